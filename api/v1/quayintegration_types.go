@@ -17,11 +17,16 @@ limitations under the License.
 package v1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,6 +36,7 @@ type QuayIntegrationSpec struct {
 	// ClusterID refers to the ID associated with this cluster.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cluster ID",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	ClusterID string `json:"clusterID"`
 
 	// CredentialsSecret refers to the Secret containing credentials to communicate with the Quay registry.
@@ -43,31 +49,1104 @@ type QuayIntegrationSpec struct {
 	// +kubebuilder:validation:Optional
 	OrganizationPrefix string `json:"organizationPrefix,omitempty"`
 
-	// QuayHostname is the hostname of the Quay registry.
+	// QuayHostname is the URL of the Quay registry including its scheme, such as https://quay.example.com. A port and, for Quay served behind a reverse proxy, a path prefix can be included. Required unless QuayRegistryRef is set.
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quay hostname",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https?://[A-Za-z0-9]([-A-Za-z0-9.]*[A-Za-z0-9])?(:[0-9]+)?(/[-A-Za-z0-9._~]+)*/?$`
+	QuayHostname string `json:"quayHostname,omitempty"`
+
+	// RegistryAccessCheck determines whether the credentials of the builder robot account of a namespace are checked against the registry once its Secrets are created.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Registry Access Check",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	RegistryAccessCheck bool `json:"registryAccessCheck,omitempty"`
+
+	// RegistryHostname is the host and optional port of the Quay registry used in image references and the docker auth entries of Secrets, such as quay-registry.example.com. Defaults to the host of the Quay API. Set when registry traffic is served by a different route or load balancer than the API.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Registry hostname",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([-A-Za-z0-9.]*[A-Za-z0-9])?(:[0-9]+)?$`
+	RegistryHostname string `json:"registryHostname,omitempty"`
+
+	// QuayRegistryRef refers to a QuayRegistry managed by the Quay Operator in this cluster. The hostname, certificate authority and availability of Quay are discovered from it.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quay Registry"
+	// +kubebuilder:validation:Optional
+	QuayRegistryRef *QuayRegistryRef `json:"quayRegistryRef,omitempty"`
+
+	// Bootstrap initializes the first user of a fresh Quay and stores an OAuth token of the user in CredentialsSecret, unless the Secret already contains a token.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Bootstrap"
+	// +kubebuilder:validation:Optional
+	Bootstrap *QuayBootstrap `json:"bootstrap,omitempty"`
+
+	// OAuthApplication manages the Quay OAuth application whose client credentials the operator stores in CredentialsSecret and exchanges for short lived access tokens. The client secret is reset on a schedule and the application is deleted along with the QuayIntegration, revoking its tokens.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OAuth Application"
+	// +kubebuilder:validation:Optional
+	OAuthApplication *OAuthApplication `json:"oauthApplication,omitempty"`
+
+	// QuayVersion is the version of Quay, such as 3.9.1, taking precedence over the version discovered from the QuayRegistry referenced by QuayRegistryRef. Required to check the version of Quay when QuayRegistryRef is not set, as Quay does not publish its version through its API.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quay Version",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?([-+][-+.A-Za-z0-9]*)?$`
+	QuayVersion string `json:"quayVersion,omitempty"`
+
+	// QuayVersionPolicy determines how a version of Quay outside of the range supported by the operator is handled. Warn reports it in the QuayVersionSupported condition while Enforce additionally stops synchronizing namespaces. Defaults to Warn.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quay Version Policy"
+	// +kubebuilder:validation:Optional
+	QuayVersionPolicy QuayVersionPolicy `json:"quayVersionPolicy,omitempty"`
+
+	// Insecure disables TLS verification of requests made against the Quay API. Intended for lab environments using self-signed certificates only.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Insecure",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	Insecure bool `json:"insecure,omitempty"`
+
+	// InsecureRegistry refers to whether to skip TLS verification to the Quay registry.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Insecure Registry",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	InsecureRegistry bool `json:"insecureRegistry,omitempty"`
+
+	// ScheduledImageStreamImport determines whether to enable import scheduling on all managed ImageStreams.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule ImageStream Imports",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	ScheduledImageStreamImport bool `json:"scheduledImageStreamImport,omitempty"`
+
+	// ImageStreamTagArchitectures determines whether to annotate the ImageStreamTags imported after Builds pushing manifest lists with the platforms of the manifest list.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Annotate ImageStreamTag Architectures",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	ImageStreamTagArchitectures bool `json:"imageStreamTagArchitectures,omitempty"`
+
+	// BuildCutover configures a window during which the ImageStreamTags of Builds pushing to Quay are served by the internal registry,
+	// letting the consumers of the internal registry migrate to Quay at their own pace. ImageStreamTags are no longer updated once the window ends.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Build Cutover"
+	// +kubebuilder:validation:Optional
+	BuildCutover *BuildCutover `json:"buildCutover,omitempty"`
+
+	// DenylistNamespaces is a list of namespaces to exclude.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="List of namespaces to exclude"
+	// +kubebuilder:validation:Optional
+	DenylistNamespaces []string `json:"denylistNamespaces,omitempty"`
+
+	// AllowlistNamespaces is a list of namespaces to include
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="List of namespaces to include"
+	// +kubebuilder:validation:Optional
+	AllowlistNamespaces []string `json:"allowlistNamespaces,omitempty"`
+
+	// Priority resolves which QuayIntegration synchronizes the namespaces selected by several QuayIntegrations. The QuayIntegration with the highest priority wins, followed by the oldest. Defaults to 0.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Priority",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	// +kubebuilder:validation:Optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// SecretFormats is the list of Secret formats generated from each robot account token. Defaults to dockerconfigjson.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Robot Secret Formats"
+	// +kubebuilder:validation:Optional
+	SecretFormats []SecretFormat `json:"secretFormats,omitempty"`
+
+	// SecretNameTemplate is a Go template used to name the Secrets generated for each robot account. The fields .OrgName, .Namespace, .ServiceAccount and .ClusterID are available.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Name Template",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	SecretNameTemplate string `json:"secretNameTemplate,omitempty"`
+
+	// ShortLivedCredentials writes short lived tokens of the robot accounts of service accounts to their Secrets instead of the permanent robot account tokens, renewing them before they expire. The robot accounts are federated with the OIDC identity of the operator, whose token is exchanged for robot account tokens. Requires Quay 3.13 or newer trusting the issuer of the token of the operator. Only applies to the Secrets written to the cluster by the operator, not to secret stores, exported credentials or user repositories.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Short Lived Credentials",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	ShortLivedCredentials bool `json:"shortLivedCredentials,omitempty"`
+
+	// RobotNameTemplate is a Go template used to name the robot account created in each organization for each service account. The fields .OrgName, .Namespace, .ServiceAccount and .ClusterID are available. Hyphens and dots are replaced with underscores. Defaults to the name of the service account.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Robot Name Template",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	RobotNameTemplate string `json:"robotNameTemplate,omitempty"`
+
+	// ServiceAccountRobots creates a distinct robot account for each service account labeled with quay.openshift.io/robot-role, set to read or write, granted that role on the repositories of the organization so that Quay logs attribute image pulls and pushes to the service account. The robot account and its Secrets are deleted once the service account is deleted or unlabeled. Robot accounts of the builder, default and deployer service accounts are always created. Not supported for user repositories.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Service Account Robots",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	ServiceAccountRobots bool `json:"serviceAccountRobots,omitempty"`
+
+	// OrganizationEmailTemplate is a Go template used to derive the email address of the organizations created in Quay. The fields .OrgName, .Namespace and .ClusterID are available. Defaults to {{ .OrgName }}@redhat.com.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Organization Email Template",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	OrganizationEmailTemplate string `json:"organizationEmailTemplate,omitempty"`
+
+	// OrganizationEmailStrategy determines how the email addresses of organizations are kept unique within Quay. None uses the rendered email address while HashSuffix appends a hash of the cluster ID and organization to the local part of the address and repairs existing organizations using the rendered email address. Defaults to None.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Organization Email Strategy"
+	// +kubebuilder:validation:Optional
+	OrganizationEmailStrategy OrganizationEmailStrategy `json:"organizationEmailStrategy,omitempty"`
+
+	// OrganizationNameMaxLength is the maximum length of the names of organizations created in Quay. Longer names, or names containing characters not accepted by Quay, are truncated and suffixed with a hash. Defaults to 255.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Organization Name Max Length",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=16
+	// +kubebuilder:validation:Maximum=255
+	OrganizationNameMaxLength int32 `json:"organizationNameMaxLength,omitempty"`
+
+	// OrganizationOwners are the Quay users which must be members of the owners team of each organization created for namespaces, such as platform administrators. Users missing from the team, including users removed from it in Quay, are added back on every synchronization of the namespace. Users who are not members of the organization yet are invited instead when Quay sends emails. Other members of the team are left in place.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Organization Owners"
+	// +kubebuilder:validation:Optional
+	OrganizationOwners []string `json:"organizationOwners,omitempty"`
+
+	// ResourceLabels is a set of labels added to all resources created by the operator.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Labels"
+	// +kubebuilder:validation:Optional
+	ResourceLabels map[string]string `json:"resourceLabels,omitempty"`
+
+	// ResourceAnnotations is a set of annotations added to all resources created by the operator.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Annotations"
+	// +kubebuilder:validation:Optional
+	ResourceAnnotations map[string]string `json:"resourceAnnotations,omitempty"`
+
+	// RewriteBuildInputImages determines whether the input images of Builds are rewritten to the mirrors defined in BuildInputImageMirrors. Can be overridden per BuildConfig using an annotation.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rewrite Build Input Images",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	RewriteBuildInputImages bool `json:"rewriteBuildInputImages,omitempty"`
+
+	// BuildInputImageMirrors maps the input images of Builds to mirrors hosted in Quay.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Build Input Image Mirrors"
+	// +kubebuilder:validation:Optional
+	BuildInputImageMirrors []ImageMirror `json:"buildInputImageMirrors,omitempty"`
+
+	// BuildPushSecretPolicy determines how the push secret of Builds redirected to Quay is managed. Preserve keeps a push secret set by the user while Replace always uses the operator managed secret. Defaults to Preserve.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Build Push Secret Policy"
+	// +kubebuilder:validation:Optional
+	BuildPushSecretPolicy PushSecretPolicy `json:"buildPushSecretPolicy,omitempty"`
+
+	// BuilderRobotScope determines which repositories the builder robot account of a namespace may push to. Organization grants write access to every repository of the organization using a default permission while Repositories only grants write access to the repositories of the ImageStreams and BuildConfigs of the namespace. Defaults to Organization.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Builder Robot Scope"
+	// +kubebuilder:validation:Optional
+	BuilderRobotScope BuilderRobotScope `json:"builderRobotScope,omitempty"`
+
+	// UnprovisionedBuildPolicy determines how Builds created in namespaces whose Quay organization, robot accounts and push secret are not provisioned yet are admitted. Allow admits them, Reject denies them with a retryable error and Delay waits a few seconds for the provisioning to complete before denying them. Defaults to Allow.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Unprovisioned Build Policy"
+	// +kubebuilder:validation:Optional
+	UnprovisionedBuildPolicy UnprovisionedBuildPolicy `json:"unprovisionedBuildPolicy,omitempty"`
+
+	// BuildOutputTagTemplate is a Go template used to tag the output of Builds pushed to Quay. The fields .Repository, .Tag, .BuildNumber, .GitSha, .GitShortSha and .Date are available. Can be overridden per BuildConfig using an annotation.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Build Output Tag Template",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	BuildOutputTagTemplate string `json:"buildOutputTagTemplate,omitempty"`
+
+	// RepositoryNotifications are the notifications configured on all managed repositories. Additional notifications can be defined per namespace using an annotation.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Repository Notifications"
+	// +kubebuilder:validation:Optional
+	RepositoryNotifications []RepositoryNotification `json:"repositoryNotifications,omitempty"`
+
+	// ConsoleLinks determines whether links to the Quay organization are added to the dashboards of synchronized namespaces in the OpenShift Console.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Console Links",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	ConsoleLinks bool `json:"consoleLinks,omitempty"`
+
+	// ImageSigning distributes the public key and policy used to verify image signatures to synchronized namespaces and optionally enables trust on managed repositories.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Signing"
+	// +kubebuilder:validation:Optional
+	ImageSigning *ImageSigning `json:"imageSigning,omitempty"`
+
+	// ImagePolicy generates policies requiring images of the managed Quay organizations to be signed by the ImageSigning public key. Requires the image policy feature of the operator.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Image Policy"
+	// +kubebuilder:validation:Optional
+	ImagePolicy *ImagePolicy `json:"imagePolicy,omitempty"`
+
+	// ReaderRobot creates a robot account with read access in each managed Quay organization and distributes the credentials of every organization as a single pull secret to the listed namespaces. Requires the reader robot feature of the operator.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Reader Robot"
+	// +kubebuilder:validation:Optional
+	ReaderRobot *ReaderRobot `json:"readerRobot,omitempty"`
+
+	// ClusterIDMigration moves the managed Quay organizations to the names derived from a new cluster ID. Quay organizations cannot be renamed, so new organizations are created, their repositories mirrored from the existing organizations and ClusterID updated once the copy completes.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cluster ID Migration"
+	// +kubebuilder:validation:Optional
+	ClusterIDMigration *ClusterIDMigration `json:"clusterIDMigration,omitempty"`
+
+	// NamespaceRecreationPolicy determines how the organization of a namespace deleted and created again with the same name is handled when the organization was not removed. Reattach reuses the organization and its robot accounts, RotateRobots regenerates the tokens of every robot account of the organization and Quarantine stops synchronizing the namespace until the policy is overridden by an annotation of the namespace. Defaults to Reattach.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Recreation Policy"
+	// +kubebuilder:validation:Optional
+	NamespaceRecreationPolicy NamespaceRecreationPolicy `json:"namespaceRecreationPolicy,omitempty"`
+
+	// OfflineMode keeps the cluster consistent while Quay is unreachable, such as on intermittently connected edge clusters. The deletion of the organizations of deleted namespaces and the provisioning of new namespaces are queued in the status and applied once Quay is reachable again, while new namespaces are marked as pending provisioning.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Offline Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	OfflineMode bool `json:"offlineMode,omitempty"`
+
+	// UserRepositories maps the personal namespaces of users to repositories of their Quay user account instead of an organization, for Quay instances restricting the creation of organizations. Repositories are created in the account of the user recorded as the requester of the namespace.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="User Repositories"
+	// +kubebuilder:validation:Optional
+	UserRepositories *UserRepositories `json:"userRepositories,omitempty"`
+
+	// SyncHooks are HTTP endpoints invoked at defined steps of the synchronization of namespaces, such as registering organizations in a CMDB or opening tickets, without modifying the operator.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Sync Hooks"
+	// +kubebuilder:validation:Optional
+	SyncHooks []SyncHook `json:"syncHooks,omitempty"`
+
+	// CatalogMetadata publishes the Quay resources of each synchronized namespace, such as the URLs of its organization and repositories and the names of its pull secrets, in a ConfigMap consumable by developer portals such as Backstage. The ConfigMap is updated on every successful synchronization.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Catalog Metadata"
+	// +kubebuilder:validation:Optional
+	CatalogMetadata *CatalogMetadata `json:"catalogMetadata,omitempty"`
+
+	// GitOpsMode limits the operator to the Quay side of the integration for clusters whose Secrets are managed by GitOps tooling such as ExternalSecrets. Organizations, robot accounts, repositories and permissions are managed in Quay, while the robot account Secrets of service accounts, their links to the service accounts and the global pull secret are left untouched.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GitOps Mode",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	GitOpsMode bool `json:"gitOpsMode,omitempty"`
+
+	// SecretStore publishes the credentials of the robot accounts of service accounts to an external secret store, such as HashiCorp Vault or AWS Secrets Manager, instead of writing them to Secrets of the cluster. Tools such as ExternalSecrets provide the Secrets linked to the service accounts from the store.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Store"
+	// +kubebuilder:validation:Optional
+	SecretStore *SecretStore `json:"secretStore,omitempty"`
+
+	// CredentialExport renders the robot account Secrets of service accounts as SealedSecrets or SOPS encrypted manifests in a ConfigMap of each synchronized namespace, letting teams commit the credentials to Git safely. Manifests are only encrypted again when the credentials or the export settings change.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credential Export"
+	// +kubebuilder:validation:Optional
+	CredentialExport *CredentialExport `json:"credentialExport,omitempty"`
+}
+
+// QuayRegistryRef represents a reference to a QuayRegistry
+type QuayRegistryRef struct {
+
+	// Name represents the name of the QuayRegistry
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name of the QuayRegistry",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace represents the namespace containing the QuayRegistry
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace containing the QuayRegistry",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+}
+
+// QuayBootstrap defines the first user initialized on a fresh Quay
+type QuayBootstrap struct {
+
+	// Username is the name of the first user, which must be listed in SUPER_USERS of the Quay configuration to administer Quay. Defaults to quayadmin
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Username",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([._-]?[a-z0-9])*$`
+	Username string `json:"username,omitempty"`
+
+	// Email is the email address of the first user
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Email",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	Email string `json:"email,omitempty"`
+}
+
+// OAuthApplication defines the Quay OAuth application used by the operator
+type OAuthApplication struct {
+
+	// Organization is the Quay organization owning the OAuth application, administered by the user of the credentials
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Organization",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	Organization string `json:"organization"`
+
+	// Name is the name of the OAuth application. Defaults to quay-bridge-operator-<clusterID>
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// RotationIntervalHours is the number of hours between resets of the client secret of the OAuth application, invalidating the
+	// access tokens minted with the previous client secret. The client secret is not rotated when unset
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rotation Interval Hours",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	RotationIntervalHours int32 `json:"rotationIntervalHours,omitempty"`
+}
+
+// OAuthApplicationStatus reports the Quay OAuth application used by the operator
+type OAuthApplicationStatus struct {
+
+	// ClientID is the client ID of the OAuth application
+	ClientID string `json:"clientID"`
+
+	// LastRotation is the time the client secret was last created or reset
+	// +kubebuilder:validation:Optional
+	LastRotation *metav1.Time `json:"lastRotation,omitempty"`
+}
+
+// RepositoryNotification defines a notification configured on a Quay repository
+type RepositoryNotification struct {
+
+	// Title uniquely identifies the notification
+	// +kubebuilder:validation:Required
+	Title string `json:"title"`
+
+	// Event is the repository event triggering the notification
+	// +kubebuilder:validation:Required
+	Event RepositoryNotificationEvent `json:"event"`
+
+	// Method is how the notification is delivered
+	// +kubebuilder:validation:Required
+	Method RepositoryNotificationMethod `json:"method"`
+
+	// Target is the Slack or webhook URL, or the email address, the notification is delivered to
+	// +kubebuilder:validation:Required
+	Target string `json:"target"`
+
+	// VulnerabilityLevel is the minimum severity triggering a vulnerability_found notification. Defaults to Critical.
+	// +kubebuilder:validation:Optional
+	VulnerabilityLevel VulnerabilityLevel `json:"vulnerabilityLevel,omitempty"`
+}
+
+// RepositoryNotificationEvent represents a repository event in Quay
+// +kubebuilder:validation:Enum=repo_push;vulnerability_found;repo_mirror_sync_failed
+type RepositoryNotificationEvent string
+
+const (
+	// RepoPushNotificationEvent is triggered when an image is pushed to the repository
+	RepoPushNotificationEvent RepositoryNotificationEvent = "repo_push"
+	// VulnerabilityFoundNotificationEvent is triggered when a vulnerability is found in an image of the repository
+	VulnerabilityFoundNotificationEvent RepositoryNotificationEvent = "vulnerability_found"
+	// RepoMirrorSyncFailedNotificationEvent is triggered when mirroring of the repository fails
+	RepoMirrorSyncFailedNotificationEvent RepositoryNotificationEvent = "repo_mirror_sync_failed"
+)
+
+// RepositoryNotificationMethod represents a notification delivery method in Quay
+// +kubebuilder:validation:Enum=slack;email;webhook
+type RepositoryNotificationMethod string
+
+const (
+	// SlackNotificationMethod delivers notifications to a Slack incoming webhook URL
+	SlackNotificationMethod RepositoryNotificationMethod = "slack"
+	// EmailNotificationMethod delivers notifications to an email address
+	EmailNotificationMethod RepositoryNotificationMethod = "email"
+	// WebhookNotificationMethod delivers notifications to a webhook URL
+	WebhookNotificationMethod RepositoryNotificationMethod = "webhook"
+)
+
+// VulnerabilityLevel represents the severity of a vulnerability in Quay
+// +kubebuilder:validation:Enum=Critical;High;Medium;Low
+type VulnerabilityLevel string
+
+const (
+	CriticalVulnerabilityLevel VulnerabilityLevel = "Critical"
+	HighVulnerabilityLevel     VulnerabilityLevel = "High"
+	MediumVulnerabilityLevel   VulnerabilityLevel = "Medium"
+	LowVulnerabilityLevel      VulnerabilityLevel = "Low"
+)
+
+// ImageMirror maps an image repository prefix to a mirror
+type ImageMirror struct {
+
+	// Source is the image repository prefix being mirrored, such as registry.redhat.io/ubi8
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Source",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	Source string `json:"source"`
+
+	// Mirror is the location the source is mirrored to, such as quay.example.com/mirrors/ubi8
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Mirror",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	Mirror string `json:"mirror"`
+}
+
+// BuildCutover is the window during which Builds pushing to Quay also update their ImageStreamTag in the internal registry
+type BuildCutover struct {
+
+	// Start is the beginning of the cutover window. The window starts immediately when not set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Start"
+	// +kubebuilder:validation:Optional
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// End is the end of the cutover window, after which the ImageStreamTags of Builds are no longer updated
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="End"
+	// +kubebuilder:validation:Required
+	End metav1.Time `json:"end"`
+}
+
+// ImageSigning defines the verification material distributed to synchronized namespaces
+type ImageSigning struct {
+
+	// PublicKeySecret refers to the Secret containing the cosign public key used to verify image signatures. The key defaults to cosign.pub.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Public Key Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	// +kubebuilder:validation:Optional
+	PublicKeySecret *SecretRef `json:"publicKeySecret,omitempty"`
+
+	// Policy is a sigstore policy document distributed as policy.json.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Policy"
+	// +kubebuilder:validation:Optional
+	Policy string `json:"policy,omitempty"`
+
+	// ConfigMapName is the name of the ConfigMap created in each synchronized namespace. Defaults to quay-image-signing.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ConfigMap Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// RepositoryTrust determines whether trust is enabled on all managed repositories in Quay.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Repository Trust",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	RepositoryTrust bool `json:"repositoryTrust,omitempty"`
+}
+
+// ImagePolicy defines the sigstore policies generated for the managed Quay organizations
+type ImagePolicy struct {
+
+	// Scope determines whether an ImagePolicy is generated in each synchronized namespace or a single ClusterImagePolicy covers every managed organization. Defaults to Namespace.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scope"
+	// +kubebuilder:validation:Optional
+	Scope ImagePolicyScope `json:"scope,omitempty"`
+
+	// MatchPolicy determines how the identity of a signature is matched against the image. Defaults to MatchRepoDigestOrExact.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Match Policy"
+	// +kubebuilder:validation:Optional
+	MatchPolicy ImagePolicyMatchPolicy `json:"matchPolicy,omitempty"`
+}
+
+// CatalogMetadata defines the ConfigMap describing the Quay resources of synchronized namespaces
+type CatalogMetadata struct {
+
+	// ConfigMapName is the name of the ConfigMap created in each synchronized namespace. Defaults to quay-catalog-metadata.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ConfigMap Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// SecretStore defines the external secret store receiving the credentials of robot accounts. Exactly one store must be set
+type SecretStore struct {
+
+	// PathTemplate is a Go template of the path of the credentials of a Secret within the store, using .Namespace, .SecretName, .OrgName and .ClusterID. Defaults to quay/{{ .Namespace }}/{{ .SecretName }}.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Path Template",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	PathTemplate string `json:"pathTemplate,omitempty"`
+
+	// Vault writes the credentials to a KV secrets engine of HashiCorp Vault.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault"
+	// +kubebuilder:validation:Optional
+	Vault *VaultSecretStore `json:"vault,omitempty"`
+
+	// AWSSecretsManager writes the credentials to AWS Secrets Manager.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="AWS Secrets Manager"
+	// +kubebuilder:validation:Optional
+	AWSSecretsManager *AWSSecretsManagerSecretStore `json:"awsSecretsManager,omitempty"`
+}
+
+// VaultSecretStore defines the KV secrets engine of HashiCorp Vault receiving the credentials of robot accounts
+type VaultSecretStore struct {
+
+	// Address of the Vault server, such as https://vault.example.com:8200
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Address string `json:"address"`
+
+	// Mount is the path the KV secrets engine is mounted at. Defaults to secret.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Mount",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	Mount string `json:"mount,omitempty"`
+
+	// KVVersion is the version of the KV secrets engine. Defaults to 2.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="KV Version"
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=1;2
+	KVVersion int32 `json:"kvVersion,omitempty"`
+
+	// TokenSecret refers to the Secret containing the Vault token, stored under the token key unless a key is set.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Token Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	// +kubebuilder:validation:Required
+	TokenSecret *SecretRef `json:"tokenSecret"`
+}
+
+// AWSSecretsManagerSecretStore defines the AWS Secrets Manager region receiving the credentials of robot accounts
+type AWSSecretsManagerSecretStore struct {
+
+	// Region of AWS Secrets Manager, such as us-east-1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Region",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+
+	// Endpoint overrides the regional endpoint of AWS Secrets Manager, such as a VPC endpoint.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Endpoint",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https://`
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// CredentialsSecret refers to the Secret containing the aws_access_key_id, aws_secret_access_key and optional aws_session_token of the IAM identity writing the secrets.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credentials Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
 	// +kubebuilder:validation:Required
-	QuayHostname string `json:"quayHostname"`
+	CredentialsSecret *SecretRef `json:"credentialsSecret"`
+}
+
+// CredentialExport defines the ConfigMap receiving the encrypted manifests of robot account Secrets. Exactly one of sealedSecrets and sops must be set
+type CredentialExport struct {
+
+	// ConfigMapName is the name of the ConfigMap created in each synchronized namespace. Defaults to quay-credential-export.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ConfigMap Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// SealedSecrets renders SealedSecrets encrypted with the public key of the sealed-secrets controller of the cluster.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Sealed Secrets"
+	// +kubebuilder:validation:Optional
+	SealedSecrets *SealedSecretsExport `json:"sealedSecrets,omitempty"`
+
+	// SOPS renders Secrets encrypted by SOPS, whose data key is encrypted with HashiCorp Vault Transit or AWS KMS.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="SOPS"
+	// +kubebuilder:validation:Optional
+	SOPS *SOPSExport `json:"sops,omitempty"`
+}
+
+// SealedSecretsExport defines the sealed-secrets controller whose public key seals the exported Secrets
+type SealedSecretsExport struct {
+
+	// ControllerName is the name of the Service of the sealed-secrets controller. Defaults to sealed-secrets-controller.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Controller Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	ControllerName string `json:"controllerName,omitempty"`
+
+	// ControllerNamespace is the namespace of the sealed-secrets controller. Defaults to kube-system.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Controller Namespace",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	ControllerNamespace string `json:"controllerNamespace,omitempty"`
+
+	// Scope determines whether the SealedSecrets may be renamed or moved to other namespaces. Defaults to Strict.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scope"
+	// +kubebuilder:validation:Optional
+	Scope SealedSecretsScope `json:"scope,omitempty"`
+}
+
+// SOPSExport defines the key encrypting the data key of the exported Secrets. Exactly one key must be set
+type SOPSExport struct {
+
+	// Vault encrypts the data key with a key of the Transit secrets engine of HashiCorp Vault.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault Transit"
+	// +kubebuilder:validation:Optional
+	Vault *SOPSVaultTransitKey `json:"vault,omitempty"`
+
+	// AWSKMS encrypts the data key with an AWS KMS key.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="AWS KMS"
+	// +kubebuilder:validation:Optional
+	AWSKMS *SOPSAWSKMSKey `json:"awsKms,omitempty"`
+}
+
+// SOPSVaultTransitKey defines a key of the Transit secrets engine of HashiCorp Vault
+type SOPSVaultTransitKey struct {
+
+	// Address of the Vault server, such as https://vault.example.com:8200
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Address string `json:"address"`
+
+	// EnginePath is the path the Transit secrets engine is mounted at. Defaults to transit.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Engine Path",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	EnginePath string `json:"enginePath,omitempty"`
+
+	// KeyName is the name of the key of the Transit secrets engine
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	KeyName string `json:"keyName"`
+
+	// TokenSecret refers to the Secret containing the Vault token, stored under the token key unless a key is set.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Token Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	// +kubebuilder:validation:Required
+	TokenSecret *SecretRef `json:"tokenSecret"`
+}
+
+// SOPSAWSKMSKey defines an AWS KMS key
+type SOPSAWSKMSKey struct {
+
+	// ARN of the KMS key, such as arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab. The region of the key is used.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="ARN",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:kms:[a-z0-9-]+:`
+	ARN string `json:"arn"`
+
+	// Endpoint overrides the regional endpoint of AWS KMS, such as a VPC endpoint.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Endpoint",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https://`
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// CredentialsSecret refers to the Secret containing the aws_access_key_id, aws_secret_access_key and optional aws_session_token of the IAM identity allowed to encrypt with the key.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credentials Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	// +kubebuilder:validation:Required
+	CredentialsSecret *SecretRef `json:"credentialsSecret"`
+}
+
+// ReaderRobot defines the robot account granted read access to every managed Quay organization
+type ReaderRobot struct {
+
+	// Name of the robot account created in each managed Quay organization. Defaults to reader.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name"
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9_]{1,254}$`
+	Name string `json:"name,omitempty"`
+
+	// SecretName is the name of the pull secret created in each of the Namespaces. Defaults to quay-reader-pull-secret.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Name"
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Namespaces receiving the pull secret, such as CI namespaces pulling images of other teams
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespaces"
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+}
+
+// SyncHook defines an HTTP endpoint receiving a POST request describing a step of the synchronization of a namespace
+type SyncHook struct {
+
+	// Name identifies the hook in events and logs
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// URL of the endpoint receiving the steps as JSON
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="URL",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Points are the steps the hook is invoked at. PreOrganizationCreate is invoked before the organization of a namespace is created, PostSecretCreate once the robot account Secrets of a service account are created and PreDelete before the organization of a deleted namespace is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Points"
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Points []SyncHookPoint `json:"points"`
+
+	// FailurePolicy determines how failures of the hook are handled. Fail stops the synchronization of the namespace, retrying it until the hook succeeds, while Ignore records a warning event and continues. Defaults to Fail.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Failure Policy"
+	// +kubebuilder:validation:Optional
+	FailurePolicy SyncHookFailurePolicy `json:"failurePolicy,omitempty"`
+
+	// TimeoutSeconds bounds the duration of a request to the hook. Defaults to 10.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Timeout Seconds",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// TokenSecret refers to a Secret containing a token sent as a bearer token to the hook. The key defaults to token.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Token Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	// +kubebuilder:validation:Optional
+	TokenSecret *SecretRef `json:"tokenSecret,omitempty"`
+}
+
+// UserRepositories defines the namespaces mapped to the repositories of Quay user accounts
+type UserRepositories struct {
+
+	// Namespaces mapped to the user account of their requester. Every namespace requested by a user is mapped when empty.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespaces"
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ClusterIDMigration defines the cluster ID the managed Quay organizations are migrated to
+type ClusterIDMigration struct {
+
+	// ClusterID is the new cluster ID. ClusterID is set to this value once the organizations have been copied.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cluster ID",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClusterID string `json:"clusterID"`
+
+	// DeleteSourceOrganizations deletes the organizations derived from the previous cluster ID once every namespace uses its new organization. The organizations are kept by default.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Delete Source Organizations",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	// +kubebuilder:validation:Optional
+	DeleteSourceOrganizations bool `json:"deleteSourceOrganizations,omitempty"`
+}
+
+// ClusterIDMigrationPhase represents the progress of a cluster ID migration
+type ClusterIDMigrationPhase string
+
+const (
+	// ClusterIDMigrationCopying copies the organizations and mirrors their repositories
+	ClusterIDMigrationCopying ClusterIDMigrationPhase = "Copying"
+	// ClusterIDMigrationSwitching updates ClusterID to the new cluster ID
+	ClusterIDMigrationSwitching ClusterIDMigrationPhase = "Switching"
+	// ClusterIDMigrationRetiring waits for the namespaces to use their new organization and retires the previous ones
+	ClusterIDMigrationRetiring ClusterIDMigrationPhase = "Retiring"
+	// ClusterIDMigrationCompleted migrations have moved every namespace to its new organization
+	ClusterIDMigrationCompleted ClusterIDMigrationPhase = "Completed"
+)
+
+// ClusterIDMigrationStatus reports the progress of a cluster ID migration
+type ClusterIDMigrationStatus struct {
+
+	// SourceClusterID is the cluster ID the organizations are migrated from
+	SourceClusterID string `json:"sourceClusterID"`
+
+	// TargetClusterID is the cluster ID the organizations are migrated to
+	TargetClusterID string `json:"targetClusterID"`
+
+	// Phase of the migration
+	Phase ClusterIDMigrationPhase `json:"phase"`
+
+	// Namespaces are the managed namespaces whose organization is migrated
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// CopiedNamespaces are the namespaces whose organization has been copied and repositories mirrored
+	// +kubebuilder:validation:Optional
+	CopiedNamespaces []string `json:"copiedNamespaces,omitempty"`
+
+	// RetiredNamespaces are the namespaces using their new organization, whose previous organization has been retired
+	// +kubebuilder:validation:Optional
+	RetiredNamespaces []string `json:"retiredNamespaces,omitempty"`
+
+	// Message describes the step the migration is waiting on
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// RegistryHostnameMigrationPhase represents the progress of a registry hostname migration
+type RegistryHostnameMigrationPhase string
+
+const (
+	// RegistryHostnameMigrationInProgress migrations are rewriting the managed resources of the namespaces
+	RegistryHostnameMigrationInProgress RegistryHostnameMigrationPhase = "InProgress"
+	// RegistryHostnameMigrationCompleted migrations have rewritten the managed resources of every namespace
+	RegistryHostnameMigrationCompleted RegistryHostnameMigrationPhase = "Completed"
+)
+
+// RegistryHostnameMigrationStatus reports the progress of a registry hostname migration
+type RegistryHostnameMigrationStatus struct {
+
+	// PreviousHostnames are the registry hostnames rewritten by the migration
+	PreviousHostnames []string `json:"previousHostnames"`
+
+	// Hostname is the registry hostname the managed resources are migrated to
+	Hostname string `json:"hostname"`
+
+	// Phase of the migration
+	Phase RegistryHostnameMigrationPhase `json:"phase"`
+
+	// StartTime is the time at which the migration started
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time at which the migration completed
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// MigratedNamespaces is the number of namespaces whose resources have been migrated
+	// +kubebuilder:validation:Optional
+	MigratedNamespaces int32 `json:"migratedNamespaces,omitempty"`
+
+	// UpdatedBuildConfigs is the number of BuildConfigs whose image references have been rewritten
+	// +kubebuilder:validation:Optional
+	UpdatedBuildConfigs int32 `json:"updatedBuildConfigs,omitempty"`
+
+	// UpdatedImageStreams is the number of ImageStreams whose image references have been rewritten
+	// +kubebuilder:validation:Optional
+	UpdatedImageStreams int32 `json:"updatedImageStreams,omitempty"`
+
+	// Failures describe the namespaces which could not be migrated during the most recent attempt
+	// +kubebuilder:validation:Optional
+	Failures []string `json:"failures,omitempty"`
+}
+
+// ImagePolicyScope represents the kind of policy generated
+// +kubebuilder:validation:Enum=Namespace;Cluster
+type ImagePolicyScope string
+
+const (
+	// NamespaceImagePolicyScope generates an ImagePolicy in each synchronized namespace covering the organization of the namespace
+	NamespaceImagePolicyScope ImagePolicyScope = "Namespace"
+	// ClusterImagePolicyScope generates a ClusterImagePolicy covering every managed organization
+	ClusterImagePolicyScope ImagePolicyScope = "Cluster"
+)
+
+// SealedSecretsScope represents the names and namespaces a SealedSecret may be unsealed with
+// +kubebuilder:validation:Enum=Strict;NamespaceWide;ClusterWide
+type SealedSecretsScope string
+
+const (
+	// StrictSealedSecretsScope only unseals the SealedSecret with its name and namespace
+	StrictSealedSecretsScope SealedSecretsScope = "Strict"
+	// NamespaceWideSealedSecretsScope unseals the SealedSecret with any name within its namespace
+	NamespaceWideSealedSecretsScope SealedSecretsScope = "NamespaceWide"
+	// ClusterWideSealedSecretsScope unseals the SealedSecret with any name in any namespace
+	ClusterWideSealedSecretsScope SealedSecretsScope = "ClusterWide"
+)
+
+// ImagePolicyMatchPolicy represents how the identity of a signature is matched against the image
+// +kubebuilder:validation:Enum=MatchRepoDigestOrExact;MatchRepository
+type ImagePolicyMatchPolicy string
+
+const (
+	// MatchRepoDigestOrExactMatchPolicy requires the signed identity to match the repository, and the tag when pulling by tag
+	MatchRepoDigestOrExactMatchPolicy ImagePolicyMatchPolicy = "MatchRepoDigestOrExact"
+	// MatchRepositoryMatchPolicy requires the signed identity to match the repository
+	MatchRepositoryMatchPolicy ImagePolicyMatchPolicy = "MatchRepository"
+)
+
+// SecretFormat represents the format of a Secret generated from a robot account token
+// +kubebuilder:validation:Enum=dockerconfigjson;dockercfg;basic-auth
+type SecretFormat string
+
+const (
+	// DockerConfigJsonSecretFormat generates a kubernetes.io/dockerconfigjson Secret
+	DockerConfigJsonSecretFormat SecretFormat = "dockerconfigjson"
+	// DockerCfgSecretFormat generates a legacy kubernetes.io/dockercfg Secret
+	DockerCfgSecretFormat SecretFormat = "dockercfg"
+	// BasicAuthSecretFormat generates a kubernetes.io/basic-auth Secret for tools such as skopeo or Tekton
+	BasicAuthSecretFormat SecretFormat = "basic-auth"
+)
+
+// AllSecretFormats are the formats Secrets can be generated in
+var AllSecretFormats = []SecretFormat{DockerConfigJsonSecretFormat, DockerCfgSecretFormat, BasicAuthSecretFormat}
+
+// PushSecretPolicy determines how a push secret set by the user on a Build is handled
+// +kubebuilder:validation:Enum=Preserve;Replace
+type PushSecretPolicy string
+
+const (
+	// PreservePushSecretPolicy keeps a push secret set by the user, using the operator managed secret otherwise
+	PreservePushSecretPolicy PushSecretPolicy = "Preserve"
+	// ReplacePushSecretPolicy always uses the operator managed secret
+	ReplacePushSecretPolicy PushSecretPolicy = "Replace"
+)
+
+// UnprovisionedBuildPolicy determines how Builds created in namespaces which have not been provisioned yet are admitted
+// +kubebuilder:validation:Enum=Allow;Reject;Delay
+type UnprovisionedBuildPolicy string
+
+const (
+	// AllowUnprovisionedBuildPolicy admits Builds regardless of the provisioning of their namespace
+	AllowUnprovisionedBuildPolicy UnprovisionedBuildPolicy = "Allow"
+	// RejectUnprovisionedBuildPolicy denies Builds with a retryable error until their namespace is provisioned
+	RejectUnprovisionedBuildPolicy UnprovisionedBuildPolicy = "Reject"
+	// DelayUnprovisionedBuildPolicy waits for the namespace to be provisioned before denying Builds
+	DelayUnprovisionedBuildPolicy UnprovisionedBuildPolicy = "Delay"
+)
+
+// PendingOperationType is a change to Quay queued while Quay is unreachable
+// +kubebuilder:validation:Enum=DeleteOrganization;SyncNamespace
+type PendingOperationType string
+
+const (
+	// DeleteOrganizationOperation deletes the organization of a namespace deleted while Quay was unreachable
+	DeleteOrganizationOperation PendingOperationType = "DeleteOrganization"
+	// SyncNamespaceOperation provisions a namespace created while Quay was unreachable
+	SyncNamespaceOperation PendingOperationType = "SyncNamespace"
+)
+
+// PendingOperation is a change to Quay queued in offline mode
+type PendingOperation struct {
+	// Type of the change
+	Type PendingOperationType `json:"type"`
+
+	// Namespace the change originates from
+	Namespace string `json:"namespace"`
+
+	// Organization the change applies to
+	Organization string `json:"organization"`
+
+	// QueuedAt is when the change was queued
+	QueuedAt metav1.Time `json:"queuedAt"`
+
+	// Message is the error of the last attempt to apply the change
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// NamespaceRecreationPolicy determines how the organization of a re-created namespace is handled
+// +kubebuilder:validation:Enum=Reattach;RotateRobots;Quarantine
+type NamespaceRecreationPolicy string
+
+const (
+	// ReattachNamespaceRecreationPolicy reuses the organization and its robot accounts
+	ReattachNamespaceRecreationPolicy NamespaceRecreationPolicy = "Reattach"
+	// RotateRobotsNamespaceRecreationPolicy reuses the organization after regenerating the tokens of its robot accounts
+	RotateRobotsNamespaceRecreationPolicy NamespaceRecreationPolicy = "RotateRobots"
+	// QuarantineNamespaceRecreationPolicy stops synchronizing the namespace until an administrator chooses another policy
+	QuarantineNamespaceRecreationPolicy NamespaceRecreationPolicy = "Quarantine"
+)
+
+// BuildCutoverPhase is the phase of the build cutover window
+type BuildCutoverPhase string
+
+const (
+	// PendingBuildCutoverPhase precedes the cutover window. ImageStreamTags refer to the images pushed to Quay
+	PendingBuildCutoverPhase BuildCutoverPhase = "Pending"
+	// ActiveBuildCutoverPhase is the cutover window. ImageStreamTags are served by the internal registry
+	ActiveBuildCutoverPhase BuildCutoverPhase = "Active"
+	// CompleteBuildCutoverPhase follows the cutover window. ImageStreamTags are no longer updated
+	CompleteBuildCutoverPhase BuildCutoverPhase = "Complete"
+)
+
+// QuayVersionPolicy determines how a version of Quay unsupported by the operator is handled
+// +kubebuilder:validation:Enum=Warn;Enforce
+type QuayVersionPolicy string
+
+const (
+	// WarnQuayVersionPolicy reports unsupported versions of Quay without changing how namespaces are synchronized
+	WarnQuayVersionPolicy QuayVersionPolicy = "Warn"
+	// EnforceQuayVersionPolicy stops synchronizing namespaces against unsupported versions of Quay
+	EnforceQuayVersionPolicy QuayVersionPolicy = "Enforce"
+)
 
-	// InsecureRegistry refers to whether to skip TLS verification to the Quay registry.
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Insecure Registry",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
-	// +kubebuilder:validation:Optional
-	InsecureRegistry bool `json:"insecureRegistry,omitempty"`
+const (
+	// DefaultOrganizationNameMaxLength is the maximum length of organization names accepted by Quay
+	DefaultOrganizationNameMaxLength = 255
 
-	// ScheduledImageStreamImport determines whether to enable import scheduling on all managed ImageStreams.
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule ImageStream Imports",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
-	// +kubebuilder:validation:Optional
-	ScheduledImageStreamImport bool `json:"scheduledImageStreamImport,omitempty"`
+	// DefaultReaderRobotName is the name of the reader robot account created in each managed organization
+	DefaultReaderRobotName = "reader"
+	// DefaultReaderRobotSecretName is the name of the pull secret containing the reader robot credentials
+	DefaultReaderRobotSecretName = "quay-reader-pull-secret"
 
-	// DenylistNamespaces is a list of namespaces to exclude.
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="List of namespaces to exclude"
-	// +kubebuilder:validation:Optional
-	DenylistNamespaces []string `json:"denylistNamespaces,omitempty"`
+	// DefaultBootstrapUsername is the name of the first user initialized on Quay when Bootstrap is set
+	DefaultBootstrapUsername = "quayadmin"
 
-	// AllowlistNamespaces is a list of namespaces to include
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="List of namespaces to include"
-	// +kubebuilder:validation:Optional
-	AllowlistNamespaces []string `json:"allowlistNamespaces,omitempty"`
-}
+	// organizationNameHashLength is the number of hexadecimal characters of the hash suffixing normalized organization names
+	organizationNameHashLength = 8
+
+	// QuayIntegrationLabel is set on the resources created by the operator to the name of the QuayIntegration they belong to
+	QuayIntegrationLabel = "quay.redhat.com/quay-integration"
+)
+
+var (
+	// organizationNameRegex matches the names of organizations accepted by Quay
+	organizationNameRegex = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+	// invalidOrganizationNameCharacters matches the characters Quay does not accept in organization names
+	invalidOrganizationNameCharacters = regexp.MustCompile(`[^a-z0-9._-]`)
+	// repeatedOrganizationNameSeparators matches consecutive separators which Quay does not accept in organization names
+	repeatedOrganizationNameSeparators = regexp.MustCompile(`[._-]{2,}`)
+
+	// generatedResourceAnnotations keep Argo CD from reporting the resources created by the operator in the namespaces it
+	// manages as out of sync
+	generatedResourceAnnotations = map[string]string{
+		"argocd.argoproj.io/compare-options": "IgnoreExtraneous",
+	}
+)
+
+// BuilderRobotScope determines which repositories the builder robot account may push to
+// +kubebuilder:validation:Enum=Organization;Repositories
+type BuilderRobotScope string
+
+const (
+	// OrganizationBuilderRobotScope grants write access to every repository of the organization
+	OrganizationBuilderRobotScope BuilderRobotScope = "Organization"
+	// RepositoriesBuilderRobotScope grants write access to the repositories derived from the ImageStreams and BuildConfigs of the namespace
+	RepositoriesBuilderRobotScope BuilderRobotScope = "Repositories"
+)
+
+// SyncHookPoint is a step of the synchronization of a namespace at which hooks are invoked
+// +kubebuilder:validation:Enum=PreOrganizationCreate;PostSecretCreate;PreDelete
+type SyncHookPoint string
+
+const (
+	// PreOrganizationCreateSyncHookPoint is invoked before the organization of a namespace is created
+	PreOrganizationCreateSyncHookPoint SyncHookPoint = "PreOrganizationCreate"
+	// PostSecretCreateSyncHookPoint is invoked once the robot account Secrets of a service account are created
+	PostSecretCreateSyncHookPoint SyncHookPoint = "PostSecretCreate"
+	// PreDeleteSyncHookPoint is invoked before the organization or robot accounts of a deleted namespace are deleted
+	PreDeleteSyncHookPoint SyncHookPoint = "PreDelete"
+)
+
+// SyncHookFailurePolicy determines how failures of a sync hook are handled
+// +kubebuilder:validation:Enum=Fail;Ignore
+type SyncHookFailurePolicy string
+
+const (
+	// FailSyncHookFailurePolicy stops the synchronization of the namespace until the hook succeeds
+	FailSyncHookFailurePolicy SyncHookFailurePolicy = "Fail"
+	// IgnoreSyncHookFailurePolicy records a warning event and continues the synchronization
+	IgnoreSyncHookFailurePolicy SyncHookFailurePolicy = "Ignore"
+)
+
+// OrganizationEmailStrategy determines how the email addresses of organizations are kept unique
+// +kubebuilder:validation:Enum=None;HashSuffix
+type OrganizationEmailStrategy string
+
+const (
+	// NoneOrganizationEmailStrategy uses the rendered email address
+	NoneOrganizationEmailStrategy OrganizationEmailStrategy = "None"
+	// HashSuffixOrganizationEmailStrategy appends a hash of the cluster ID and organization to the local part of the rendered email address
+	HashSuffixOrganizationEmailStrategy OrganizationEmailStrategy = "HashSuffix"
+)
+
+const (
+	// AvailableConditionType is reported when the operator is able to synchronize with Quay
+	AvailableConditionType = "Available"
+	// ProgressingConditionType is reported while the operator is working through pending synchronizations
+	ProgressingConditionType = "Progressing"
+	// DegradedConditionType is reported when one or more components of the operator are unhealthy
+	DegradedConditionType = "Degraded"
+)
+
+const (
+	// ConflictedConditionType reports whether namespaces selected by the QuayIntegration are synchronized by another QuayIntegration with precedence
+	ConflictedConditionType = "Conflicted"
+	// NamespacesOwnedElsewhereReason is the reason of the Conflicted condition when namespaces are synchronized by another QuayIntegration
+	NamespacesOwnedElsewhereReason = "NamespacesOwnedElsewhere"
+	// NoConflictsReason is the reason of the Conflicted condition when the QuayIntegration synchronizes every namespace it selects
+	NoConflictsReason = "NoConflicts"
+)
+
+const (
+	// QuayRegistryAvailableConditionType is reported when QuayRegistryRef is set and reflects the availability of the QuayRegistry
+	QuayRegistryAvailableConditionType = "QuayRegistryAvailable"
+)
+
+const (
+	// InsecureTLSConditionType is reported while TLS verification against Quay is disabled
+	InsecureTLSConditionType = "InsecureTLS"
+	// InsecureTLSReason is the reason associated with the InsecureTLS condition and warning events
+	InsecureTLSReason = "InsecureSkipVerify"
+)
+
+const (
+	// QuayFeaturesSupportedConditionType reports whether the Quay features required by the requested operator features are enabled on Quay
+	QuayFeaturesSupportedConditionType = "QuayFeaturesSupported"
+	// QuayFeaturesSupportedReason is the reason of the QuayFeaturesSupported condition when every required Quay feature is enabled
+	QuayFeaturesSupportedReason = "QuayFeaturesSupported"
+	// QuayFeaturesUnsupportedReason is the reason of the QuayFeaturesSupported condition when required Quay features are disabled
+	QuayFeaturesUnsupportedReason = "QuayFeaturesUnsupported"
+)
+
+const (
+	// QuayVersionSupportedConditionType reports whether the version of Quay is supported by the operator
+	QuayVersionSupportedConditionType = "QuayVersionSupported"
+	// QuayVersionSupportedReason is the reason of the QuayVersionSupported condition when the version of Quay is supported
+	QuayVersionSupportedReason = "QuayVersionSupported"
+	// QuayVersionUnsupportedReason is the reason of the QuayVersionSupported condition when the version of Quay is outside of the supported range
+	QuayVersionUnsupportedReason = "QuayVersionUnsupported"
+	// QuayVersionUnknownReason is the reason of the QuayVersionSupported condition when the version of Quay cannot be determined
+	QuayVersionUnknownReason = "QuayVersionUnknown"
+)
+
+const (
+	// BuildRepositoriesPrivateConditionType reports whether the repositories first pushed to by Builds are created as private repositories
+	BuildRepositoriesPrivateConditionType = "BuildRepositoriesPrivate"
+	// PrivateOnPushReason is the reason of the BuildRepositoriesPrivate condition when Quay creates private repositories on push
+	PrivateOnPushReason = "PrivateOnPush"
+	// CreatedByWebhookReason is the reason of the BuildRepositoriesPrivate condition when the webhook creates the repositories of Builds
+	CreatedByWebhookReason = "CreatedByWebhook"
+	// PublicOnPushReason is the reason of the BuildRepositoriesPrivate condition when repositories first pushed to by Builds are public
+	PublicOnPushReason = "PublicOnPush"
+)
+
+const (
+	// BuildCutoverConditionType reports the phase of the build cutover window, using the phase as the reason
+	BuildCutoverConditionType = "BuildCutover"
+)
+
+const (
+	// BootstrappedConditionType reports whether the first user of Quay has been initialized by the operator when Bootstrap is set
+	BootstrappedConditionType = "Bootstrapped"
+	// BootstrappedReason is the reason of the Bootstrapped condition once the first user has been initialized and its token stored
+	BootstrappedReason = "Bootstrapped"
+	// CredentialsProvidedReason is the reason of the Bootstrapped condition when the credentials Secret already contains a token
+	CredentialsProvidedReason = "CredentialsProvided"
+	// AlreadyInitializedReason is the reason of the Bootstrapped condition when Quay cannot be initialized, as it already has users
+	// or does not enable FEATURE_USER_INITIALIZE
+	AlreadyInitializedReason = "AlreadyInitialized"
+	// BootstrapFailedReason is the reason of the Bootstrapped condition when the initialization failed and is retried
+	BootstrapFailedReason = "BootstrapFailed"
+)
+
+const (
+	// OAuthApplicationReadyConditionType reports whether the Quay OAuth application is managed when OAuthApplication is set
+	OAuthApplicationReadyConditionType = "OAuthApplicationReady"
+	// OAuthApplicationReadyReason is the reason of the OAuthApplicationReady condition when the credentials Secret contains the
+	// client credentials of the OAuth application
+	OAuthApplicationReadyReason = "OAuthApplicationReady"
+	// OAuthApplicationFailedReason is the reason of the OAuthApplicationReady condition when the OAuth application cannot be managed
+	OAuthApplicationFailedReason = "OAuthApplicationFailed"
+	// OAuthApplicationCreatedReason is the reason of the event recorded when the OAuth application is created
+	OAuthApplicationCreatedReason = "OAuthApplicationCreated"
+	// OAuthApplicationRotatedReason is the reason of the event recorded when the client secret of the OAuth application is reset
+	OAuthApplicationRotatedReason = "OAuthApplicationRotated"
+)
+
+const (
+	// OrphanedResourcesReason is the reason of the event listing the orphaned resources found by an orphan cleanup dry run
+	OrphanedResourcesReason = "OrphanedResources"
+	// OrphanedResourcesDeletedReason is the reason of the event listing the orphaned resources deleted by an orphan cleanup
+	OrphanedResourcesDeletedReason = "OrphanedResourcesDeleted"
+)
 
 // QuayIntegrationStatus defines the observed state of QuayIntegration
 type QuayIntegrationStatus struct {
@@ -83,10 +1162,74 @@ type QuayIntegrationStatus struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Updated Time",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	LastUpdate string `json:"lastUpdate,omitempty"`
+
+	// QuayHostname is the hostname of the Quay registry, either configured or discovered from QuayRegistryRef
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Quay hostname",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	QuayHostname string `json:"quayHostname,omitempty"`
+
+	// QuayFeatures are the features enabled on Quay, as published by its configuration
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Quay Features"
+	QuayFeatures []string `json:"quayFeatures,omitempty"`
+
+	// QuayVersion is the version of Quay, either configured or discovered from QuayRegistryRef
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Quay Version",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	QuayVersion string `json:"quayVersion,omitempty"`
+
+	// Features are the features enabled on the operator
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Features"
+	Features []string `json:"features,omitempty"`
+
+	// Permissions are the permissions required by the operator for the enabled features
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Permissions"
+	Permissions []rbacv1.PolicyRule `json:"permissions,omitempty"`
+
+	// SyncedNamespaces is the number of managed namespaces whose most recent synchronization with Quay succeeded
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Synced Namespaces",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	SyncedNamespaces int32 `json:"syncedNamespaces"`
+
+	// ClusterIDMigration reports the progress of the most recent cluster ID migration
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Cluster ID Migration"
+	ClusterIDMigration *ClusterIDMigrationStatus `json:"clusterIDMigration,omitempty"`
+
+	// RegistryHostname is the registry hostname the managed resources refer to
+	// +kubebuilder:validation:Optional
+	RegistryHostname string `json:"registryHostname,omitempty"`
+
+	// RegistryHostnameMigration reports the progress of the most recent migration of the managed resources to a new registry hostname
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Registry Hostname Migration"
+	RegistryHostnameMigration *RegistryHostnameMigrationStatus `json:"registryHostnameMigration,omitempty"`
+
+	// PendingOperations are the changes to Quay queued in offline mode while Quay was unreachable
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pending Operations"
+	PendingOperations []PendingOperation `json:"pendingOperations,omitempty"`
+
+	// OAuthApplication reports the Quay OAuth application managed by the operator
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="OAuth Application"
+	OAuthApplication *OAuthApplicationStatus `json:"oauthApplication,omitempty"`
+
+	// OrphanedResources are the orphaned resources found by the most recent orphan cleanup dry run. Only these resources are
+	// deleted by the next orphan cleanup
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Orphaned Resources"
+	OrphanedResources []string `json:"orphanedResources,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Quay Host",type=string,JSONPath=`.status.quayHostname`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+//+kubebuilder:printcolumn:name="Synced",type=integer,JSONPath=`.status.syncedNamespaces`,description="Namespaces synchronized with Quay"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // QuayIntegration is the Schema for the quayintegrations API
 // +kubebuilder:resource:path=quayintegrations,scope=Cluster
@@ -147,7 +1290,43 @@ var (
 )
 
 func (qi *QuayIntegration) GenerateQuayOrganizationNameFromNamespace(namespace string) string {
-	return fmt.Sprintf("%s_%s", strings.ToLower(qi.Spec.ClusterID), namespace)
+	return NormalizeOrganizationName(fmt.Sprintf("%s_%s", strings.ToLower(qi.Spec.ClusterID), namespace), qi.GetOrganizationNameMaxLength())
+}
+
+// GetOrganizationNameMaxLength returns the maximum length of the names of organizations created in Quay
+func (qi *QuayIntegration) GetOrganizationNameMaxLength() int {
+
+	if qi.Spec.OrganizationNameMaxLength == 0 {
+		return DefaultOrganizationNameMaxLength
+	}
+
+	return int(qi.Spec.OrganizationNameMaxLength)
+}
+
+// NormalizeOrganizationName returns a name accepted by Quay for an organization. Valid names are returned unchanged while
+// invalid characters are otherwise replaced with underscores and the name truncated, suffixed with a hash of the original
+// name so that distinct names remain distinct
+func NormalizeOrganizationName(name string, maxLength int) string {
+
+	if organizationNameRegex.MatchString(name) && len(name) <= maxLength {
+		return name
+	}
+
+	normalized := invalidOrganizationNameCharacters.ReplaceAllString(name, "_")
+	normalized = repeatedOrganizationNameSeparators.ReplaceAllString(normalized, "_")
+
+	if len(normalized) > maxLength-organizationNameHashLength-1 {
+		normalized = normalized[:maxLength-organizationNameHashLength-1]
+	}
+
+	hash := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(hash[:])[:organizationNameHashLength]
+
+	if normalized = strings.Trim(normalized, "._-"); normalized == "" {
+		return suffix
+	}
+
+	return fmt.Sprintf("%s_%s", normalized, suffix)
 }
 
 // IsAllowedNamespace returns whether a namespace is allowed to be managed.
@@ -171,8 +1350,26 @@ func (qi *QuayIntegration) IsAllowedNamespace(namespace string) bool {
 	return len(qi.Spec.AllowlistNamespaces) == 0
 }
 
+// GetQuayHostname returns the configured URL of Quay, normalized when valid, falling back to the URL discovered from QuayRegistryRef
+func (qi *QuayIntegration) GetQuayHostname() string {
+	if qi.Spec.QuayHostname != "" {
+		if quayHostname, err := NormalizeQuayHostname(qi.Spec.QuayHostname); err == nil {
+			return quayHostname
+		}
+		return qi.Spec.QuayHostname
+	}
+
+	return qi.Status.QuayHostname
+}
+
+// GetRegistryHostname returns the host of the Quay registry, including its port when not the default port of the scheme, as used
+// in image references and docker auth entries. Defaults to the host of the Quay API unless a separate registry hostname is configured
 func (qi *QuayIntegration) GetRegistryHostname() (string, error) {
-	quayURL, err := url.Parse(qi.Spec.QuayHostname)
+	if qi.Spec.RegistryHostname != "" {
+		return strings.ToLower(qi.Spec.RegistryHostname), nil
+	}
+
+	quayURL, err := ParseQuayHostname(qi.GetQuayHostname())
 
 	if err != nil {
 		return "", err
@@ -181,6 +1378,325 @@ func (qi *QuayIntegration) GetRegistryHostname() (string, error) {
 	return quayURL.Host, nil
 }
 
+// ParseQuayHostname parses and normalizes the URL of Quay. The scheme must be http or https, default ports and trailing
+// slashes are removed and the scheme and host are lowercased, so that equivalent URLs produce the same registry hostname
+func ParseQuayHostname(hostname string) (*url.URL, error) {
+
+	quayURL, err := url.Parse(strings.TrimSpace(hostname))
+
+	if err != nil {
+		return nil, err
+	}
+
+	quayURL.Scheme = strings.ToLower(quayURL.Scheme)
+
+	if quayURL.Scheme != "http" && quayURL.Scheme != "https" {
+		return nil, fmt.Errorf("Quay hostname '%s' must start with http:// or https://", hostname)
+	}
+
+	if quayURL.Hostname() == "" || quayURL.User != nil || quayURL.RawQuery != "" || quayURL.Fragment != "" {
+		return nil, fmt.Errorf("Quay hostname '%s' must only consist of a scheme, host, optional port and optional path", hostname)
+	}
+
+	host, port := strings.ToLower(quayURL.Hostname()), quayURL.Port()
+
+	if (quayURL.Scheme == "https" && port == "443") || (quayURL.Scheme == "http" && port == "80") {
+		port = ""
+	}
+
+	quayURL.Host = host
+
+	if port != "" {
+		quayURL.Host = net.JoinHostPort(host, port)
+	}
+
+	quayURL.Path = strings.TrimRight(quayURL.Path, "/")
+	quayURL.RawPath = ""
+
+	return quayURL, nil
+}
+
+// NormalizeQuayHostname returns the normalized URL of Quay, see ParseQuayHostname
+func NormalizeQuayHostname(hostname string) (string, error) {
+
+	quayURL, err := ParseQuayHostname(hostname)
+
+	if err != nil {
+		return "", err
+	}
+
+	return quayURL.String(), nil
+}
+
+// IsTLSVerificationDisabled returns whether TLS verification of requests made against Quay is disabled
+func (qi *QuayIntegration) IsTLSVerificationDisabled() bool {
+	return qi.Spec.Insecure || qi.Spec.InsecureRegistry
+}
+
+// GetBuildInputImageMirror returns the mirrored location of an image using the most specific matching BuildInputImageMirrors entry
+func (qi *QuayIntegration) GetBuildInputImageMirror(image string) (string, bool) {
+
+	var matched *ImageMirror
+
+	for i, imageMirror := range qi.Spec.BuildInputImageMirrors {
+
+		source := strings.TrimSuffix(imageMirror.Source, "/")
+
+		if source == "" || !strings.HasPrefix(image, source) {
+			continue
+		}
+
+		// Only match on repository boundaries
+		if remainder := image[len(source):]; remainder != "" && !strings.ContainsAny(remainder[:1], "/:@") {
+			continue
+		}
+
+		if matched == nil || len(source) > len(strings.TrimSuffix(matched.Source, "/")) {
+			matched = &qi.Spec.BuildInputImageMirrors[i]
+		}
+	}
+
+	if matched == nil {
+		return "", false
+	}
+
+	return strings.TrimSuffix(matched.Mirror, "/") + image[len(strings.TrimSuffix(matched.Source, "/")):], true
+}
+
+// GetNamespaceRecreationPolicy returns the configured NamespaceRecreationPolicy, defaulting to Reattach
+func (qi *QuayIntegration) GetNamespaceRecreationPolicy() NamespaceRecreationPolicy {
+
+	if qi.Spec.NamespaceRecreationPolicy == "" {
+		return ReattachNamespaceRecreationPolicy
+	}
+
+	return qi.Spec.NamespaceRecreationPolicy
+}
+
+// GetQuayVersionPolicy returns the configured QuayVersionPolicy, defaulting to Warn
+func (qi *QuayIntegration) GetQuayVersionPolicy() QuayVersionPolicy {
+
+	if qi.Spec.QuayVersionPolicy == "" {
+		return WarnQuayVersionPolicy
+	}
+
+	return qi.Spec.QuayVersionPolicy
+}
+
+// GetBootstrapUsername returns the name of the first user initialized on Quay
+func (qi *QuayIntegration) GetBootstrapUsername() string {
+	if qi.Spec.Bootstrap == nil || qi.Spec.Bootstrap.Username == "" {
+		return DefaultBootstrapUsername
+	}
+
+	return qi.Spec.Bootstrap.Username
+}
+
+// GetOAuthApplicationName returns the name of the Quay OAuth application managed by the operator
+func (qi *QuayIntegration) GetOAuthApplicationName() string {
+
+	if qi.Spec.OAuthApplication != nil && qi.Spec.OAuthApplication.Name != "" {
+		return qi.Spec.OAuthApplication.Name
+	}
+
+	return "quay-bridge-operator-" + qi.Spec.ClusterID
+}
+
+// GetOAuthApplicationRotation returns the time the client secret of the OAuth application is due for rotation, zero when it is
+// not rotated or has not been created
+func (qi *QuayIntegration) GetOAuthApplicationRotation() time.Time {
+
+	if qi.Spec.OAuthApplication == nil || qi.Spec.OAuthApplication.RotationIntervalHours <= 0 || qi.Status.OAuthApplication == nil || qi.Status.OAuthApplication.LastRotation == nil {
+		return time.Time{}
+	}
+
+	return qi.Status.OAuthApplication.LastRotation.Add(time.Duration(qi.Spec.OAuthApplication.RotationIntervalHours) * time.Hour)
+}
+
+// GetBuildCutoverPhase returns the phase of the build cutover window at a point in time, empty when no window is configured
+func (qi *QuayIntegration) GetBuildCutoverPhase(now time.Time) BuildCutoverPhase {
+
+	buildCutover := qi.Spec.BuildCutover
+
+	switch {
+	case buildCutover == nil:
+		return ""
+	case !now.Before(buildCutover.End.Time):
+		return CompleteBuildCutoverPhase
+	case buildCutover.Start != nil && now.Before(buildCutover.Start.Time):
+		return PendingBuildCutoverPhase
+	}
+
+	return ActiveBuildCutoverPhase
+}
+
+// GetBuildCutoverTransition returns the next time the phase of the build cutover window changes, false once the window has ended
+func (qi *QuayIntegration) GetBuildCutoverTransition(now time.Time) (time.Time, bool) {
+
+	switch qi.GetBuildCutoverPhase(now) {
+	case PendingBuildCutoverPhase:
+		return qi.Spec.BuildCutover.Start.Time, true
+	case ActiveBuildCutoverPhase:
+		return qi.Spec.BuildCutover.End.Time, true
+	}
+
+	return time.Time{}, false
+}
+
+// GetBuildPushSecretPolicy returns the configured BuildPushSecretPolicy, defaulting to Preserve
+func (qi *QuayIntegration) GetBuildPushSecretPolicy() PushSecretPolicy {
+
+	if qi.Spec.BuildPushSecretPolicy == "" {
+		return PreservePushSecretPolicy
+	}
+
+	return qi.Spec.BuildPushSecretPolicy
+}
+
+// GetUnprovisionedBuildPolicy returns the configured UnprovisionedBuildPolicy, defaulting to Allow
+func (qi *QuayIntegration) GetUnprovisionedBuildPolicy() UnprovisionedBuildPolicy {
+
+	if qi.Spec.UnprovisionedBuildPolicy == "" {
+		return AllowUnprovisionedBuildPolicy
+	}
+
+	return qi.Spec.UnprovisionedBuildPolicy
+}
+
+// GetReaderRobotName returns the name of the reader robot account, defaulting to reader
+func (qi *QuayIntegration) GetReaderRobotName() string {
+	if qi.Spec.ReaderRobot == nil || qi.Spec.ReaderRobot.Name == "" {
+		return DefaultReaderRobotName
+	}
+
+	return qi.Spec.ReaderRobot.Name
+}
+
+// GetReaderRobotSecretName returns the name of the pull secret containing the reader robot credentials, defaulting to quay-reader-pull-secret
+func (qi *QuayIntegration) GetReaderRobotSecretName() string {
+	if qi.Spec.ReaderRobot == nil || qi.Spec.ReaderRobot.SecretName == "" {
+		return DefaultReaderRobotSecretName
+	}
+
+	return qi.Spec.ReaderRobot.SecretName
+}
+
+// GetBuilderRobotScope returns the configured BuilderRobotScope, defaulting to Organization
+func (qi *QuayIntegration) GetBuilderRobotScope() BuilderRobotScope {
+
+	if qi.Spec.BuilderRobotScope == "" {
+		return OrganizationBuilderRobotScope
+	}
+
+	return qi.Spec.BuilderRobotScope
+}
+
+// GetFailurePolicy returns the failure policy of a sync hook, defaulting to Fail
+func (h *SyncHook) GetFailurePolicy() SyncHookFailurePolicy {
+
+	if h.FailurePolicy == "" {
+		return FailSyncHookFailurePolicy
+	}
+
+	return h.FailurePolicy
+}
+
+// GetTimeout returns the timeout of requests to a sync hook, defaulting to 10 seconds
+func (h *SyncHook) GetTimeout() time.Duration {
+
+	if h.TimeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+// HasPoint returns whether a sync hook is invoked at a step of the synchronization of namespaces
+func (h *SyncHook) HasPoint(point SyncHookPoint) bool {
+
+	for _, p := range h.Points {
+		if p == point {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetOrganizationEmailStrategy returns the strategy keeping the email addresses of organizations unique
+func (qi *QuayIntegration) GetOrganizationEmailStrategy() OrganizationEmailStrategy {
+
+	if qi.Spec.OrganizationEmailStrategy == "" {
+		return NoneOrganizationEmailStrategy
+	}
+
+	return qi.Spec.OrganizationEmailStrategy
+}
+
+// IsRepositoryTrustEnabled returns whether trust is enabled on managed repositories
+func (qi *QuayIntegration) IsRepositoryTrustEnabled() bool {
+	return qi.Spec.ImageSigning != nil && qi.Spec.ImageSigning.RepositoryTrust
+}
+
+// GetSecretFormats returns the Secret formats to generate for each robot account
+func (qi *QuayIntegration) GetSecretFormats() []SecretFormat {
+	if len(qi.Spec.SecretFormats) == 0 {
+		return []SecretFormat{DockerConfigJsonSecretFormat}
+	}
+
+	return qi.Spec.SecretFormats
+}
+
+// ApplyResourceMetadata adds the configured labels and annotations to a resource created by the operator, along with the
+// annotations keeping GitOps tooling from reporting the resource as extraneous. Labels and annotations already present on the
+// resource take precedence, followed by the configured labels and annotations. The resource is always labeled with the name of
+// the QuayIntegration, which identifies it in the inventory of managed resources.
+func (qi *QuayIntegration) ApplyResourceMetadata(obj metav1.Object) {
+	labels := mergeMetadata(obj.GetLabels(), qi.Spec.ResourceLabels)
+
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	labels[QuayIntegrationLabel] = qi.Name
+	obj.SetLabels(labels)
+	obj.SetAnnotations(mergeMetadata(mergeMetadata(obj.GetAnnotations(), qi.Spec.ResourceAnnotations), generatedResourceAnnotations))
+}
+
+// ManagesClusterSecrets returns whether the operator writes the robot account Secrets of service accounts and the global pull secret
+func (qi *QuayIntegration) ManagesClusterSecrets() bool {
+	return !qi.Spec.GitOpsMode
+}
+
+// ProvidesRobotAccountSecrets returns whether the robot account Secrets of service accounts are provided by the operator, either
+// written directly or published to a secret store
+func (qi *QuayIntegration) ProvidesRobotAccountSecrets() bool {
+	return qi.ManagesClusterSecrets() || qi.Spec.SecretStore != nil
+}
+
+// UsesShortLivedCredentials returns whether the Secrets of service accounts written by the operator contain short lived tokens
+func (qi *QuayIntegration) UsesShortLivedCredentials() bool {
+	return qi.Spec.ShortLivedCredentials && qi.ManagesClusterSecrets() && qi.Spec.SecretStore == nil && qi.Spec.CredentialExport == nil
+}
+
+func mergeMetadata(existing map[string]string, additional map[string]string) map[string]string {
+	if len(additional) == 0 {
+		return existing
+	}
+
+	if existing == nil {
+		existing = map[string]string{}
+	}
+
+	for key, value := range additional {
+		if _, found := existing[key]; !found {
+			existing[key] = value
+		}
+	}
+
+	return existing
+}
+
 func (qi *QuayIntegration) SetStatus(status *QuayIntegrationStatus) (*QuayIntegration, error) {
 	qi.Status.LastUpdate = time.Now().UTC().String()
 
@@ -0,0 +1,83 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QuaySyncPolicyAction represents the effect of a QuaySyncPolicy on the namespaces it matches
+// +kubebuilder:validation:Enum=Allow;Deny
+type QuaySyncPolicyAction string
+
+const (
+	// AllowQuaySyncPolicyAction limits synchronization to the namespaces matched by at least one Allow policy
+	AllowQuaySyncPolicyAction QuaySyncPolicyAction = "Allow"
+	// DenyQuaySyncPolicyAction excludes the namespaces it matches from synchronization
+	DenyQuaySyncPolicyAction QuaySyncPolicyAction = "Deny"
+)
+
+// QuaySyncPolicySpec defines the namespaces matched by a QuaySyncPolicy. A namespace is matched when it satisfies every rule set
+type QuaySyncPolicySpec struct {
+
+	// Action is Allow to limit synchronization to the namespaces matched by Allow policies, or Deny to exclude the namespaces matched.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Action"
+	// +kubebuilder:validation:Required
+	Action QuaySyncPolicyAction `json:"action"`
+
+	// NamespacePatterns are shell patterns matched against the names of namespaces, such as openshift-* or team-?-prod. A namespace matches when any pattern matches its name. Every namespace matches when no pattern is set.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Patterns"
+	// +kubebuilder:validation:Optional
+	NamespacePatterns []string `json:"namespacePatterns,omitempty"`
+
+	// NamespaceSelector selects the namespaces matched by their labels.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Selector"
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// OptInAnnotation is an annotation namespaces must set to true to be matched, letting namespace owners opt in to synchronization, such as quay.redhat.com/sync.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Opt-In Annotation",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	// +kubebuilder:validation:Optional
+	OptInAnnotation string `json:"optInAnnotation,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.spec.action`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// QuaySyncPolicy allows or denies the synchronization of namespaces by every QuayIntegration, letting cluster administrators
+// delegate eligibility rules without editing the QuayIntegration
+// +kubebuilder:resource:path=quaysyncpolicies,scope=Cluster
+type QuaySyncPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec QuaySyncPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// QuaySyncPolicyList contains a list of QuaySyncPolicy
+type QuaySyncPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuaySyncPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuaySyncPolicy{}, &QuaySyncPolicyList{})
+}
@@ -21,10 +21,251 @@ limitations under the License.
 package v1
 
 import (
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSecretsManagerSecretStore) DeepCopyInto(out *AWSSecretsManagerSecretStore) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSecretsManagerSecretStore.
+func (in *AWSSecretsManagerSecretStore) DeepCopy() *AWSSecretsManagerSecretStore {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSecretsManagerSecretStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildCutover) DeepCopyInto(out *BuildCutover) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	in.End.DeepCopyInto(&out.End)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildCutover.
+func (in *BuildCutover) DeepCopy() *BuildCutover {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildCutover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogMetadata) DeepCopyInto(out *CatalogMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogMetadata.
+func (in *CatalogMetadata) DeepCopy() *CatalogMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIDMigration) DeepCopyInto(out *ClusterIDMigration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIDMigration.
+func (in *ClusterIDMigration) DeepCopy() *ClusterIDMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIDMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIDMigrationStatus) DeepCopyInto(out *ClusterIDMigrationStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CopiedNamespaces != nil {
+		in, out := &in.CopiedNamespaces, &out.CopiedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetiredNamespaces != nil {
+		in, out := &in.RetiredNamespaces, &out.RetiredNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIDMigrationStatus.
+func (in *ClusterIDMigrationStatus) DeepCopy() *ClusterIDMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIDMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialExport) DeepCopyInto(out *CredentialExport) {
+	*out = *in
+	if in.SealedSecrets != nil {
+		in, out := &in.SealedSecrets, &out.SealedSecrets
+		*out = new(SealedSecretsExport)
+		**out = **in
+	}
+	if in.SOPS != nil {
+		in, out := &in.SOPS, &out.SOPS
+		*out = new(SOPSExport)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialExport.
+func (in *CredentialExport) DeepCopy() *CredentialExport {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageMirror) DeepCopyInto(out *ImageMirror) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageMirror.
+func (in *ImageMirror) DeepCopy() *ImageMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePolicy) DeepCopyInto(out *ImagePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePolicy.
+func (in *ImagePolicy) DeepCopy() *ImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSigning) DeepCopyInto(out *ImageSigning) {
+	*out = *in
+	if in.PublicKeySecret != nil {
+		in, out := &in.PublicKeySecret, &out.PublicKeySecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSigning.
+func (in *ImageSigning) DeepCopy() *ImageSigning {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSigning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuthApplication) DeepCopyInto(out *OAuthApplication) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuthApplication.
+func (in *OAuthApplication) DeepCopy() *OAuthApplication {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuthApplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuthApplicationStatus) DeepCopyInto(out *OAuthApplicationStatus) {
+	*out = *in
+	if in.LastRotation != nil {
+		in, out := &in.LastRotation, &out.LastRotation
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuthApplicationStatus.
+func (in *OAuthApplicationStatus) DeepCopy() *OAuthApplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuthApplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingOperation) DeepCopyInto(out *PendingOperation) {
+	*out = *in
+	in.QueuedAt.DeepCopyInto(&out.QueuedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingOperation.
+func (in *PendingOperation) DeepCopy() *PendingOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuayBootstrap) DeepCopyInto(out *QuayBootstrap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuayBootstrap.
+func (in *QuayBootstrap) DeepCopy() *QuayBootstrap {
+	if in == nil {
+		return nil
+	}
+	out := new(QuayBootstrap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QuayIntegration) DeepCopyInto(out *QuayIntegration) {
 	*out = *in
@@ -92,6 +333,26 @@ func (in *QuayIntegrationSpec) DeepCopyInto(out *QuayIntegrationSpec) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.QuayRegistryRef != nil {
+		in, out := &in.QuayRegistryRef, &out.QuayRegistryRef
+		*out = new(QuayRegistryRef)
+		**out = **in
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(QuayBootstrap)
+		**out = **in
+	}
+	if in.OAuthApplication != nil {
+		in, out := &in.OAuthApplication, &out.OAuthApplication
+		*out = new(OAuthApplication)
+		**out = **in
+	}
+	if in.BuildCutover != nil {
+		in, out := &in.BuildCutover, &out.BuildCutover
+		*out = new(BuildCutover)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DenylistNamespaces != nil {
 		in, out := &in.DenylistNamespaces, &out.DenylistNamespaces
 		*out = make([]string, len(*in))
@@ -102,6 +363,87 @@ func (in *QuayIntegrationSpec) DeepCopyInto(out *QuayIntegrationSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SecretFormats != nil {
+		in, out := &in.SecretFormats, &out.SecretFormats
+		*out = make([]SecretFormat, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrganizationOwners != nil {
+		in, out := &in.OrganizationOwners, &out.OrganizationOwners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceLabels != nil {
+		in, out := &in.ResourceLabels, &out.ResourceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceAnnotations != nil {
+		in, out := &in.ResourceAnnotations, &out.ResourceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BuildInputImageMirrors != nil {
+		in, out := &in.BuildInputImageMirrors, &out.BuildInputImageMirrors
+		*out = make([]ImageMirror, len(*in))
+		copy(*out, *in)
+	}
+	if in.RepositoryNotifications != nil {
+		in, out := &in.RepositoryNotifications, &out.RepositoryNotifications
+		*out = make([]RepositoryNotification, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageSigning != nil {
+		in, out := &in.ImageSigning, &out.ImageSigning
+		*out = new(ImageSigning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePolicy != nil {
+		in, out := &in.ImagePolicy, &out.ImagePolicy
+		*out = new(ImagePolicy)
+		**out = **in
+	}
+	if in.ReaderRobot != nil {
+		in, out := &in.ReaderRobot, &out.ReaderRobot
+		*out = new(ReaderRobot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterIDMigration != nil {
+		in, out := &in.ClusterIDMigration, &out.ClusterIDMigration
+		*out = new(ClusterIDMigration)
+		**out = **in
+	}
+	if in.UserRepositories != nil {
+		in, out := &in.UserRepositories, &out.UserRepositories
+		*out = new(UserRepositories)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncHooks != nil {
+		in, out := &in.SyncHooks, &out.SyncHooks
+		*out = make([]SyncHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CatalogMetadata != nil {
+		in, out := &in.CatalogMetadata, &out.CatalogMetadata
+		*out = new(CatalogMetadata)
+		**out = **in
+	}
+	if in.SecretStore != nil {
+		in, out := &in.SecretStore, &out.SecretStore
+		*out = new(SecretStore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialExport != nil {
+		in, out := &in.CredentialExport, &out.CredentialExport
+		*out = new(CredentialExport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuayIntegrationSpec.
@@ -124,6 +466,50 @@ func (in *QuayIntegrationStatus) DeepCopyInto(out *QuayIntegrationStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.QuayFeatures != nil {
+		in, out := &in.QuayFeatures, &out.QuayFeatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]rbacv1.PolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterIDMigration != nil {
+		in, out := &in.ClusterIDMigration, &out.ClusterIDMigration
+		*out = new(ClusterIDMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistryHostnameMigration != nil {
+		in, out := &in.RegistryHostnameMigration, &out.RegistryHostnameMigration
+		*out = new(RegistryHostnameMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingOperations != nil {
+		in, out := &in.PendingOperations, &out.PendingOperations
+		*out = make([]PendingOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OAuthApplication != nil {
+		in, out := &in.OAuthApplication, &out.OAuthApplication
+		*out = new(OAuthApplicationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OrphanedResources != nil {
+		in, out := &in.OrphanedResources, &out.OrphanedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuayIntegrationStatus.
@@ -136,6 +522,169 @@ func (in *QuayIntegrationStatus) DeepCopy() *QuayIntegrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuaySyncPolicy) DeepCopyInto(out *QuaySyncPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuaySyncPolicy.
+func (in *QuaySyncPolicy) DeepCopy() *QuaySyncPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(QuaySyncPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuaySyncPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuaySyncPolicyList) DeepCopyInto(out *QuaySyncPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuaySyncPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuaySyncPolicyList.
+func (in *QuaySyncPolicyList) DeepCopy() *QuaySyncPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuaySyncPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuaySyncPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuaySyncPolicySpec) DeepCopyInto(out *QuaySyncPolicySpec) {
+	*out = *in
+	if in.NamespacePatterns != nil {
+		in, out := &in.NamespacePatterns, &out.NamespacePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuaySyncPolicySpec.
+func (in *QuaySyncPolicySpec) DeepCopy() *QuaySyncPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuaySyncPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SOPSAWSKMSKey) DeepCopyInto(out *SOPSAWSKMSKey) {
+	*out = *in
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SOPSAWSKMSKey.
+func (in *SOPSAWSKMSKey) DeepCopy() *SOPSAWSKMSKey {
+	if in == nil {
+		return nil
+	}
+	out := new(SOPSAWSKMSKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SOPSExport) DeepCopyInto(out *SOPSExport) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(SOPSVaultTransitKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSKMS != nil {
+		in, out := &in.AWSKMS, &out.AWSKMS
+		*out = new(SOPSAWSKMSKey)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SOPSExport.
+func (in *SOPSExport) DeepCopy() *SOPSExport {
+	if in == nil {
+		return nil
+	}
+	out := new(SOPSExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SOPSVaultTransitKey) DeepCopyInto(out *SOPSVaultTransitKey) {
+	*out = *in
+	if in.TokenSecret != nil {
+		in, out := &in.TokenSecret, &out.TokenSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SOPSVaultTransitKey.
+func (in *SOPSVaultTransitKey) DeepCopy() *SOPSVaultTransitKey {
+	if in == nil {
+		return nil
+	}
+	out := new(SOPSVaultTransitKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SealedSecretsExport) DeepCopyInto(out *SealedSecretsExport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SealedSecretsExport.
+func (in *SealedSecretsExport) DeepCopy() *SealedSecretsExport {
+	if in == nil {
+		return nil
+	}
+	out := new(SealedSecretsExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
@@ -150,3 +699,176 @@ func (in *SecretRef) DeepCopy() *SecretRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretStore) DeepCopyInto(out *SecretStore) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretStore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSSecretsManager != nil {
+		in, out := &in.AWSSecretsManager, &out.AWSSecretsManager
+		*out = new(AWSSecretsManagerSecretStore)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStore.
+func (in *SecretStore) DeepCopy() *SecretStore {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuayRegistryRef) DeepCopyInto(out *QuayRegistryRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuayRegistryRef.
+func (in *QuayRegistryRef) DeepCopy() *QuayRegistryRef {
+	if in == nil {
+		return nil
+	}
+	out := new(QuayRegistryRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReaderRobot) DeepCopyInto(out *ReaderRobot) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReaderRobot.
+func (in *ReaderRobot) DeepCopy() *ReaderRobot {
+	if in == nil {
+		return nil
+	}
+	out := new(ReaderRobot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryHostnameMigrationStatus) DeepCopyInto(out *RegistryHostnameMigrationStatus) {
+	*out = *in
+	if in.PreviousHostnames != nil {
+		in, out := &in.PreviousHostnames, &out.PreviousHostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryHostnameMigrationStatus.
+func (in *RegistryHostnameMigrationStatus) DeepCopy() *RegistryHostnameMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryHostnameMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryNotification) DeepCopyInto(out *RepositoryNotification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryNotification.
+func (in *RepositoryNotification) DeepCopy() *RepositoryNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncHook) DeepCopyInto(out *SyncHook) {
+	*out = *in
+	if in.Points != nil {
+		in, out := &in.Points, &out.Points
+		*out = make([]SyncHookPoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.TokenSecret != nil {
+		in, out := &in.TokenSecret, &out.TokenSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncHook.
+func (in *SyncHook) DeepCopy() *SyncHook {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserRepositories) DeepCopyInto(out *UserRepositories) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserRepositories.
+func (in *UserRepositories) DeepCopy() *UserRepositories {
+	if in == nil {
+		return nil
+	}
+	out := new(UserRepositories)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretStore) DeepCopyInto(out *VaultSecretStore) {
+	*out = *in
+	if in.TokenSecret != nil {
+		in, out := &in.TokenSecret, &out.TokenSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretStore.
+func (in *VaultSecretStore) DeepCopy() *VaultSecretStore {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretStore)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,29 @@
+// Package config embeds the manifests generated from the API types and kubebuilder markers so that the operator can
+// render its own deployment manifests
+package config
+
+import (
+	_ "embed"
+)
+
+var (
+	// QuayIntegrationCRD is the CustomResourceDefinition of QuayIntegrations
+	//go:embed crd/bases/quay.redhat.com_quayintegrations.yaml
+	QuayIntegrationCRD []byte
+
+	// WebhookConfigurations are the MutatingWebhookConfiguration and ValidatingWebhookConfiguration of the operator
+	//go:embed webhook/manifests.yaml
+	WebhookConfigurations []byte
+
+	// PullSecretInjectionWebhookConfiguration is the MutatingWebhookConfiguration only installed along with pull secret injection
+	//go:embed webhook/pull-secret-injection/webhook.yaml
+	PullSecretInjectionWebhookConfiguration []byte
+
+	// SecretProtectionWebhookConfiguration is the ValidatingWebhookConfiguration only installed along with the secret protection webhook
+	//go:embed webhook/secret-protection/webhook.yaml
+	SecretProtectionWebhookConfiguration []byte
+
+	// NamespaceWriterRole is the ClusterRole bound to the service account impersonated within synchronized namespaces
+	//go:embed rbac/namespace_writer_role.yaml
+	NamespaceWriterRole []byte
+)
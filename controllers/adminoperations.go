@@ -0,0 +1,215 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// defaultResyncPollInterval is the interval at which the completion of a requested synchronization is checked
+	defaultResyncPollInterval = 2 * time.Second
+)
+
+// AdminExecutor performs the operations requested through the admin API. Synchronizations are requested by annotating the
+// namespace, which the namespace controller removes once the namespace is synchronized
+type AdminExecutor struct {
+	reconcilerbase.ReconcilerBase
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+
+	// PollInterval is the interval at which the completion of a requested synchronization is checked
+	PollInterval time.Duration
+
+	// OrgActuator changes Quay. The default OrgActuator is used when not set
+	OrgActuator actuator.OrgActuatorFactory
+}
+
+// ResyncNamespace requests the synchronization of a namespace and waits for it to complete
+func (e *AdminExecutor) ResyncNamespace(ctx context.Context, name string) (string, error) {
+
+	namespace, _, err := e.getManagedNamespace(ctx, name)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := e.resync(ctx, namespace); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("namespace %s synchronized", name), nil
+}
+
+// RotateRobot regenerates the token of the robot account of a service account and waits for the namespace to be
+// synchronized, which refreshes its Secrets
+func (e *AdminExecutor) RotateRobot(ctx context.Context, name string, serviceAccount string) (string, error) {
+
+	if _, found := QuayServiceAccountPermissionMatrix[qotypes.OpenShiftServiceAccount(serviceAccount)]; !found {
+		return "", fmt.Errorf("service account '%s' is not managed by the operator", serviceAccount)
+	}
+
+	namespace, quayIntegration, err := e.getManagedNamespace(ctx, name)
+
+	if err != nil {
+		return "", err
+	}
+
+	if _, userRepositories := utils.GetUserAccount(quayIntegration, namespace); userRepositories {
+		return "", fmt.Errorf("rotating robot accounts of namespace %s mapped to a Quay user account is not supported", name)
+	}
+
+	quayOrganizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(name)
+
+	robotName, err := utils.GenerateRobotAccountName(quayIntegration, name, quayOrganizationName, serviceAccount)
+
+	if err != nil {
+		return "", err
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, e.GetClient(), quayIntegration, e.HTTPClientPool)
+
+	if err != nil {
+		return "", err
+	}
+
+	_, regenerateResponse, regenerateErr := newOrgActuator(e.OrgActuator, quayClient).RegenerateOrganizationRobotAccountToken(quayOrganizationName, robotName)
+
+	if regenerateErr.Error != nil || regenerateResponse.StatusCode != 200 {
+		return "", requestError(fmt.Sprintf("error regenerating token of robot account '%s+%s'", quayOrganizationName, robotName), regenerateResponse, regenerateErr.Error)
+	}
+
+	if err := e.resync(ctx, namespace); err != nil {
+		return "", fmt.Errorf("token of robot account '%s+%s' was regenerated but the Secrets were not refreshed: %w", quayOrganizationName, robotName, err)
+	}
+
+	return fmt.Sprintf("token of robot account %s+%s regenerated and Secrets refreshed", quayOrganizationName, robotName), nil
+}
+
+// VerifyIntegration verifies that Quay is healthy and accepts the credentials of a QuayIntegration
+func (e *AdminExecutor) VerifyIntegration(ctx context.Context, name string) (string, error) {
+
+	quayIntegration := &quayv1.QuayIntegration{}
+
+	if err := e.GetClient().Get(ctx, types.NamespacedName{Name: name}, quayIntegration); err != nil {
+		return "", err
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, e.GetClient(), quayIntegration, e.HTTPClientPool)
+
+	if err != nil {
+		return "", err
+	}
+
+	healthResponse, healthErr := quayClient.GetHealth()
+
+	if healthErr.Error != nil || healthResponse.StatusCode != 200 {
+		return "", requestError("Quay is not healthy", healthResponse, healthErr.Error)
+	}
+
+	user, userResponse, userErr := quayClient.GetUser()
+
+	if userErr.Error != nil || userResponse.StatusCode != 200 {
+		return "", requestError("Quay rejected the credentials of the integration", userResponse, userErr.Error)
+	}
+
+	return fmt.Sprintf("Quay is healthy and the integration is authenticated as '%s'", user.Username), nil
+}
+
+// getManagedNamespace returns a namespace synchronized by the operator along with its QuayIntegration
+func (e *AdminExecutor) getManagedNamespace(ctx context.Context, name string) (*corev1.Namespace, *quayv1.QuayIntegration, error) {
+
+	if !cachescope.InNamespaces(e.Namespaces, name) {
+		return nil, nil, fmt.Errorf("namespace %s is not watched by the operator", name)
+	}
+
+	namespace, quayIntegration, err := syncpolicy.GetOwner(ctx, e.GetClient(), name)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if quayIntegration == nil {
+		return nil, nil, fmt.Errorf("namespace %s is not synchronized by any QuayIntegration", name)
+	}
+
+	if reconcilerbase.IsBeingDeleted(namespace) {
+		return nil, nil, fmt.Errorf("namespace %s is being deleted", name)
+	}
+
+	return namespace, quayIntegration, nil
+}
+
+// resync records the time of the request on the namespace, which triggers its synchronization, and waits for the namespace
+// controller to remove the annotation once the namespace is synchronized
+func (e *AdminExecutor) resync(ctx context.Context, namespace *corev1.Namespace) error {
+
+	requested := time.Now().UTC().Format(time.RFC3339Nano)
+
+	err := e.UpdateResource(ctx, namespace, func() error {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[constants.ResyncRequestedAnnotation] = requested
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	interval := e.PollInterval
+
+	if interval <= 0 {
+		interval = defaultResyncPollInterval
+	}
+
+	err = wait.PollImmediateUntil(interval, func() (bool, error) {
+
+		current := &corev1.Namespace{}
+
+		if err := e.GetClient().Get(ctx, types.NamespacedName{Name: namespace.Name}, current); err != nil {
+			return false, err
+		}
+
+		return current.Annotations[constants.ResyncRequestedAnnotation] != requested, nil
+	}, ctx.Done())
+
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("synchronization of namespace %s did not complete in time, see the events of the namespace", namespace.Name)
+	}
+
+	return err
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/auditlog"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// AuditLogForwarder periodically polls the audit logs of the organization of each managed namespace, reporting notable
+// actions performed outside of the operator, such as permission changes, repository deletions or robot accounts created
+// by hand, as events of the namespace and as metrics
+type AuditLogForwarder struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+
+	// Interval between polls
+	Interval time.Duration
+
+	// cursors holds the logs already processed for each namespace
+	cursors map[string]auditlog.Cursor
+}
+
+// Start implements manager.Runnable
+func (a *AuditLogForwarder) Start(ctx context.Context) error {
+
+	a.cursors = map[string]auditlog.Cursor{}
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := a.forward(ctx); err != nil {
+			a.Log.Error(err, "Failed to forward Quay audit logs")
+		}
+	}, a.Interval)
+
+	return nil
+}
+
+func (a *AuditLogForwarder) forward(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := a.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	polled := map[string]bool{}
+
+	for _, quayIntegration := range quayIntegrations.Items {
+
+		namespaces, err := state.ManagedOrganizationNamespaces(ctx, a.GetClient(), &quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		quayClient, err := state.NewQuayClient(ctx, a.GetClient(), quayIntegration.DeepCopy(), a.HTTPClientPool)
+
+		if err != nil {
+			a.Log.Error(err, "Unable to create Quay client", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		user, userResponse, userErr := quayClient.GetUser()
+
+		if userErr.Error != nil || userResponse.StatusCode != 200 {
+			a.Log.Error(requestError("Unable to retrieve the Quay user of the operator", userResponse, userErr.Error), "Unable to forward audit logs", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		for _, namespace := range namespaces {
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if !cachescope.InNamespaces(a.Namespaces, namespace) {
+				continue
+			}
+
+			polled[namespace] = true
+
+			cursor, found := a.cursors[namespace]
+
+			// Only actions performed after the namespace is first polled are reported
+			if !found {
+				a.cursors[namespace] = auditlog.Cursor{Time: time.Now().UTC().Truncate(time.Second)}
+				continue
+			}
+
+			organizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+
+			logs, logsResponse, logsErr := quayClient.GetOrganizationLogs(organizationName, cursor.Time, time.Now())
+
+			if logsErr.Error != nil || logsResponse.StatusCode != 200 {
+				a.Log.Error(requestError(fmt.Sprintf("Unable to retrieve audit logs of organization %s", organizationName), logsResponse, logsErr.Error), "Unable to forward audit logs", "Namespace", namespace)
+				continue
+			}
+
+			entries, next := auditlog.Notable(logs, cursor, user.Username)
+
+			a.cursors[namespace] = next
+
+			if len(entries) > 0 {
+				a.report(ctx, namespace, entries)
+			}
+		}
+	}
+
+	for namespace := range a.cursors {
+		if !polled[namespace] {
+			delete(a.cursors, namespace)
+		}
+	}
+
+	return nil
+}
+
+// report records the notable actions performed on the organization of a namespace
+func (a *AuditLogForwarder) report(ctx context.Context, name string, entries []auditlog.Entry) {
+
+	namespace := &corev1.Namespace{}
+
+	if err := a.GetClient().Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		if !apierrors.IsNotFound(err) {
+			a.Log.Error(err, "Unable to retrieve namespace", "Namespace", name)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		metrics.OutOfBandChanges.WithLabelValues(entry.Reason).Inc()
+		a.GetRecorder().Event(namespace, "Warning", entry.Reason, entry.Message)
+	}
+}
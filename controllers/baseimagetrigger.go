@@ -0,0 +1,216 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	buildv1 "github.com/openshift/api/build/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// baseImageChangedReason is the reason of events recorded when a BuildConfig is instantiated as its base image changed
+	baseImageChangedReason = "BaseImageChanged"
+)
+
+// BaseImageTrigger periodically checks the tags of the Quay images used as builder or base images by annotated BuildConfigs,
+// instantiating a Build when the tag points to a new manifest. ImageChange triggers only apply to ImageStreamTags, so this
+// restores rebuilds on base image updates for images referenced directly in Quay
+type BaseImageTrigger struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+
+	// Interval between checks
+	Interval time.Duration
+}
+
+// Start implements manager.Runnable
+func (b *BaseImageTrigger) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := b.check(ctx); err != nil {
+			b.Log.Error(err, "Failed to check base images")
+		}
+	}, b.Interval)
+
+	return nil
+}
+
+func (b *BaseImageTrigger) check(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := b.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	for _, quayIntegration := range quayIntegrations.Items {
+
+		registryHostname, err := quayIntegration.GetRegistryHostname()
+
+		if err != nil || registryHostname == "" {
+			continue
+		}
+
+		namespaces, err := state.ManagedNamespaces(ctx, b.GetClient(), &quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		quayClient, err := state.NewQuayClient(ctx, b.GetClient(), quayIntegration.DeepCopy(), b.HTTPClientPool)
+
+		if err != nil {
+			b.Log.Error(err, "Unable to create Quay client", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		for _, namespace := range namespaces {
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if !cachescope.InNamespaces(b.Namespaces, namespace) {
+				continue
+			}
+
+			buildConfigs := buildv1.BuildConfigList{}
+
+			if err := b.GetClient().List(ctx, &buildConfigs, client.InNamespace(namespace)); err != nil {
+				b.Log.Error(err, "Unable to list BuildConfigs", "Namespace", namespace)
+				continue
+			}
+
+			for i := range buildConfigs.Items {
+
+				buildConfig := &buildConfigs.Items[i]
+
+				if buildConfig.Annotations[constants.QuayBaseImageTriggerAnnotation] != "true" {
+					continue
+				}
+
+				if err := b.checkBuildConfig(ctx, quayClient, registryHostname, buildConfig); err != nil {
+					b.Log.Error(err, "Unable to check base image of BuildConfig", "Namespace", namespace, "BuildConfig", buildConfig.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkBuildConfig instantiates a BuildConfig when the manifest of its base image changed since it was last checked. The
+// manifest is only recorded the first time a BuildConfig is checked
+func (b *BaseImageTrigger) checkBuildConfig(ctx context.Context, quayClient *qclient.QuayClient, registryHostname string, buildConfig *buildv1.BuildConfig) error {
+
+	image := getBaseDockerImage(buildConfig)
+
+	organizationName, repositoryName, tagName, ok := utils.ParseQuayImageReference(registryHostname, image)
+
+	if !ok {
+		return nil
+	}
+
+	tag, tagResponse, tagErr := quayClient.GetRepositoryTag(organizationName, repositoryName, tagName)
+
+	if tagErr.Error != nil || tagResponse.StatusCode != 200 {
+		return requestError(fmt.Sprintf("Unable to retrieve tag %s", image), tagResponse, tagErr.Error)
+	}
+
+	// Tags being repushed are briefly missing
+	if tag.ManifestDigest == "" {
+		return nil
+	}
+
+	previousDigest, checked := buildConfig.Annotations[constants.QuayBaseImageDigestAnnotation]
+
+	if previousDigest == tag.ManifestDigest {
+		return nil
+	}
+
+	if checked {
+		b.Log.Info("Instantiating BuildConfig as its base image changed", "Namespace", buildConfig.Namespace, "BuildConfig", buildConfig.Name, "Image", image, "Digest", tag.ManifestDigest)
+
+		if err := b.instantiate(ctx, buildConfig, image); err != nil {
+			return err
+		}
+
+		b.GetRecorder().Event(buildConfig, "Normal", baseImageChangedReason, fmt.Sprintf("Base image %s changed to %s", image, tag.ManifestDigest))
+	}
+
+	return b.UpdateResource(ctx, buildConfig, func() error {
+		if buildConfig.Annotations == nil {
+			buildConfig.Annotations = map[string]string{}
+		}
+		buildConfig.Annotations[constants.QuayBaseImageDigestAnnotation] = tag.ManifestDigest
+		return nil
+	})
+}
+
+// instantiate submits a BuildRequest to the instantiate subresource of a BuildConfig
+func (b *BaseImageTrigger) instantiate(ctx context.Context, buildConfig *buildv1.BuildConfig, image string) error {
+
+	resourceInterface, err := b.GetDynamicClientOnGVK(buildv1.GroupVersion.WithKind("BuildConfig"), buildConfig.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	buildRequest := &unstructured.Unstructured{}
+	buildRequest.SetGroupVersionKind(buildv1.GroupVersion.WithKind("BuildRequest"))
+	buildRequest.SetName(buildConfig.Name)
+
+	if err := unstructured.SetNestedSlice(buildRequest.Object, []interface{}{
+		map[string]interface{}{"message": fmt.Sprintf("Base image %s changed in Quay", image)},
+	}, "triggeredBy"); err != nil {
+		return err
+	}
+
+	_, err = resourceInterface.Create(ctx, buildRequest, metav1.CreateOptions{}, "instantiate")
+
+	return err
+}
+
+// getBaseDockerImage returns the image pulled by reference as the builder or base image of a BuildConfig, if any
+func getBaseDockerImage(buildConfig *buildv1.BuildConfig) string {
+
+	from := getStrategyFrom(buildConfig)
+
+	if from == nil || from.Kind != "DockerImage" {
+		return ""
+	}
+
+	return from.Name
+}
@@ -18,7 +18,10 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	buildv1 "github.com/openshift/api/build/v1"
@@ -28,10 +31,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
 	"github.com/quay/quay-bridge-operator/pkg/constants"
 	"github.com/quay/quay-bridge-operator/pkg/core"
 	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/readiness"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -47,12 +58,31 @@ import (
 type BuildIntegrationReconciler struct {
 	CoreComponents core.CoreComponents
 	Log            logr.Logger
+	ReadinessGate  *readiness.Gate
+	Namespaces     []string
+	HTTPClientPool *qclient.HTTPClientPool
+}
+
+// preserveOriginalImportMode imports every manifest of a manifest list instead of only the manifest of the platform of the cluster.
+// The field is set on unstructured ImageStreamImports as it is not part of the vendored image API
+const preserveOriginalImportMode = "PreserveOriginal"
+
+// pushedManifest is the manifest of the image pushed by a Build as resolved in Quay
+type pushedManifest struct {
+	Digest            string
+	IsManifestList    bool
+	PlatformManifests []qclient.PlatformManifest
 }
 
 //+kubebuilder:rbac:groups=build.openshift.io,resources=builds,verbs=get;list;watch;create;update;patch
 
 func (r *BuildIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
+	// Wait for Quay to become ready before importing images from it
+	if r.ReadinessGate != nil && !r.ReadinessGate.IsReady() {
+		return reconcile.Result{RequeueAfter: r.ReadinessGate.RetryAfter()}, nil
+	}
+
 	logging.Log.Info("Reconciling Build", "Request.Namespace", req.Namespace, "Request.Name", req.Name)
 
 	instance := &buildv1.Build{}
@@ -112,6 +142,32 @@ func (r *BuildIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return result, err
 	}
 
+	cutoverPhase := quayIntegration.GetBuildCutoverPhase(time.Now())
+
+	// Consumers pull from Quay once the cutover window has ended, so the ImageStreamTag is no longer updated
+	if cutoverPhase == quayv1.CompleteBuildCutoverPhase {
+		logging.Log.Info("Build cutover complete, skipping ImageStreamTag import", "Namespace", instance.Namespace, "Build", instance.Name)
+
+		if err := r.markImageStreamTagImported(ctx, instance, "false", nil); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       instance,
+				Message:      "Error occurred updating Build",
+				KeyAndValues: []interface{}{"Namespace", instance.Namespace, "Build", instance.Name},
+				Reason:       "ProcessingError",
+				Error:        err,
+			})
+		}
+
+		return reconcile.Result{}, nil
+	}
+
+	// During the cutover window, the ImageStreamTag is served by the internal registry so consumers pulling from it receive the images pushed to Quay
+	referencePolicy := imagev1.SourceTagReferencePolicy
+
+	if cutoverPhase == quayv1.ActiveBuildCutoverPhase {
+		referencePolicy = imagev1.LocalTagReferencePolicy
+	}
+
 	// First, Get the ImageStream
 	existingImageStream := &imagev1.ImageStream{}
 	err = r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: buildImageStreamNamespace, Name: buildImageName}, existingImageStream)
@@ -146,14 +202,27 @@ func (r *BuildIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 						Scheduled: quayIntegration.Spec.ScheduledImageStreamImport,
 					},
 					ReferencePolicy: imagev1.TagReferencePolicy{
-						Type: imagev1.SourceTagReferencePolicy,
+						Type: referencePolicy,
 					},
 				},
 			},
 		},
 	}
 
-	err = r.CoreComponents.ReconcilerBase.GetClient().Create(ctx, isi)
+	quayIntegration.ApplyResourceMetadata(isi)
+
+	// Resolving the pushed manifest only enriches the import and the Build, so failures do not prevent the import
+	manifest, err := r.resolvePushedManifest(ctx, &quayIntegration, instance.Spec.Output.To.Name)
+
+	if err != nil {
+		logging.Log.Error(err, "Unable to resolve manifest pushed by Build", "Namespace", instance.Namespace, "Build", instance.Name, "Image", instance.Spec.Output.To.Name)
+	}
+
+	if manifest != nil && manifest.IsManifestList {
+		err = r.createPreservingImportMode(ctx, isi)
+	} else {
+		err = r.CoreComponents.ReconcilerBase.GetClient().Create(ctx, isi)
+	}
 
 	if err != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
@@ -165,11 +234,7 @@ func (r *BuildIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		})
 	}
 
-	// Update the Build
-	instance.GetAnnotations()[constants.BuildDestinationImageStreamTagImportedAnnotation] = "true"
-
-	err = r.CoreComponents.ReconcilerBase.GetClient().Update(ctx, instance)
-	if err != nil {
+	if err := r.markImageStreamTagImported(ctx, instance, "true", manifest); err != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       instance,
 			Message:      "Error occurred updating Build",
@@ -179,10 +244,192 @@ func (r *BuildIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		})
 	}
 
+	if quayIntegration.Spec.ImageStreamTagArchitectures && manifest != nil && manifest.IsManifestList {
+		if err := r.annotateImageStreamTag(ctx, buildImageStreamNamespace, buildImageName, buildImageTag, manifest); err != nil {
+			logging.Log.Error(err, "Unable to annotate ImageStreamTag with architectures", "Namespace", buildImageStreamNamespace, "ImageStream", buildImageName, "Tag", buildImageTag)
+		}
+	}
+
 	return reconcile.Result{}, nil
 
 }
 
+// markImageStreamTagImported records on a Build whether its ImageStreamTag was imported, along with the manifest pushed to Quay when resolved
+func (r *BuildIntegrationReconciler) markImageStreamTagImported(ctx context.Context, instance *buildv1.Build, imported string, manifest *pushedManifest) error {
+	return r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+
+		if instance.Annotations == nil {
+			instance.Annotations = map[string]string{}
+		}
+
+		instance.Annotations[constants.BuildDestinationImageStreamTagImportedAnnotation] = imported
+
+		if manifest != nil {
+			return setManifestAnnotations(instance.Annotations, manifest)
+		}
+
+		return nil
+	})
+}
+
+// resolvePushedManifest returns the manifest of an image pushed to Quay, along with the manifest of each platform for manifest lists.
+// Nothing is returned for images not pushed to the registry of Quay
+func (r *BuildIntegrationReconciler) resolvePushedManifest(ctx context.Context, quayIntegration *quayv1.QuayIntegration, image string) (*pushedManifest, error) {
+
+	if r.HTTPClientPool == nil {
+		return nil, nil
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return nil, err
+	}
+
+	organizationName, repositoryName, tagName, ok := utils.ParseQuayImageReference(registryHostname, image)
+
+	if !ok {
+		return nil, nil
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, r.CoreComponents.ReconcilerBase.GetClient(), quayIntegration, r.HTTPClientPool)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tag, tagResponse, tagErr := quayClient.GetRepositoryTag(organizationName, repositoryName, tagName)
+
+	if tagErr.Error != nil || tagResponse.StatusCode != 200 {
+		return nil, requestError(fmt.Sprintf("Unable to retrieve tag %s", image), tagResponse, tagErr.Error)
+	}
+
+	if tag.ManifestDigest == "" {
+		return nil, fmt.Errorf("tag %s has no manifest", image)
+	}
+
+	manifest := &pushedManifest{Digest: tag.ManifestDigest, IsManifestList: tag.IsManifestList}
+
+	if !tag.IsManifestList {
+		return manifest, nil
+	}
+
+	listManifest, manifestResponse, manifestErr := quayClient.GetManifest(organizationName, repositoryName, tag.ManifestDigest)
+
+	if manifestErr.Error != nil || manifestResponse.StatusCode != 200 {
+		return manifest, requestError(fmt.Sprintf("Unable to retrieve manifest %s of %s", tag.ManifestDigest, image), manifestResponse, manifestErr.Error)
+	}
+
+	manifest.PlatformManifests, err = listManifest.GetPlatformManifests()
+
+	return manifest, err
+}
+
+// createPreservingImportMode creates an ImageStreamImport importing every manifest of manifest lists
+func (r *BuildIntegrationReconciler) createPreservingImportMode(ctx context.Context, isi *imagev1.ImageStreamImport) error {
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(isi)
+
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(imagev1.GroupVersion.WithKind("ImageStreamImport"))
+
+	images, _, err := unstructured.NestedSlice(obj.Object, "spec", "images")
+
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if err := unstructured.SetNestedField(image.(map[string]interface{}), preserveOriginalImportMode, "importPolicy", "importMode"); err != nil {
+			return err
+		}
+	}
+
+	if err := unstructured.SetNestedSlice(obj.Object, images, "spec", "images"); err != nil {
+		return err
+	}
+
+	return r.CoreComponents.ReconcilerBase.GetClient().Create(ctx, obj)
+}
+
+// annotateImageStreamTag annotates the tag of an ImageStream with the platforms of a manifest list, which is reported on the ImageStreamTag
+func (r *BuildIntegrationReconciler) annotateImageStreamTag(ctx context.Context, namespace string, name string, tag string, manifest *pushedManifest) error {
+
+	imageStream := &imagev1.ImageStream{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, imageStream); err != nil {
+		return err
+	}
+
+	architectures := getArchitectures(manifest)
+
+	for i := range imageStream.Spec.Tags {
+
+		tagReference := &imageStream.Spec.Tags[i]
+
+		if tagReference.Name != tag {
+			continue
+		}
+
+		if tagReference.Annotations[constants.QuayArchitecturesAnnotation] == architectures {
+			return nil
+		}
+
+		if tagReference.Annotations == nil {
+			tagReference.Annotations = map[string]string{}
+		}
+
+		tagReference.Annotations[constants.QuayArchitecturesAnnotation] = architectures
+
+		return r.CoreComponents.ReconcilerBase.GetClient().Update(ctx, imageStream)
+	}
+
+	return fmt.Errorf("tag %s not found in ImageStream %s/%s", tag, namespace, name)
+}
+
+// setManifestAnnotations records the digest of a pushed manifest and, for manifest lists, its platforms and the digest of each platform
+func setManifestAnnotations(annotations map[string]string, manifest *pushedManifest) error {
+
+	annotations[constants.QuayImageDigestAnnotation] = manifest.Digest
+
+	if !manifest.IsManifestList || manifest.PlatformManifests == nil {
+		return nil
+	}
+
+	platformDigests := map[string]string{}
+
+	for _, platformManifest := range manifest.PlatformManifests {
+		platformDigests[platformManifest.Platform] = platformManifest.Digest
+	}
+
+	platformDigestsJSON, err := json.Marshal(platformDigests)
+
+	if err != nil {
+		return err
+	}
+
+	annotations[constants.QuayArchitecturesAnnotation] = getArchitectures(manifest)
+	annotations[constants.QuayPlatformDigestsAnnotation] = string(platformDigestsJSON)
+
+	return nil
+}
+
+// getArchitectures returns the comma separated platforms of a manifest list
+func getArchitectures(manifest *pushedManifest) string {
+
+	platforms := []string{}
+
+	for _, platformManifest := range manifest.PlatformManifests {
+		platforms = append(platforms, platformManifest.Platform)
+	}
+
+	return strings.Join(platforms, ",")
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *BuildIntegrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
@@ -217,5 +464,6 @@ func (r *BuildIntegrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&buildv1.Build{}).
 		Watches(&source.Kind{Type: &buildv1.Build{}}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(buildPredicates...)).
+		WithEventFilter(cachescope.NamespacePredicate(r.Namespaces)).
 		Complete(r)
 }
@@ -0,0 +1,112 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	buildv1 "github.com/openshift/api/build/v1"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/webhook"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuildBackfill periodically scans recent Builds for those admitted without being redirected to Quay and recovers them,
+// covering Builds whose creation was missed or whose recovery failed. Builds are processed one at a time to keep the load low
+type BuildBackfill struct {
+	Recovery   *BuildRecoveryReconciler
+	Log        logr.Logger
+	Namespaces []string
+
+	// Interval between scans
+	Interval time.Duration
+	// Window limits the scan to Builds created within the duration
+	Window time.Duration
+}
+
+// Start implements manager.Runnable
+func (b *BuildBackfill) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := b.scan(ctx); err != nil {
+			b.Log.Error(err, "Failed to scan Builds")
+		}
+	}, b.Interval)
+
+	return nil
+}
+
+func (b *BuildBackfill) scan(ctx context.Context) error {
+
+	namespaces := b.Namespaces
+
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	for _, namespace := range namespaces {
+
+		builds := buildv1.BuildList{}
+
+		if err := b.Recovery.CoreComponents.ReconcilerBase.GetClient().List(ctx, &builds, client.InNamespace(namespace)); err != nil {
+			return err
+		}
+
+		for _, build := range GetBackfillCandidates(builds.Items, time.Now().Add(-b.Window)) {
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			b.Log.Info("Backfilling Build missed by the webhook", "Namespace", build.Namespace, "Build", build.Name)
+
+			if _, err := b.Recovery.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: build.Namespace, Name: build.Name}}); err != nil {
+				b.Log.Error(err, "Failed to backfill Build", "Namespace", build.Namespace, "Build", build.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetBackfillCandidates returns the Builds created after a point in time which were admitted without being redirected to Quay
+// and have not been processed by the recovery
+func GetBackfillCandidates(builds []buildv1.Build, since time.Time) []buildv1.Build {
+
+	candidates := []buildv1.Build{}
+
+	for _, build := range builds {
+
+		if build.CreationTimestamp.Time.Before(since) {
+			continue
+		}
+
+		if _, recovered := build.Annotations[constants.BuildRecoveredAnnotation]; recovered || !webhook.IsMutationMissed(&build) {
+			continue
+		}
+
+		candidates = append(candidates, build)
+	}
+
+	return candidates
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	buildv1 "github.com/openshift/api/build/v1"
+
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/webhook"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// buildRecoveredReason is the reason of events recorded when a Build is replaced by a clone redirected to Quay
+	buildRecoveredReason = "BuildOutputRecovered"
+	// buildNotRedirectedReason is the reason of events recorded when a Build started before it could be redirected to Quay
+	buildNotRedirectedReason = "BuildOutputNotRedirected"
+)
+
+// BuildRecoveryReconciler replaces Builds admitted without being redirected to Quay, such as while the webhook was unavailable.
+// The spec of a Build is immutable, so pending Builds are cancelled and cloned, letting the webhook mutate the clone
+type BuildRecoveryReconciler struct {
+	CoreComponents core.CoreComponents
+	Log            logr.Logger
+	Namespaces     []string
+}
+
+//+kubebuilder:rbac:groups=build.openshift.io,resources=builds,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=build.openshift.io,resources=builds/clone,verbs=create
+
+func (r *BuildRecoveryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+
+	instance := &buildv1.Build{}
+	err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, req.NamespacedName, instance)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	// Clones of a recovered Build inherit its annotations and are not recovered again. Builds which could not be recovered are reported once
+	if _, recovered := instance.Annotations[constants.BuildRecoveredAnnotation]; recovered || !webhook.IsMutationMissed(instance) {
+		return reconcile.Result{}, nil
+	}
+
+	_, owner, err := syncpolicy.GetOwner(ctx, r.CoreComponents.ReconcilerBase.GetClient(), instance.Namespace)
+
+	if err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	if owner == nil {
+		return reconcile.Result{}, nil
+	}
+
+	// Builds which started are reported once, recording the outcome in the recovered annotation
+	if instance.Status.Cancelled || (instance.Status.Phase != buildv1.BuildPhaseNew && instance.Status.Phase != buildv1.BuildPhasePending) {
+
+		r.CoreComponents.ReconcilerBase.GetRecorder().Event(instance, "Warning", buildNotRedirectedReason, "Build started before its output could be redirected to Quay")
+		metrics.MissedBuildMutations.WithLabelValues("not_redirected").Inc()
+
+		if err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+			setBuildRecoveredAnnotation(instance, "false")
+			return nil
+		}); err != nil && !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+
+		return reconcile.Result{}, nil
+	}
+
+	logging.Log.Info("Recovering Build admitted without being redirected to Quay", "Namespace", instance.Namespace, "Build", instance.Name)
+
+	if err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+		setBuildRecoveredAnnotation(instance, "true")
+		return nil
+	}); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       instance,
+			Message:      "Error occurred marking Build as recovered",
+			KeyAndValues: []interface{}{"Namespace", instance.Namespace, "Build", instance.Name},
+			Error:        err,
+		})
+	}
+
+	clone, err := r.cloneBuild(ctx, instance)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       instance,
+			Message:      "Error occurred cloning Build",
+			KeyAndValues: []interface{}{"Namespace", instance.Namespace, "Build", instance.Name},
+			Error:        err,
+		})
+	}
+
+	if err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+		instance.Status.Cancelled = true
+		return nil
+	}); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       instance,
+			Message:      "Error occurred cancelling Build",
+			KeyAndValues: []interface{}{"Namespace", instance.Namespace, "Build", instance.Name},
+			Error:        err,
+		})
+	}
+
+	metrics.MissedBuildMutations.WithLabelValues("recovered").Inc()
+	r.CoreComponents.ReconcilerBase.GetRecorder().Event(instance, "Normal", buildRecoveredReason, fmt.Sprintf("Build replaced by %s redirected to Quay", clone.GetName()))
+
+	return reconcile.Result{}, nil
+}
+
+// cloneBuild submits a BuildRequest to the clone subresource of a Build, returning the new Build
+func (r *BuildRecoveryReconciler) cloneBuild(ctx context.Context, build *buildv1.Build) (*unstructured.Unstructured, error) {
+
+	resourceInterface, err := r.CoreComponents.ReconcilerBase.GetDynamicClientOnGVK(buildv1.GroupVersion.WithKind("Build"), build.Namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	buildRequest := &unstructured.Unstructured{}
+	buildRequest.SetGroupVersionKind(buildv1.GroupVersion.WithKind("BuildRequest"))
+	buildRequest.SetName(build.Name)
+
+	return resourceInterface.Create(ctx, buildRequest, metav1.CreateOptions{}, "clone")
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildRecoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	buildPredicates := predicate.Funcs{
+
+		CreateFunc: func(e event.CreateEvent) bool {
+			build, ok := e.Object.(*buildv1.Build)
+			return ok && webhook.IsMutationMissed(build)
+		},
+
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return false
+		},
+
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildrecovery").
+		For(&buildv1.Build{}).
+		WithEventFilter(buildPredicates).
+		WithEventFilter(cachescope.NamespacePredicate(r.Namespaces)).
+		Complete(r)
+}
+
+func setBuildRecoveredAnnotation(build *buildv1.Build, value string) {
+
+	if build.Annotations == nil {
+		build.Annotations = map[string]string{}
+	}
+
+	build.Annotations[constants.BuildRecoveredAnnotation] = value
+}
@@ -0,0 +1,555 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/jobs"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// MigrateOrganizationJobKind is the kind of the jobs copying an organization and mirroring its repositories
+	MigrateOrganizationJobKind = "MigrateOrganization"
+
+	migrateOrganizationQuayIntegrationParam = "quayIntegration"
+	migrateOrganizationNamespaceParam       = "namespace"
+	migrateOrganizationSourceParam          = "sourceOrganization"
+	migrateOrganizationTargetParam          = "targetOrganization"
+	migrateOrganizationClusterIDParam       = "targetClusterID"
+
+	// clusterIDMigrationPollInterval is the delay before the progress of a migration is checked again
+	clusterIDMigrationPollInterval = 30 * time.Second
+	// clusterIDMigrationReason is the reason of events recorded as a cluster ID migration progresses
+	clusterIDMigrationReason = "ClusterIDMigration"
+	// mirrorSyncInterval is the interval of the mirrors configured during a migration. Mirrors are only synchronized on demand
+	// and disabled once their first synchronization succeeds
+	mirrorSyncInterval = 24 * 60 * 60
+)
+
+// ClusterIDMigrationReconciler moves the organizations managed by a QuayIntegration to the names derived from a new cluster ID.
+// Quay cannot rename organizations, so each organization is copied and its repositories mirrored in a job before ClusterID is
+// updated. The previous organizations are retired once every namespace uses its new organization
+type ClusterIDMigrationReconciler struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Jobs           *jobs.Queue
+	Namespaces     []string
+	Capabilities   *capabilities.Store
+
+	// OrgActuator changes Quay. The default OrgActuator is used when not set
+	OrgActuator actuator.OrgActuatorFactory
+}
+
+func (r *ClusterIDMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("quayintegration", req.NamespacedName)
+
+	instance := &quayv1.QuayIntegration{}
+	err := r.GetClient().Get(ctx, req.NamespacedName, instance)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	migration := instance.Spec.ClusterIDMigration
+
+	if migration == nil {
+		return reconcile.Result{}, nil
+	}
+
+	migrationStatus := instance.Status.ClusterIDMigration.DeepCopy()
+
+	if migrationStatus == nil || migrationStatus.TargetClusterID != migration.ClusterID {
+
+		if instance.Spec.ClusterID == migration.ClusterID {
+			return reconcile.Result{}, nil
+		}
+
+		migrationStatus = &quayv1.ClusterIDMigrationStatus{
+			SourceClusterID: instance.Spec.ClusterID,
+			TargetClusterID: migration.ClusterID,
+			Phase:           quayv1.ClusterIDMigrationCopying,
+		}
+
+		logger.Info("Starting cluster ID migration", "Source Cluster ID", migrationStatus.SourceClusterID, "Target Cluster ID", migrationStatus.TargetClusterID)
+		r.GetRecorder().Event(instance, "Normal", clusterIDMigrationReason, fmt.Sprintf("Migrating organizations from cluster ID %s to %s", migrationStatus.SourceClusterID, migrationStatus.TargetClusterID))
+	}
+
+	phase := migrationStatus.Phase
+	advanced := false
+
+	switch phase {
+	case quayv1.ClusterIDMigrationCopying:
+		advanced, err = r.copyOrganizations(ctx, instance, migrationStatus)
+	case quayv1.ClusterIDMigrationSwitching:
+		advanced, err = r.switchClusterID(ctx, instance, migrationStatus)
+	case quayv1.ClusterIDMigrationRetiring:
+		advanced, err = r.retireOrganizations(ctx, instance, migration, migrationStatus)
+	default:
+		return reconcile.Result{}, nil
+	}
+
+	if err != nil {
+		migrationStatus.Message = err.Error()
+	}
+
+	if statusErr := r.UpdateResourceStatus(ctx, instance, func() error {
+		instance.Status.ClusterIDMigration = migrationStatus.DeepCopy()
+		return nil
+	}); statusErr != nil {
+		return reconcile.Result{}, statusErr
+	}
+
+	if err != nil {
+		return r.ManageError(ctx, instance, err)
+	}
+
+	if !advanced {
+		return reconcile.Result{RequeueAfter: clusterIDMigrationPollInterval}, nil
+	}
+
+	logger.Info("Cluster ID migration advanced", "From", phase, "To", migrationStatus.Phase)
+
+	if migrationStatus.Phase == quayv1.ClusterIDMigrationCompleted {
+		r.GetRecorder().Event(instance, "Normal", clusterIDMigrationReason, fmt.Sprintf("Migrated organizations from cluster ID %s to %s", migrationStatus.SourceClusterID, migrationStatus.TargetClusterID))
+		return reconcile.Result{}, nil
+	}
+
+	return reconcile.Result{Requeue: true}, nil
+}
+
+// copyOrganizations enqueues a job copying the organization of each managed namespace and returns whether every organization has been copied
+func (r *ClusterIDMigrationReconciler) copyOrganizations(ctx context.Context, instance *quayv1.QuayIntegration, migrationStatus *quayv1.ClusterIDMigrationStatus) (bool, error) {
+
+	if r.Jobs == nil {
+		return false, fmt.Errorf("cluster ID migrations require the job queue, enable it with --enable-job-queue")
+	}
+
+	if !r.Capabilities.Get(instance.Name).Supports(capabilities.RepositoryMirroring) {
+		return false, fmt.Errorf("cluster ID migrations require repository mirroring, which is not enabled on Quay")
+	}
+
+	namespaces, err := r.managedNamespaces(ctx, instance)
+
+	if err != nil {
+		return false, err
+	}
+
+	copied := []string{}
+
+	for _, namespace := range namespaces {
+
+		sourceOrganizationName := GenerateOrganizationNameForClusterID(instance, migrationStatus.SourceClusterID, namespace)
+		targetOrganizationName := GenerateOrganizationNameForClusterID(instance, migrationStatus.TargetClusterID, namespace)
+
+		id := jobs.GenerateID(MigrateOrganizationJobKind, targetOrganizationName)
+
+		if job, found := r.Jobs.Get(id); found && job.State == jobs.SucceededState {
+			copied = append(copied, namespace)
+			continue
+		}
+
+		job, err := r.Jobs.Enqueue(ctx, jobs.Job{
+			ID:   id,
+			Kind: MigrateOrganizationJobKind,
+			Params: map[string]string{
+				migrateOrganizationQuayIntegrationParam: instance.Name,
+				migrateOrganizationNamespaceParam:       namespace,
+				migrateOrganizationSourceParam:          sourceOrganizationName,
+				migrateOrganizationTargetParam:          targetOrganizationName,
+				migrateOrganizationClusterIDParam:       migrationStatus.TargetClusterID,
+			},
+		})
+
+		if err != nil {
+			return false, err
+		}
+
+		if job.State == jobs.FailedState {
+			return false, fmt.Errorf("error copying organization '%s' to '%s': %s", sourceOrganizationName, targetOrganizationName, job.Message)
+		}
+	}
+
+	migrationStatus.Namespaces = namespaces
+	migrationStatus.CopiedNamespaces = copied
+
+	if len(copied) < len(namespaces) {
+		migrationStatus.Message = fmt.Sprintf("Copied %d of %d organizations", len(copied), len(namespaces))
+		return false, nil
+	}
+
+	migrationStatus.Phase = quayv1.ClusterIDMigrationSwitching
+	migrationStatus.Message = ""
+
+	return true, nil
+}
+
+// switchClusterID sets ClusterID to the target of the migration, letting the namespaces be synchronized with their new organization
+func (r *ClusterIDMigrationReconciler) switchClusterID(ctx context.Context, instance *quayv1.QuayIntegration, migrationStatus *quayv1.ClusterIDMigrationStatus) (bool, error) {
+
+	if err := r.UpdateResource(ctx, instance, func() error {
+		instance.Spec.ClusterID = migrationStatus.TargetClusterID
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	migrationStatus.Phase = quayv1.ClusterIDMigrationRetiring
+	migrationStatus.Message = ""
+
+	return true, nil
+}
+
+// retireOrganizations retires the previous organization of each namespace using its new organization and returns whether every
+// organization has been retired. Previous organizations are only deleted when requested
+func (r *ClusterIDMigrationReconciler) retireOrganizations(ctx context.Context, instance *quayv1.QuayIntegration, migration *quayv1.ClusterIDMigration, migrationStatus *quayv1.ClusterIDMigrationStatus) (bool, error) {
+
+	retired := map[string]bool{}
+
+	for _, namespace := range migrationStatus.RetiredNamespaces {
+		retired[namespace] = true
+	}
+
+	var quayClient *qclient.QuayClient
+	pending := 0
+
+	for _, namespaceName := range migrationStatus.Namespaces {
+
+		if retired[namespaceName] {
+			continue
+		}
+
+		namespace := &corev1.Namespace{}
+
+		if err := r.GetClient().Get(ctx, types.NamespacedName{Name: namespaceName}, namespace); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		} else if err == nil && namespace.Annotations[constants.QuayOrganizationAnnotation] != GenerateOrganizationNameForClusterID(instance, migrationStatus.TargetClusterID, namespaceName) {
+			pending++
+			continue
+		}
+
+		if migration.DeleteSourceOrganizations {
+
+			if quayClient == nil {
+
+				client, err := state.NewQuayClient(ctx, r.GetClient(), instance.DeepCopy(), r.HTTPClientPool)
+
+				if err != nil {
+					return false, err
+				}
+
+				quayClient = client
+			}
+
+			sourceOrganizationName := GenerateOrganizationNameForClusterID(instance, migrationStatus.SourceClusterID, namespaceName)
+
+			deleteResponse, deleteErr := newOrgActuator(r.OrgActuator, quayClient).DeleteOrganization(sourceOrganizationName)
+
+			if deleteErr.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 404) {
+				return false, requestError(fmt.Sprintf("error deleting organization '%s'", sourceOrganizationName), deleteResponse, deleteErr.Error)
+			}
+
+			r.Log.Info("Deleted organization retired by cluster ID migration", "Organization", sourceOrganizationName)
+		}
+
+		migrationStatus.RetiredNamespaces = append(migrationStatus.RetiredNamespaces, namespaceName)
+	}
+
+	sort.Strings(migrationStatus.RetiredNamespaces)
+
+	if pending > 0 {
+		migrationStatus.Message = fmt.Sprintf("Waiting for %d namespaces to be synchronized with their new organization", pending)
+		return false, nil
+	}
+
+	migrationStatus.Phase = quayv1.ClusterIDMigrationCompleted
+	migrationStatus.Message = ""
+
+	return true, nil
+}
+
+// managedNamespaces returns the namespaces synchronized with the QuayIntegration within the scope of the operator
+func (r *ClusterIDMigrationReconciler) managedNamespaces(ctx context.Context, instance *quayv1.QuayIntegration) ([]string, error) {
+
+	namespaces, err := state.ManagedOrganizationNamespaces(ctx, r.GetClient(), instance)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := []string{}
+
+	for _, namespace := range namespaces {
+		if cachescope.InNamespaces(r.Namespaces, namespace) {
+			scoped = append(scoped, namespace)
+		}
+	}
+
+	return scoped, nil
+}
+
+// runMigrateOrganizationJob copies an organization and mirrors each of its repositories from the source organization. Mirrors are
+// synchronized concurrently by Quay, the checkpoint records the last repository of the sorted list whose mirroring has completed
+func (r *ClusterIDMigrationReconciler) runMigrateOrganizationJob(ctx context.Context, job jobs.Job, checkpoint func(progress string) error) error {
+
+	quayIntegration, err := state.GetQuayIntegration(ctx, r.GetClient(), job.Params[migrateOrganizationQuayIntegrationParam])
+
+	if err != nil {
+		return err
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, r.GetClient(), quayIntegration, r.HTTPClientPool)
+
+	if err != nil {
+		return err
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return err
+	}
+
+	sourceOrganizationName := job.Params[migrateOrganizationSourceParam]
+	targetOrganizationName := job.Params[migrateOrganizationTargetParam]
+
+	// The email address of the new organization is derived from the target cluster ID
+	targetQuayIntegration := quayIntegration.DeepCopy()
+	targetQuayIntegration.Spec.ClusterID = job.Params[migrateOrganizationClusterIDParam]
+
+	organization, err := state.CopyOrganization(quayClient, targetQuayIntegration, sourceOrganizationName, targetOrganizationName, job.Params[migrateOrganizationNamespaceParam])
+
+	if err != nil {
+		return err
+	}
+
+	if len(organization.Repositories) == 0 {
+		return nil
+	}
+
+	robotName, found := GetMirrorRobotName(organization)
+
+	if !found {
+		return fmt.Errorf("organization '%s' has no robot account with write access to mirror its repositories", sourceOrganizationName)
+	}
+
+	sourceRobotAccount, sourceRobotAccountResponse, sourceRobotAccountErr := quayClient.GetOrganizationRobotAccount(sourceOrganizationName, robotName)
+
+	if sourceRobotAccountErr.Error != nil || sourceRobotAccountResponse.StatusCode != 200 {
+		return requestError(fmt.Sprintf("error retrieving robot account '%s' of organization '%s'", robotName, sourceOrganizationName), sourceRobotAccountResponse, sourceRobotAccountErr.Error)
+	}
+
+	mirror := qclient.RepositoryMirror{
+		IsEnabled:                true,
+		ExternalRegistryUsername: sourceRobotAccount.Name,
+		ExternalRegistryPassword: sourceRobotAccount.Token,
+		ExternalRegistryConfig:   map[string]interface{}{"verify_tls": !quayIntegration.IsTLSVerificationDisabled()},
+		SyncInterval:             mirrorSyncInterval,
+		SyncStartDate:            time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		RobotUsername:            utils.FormatOrganizationRobotAccountName(targetOrganizationName, robotName),
+		RootRule:                 qclient.RepositoryMirrorRule{RuleKind: "tag_glob_csv", RuleValue: []string{"*"}},
+	}
+
+	repositoryNames := []string{}
+
+	for _, repository := range organization.Repositories {
+		repositoryNames = append(repositoryNames, repository.Name)
+	}
+
+	sort.Strings(repositoryNames)
+
+	pending := false
+
+	for _, repositoryName := range repositoryNames {
+
+		if repositoryName <= job.Checkpoint {
+			continue
+		}
+
+		mirror.ExternalReference = fmt.Sprintf("%s/%s/%s", registryHostname, sourceOrganizationName, repositoryName)
+
+		mirrored, err := mirrorRepository(quayClient, newOrgActuator(r.OrgActuator, quayClient), sourceOrganizationName, targetOrganizationName, repositoryName, mirror)
+
+		if err != nil {
+			return err
+		}
+
+		if !mirrored {
+			pending = true
+			continue
+		}
+
+		if !pending {
+			if err := checkpoint(repositoryName); err != nil {
+				return err
+			}
+		}
+	}
+
+	if pending {
+		return jobs.ErrPending
+	}
+
+	return nil
+}
+
+// mirrorRepository configures the mirroring of a repository of the source organization into the target organization and returns
+// whether the images have been mirrored. The repository accepts pushes again once mirrored
+func mirrorRepository(quayClient *qclient.QuayClient, orgActuator actuator.OrgActuator, sourceOrganizationName string, targetOrganizationName string, repositoryName string, mirror qclient.RepositoryMirror) (bool, error) {
+
+	repository, repositoryResponse, repositoryErr := quayClient.GetRepository(sourceOrganizationName, repositoryName)
+
+	if repositoryErr.Error != nil || repositoryResponse.StatusCode != 200 {
+		return false, requestError(fmt.Sprintf("error retrieving repository '%s/%s'", sourceOrganizationName, repositoryName), repositoryResponse, repositoryErr.Error)
+	}
+
+	// Repositories without tags have no images to mirror
+	if len(repository.Tags) == 0 {
+		return true, nil
+	}
+
+	existingMirror, mirrorResponse, mirrorErr := quayClient.GetRepositoryMirror(targetOrganizationName, repositoryName)
+
+	if mirrorErr.Error != nil {
+		return false, requestError(fmt.Sprintf("error retrieving mirror of repository '%s/%s'", targetOrganizationName, repositoryName), mirrorResponse, mirrorErr.Error)
+	}
+
+	if mirrorResponse.StatusCode == 404 {
+
+		stateResponse, stateErr := quayClient.ChangeRepositoryState(targetOrganizationName, repositoryName, qclient.RepositoryStateMirror)
+
+		if stateErr.Error != nil || stateResponse.StatusCode != 200 {
+			return false, requestError(fmt.Sprintf("error changing state of repository '%s/%s'", targetOrganizationName, repositoryName), stateResponse, stateErr.Error)
+		}
+
+		createResponse, createErr := orgActuator.CreateRepositoryMirror(targetOrganizationName, repositoryName, mirror)
+
+		if createErr.Error != nil || createResponse.StatusCode != 201 {
+			return false, requestError(fmt.Sprintf("error creating mirror of repository '%s/%s'", targetOrganizationName, repositoryName), createResponse, createErr.Error)
+		}
+
+		return false, syncRepositoryMirror(quayClient, targetOrganizationName, repositoryName)
+	}
+
+	if mirrorResponse.StatusCode != 200 {
+		return false, requestError(fmt.Sprintf("error retrieving mirror of repository '%s/%s'", targetOrganizationName, repositoryName), mirrorResponse, nil)
+	}
+
+	switch existingMirror.SyncStatus {
+	case qclient.MirrorSyncSuccess:
+
+		stateResponse, stateErr := quayClient.ChangeRepositoryState(targetOrganizationName, repositoryName, qclient.RepositoryStateNormal)
+
+		if stateErr.Error != nil || stateResponse.StatusCode != 200 {
+			return false, requestError(fmt.Sprintf("error changing state of repository '%s/%s'", targetOrganizationName, repositoryName), stateResponse, stateErr.Error)
+		}
+
+		return true, nil
+	case qclient.MirrorSyncFailed:
+
+		// The failure consumes an attempt of the job while the synchronization is retried
+		if err := syncRepositoryMirror(quayClient, targetOrganizationName, repositoryName); err != nil {
+			return false, err
+		}
+
+		return false, fmt.Errorf("mirroring of repository '%s/%s' failed", targetOrganizationName, repositoryName)
+	}
+
+	return false, nil
+}
+
+func syncRepositoryMirror(quayClient *qclient.QuayClient, organizationName string, repositoryName string) error {
+
+	syncResponse, syncErr := quayClient.SyncRepositoryMirror(organizationName, repositoryName)
+
+	if syncErr.Error != nil || syncResponse.StatusCode != 204 {
+		return requestError(fmt.Sprintf("error synchronizing mirror of repository '%s/%s'", organizationName, repositoryName), syncResponse, syncErr.Error)
+	}
+
+	return nil
+}
+
+// GetMirrorRobotName returns the robot account of an organization with write access used to mirror its repositories, preferring
+// the robot account of the builder service account
+func GetMirrorRobotName(organization *state.OrganizationState) (string, bool) {
+
+	robotName := ""
+
+	for _, robotAccount := range organization.RobotAccounts {
+
+		if robotAccount.Role != string(qclient.QuayRoleWrite) && robotAccount.Role != string(qclient.QuayRoleAdmin) {
+			continue
+		}
+
+		if robotAccount.Name == string(qotypes.BuilderOpenShiftServiceAccount) {
+			return robotAccount.Name, true
+		}
+
+		if robotName == "" {
+			robotName = robotAccount.Name
+		}
+	}
+
+	return robotName, robotName != ""
+}
+
+// GenerateOrganizationNameForClusterID returns the name of the organization of a namespace derived from a cluster ID
+func GenerateOrganizationNameForClusterID(quayIntegration *quayv1.QuayIntegration, clusterID string, namespace string) string {
+
+	quayIntegration = quayIntegration.DeepCopy()
+	quayIntegration.Spec.ClusterID = clusterID
+
+	return quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterIDMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	if r.Jobs != nil {
+		r.Jobs.Register(MigrateOrganizationJobKind, r.runMigrateOrganizationJob)
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusteridmigration").
+		For(&quayv1.QuayIntegration{}).
+		Complete(r)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/export"
+	"github.com/quay/quay-bridge-operator/pkg/secretstore"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// newCredentialEncrypter returns the Encrypter rendering the manifests of the credential export of a QuayIntegration
+func (r *NamespaceIntegrationReconciler) newCredentialEncrypter(ctx context.Context, credentialExport *quayv1.CredentialExport) (export.Encrypter, error) {
+
+	httpClient := &http.Client{Timeout: secretStoreTimeout}
+
+	switch {
+	case credentialExport.SealedSecrets != nil && credentialExport.SOPS != nil:
+		return nil, fmt.Errorf("only one of sealedSecrets and sops may be set")
+
+	case credentialExport.SealedSecrets != nil:
+		return &export.SealedSecretsEncrypter{
+			Client:         httpClient,
+			CertificateURL: export.GetSealedSecretsCertificateURL(credentialExport.SealedSecrets),
+			Scope:          credentialExport.SealedSecrets.Scope,
+		}, nil
+
+	case credentialExport.SOPS != nil && credentialExport.SOPS.Vault != nil && credentialExport.SOPS.AWSKMS != nil:
+		return nil, fmt.Errorf("only one of vault and awsKms may be set")
+
+	case credentialExport.SOPS != nil && credentialExport.SOPS.Vault != nil:
+		vault := credentialExport.SOPS.Vault
+		token, err := r.getSecretStoreCredential(ctx, vault.TokenSecret, secretstore.DefaultVaultTokenKey, true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &export.SOPSEncrypter{
+			KeyService: &export.SOPSVaultTransitKeyService{
+				Client:     httpClient,
+				Address:    vault.Address,
+				EnginePath: vault.EnginePath,
+				KeyName:    vault.KeyName,
+				Token:      token,
+			},
+		}, nil
+
+	case credentialExport.SOPS != nil && credentialExport.SOPS.AWSKMS != nil:
+		awsKMS := credentialExport.SOPS.AWSKMS
+		credentials, err := r.getAWSCredentials(ctx, awsKMS.CredentialsSecret)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keyService, err := export.NewSOPSAWSKMSKeyService(httpClient, awsKMS.ARN, awsKMS.Endpoint, credentials)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &export.SOPSEncrypter{KeyService: keyService}, nil
+
+	case credentialExport.SOPS != nil:
+		return nil, fmt.Errorf("one of vault and awsKms must be set")
+	}
+
+	return nil, fmt.Errorf("one of sealedSecrets and sops must be set")
+}
+
+// exportCredentials writes the encrypted manifests of the robot account Secrets of a service account to the credential export
+// ConfigMap of its namespace
+func (r *NamespaceIntegrationReconciler) exportCredentials(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration, secrets []*corev1.Secret) (reconcile.Result, error) {
+
+	credentialExport := quayIntegration.Spec.CredentialExport
+
+	if credentialExport == nil || len(secrets) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	configMap, err := r.getCredentialExportConfigMap(ctx, namespace, credentialExport)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Credential Export ConfigMap",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", export.GetConfigMapName(credentialExport)},
+			Error:        err,
+		})
+	}
+
+	encrypter, err := r.newCredentialEncrypter(ctx, credentialExport)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to set up credential export",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Reason:       "ConfigrurationError",
+			Error:        err,
+		})
+	}
+
+	updated := false
+
+	for _, secret := range secrets {
+
+		exported := secret.DeepCopy()
+		exported.Namespace = namespace.Name
+
+		changed, err := export.Export(ctx, configMap, encrypter, exported)
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to encrypt robot account Secret for credential export",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Secret", secret.Name},
+				Error:        err,
+			})
+		}
+
+		updated = updated || changed
+	}
+
+	if !updated {
+		return reconcile.Result{}, nil
+	}
+
+	return r.applyCredentialExportConfigMap(ctx, namespace, quayIntegration, configMap)
+}
+
+// reconcileCredentialExport removes the manifests of Secrets which are no longer generated from the credential export ConfigMap
+// of a namespace, removing the ConfigMap once credential export is disabled
+func (r *NamespaceIntegrationReconciler) reconcileCredentialExport(ctx context.Context, namespace *corev1.Namespace, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	credentialExport := quayIntegration.Spec.CredentialExport
+
+	if credentialExport == nil {
+
+		configMap := &corev1.ConfigMap{}
+
+		err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: export.GetConfigMapName(credentialExport)}, configMap)
+
+		if err == nil && export.IsManagedConfigMap(configMap) {
+			err = r.CoreComponents.ReconcilerBase.DeleteResourceIfExists(ctx, configMap)
+		}
+
+		if err != nil && !errors.IsNotFound(err) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred removing Credential Export ConfigMap",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", configMap.Name},
+				Error:        err,
+			})
+		}
+
+		return reconcile.Result{}, nil
+	}
+
+	configMap, err := r.getCredentialExportConfigMap(ctx, namespace, credentialExport)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Credential Export ConfigMap",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", export.GetConfigMapName(credentialExport)},
+			Error:        err,
+		})
+	}
+
+	// Nothing was exported yet
+	if configMap.ResourceVersion == "" {
+		return reconcile.Result{}, nil
+	}
+
+	secretNames := []string{}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+		for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+			secretName, err := utils.GenerateRobotAccountSecretName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount), secretFormat)
+
+			if err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Invalid Secret name template",
+					KeyAndValues: []interface{}{"Namespace", namespace.Name, "Template", quayIntegration.Spec.SecretNameTemplate},
+					Reason:       "ConfigrurationError",
+					Error:        err,
+				})
+			}
+
+			secretNames = append(secretNames, secretName)
+		}
+	}
+
+	changed, err := export.Prune(configMap, secretNames)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to prune Credential Export ConfigMap",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", configMap.Name},
+			Error:        err,
+		})
+	}
+
+	if !changed {
+		return reconcile.Result{}, nil
+	}
+
+	return r.applyCredentialExportConfigMap(ctx, namespace, quayIntegration, configMap)
+}
+
+// getCredentialExportConfigMap returns the credential export ConfigMap of a namespace, or a new ConfigMap without resource version
+// when it does not exist. ConfigMaps created by users are never modified
+func (r *NamespaceIntegrationReconciler) getCredentialExportConfigMap(ctx context.Context, namespace *corev1.Namespace, credentialExport *quayv1.CredentialExport) (*corev1.ConfigMap, error) {
+
+	configMap := export.NewConfigMap(namespace.Name, credentialExport)
+	existing := &corev1.ConfigMap{}
+
+	err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}, existing)
+
+	if errors.IsNotFound(err) {
+		return configMap, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !export.IsManagedConfigMap(existing) {
+		return nil, fmt.Errorf("ConfigMap %s/%s is not managed by the operator", existing.Namespace, existing.Name)
+	}
+
+	return existing, nil
+}
+
+// applyCredentialExportConfigMap writes the manifests and checksums of a credential export ConfigMap of a namespace
+func (r *NamespaceIntegrationReconciler) applyCredentialExportConfigMap(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration, configMap *corev1.ConfigMap) (reconcile.Result, error) {
+
+	desired := export.NewConfigMap(namespace.Name, quayIntegration.Spec.CredentialExport)
+	desired.Data = configMap.Data
+	desired.Annotations = map[string]string{export.ChecksumsAnnotation: configMap.Annotations[export.ChecksumsAnnotation]}
+
+	quayIntegration.ApplyResourceMetadata(desired)
+
+	if err := r.CoreComponents.ReconcilerBase.ApplyResource(ctx, nil, "", desired); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred writing Credential Export ConfigMap",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", desired.Name},
+			Error:        err,
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/federation"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CredentialRefresher periodically looks for the robot account Secrets containing short lived tokens due for renewal and requests
+// a synchronization of their namespaces, which writes renewed tokens to the Secrets before the previous ones expire
+type CredentialRefresher struct {
+	reconcilerbase.ReconcilerBase
+	Log        logr.Logger
+	Namespaces []string
+
+	// Interval between checks, which must be shorter than the third of the lifetime of the tokens
+	Interval time.Duration
+}
+
+// Start implements manager.Runnable
+func (c *CredentialRefresher) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.refresh(ctx, time.Now()); err != nil {
+			c.Log.Error(err, "Failed to refresh short lived robot account credentials")
+		}
+	}, c.Interval)
+
+	return nil
+}
+
+func (c *CredentialRefresher) refresh(ctx context.Context, now time.Time) error {
+
+	// Only the metadata of the Secrets is retrieved, as the annotations record when their tokens are due
+	secrets := &metav1.PartialObjectMetadataList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+
+	if err := c.GetAPIReader().List(ctx, secrets, client.MatchingLabels{constants.ManagedSecretLabel: "true"}); err != nil {
+		return err
+	}
+
+	due := map[string]bool{}
+
+	for i := range secrets.Items {
+
+		secret := &secrets.Items[i]
+
+		if cachescope.InNamespaces(c.Namespaces, secret.Namespace) && federation.NeedsRefresh(secret, now) {
+			due[secret.Namespace] = true
+		}
+	}
+
+	for name := range due {
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := c.requestResync(ctx, name, now); err != nil {
+			c.Log.Error(err, "Unable to request the synchronization of namespace", "Namespace", name)
+		}
+	}
+
+	return nil
+}
+
+// requestResync records the time of the request on a namespace, which triggers its synchronization, unless a synchronization is
+// already pending
+func (c *CredentialRefresher) requestResync(ctx context.Context, name string, now time.Time) error {
+
+	namespace := &corev1.Namespace{}
+
+	if err := c.GetClient().Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if namespace.DeletionTimestamp != nil || namespace.Annotations[constants.ResyncRequestedAnnotation] != "" {
+		return nil
+	}
+
+	c.Log.Info("Renewing short lived robot account credentials", "Namespace", name)
+
+	return c.UpdateResource(ctx, namespace, func() error {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[constants.ResyncRequestedAnnotation] = now.UTC().Format(time.RFC3339Nano)
+
+		return nil
+	})
+}
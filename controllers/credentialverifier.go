@@ -0,0 +1,291 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/credentials"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// robotCredentialsInvalidReason is the reason of events recorded when Quay rejects the credentials of a robot account Secret
+	robotCredentialsInvalidReason = "RobotCredentialsInvalid"
+	// robotCredentialsRepairedReason is the reason of events recorded once repaired credentials are accepted by Quay again
+	robotCredentialsRepairedReason = "RobotCredentialsRepaired"
+)
+
+// CredentialVerifier periodically verifies that Quay accepts the credentials stored in the robot account Secrets of managed
+// namespaces. Robot accounts deleted in Quay or whose token was invalidated leave pull secrets silently broken, so rejected
+// credentials are repaired by regenerating the token when needed and requesting a synchronization of the namespace, which
+// recreates missing robot accounts and refreshes the Secrets
+type CredentialVerifier struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+
+	// Interval between verifications
+	Interval time.Duration
+
+	// OrgActuator changes Quay. The default OrgActuator is used when not set
+	OrgActuator actuator.OrgActuatorFactory
+}
+
+// Start implements manager.Runnable
+func (v *CredentialVerifier) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := v.verify(ctx); err != nil {
+			v.Log.Error(err, "Failed to verify robot account credentials")
+		}
+	}, v.Interval)
+
+	return nil
+}
+
+func (v *CredentialVerifier) verify(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := v.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	for i := range quayIntegrations.Items {
+
+		quayIntegration := &quayIntegrations.Items[i]
+
+		registryHostname, err := quayIntegration.GetRegistryHostname()
+
+		if err != nil {
+			v.Log.Error(err, "Unable to determine registry hostname", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		namespaces, err := state.ManagedOrganizationNamespaces(ctx, v.GetClient(), quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		quayClient, err := state.NewQuayClient(ctx, v.GetClient(), quayIntegration.DeepCopy(), v.HTTPClientPool)
+
+		if err != nil {
+			v.Log.Error(err, "Unable to create Quay client", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		for _, name := range namespaces {
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if !cachescope.InNamespaces(v.Namespaces, name) {
+				continue
+			}
+
+			if err := v.verifyNamespace(ctx, quayClient, registryHostname, name, quayIntegration); err != nil {
+				v.Log.Error(err, "Unable to verify robot account credentials of namespace", "Namespace", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyNamespace verifies the credentials of the robot account of each service account of a namespace in every configured Secret
+// format. Missing Secrets and Secrets without credentials for the registry are repaired along with rejected credentials
+func (v *CredentialVerifier) verifyNamespace(ctx context.Context, quayClient *qclient.QuayClient, registryHostname string, name string, quayIntegration *quayv1.QuayIntegration) error {
+
+	namespace := &corev1.Namespace{}
+
+	if err := v.GetClient().Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if namespace.DeletionTimestamp != nil {
+		return nil
+	}
+
+	quayOrganizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(name)
+	repairing := false
+
+	// Secrets not written to the namespace by the operator cannot be repaired when missing
+	writesSecrets := quayIntegration.ManagesClusterSecrets() && quayIntegration.Spec.SecretStore == nil && !utils.IsProvisioningPending(namespace)
+
+	// Robot accounts stored in several Secret formats are only verified once for each token
+	verified := map[string]bool{}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+		for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+			secretName, err := utils.GenerateRobotAccountSecretName(quayIntegration, name, quayOrganizationName, string(serviceAccount), secretFormat)
+
+			if err != nil {
+				return err
+			}
+
+			secret := &corev1.Secret{}
+
+			if err := v.GetClient().Get(ctx, types.NamespacedName{Namespace: name, Name: secretName}, secret); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return err
+				}
+
+				secret = nil
+			}
+
+			var username, password string
+			found := false
+
+			if secret != nil {
+				username, password, found = credentials.GetRegistryAuth(secret, registryHostname)
+			}
+
+			if !found {
+
+				if !writesSecrets {
+					continue
+				}
+
+				problem := "is missing"
+
+				if secret != nil {
+					problem = fmt.Sprintf("contains no credentials for %s", registryHostname)
+				}
+
+				v.Log.Info("Robot account Secret cannot be verified", "Namespace", name, "Secret", secretName, "Format", secretFormat, "Problem", problem)
+				v.GetRecorder().Event(namespace, "Warning", robotCredentialsInvalidReason, fmt.Sprintf("Robot account Secret %s of service account %s %s and will be recreated", secretName, serviceAccount, problem))
+
+				repairing = true
+				continue
+			}
+
+			if verified[username+":"+password] {
+				continue
+			}
+
+			verified[username+":"+password] = true
+
+			valid, _, verifyErr := quayClient.VerifyRobotCredentials(username, password)
+
+			if verifyErr.Error != nil {
+				return verifyErr.Error
+			}
+
+			if valid {
+				continue
+			}
+
+			repair, err := v.repairRobotAccount(quayClient, quayOrganizationName, username, password)
+
+			if err != nil {
+				return err
+			}
+
+			v.Log.Info("Robot account credentials rejected by Quay", "Namespace", name, "Secret", secretName, "Robot Account", username, "Repair", repair)
+			v.GetRecorder().Event(namespace, "Warning", robotCredentialsInvalidReason, fmt.Sprintf("Credentials of robot account %s in Secret %s were rejected by Quay: %s", username, secretName, repair))
+
+			repairing = true
+		}
+	}
+
+	if repairing {
+		return v.requestRepair(ctx, namespace)
+	}
+
+	if requested, found := namespace.Annotations[constants.RobotCredentialsRepairAnnotation]; found {
+
+		v.GetRecorder().Event(namespace, "Normal", robotCredentialsRepairedReason, fmt.Sprintf("Robot account credentials rejected by Quay at %s were repaired", requested))
+
+		return v.UpdateResource(ctx, namespace, func() error {
+			delete(namespace.Annotations, constants.RobotCredentialsRepairAnnotation)
+			return nil
+		})
+	}
+
+	return nil
+}
+
+// repairRobotAccount prepares the repair of rejected credentials, returning the action taken. Missing robot accounts and Secrets
+// containing a previous token are repaired by the synchronization of the namespace, while robot accounts whose current token is
+// rejected are issued a new token
+func (v *CredentialVerifier) repairRobotAccount(quayClient *qclient.QuayClient, quayOrganizationName string, username string, password string) (string, error) {
+
+	robotName := strings.TrimPrefix(username, quayOrganizationName+"+")
+
+	robotAccount, robotAccountResponse, robotAccountErr := quayClient.GetOrganizationRobotAccount(quayOrganizationName, robotName)
+
+	if robotAccountErr.Error != nil || (robotAccountResponse.StatusCode != 200 && robotAccountResponse.StatusCode != 400 && robotAccountResponse.StatusCode != 404) {
+		return "", requestError(fmt.Sprintf("error retrieving robot account '%s'", username), robotAccountResponse, robotAccountErr.Error)
+	}
+
+	if robotAccountResponse.StatusCode != 200 {
+		return "robot account no longer exists and will be recreated", nil
+	}
+
+	if robotAccount.Token != password {
+		return "Secret contains a previous token and will be refreshed", nil
+	}
+
+	_, regenerateResponse, regenerateErr := newOrgActuator(v.OrgActuator, quayClient).RegenerateOrganizationRobotAccountToken(quayOrganizationName, robotName)
+
+	if regenerateErr.Error != nil || regenerateResponse.StatusCode != 200 {
+		return "", requestError(fmt.Sprintf("error regenerating token of robot account '%s'", username), regenerateResponse, regenerateErr.Error)
+	}
+
+	return "token was regenerated and the Secret will be refreshed", nil
+}
+
+// requestRepair records the time of the repair on the namespace, which triggers its synchronization
+func (v *CredentialVerifier) requestRepair(ctx context.Context, namespace *corev1.Namespace) error {
+
+	requested := time.Now().UTC().Format(time.RFC3339)
+
+	return v.UpdateResource(ctx, namespace, func() error {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[constants.RobotCredentialsRepairAnnotation] = requested
+
+		return nil
+	})
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/imagepolicy"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/signing"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// imagePolicyErrorReason is the reason of events recorded when the policies of a QuayIntegration cannot be generated
+	imagePolicyErrorReason = "ImagePolicyError"
+)
+
+// ImagePolicyReconciler generates the ImagePolicies or ClusterImagePolicy requiring images of the organizations managed by
+// a QuayIntegration to be signed. Generated policies are tracked in an inventory and pruned once no longer desired
+type ImagePolicyReconciler struct {
+	reconcilerbase.ReconcilerBase
+	Log        logr.Logger
+	Namespaces []string
+}
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterimagepolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=config.openshift.io,resources=imagepolicies,verbs=get;list;watch;create;update;patch;delete
+
+func (r *ImagePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("quayintegration", req.NamespacedName)
+
+	instance := &quayv1.QuayIntegration{}
+	err := r.GetClient().Get(ctx, req.NamespacedName, instance)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Generated policies are owned by the QuayIntegration and garbage collected
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	operatorNamespace, err := r.GetOperatorNamespace()
+
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	objs := []unstructured.Unstructured{}
+
+	if instance.Spec.ImagePolicy != nil {
+
+		gvk := imagepolicy.ImagePolicyGVK
+
+		if imagepolicy.GetScope(instance.Spec.ImagePolicy) == quayv1.ClusterImagePolicyScope {
+			gvk = imagepolicy.ClusterImagePolicyGVK
+		}
+
+		if available, err := r.IsAPIResourceAvailable(gvk); err != nil || !available {
+			logger.Info("Image policy API not available, skipping generation of image policies", "GroupVersionKind", gvk)
+			return reconcile.Result{}, err
+		}
+
+		objs, err = r.generatePolicies(ctx, instance)
+
+		if err != nil {
+			r.GetRecorder().Event(instance, "Warning", imagePolicyErrorReason, err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
+	results, err := r.ReconcileResources(ctx, instance, operatorNamespace, imagepolicy.GenerateInventory(instance.Name), objs)
+
+	if err != nil {
+		// Surface the policies which could not be reconciled while the remaining policies are in effect
+		if failed := reconcilerbase.FailedResults(results); len(failed) > 0 {
+			return r.ManageError(ctx, instance, fmt.Errorf("unable to reconcile %d of %d image policies: %v", len(failed), len(results), err))
+		}
+
+		return r.ManageError(ctx, instance, err)
+	}
+
+	logger.Info("Reconciled image policies", "Count", len(objs))
+
+	if meta.IsStatusConditionFalse(instance.Status.Conditions, reconcilerbase.ReconcileSuccessConditionType) {
+		return r.ManageSuccess(ctx, instance)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// generatePolicies returns the policies covering the organizations of the synchronized namespaces
+func (r *ImagePolicyReconciler) generatePolicies(ctx context.Context, instance *quayv1.QuayIntegration) ([]unstructured.Unstructured, error) {
+
+	imageSigning := instance.Spec.ImageSigning
+
+	if imageSigning == nil || imageSigning.PublicKeySecret == nil {
+		return nil, fmt.Errorf("image policies require imageSigning.publicKeySecret to be set")
+	}
+
+	publicKeySecret := &corev1.Secret{}
+
+	if err := r.GetClient().Get(ctx, types.NamespacedName{Namespace: imageSigning.PublicKeySecret.Namespace, Name: imageSigning.PublicKeySecret.Name}, publicKeySecret); err != nil {
+		return nil, err
+	}
+
+	publicKey, found := publicKeySecret.Data[signing.GetPublicKeySecretKey(imageSigning)]
+
+	if !found {
+		return nil, fmt.Errorf("public key '%s' not found in Secret %s/%s", signing.GetPublicKeySecretKey(imageSigning), publicKeySecret.Namespace, publicKeySecret.Name)
+	}
+
+	registryHostname, err := instance.GetRegistryHostname()
+
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := corev1.NamespaceList{}
+
+	if err := r.GetClient().List(ctx, &namespaces); err != nil {
+		return nil, err
+	}
+
+	syncScope, err := syncpolicy.LoadScope(ctx, r.GetClient())
+
+	if err != nil {
+		return nil, err
+	}
+
+	name := imagepolicy.GenerateName(instance.Name)
+	matchPolicy := imagepolicy.GetMatchPolicy(instance.Spec.ImagePolicy)
+	objs := []unstructured.Unstructured{}
+	scopes := []string{}
+
+	for i := range namespaces.Items {
+
+		namespace := &namespaces.Items[i]
+
+		if namespace.DeletionTimestamp != nil || !syncScope.IsSynchronized(instance, namespace) || !cachescope.InNamespaces(r.Namespaces, namespace.Name) {
+			continue
+		}
+
+		scope := imagepolicy.GenerateScope(registryHostname, utils.GetRepositoryNamespace(instance, namespace.Name, namespace))
+
+		if imagepolicy.GetScope(instance.Spec.ImagePolicy) == quayv1.NamespaceImagePolicyScope {
+			objs = append(objs, *imagepolicy.NewImagePolicy(namespace.Name, name, []string{scope}, publicKey, matchPolicy))
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	if imagepolicy.GetScope(instance.Spec.ImagePolicy) == quayv1.ClusterImagePolicyScope && len(scopes) > 0 {
+		objs = append(objs, *imagepolicy.NewClusterImagePolicy(name, scopes, publicKey, matchPolicy))
+	}
+
+	for i := range objs {
+		instance.ApplyResourceMetadata(&objs[i])
+	}
+
+	return objs, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ImagePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	namespaceToQuayIntegrations := handler.MapFunc(
+		func(a client.Object) []reconcile.Request {
+			res := []reconcile.Request{}
+
+			quayIntegrations := quayv1.QuayIntegrationList{}
+
+			if err := mgr.GetClient().List(context.TODO(), &quayIntegrations); err != nil {
+				return res
+			}
+
+			for _, quayIntegration := range quayIntegrations.Items {
+				if quayIntegration.Spec.ImagePolicy != nil {
+					res = append(res, reconcile.Request{NamespacedName: types.NamespacedName{Name: quayIntegration.Name}})
+				}
+			}
+
+			return res
+		})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("imagepolicy").
+		For(&quayv1.QuayIntegration{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(namespaceToQuayIntegrations), builder.WithPredicates(cachescope.NamespacePredicate(r.Namespaces))).
+		Complete(r)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/imagescan"
+	"github.com/quay/quay-bridge-operator/pkg/jobs"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ImageScanner periodically lists the image references of the Pods, BuildConfigs and ImageStreams of each managed namespace,
+// reporting the references to the internal registry or to unmanaged registries to track the completeness of the migration to Quay
+type ImageScanner struct {
+	reconcilerbase.ReconcilerBase
+	Log        logr.Logger
+	Namespaces []string
+
+	// Interval between scans
+	Interval time.Duration
+	// Store records the references to the internal registry or to unmanaged registries of each namespace. Only metrics are
+	// reported when nil
+	Store jobs.Store
+	// InternalRegistryHostnames are the hostnames of the internal registry in addition to the default hostnames and the
+	// hostnames reported by ImageStreams
+	InternalRegistryHostnames []string
+	// AllowedRegistries are the registries allowed alongside Quay
+	AllowedRegistries []string
+}
+
+// Start implements manager.Runnable
+func (s *ImageScanner) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.scan(ctx); err != nil {
+			s.Log.Error(err, "Failed to scan image references")
+		}
+	}, s.Interval)
+
+	return nil
+}
+
+func (s *ImageScanner) scan(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := s.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	scanner := &imagescan.Scanner{AllowedRegistries: s.AllowedRegistries}
+	namespaces := []string{}
+
+	for i := range quayIntegrations.Items {
+
+		quayIntegration := &quayIntegrations.Items[i]
+
+		if registryHostname, err := quayIntegration.GetRegistryHostname(); err == nil {
+			scanner.QuayHostnames = append(scanner.QuayHostnames, registryHostname)
+		}
+
+		managedNamespaces, err := state.ManagedNamespaces(ctx, s.GetClient(), quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		for _, namespace := range managedNamespaces {
+			if cachescope.InNamespaces(s.Namespaces, namespace) {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+
+	counts := map[string]map[string]int{}
+	report := map[string]string{}
+
+	for _, namespace := range namespaces {
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		hostnames, err := imagescan.DiscoverInternalHostnames(ctx, s.GetAPIReader(), namespace, append(append([]string{}, imagescan.DefaultInternalRegistryHostnames...), s.InternalRegistryHostnames...))
+
+		if err != nil {
+			s.Log.Error(err, "Unable to discover the hostnames of the internal registry", "Namespace", namespace)
+			continue
+		}
+
+		references, err := imagescan.List(ctx, s.GetAPIReader(), namespace)
+
+		if err != nil {
+			s.Log.Error(err, "Unable to list image references", "Namespace", namespace)
+			continue
+		}
+
+		scanner.InternalHostnames = hostnames
+
+		namespaceCounts, findings := scanner.Scan(references)
+		counts[namespace] = namespaceCounts
+
+		if len(findings) == 0 {
+			continue
+		}
+
+		var buffer bytes.Buffer
+
+		if err := imagescan.WriteFindings(&buffer, findings); err != nil {
+			return err
+		}
+
+		report[namespace] = buffer.String()
+	}
+
+	metrics.RecordImageReferences(counts)
+
+	s.Log.Info("Scanned image references", "Namespaces", len(counts), "NonCompliantNamespaces", len(report))
+
+	if s.Store == nil {
+		return nil
+	}
+
+	return s.Store.Save(ctx, report)
+}
@@ -17,39 +17,94 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
-	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	buildv1 "github.com/openshift/api/build/v1"
 	imagev1 "github.com/openshift/api/image/v1"
-	"github.com/redhat-cop/operator-utils/pkg/util"
 
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
 	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/console"
 	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
 
 	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	"github.com/quay/quay-bridge-operator/pkg/catalog"
 	"github.com/quay/quay-bridge-operator/pkg/constants"
 	"github.com/quay/quay-bridge-operator/pkg/core"
 	"github.com/quay/quay-bridge-operator/pkg/credentials"
+	"github.com/quay/quay-bridge-operator/pkg/federation"
+	"github.com/quay/quay-bridge-operator/pkg/hooks"
+	"github.com/quay/quay-bridge-operator/pkg/jobs"
 	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/offline"
+	"github.com/quay/quay-bridge-operator/pkg/priority"
+	"github.com/quay/quay-bridge-operator/pkg/provenance"
+	"github.com/quay/quay-bridge-operator/pkg/pullsecret"
+	"github.com/quay/quay-bridge-operator/pkg/quayregistry"
+	"github.com/quay/quay-bridge-operator/pkg/readiness"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/signing"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/usage"
 	"github.com/quay/quay-bridge-operator/pkg/utils"
+	"github.com/quay/quay-bridge-operator/pkg/version"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+const (
+	// GrantReadAccessJobKind is the kind of the jobs granting a robot account read access to the repositories of an organization
+	GrantReadAccessJobKind = "GrantReadAccess"
+
+	grantReadAccessQuayIntegrationParam = "quayIntegration"
+	grantReadAccessOrganizationParam    = "organization"
+	grantReadAccessRobotAccountParam    = "robotAccount"
+
+	// grantReadAccessPollInterval is the delay before a namespace is synchronized again while read access is being granted
+	grantReadAccessPollInterval = 15 * time.Second
+	// grantReadAccessCheckpointInterval is the number of repositories granted between checkpoints
+	grantReadAccessCheckpointInterval = 20
+
+	// namespaceProvisionedReason is the reason of the event recorded once a namespace created while the operator is running has been provisioned
+	namespaceProvisionedReason = "NamespaceProvisioned"
+	// operationQueuedReason is the reason of the event recorded when a change to Quay is queued in offline mode
+	operationQueuedReason = "QuayOperationQueued"
+	// managedSecretRecreatedReason is the reason of the event recorded when a deleted robot account Secret is recreated
+	managedSecretRecreatedReason = "ManagedSecretRecreated"
+	// managedSecretRevertedReason is the reason of the event recorded when an edit to a robot account Secret is reverted
+	managedSecretRevertedReason = "ManagedSecretReverted"
+)
+
 var (
 	// QuayServiceAccountPermissionMatrix contains a mapping between OpenShift Service Accounts and Quay Roles
 	QuayServiceAccountPermissionMatrix = map[qotypes.OpenShiftServiceAccount]qclient.QuayRole{
@@ -63,19 +118,73 @@ var (
 type NamespaceIntegrationReconciler struct {
 	CoreComponents core.CoreComponents
 	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	ReadinessGate  *readiness.Gate
+	Warmup         *readiness.Warmup
+	Namespaces     []string
+
+	// RoutineThrottle spaces the routine synchronizations of namespaces, such as periodic resyncs
+	RoutineThrottle *priority.Throttle
+
+	// startedAt is the time the controller was set up. Namespaces created before existed when the operator started
+	startedAt time.Time
+
+	// ImpersonationServiceAccount, when set, is the service account created in each synchronized namespace and impersonated
+	// to write Secrets and update service accounts. It is bound to ImpersonationClusterRole
+	ImpersonationServiceAccount string
+	ImpersonationClusterRole    string
+
+	// GlobalPullSecret adds the nodes robot account, granted read access to each organization, to the global pull secret of the cluster
+	GlobalPullSecret bool
+
+	// PullGrants grants namespaces listed by the grant-pull-to annotation read access to the organization of the namespace
+	PullGrants bool
+
+	// ReaderRobot creates the reader robot account of QuayIntegrations defining a readerRobot in each organization
+	ReaderRobot bool
+
+	// Jobs, when set, runs long operations against Quay in the background
+	Jobs *jobs.Queue
+
+	// SecretProtection watches the robot account Secrets of namespaces to recreate them when deleted and revert edits
+	SecretProtection bool
+
+	// OrgActuator changes Quay and SecretActuator writes the Secrets of namespaces. The default implementations are used when not set
+	OrgActuator    actuator.OrgActuatorFactory
+	SecretActuator actuator.SecretActuator
+
+	// Hooks are custom steps registered by products embedding the operator, invoked before the sync hooks of the QuayIntegration
+	Hooks []hooks.Registration
+
+	// ShortLivedCredentials issues the short lived robot account tokens of QuayIntegrations using short lived credentials
+	ShortLivedCredentials *federation.Provider
+
+	// Capabilities are the features detected on Quay by the QuayIntegration controller
+	Capabilities *capabilities.Store
 }
 
 //+kubebuilder:rbac:groups=quay.redhat.com,resources=quayintegrations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=quay.redhat.com,resources=quayintegrations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=quay.redhat.com,resources=quayintegrations/finalizers,verbs=update
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=quay.redhat.com,resources=quaysyncpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;update
 //+kubebuilder:rbac:groups="image.openshift.io",resources=imagestreams;imagestreamimports,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,resourceNames=quay-bridge-operator-writer,verbs=impersonate
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 
+	// Wait for Quay to become ready instead of failing the synchronization of every namespace
+	if r.ReadinessGate != nil && !r.ReadinessGate.IsReady() {
+		return reconcile.Result{RequeueAfter: r.ReadinessGate.RetryAfter()}, nil
+	}
+
+	ctx = withNamespaceWriters(ctx)
+
 	r.Log.Info("Reconciling Namespace", "Name", req.Name)
 
 	// Fetch the Namespace instance
@@ -86,16 +195,16 @@ func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			metrics.ForgetNamespace(req.Name)
+			r.forgetNamespaceWriter(req.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
 
-	// Find the Current Registered QuayIntegration objects
-	quayIntegrations := quayv1.QuayIntegrationList{}
-
-	err = r.CoreComponents.ReconcilerBase.GetClient().List(ctx, &quayIntegrations, &client.ListOptions{})
+	// Find the Current Registered QuayIntegration objects and the QuaySyncPolicies selecting their namespaces
+	syncScope, err := syncpolicy.LoadScope(ctx, r.CoreComponents.ReconcilerBase.GetClient())
 
 	if err != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
@@ -105,23 +214,48 @@ func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 		})
 	}
 
-	if len(quayIntegrations.Items) != 1 {
+	if len(syncScope.QuayIntegrations()) == 0 {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:  instance,
-			Message: "No QuayIntegrations defined or more than 1 integration present",
+			Message: "No QuayIntegrations defined",
 			Reason:  "ConfigrurationError",
 		})
 	}
 
-	quayIntegration := *&quayIntegrations.Items[0]
+	// Check is this is a valid namespace (TODO: Use a predicate to filter out?). Namespaces selected by several QuayIntegrations
+	// are only synchronized by the one with precedence
+	owner := syncScope.Owner(instance)
 
-	// Check is this is a valid namespace (TODO: Use a predicate to filter out?)
-	validNamespace := quayIntegration.IsAllowedNamespace(instance.Name)
+	if owner == nil {
 
-	if !validNamespace {
+		// Namespaces excluded after being synchronized are still cleaned up when deleted to release their finalizer
+		if !reconcilerbase.IsBeingDeleted(instance) || !reconcilerbase.HasFinalizer(instance, constants.NamespaceFinalizer) {
 
-		// Not a synchronized namespace
-		return reconcile.Result{}, nil
+			// Not a synchronized namespace
+			metrics.ForgetNamespace(instance.Name)
+			r.forgetNamespaceWriter(instance.Name)
+
+			if _, labeled := instance.Labels[constants.SynchronizedNamespaceLabel]; labeled && !reconcilerbase.IsBeingDeleted(instance) {
+				return reconcile.Result{}, r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+					delete(instance.Labels, constants.SynchronizedNamespaceLabel)
+					return nil
+				})
+			}
+
+			return reconcile.Result{}, nil
+		}
+
+		owner = &syncScope.QuayIntegrations()[0]
+	}
+
+	quayIntegration := *owner
+
+	// Namespaces existing when the operator starts are synchronized gradually, those missing their Secrets first
+	if delay := r.Warmup.Delay(instance.Name, instance.CreationTimestamp.Time, func() bool {
+		return reconcilerbase.IsBeingDeleted(instance) || r.needsRobotAccountSecrets(ctx, instance.Name, &quayIntegration)
+	}); delay > 0 {
+		r.Log.V(1).Info("Deferring synchronization of namespace during warm-up", "Name", instance.Name, "Delay", delay)
+		return reconcile.Result{RequeueAfter: delay}, nil
 	}
 
 	if quayIntegration.Spec.CredentialsSecret == nil {
@@ -153,41 +287,105 @@ func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 		quaySecretCredentialTokenKey = quayIntegration.Spec.CredentialsSecret.Key
 	}
 
-	if _, ok := secretCredential.Data[quaySecretCredentialTokenKey]; !ok {
+	quayHostname, certificateAuthority, endpointErr := quayregistry.ResolveEndpoint(ctx, r.CoreComponents.ReconcilerBase.GetClient(), &quayIntegration)
+
+	if endpointErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:  instance,
+			Message: "Unable to determine Quay endpoint",
+			Reason:  "ConfigrurationError",
+			Error:   endpointErr,
+		})
+	}
+
+	quayIntegration.Status.QuayHostname = quayHostname
+
+	httpClient, httpClientErr := r.HTTPClientPool.GetHTTPClientWithCA(quayIntegration.IsTLSVerificationDisabled(), certificateAuthority)
+
+	if httpClientErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:  instance,
+			Message: "Unable to trust the Quay certificate authority",
+			Reason:  "ConfigrurationError",
+			Error:   httpClientErr,
+		})
+	}
+
+	// Long lived tokens or short lived tokens exchanged using the credentials of an OAuth application
+	tokenSource, tokenSourceErr := r.HTTPClientPool.GetTokenSource(httpClient, quayHostname, secretCredential.Namespace, secretCredential.Name, secretCredential.Data, quaySecretCredentialTokenKey)
+
+	if tokenSourceErr != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       instance,
-			Message:      fmt.Sprintf("Credential Secret does not contain key '%s'", quaySecretCredentialTokenKey),
+			Message:      tokenSourceErr.Error(),
 			Reason:       "ConfigrurationError",
 			KeyAndValues: []interface{}{"Namespace", quayIntegration.Spec.CredentialsSecret.Namespace, "Secret", quayIntegration.Spec.CredentialsSecret.Name},
 		})
 	}
 
-	authToken := string(secretCredential.Data[constants.QuaySecretCredentialTokenKey])
-
 	// Setup Quay Client
-	quayClient := qclient.NewClient(&http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}, quayIntegration.Spec.QuayHostname, authToken)
+	quayClient := qclient.NewClientWithTokenSource(httpClient, quayHostname, tokenSource)
 
 	// Create Organization
 	quayOrganizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(req.Name)
 
-	if util.IsBeingDeleted(instance) {
-		if !util.HasFinalizer(instance, constants.NamespaceFinalizer) {
+	// Namespaces mapped to a Quay user account keep the organization name to name their robot accounts and Secrets
+	quayUsername, userRepositories := utils.GetUserAccount(&quayIntegration, instance)
+
+	if reconcilerbase.IsBeingDeleted(instance) {
+		if !reconcilerbase.HasFinalizer(instance, constants.NamespaceFinalizer) {
 			return reconcile.Result{}, nil
 		}
 
+		if result, err := r.runSyncHooks(ctx, instance, &quayIntegration, hooks.Event{Point: quayv1.PreDeleteSyncHookPoint, Organization: quayOrganizationName}); err != nil || result.Requeue {
+			return result, err
+		}
+
 		// Remove Resources
-		result, err := r.cleanupResources(req, instance, quayClient, quayOrganizationName)
+		var result reconcile.Result
 
-		if err != nil {
+		if userRepositories {
+			result, err = r.cleanupUserResources(instance, quayClient, quayOrganizationName)
+		} else {
+			result, err = r.cleanupResources(req, instance, quayClient, quayOrganizationName)
+		}
+
+		if (err != nil || result.Requeue) && !userRepositories && quayIntegration.Spec.OfflineMode && !offline.IsReachable(quayClient) {
+
+			// The organization is deleted once Quay is reachable again instead of blocking the deletion of the namespace
+			if err := offline.Enqueue(ctx, r.CoreComponents.ReconcilerBase.GetClient(), quayIntegration.Name, quayv1.PendingOperation{Type: quayv1.DeleteOrganizationOperation, Namespace: instance.Name, Organization: quayOrganizationName}); err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       instance,
+					Message:      "Unable to queue deletion of Organization",
+					KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName},
+					Error:        err,
+				})
+			}
+
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(instance, "Warning", operationQueuedReason, fmt.Sprintf("Quay is unreachable, the deletion of organization %s is queued", quayOrganizationName))
+		} else if err != nil {
+			return result, err
+		}
+
+		if result, err := r.removeStoredSecrets(ctx, instance, quayOrganizationName, &quayIntegration); err != nil || result.Requeue {
+			return result, err
+		}
+
+		if result, err := r.removeGlobalPullSecretAuth(ctx, instance, quayOrganizationName, &quayIntegration); err != nil {
+			return result, err
+		}
+
+		if result, err := r.removePullGrants(ctx, instance, &quayIntegration); err != nil {
 			return result, err
 		}
 
-		util.RemoveFinalizer(instance, constants.NamespaceFinalizer)
-		err = r.CoreComponents.ReconcilerBase.GetClient().Update(ctx, instance)
+		metrics.ForgetNamespace(instance.Name)
+		r.forgetNamespaceWriter(instance.Name)
+
+		err = r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+			reconcilerbase.RemoveFinalizer(instance, constants.NamespaceFinalizer)
+			return nil
+		})
 		if err != nil {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       instance,
@@ -200,8 +398,22 @@ func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 
 	}
 
+	// Namespaces are still cleaned up against unsupported versions of Quay, while new changes are refused
+	if quayIntegration.GetQuayVersionPolicy() == quayv1.EnforceQuayVersionPolicy && quayIntegration.Status.QuayVersion != "" {
+
+		if quayVersion, err := capabilities.ParseVersion(quayIntegration.Status.QuayVersion); err == nil && !quayVersion.IsSupported() {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       instance,
+				Message:      fmt.Sprintf("Quay %s is not supported by the operator, which supports Quay %s", quayVersion, capabilities.SupportedRange()),
+				KeyAndValues: []interface{}{"QuayIntegration", quayIntegration.Name},
+				Reason:       quayv1.QuayVersionUnsupportedReason,
+				Error:        fmt.Errorf("unsupported Quay version '%s'", quayVersion),
+			})
+		}
+	}
+
 	// Finalizer Management
-	if !util.HasFinalizer(instance, constants.NamespaceFinalizer) {
+	if !reconcilerbase.HasFinalizer(instance, constants.NamespaceFinalizer) {
 
 		// Check if OpenShift Project
 		if utils.IsOpenShiftAnnotatedNamespace(instance) {
@@ -212,8 +424,29 @@ func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 
 		}
 
-		util.AddFinalizer(instance, constants.NamespaceFinalizer)
-		err := r.CoreComponents.ReconcilerBase.GetClient().Update(ctx, instance)
+	}
+
+	// The organization is recorded on the namespace, mapping normalized organization names back to the namespace. The label selects the
+	// synchronized namespaces in the configurations of the webhooks only intercepting their resources
+	if !reconcilerbase.HasFinalizer(instance, constants.NamespaceFinalizer) || instance.Annotations[constants.QuayOrganizationAnnotation] != quayOrganizationName || instance.Labels[constants.SynchronizedNamespaceLabel] != "true" {
+
+		err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+			reconcilerbase.AddFinalizer(instance, constants.NamespaceFinalizer)
+
+			if instance.Annotations == nil {
+				instance.Annotations = map[string]string{}
+			}
+
+			instance.Annotations[constants.QuayOrganizationAnnotation] = quayOrganizationName
+
+			if instance.Labels == nil {
+				instance.Labels = map[string]string{}
+			}
+
+			instance.Labels[constants.SynchronizedNamespaceLabel] = "true"
+
+			return nil
+		})
 		if err != nil {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       instance,
@@ -225,19 +458,114 @@ func (r *NamespaceIntegrationReconciler) Reconcile(ctx context.Context, req ctrl
 		return reconcile.Result{}, nil
 	}
 
+	// The namespace was deleted and created again while Quay was unreachable, the previous organization is deleted first
+	if offline.HasOperation(&quayIntegration, quayv1.DeleteOrganizationOperation, quayOrganizationName) {
+
+		if result, err := r.cleanupResources(req, instance, quayClient, quayOrganizationName); err != nil || result.Requeue {
+			return result, err
+		}
+
+		if err := offline.Dequeue(ctx, r.CoreComponents.ReconcilerBase.GetClient(), quayIntegration.Name, quayv1.DeleteOrganizationOperation, quayOrganizationName); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       instance,
+				Message:      "Unable to dequeue deletion of Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName},
+				Error:        err,
+			})
+		}
+	}
+
 	// Setup Resources
-	result, err := r.setupResources(ctx, req, instance, quayClient, quayOrganizationName, quayIntegration.Spec.ClusterID, quayIntegration.Spec.QuayHostname)
+	var result reconcile.Result
+
+	if userRepositories {
+		result, err = r.setupUserResources(ctx, instance, quayClient, quayOrganizationName, quayUsername, &quayIntegration)
+	} else {
+		result, err = r.setupResources(ctx, req, instance, quayClient, quayOrganizationName, &quayIntegration)
+	}
+
+	if err != nil || result.Requeue {
+		metrics.RecordNamespaceSyncFailure(instance.Name)
+
+		if quayIntegration.Spec.OfflineMode && !offline.IsReachable(quayClient) {
+			return r.deferSynchronization(ctx, instance, quayOrganizationName, &quayIntegration)
+		}
 
-	if err != nil {
 		return result, err
 	}
 
-	return reconcile.Result{}, nil
+	if result, err := r.reconcileCatalogMetadata(ctx, instance, quayClient, quayOrganizationName, quayUsername, &quayIntegration); err != nil || result.Requeue {
+		metrics.RecordNamespaceSyncFailure(instance.Name)
+		return result, err
+	}
+
+	if result, err := r.reconcileCredentialExport(ctx, instance, quayOrganizationName, &quayIntegration); err != nil || result.Requeue {
+		metrics.RecordNamespaceSyncFailure(instance.Name)
+		return result, err
+	}
+
+	metrics.RecordNamespaceSyncSuccess(instance.Name)
+
+	if offline.HasOperation(&quayIntegration, quayv1.SyncNamespaceOperation, quayOrganizationName) {
+		if err := offline.Dequeue(ctx, r.CoreComponents.ReconcilerBase.GetClient(), quayIntegration.Name, quayv1.SyncNamespaceOperation, quayOrganizationName); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       instance,
+				Message:      "Unable to dequeue synchronization of namespace",
+				KeyAndValues: []interface{}{"Namespace", instance.Name},
+				Error:        err,
+			})
+		}
+	}
+
+	// Synchronizations requested through the admin API are marked as completed
+	if requested, found := instance.Annotations[constants.ResyncRequestedAnnotation]; found {
+
+		err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+			if instance.Annotations[constants.ResyncRequestedAnnotation] == requested {
+				delete(instance.Annotations, constants.ResyncRequestedAnnotation)
+			}
+			return nil
+		})
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       instance,
+				Message:      "Unable to update namespace",
+				KeyAndValues: []interface{}{"Namespace", instance.Name},
+				Error:        err,
+			})
+		}
+	}
+
+	// Projects annotated when they were requested are marked as provisioned
+	if utils.IsProvisioningPending(instance) {
+
+		err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, instance, func() error {
+			delete(instance.Annotations, constants.NamespaceProvisioningAnnotation)
+			return nil
+		})
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       instance,
+				Message:      "Unable to update namespace",
+				KeyAndValues: []interface{}{"Namespace", instance.Name},
+				Error:        err,
+			})
+		}
+	}
+
+	// Provisioning is only measured for namespaces created while the operator is running
+	if created := instance.CreationTimestamp.Time; created.After(r.startedAt) {
+		if duration := time.Since(created); metrics.RecordNamespaceProvisioned(instance.Name, duration) {
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(instance, "Normal", namespaceProvisionedReason, fmt.Sprintf("Quay organization %s and pull secrets provisioned %s after the namespace was created", quayOrganizationName, duration.Round(time.Second)))
+		}
+	}
+
+	return result, nil
 
 }
 
-func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayName string, quayHostname string) (reconcile.Result, error) {
-	_, organizationResponse, organizationError := quayClient.GetOrganizationByname(quayOrganizationName)
+func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+	organization, organizationResponse, organizationError := quayClient.GetOrganizationByname(quayOrganizationName)
 
 	if organizationError.Error != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
@@ -248,13 +576,29 @@ func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, req
 		})
 	}
 
+	organizationEmail, organizationEmailErr := utils.GenerateOrganizationEmail(quayIntegration, namespace.Name, quayOrganizationName)
+
+	if organizationEmailErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Invalid Organization email template",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Template", quayIntegration.Spec.OrganizationEmailTemplate},
+			Reason:       "ConfigrurationError",
+			Error:        organizationEmailErr,
+		})
+	}
+
 	// Check to see if Organization Exists (Response Code)
 	if organizationResponse.StatusCode == 404 {
 
+		if result, err := r.runSyncHooks(ctx, namespace, quayIntegration, hooks.Event{Point: quayv1.PreOrganizationCreateSyncHookPoint, Organization: quayOrganizationName}); err != nil || result.Requeue {
+			return result, err
+		}
+
 		// Create Organization
 		logging.Log.Info("Organization Does Not Exist", "Name", quayOrganizationName)
 
-		_, createOrganizationResponse, createOrganizationError := quayClient.CreateOrganization(quayOrganizationName)
+		_, createOrganizationResponse, createOrganizationError := r.orgActuator(quayClient).CreateOrganization(quayOrganizationName, organizationEmail)
 
 		if createOrganizationError.Error != nil || createOrganizationResponse.StatusCode != 201 {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
@@ -272,12 +616,54 @@ func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, req
 			Message:      "Error occurred retrieving Quay Organization",
 			KeyAndValues: []interface{}{"Organization", quayOrganizationName},
 		})
+	} else if result, err := r.repairOrganizationEmail(namespace, quayClient, quayOrganizationName, organization, organizationEmail, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	if result, err := r.reconcileNamespaceOwner(ctx, namespace, quayClient, quayOrganizationName, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	if result, err := r.reconcileOrganizationOwners(namespace, quayClient, quayOrganizationName, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	if err := validateRobotAccountSecretNames(quayIntegration, namespace.Name, quayOrganizationName); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Invalid Secret name template",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Template", quayIntegration.Spec.SecretNameTemplate},
+			Reason:       "ConfigrurationError",
+			Error:        err,
+		})
+	}
+
+	if err := validateRobotAccountNames(quayIntegration, namespace.Name, quayOrganizationName); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Invalid robot account name template",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Template", quayIntegration.Spec.RobotNameTemplate},
+			Reason:       "ConfigrurationError",
+			Error:        err,
+		})
+	}
+
+	consoleLinkResult, consoleLinkErr := r.reconcileConsoleLink(ctx, namespace, quayOrganizationName, quayIntegration)
+
+	if consoleLinkErr != nil || consoleLinkResult.Requeue {
+		return consoleLinkResult, consoleLinkErr
+	}
+
+	imageSigningResult, imageSigningErr := r.reconcileImageSigning(ctx, namespace, quayIntegration)
+
+	if imageSigningErr != nil || imageSigningResult.Requeue {
+		return imageSigningResult, imageSigningErr
 	}
 
 	// Create Default Permissions
 	for quayServiceAccountPermissionMatrixKey, quayServiceAccountPermissionMatrixValue := range QuayServiceAccountPermissionMatrix {
 
-		robotAccountResult, robotAccountErr := r.createRobotAccountAssociateToSA(ctx, request, namespace, quayClient, quayOrganizationName, quayServiceAccountPermissionMatrixKey, quayServiceAccountPermissionMatrixValue, quayName, quayHostname)
+		robotAccountResult, robotAccountErr := r.createRobotAccountAssociateToSA(ctx, request, namespace, quayClient, quayOrganizationName, quayServiceAccountPermissionMatrixKey, quayServiceAccountPermissionMatrixValue, quayIntegration)
 
 		if robotAccountErr != nil {
 			return robotAccountResult, robotAccountErr
@@ -285,6 +671,66 @@ func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, req
 
 	}
 
+	if result, err := r.reconcileServiceAccountRobots(ctx, request, namespace, quayClient, quayOrganizationName, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	if result, err := r.reconcileInjectedPullSecrets(ctx, namespace, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	if result, err := r.reconcileRegistryAccessCheck(ctx, namespace, quayClient, quayOrganizationName, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	// Operations completing in the background delay the next synchronization until they are expected to be done
+	pendingResult := reconcile.Result{}
+
+	globalPullSecretResult, globalPullSecretErr := r.reconcileGlobalPullSecret(ctx, namespace, quayClient, quayOrganizationName, quayIntegration)
+
+	if globalPullSecretErr != nil || globalPullSecretResult.Requeue {
+		return globalPullSecretResult, globalPullSecretErr
+	} else if globalPullSecretResult.RequeueAfter > 0 {
+		pendingResult = globalPullSecretResult
+	}
+
+	readerRobotResult, readerRobotErr := r.reconcileReaderRobot(ctx, namespace, quayClient, quayOrganizationName, quayIntegration)
+
+	if readerRobotErr != nil || readerRobotResult.Requeue {
+		return readerRobotResult, readerRobotErr
+	} else if readerRobotResult.RequeueAfter > 0 {
+		pendingResult = readerRobotResult
+	}
+
+	pullGrantsResult, pullGrantsErr := r.reconcilePullGrants(ctx, namespace, quayClient, quayOrganizationName, quayIntegration)
+
+	if pullGrantsErr != nil || pullGrantsResult.Requeue {
+		return pullGrantsResult, pullGrantsErr
+	} else if pullGrantsResult.RequeueAfter > 0 {
+		pendingResult = pullGrantsResult
+	}
+
+	robotRoles := map[string]qclient.QuayRole{}
+
+	if quayIntegration.GetBuilderRobotScope() == quayv1.RepositoriesBuilderRobotScope {
+		// Validated before the robot accounts are reconciled
+		builderRobotName, _ := utils.GenerateRobotAccountName(quayIntegration, namespace.Name, quayOrganizationName, string(qotypes.BuilderOpenShiftServiceAccount))
+		robotRoles[utils.FormatOrganizationRobotAccountName(quayOrganizationName, builderRobotName)] = qclient.QuayRoleWrite
+	}
+
+	if result, err := r.reconcileRepositories(ctx, namespace, quayClient, quayOrganizationName, quayIntegration, robotRoles); err != nil || result.Requeue {
+		return result, err
+	}
+
+	return pendingResult, nil
+
+}
+
+// reconcileRepositories ensures the repositories of the ImageStreams of a namespace and those requested by its BuildConfigs exist in
+// the Quay organization or user account of the namespace, along with their notifications. Robot accounts which cannot rely on the
+// default permissions of an organization are granted their role on each repository
+func (r *NamespaceIntegrationReconciler) reconcileRepositories(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration, robotRoles map[string]qclient.QuayRole) (reconcile.Result, error) {
+
 	// Synchronize Namespaces
 	imageStreams := imagev1.ImageStreamList{}
 
@@ -300,17 +746,81 @@ func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, req
 
 	}
 
+	repositoryNames := []string{}
+	requestedRepositories := map[string]bool{}
+
 	for _, imageStream := range imageStreams.Items {
+		repositoryNames = append(repositoryNames, imageStream.Name)
+		requestedRepositories[imageStream.Name] = true
+	}
+
+	// Include repositories requested by BuildConfigs pushing to a custom destination
+	buildConfigs := buildv1.BuildConfigList{}
+
+	err = r.CoreComponents.ReconcilerBase.GetClient().List(ctx, &buildConfigs, &client.ListOptions{Namespace: namespace.Name})
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error Retrieving BuildConfigs for Namespace",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	for _, buildConfig := range buildConfigs.Items {
+
+		repositoryName, found := buildConfig.Annotations[constants.QuayRepositoryAnnotation]
+
+		// Robot accounts granted their role per repository need access to the repositories of output ImageStreams not created yet
+		if !found && len(robotRoles) > 0 {
+			repositoryName, found = getOutputImageStreamName(&buildConfig, namespace.Name)
+		}
+
+		if !found || requestedRepositories[repositoryName] {
+			continue
+		}
+
+		if !utils.IsValidRepositoryName(repositoryName) {
+			logging.Log.Info("Ignoring invalid repository requested by BuildConfig", "Namespace", namespace.Name, "BuildConfig", buildConfig.Name, "Repository", repositoryName)
+			continue
+		}
+
+		repositoryNames = append(repositoryNames, repositoryName)
+		requestedRepositories[repositoryName] = true
+	}
+
+	triggerNotifications := getTriggerNotifications(namespace.Name, buildConfigs.Items, requestedRepositories)
+
+	repositoryNotifications, err := getRepositoryNotifications(quayIntegration, namespace)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Invalid Repository Notifications",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Annotation", constants.QuayNotificationsAnnotation},
+			Error:        err,
+		})
+	}
+
+	manageTrust, trustEnabled, trustResult, trustErr := r.prepareRepositoryTrust(ctx, namespace, quayIntegration)
+
+	if trustErr != nil || trustResult.Requeue {
+		return trustResult, trustErr
+	}
+
+	trustFailed := false
+
+	for _, repositoryName := range repositoryNames {
 
-		imageStreamName := imageStream.Name
 		// Check if Repository Exists
-		_, repositoryHttpResponse, repositoryErr := quayClient.GetRepository(quayOrganizationName, imageStreamName)
+		repository, repositoryHttpResponse, repositoryErr := quayClient.GetRepository(quayOrganizationName, repositoryName)
 
 		if repositoryErr.Error != nil {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       namespace,
 				Message:      "Error Retrieving Repository",
-				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Name", imageStreamName, "Status Code", repositoryHttpResponse.StatusCode},
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Name", repositoryName, "Status Code", repositoryHttpResponse.StatusCode},
 				Error:        repositoryErr.Error,
 			})
 
@@ -318,243 +828,2322 @@ func (r *NamespaceIntegrationReconciler) setupResources(ctx context.Context, req
 
 		// If an Repository reports back that it cannot be found or permission dened
 		if repositoryHttpResponse.StatusCode == 403 || repositoryHttpResponse.StatusCode == 404 {
-			logging.Log.Info("Creating Repository", "Organization", quayOrganizationName, "Name", imageStreamName)
+			logging.Log.Info("Creating Repository", "Organization", quayOrganizationName, "Name", repositoryName)
 
-			_, createRepositoryResponse, createRepositoryErr := quayClient.CreateRepository(quayOrganizationName, imageStreamName)
+			repositoryDescription, err := provenance.ApplyDescription("", provenance.New(quayIntegration, namespace))
 
-			if createRepositoryErr.Error != nil || createRepositoryResponse.StatusCode != 201 {
+			if err != nil {
 				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 					Object:       namespace,
-					Message:      "Error occurred creating Quay Repository",
-					KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, imageStreamName), "Status Code", createRepositoryResponse.StatusCode},
-					Error:        createRepositoryErr.Error,
+					Message:      "Error occurred recording the provenance of Quay Repository",
+					KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName)},
+					Error:        err,
 				})
-
 			}
 
+			_, createRepositoryResponse, createRepositoryErr := r.orgActuator(quayClient).CreateRepositoryWithDescription(quayOrganizationName, repositoryName, repositoryDescription)
+
+			if createRepositoryErr.Error != nil || createRepositoryResponse.StatusCode != 201 {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Error occurred creating Quay Repository",
+					KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName), "Status Code", createRepositoryResponse.StatusCode},
+					Error:        createRepositoryErr.Error,
+				})
+
+			}
+
+			repository = qclient.Repository{}
+
 		} else if repositoryHttpResponse.StatusCode != 200 {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       namespace,
 				Message:      "Error Retrieving Repository for Namespace",
-				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, imageStreamName), "Status Code", repositoryHttpResponse.StatusCode},
+				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName), "Status Code", repositoryHttpResponse.StatusCode},
+			})
+		} else if err := r.reconcileRepositoryProvenance(namespace, quayClient, quayOrganizationName, repository, quayIntegration); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred recording the provenance of Quay Repository",
+				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName)},
+				Error:        err,
 			})
 		}
 
+		if manageTrust && repository.TrustEnabled != trustEnabled && !r.changeRepositoryTrust(namespace, quayClient, quayOrganizationName, repositoryName, trustEnabled) {
+			trustFailed = true
+		}
+
+		desiredNotifications := append(append([]qclient.NotificationRequest{}, repositoryNotifications...), triggerNotifications[repositoryName]...)
+
+		notificationsResult, notificationsErr := r.reconcileRepositoryNotifications(namespace, quayClient, quayOrganizationName, repositoryName, desiredNotifications)
+
+		if notificationsErr != nil || notificationsResult.Requeue {
+			return notificationsResult, notificationsErr
+		}
+
+		if len(robotRoles) > 0 {
+			if result, err := r.reconcileRepositoryPermissions(namespace, quayClient, quayOrganizationName, repositoryName, robotRoles); err != nil || result.Requeue {
+				return result, err
+			}
+		}
+
+	}
+
+	// Trust is no longer managed once disabled on every repository it was enabled on
+	if manageTrust && !trustEnabled && !trustFailed {
+		if result, err := r.releaseRepositoryTrust(ctx, namespace); err != nil || result.Requeue {
+			return result, err
+		}
+	}
+
+	if len(robotRoles) > 0 {
+		return r.revokeRepositoryPermissions(namespace, quayClient, quayOrganizationName, requestedRepositories, robotRoles)
 	}
 
 	return reconcile.Result{}, nil
 
 }
 
-// createRobotAccountAndSecret creates a robot account, creates a secret and adds the secret to the service account
-func (r *NamespaceIntegrationReconciler) createRobotAccountAssociateToSA(ctx context.Context, request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, serviceAccount qotypes.OpenShiftServiceAccount, role qclient.QuayRole, quayName string, quayHostname string) (reconcile.Result, error) {
-	// Setup Robot Account
-	robotAccount, robotAccountResponse, robotAccountError := quayClient.GetOrganizationRobotAccount(quayOrganizationName, string(serviceAccount))
+// getOutputImageStreamName returns the name of the ImageStream of the namespace a BuildConfig pushes to, if any
+func getOutputImageStreamName(buildConfig *buildv1.BuildConfig, namespace string) (string, bool) {
 
-	if robotAccountError.Error != nil {
+	output := buildConfig.Spec.Output.To
+
+	if output == nil || output.Kind != "ImageStreamTag" || (output.Namespace != "" && output.Namespace != namespace) {
+		return "", false
+	}
+
+	separator := strings.Index(output.Name, ":")
+
+	if separator <= 0 {
+		return "", false
+	}
+
+	return output.Name[:separator], true
+}
+
+// revokeRepositoryPermissions revokes the roles granted per repository to robot accounts on the repositories no longer derived from
+// the ImageStreams and BuildConfigs of the namespace, such as repositories of deleted ImageStreams or created by other means
+func (r *NamespaceIntegrationReconciler) revokeRepositoryPermissions(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, requestedRepositories map[string]bool, robotRoles map[string]qclient.QuayRole) (reconcile.Result, error) {
+
+	repositories, repositoriesResponse, repositoriesErr := quayClient.GetRepositoriesByOrganization(quayOrganizationName)
+
+	if repositoriesErr.Error != nil || repositoriesResponse.StatusCode != 200 {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       namespace,
-			Message:      "Error occurred retrieving robot account for Quay Organization",
-			KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Robot Account", serviceAccount, "Status Code", robotAccountResponse.StatusCode},
-			Error:        robotAccountError.Error,
+			Message:      "Error occurred retrieving Quay Repositories",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName},
+			Error:        requestError("error retrieving repositories", repositoriesResponse, repositoriesErr.Error),
 		})
 	}
 
-	// Check to see if Robot Exists
-	if robotAccountResponse.StatusCode == 400 {
+	for _, repository := range repositories {
 
-		// Create Robot Account
-		robotAccount, robotAccountResponse, robotAccountError = quayClient.CreateOrganizationRobotAccount(quayOrganizationName, string(serviceAccount))
+		if requestedRepositories[repository.Name] {
+			continue
+		}
 
-		if robotAccountError.Error != nil || robotAccountResponse.StatusCode != 201 {
+		permissions, permissionsResponse, permissionsErr := quayClient.GetRepositoryUserPermissions(quayOrganizationName, repository.Name)
+
+		if permissionsErr.Error != nil || permissionsResponse.StatusCode != 200 {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       namespace,
-				Message:      "Error occurred retrieving robot account for Quay Organization",
-				KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Robot Account", serviceAccount, "Status Code", robotAccountResponse.StatusCode},
+				Message:      "Error occurred retrieving permissions of Quay Repository",
+				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repository.Name)},
+				Error:        requestError("error retrieving repository permissions", permissionsResponse, permissionsErr.Error),
 			})
+		}
+
+		for robotName := range robotRoles {
+
+			if _, found := permissions.Permissions[robotName]; !found {
+				continue
+			}
+
+			logging.Log.Info("Revoking robot account permission on Quay Repository", "Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repository.Name), "Robot Account", robotName)
+
+			deleteResponse, deleteErr := r.orgActuator(quayClient).DeleteRepositoryUserPermission(quayOrganizationName, repository.Name, robotName)
+
+			if deleteErr.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 404) {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Error occurred revoking robot account permission on Quay Repository",
+					KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repository.Name), "Robot Account", robotName},
+					Error:        requestError("error revoking repository permission", deleteResponse, deleteErr.Error),
+				})
+			}
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
 
+// deleteRobotAccountPrototypes deletes the default permissions of an organization granted to a robot account
+func (r *NamespaceIntegrationReconciler) deleteRobotAccountPrototypes(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, robotName string, prototypes []qclient.Prototype) (reconcile.Result, error) {
+
+	for _, prototype := range prototypes {
+
+		if !prototype.Delegate.Robot || prototype.Delegate.Name != robotName {
+			continue
 		}
 
+		logging.Log.Info("Deleting default permission of robot account", "Organization", quayOrganizationName, "Robot Account", robotName, "Role", prototype.Role)
+
+		deleteResponse, deleteErr := r.orgActuator(quayClient).DeletePrototype(quayOrganizationName, prototype.ID)
+
+		if deleteErr.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 404) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred deleting default permission of robot account",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName},
+				Error:        requestError("error deleting default permission", deleteResponse, deleteErr.Error),
+			})
+		}
 	}
 
-	organizationPrototypes, organizationPrototypesResponse, organizationPrototypesError := quayClient.GetPrototypesByOrganization(quayOrganizationName)
+	return reconcile.Result{}, nil
+}
 
-	if organizationPrototypesError.Error != nil {
-		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
-			Object:       namespace,
-			Message:      "Error occurred retrieving Prototypes for Quay Organization",
-			KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Status Code", robotAccountResponse.StatusCode},
-			Error:        organizationPrototypesError.Error,
-		})
+// reconcileConsoleLink ensures a link to the Quay organization is displayed on the dashboard of the namespace when
+// requested and the OpenShift Console is available. The link is owned by the namespace and removed along with it
+func (r *NamespaceIntegrationReconciler) reconcileConsoleLink(ctx context.Context, namespace *corev1.Namespace, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	available, err := r.CoreComponents.ReconcilerBase.IsAPIResourceAvailable(console.ConsoleLinkGVK)
+
+	if err != nil || !available {
+		return reconcile.Result{}, nil
+	}
 
+	consoleLink := console.NewConsoleLink(namespace.Name, quayIntegration.GetQuayHostname(), quayOrganizationName)
+
+	if !quayIntegration.Spec.ConsoleLinks {
+		err = r.CoreComponents.ReconcilerBase.DeleteResourceIfExists(ctx, consoleLink)
+	} else {
+		quayIntegration.ApplyResourceMetadata(consoleLink)
+		err = r.CoreComponents.ReconcilerBase.ApplyResource(ctx, namespace, "", consoleLink)
 	}
 
-	if organizationPrototypesResponse.StatusCode != 200 {
+	if err != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       namespace,
-			Message:      "Error occurred retrieving Prototypes for Quay Organization",
-			KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Status Code", robotAccountResponse.StatusCode},
+			Message:      "Error occurred reconciling ConsoleLink",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConsoleLink", consoleLink.GetName()},
+			Error:        err,
 		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileImageSigning distributes the public key and policy used to verify image signatures to the namespace when configured,
+// removing a previously distributed ConfigMap otherwise
+func (r *NamespaceIntegrationReconciler) reconcileImageSigning(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	imageSigning := quayIntegration.Spec.ImageSigning
+
+	if !signing.IsDistributionEnabled(imageSigning) {
+
+		configMap := &corev1.ConfigMap{}
+
+		err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: signing.GetConfigMapName(imageSigning)}, configMap)
+
+		if err == nil && signing.IsManagedConfigMap(configMap) {
+			err = r.CoreComponents.ReconcilerBase.DeleteResourceIfExists(ctx, configMap)
+		}
+
+		if err != nil && !errors.IsNotFound(err) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred removing Image Signing ConfigMap",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", configMap.Name},
+				Error:        err,
+			})
+		}
 
+		return reconcile.Result{}, nil
 	}
 
-	if found := qclient.IsRobotAccountInPrototypeByRole(organizationPrototypes.Prototypes, robotAccount.Name, string(role)); !found {
-		// Create Prototype
-		_, robotPrototypeResponse, robotPrototypeError := quayClient.CreateRobotPermissionForOrganization(quayOrganizationName, robotAccount.Name, string(role))
+	publicKey := ""
 
-		if robotPrototypeError.Error != nil || robotPrototypeResponse.StatusCode != 200 {
+	if imageSigning.PublicKeySecret != nil {
+
+		publicKeySecret := &corev1.Secret{}
+
+		err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: imageSigning.PublicKeySecret.Namespace, Name: imageSigning.PublicKeySecret.Name}, publicKeySecret)
+
+		if err != nil {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       namespace,
-				Message:      "Error occurred creating Robot account permissions for Prototype",
-				KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Robot Account", robotAccount.Name, "Prototype", role, "Status Code", robotPrototypeResponse.StatusCode},
-				Error:        robotPrototypeError.Error,
+				Message:      "Error occurred retrieving Image Signing public key",
+				KeyAndValues: []interface{}{"Namespace", imageSigning.PublicKeySecret.Namespace, "Secret", imageSigning.PublicKeySecret.Name},
+				Error:        err,
+			})
+		}
+
+		key := signing.GetPublicKeySecretKey(imageSigning)
+
+		if _, found := publicKeySecret.Data[key]; !found {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Image Signing public key not found in Secret",
+				KeyAndValues: []interface{}{"Namespace", imageSigning.PublicKeySecret.Namespace, "Secret", imageSigning.PublicKeySecret.Name, "Key", key},
+				Reason:       "ConfigrurationError",
 			})
 		}
 
+		publicKey = string(publicKeySecret.Data[key])
 	}
 
-	// Parse out hostname from Quay Hostname
-	quayURL, quayURLErr := url.Parse(quayHostname)
+	configMap := signing.NewImageSigningConfigMap(namespace.Name, imageSigning, publicKey)
+	quayIntegration.ApplyResourceMetadata(configMap)
 
-	if quayURLErr != nil {
+	if err := r.CoreComponents.ReconcilerBase.ApplyResource(ctx, nil, "", configMap); err != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       namespace,
-			Message:      "Failed to parse Quay hostname",
-			KeyAndValues: []interface{}{"Hostname", quayHostname},
-			Error:        quayURLErr,
+			Message:      "Error occurred distributing Image Signing ConfigMap",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", configMap.Name},
+			Error:        err,
 		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileCatalogMetadata publishes the Quay resources of a synchronized namespace for developer portals, removing the
+// ConfigMap once catalog metadata is disabled
+func (r *NamespaceIntegrationReconciler) reconcileCatalogMetadata(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayUsername string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	catalogMetadata := quayIntegration.Spec.CatalogMetadata
+
+	if catalogMetadata == nil {
+
+		configMap := &corev1.ConfigMap{}
+
+		err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: catalog.GetConfigMapName(catalogMetadata)}, configMap)
+
+		if err == nil && catalog.IsManagedConfigMap(configMap) {
+			err = r.CoreComponents.ReconcilerBase.DeleteResourceIfExists(ctx, configMap)
+		}
+
+		if err != nil && !errors.IsNotFound(err) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred removing Catalog Metadata ConfigMap",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", configMap.Name},
+				Error:        err,
+			})
+		}
+
+		return reconcile.Result{}, nil
+	}
 
+	// Repositories of namespaces mapped to a Quay user account belong to the user
+	organization := quayOrganizationName
+
+	if quayUsername != "" {
+		organization = quayUsername
 	}
 
-	// Setup Secret for Quay Robot Account
-	robotSecret, robotSecretErr := credentials.GenerateDockerJsonSecret(utils.GenerateDockerJsonSecretNameForServiceAccount(string(serviceAccount), quayName), quayURL.Host, robotAccount.Name, robotAccount.Token, "")
-	robotSecret.ObjectMeta.Namespace = namespace.Name
+	registryHostname, err := quayIntegration.GetRegistryHostname()
 
-	if robotSecretErr != nil {
+	if err != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
-			Object:       namespace,
-			Message:      "Failed to generate Docker JSON Secret for Service Account",
-			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Robot Account", robotAccount.Name, "Service Account", serviceAccount},
-			Error:        robotSecretErr,
+			Object:  namespace,
+			Message: "Unable to determine registry hostname",
+			Reason:  "ConfigrurationError",
+			Error:   err,
 		})
 	}
 
-	robotCreateSecretErr := r.CoreComponents.ReconcilerBase.CreateOrUpdateResource(ctx, nil, namespace.Name, robotSecret)
+	secretNames := map[qotypes.OpenShiftServiceAccount]string{}
+
+	for _, serviceAccount := range []qotypes.OpenShiftServiceAccount{qotypes.DefaultOpenShiftServiceAccount, qotypes.BuilderOpenShiftServiceAccount} {
 
-	if robotCreateSecretErr != nil {
-		return reconcile.Result{Requeue: true}, robotSecretErr
+		// Published in the first configured format usable as a pull or push secret, and left empty when only basic auth Secrets are generated
+		secretName, _, err := utils.GenerateRobotAccountPullSecretName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount))
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Invalid Secret name template",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Template", quayIntegration.Spec.SecretNameTemplate},
+				Reason:       "ConfigrurationError",
+				Error:        err,
+			})
+		}
+
+		secretNames[serviceAccount] = secretName
 	}
 
-	existingServiceAccount := &corev1.ServiceAccount{}
-	serviceAccountErr := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: string(serviceAccount)}, existingServiceAccount)
+	repositories, repositoriesResponse, repositoriesErr := quayClient.GetRepositoriesByOrganization(organization)
 
-	if serviceAccountErr != nil {
+	if repositoriesErr.Error != nil || repositoriesResponse.StatusCode != 200 {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       namespace,
-			Message:      "Failed to get existing platform service account",
-			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount},
-			Error:        serviceAccountErr,
+			Message:      "Error occurred retrieving Quay Repositories",
+			KeyAndValues: []interface{}{"Quay Organization", organization},
+			Error:        requestError("error retrieving repositories", repositoriesResponse, repositoriesErr.Error),
 		})
+	}
 
+	metadata := catalog.Metadata{
+		QuayHostname:     quayIntegration.GetQuayHostname(),
+		RegistryHostname: registryHostname,
+		Organization:     organization,
+		UserAccount:      quayUsername != "",
+		PullSecret:       secretNames[qotypes.DefaultOpenShiftServiceAccount],
+		PushSecret:       secretNames[qotypes.BuilderOpenShiftServiceAccount],
 	}
 
-	_, updated := r.updateSecretWithMountablePullSecret(existingServiceAccount, robotSecret.Name)
+	for _, repository := range repositories {
+		metadata.Repositories = append(metadata.Repositories, repository.Name)
+	}
 
-	if updated {
+	configMap, err := catalog.NewCatalogConfigMap(namespace.Name, catalogMetadata, metadata)
 
-		updatedServiceAccountErr := r.CoreComponents.ReconcilerBase.CreateOrUpdateResource(ctx, nil, namespace.Name, existingServiceAccount)
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to generate Catalog Metadata",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
 
-		if updatedServiceAccountErr != nil {
-			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
-				Object:       namespace,
-				Message:      "Failed to to updated existing platform service account",
-				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount},
-				Error:        updatedServiceAccountErr,
-			})
+	quayIntegration.ApplyResourceMetadata(configMap)
 
-		}
+	if err := r.CoreComponents.ReconcilerBase.ApplyResource(ctx, nil, "", configMap); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred publishing Catalog Metadata ConfigMap",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "ConfigMap", configMap.Name},
+			Error:        err,
+		})
 	}
 
 	return reconcile.Result{}, nil
-
 }
 
-func (r *NamespaceIntegrationReconciler) cleanupResources(request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string) (reconcile.Result, error) {
-
-	logging.Log.Info("Deleting Organization", "Organization Name", quayOrganizationName)
+// reconcileRepositoryNotifications ensures the notifications managed by the operator on a repository match the desired notifications
+func (r *NamespaceIntegrationReconciler) reconcileRepositoryNotifications(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, repositoryName string, repositoryNotifications []qclient.NotificationRequest) (reconcile.Result, error) {
 
-	_, organizationResponse, orgniazationError := quayClient.GetOrganizationByname(quayOrganizationName)
+	existingNotifications, notificationsResponse, notificationsErr := quayClient.GetRepositoryNotifications(quayOrganizationName, repositoryName)
 
-	if orgniazationError.Error != nil {
+	if notificationsErr.Error != nil || notificationsResponse.StatusCode != 200 {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       namespace,
-			Message:      "Error occurred retrieving Organization",
-			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationResponse.StatusCode},
-			Error:        orgniazationError.Error,
+			Message:      "Error Retrieving Repository Notifications",
+			KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName), "Status Code", statusCode(notificationsResponse)},
+			Error:        notificationsErr.Error,
 		})
 	}
 
-	// Check to see if Organization Exists (Response Code)
-	if organizationResponse.StatusCode == 404 {
-		return reconcile.Result{}, nil
-		// Organization is not present
-	} else if organizationResponse.StatusCode == 200 {
-		organizationDeleteResponse, orgniazationDeleteError := quayClient.DeleteOrganization(quayOrganizationName)
+	toCreate, toDelete := qclient.DiffNotifications(existingNotifications.Notifications, repositoryNotifications, constants.ManagedNotificationTitlePrefix)
 
-		if orgniazationDeleteError.Error != nil {
+	for _, uuid := range toDelete {
+
+		logging.Log.Info("Deleting Repository Notification", "Organization", quayOrganizationName, "Repository", repositoryName, "UUID", uuid)
+
+		deleteNotificationResponse, deleteNotificationErr := r.orgActuator(quayClient).DeleteRepositoryNotification(quayOrganizationName, repositoryName, uuid)
+
+		if deleteNotificationErr.Error != nil || (deleteNotificationResponse.StatusCode != 204 && deleteNotificationResponse.StatusCode != 404) {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       namespace,
-				Message:      "Error occurred deleting Organization",
-				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationDeleteResponse.StatusCode},
-				Error:        orgniazationDeleteError.Error,
+				Message:      "Error occurred deleting Repository Notification",
+				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName), "UUID", uuid, "Status Code", statusCode(deleteNotificationResponse)},
+				Error:        deleteNotificationErr.Error,
 			})
 		}
+	}
 
-		if organizationDeleteResponse.StatusCode != 204 {
+	for _, notification := range toCreate {
+
+		logging.Log.Info("Creating Repository Notification", "Organization", quayOrganizationName, "Repository", repositoryName, "Title", notification.Title)
+
+		_, createNotificationResponse, createNotificationErr := r.orgActuator(quayClient).CreateRepositoryNotification(quayOrganizationName, repositoryName, notification)
+
+		if createNotificationErr.Error != nil || createNotificationResponse.StatusCode != 201 {
 			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 				Object:       namespace,
-				Message:      "Error occurred deleting Organization",
-				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationDeleteResponse.StatusCode},
+				Message:      "Error occurred creating Repository Notification",
+				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName), "Title", notification.Title, "Status Code", statusCode(createNotificationResponse)},
+				Error:        createNotificationErr.Error,
 			})
 		}
+	}
 
-		return reconcile.Result{}, nil
+	return reconcile.Result{}, nil
+}
 
-	} else {
+// createRobotAccountAndSecret creates a robot account, creates a secret and adds the secret to the service account
+func (r *NamespaceIntegrationReconciler) createRobotAccountAssociateToSA(ctx context.Context, request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, serviceAccount qotypes.OpenShiftServiceAccount, role qclient.QuayRole, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+	// Validated before the robot accounts are reconciled
+	robotName, _ := utils.GenerateRobotAccountName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount))
+
+	// Setup Robot Account
+	robotAccount, robotAccountResponse, robotAccountError := quayClient.GetOrganizationRobotAccount(quayOrganizationName, robotName)
+
+	if robotAccountError.Error != nil {
 		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
 			Object:       namespace,
-			Message:      "Error occurred retrieving Organization",
-			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationResponse.StatusCode},
+			Message:      "Error occurred retrieving robot account for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Robot Account", robotName, "Status Code", robotAccountResponse.StatusCode},
+			Error:        robotAccountError.Error,
 		})
 	}
 
-}
-
-func (r *NamespaceIntegrationReconciler) updateSecretWithMountablePullSecret(serviceAccount *corev1.ServiceAccount, name string) (*corev1.ServiceAccount, bool) {
+	// Check to see if Robot Exists
+	if robotAccountResponse.StatusCode == 400 {
 
-	updated := false
+		// Create Robot Account
+		robotAccount, robotAccountResponse, robotAccountError = r.orgActuator(quayClient).CreateOrganizationRobotAccountWithMetadata(quayOrganizationName, robotName, newRobotAccountRequest(namespace, quayIntegration, string(serviceAccount), fmt.Sprintf("Credentials of service account %s", serviceAccount)))
 
-	if found := utils.LocalObjectReferenceNameExists(serviceAccount.ImagePullSecrets, name); !found {
+		if robotAccountError.Error != nil || robotAccountResponse.StatusCode != 201 {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred retrieving robot account for Quay Organization",
+				KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Robot Account", robotName, "Status Code", robotAccountResponse.StatusCode},
+			})
 
-		serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+		}
 
-		updated = true
 	}
 
-	if found := utils.ObjectReferenceNameExists(serviceAccount.Secrets, name); !found {
+	organizationPrototypes, organizationPrototypesResponse, organizationPrototypesError := quayClient.GetPrototypesByOrganization(quayOrganizationName)
+
+	if organizationPrototypesError.Error != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Prototypes for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Status Code", robotAccountResponse.StatusCode},
+			Error:        organizationPrototypesError.Error,
+		})
+
+	}
+
+	if organizationPrototypesResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Prototypes for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Status Code", robotAccountResponse.StatusCode},
+		})
+
+	}
+
+	// Builder robot accounts scoped to the repositories of the namespace are granted write access per repository instead
+	if serviceAccount == qotypes.BuilderOpenShiftServiceAccount && quayIntegration.GetBuilderRobotScope() == quayv1.RepositoriesBuilderRobotScope {
+
+		if result, err := r.deleteRobotAccountPrototypes(namespace, quayClient, quayOrganizationName, robotAccount.Name, organizationPrototypes.Prototypes); err != nil || result.Requeue {
+			return result, err
+		}
+
+	} else if found := qclient.IsRobotAccountInPrototypeByRole(organizationPrototypes.Prototypes, robotAccount.Name, string(role)); !found {
+
+		// Default permissions granting a previous role of the service account are replaced
+		if result, err := r.deleteRobotAccountPrototypes(namespace, quayClient, quayOrganizationName, robotAccount.Name, organizationPrototypes.Prototypes); err != nil || result.Requeue {
+			return result, err
+		}
+
+		// Create Prototype
+		_, robotPrototypeResponse, robotPrototypeError := r.orgActuator(quayClient).CreateRobotPermissionForOrganization(quayOrganizationName, robotAccount.Name, string(role))
+
+		if robotPrototypeError.Error != nil || robotPrototypeResponse.StatusCode != 200 {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred creating Robot account permissions for Prototype",
+				KeyAndValues: []interface{}{"Quay Repository", quayOrganizationName, "Robot Account", robotAccount.Name, "Prototype", role, "Status Code", robotPrototypeResponse.StatusCode},
+				Error:        robotPrototypeError.Error,
+			})
+		}
+
+	}
+
+	shortLivedCredentials, result, err := r.shortLivedCredentials(namespace, quayClient, quayOrganizationName, robotAccount, quayIntegration)
+
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
+	return r.associateRobotAccountToSA(ctx, namespace, quayOrganizationName, serviceAccount, robotAccount, shortLivedCredentials, quayIntegration)
+}
+
+// shortLivedCredentials returns a short lived token of a robot account when the QuayIntegration uses short lived credentials, or nil
+// when the Secrets contain the permanent token of the robot account
+func (r *NamespaceIntegrationReconciler) shortLivedCredentials(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, robotAccount qclient.RobotAccount, quayIntegration *quayv1.QuayIntegration) (*federation.Credentials, reconcile.Result, error) {
+
+	if !quayIntegration.UsesShortLivedCredentials() || r.ShortLivedCredentials == nil {
+		return nil, reconcile.Result{}, nil
+	}
+
+	if quayVersion, err := capabilities.ParseVersion(quayIntegration.Status.QuayVersion); err == nil && quayVersion.Less(federation.MinimumVersion) {
+		result, err := r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      fmt.Sprintf("Short lived credentials require Quay %d.%d or newer", federation.MinimumVersion.Major, federation.MinimumVersion.Minor),
+			KeyAndValues: []interface{}{"Quay Version", quayVersion},
+			Reason:       "ConfigrurationError",
+		})
+
+		return nil, result, err
+	}
+
+	credentials, err := r.ShortLivedCredentials.Credentials(quayClient, quayOrganizationName, robotAccount.Name, time.Now())
+
+	if err != nil {
+		result, err := r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to issue short lived token of robot account",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotAccount.Name},
+			Error:        err,
+		})
+
+		return nil, result, err
+	}
+
+	return &credentials, reconcile.Result{}, nil
+}
+
+// associateRobotAccountToSA writes the Secrets of a robot account to a namespace and adds them to the service account. The Secrets
+// contain the short lived token of the robot account when provided, and its permanent token otherwise
+func (r *NamespaceIntegrationReconciler) associateRobotAccountToSA(ctx context.Context, namespace *corev1.Namespace, quayOrganizationName string, serviceAccount qotypes.OpenShiftServiceAccount, robotAccount qclient.RobotAccount, shortLivedCredentials *federation.Credentials, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	// Secrets and service accounts are managed by GitOps tooling in GitOps mode, unless credentials are published to a secret store
+	// or exported for GitOps tooling
+	writesSecrets := quayIntegration.ProvidesRobotAccountSecrets()
+
+	if !writesSecrets && quayIntegration.Spec.CredentialExport == nil {
+		return reconcile.Result{}, nil
+	}
+
+	// Parse out hostname from Quay Hostname
+	registryHostname, registryHostnameErr := quayIntegration.GetRegistryHostname()
+
+	if registryHostnameErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to parse Quay hostname",
+			KeyAndValues: []interface{}{"Hostname", quayIntegration.GetQuayHostname()},
+			Error:        registryHostnameErr,
+		})
+
+	}
+
+	existingServiceAccount := &corev1.ServiceAccount{}
+	serviceAccountErr := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: string(serviceAccount)}, existingServiceAccount)
+
+	if serviceAccountErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to get existing platform service account",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount},
+			Error:        serviceAccountErr,
+		})
+
+	}
+
+	writer, writerErr := r.namespaceWriter(ctx, namespace.Name, quayIntegration)
+
+	if writerErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to set up impersonation of namespace service account",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", r.ImpersonationServiceAccount},
+			Error:        writerErr,
+		})
+	}
+
+	secretActuator, secretActuatorErr := r.secretActuatorFor(ctx, quayOrganizationName, quayIntegration)
+
+	if secretActuatorErr != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to set up secret store",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Reason:       "ConfigrurationError",
+			Error:        secretActuatorErr,
+		})
+	}
+
+	updated := false
+	secretNames := []string{}
+	exportedSecrets := []*corev1.Secret{}
+
+	if shortLivedCredentials != nil {
+		robotAccount.Token = shortLivedCredentials.Token
+	}
+
+	for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+		// Setup Secret for Quay Robot Account
+		robotSecretName, robotSecretErr := utils.GenerateRobotAccountSecretName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount), secretFormat)
+
+		if robotSecretErr != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to generate Secret name for Service Account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount, "Template", quayIntegration.Spec.SecretNameTemplate},
+				Reason:       "ConfigrurationError",
+				Error:        robotSecretErr,
+			})
+		}
+
+		robotSecret, robotSecretErr := generateRobotAccountSecret(secretFormat, robotSecretName, registryHostname, robotAccount)
+
+		if robotSecretErr != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to generate Secret for Service Account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Robot Account", robotAccount.Name, "Service Account", serviceAccount, "Format", string(secretFormat)},
+				Error:        robotSecretErr,
+			})
+		}
+
+		robotSecret.Labels = map[string]string{constants.ManagedSecretLabel: "true"}
+		robotSecret.Annotations = map[string]string{constants.ManagedSecretHashAnnotation: credentials.HashSecretData(robotSecret)}
+
+		if shortLivedCredentials != nil {
+			shortLivedCredentials.Annotate(robotSecret)
+		}
+
+		quayIntegration.ApplyResourceMetadata(robotSecret)
+
+		exportedSecrets = append(exportedSecrets, robotSecret)
+
+		if !writesSecrets {
+			continue
+		}
+
+		// Secrets provided from a secret store are not written by the operator
+		if r.SecretProtection && quayIntegration.Spec.SecretStore == nil {
+			if err := r.recordManagedSecretDrift(ctx, namespace, existingServiceAccount, robotSecret); err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Failed to get existing robot account Secret",
+					KeyAndValues: []interface{}{"Namespace", namespace.Name, "Secret", robotSecret.Name},
+					Error:        err,
+				})
+			}
+		}
+
+		// Applying the Secret recreates it when deleted and reverts edits made to the fields owned by the operator
+		robotCreateSecretErr := secretActuator.ApplySecret(ctx, writer, namespace.Name, robotSecret)
+
+		if robotCreateSecretErr != nil {
+			return reconcile.Result{Requeue: true}, robotCreateSecretErr
+		}
+
+		secretNames = append(secretNames, robotSecret.Name)
+
+		if !quayIntegration.ManagesClusterSecrets() {
+			continue
+		}
+
+		// Basic auth Secrets cannot be used to pull images and are only mounted
+		if secretFormat == quayv1.BasicAuthSecretFormat {
+			if r.updateServiceAccountWithMountableSecret(existingServiceAccount, robotSecret.Name) {
+				updated = true
+			}
+		} else {
+			if _, pullSecretUpdated := r.updateSecretWithMountablePullSecret(existingServiceAccount, robotSecret.Name); pullSecretUpdated {
+				updated = true
+			}
+		}
+	}
+
+	if result, err := r.exportCredentials(ctx, namespace, quayIntegration, exportedSecrets); err != nil || result.Requeue {
+		return result, err
+	}
+
+	// The Secrets of formats no longer configured, or named after a previous SecretNameTemplate, still hold the credentials of the robot
+	// account. They are unlinked from the service account and deleted once it is updated
+	staleSecretNames := map[string]bool{}
+	recorded := false
+
+	if writesSecrets && quayIntegration.ManagesClusterSecrets() {
+
+		for _, secretName := range utils.GenerateStaleRobotAccountSecretNames(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount)) {
+			if utils.ObjectReferenceNameExists(existingServiceAccount.Secrets, secretName) || utils.LocalObjectReferenceNameExists(existingServiceAccount.ImagePullSecrets, secretName) {
+				staleSecretNames[secretName] = true
+			}
+		}
+
+		written := map[string]bool{}
+
+		for _, secretName := range secretNames {
+			written[secretName] = true
+		}
+
+		for _, secretName := range utils.ParseRobotAccountSecretNames(existingServiceAccount.Annotations[constants.QuayRobotSecretsAnnotation]) {
+			if !written[secretName] {
+				staleSecretNames[secretName] = true
+			}
+		}
+
+		unlinkSecrets(existingServiceAccount, staleSecretNames)
+
+		recorded = recordRobotAccountSecretNames(existingServiceAccount, secretNames)
+	}
+
+	if updated {
+
+		// Secrets not linked to the service account yet were just created. The hooks are invoked again until they succeed as the
+		// service account is only updated afterwards
+		hookEvent := hooks.Event{Point: quayv1.PostSecretCreateSyncHookPoint, Organization: quayOrganizationName, ServiceAccount: string(serviceAccount), Secrets: secretNames}
+
+		if result, err := r.runSyncHooks(ctx, namespace, quayIntegration, hookEvent); err != nil || result.Requeue {
+			return result, err
+		}
+	}
+
+	if updated || recorded || len(staleSecretNames) > 0 {
+
+		updatedServiceAccountErr := writer.CreateOrUpdateResource(ctx, nil, namespace.Name, existingServiceAccount)
+
+		if updatedServiceAccountErr != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to to updated existing platform service account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount},
+				Error:        updatedServiceAccountErr,
+			})
+
+		}
+	}
+
+	for secretName := range staleSecretNames {
+
+		logging.Log.Info("Deleting robot account Secret no longer generated", "Namespace", namespace.Name, "Service Account", serviceAccount, "Secret", secretName)
+
+		if err := secretActuator.DeleteSecret(ctx, writer, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: secretName}}); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to delete robot account Secret",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Secret", secretName},
+				Error:        err,
+			})
+		}
+	}
+
+	return reconcile.Result{}, nil
+
+}
+
+// recordRobotAccountSecretNames records the names of the robot account Secrets written for a service account, so that they are deleted
+// once no longer generated. Returns whether the recorded names changed
+func recordRobotAccountSecretNames(serviceAccount *corev1.ServiceAccount, secretNames []string) bool {
+
+	sortedSecretNames := append([]string{}, secretNames...)
+	sort.Strings(sortedSecretNames)
+
+	value := strings.Join(sortedSecretNames, ",")
+
+	if serviceAccount.Annotations[constants.QuayRobotSecretsAnnotation] == value {
+		return false
+	}
+
+	if serviceAccount.Annotations == nil {
+		serviceAccount.Annotations = map[string]string{}
+	}
+
+	serviceAccount.Annotations[constants.QuayRobotSecretsAnnotation] = value
+
+	return true
+}
+
+// recordManagedSecretDrift records an event when a robot account Secret previously provisioned for a service account was deleted
+// or edited outside of the operator
+func (r *NamespaceIntegrationReconciler) recordManagedSecretDrift(ctx context.Context, namespace *corev1.Namespace, serviceAccount *corev1.ServiceAccount, robotSecret *corev1.Secret) error {
+
+	existingSecret := &corev1.Secret{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: robotSecret.Name}, existingSecret); err != nil {
+
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		if utils.ObjectReferenceNameExists(serviceAccount.Secrets, robotSecret.Name) {
+			logging.Log.Info("Recreating deleted robot account Secret", "Namespace", namespace.Name, "Secret", robotSecret.Name)
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", managedSecretRecreatedReason, fmt.Sprintf("Secret %s managed by the operator was deleted and has been recreated", robotSecret.Name))
+		}
+
+		return nil
+	}
+
+	if IsManagedSecretTampered(existingSecret) {
+		logging.Log.Info("Reverting edited robot account Secret", "Namespace", namespace.Name, "Secret", robotSecret.Name)
+		r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", managedSecretRevertedReason, fmt.Sprintf("Secret %s managed by the operator was edited and has been reverted", robotSecret.Name))
+	}
+
+	return nil
+}
+
+// IsManagedSecretTampered returns whether a Secret provisioned by the operator no longer carries its label or its data no longer
+// matches the digest recorded when it was provisioned. Secrets provisioned before digests were recorded are not considered edited
+func IsManagedSecretTampered(secret *corev1.Secret) bool {
+
+	hash, found := secret.Annotations[constants.ManagedSecretHashAnnotation]
+
+	if !found {
+		return false
+	}
+
+	return secret.Labels[constants.ManagedSecretLabel] != "true" || hash != credentials.HashSecretData(secret)
+}
+
+// orgActuator returns the OrgActuator changing Quay through a Quay client
+func (r *NamespaceIntegrationReconciler) orgActuator(quayClient *qclient.QuayClient) actuator.OrgActuator {
+	return newOrgActuator(r.OrgActuator, quayClient)
+}
+
+// secretActuator returns the SecretActuator writing the Secrets of namespaces
+func (r *NamespaceIntegrationReconciler) secretActuator() actuator.SecretActuator {
+
+	if r.SecretActuator == nil {
+		return actuator.DefaultSecretActuator{}
+	}
+
+	return r.SecretActuator
+}
+
+// newOrgActuator returns the OrgActuator created by a factory, or the default OrgActuator when no factory is provided
+func newOrgActuator(factory actuator.OrgActuatorFactory, quayClient *qclient.QuayClient) actuator.OrgActuator {
+
+	if factory == nil {
+		factory = actuator.DefaultOrgActuator
+	}
+
+	return factory(quayClient)
+}
+
+// namespaceWritersKey is the context key of the namespace writers set up during a reconcile
+type namespaceWritersKey struct{}
+
+// namespaceWriters are the namespace writers set up during a reconcile, keyed by namespace
+type namespaceWriters struct {
+	mutex   sync.Mutex
+	writers map[string]reconcilerbase.ReconcilerBase
+}
+
+// withNamespaceWriters returns a context recording the namespace writers set up during a reconcile, so that the impersonated service
+// account and its RoleBinding are applied once per namespace and reconcile rather than before each write
+func withNamespaceWriters(ctx context.Context) context.Context {
+	return context.WithValue(ctx, namespaceWritersKey{}, &namespaceWriters{writers: map[string]reconcilerbase.ReconcilerBase{}})
+}
+
+// namespaceWriter returns the ReconcilerBase writing resources within a namespace. When impersonation is enabled, a service account
+// of the namespace bound to the writer ClusterRole is impersonated instead of using the cluster wide identity of the operator
+func (r *NamespaceIntegrationReconciler) namespaceWriter(ctx context.Context, namespace string, quayIntegration *quayv1.QuayIntegration) (reconcilerbase.ReconcilerBase, error) {
+
+	if r.ImpersonationServiceAccount == "" {
+		return r.CoreComponents.ReconcilerBase, nil
+	}
+
+	writers, ok := ctx.Value(namespaceWritersKey{}).(*namespaceWriters)
+
+	if !ok {
+		return r.setUpNamespaceWriter(ctx, namespace, quayIntegration)
+	}
+
+	writers.mutex.Lock()
+	defer writers.mutex.Unlock()
+
+	if writer, ok := writers.writers[namespace]; ok {
+		return writer, nil
+	}
+
+	writer, err := r.setUpNamespaceWriter(ctx, namespace, quayIntegration)
+
+	if err != nil {
+		return reconcilerbase.ReconcilerBase{}, err
+	}
+
+	writers.writers[namespace] = writer
+
+	return writer, nil
+}
+
+// forgetNamespaceWriter evicts the client impersonating the service account of a namespace no longer synchronized
+func (r *NamespaceIntegrationReconciler) forgetNamespaceWriter(namespace string) {
+
+	if r.ImpersonationServiceAccount != "" {
+		r.CoreComponents.ReconcilerBase.ForgetImpersonation(reconcilerbase.ServiceAccountUsername(namespace, r.ImpersonationServiceAccount))
+	}
+}
+
+// setUpNamespaceWriter applies the impersonated service account of a namespace and its RoleBinding, and returns the ReconcilerBase
+// impersonating it
+func (r *NamespaceIntegrationReconciler) setUpNamespaceWriter(ctx context.Context, namespace string, quayIntegration *quayv1.QuayIntegration) (reconcilerbase.ReconcilerBase, error) {
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.ImpersonationServiceAccount,
+		},
+	}
+
+	quayIntegration.ApplyResourceMetadata(serviceAccount)
+
+	if err := r.CoreComponents.ReconcilerBase.ApplyResource(ctx, nil, namespace, serviceAccount); err != nil {
+		return reconcilerbase.ReconcilerBase{}, err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.ImpersonationServiceAccount,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     r.ImpersonationClusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      r.ImpersonationServiceAccount,
+				Namespace: namespace,
+			},
+		},
+	}
+
+	quayIntegration.ApplyResourceMetadata(roleBinding)
+
+	if err := r.CoreComponents.ReconcilerBase.ApplyResource(ctx, nil, namespace, roleBinding); err != nil {
+		return reconcilerbase.ReconcilerBase{}, err
+	}
+
+	return r.CoreComponents.ReconcilerBase.Impersonate(reconcilerbase.ServiceAccountUsername(namespace, r.ImpersonationServiceAccount))
+}
+
+// deferSynchronization queues the synchronization of a namespace while Quay is unreachable in offline mode. Namespaces missing their
+// robot account Secrets are marked as pending provisioning, so Builds are admitted according to the UnprovisionedBuildPolicy
+func (r *NamespaceIntegrationReconciler) deferSynchronization(ctx context.Context, namespace *corev1.Namespace, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if err := offline.Enqueue(ctx, r.CoreComponents.ReconcilerBase.GetClient(), quayIntegration.Name, quayv1.PendingOperation{Type: quayv1.SyncNamespaceOperation, Namespace: namespace.Name, Organization: quayOrganizationName}); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to queue synchronization of namespace",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	if !utils.IsProvisioningPending(namespace) && r.needsRobotAccountSecrets(ctx, namespace.Name, quayIntegration) {
+
+		err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, namespace, func() error {
+			if namespace.Annotations == nil {
+				namespace.Annotations = map[string]string{}
+			}
+
+			namespace.Annotations[constants.NamespaceProvisioningAnnotation] = constants.NamespaceProvisioningPending
+
+			return nil
+		})
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Unable to update namespace",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name},
+				Error:        err,
+			})
+		}
+	}
+
+	r.Log.Info("Quay is unreachable, synchronization of namespace queued", "Namespace", namespace.Name, "Retry", offline.RetryInterval)
+
+	return reconcile.Result{RequeueAfter: offline.RetryInterval}, nil
+}
+
+func (r *NamespaceIntegrationReconciler) cleanupResources(request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string) (reconcile.Result, error) {
+
+	logging.Log.Info("Deleting Organization", "Organization Name", quayOrganizationName)
+
+	_, organizationResponse, orgniazationError := quayClient.GetOrganizationByname(quayOrganizationName)
+
+	if orgniazationError.Error != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationResponse.StatusCode},
+			Error:        orgniazationError.Error,
+		})
+	}
+
+	// Check to see if Organization Exists (Response Code)
+	if organizationResponse.StatusCode == 404 {
+		return reconcile.Result{}, nil
+		// Organization is not present
+	} else if organizationResponse.StatusCode == 200 {
+		organizationDeleteResponse, orgniazationDeleteError := r.orgActuator(quayClient).DeleteOrganization(quayOrganizationName)
+
+		if orgniazationDeleteError.Error != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred deleting Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationDeleteResponse.StatusCode},
+				Error:        orgniazationDeleteError.Error,
+			})
+		}
+
+		if organizationDeleteResponse.StatusCode != 204 {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred deleting Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationDeleteResponse.StatusCode},
+			})
+		}
+
+		return reconcile.Result{}, nil
+
+	} else {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", organizationResponse.StatusCode},
+		})
+	}
+
+}
+
+func (r *NamespaceIntegrationReconciler) updateSecretWithMountablePullSecret(serviceAccount *corev1.ServiceAccount, name string) (*corev1.ServiceAccount, bool) {
+
+	updated := false
+
+	if found := utils.LocalObjectReferenceNameExists(serviceAccount.ImagePullSecrets, name); !found {
+
+		serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+
+		updated = true
+	}
+
+	if found := utils.ObjectReferenceNameExists(serviceAccount.Secrets, name); !found {
+
+		serviceAccount.Secrets = append(serviceAccount.Secrets, corev1.ObjectReference{Name: name})
+
+		updated = true
+	}
+
+	return serviceAccount, updated
+}
+
+func (r *NamespaceIntegrationReconciler) updateServiceAccountWithMountableSecret(serviceAccount *corev1.ServiceAccount, name string) bool {
+
+	if found := utils.ObjectReferenceNameExists(serviceAccount.Secrets, name); !found {
 
 		serviceAccount.Secrets = append(serviceAccount.Secrets, corev1.ObjectReference{Name: name})
 
-		updated = true
+		return true
+	}
+
+	return false
+}
+
+// repairOrganizationEmail updates the email address of an existing organization still using the rendered email address
+// which conflicts with other organizations when a uniqueness strategy is configured
+func (r *NamespaceIntegrationReconciler) repairOrganizationEmail(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, organization qclient.Organization, organizationEmail string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	// The email address is only returned to administrators of the organization
+	if organization.Email == "" || organization.Email == organizationEmail {
+		return reconcile.Result{}, nil
+	}
+
+	renderedEmail, err := utils.RenderOrganizationEmail(quayIntegration, namespace.Name, quayOrganizationName)
+
+	if err != nil || organization.Email != renderedEmail {
+		return reconcile.Result{}, nil
+	}
+
+	logging.Log.Info("Repairing Organization email", "Organization", quayOrganizationName, "Email", organizationEmail)
+
+	updateResponse, updateError := r.orgActuator(quayClient).UpdateOrganizationEmail(quayOrganizationName, organizationEmail)
+
+	if updateError.Error != nil || updateResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred updating Quay Organization email",
+			KeyAndValues: []interface{}{"Organization", quayOrganizationName},
+			Error:        updateError.Error,
+		})
+	}
+
+	r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Normal", "OrganizationEmailRepaired", fmt.Sprintf("Email of Quay Organization %s changed to %s", quayOrganizationName, organizationEmail))
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileNamespaceOwner detects organizations left behind by a previous namespace of the same name. The UID of the namespace
+// owning the organization is recorded in the metadata of a robot account without permissions, as Quay organizations have no metadata.
+// Organizations without the robot account are adopted by the namespace
+func (r *NamespaceIntegrationReconciler) reconcileNamespaceOwner(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	ownerRobotAccount, ownerRobotAccountResponse, ownerRobotAccountError := quayClient.GetOrganizationRobotAccount(quayOrganizationName, constants.NamespaceOwnerRobotName)
+
+	if ownerRobotAccountError.Error != nil || (ownerRobotAccountResponse.StatusCode != 200 && ownerRobotAccountResponse.StatusCode != 400 && ownerRobotAccountResponse.StatusCode != 404) {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving the owner of Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", constants.NamespaceOwnerRobotName},
+			Error:        requestError("error retrieving robot account", ownerRobotAccountResponse, ownerRobotAccountError.Error),
+		})
+	}
+
+	ownerUID := ""
+
+	if ownerRobotAccountResponse.StatusCode == 200 {
+
+		ownerUID, _ = ownerRobotAccount.UnstructuredMetadata[constants.NamespaceUIDRobotMetadataKey].(string)
+
+		if ownerUID == string(namespace.UID) {
+			return reconcile.Result{}, nil
+		}
+	}
+
+	if ownerUID != "" {
+
+		if conflict := provenance.FromRobotMetadata(ownerRobotAccount.UnstructuredMetadata).Conflict(provenance.New(quayIntegration, namespace)); conflict != "" {
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "OrganizationCollision", fmt.Sprintf("Quay Organization %s was %s, another cluster may be synchronizing a namespace to the same organization", quayOrganizationName, conflict))
+		}
+
+		policy := quayIntegration.GetNamespaceRecreationPolicy()
+
+		switch override := quayv1.NamespaceRecreationPolicy(namespace.Annotations[constants.NamespaceRecreationPolicyAnnotation]); override {
+		case quayv1.ReattachNamespaceRecreationPolicy, quayv1.RotateRobotsNamespaceRecreationPolicy, quayv1.QuarantineNamespaceRecreationPolicy:
+			policy = override
+		}
+
+		logging.Log.Info("Namespace was re-created", "Namespace", namespace.Name, "Quay Organization", quayOrganizationName, "Previous UID", ownerUID, "Policy", policy)
+
+		switch policy {
+		case quayv1.QuarantineNamespaceRecreationPolicy:
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      fmt.Sprintf("Quay Organization belongs to a previous namespace of the same name, set the %s annotation to Reattach or RotateRobots to use it", constants.NamespaceRecreationPolicyAnnotation),
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Previous UID", ownerUID},
+				Reason:       "NamespaceQuarantined",
+				Error:        fmt.Errorf("organization '%s' belongs to namespace UID '%s'", quayOrganizationName, ownerUID),
+			})
+		case quayv1.RotateRobotsNamespaceRecreationPolicy:
+			if err := rotateRobotAccountTokens(quayClient, r.orgActuator(quayClient), quayOrganizationName); err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Error occurred rotating robot accounts of Quay Organization",
+					KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName},
+					Error:        err,
+				})
+			}
+
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "NamespaceRecreated", fmt.Sprintf("Quay Organization %s belonged to a previous namespace of the same name, the tokens of its robot accounts have been regenerated", quayOrganizationName))
+		default:
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "NamespaceRecreated", fmt.Sprintf("Quay Organization %s belonged to a previous namespace of the same name and is reused along with its robot accounts", quayOrganizationName))
+		}
+	}
+
+	// The metadata of a robot account cannot be changed, the robot account is created again instead
+	if ownerRobotAccountResponse.StatusCode == 200 {
+
+		deleteResponse, deleteError := r.orgActuator(quayClient).DeleteOrganizationRobotAccount(quayOrganizationName, constants.NamespaceOwnerRobotName)
+
+		if deleteError.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 404) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred updating the owner of Quay Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", constants.NamespaceOwnerRobotName},
+				Error:        requestError("error deleting robot account", deleteResponse, deleteError.Error),
+			})
+		}
+	}
+
+	_, createResponse, createError := r.orgActuator(quayClient).CreateOrganizationRobotAccountWithMetadata(quayOrganizationName, constants.NamespaceOwnerRobotName, qclient.RobotAccountRequest{
+		Description:          fmt.Sprintf("Records the namespace %s of cluster %s owning this organization. Managed by the Quay Bridge Operator", namespace.Name, quayIntegration.Spec.ClusterID),
+		UnstructuredMetadata: provenance.New(quayIntegration, namespace).RobotMetadata(),
+	})
+
+	if createError.Error != nil || createResponse.StatusCode != 201 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred recording the owner of Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", constants.NamespaceOwnerRobotName},
+			Error:        requestError("error creating robot account", createResponse, createError.Error),
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileRepositoryProvenance records the provenance of an existing repository in its description unless already recorded
+// for the namespace. Repositories recorded for another cluster are reported and left unchanged
+func (r *NamespaceIntegrationReconciler) reconcileRepositoryProvenance(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, repository qclient.Repository, quayIntegration *quayv1.QuayIntegration) error {
+
+	expected := provenance.New(quayIntegration, namespace)
+
+	if recorded, found := provenance.ParseDescription(repository.Description); found {
+
+		if conflict := recorded.Conflict(expected); conflict != "" {
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "RepositoryCollision", fmt.Sprintf("Quay Repository %s/%s was %s, another cluster may be pushing to the same repository", quayOrganizationName, repository.Name, conflict))
+			return nil
+		}
+
+		if recorded.NamespaceUID == expected.NamespaceUID {
+			return nil
+		}
+	}
+
+	description, err := provenance.ApplyDescription(repository.Description, expected)
+
+	if err != nil {
+		return err
+	}
+
+	logging.Log.Info("Recording Repository provenance", "Organization", quayOrganizationName, "Name", repository.Name)
+
+	updateResponse, updateError := r.orgActuator(quayClient).UpdateRepositoryDescription(quayOrganizationName, repository.Name, description)
+
+	if updateError.Error != nil || updateResponse.StatusCode != 200 {
+		return requestError("error updating repository description", updateResponse, updateError.Error)
+	}
+
+	return nil
+}
+
+// rotateRobotAccountTokens regenerates the token of every robot account of an organization. Secrets containing the tokens are
+// refreshed as the namespace is synchronized
+func rotateRobotAccountTokens(quayClient *qclient.QuayClient, orgActuator actuator.OrgActuator, quayOrganizationName string) error {
+
+	robotAccounts, robotAccountsResponse, robotAccountsError := quayClient.GetOrganizationRobotAccounts(quayOrganizationName)
+
+	if robotAccountsError.Error != nil || robotAccountsResponse.StatusCode != 200 {
+		return requestError("error retrieving robot accounts", robotAccountsResponse, robotAccountsError.Error)
+	}
+
+	for _, robotAccount := range robotAccounts.Robots {
+
+		robotName := strings.TrimPrefix(robotAccount.Name, quayOrganizationName+"+")
+
+		if robotName == constants.NamespaceOwnerRobotName {
+			continue
+		}
+
+		_, regenerateResponse, regenerateError := orgActuator.RegenerateOrganizationRobotAccountToken(quayOrganizationName, robotName)
+
+		if regenerateError.Error != nil || regenerateResponse.StatusCode != 200 {
+			return requestError(fmt.Sprintf("error regenerating token of robot account '%s'", robotAccount.Name), regenerateResponse, regenerateError.Error)
+		}
+
+		logging.Log.Info("Regenerated robot account token", "Quay Organization", quayOrganizationName, "Robot Account", robotAccount.Name)
+	}
+
+	return nil
+}
+
+// reconcileRegistryAccessCheck authenticates against the registry using the credentials of the builder robot account once the
+// Secrets of a namespace are created, catching misconfigured registry authentication before the first push of a Build fails.
+// The registry authenticated against is recorded on the namespace, so the check is only repeated while it fails or once the
+// registry hostname changes
+func (r *NamespaceIntegrationReconciler) reconcileRegistryAccessCheck(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !quayIntegration.Spec.RegistryAccessCheck {
+		return reconcile.Result{}, nil
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to parse Quay hostname",
+			KeyAndValues: []interface{}{"Hostname", quayIntegration.GetQuayHostname()},
+			Error:        err,
+		})
+	}
+
+	if namespace.Annotations[constants.RegistryAccessVerifiedAnnotation] == registryHostname {
+		return reconcile.Result{}, nil
+	}
+
+	// Validated before the robot accounts are reconciled
+	robotName, _ := utils.GenerateRobotAccountName(quayIntegration, namespace.Name, quayOrganizationName, string(qotypes.BuilderOpenShiftServiceAccount))
+
+	robotAccount, robotAccountResponse, robotAccountError := quayClient.GetOrganizationRobotAccount(quayOrganizationName, robotName)
+
+	if robotAccountError.Error != nil || robotAccountResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving robot account for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName},
+			Error:        requestError("error retrieving robot account", robotAccountResponse, robotAccountError.Error),
+		})
+	}
+
+	if err := credentials.PingRegistry(quayClient.HTTPClient(), registryHostname, robotAccount.Name, robotAccount.Token); err != nil {
+
+		logging.Log.Info("Registry access check failed", "Namespace", namespace.Name, "Robot Account", robotAccount.Name, "Registry", registryHostname, "Error", err.Error())
+		r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "RegistryAccessCheckFailed", fmt.Sprintf("Robot account %s could not authenticate against registry %s: %v", robotAccount.Name, registryHostname, err))
+
+		return reconcile.Result{}, nil
+	}
+
+	err = r.CoreComponents.ReconcilerBase.UpdateResource(ctx, namespace, func() error {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[constants.RegistryAccessVerifiedAnnotation] = registryHostname
+
+		return nil
+	})
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to update namespace",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Normal", "RegistryAccessVerified", fmt.Sprintf("Robot account %s authenticated against registry %s", robotAccount.Name, registryHostname))
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileReaderRobot creates the reader robot account in the organization
+func (r *NamespaceIntegrationReconciler) reconcileReaderRobot(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !r.ReaderRobot || quayIntegration.Spec.ReaderRobot == nil {
+		return reconcile.Result{}, nil
+	}
+
+	robotName := quayIntegration.GetReaderRobotName()
+
+	for serviceAccount, role := range QuayServiceAccountPermissionMatrix {
+
+		serviceAccountRobotName, _ := utils.GenerateRobotAccountName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount))
+
+		if serviceAccountRobotName == robotName && role != qclient.QuayRoleRead {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Reader robot account conflicts with a robot account granted write access",
+				KeyAndValues: []interface{}{"Robot Account", robotName, "Service Account", serviceAccount},
+				Reason:       "ConfigrurationError",
+			})
+		}
+	}
+
+	_, result, err := r.ensureReadRobotAccount(ctx, namespace, quayClient, quayOrganizationName, robotName, "Read only access to the organization", quayIntegration)
+
+	return result, err
+}
+
+// ensureReadRobotAccount creates a robot account of the organization granted read access to every repository of the organization
+func (r *NamespaceIntegrationReconciler) ensureReadRobotAccount(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, robotName string, purpose string, quayIntegration *quayv1.QuayIntegration) (qclient.RobotAccount, reconcile.Result, error) {
+
+	manageError := func(issue *core.QuayIntegrationCoreError) (qclient.RobotAccount, reconcile.Result, error) {
+		result, err := r.CoreComponents.ManageError(issue)
+		return qclient.RobotAccount{}, result, err
+	}
+
+	robotAccount, robotAccountResponse, robotAccountError := quayClient.GetOrganizationRobotAccount(quayOrganizationName, robotName)
+
+	if robotAccountError.Error != nil {
+		return manageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving robot account for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName, "Status Code", statusCode(robotAccountResponse)},
+			Error:        robotAccountError.Error,
+		})
+	}
+
+	if robotAccountResponse.StatusCode == 400 {
+
+		logging.Log.Info("Creating read only robot account", "Organization", quayOrganizationName, "Robot Account", robotName)
+
+		robotAccount, robotAccountResponse, robotAccountError = r.orgActuator(quayClient).CreateOrganizationRobotAccountWithMetadata(quayOrganizationName, robotName, newRobotAccountRequest(namespace, quayIntegration, "", purpose))
+
+		if robotAccountError.Error != nil || robotAccountResponse.StatusCode != 201 {
+			return manageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred creating robot account for Quay Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName, "Status Code", statusCode(robotAccountResponse)},
+				Error:        robotAccountError.Error,
+			})
+		}
+
+	} else if robotAccountResponse.StatusCode != 200 {
+		return manageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving robot account for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName, "Status Code", robotAccountResponse.StatusCode},
+		})
+	}
+
+	result, err := r.ensureReadAccess(ctx, namespace, quayClient, quayOrganizationName, robotName, robotAccount.Name, quayIntegration)
+
+	if err != nil {
+		return qclient.RobotAccount{}, result, err
+	}
+
+	return robotAccount, result, nil
+}
+
+// ensureReadAccess grants a robot account read access to every repository of the organization. The default permission only covers
+// repositories created afterwards, so existing repositories are granted read access before the default permission is created
+func (r *NamespaceIntegrationReconciler) ensureReadAccess(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, robotName string, robotAccountName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	organizationPrototypes, organizationPrototypesResponse, organizationPrototypesError := quayClient.GetPrototypesByOrganization(quayOrganizationName)
+
+	if organizationPrototypesError.Error != nil || organizationPrototypesResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Prototypes for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", statusCode(organizationPrototypesResponse)},
+			Error:        organizationPrototypesError.Error,
+		})
+	}
+
+	// The default permission is created last, marking the grants on existing repositories as complete
+	if qclient.IsRobotAccountInPrototypeByRole(organizationPrototypes.Prototypes, robotAccountName, string(qclient.QuayRoleRead)) {
+		return reconcile.Result{}, nil
+	}
+
+	// Organizations may contain many repositories, so read access is granted in the background when the job queue is available
+	if r.Jobs != nil {
+
+		job, err := r.Jobs.Enqueue(ctx, jobs.Job{
+			ID:   jobs.GenerateID(GrantReadAccessJobKind, quayOrganizationName, robotName),
+			Kind: GrantReadAccessJobKind,
+			Params: map[string]string{
+				grantReadAccessQuayIntegrationParam: quayIntegration.Name,
+				grantReadAccessOrganizationParam:    quayOrganizationName,
+				grantReadAccessRobotAccountParam:    robotAccountName,
+			},
+		})
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred queueing grant of read access to robot account",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotAccountName},
+				Error:        err,
+			})
+		}
+
+		if job.State == jobs.FailedState {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred granting read access to robot account",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotAccountName, "Attempts", job.Attempts},
+				Error:        fmt.Errorf("%s", job.Message),
+			})
+		}
+
+		return reconcile.Result{RequeueAfter: grantReadAccessPollInterval}, nil
+	}
+
+	if err := grantReadAccess(quayClient, r.orgActuator(quayClient), quayOrganizationName, robotAccountName, "", nil); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred granting read access to robot account",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotAccountName},
+			Error:        err,
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// runGrantReadAccessJob grants a robot account read access to the repositories of an organization, resuming after the last repository checkpointed
+func (r *NamespaceIntegrationReconciler) runGrantReadAccessJob(ctx context.Context, job jobs.Job, checkpoint func(progress string) error) error {
+
+	quayIntegration, err := state.GetQuayIntegration(ctx, r.CoreComponents.ReconcilerBase.GetClient(), job.Params[grantReadAccessQuayIntegrationParam])
+
+	if err != nil {
+		return err
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, r.CoreComponents.ReconcilerBase.GetClient(), quayIntegration, r.HTTPClientPool)
+
+	if err != nil {
+		return err
+	}
+
+	return grantReadAccess(quayClient, r.orgActuator(quayClient), job.Params[grantReadAccessOrganizationParam], job.Params[grantReadAccessRobotAccountParam], job.Checkpoint, checkpoint)
+}
+
+// grantReadAccess grants a robot account read access to the repositories of an organization sorted after the last repository granted, then
+// creates the default permission granting read access to repositories created afterwards. Progress is recorded every few repositories when requested
+func grantReadAccess(quayClient *qclient.QuayClient, orgActuator actuator.OrgActuator, quayOrganizationName string, robotAccountName string, lastRepository string, checkpoint func(progress string) error) error {
+
+	repositories, repositoriesResponse, repositoriesError := quayClient.GetRepositoriesByOrganization(quayOrganizationName)
+
+	if repositoriesError.Error != nil || repositoriesResponse.StatusCode != 200 {
+		return requestError(fmt.Sprintf("error retrieving repositories of organization %s", quayOrganizationName), repositoriesResponse, repositoriesError.Error)
+	}
+
+	sort.Slice(repositories, func(i, j int) bool {
+		return repositories[i].Name < repositories[j].Name
+	})
+
+	granted := 0
+
+	for _, repository := range repositories {
+
+		if repository.Name <= lastRepository {
+			continue
+		}
+
+		permissionResponse, permissionError := orgActuator.SetRepositoryUserPermission(quayOrganizationName, repository.Name, robotAccountName, string(qclient.QuayRoleRead))
+
+		if permissionError.Error != nil || permissionResponse.StatusCode != 200 {
+			return requestError(fmt.Sprintf("error granting read access to repository %s/%s", quayOrganizationName, repository.Name), permissionResponse, permissionError.Error)
+		}
+
+		granted++
+
+		if checkpoint != nil && granted%grantReadAccessCheckpointInterval == 0 {
+			if err := checkpoint(repository.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, robotPrototypeResponse, robotPrototypeError := orgActuator.CreateRobotPermissionForOrganization(quayOrganizationName, robotAccountName, string(qclient.QuayRoleRead))
+
+	if robotPrototypeError.Error != nil || robotPrototypeResponse.StatusCode != 200 {
+		return requestError(fmt.Sprintf("error creating default permission of organization %s", quayOrganizationName), robotPrototypeResponse, robotPrototypeError.Error)
+	}
+
+	return nil
+}
+
+// reconcilePullGrants grants the namespaces listed by the grant-pull-to annotation read access to the organization. Quay only grants
+// robot accounts access within their own organization, so a robot account is created in the organization for each grantee and its
+// credentials are written to the grantee namespace. Grants recorded by the granted-pull-to annotation and no longer listed are revoked
+func (r *NamespaceIntegrationReconciler) reconcilePullGrants(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !r.PullGrants {
+		return reconcile.Result{}, nil
+	}
+
+	desired := utils.ParsePullGrants(namespace.Name, namespace.Annotations[constants.QuayGrantPullToAnnotation])
+	granted := utils.ParsePullGrants(namespace.Name, namespace.Annotations[constants.QuayGrantedPullToAnnotation])
+
+	if len(desired) == 0 && len(granted) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to parse Quay hostname",
+			KeyAndValues: []interface{}{"Hostname", quayIntegration.GetQuayHostname()},
+			Error:        err,
+		})
+	}
+
+	syncScope, err := syncpolicy.LoadScope(ctx, r.CoreComponents.ReconcilerBase.GetClient())
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:  namespace,
+			Message: "Error Retrieving QuayIntegration",
+			Error:   err,
+		})
+	}
+
+	active := []string{}
+	activeGrantees := map[string]bool{}
+	pendingResult := reconcile.Result{}
+
+	for _, grantee := range desired {
+
+		granteeNamespace := &corev1.Namespace{}
+
+		if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Name: grantee}, granteeNamespace); err != nil && !errors.IsNotFound(err) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred retrieving pull grant namespace",
+				KeyAndValues: []interface{}{"Namespace", grantee},
+				Error:        err,
+			})
+		} else if err != nil || granteeNamespace.DeletionTimestamp != nil || !syncScope.IsSynchronized(quayIntegration, granteeNamespace) || !cachescope.InNamespaces(r.Namespaces, grantee) {
+			r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "PullGrantIgnored", fmt.Sprintf("Namespace %s does not exist or is not managed by the operator", grantee))
+			continue
+		}
+
+		robotAccount, result, err := r.ensureReadRobotAccount(ctx, namespace, quayClient, quayOrganizationName, utils.GeneratePullGrantRobotName(grantee), fmt.Sprintf("Pull access granted to namespace %s", grantee), quayIntegration)
+
+		if err != nil || result.Requeue {
+			return result, err
+		}
+
+		// The credentials are usable while read access is being granted in the background
+		if result.RequeueAfter > 0 {
+			pendingResult = result
+		}
+
+		secret, err := credentials.GenerateDockerJsonSecret(utils.GeneratePullGrantSecretName(namespace.Name), pullsecret.GenerateAuthKey(registryHostname, quayOrganizationName), robotAccount.Name, robotAccount.Token, "")
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to generate pull grant Secret",
+				KeyAndValues: []interface{}{"Namespace", grantee, "Robot Account", robotAccount.Name},
+				Error:        err,
+			})
+		}
+
+		quayIntegration.ApplyResourceMetadata(secret)
+
+		if err := r.writePullGrant(ctx, grantee, secret, quayIntegration); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to write pull grant Secret",
+				KeyAndValues: []interface{}{"Namespace", grantee, "Secret", secret.Name},
+				Error:        err,
+			})
+		}
+
+		active = append(active, grantee)
+		activeGrantees[grantee] = true
+	}
+
+	for _, grantee := range granted {
+
+		if activeGrantees[grantee] {
+			continue
+		}
+
+		logging.Log.Info("Revoking pull grant", "Organization", quayOrganizationName, "Namespace", grantee)
+
+		robotName := utils.GeneratePullGrantRobotName(grantee)
+
+		deleteResponse, deleteError := r.orgActuator(quayClient).DeleteOrganizationRobotAccount(quayOrganizationName, robotName)
+
+		if deleteError.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 400 && deleteResponse.StatusCode != 404) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred deleting robot account for Quay Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName, "Status Code", statusCode(deleteResponse)},
+				Error:        deleteError.Error,
+			})
+		}
+
+		if err := r.removePullGrant(ctx, grantee, utils.GeneratePullGrantSecretName(namespace.Name), quayIntegration); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to remove pull grant Secret",
+				KeyAndValues: []interface{}{"Namespace", grantee},
+				Error:        err,
+			})
+		}
+	}
+
+	if reflect.DeepEqual(granted, active) {
+		return pendingResult, nil
+	}
+
+	if err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, namespace, func() error {
+		setPullGrantsAnnotation(namespace, active)
+		return nil
+	}); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to update namespace",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	return pendingResult, nil
+}
+
+// removePullGrants removes the Secrets written to the namespaces granted read access to a namespace being deleted. The robot
+// accounts are deleted along with the organization
+func (r *NamespaceIntegrationReconciler) removePullGrants(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !r.PullGrants {
+		return reconcile.Result{}, nil
+	}
+
+	for _, grantee := range utils.ParsePullGrants(namespace.Name, namespace.Annotations[constants.QuayGrantedPullToAnnotation]) {
+		if err := r.removePullGrant(ctx, grantee, utils.GeneratePullGrantSecretName(namespace.Name), quayIntegration); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to remove pull grant Secret",
+				KeyAndValues: []interface{}{"Namespace", grantee},
+				Error:        err,
+			})
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// writePullGrant writes a pull grant Secret to the grantee namespace and adds it to the image pull secrets of its service accounts
+func (r *NamespaceIntegrationReconciler) writePullGrant(ctx context.Context, grantee string, secret *corev1.Secret, quayIntegration *quayv1.QuayIntegration) error {
+
+	writer, err := r.namespaceWriter(ctx, grantee, quayIntegration)
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.secretActuator().ApplySecret(ctx, writer, grantee, secret); err != nil {
+		return err
+	}
+
+	return r.linkPullGrant(ctx, writer, grantee, secret.Name, true)
+}
+
+// removePullGrant removes a pull grant Secret from the image pull secrets of the service accounts of the grantee namespace and deletes it
+func (r *NamespaceIntegrationReconciler) removePullGrant(ctx context.Context, grantee string, secretName string, quayIntegration *quayv1.QuayIntegration) error {
+
+	granteeNamespace := &corev1.Namespace{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Name: grantee}, granteeNamespace); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	// Resources of namespaces being deleted are removed along with them
+	if granteeNamespace.DeletionTimestamp != nil {
+		return nil
+	}
+
+	writer, err := r.namespaceWriter(ctx, grantee, quayIntegration)
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.linkPullGrant(ctx, writer, grantee, secretName, false); err != nil {
+		return err
+	}
+
+	return r.secretActuator().DeleteSecret(ctx, writer, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: grantee, Name: secretName}})
+}
+
+// linkPullGrant adds or removes a pull grant Secret from the image pull secrets of the service accounts pulling images in the grantee namespace
+func (r *NamespaceIntegrationReconciler) linkPullGrant(ctx context.Context, writer reconcilerbase.ReconcilerBase, grantee string, secretName string, link bool) error {
+
+	for _, serviceAccountName := range []qotypes.OpenShiftServiceAccount{qotypes.BuilderOpenShiftServiceAccount, qotypes.DefaultOpenShiftServiceAccount, qotypes.DeployerOpenShiftServiceAccount} {
+
+		serviceAccount := &corev1.ServiceAccount{}
+
+		if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: grantee, Name: string(serviceAccountName)}, serviceAccount); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if utils.LocalObjectReferenceNameExists(serviceAccount.ImagePullSecrets, secretName) == link {
+			continue
+		}
+
+		if err := writer.UpdateResource(ctx, serviceAccount, func() error {
+
+			imagePullSecrets := []corev1.LocalObjectReference{}
+
+			for _, imagePullSecret := range serviceAccount.ImagePullSecrets {
+				if imagePullSecret.Name != secretName {
+					imagePullSecrets = append(imagePullSecrets, imagePullSecret)
+				}
+			}
+
+			if link {
+				imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+			}
+
+			serviceAccount.ImagePullSecrets = imagePullSecrets
+
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setPullGrantsAnnotation(namespace *corev1.Namespace, grantees []string) {
+
+	if len(grantees) == 0 {
+		delete(namespace.Annotations, constants.QuayGrantedPullToAnnotation)
+		return
 	}
 
-	return serviceAccount, updated
+	if namespace.Annotations == nil {
+		namespace.Annotations = map[string]string{}
+	}
+
+	namespace.Annotations[constants.QuayGrantedPullToAnnotation] = strings.Join(grantees, ",")
+}
+
+// reconcileGlobalPullSecret grants the nodes robot account of the cluster read access to the organization and adds its credentials
+// to the global pull secret, letting nodes pull images of every organization with a single auth. Auths of the global pull secret
+// not added by the operator are never replaced
+func (r *NamespaceIntegrationReconciler) reconcileGlobalPullSecret(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !r.GlobalPullSecret || !quayIntegration.ManagesClusterSecrets() {
+		return reconcile.Result{}, nil
+	}
+
+	robotName := utils.GenerateNodesRobotName(quayIntegration.Spec.ClusterID)
+
+	robotAccount, robotAccountResponse, robotAccountError := quayClient.GetUserRobotAccount(robotName)
+
+	if robotAccountError.Error != nil || (robotAccountResponse.StatusCode != 200 && robotAccountResponse.StatusCode != 400 && robotAccountResponse.StatusCode != 404) {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving robot account of the operator account",
+			KeyAndValues: []interface{}{"Robot Account", robotName},
+			Error:        requestError("error retrieving robot account", robotAccountResponse, robotAccountError.Error),
+		})
+	}
+
+	if robotAccountResponse.StatusCode != 200 {
+
+		logging.Log.Info("Creating nodes robot account of the operator account", "Robot Account", robotName)
+
+		robotAccount, robotAccountResponse, robotAccountError = r.orgActuator(quayClient).CreateUserRobotAccountWithMetadata(robotName, newNodesRobotAccountRequest(quayIntegration))
+
+		if robotAccountError.Error != nil || robotAccountResponse.StatusCode != 201 {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred creating robot account of the operator account",
+				KeyAndValues: []interface{}{"Robot Account", robotName},
+				Error:        requestError("error creating robot account", robotAccountResponse, robotAccountError.Error),
+			})
+		}
+	}
+
+	result, err := r.ensureReadAccess(ctx, namespace, quayClient, quayOrganizationName, robotName, robotAccount.Name, quayIntegration)
+
+	if err != nil || result.Requeue {
+		return result, err
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to parse Quay hostname",
+			KeyAndValues: []interface{}{"Hostname", quayIntegration.GetQuayHostname()},
+			Error:        err,
+		})
+	}
+
+	// Earlier versions of the operator added an auth scoped to each organization
+	organizationKey := pullsecret.GenerateAuthKey(registryHostname, quayOrganizationName)
+
+	err = r.updateGlobalPullSecret(ctx, func(secret *corev1.Secret) error {
+
+		if _, err := pullsecret.RemoveAuth(secret, organizationKey); err != nil {
+			return err
+		}
+
+		_, err := pullsecret.SetAuth(secret, registryHostname, robotAccount.Name, robotAccount.Token)
+		return err
+	})
+
+	if err == pullsecret.ErrUnmanagedAuth {
+		r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", "GlobalPullSecretConflict", fmt.Sprintf("Auth %s of the global pull secret is not managed by the operator", registryHostname))
+		return result, nil
+	}
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred updating global pull secret",
+			KeyAndValues: []interface{}{"Namespace", constants.GlobalPullSecretNamespace, "Secret", constants.GlobalPullSecretName, "Auth", registryHostname},
+			Error:        err,
+		})
+	}
+
+	return result, nil
+}
+
+// removeGlobalPullSecretAuth removes the auth scoped to the organization added to the global pull secret by earlier versions of the
+// operator. The auth of the nodes robot account is shared by every organization and kept
+func (r *NamespaceIntegrationReconciler) removeGlobalPullSecretAuth(ctx context.Context, namespace *corev1.Namespace, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !r.GlobalPullSecret {
+		return reconcile.Result{}, nil
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return reconcile.Result{}, nil
+	}
+
+	key := pullsecret.GenerateAuthKey(registryHostname, quayOrganizationName)
+
+	err = r.updateGlobalPullSecret(ctx, func(secret *corev1.Secret) error {
+		_, err := pullsecret.RemoveAuth(secret, key)
+		return err
+	})
+
+	if err != nil && !errors.IsNotFound(err) {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred updating global pull secret",
+			KeyAndValues: []interface{}{"Namespace", constants.GlobalPullSecretNamespace, "Secret", constants.GlobalPullSecretName, "Auth", key},
+			Error:        err,
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// updateGlobalPullSecret applies mutate to the global pull secret, reapplying it when the update conflicts. Every update of the
+// global pull secret is rolled out to each node of the cluster, so the Secret is only written when its content changes
+func (r *NamespaceIntegrationReconciler) updateGlobalPullSecret(ctx context.Context, mutate func(secret *corev1.Secret) error) error {
+
+	secret := &corev1.Secret{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: constants.GlobalPullSecretNamespace, Name: constants.GlobalPullSecretName}, secret); err != nil {
+		return err
+	}
+
+	mutated := secret.DeepCopy()
+
+	if err := mutate(mutated); err != nil {
+		return err
+	}
+
+	if bytes.Equal(mutated.Data[corev1.DockerConfigJsonKey], secret.Data[corev1.DockerConfigJsonKey]) && mutated.Annotations[pullsecret.ManagedAuthsAnnotation] == secret.Annotations[pullsecret.ManagedAuthsAnnotation] {
+		return nil
+	}
+
+	return r.CoreComponents.ReconcilerBase.UpdateResource(ctx, secret, func() error {
+		return mutate(secret)
+	})
+}
+
+// newNodesRobotAccountRequest describes the nodes robot account of the cluster, shared by every synchronized namespace
+func newNodesRobotAccountRequest(quayIntegration *quayv1.QuayIntegration) qclient.RobotAccountRequest {
+
+	created := time.Now().UTC().Format(time.RFC3339)
+
+	return qclient.RobotAccountRequest{
+		Description: fmt.Sprintf("Global pull secret of cluster %s. Created by the Quay Bridge Operator %s on %s", quayIntegration.Spec.ClusterID, version.Version, created),
+		UnstructuredMetadata: map[string]interface{}{
+			constants.ClusterIDRobotMetadataKey:       quayIntegration.Spec.ClusterID,
+			constants.OperatorVersionRobotMetadataKey: version.Version,
+			constants.CreatedRobotMetadataKey:         created,
+		},
+	}
+}
+
+// needsRobotAccountSecrets returns whether a Secret of a robot account is missing from a namespace or does not contain
+// credentials for the registry
+func (r *NamespaceIntegrationReconciler) needsRobotAccountSecrets(ctx context.Context, namespace string, quayIntegration *quayv1.QuayIntegration) bool {
+
+	getSecret := func(name string) *corev1.Secret {
+
+		secret := &corev1.Secret{}
+
+		if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			return nil
+		}
+
+		return secret
+	}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+		if !utils.HasRobotAccountSecrets(quayIntegration, namespace, string(serviceAccount), getSecret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRobotAccountSecretNames ensures the configured SecretNameTemplate produces a distinct Secret for each robot account and
+// configured Secret format
+func validateRobotAccountSecretNames(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string) error {
+
+	secretNames := map[string]string{}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+		for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+			secretName, err := utils.GenerateRobotAccountSecretName(quayIntegration, namespace, quayOrganizationName, string(serviceAccount), secretFormat)
+
+			if err != nil {
+				return err
+			}
+
+			robotSecret := fmt.Sprintf("%s (%s)", serviceAccount, secretFormat)
+
+			if existingRobotSecret, found := secretNames[secretName]; found {
+				return fmt.Errorf("secret name '%s' generated for both '%s' and '%s'", secretName, existingRobotSecret, robotSecret)
+			}
+
+			secretNames[secretName] = robotSecret
+		}
+	}
+
+	return nil
+}
+
+// validateRobotAccountNames ensures the configured RobotNameTemplate produces a distinct robot account for each service account
+// which does not replace the robot account recording the owner of the organization
+func validateRobotAccountNames(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string) error {
+
+	robotNames := map[string]qotypes.OpenShiftServiceAccount{}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+
+		robotName, err := utils.GenerateRobotAccountName(quayIntegration, namespace, quayOrganizationName, string(serviceAccount))
+
+		if err != nil {
+			return err
+		}
+
+		if robotName == constants.NamespaceOwnerRobotName {
+			return fmt.Errorf("robot account name '%s' generated for service account '%s' is reserved", robotName, serviceAccount)
+		}
+
+		if existingServiceAccount, found := robotNames[robotName]; found {
+			return fmt.Errorf("robot account name '%s' generated for both service accounts '%s' and '%s'", robotName, existingServiceAccount, serviceAccount)
+		}
+
+		robotNames[robotName] = serviceAccount
+	}
+
+	return nil
+}
+
+// newRobotAccountRequest returns the description and metadata recording which cluster, namespace and operator version
+// created a robot account, letting Quay administrators trace robot accounts back to their origin
+func newRobotAccountRequest(namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration, serviceAccount string, purpose string) qclient.RobotAccountRequest {
+
+	created := time.Now().UTC().Format(time.RFC3339)
+
+	metadata := provenance.New(quayIntegration, namespace).RobotMetadata()
+	metadata[constants.CreatedRobotMetadataKey] = created
+
+	if serviceAccount != "" {
+		metadata[constants.ServiceAccountRobotMetadataKey] = serviceAccount
+	}
+
+	return qclient.RobotAccountRequest{
+		Description:          fmt.Sprintf("%s of namespace %s on cluster %s. Created by the Quay Bridge Operator %s on %s", purpose, namespace.Name, quayIntegration.Spec.ClusterID, version.Version, created),
+		UnstructuredMetadata: metadata,
+	}
+}
+
+// generateRobotAccountSecret generates a Secret in the requested format containing the credentials of a robot account
+func generateRobotAccountSecret(secretFormat quayv1.SecretFormat, secretName string, registryHostname string, robotAccount qclient.RobotAccount) (*corev1.Secret, error) {
+
+	switch secretFormat {
+	case quayv1.DockerConfigJsonSecretFormat:
+		return credentials.GenerateDockerJsonSecret(secretName, registryHostname, robotAccount.Name, robotAccount.Token, "")
+	case quayv1.DockerCfgSecretFormat:
+		return credentials.GenerateDockerCfgSecret(secretName, registryHostname, robotAccount.Name, robotAccount.Token, "")
+	case quayv1.BasicAuthSecretFormat:
+		return credentials.GenerateBasicAuthSecret(secretName, registryHostname, robotAccount.Name, robotAccount.Token), nil
+	default:
+		return nil, fmt.Errorf("unsupported secret format '%s'", secretFormat)
+	}
+}
+
+// getRepositoryNotifications returns the notifications to configure on the repositories of a namespace. Notifications defined
+// in the namespace annotation are added to those of the QuayIntegration, replacing any with the same title
+func getRepositoryNotifications(quayIntegration *quayv1.QuayIntegration, namespace *corev1.Namespace) ([]qclient.NotificationRequest, error) {
+
+	repositoryNotifications := append([]quayv1.RepositoryNotification{}, quayIntegration.Spec.RepositoryNotifications...)
+
+	if value, ok := namespace.Annotations[constants.QuayNotificationsAnnotation]; ok {
+
+		namespaceNotifications := []quayv1.RepositoryNotification{}
+
+		if err := json.Unmarshal([]byte(value), &namespaceNotifications); err != nil {
+			return nil, err
+		}
+
+		for _, namespaceNotification := range namespaceNotifications {
+
+			replaced := false
+
+			for i := range repositoryNotifications {
+				if repositoryNotifications[i].Title == namespaceNotification.Title {
+					repositoryNotifications[i] = namespaceNotification
+					replaced = true
+				}
+			}
+
+			if !replaced {
+				repositoryNotifications = append(repositoryNotifications, namespaceNotification)
+			}
+		}
+	}
+
+	notificationRequests := []qclient.NotificationRequest{}
+
+	for _, repositoryNotification := range repositoryNotifications {
+
+		notificationRequest, err := generateNotificationRequest(repositoryNotification)
+
+		if err != nil {
+			return nil, err
+		}
+
+		notificationRequests = append(notificationRequests, notificationRequest)
+	}
+
+	return notificationRequests, nil
+}
+
+// getTriggerNotifications returns the webhooks requested by the BuildConfigs of a namespace, keyed by the repository whose
+// pushes trigger them. Only repositories of the namespace can trigger BuildConfigs
+func getTriggerNotifications(namespace string, buildConfigs []buildv1.BuildConfig, repositories map[string]bool) map[string][]qclient.NotificationRequest {
+
+	triggerNotifications := map[string][]qclient.NotificationRequest{}
+
+	for _, buildConfig := range buildConfigs {
+
+		triggerURL, found := buildConfig.Annotations[constants.QuayTriggerURLAnnotation]
+
+		if !found {
+			continue
+		}
+
+		if parsedURL, err := url.Parse(triggerURL); err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			logging.Log.Info("Ignoring invalid trigger URL requested by BuildConfig", "Namespace", namespace, "BuildConfig", buildConfig.Name)
+			continue
+		}
+
+		repositoryName, found := buildConfig.Annotations[constants.QuayTriggerRepositoryAnnotation]
+
+		if !found {
+			repositoryName = getBaseImageStream(namespace, &buildConfig)
+		}
+
+		if !repositories[repositoryName] {
+			logging.Log.Info("Ignoring trigger requested by BuildConfig on a repository not managed in the namespace", "Namespace", namespace, "BuildConfig", buildConfig.Name, "Repository", repositoryName)
+			continue
+		}
+
+		triggerNotifications[repositoryName] = append(triggerNotifications[repositoryName], qclient.NotificationRequest{
+			Title:       fmt.Sprintf("%strigger/%s", constants.ManagedNotificationTitlePrefix, buildConfig.Name),
+			Event:       string(quayv1.RepoPushNotificationEvent),
+			Method:      string(quayv1.WebhookNotificationMethod),
+			Config:      map[string]interface{}{"url": triggerURL},
+			EventConfig: map[string]interface{}{},
+		})
+	}
+
+	return triggerNotifications
+}
+
+// getBaseImageStream returns the ImageStream of the namespace used as the builder or base image of a BuildConfig, if any
+func getBaseImageStream(namespace string, buildConfig *buildv1.BuildConfig) string {
+
+	from := getStrategyFrom(buildConfig)
+
+	if from == nil || from.Kind != "ImageStreamTag" || (from.Namespace != "" && from.Namespace != namespace) {
+		return ""
+	}
+
+	return strings.SplitN(from.Name, ":", 2)[0]
+}
+
+// getStrategyFrom returns the builder or base image of a BuildConfig, if any
+func getStrategyFrom(buildConfig *buildv1.BuildConfig) *corev1.ObjectReference {
+
+	strategy := buildConfig.Spec.Strategy
+
+	switch {
+	case strategy.DockerStrategy != nil:
+		return strategy.DockerStrategy.From
+	case strategy.SourceStrategy != nil:
+		return &strategy.SourceStrategy.From
+	case strategy.CustomStrategy != nil:
+		return &strategy.CustomStrategy.From
+	}
+
+	return nil
+}
+
+// generateNotificationRequest converts a RepositoryNotification to a notification managed by the operator in Quay
+func generateNotificationRequest(repositoryNotification quayv1.RepositoryNotification) (qclient.NotificationRequest, error) {
+
+	if repositoryNotification.Title == "" || repositoryNotification.Target == "" {
+		return qclient.NotificationRequest{}, fmt.Errorf("notification '%s' must define a title and target", repositoryNotification.Title)
+	}
+
+	notificationRequest := qclient.NotificationRequest{
+		Title:       constants.ManagedNotificationTitlePrefix + repositoryNotification.Title,
+		Event:       string(repositoryNotification.Event),
+		Method:      string(repositoryNotification.Method),
+		EventConfig: map[string]interface{}{},
+	}
+
+	switch repositoryNotification.Method {
+	case quayv1.SlackNotificationMethod, quayv1.WebhookNotificationMethod:
+		notificationRequest.Config = map[string]interface{}{"url": repositoryNotification.Target}
+	case quayv1.EmailNotificationMethod:
+		notificationRequest.Config = map[string]interface{}{"email": repositoryNotification.Target}
+	default:
+		return qclient.NotificationRequest{}, fmt.Errorf("unsupported notification method '%s'", repositoryNotification.Method)
+	}
+
+	switch repositoryNotification.Event {
+	case quayv1.VulnerabilityFoundNotificationEvent:
+		level := repositoryNotification.VulnerabilityLevel
+
+		if level == "" {
+			level = quayv1.CriticalVulnerabilityLevel
+		}
+
+		priority, ok := qclient.VulnerabilityPriority(string(level))
+
+		if !ok {
+			return qclient.NotificationRequest{}, fmt.Errorf("unsupported vulnerability level '%s'", level)
+		}
+
+		// Quay compares the index of the priority of vulnerabilities with the level rather than their severity
+		notificationRequest.EventConfig["level"] = priority
+	case quayv1.RepoPushNotificationEvent, quayv1.RepoMirrorSyncFailedNotificationEvent:
+	default:
+		return qclient.NotificationRequest{}, fmt.Errorf("unsupported notification event '%s'", repositoryNotification.Event)
+	}
+
+	return notificationRequest, nil
+}
+
+func statusCode(response *http.Response) int {
+
+	if response == nil {
+		return 0
+	}
+
+	return response.StatusCode
+}
+
+// requestError describes a failed request against the Quay API
+func requestError(message string, response *http.Response, err error) error {
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
+	return fmt.Errorf("%s: unexpected status code %d", message, statusCode(response))
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NamespaceIntegrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
-	//Retriggers a reconcilation of a namespace upon a change to an ImageStream within a namespace. Currently only supports adding repositories to Quay
+	//Retriggers a reconcilation of a namespace upon a change to an ImageStream or BuildConfig within a namespace. Currently only supports adding repositories to Quay
 	imageStreamToNamespace := handler.MapFunc(
 		func(a client.Object) []reconcile.Request {
 			res := []reconcile.Request{}
@@ -568,8 +3157,90 @@ func (r *NamespaceIntegrationReconciler) SetupWithManager(mgr ctrl.Manager) erro
 
 		})
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Namespace{}).
+	if r.Jobs != nil {
+		r.Jobs.Register(GrantReadAccessJobKind, r.runGrantReadAccessJob)
+	}
+
+	// Namespaces are tracked from the time they are queued so that the synchronization backlog can be measured. Updates
+	// only recording the usage of the organization do not require a synchronization
+	queuedNamespace := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			metrics.RecordNamespaceQueued(e.Object.GetName())
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNamespace, oldOk := e.ObjectOld.(*corev1.Namespace)
+			namespace, ok := e.ObjectNew.(*corev1.Namespace)
+
+			return !oldOk || !ok || !usage.OnlyAnnotationsChanged(oldNamespace, namespace)
+		},
+	}
+
+	// Namespaces created or changed by users, projects pending provisioning, namespaces whose synchronization was requested and
+	// namespaces missing their Secrets are synchronized before routine resyncs
+	r.startedAt = time.Now()
+
+	classifier := &priority.Classifier{
+		StartedAt: r.startedAt,
+		IsUrgent: func(obj client.Object) bool {
+			namespace, ok := obj.(*corev1.Namespace)
+
+			if !ok {
+				return false
+			}
+
+			if utils.IsProvisioningPending(namespace) || namespace.Annotations[constants.ResyncRequestedAnnotation] != "" {
+				return true
+			}
+
+			syncScope, err := syncpolicy.LoadScope(context.TODO(), mgr.GetClient())
+
+			if err != nil {
+				return false
+			}
+
+			quayIntegration := syncScope.Owner(namespace)
+
+			return quayIntegration != nil && r.needsRobotAccountSecrets(context.TODO(), obj.GetName(), quayIntegration)
+		},
+	}
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}, builder.WithPredicates(queuedNamespace, classifier.Urgent())).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, &priority.EnqueueRoutine{Throttle: r.RoutineThrottle}, builder.WithPredicates(queuedNamespace, classifier.Routine())).
 		Watches(&source.Kind{Type: &imagev1.ImageStream{}}, handler.EnqueueRequestsFromMapFunc(imageStreamToNamespace)).
+		Watches(&source.Kind{Type: &buildv1.BuildConfig{}}, handler.EnqueueRequestsFromMapFunc(imageStreamToNamespace)).
+		// Namespaces are synchronized as soon as one of their service accounts is labeled for, or unlabeled from, pull secret injection
+		// or a robot role, or a service account labeled with a robot role is created or deleted
+		Watches(&source.Kind{Type: &corev1.ServiceAccount{}}, handler.EnqueueRequestsFromMapFunc(imageStreamToNamespace), builder.WithPredicates(predicate.Or(injectPullSecretLabelChanged(), robotRoleLabelChanged())))
+
+	// Namespaces are synchronized as soon as one of their robot account Secrets is deleted or edited
+	if r.SecretProtection {
+		controllerBuilder = controllerBuilder.Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(imageStreamToNamespace), builder.WithPredicates(managedSecretChanged()))
+	}
+
+	return controllerBuilder.
+		WithEventFilter(cachescope.NamespacePredicate(r.Namespaces)).
 		Complete(r)
 }
+
+// managedSecretChanged filters events of Secrets to the deletion of, or edits made outside of the operator to, robot account Secrets
+func managedSecretChanged() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, oldOk := e.ObjectOld.(*corev1.Secret)
+			secret, ok := e.ObjectNew.(*corev1.Secret)
+
+			return oldOk && ok && oldSecret.Labels[constants.ManagedSecretLabel] == "true" && IsManagedSecretTampered(secret)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetLabels()[constants.ManagedSecretLabel] == "true"
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/oauthapplication"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileOAuthApplication creates the OAuth application of a QuayIntegration, resets its client secret once due for rotation
+// and stores its client credentials in the credentials Secret. The finalizer deleting the application is added before it is
+// created. Returns the status of the application
+func (r *QuayIntegrationReconciler) reconcileOAuthApplication(ctx context.Context, instance *quayv1.QuayIntegration, now time.Time) (*quayv1.OAuthApplicationStatus, error) {
+
+	if instance.Spec.OAuthApplication == nil {
+
+		// The application is left in Quay when no longer managed
+		if reconcilerbase.HasFinalizer(instance, constants.OAuthApplicationFinalizer) {
+			return nil, r.UpdateResource(ctx, instance, func() error {
+				reconcilerbase.RemoveFinalizer(instance, constants.OAuthApplicationFinalizer)
+				return nil
+			})
+		}
+
+		return nil, nil
+	}
+
+	if !reconcilerbase.HasFinalizer(instance, constants.OAuthApplicationFinalizer) {
+		if err := r.UpdateResource(ctx, instance, func() error {
+			reconcilerbase.AddFinalizer(instance, constants.OAuthApplicationFinalizer)
+			return nil
+		}); err != nil {
+			return instance.Status.OAuthApplication, err
+		}
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, r.GetAPIReader(), instance.DeepCopy(), r.HTTPClientPool)
+
+	if err != nil {
+		return instance.Status.OAuthApplication, err
+	}
+
+	result, err := oauthapplication.Ensure(quayClient, instance, now)
+
+	if err != nil {
+		return instance.Status.OAuthApplication, err
+	}
+
+	oauthApplicationStatus := &quayv1.OAuthApplicationStatus{ClientID: result.Application.ClientID}
+
+	if instance.Status.OAuthApplication != nil && instance.Status.OAuthApplication.ClientID == result.Application.ClientID {
+		oauthApplicationStatus.LastRotation = instance.Status.OAuthApplication.LastRotation
+	}
+
+	if result.Created || result.Rotated || oauthApplicationStatus.LastRotation == nil {
+		oauthApplicationStatus.LastRotation = &metav1.Time{Time: now}
+	}
+
+	if err := r.updateCredentialsSecret(ctx, instance, func(data map[string][]byte) bool {
+		return oauthapplication.ApplyCredentials(data, result.Application)
+	}); err != nil {
+		return oauthApplicationStatus, err
+	}
+
+	switch {
+	case result.Created:
+		r.GetRecorder().Eventf(instance, "Normal", quayv1.OAuthApplicationCreatedReason, "Created OAuth application %s in organization %s", result.Application.Name, instance.Spec.OAuthApplication.Organization)
+	case result.Rotated:
+		r.GetRecorder().Eventf(instance, "Normal", quayv1.OAuthApplicationRotatedReason, "Reset the client secret of OAuth application %s", result.Application.Name)
+	}
+
+	return oauthApplicationStatus, nil
+}
+
+// finalizeOAuthApplication deletes the OAuth application of a QuayIntegration being deleted, revoking its tokens, and removes its
+// client credentials from the credentials Secret. The application cannot be deleted once the credentials Secret is gone
+func (r *QuayIntegrationReconciler) finalizeOAuthApplication(ctx context.Context, instance *quayv1.QuayIntegration) (ctrl.Result, error) {
+
+	if !reconcilerbase.HasFinalizer(instance, constants.OAuthApplicationFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	logger := r.Log.WithValues("quayintegration", instance.Name)
+
+	quayClient, err := state.NewQuayClient(ctx, r.GetAPIReader(), instance.DeepCopy(), r.HTTPClientPool)
+
+	if apierrors.IsNotFound(err) {
+		r.GetRecorder().Event(instance, "Warning", quayv1.OAuthApplicationFailedReason, "The credentials Secret no longer exists, the OAuth application must be deleted manually")
+	} else if err != nil {
+		logger.Error(err, "Unable to create the Quay client deleting the OAuth application")
+		return reconcile.Result{}, err
+	} else {
+
+		if err := oauthapplication.Delete(quayClient, instance); err != nil {
+			logger.Error(err, "Failed to delete the OAuth application")
+			r.GetRecorder().Event(instance, "Warning", quayv1.OAuthApplicationFailedReason, fmt.Sprintf("Failed to delete the OAuth application: %v", err))
+			return reconcile.Result{}, err
+		}
+
+		if instance.Status.OAuthApplication != nil {
+			if err := r.updateCredentialsSecret(ctx, instance, func(data map[string][]byte) bool {
+				return oauthapplication.RemoveCredentials(data, instance.Status.OAuthApplication.ClientID)
+			}); err != nil && !apierrors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+		}
+
+		logger.Info("Deleted OAuth application", "Organization", instance.Spec.OAuthApplication.Organization)
+	}
+
+	return reconcile.Result{}, r.UpdateResource(ctx, instance, func() error {
+		reconcilerbase.RemoveFinalizer(instance, constants.OAuthApplicationFinalizer)
+		return nil
+	})
+}
+
+// updateCredentialsSecret updates the data of the credentials Secret of a QuayIntegration when changed by mutate
+func (r *QuayIntegrationReconciler) updateCredentialsSecret(ctx context.Context, instance *quayv1.QuayIntegration, mutate func(map[string][]byte) bool) error {
+
+	secret := &corev1.Secret{}
+
+	if err := r.GetAPIReader().Get(ctx, types.NamespacedName{Namespace: instance.Spec.CredentialsSecret.Namespace, Name: instance.Spec.CredentialsSecret.Name}, secret); err != nil {
+		return err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	if !mutate(secret.Data) {
+		return nil
+	}
+
+	return r.GetClient().Update(ctx, secret)
+}
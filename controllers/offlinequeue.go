@@ -0,0 +1,146 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/offline"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// operationAppliedReason is the reason of the event recorded when a change to Quay queued in offline mode is applied
+const operationAppliedReason = "QuayOperationApplied"
+
+// OfflineQueue applies the changes to Quay queued in offline mode once Quay is reachable again. The deletion of the organization of a
+// namespace created again is left to the namespace controller, which deletes it before provisioning the namespace. Queued synchronizations
+// are retried by the namespace controller and only dropped here when their namespace no longer exists
+type OfflineQueue struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+
+	// OrgActuator changes Quay. The default OrgActuator is used when not set
+	OrgActuator actuator.OrgActuatorFactory
+}
+
+// Start implements manager.Runnable
+func (o *OfflineQueue) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := o.replay(ctx); err != nil {
+			o.Log.Error(err, "Failed to apply queued Quay operations")
+		}
+	}, offline.RetryInterval)
+
+	return nil
+}
+
+func (o *OfflineQueue) replay(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := o.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	for i := range quayIntegrations.Items {
+
+		quayIntegration := &quayIntegrations.Items[i]
+
+		if len(quayIntegration.Status.PendingOperations) == 0 {
+			continue
+		}
+
+		quayClient, err := state.NewQuayClient(ctx, o.GetClient(), quayIntegration.DeepCopy(), o.HTTPClientPool)
+
+		if err != nil {
+			o.Log.Error(err, "Unable to create Quay client", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		if !offline.IsReachable(quayClient) {
+			o.Log.Info("Quay is unreachable, keeping operations queued", "QuayIntegration", quayIntegration.Name, "Pending", len(quayIntegration.Status.PendingOperations))
+			continue
+		}
+
+		for _, operation := range quayIntegration.Status.PendingOperations {
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if err := o.apply(ctx, quayIntegration, quayClient, operation); err != nil {
+
+				o.Log.Error(err, "Unable to apply queued Quay operation", "Type", operation.Type, "Organization", operation.Organization)
+
+				if err := offline.RecordError(ctx, o.GetClient(), quayIntegration.Name, operation.Type, operation.Organization, err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply applies a queued operation, removing it from the queue once it no longer needs to be applied
+func (o *OfflineQueue) apply(ctx context.Context, quayIntegration *quayv1.QuayIntegration, quayClient *qclient.QuayClient, operation quayv1.PendingOperation) error {
+
+	namespace := &corev1.Namespace{}
+	err := o.GetClient().Get(ctx, types.NamespacedName{Name: operation.Namespace}, namespace)
+
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	namespaceExists := err == nil
+
+	switch operation.Type {
+	case quayv1.DeleteOrganizationOperation:
+
+		if namespaceExists {
+			return nil
+		}
+
+		if err := offline.DeleteOrganization(quayClient, newOrgActuator(o.OrgActuator, quayClient), operation.Organization); err != nil {
+			return err
+		}
+
+		o.Log.Info("Deleted organization of namespace deleted while Quay was unreachable", "Namespace", operation.Namespace, "Organization", operation.Organization)
+		o.GetRecorder().Event(quayIntegration, "Normal", operationAppliedReason, fmt.Sprintf("Deleted organization %s of namespace %s queued while Quay was unreachable", operation.Organization, operation.Namespace))
+
+	case quayv1.SyncNamespaceOperation:
+
+		if namespaceExists {
+			return nil
+		}
+	}
+
+	return offline.Dequeue(ctx, o.GetClient(), quayIntegration.Name, operation.Type, operation.Organization)
+}
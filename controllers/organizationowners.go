@@ -0,0 +1,85 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileOrganizationOwners adds the configured organization owners missing from the owners team of the organization of a namespace,
+// restoring the owners removed in Quay. Other members of the team are left in place
+func (r *NamespaceIntegrationReconciler) reconcileOrganizationOwners(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if len(quayIntegration.Spec.OrganizationOwners) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	teamMembers, teamMembersResponse, teamMembersErr := quayClient.GetOrganizationTeamMembers(quayOrganizationName, qclient.OwnersTeamName)
+
+	if teamMembersErr.Error != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving owners of Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Team", qclient.OwnersTeamName},
+			Error:        teamMembersErr.Error,
+		})
+	}
+
+	// Only the members of the owners team, and superusers when granted full access, can manage the team
+	if teamMembersResponse.StatusCode == 401 || teamMembersResponse.StatusCode == 403 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Quay account of the operator is not an owner of Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Team", qclient.OwnersTeamName},
+			Error:        fmt.Errorf("the Quay account of the operator must be added back to the %s team of organization %s", qclient.OwnersTeamName, quayOrganizationName),
+		})
+	}
+
+	if teamMembersResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving owners of Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Team", qclient.OwnersTeamName},
+			Error:        requestError("error retrieving team members", teamMembersResponse, nil),
+		})
+	}
+
+	for _, owner := range qclient.MissingTeamMembers(teamMembers.Members, quayIntegration.Spec.OrganizationOwners) {
+
+		logging.Log.Info("Adding owner to Quay Organization", "Organization", quayOrganizationName, "User", owner)
+
+		_, addResponse, addErr := r.orgActuator(quayClient).AddOrganizationTeamMember(quayOrganizationName, qclient.OwnersTeamName, owner)
+
+		if addErr.Error != nil || addResponse.StatusCode != 200 {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred adding owner to Quay Organization",
+				KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Team", qclient.OwnersTeamName, "User", owner, "Status Code", statusCode(addResponse)},
+				Error:        requestError("error adding team member", addResponse, addErr.Error),
+			})
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/inventory"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// cleanupOrphans lists the resources created by the operator and records the orphaned resources in the status of the
+// QuayIntegration requesting the cleanup. A Delete cleanup only deletes the orphans reported by the previous dry run, so that
+// resources are never deleted before being reported. The annotation requesting the cleanup is removed once done
+func (r *QuayIntegrationReconciler) cleanupOrphans(ctx context.Context, instance *quayv1.QuayIntegration, mode string) (ctrl.Result, error) {
+
+	logger := r.Log.WithValues("quayintegration", instance.Name, "mode", mode)
+
+	if mode != inventory.DryRunCleanup && mode != inventory.DeleteCleanup {
+		r.GetRecorder().Eventf(instance, "Warning", quayv1.OrphanedResourcesReason, "Invalid orphan cleanup '%s', must be %s or %s", mode, inventory.DryRunCleanup, inventory.DeleteCleanup)
+
+		return reconcile.Result{}, r.removeOrphanCleanupAnnotation(ctx, instance)
+	}
+
+	inventoryState, err := inventory.LoadState(ctx, r.GetAPIReader())
+
+	if err != nil {
+		logger.Error(err, "Unable to load the state of the QuayIntegrations")
+		return reconcile.Result{}, err
+	}
+
+	resources, err := inventory.List(ctx, r.GetAPIReader(), inventoryState)
+
+	if err != nil {
+		logger.Error(err, "Unable to list the resources created by the operator")
+		return reconcile.Result{}, err
+	}
+
+	orphans := inventory.Orphans(resources)
+	remaining := orphans
+
+	if mode == inventory.DeleteCleanup {
+
+		results, deleteErr := r.DeleteResources(ctx, inventory.Objects(inventory.Reported(orphans, instance.Status.OrphanedResources)))
+
+		deleted := map[reconcilerbase.ObjectReference]bool{}
+
+		for _, result := range results {
+			if result.Error == nil {
+				deleted[result.Reference] = true
+				logger.Info("Deleted orphaned resource", "Resource", result.Reference.String())
+			}
+		}
+
+		remaining = []inventory.Resource{}
+
+		for _, orphan := range orphans {
+			if !deleted[orphan.Reference] {
+				remaining = append(remaining, orphan)
+			}
+		}
+
+		r.GetRecorder().Eventf(instance, "Normal", quayv1.OrphanedResourcesDeletedReason, "Deleted %d orphaned resources, %d orphaned resources remain", len(deleted), len(remaining))
+
+		if deleteErr != nil {
+			logger.Error(deleteErr, "Unable to delete orphaned resources")
+			r.GetRecorder().Event(instance, "Warning", quayv1.OrphanedResourcesDeletedReason, deleteErr.Error())
+		}
+	} else if len(orphans) > 0 {
+		r.GetRecorder().Eventf(instance, "Normal", quayv1.OrphanedResourcesReason, "Found %d orphaned resources among %d resources created by the operator, listed in the status", len(orphans), len(resources))
+	}
+
+	logger.Info("Completed orphan cleanup", "Resources", len(resources), "Orphans", len(remaining))
+
+	if err := r.UpdateResourceStatus(ctx, instance, func() error {
+		instance.Status.OrphanedResources = inventory.References(remaining)
+		return nil
+	}); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, r.removeOrphanCleanupAnnotation(ctx, instance)
+}
+
+func (r *QuayIntegrationReconciler) removeOrphanCleanupAnnotation(ctx context.Context, instance *quayv1.QuayIntegration) error {
+	return r.UpdateResource(ctx, instance, func() error {
+		delete(instance.Annotations, constants.OrphanCleanupAnnotation)
+		return nil
+	})
+}
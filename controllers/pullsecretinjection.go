@@ -0,0 +1,170 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileInjectedPullSecrets links the pull secret of the default service account of a namespace to the other service accounts of
+// the namespace labeled with the inject-pull-secret label. The injected-pull-secret annotation records the pull secret linked by the
+// operator, which is unlinked once the label is removed
+func (r *NamespaceIntegrationReconciler) reconcileInjectedPullSecrets(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !quayIntegration.ManagesClusterSecrets() {
+		return reconcile.Result{}, nil
+	}
+
+	serviceAccounts := corev1.ServiceAccountList{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().List(ctx, &serviceAccounts, client.InNamespace(namespace.Name)); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving service accounts",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	// Only basic auth Secrets leave nothing to inject
+	secretName, found, err := utils.GeneratePullSecretName(quayIntegration, namespace.Name)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Failed to generate Secret name for Service Account",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", qotypes.DefaultOpenShiftServiceAccount, "Template", quayIntegration.Spec.SecretNameTemplate},
+			Reason:       "ConfigrurationError",
+			Error:        err,
+		})
+	}
+
+	if !found {
+		secretName = ""
+	}
+
+	for i := range serviceAccounts.Items {
+
+		serviceAccount := &serviceAccounts.Items[i]
+
+		// The pull secrets of the service accounts the robot accounts are created for are linked along with their Secrets
+		if _, builtIn := QuayServiceAccountPermissionMatrix[qotypes.OpenShiftServiceAccount(serviceAccount.Name)]; builtIn {
+			continue
+		}
+
+		desired := ""
+
+		if serviceAccount.Labels[constants.QuayInjectPullSecretLabel] == "true" {
+			desired = secretName
+		}
+
+		injected := serviceAccount.Annotations[constants.QuayInjectedPullSecretAnnotation]
+
+		if desired == injected && (desired == "" || utils.LocalObjectReferenceNameExists(serviceAccount.ImagePullSecrets, desired)) {
+			continue
+		}
+
+		writer, err := r.namespaceWriter(ctx, namespace.Name, quayIntegration)
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to set up impersonation of namespace service account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", r.ImpersonationServiceAccount},
+				Error:        err,
+			})
+		}
+
+		logging.Log.Info("Updating injected pull secret of service account", "Namespace", namespace.Name, "Service Account", serviceAccount.Name, "Secret", desired)
+
+		if err := writer.UpdateResource(ctx, serviceAccount, func() error {
+			setInjectedPullSecret(serviceAccount, desired)
+			return nil
+		}); err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to update injected pull secret of service account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount.Name},
+				Error:        err,
+			})
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// setInjectedPullSecret replaces the pull secret previously injected into a service account with the provided pull secret, removing
+// it when empty
+func setInjectedPullSecret(serviceAccount *corev1.ServiceAccount, secretName string) {
+
+	injected := serviceAccount.Annotations[constants.QuayInjectedPullSecretAnnotation]
+
+	imagePullSecrets := []corev1.LocalObjectReference{}
+
+	for _, imagePullSecret := range serviceAccount.ImagePullSecrets {
+		if imagePullSecret.Name != injected || injected == "" {
+			imagePullSecrets = append(imagePullSecrets, imagePullSecret)
+		}
+	}
+
+	if secretName != "" && !utils.LocalObjectReferenceNameExists(imagePullSecrets, secretName) {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+	}
+
+	serviceAccount.ImagePullSecrets = imagePullSecrets
+
+	if secretName == "" {
+		delete(serviceAccount.Annotations, constants.QuayInjectedPullSecretAnnotation)
+		return
+	}
+
+	if serviceAccount.Annotations == nil {
+		serviceAccount.Annotations = map[string]string{}
+	}
+
+	serviceAccount.Annotations[constants.QuayInjectedPullSecretAnnotation] = secretName
+}
+
+// injectPullSecretLabelChanged filters events of service accounts to the addition or removal of the inject-pull-secret label
+func injectPullSecretLabelChanged() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetLabels()[constants.QuayInjectPullSecretLabel] == "true"
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetLabels()[constants.QuayInjectPullSecretLabel] != e.ObjectNew.GetLabels()[constants.QuayInjectPullSecretLabel]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
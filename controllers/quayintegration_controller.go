@@ -19,22 +19,51 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
-	"github.com/redhat-cop/operator-utils/pkg/util"
+	"github.com/quay/quay-bridge-operator/pkg/bootstrap"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/quayregistry"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/webhook"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// quayRegistryRequeueInterval is how often an unavailable QuayRegistry is checked again
+	quayRegistryRequeueInterval = time.Minute
 )
 
 // QuayIntegrationReconciler reconciles a QuayIntegration object
 type QuayIntegrationReconciler struct {
-	util.ReconcilerBase
-	Log          logr.Logger
-	LastSeenSpec map[types.NamespacedName]string
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	LastSeenSpec   map[types.NamespacedName]string
+	HTTPClientPool *qclient.HTTPClientPool
+
+	// Capabilities records the features detected on Quay for the other controllers
+	Capabilities *capabilities.Store
+	// RequiredQuayFeatures maps the Quay features required by the enabled operator features to the flag enabling them
+	RequiredQuayFeatures map[string]string
+	// BuildRepositoryCreation determines whether the webhook creates the repositories of Builds
+	BuildRepositoryCreation webhook.RepositoryCreationMode
 }
 
 //+kubebuilder:rbac:groups=quay.redhat.com,resources=quayintegrations,verbs=get;list;watch;create;update;patch;delete
@@ -49,6 +78,7 @@ func (r *QuayIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	if err != nil {
 		if apierrors.IsNotFound(err) {
+			r.Capabilities.Delete(req.Name)
 			return reconcile.Result{}, nil
 		}
 
@@ -56,37 +86,496 @@ func (r *QuayIntegrationReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return reconcile.Result{}, err
 	}
 
+	if reconcilerbase.IsBeingDeleted(instance) {
+		return r.finalizeOAuthApplication(ctx, instance)
+	}
+
+	// Orphaned resources are reported or deleted on request, regardless of changes to the spec
+	if mode, requested := instance.Annotations[constants.OrphanCleanupAnnotation]; requested {
+		return r.cleanupOrphans(ctx, instance, mode)
+	}
+
 	specBytes, _ := json.Marshal(instance.Spec)
-	if r.LastSeenSpec[req.NamespacedName] == string(specBytes) {
+	// QuayRegistry status changes and the phases of the build cutover window are not reflected in the spec and are always reconciled,
+	// as are the rotations of the OAuth application once due
+	rotation := instance.GetOAuthApplicationRotation()
+	if r.LastSeenSpec[req.NamespacedName] == string(specBytes) && instance.Spec.QuayRegistryRef == nil && instance.Spec.BuildCutover == nil && (rotation.IsZero() || time.Now().Before(rotation)) {
 		logger.Info("No changes to QuayIntegration spec, skipping reconciliation")
 		return reconcile.Result{Requeue: false}, nil
 	}
 
-	instance, err = instance.SetStatus(&quayv1.QuayIntegrationStatus{})
-	if err != nil {
-		return reconcile.Result{Requeue: true}, err
+	if instance.IsTLSVerificationDisabled() {
+		logger.Info("WARNING: TLS verification against Quay is disabled. This configuration must not be used in production", "QuayHostname", instance.Spec.QuayHostname)
+		r.GetRecorder().Event(instance, "Warning", quayv1.InsecureTLSReason, "TLS verification against Quay is disabled")
 	}
-	instance, err = instance.SetStatus(&quayv1.QuayIntegrationStatus{})
-	if err != nil {
-		return reconcile.Result{Requeue: true}, err
+
+	// The first user of Quay is initialized before capabilities are detected using its token
+	bootstrapReason, bootstrapMessage := "", ""
+
+	if instance.Spec.Bootstrap != nil {
+
+		var bootstrapErr error
+		bootstrapReason, bootstrapMessage, bootstrapErr = bootstrap.Bootstrap(ctx, r.GetClient(), r.GetAPIReader(), r.HTTPClientPool, instance.DeepCopy())
+
+		if bootstrapErr != nil {
+			logger.Error(bootstrapErr, "Failed to bootstrap Quay")
+			bootstrapMessage = fmt.Sprintf("Failed to initialize the first user of Quay: %v", bootstrapErr)
+		} else if bootstrapReason == quayv1.BootstrappedReason {
+			logger.Info("Bootstrapped Quay", "Username", instance.GetBootstrapUsername())
+			r.GetRecorder().Event(instance, "Normal", quayv1.BootstrappedReason, bootstrapMessage)
+		}
+	}
+
+	// The OAuth application is managed using the credentials of the first user, and its credentials are used from then on
+	oauthApplicationStatus, oauthApplicationErr := r.reconcileOAuthApplication(ctx, instance, time.Now())
+
+	if oauthApplicationErr != nil {
+		logger.Error(oauthApplicationErr, "Failed to manage the OAuth application")
+	}
+
+	// Capabilities are detected when the operator starts and whenever the spec changes
+	quayVersion, versionErr := r.resolveQuayVersion(ctx, instance)
+	quayCapabilities, detected := r.detectCapabilities(ctx, instance, quayVersion)
+
+	result := reconcile.Result{Requeue: false}
+
+	// The status is recomputed on the latest revision when the update conflicts with the health reconciler
+	err = r.UpdateResourceStatus(ctx, instance, func() error {
+
+		result = reconcile.Result{Requeue: false}
+
+		if _, err := instance.SetStatus(&quayv1.QuayIntegrationStatus{}); err != nil {
+			return err
+		}
+
+		if instance.IsTLSVerificationDisabled() {
+			meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+				Type:               quayv1.InsecureTLSConditionType,
+				Status:             metav1.ConditionTrue,
+				Reason:             quayv1.InsecureTLSReason,
+				Message:            "TLS verification against Quay is disabled. This configuration must not be used in production",
+				ObservedGeneration: instance.GetGeneration(),
+			})
+		} else {
+			meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.InsecureTLSConditionType)
+		}
+
+		if instance.Spec.QuayRegistryRef != nil {
+			if !r.updateQuayRegistryStatus(ctx, instance) {
+				// QuayRegistry may not be installed yet, in which case no watch exists
+				result.RequeueAfter = quayRegistryRequeueInterval
+			}
+		} else {
+			instance.Status.QuayHostname = instance.GetQuayHostname()
+			meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.QuayRegistryAvailableConditionType)
+		}
+
+		r.updateBootstrapStatus(instance, bootstrapReason, bootstrapMessage)
+		r.updateOAuthApplicationStatus(instance, oauthApplicationStatus, oauthApplicationErr)
+		r.updateQuayVersionStatus(instance, quayVersion, versionErr)
+		r.updateQuayFeaturesStatus(instance, quayCapabilities)
+		r.updateBuildRepositoriesStatus(instance, quayCapabilities)
+
+		if transition, ok := r.updateBuildCutoverStatus(instance, time.Now()); ok && (result.RequeueAfter == 0 || transition < result.RequeueAfter) {
+			result.RequeueAfter = transition
+		}
+
+		if rotation := instance.GetOAuthApplicationRotation(); !rotation.IsZero() && (result.RequeueAfter == 0 || time.Until(rotation) < result.RequeueAfter) {
+			result.RequeueAfter = time.Until(rotation)
+		}
+
+		return nil
+	})
+
+	if apierrors.IsConflict(err) {
+		logger.Info("QuayIntegration status update conflicted with another update, retrying")
+		return reconcile.Result{Requeue: true}, nil
 	}
-	err = r.GetClient().Status().Update(ctx, instance)
+
 	if err != nil {
 		logger.Error(err, "Failed to update QuayIntegration status")
 		return reconcile.Result{Requeue: true}, err
 	}
 	logger.Info("Updated QuayIntegration status")
 
+	if condition := meta.FindStatusCondition(instance.Status.Conditions, quayv1.QuayVersionSupportedConditionType); condition != nil && condition.Status == metav1.ConditionFalse {
+		r.GetRecorder().Event(instance, "Warning", quayv1.QuayVersionUnsupportedReason, condition.Message)
+	}
+
+	if condition := meta.FindStatusCondition(instance.Status.Conditions, quayv1.QuayFeaturesSupportedConditionType); condition != nil && condition.Status == metav1.ConditionFalse {
+		r.GetRecorder().Event(instance, "Warning", quayv1.QuayFeaturesUnsupportedReason, condition.Message)
+	}
+
+	if condition := meta.FindStatusCondition(instance.Status.Conditions, quayv1.BuildRepositoriesPrivateConditionType); condition != nil && condition.Status == metav1.ConditionFalse {
+		r.GetRecorder().Event(instance, "Warning", quayv1.PublicOnPushReason, condition.Message)
+	}
+
+	// Detection and the management of the OAuth application are retried until they succeed
+	if !detected || oauthApplicationErr != nil {
+		if result.RequeueAfter == 0 {
+			result.RequeueAfter = quayRegistryRequeueInterval
+		}
+
+		return result, nil
+	}
+
 	specBytes, _ = json.Marshal(instance.Spec)
 	r.LastSeenSpec[req.NamespacedName] = string(specBytes)
 
-	return reconcile.Result{Requeue: false}, nil
+	return result, nil
+
+}
+
+// updateQuayRegistryStatus records the hostname and availability discovered from the referenced QuayRegistry. Returns whether Quay is available
+func (r *QuayIntegrationReconciler) updateQuayRegistryStatus(ctx context.Context, instance *quayv1.QuayIntegration) bool {
+
+	condition := metav1.Condition{
+		Type:               quayv1.QuayRegistryAvailableConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	registryInfo, err := quayregistry.Discover(ctx, r.GetClient(), instance.Spec.QuayRegistryRef)
+
+	if err != nil {
+		condition.Reason = "QuayRegistryNotFound"
+		condition.Message = err.Error()
+	} else {
+		instance.Status.QuayHostname = registryInfo.Endpoint
+		condition.Message = registryInfo.Message
+
+		if registryInfo.Available {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "QuayRegistryAvailable"
+		} else {
+			condition.Reason = "QuayRegistryUnavailable"
+		}
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+
+	return condition.Status == metav1.ConditionTrue
+}
+
+// resolveQuayVersion returns the version of Quay configured on the QuayIntegration or reported by the referenced QuayRegistry, or
+// nil when unknown
+func (r *QuayIntegrationReconciler) resolveQuayVersion(ctx context.Context, instance *quayv1.QuayIntegration) (*capabilities.Version, error) {
+
+	version := instance.Spec.QuayVersion
+
+	if version == "" && instance.Spec.QuayRegistryRef != nil {
+
+		registryInfo, err := quayregistry.Discover(ctx, r.GetClient(), instance.Spec.QuayRegistryRef)
+
+		if err != nil {
+			return nil, err
+		}
+
+		version = registryInfo.Version
+	}
+
+	if version == "" {
+		return nil, nil
+	}
+
+	quayVersion, err := capabilities.ParseVersion(version)
+
+	if err != nil {
+		return nil, err
+	}
 
+	return &quayVersion, nil
+}
+
+// detectCapabilities retrieves the features enabled on Quay and records them along with the version of Quay for the other
+// controllers. The capabilities previously detected are returned along with false when Quay cannot be reached
+func (r *QuayIntegrationReconciler) detectCapabilities(ctx context.Context, instance *quayv1.QuayIntegration, quayVersion *capabilities.Version) (capabilities.Capabilities, bool) {
+
+	// The version is known without reaching Quay
+	previous := r.Capabilities.Get(instance.Name)
+	previous.Version = quayVersion
+
+	quayClient, err := state.NewQuayClient(ctx, r.GetClient(), instance.DeepCopy(), r.HTTPClientPool)
+
+	if err != nil {
+		r.Log.Error(err, "Unable to create Quay client to detect the features enabled on Quay", "QuayIntegration", instance.Name)
+		r.Capabilities.Set(instance.Name, previous)
+		return previous, false
+	}
+
+	quayCapabilities, err := capabilities.Detect(quayClient)
+
+	if err != nil {
+		r.Log.Error(err, "Unable to detect the features enabled on Quay", "QuayIntegration", instance.Name)
+		r.Capabilities.Set(instance.Name, previous)
+		return previous, false
+	}
+
+	quayCapabilities.Version = quayVersion
+	r.Capabilities.Set(instance.Name, quayCapabilities)
+
+	return quayCapabilities, true
+}
+
+// updateQuayVersionStatus records the version of Quay and whether it is supported by the operator
+func (r *QuayIntegrationReconciler) updateQuayVersionStatus(instance *quayv1.QuayIntegration, quayVersion *capabilities.Version, versionErr error) {
+
+	instance.Status.QuayVersion = ""
+
+	condition := metav1.Condition{
+		Type:               quayv1.QuayVersionSupportedConditionType,
+		Status:             metav1.ConditionUnknown,
+		Reason:             quayv1.QuayVersionUnknownReason,
+		Message:            "The version of Quay is unknown. Set quayVersion to check it against the versions supported by the operator",
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	switch {
+	case versionErr != nil:
+		condition.Message = fmt.Sprintf("Unable to determine the version of Quay: %v", versionErr)
+	case quayVersion == nil:
+	case !quayVersion.IsSupported():
+		instance.Status.QuayVersion = quayVersion.String()
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = quayv1.QuayVersionUnsupportedReason
+		condition.Message = fmt.Sprintf("Quay %s is not supported by the operator, which supports Quay %s", quayVersion, capabilities.SupportedRange())
+
+		if instance.GetQuayVersionPolicy() == quayv1.EnforceQuayVersionPolicy {
+			condition.Message += ". Namespaces are not synchronized"
+		}
+	default:
+		instance.Status.QuayVersion = quayVersion.String()
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = quayv1.QuayVersionSupportedReason
+		condition.Message = fmt.Sprintf("Quay %s is supported by the operator", quayVersion)
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+}
+
+// updateQuayFeaturesStatus records the features enabled on Quay and whether the features required by the operator are enabled.
+// Quay instances not publishing their features are assumed to support every feature introduced before their version
+func (r *QuayIntegrationReconciler) updateQuayFeaturesStatus(instance *quayv1.QuayIntegration, quayCapabilities capabilities.Capabilities) {
+
+	instance.Status.QuayFeatures = nil
+
+	if quayCapabilities.Detected {
+		instance.Status.QuayFeatures = quayCapabilities.Enabled()
+	} else if quayCapabilities.Version == nil {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.QuayFeaturesSupportedConditionType)
+		return
+	}
+
+	required := map[string]string{}
+
+	for feature, requiredBy := range r.RequiredQuayFeatures {
+		required[feature] = requiredBy
+	}
+
+	if instance.Spec.ClusterIDMigration != nil {
+		required[capabilities.RepositoryMirroring] = "clusterIDMigration"
+	}
+
+	if instance.IsRepositoryTrustEnabled() {
+		required[capabilities.Signing] = "imageSigning.repositoryTrust"
+	}
+
+	features := []string{}
+
+	for feature := range required {
+		features = append(features, feature)
+	}
+
+	sort.Strings(features)
+
+	condition := metav1.Condition{
+		Type:               quayv1.QuayFeaturesSupportedConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             quayv1.QuayFeaturesSupportedReason,
+		Message:            "The Quay features required by the operator are enabled",
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	if unsupported := quayCapabilities.Unsupported(features); len(unsupported) > 0 {
+
+		descriptions := []string{}
+
+		for _, feature := range unsupported {
+
+			description := fmt.Sprintf("%s required by %s", feature, required[feature])
+
+			if minimum, newer := quayCapabilities.RequiresNewerVersion(feature); newer {
+				description += fmt.Sprintf(" (introduced in Quay %d.%d)", minimum.Major, minimum.Minor)
+			}
+
+			descriptions = append(descriptions, description)
+		}
+
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = quayv1.QuayFeaturesUnsupportedReason
+		condition.Message = fmt.Sprintf("Features not enabled on Quay: %s", strings.Join(descriptions, ", "))
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+}
+
+// updateBuildRepositoriesStatus reports whether the repositories first pushed to by Builds are private, either because Quay
+// creates private repositories on push or because the webhook creates them beforehand
+func (r *QuayIntegrationReconciler) updateBuildRepositoriesStatus(instance *quayv1.QuayIntegration, quayCapabilities capabilities.Capabilities) {
+
+	if !quayCapabilities.Detected {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.BuildRepositoriesPrivateConditionType)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               quayv1.BuildRepositoriesPrivateConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             quayv1.PrivateOnPushReason,
+		Message:            "Quay creates the repositories pushed to by Builds as private repositories",
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	if quayCapabilities.PublicRepositoriesOnPush {
+		if r.BuildRepositoryCreation == webhook.AutoRepositoryCreation || r.BuildRepositoryCreation == webhook.AlwaysRepositoryCreation {
+			condition.Reason = quayv1.CreatedByWebhookReason
+			condition.Message = "Quay creates repositories pushed to as public repositories. The webhook creates the repositories of Builds as private repositories when they are admitted"
+		} else {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = quayv1.PublicOnPushReason
+			condition.Message = fmt.Sprintf("Quay creates repositories pushed to as public repositories (%s is false) and the webhook does not create the repositories of Builds (--build-repository-creation is %s). Repositories without an ImageStream or BuildConfig are public once pushed to",
+				capabilities.CreatePrivateRepoOnPush, webhook.NeverRepositoryCreation)
+		}
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+}
+
+// updateBootstrapStatus records the outcome of the initialization of the first user of Quay. Once bootstrapped, the token stored
+// in the credentials Secret keeps the condition Bootstrapped
+func (r *QuayIntegrationReconciler) updateBootstrapStatus(instance *quayv1.QuayIntegration, reason string, message string) {
+
+	if instance.Spec.Bootstrap == nil {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.BootstrappedConditionType)
+		return
+	}
+
+	if existing := meta.FindStatusCondition(instance.Status.Conditions, quayv1.BootstrappedConditionType); existing != nil && existing.Reason == quayv1.BootstrappedReason && reason == quayv1.CredentialsProvidedReason {
+		existing.ObservedGeneration = instance.GetGeneration()
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               quayv1.BootstrappedConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	if reason == quayv1.BootstrappedReason || reason == quayv1.CredentialsProvidedReason {
+		condition.Status = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+}
+
+// updateOAuthApplicationStatus records the OAuth application managed by the operator
+func (r *QuayIntegrationReconciler) updateOAuthApplicationStatus(instance *quayv1.QuayIntegration, oauthApplicationStatus *quayv1.OAuthApplicationStatus, err error) {
+
+	instance.Status.OAuthApplication = oauthApplicationStatus
+
+	if instance.Spec.OAuthApplication == nil {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.OAuthApplicationReadyConditionType)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               quayv1.OAuthApplicationReadyConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             quayv1.OAuthApplicationReadyReason,
+		Message:            fmt.Sprintf("The credentials Secret contains the client credentials of OAuth application %s", instance.GetOAuthApplicationName()),
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = quayv1.OAuthApplicationFailedReason
+		condition.Message = fmt.Sprintf("Failed to manage OAuth application %s: %v", instance.GetOAuthApplicationName(), err)
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+}
+
+// updateBuildCutoverStatus records the phase of the build cutover window. Returns the time until the phase changes, false once the window has ended
+func (r *QuayIntegrationReconciler) updateBuildCutoverStatus(instance *quayv1.QuayIntegration, now time.Time) (time.Duration, bool) {
+
+	phase := instance.GetBuildCutoverPhase(now)
+
+	if phase == "" {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, quayv1.BuildCutoverConditionType)
+		return 0, false
+	}
+
+	condition := metav1.Condition{
+		Type:               quayv1.BuildCutoverConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             string(phase),
+		ObservedGeneration: instance.GetGeneration(),
+	}
+
+	switch phase {
+	case quayv1.PendingBuildCutoverPhase:
+		condition.Message = fmt.Sprintf("The build cutover window starts at %s. ImageStreamTags refer to the images pushed to Quay", instance.Spec.BuildCutover.Start.UTC().Format(time.RFC3339))
+	case quayv1.ActiveBuildCutoverPhase:
+		condition.Status = metav1.ConditionTrue
+		condition.Message = fmt.Sprintf("ImageStreamTags of Builds are served by the internal registry until %s", instance.Spec.BuildCutover.End.UTC().Format(time.RFC3339))
+	case quayv1.CompleteBuildCutoverPhase:
+		condition.Message = fmt.Sprintf("The build cutover window ended at %s. ImageStreamTags of Builds are no longer updated", instance.Spec.BuildCutover.End.UTC().Format(time.RFC3339))
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+
+	transition, ok := instance.GetBuildCutoverTransition(now)
+
+	if !ok {
+		return 0, false
+	}
+
+	// The phase is reported shortly after it changes
+	return transition.Sub(now) + time.Second, true
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *QuayIntegrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&quayv1.QuayIntegration{}).
-		Complete(r)
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&quayv1.QuayIntegration{})
+
+	// Watch QuayRegistries only when the Quay Operator is installed
+	if available, err := r.IsAPIResourceAvailable(quayregistry.QuayRegistryGVK); err == nil && available {
+
+		quayRegistryToQuayIntegrations := handler.MapFunc(
+			func(a client.Object) []reconcile.Request {
+				res := []reconcile.Request{}
+
+				quayIntegrations := quayv1.QuayIntegrationList{}
+
+				if err := mgr.GetClient().List(context.TODO(), &quayIntegrations); err != nil {
+					return res
+				}
+
+				for _, quayIntegration := range quayIntegrations.Items {
+					if ref := quayIntegration.Spec.QuayRegistryRef; ref != nil && ref.Name == a.GetName() && ref.Namespace == a.GetNamespace() {
+						res = append(res, reconcile.Request{NamespacedName: types.NamespacedName{Name: quayIntegration.Name}})
+					}
+				}
+
+				return res
+			})
+
+		controllerBuilder = controllerBuilder.Watches(&source.Kind{Type: quayregistry.NewQuayRegistry()}, handler.EnqueueRequestsFromMapFunc(quayRegistryToQuayIntegrations))
+	}
+
+	return controllerBuilder.Complete(r)
 }
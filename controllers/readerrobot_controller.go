@@ -0,0 +1,245 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/credentials"
+	"github.com/quay/quay-bridge-operator/pkg/pullsecret"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// readerRobotErrorReason is the reason of events recorded when the reader robot pull secrets of a QuayIntegration cannot be generated
+	readerRobotErrorReason = "ReaderRobotError"
+)
+
+// ReaderRobotReconciler distributes the credentials of the reader robot account of every organization managed by a
+// QuayIntegration as a single pull secret to the designated namespaces. Quay scopes robot accounts to their organization,
+// so the pull secret contains an auth for each organization. Pull secrets are tracked in an inventory and pruned once no longer desired
+type ReaderRobotReconciler struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=delete
+
+func (r *ReaderRobotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("quayintegration", req.NamespacedName)
+
+	instance := &quayv1.QuayIntegration{}
+	err := r.GetClient().Get(ctx, req.NamespacedName, instance)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Pull secrets are owned by the QuayIntegration and garbage collected
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	operatorNamespace, err := r.GetOperatorNamespace()
+
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	objs := []unstructured.Unstructured{}
+	pending := 0
+
+	if instance.Spec.ReaderRobot != nil {
+
+		objs, pending, err = r.generatePullSecrets(ctx, instance)
+
+		if err != nil {
+			r.GetRecorder().Event(instance, "Warning", readerRobotErrorReason, err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
+	results, err := r.ReconcileResources(ctx, instance, operatorNamespace, fmt.Sprintf("quay-bridge-operator-%s-reader-robot", instance.Name), objs)
+
+	if err != nil {
+		if failed := reconcilerbase.FailedResults(results); len(failed) > 0 {
+			return r.ManageError(ctx, instance, fmt.Errorf("unable to reconcile %d of %d reader robot pull secrets: %v", len(failed), len(results), err))
+		}
+
+		return r.ManageError(ctx, instance, err)
+	}
+
+	logger.Info("Reconciled reader robot pull secrets", "Count", len(objs), "Pending Organizations", pending)
+
+	// Reader robot accounts are created by the namespace controller once the organization has been set up
+	if pending > 0 {
+		return reconcile.Result{RequeueAfter: constants.RequeuePeriod}, nil
+	}
+
+	if meta.IsStatusConditionFalse(instance.Status.Conditions, reconcilerbase.ReconcileSuccessConditionType) {
+		return r.ManageSuccess(ctx, instance)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// generatePullSecrets returns the pull secrets of the designated namespaces along with the number of synchronized
+// organizations whose reader robot account does not exist yet
+func (r *ReaderRobotReconciler) generatePullSecrets(ctx context.Context, instance *quayv1.QuayIntegration) ([]unstructured.Unstructured, int, error) {
+
+	quayClient, err := state.NewQuayClient(ctx, r.GetClient(), instance.DeepCopy(), r.HTTPClientPool)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	registryHostname, err := instance.GetRegistryHostname()
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	namespaces := corev1.NamespaceList{}
+
+	if err := r.GetClient().List(ctx, &namespaces); err != nil {
+		return nil, 0, err
+	}
+
+	syncScope, err := syncpolicy.LoadScope(ctx, r.GetClient())
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	robotName := instance.GetReaderRobotName()
+	registryCredentials := []credentials.RegistryCredential{}
+	existingNamespaces := map[string]bool{}
+	pending := 0
+
+	for _, namespace := range namespaces.Items {
+
+		existingNamespaces[namespace.Name] = namespace.DeletionTimestamp == nil
+
+		if namespace.DeletionTimestamp != nil || !reconcilerbase.HasFinalizer(&namespace, constants.NamespaceFinalizer) ||
+			!syncScope.IsSynchronized(instance, &namespace) || !cachescope.InNamespaces(r.Namespaces, namespace.Name) {
+			continue
+		}
+
+		quayOrganizationName := instance.GenerateQuayOrganizationNameFromNamespace(namespace.Name)
+
+		robotAccount, robotAccountResponse, robotAccountError := quayClient.GetOrganizationRobotAccount(quayOrganizationName, robotName)
+
+		if robotAccountError.Error != nil {
+			return nil, 0, robotAccountError.Error
+		}
+
+		if robotAccountResponse.StatusCode == 400 || robotAccountResponse.StatusCode == 404 {
+			pending++
+			continue
+		}
+
+		if robotAccountResponse.StatusCode != 200 {
+			return nil, 0, fmt.Errorf("unable to retrieve robot account %s of organization %s: status code %d", robotName, quayOrganizationName, robotAccountResponse.StatusCode)
+		}
+
+		registryCredentials = append(registryCredentials, credentials.RegistryCredential{
+			Server:   pullsecret.GenerateAuthKey(registryHostname, quayOrganizationName),
+			Username: robotAccount.Name,
+			Password: robotAccount.Token,
+		})
+	}
+
+	objs := []unstructured.Unstructured{}
+
+	for _, namespace := range instance.Spec.ReaderRobot.Namespaces {
+
+		if !existingNamespaces[namespace] || !cachescope.InNamespaces(r.Namespaces, namespace) {
+			continue
+		}
+
+		secret, err := credentials.GenerateCombinedDockerJsonSecret(instance.GetReaderRobotSecretName(), registryCredentials)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		secret.Namespace = namespace
+		instance.ApplyResourceMetadata(secret)
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(secret)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		objs = append(objs, unstructured.Unstructured{Object: content})
+	}
+
+	return objs, pending, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReaderRobotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+
+	namespaceToQuayIntegrations := handler.MapFunc(
+		func(a client.Object) []reconcile.Request {
+			res := []reconcile.Request{}
+
+			quayIntegrations := quayv1.QuayIntegrationList{}
+
+			if err := mgr.GetClient().List(context.TODO(), &quayIntegrations); err != nil {
+				return res
+			}
+
+			for _, quayIntegration := range quayIntegrations.Items {
+				if quayIntegration.Spec.ReaderRobot != nil {
+					res = append(res, reconcile.Request{NamespacedName: types.NamespacedName{Name: quayIntegration.Name}})
+				}
+			}
+
+			return res
+		})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("readerrobot").
+		For(&quayv1.QuayIntegration{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(namespaceToQuayIntegrations), builder.WithPredicates(cachescope.NamespacePredicate(r.Namespaces))).
+		Complete(r)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/pullspec"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// registryHostnameMigrationPollInterval is the delay before the namespaces which could not be migrated are retried
+	registryHostnameMigrationPollInterval = time.Minute
+	// registryHostnameMigrationReason is the reason of events recorded as a registry hostname migration progresses
+	registryHostnameMigrationReason = "RegistryHostnameMigration"
+	// maxRegistryHostnameMigrationFailures is the number of failures reported in the status of a migration
+	maxRegistryHostnameMigrationFailures = 10
+)
+
+// RegistryHostnameMigrationReconciler rewrites the image references of the resources in managed namespaces when the registry
+// hostname of a QuayIntegration changes, such as when the Quay route moves to a new domain. BuildConfigs and ImageStreams
+// referring to a previous hostname are updated in place, while namespaces are annotated with the new hostname so that their
+// robot account Secrets are regenerated
+type RegistryHostnameMigrationReconciler struct {
+	reconcilerbase.ReconcilerBase
+	Log        logr.Logger
+	Namespaces []string
+}
+
+func (r *RegistryHostnameMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("quayintegration", req.NamespacedName)
+
+	instance := &quayv1.QuayIntegration{}
+	err := r.GetClient().Get(ctx, req.NamespacedName, instance)
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	registryHostname, err := instance.GetRegistryHostname()
+
+	if err != nil {
+		return r.ManageError(ctx, instance, err)
+	}
+
+	// The hostname in use is recorded the first time the QuayIntegration is observed
+	if instance.Status.RegistryHostname == "" {
+		return reconcile.Result{}, r.UpdateResourceStatus(ctx, instance, func() error {
+			instance.Status.RegistryHostname = registryHostname
+			return nil
+		})
+	}
+
+	migrationStatus := instance.Status.RegistryHostnameMigration.DeepCopy()
+
+	if instance.Status.RegistryHostname == registryHostname && (migrationStatus == nil || migrationStatus.Phase == quayv1.RegistryHostnameMigrationCompleted) {
+		return reconcile.Result{}, nil
+	}
+
+	if migrationStatus == nil || migrationStatus.Hostname != registryHostname || migrationStatus.Phase == quayv1.RegistryHostnameMigrationCompleted {
+
+		previousHostnames := []string{}
+		seen := map[string]bool{registryHostname: true}
+
+		// Resources may still refer to the target of a superseded migration
+		candidates := []string{instance.Status.RegistryHostname}
+
+		if migrationStatus != nil && migrationStatus.Phase == quayv1.RegistryHostnameMigrationInProgress {
+			candidates = append(candidates, migrationStatus.PreviousHostnames...)
+			candidates = append(candidates, migrationStatus.Hostname)
+		}
+
+		for _, candidate := range candidates {
+			if !seen[candidate] {
+				previousHostnames = append(previousHostnames, candidate)
+				seen[candidate] = true
+			}
+		}
+
+		now := metav1.Now()
+
+		migrationStatus = &quayv1.RegistryHostnameMigrationStatus{
+			PreviousHostnames: previousHostnames,
+			Hostname:          registryHostname,
+			Phase:             quayv1.RegistryHostnameMigrationInProgress,
+			StartTime:         &now,
+		}
+
+		logger.Info("Starting registry hostname migration", "Previous Hostnames", previousHostnames, "Hostname", registryHostname)
+		r.GetRecorder().Event(instance, "Normal", registryHostnameMigrationReason, fmt.Sprintf("Migrating image references from %s to %s", strings.Join(previousHostnames, ", "), registryHostname))
+	}
+
+	if err := r.migrateNamespaces(ctx, instance, migrationStatus); err != nil {
+		return r.ManageError(ctx, instance, err)
+	}
+
+	if len(migrationStatus.Failures) == 0 {
+		now := metav1.Now()
+		migrationStatus.Phase = quayv1.RegistryHostnameMigrationCompleted
+		migrationStatus.CompletionTime = &now
+	}
+
+	if err := r.UpdateResourceStatus(ctx, instance, func() error {
+		instance.Status.RegistryHostnameMigration = migrationStatus.DeepCopy()
+
+		if migrationStatus.Phase == quayv1.RegistryHostnameMigrationCompleted {
+			instance.Status.RegistryHostname = migrationStatus.Hostname
+		}
+
+		return nil
+	}); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if migrationStatus.Phase != quayv1.RegistryHostnameMigrationCompleted {
+		logger.Info("Registry hostname migration incomplete", "Failures", len(migrationStatus.Failures))
+		return reconcile.Result{RequeueAfter: registryHostnameMigrationPollInterval}, nil
+	}
+
+	logger.Info("Registry hostname migration completed", "Namespaces", migrationStatus.MigratedNamespaces, "BuildConfigs", migrationStatus.UpdatedBuildConfigs, "ImageStreams", migrationStatus.UpdatedImageStreams)
+	r.GetRecorder().Event(instance, "Normal", registryHostnameMigrationReason, fmt.Sprintf("Migrated image references to %s in %d namespaces, updating %d BuildConfigs and %d ImageStreams", migrationStatus.Hostname, migrationStatus.MigratedNamespaces, migrationStatus.UpdatedBuildConfigs, migrationStatus.UpdatedImageStreams))
+
+	return reconcile.Result{}, nil
+}
+
+// migrateNamespaces rewrites the resources of each managed namespace, recording the namespaces which could not be migrated
+func (r *RegistryHostnameMigrationReconciler) migrateNamespaces(ctx context.Context, instance *quayv1.QuayIntegration, migrationStatus *quayv1.RegistryHostnameMigrationStatus) error {
+
+	namespaces, err := state.ManagedNamespaces(ctx, r.GetClient(), instance)
+
+	if err != nil {
+		return err
+	}
+
+	migrationStatus.MigratedNamespaces = 0
+	migrationStatus.Failures = nil
+	failed := 0
+
+	for _, namespace := range namespaces {
+
+		if !cachescope.InNamespaces(r.Namespaces, namespace) {
+			continue
+		}
+
+		if err := r.migrateNamespace(ctx, namespace, migrationStatus); err != nil {
+			r.Log.Error(err, "Failed to migrate namespace to new registry hostname", "Namespace", namespace)
+
+			failed++
+
+			if len(migrationStatus.Failures) < maxRegistryHostnameMigrationFailures {
+				migrationStatus.Failures = append(migrationStatus.Failures, fmt.Sprintf("%s: %v", namespace, err))
+			}
+
+			continue
+		}
+
+		migrationStatus.MigratedNamespaces++
+	}
+
+	if failed > len(migrationStatus.Failures) {
+		migrationStatus.Failures = append(migrationStatus.Failures, fmt.Sprintf("%d more namespaces could not be migrated", failed-len(migrationStatus.Failures)))
+	}
+
+	return nil
+}
+
+// migrateNamespace rewrites the BuildConfigs and ImageStreams of a namespace and annotates the namespace with the new hostname
+func (r *RegistryHostnameMigrationReconciler) migrateNamespace(ctx context.Context, namespaceName string, migrationStatus *quayv1.RegistryHostnameMigrationStatus) error {
+
+	buildConfigs := &buildv1.BuildConfigList{}
+
+	if err := r.GetClient().List(ctx, buildConfigs, client.InNamespace(namespaceName)); err != nil {
+		return fmt.Errorf("error listing BuildConfigs: %w", err)
+	}
+
+	for i := range buildConfigs.Items {
+
+		buildConfig := &buildConfigs.Items[i]
+
+		if !pullspec.RewriteBuildConfig(buildConfig.DeepCopy(), migrationStatus.PreviousHostnames, migrationStatus.Hostname) {
+			continue
+		}
+
+		if err := r.UpdateResource(ctx, buildConfig, func() error {
+			pullspec.RewriteBuildConfig(buildConfig, migrationStatus.PreviousHostnames, migrationStatus.Hostname)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("error updating BuildConfig '%s': %w", buildConfig.Name, err)
+		}
+
+		migrationStatus.UpdatedBuildConfigs++
+	}
+
+	imageStreams := &imagev1.ImageStreamList{}
+
+	if err := r.GetClient().List(ctx, imageStreams, client.InNamespace(namespaceName)); err != nil {
+		return fmt.Errorf("error listing ImageStreams: %w", err)
+	}
+
+	for i := range imageStreams.Items {
+
+		imageStream := &imageStreams.Items[i]
+
+		if !pullspec.RewriteImageStream(imageStream.DeepCopy(), migrationStatus.PreviousHostnames, migrationStatus.Hostname) {
+			continue
+		}
+
+		if err := r.UpdateResource(ctx, imageStream, func() error {
+			pullspec.RewriteImageStream(imageStream, migrationStatus.PreviousHostnames, migrationStatus.Hostname)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("error updating ImageStream '%s': %w", imageStream.Name, err)
+		}
+
+		migrationStatus.UpdatedImageStreams++
+	}
+
+	namespace := &corev1.Namespace{}
+
+	if err := r.GetClient().Get(ctx, types.NamespacedName{Name: namespaceName}, namespace); err != nil {
+		return fmt.Errorf("error retrieving namespace: %w", err)
+	}
+
+	if namespace.Annotations[constants.RegistryHostnameAnnotation] == migrationStatus.Hostname {
+		return nil
+	}
+
+	// Updating the namespace queues its synchronization, regenerating the robot account Secrets with the new hostname
+	if err := r.UpdateResource(ctx, namespace, func() error {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[constants.RegistryHostnameAnnotation] = migrationStatus.Hostname
+		return nil
+	}); err != nil {
+		return fmt.Errorf("error annotating namespace: %w", err)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RegistryHostnameMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("registryhostnamemigration").
+		For(&quayv1.QuayIntegration{}).
+		Complete(r)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/usage"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RepositoryStatsReporter periodically retrieves the pulls and pushes of each repository of the organization of managed
+// namespaces from the aggregated logs of Quay, recording them as metrics to find unused images. Requests against Quay are
+// rate limited as aggregating logs is expensive for Quay
+type RepositoryStatsReporter struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+	Capabilities   *capabilities.Store
+
+	// Interval between reports
+	Interval time.Duration
+	// Window of logs covered by the statistics
+	Window time.Duration
+	// RateLimiter throttles the requests made against Quay
+	RateLimiter flowcontrol.RateLimiter
+}
+
+// Start implements manager.Runnable
+func (r *RepositoryStatsReporter) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.report(ctx); err != nil {
+			r.Log.Error(err, "Failed to report repository statistics")
+		}
+	}, r.Interval)
+
+	return nil
+}
+
+func (r *RepositoryStatsReporter) report(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := r.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	reported := map[string]bool{}
+
+	for _, quayIntegration := range quayIntegrations.Items {
+
+		// Statistics rely on the aggregated logs of Quay, which may be disabled
+		if !r.Capabilities.Get(quayIntegration.Name).Supports(capabilities.AggregatedLogCount) {
+			continue
+		}
+
+		namespaces, err := state.ManagedOrganizationNamespaces(ctx, r.GetClient(), &quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		quayClient, err := state.NewQuayClient(ctx, r.GetClient(), quayIntegration.DeepCopy(), r.HTTPClientPool)
+
+		if err != nil {
+			r.Log.Error(err, "Unable to create Quay client", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		for _, namespace := range namespaces {
+
+			if !cachescope.InNamespaces(r.Namespaces, namespace) {
+				continue
+			}
+
+			organizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+
+			if err := r.reportNamespace(ctx, quayClient, namespace, organizationName); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				r.Log.Error(err, "Unable to retrieve repository statistics of organization", "Namespace", namespace, "Organization", organizationName)
+				continue
+			}
+
+			reported[namespace] = true
+		}
+	}
+
+	// Namespaces which failed to report keep their last known statistics until they are no longer managed
+	for _, namespace := range metrics.GetStatsReportedNamespaces() {
+		if !reported[namespace] && !r.isManaged(ctx, namespace, quayIntegrations.Items) {
+			metrics.ForgetRepositoryStats(namespace, nil)
+		}
+	}
+
+	return nil
+}
+
+// reportNamespace records the statistics of every repository of the organization of a namespace
+func (r *RepositoryStatsReporter) reportNamespace(ctx context.Context, quayClient *qclient.QuayClient, namespace string, organizationName string) error {
+
+	if err := r.RateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	repositories, repositoriesResponse, repositoriesErr := quayClient.GetRepositoriesByOrganization(organizationName)
+
+	if repositoriesErr.Error != nil {
+		return fmt.Errorf("error retrieving repositories of organization '%s': %w", organizationName, repositoriesErr.Error)
+	}
+
+	if repositoriesResponse == nil || repositoriesResponse.StatusCode != 200 {
+		return fmt.Errorf("error retrieving repositories of organization '%s'", organizationName)
+	}
+
+	now := time.Now()
+	repositoryNames := map[string]bool{}
+
+	for _, repository := range repositories {
+
+		repositoryNames[repository.Name] = true
+
+		if err := r.RateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		stats, err := usage.GetRepositoryStats(quayClient, organizationName, repository.Name, now.Add(-r.Window), now)
+
+		if err != nil {
+			// Repositories which failed to report keep their last known statistics
+			r.Log.Error(err, "Unable to retrieve repository statistics", "Namespace", namespace, "Repository", repository.Name)
+			continue
+		}
+
+		metrics.RecordRepositoryStats(namespace, repository.Name, stats.Pulls, stats.Pushes, stats.LastPull)
+	}
+
+	metrics.ForgetRepositoryStats(namespace, repositoryNames)
+
+	return nil
+}
+
+// isManaged returns whether a namespace is still synchronized with one of the QuayIntegrations
+func (r *RepositoryStatsReporter) isManaged(ctx context.Context, name string, quayIntegrations []quayv1.QuayIntegration) bool {
+
+	namespace := &corev1.Namespace{}
+
+	if err := r.GetClient().Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		return !apierrors.IsNotFound(err)
+	}
+
+	policies, err := syncpolicy.List(ctx, r.GetClient())
+
+	if err != nil {
+		return true
+	}
+
+	return syncpolicy.NewScope(quayIntegrations, policies).Owner(namespace) != nil && cachescope.InNamespaces(r.Namespaces, name)
+}
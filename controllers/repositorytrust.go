@@ -0,0 +1,122 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// repositoryTrustFailedReason is the reason of the event recorded when trust cannot be changed on a repository
+	repositoryTrustFailedReason = "RepositoryTrustFailed"
+)
+
+// prepareRepositoryTrust returns whether trust is managed on the repositories of a namespace and whether it is to be enabled. Trust is
+// managed while enabled by the QuayIntegration and afterwards, until disabled on the repositories it was enabled on, which is recorded
+// by the repository-trust annotation of the namespace. Trust is left untouched when signing is not enabled on Quay, as reported by the
+// QuayFeaturesSupported condition of the QuayIntegration
+func (r *NamespaceIntegrationReconciler) prepareRepositoryTrust(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) (bool, bool, reconcile.Result, error) {
+
+	enabled := quayIntegration.IsRepositoryTrustEnabled()
+	_, annotated := namespace.Annotations[constants.RepositoryTrustAnnotation]
+
+	if !enabled && !annotated {
+		return false, false, reconcile.Result{}, nil
+	}
+
+	if r.Capabilities != nil && !r.Capabilities.Get(quayIntegration.Name).Supports(capabilities.Signing) {
+		return false, false, reconcile.Result{}, nil
+	}
+
+	if !enabled || annotated {
+		return true, enabled, reconcile.Result{}, nil
+	}
+
+	// Recorded before trust is enabled, so that repositories are reverted even when trust is disabled while being enabled
+	err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, namespace, func() error {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[constants.RepositoryTrustAnnotation] = "true"
+
+		return nil
+	})
+
+	if err != nil {
+		result, err := r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to update namespace",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+
+		return false, false, result, err
+	}
+
+	return true, true, reconcile.Result{}, nil
+}
+
+// changeRepositoryTrust enables or disables trust on a repository. Failures are recorded as events on the namespace rather than
+// failing the synchronization, and retried on the next synchronization. Returns whether trust was changed
+func (r *NamespaceIntegrationReconciler) changeRepositoryTrust(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, repositoryName string, trustEnabled bool) bool {
+
+	logging.Log.Info("Changing Repository Trust", "Organization", quayOrganizationName, "Name", repositoryName, "Trust Enabled", trustEnabled)
+
+	changeTrustResponse, changeTrustErr := quayClient.ChangeRepositoryTrust(quayOrganizationName, repositoryName, trustEnabled)
+
+	if changeTrustErr.Error == nil && changeTrustResponse.StatusCode == 200 {
+		return true
+	}
+
+	err := requestError("error changing repository trust", changeTrustResponse, changeTrustErr.Error)
+
+	logging.Log.Info("Unable to change Repository Trust", "Organization", quayOrganizationName, "Name", repositoryName, "Trust Enabled", trustEnabled, "Error", err.Error())
+	r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", repositoryTrustFailedReason, fmt.Sprintf("Trust of Quay Repository %s/%s could not be changed to %t: %v", quayOrganizationName, repositoryName, trustEnabled, err))
+
+	return false
+}
+
+// releaseRepositoryTrust removes the repository-trust annotation of a namespace once trust is disabled on its repositories
+func (r *NamespaceIntegrationReconciler) releaseRepositoryTrust(ctx context.Context, namespace *corev1.Namespace) (reconcile.Result, error) {
+
+	err := r.CoreComponents.ReconcilerBase.UpdateResource(ctx, namespace, func() error {
+		delete(namespace.Annotations, constants.RepositoryTrustAnnotation)
+		return nil
+	})
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to update namespace",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
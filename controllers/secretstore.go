@@ -0,0 +1,196 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/secretstore"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// secretStoreTimeout bounds the requests made to external secret stores
+	secretStoreTimeout = 30 * time.Second
+)
+
+// secretActuatorFor returns the SecretActuator writing the robot account Secrets of a namespace. The SecretActuator of products
+// embedding the operator takes precedence over the secret store of the QuayIntegration
+func (r *NamespaceIntegrationReconciler) secretActuatorFor(ctx context.Context, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (actuator.SecretActuator, error) {
+
+	if r.SecretActuator != nil || quayIntegration.Spec.SecretStore == nil {
+		return r.secretActuator(), nil
+	}
+
+	store, err := r.newSecretStore(ctx, quayIntegration.Spec.SecretStore)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretstore.Actuator{
+		Store:        store,
+		PathTemplate: quayIntegration.Spec.SecretStore.PathTemplate,
+		OrgName:      quayOrganizationName,
+		ClusterID:    quayIntegration.Spec.ClusterID,
+	}, nil
+}
+
+// newSecretStore returns the client of the external secret store of a QuayIntegration
+func (r *NamespaceIntegrationReconciler) newSecretStore(ctx context.Context, secretStore *quayv1.SecretStore) (secretstore.Store, error) {
+
+	httpClient := &http.Client{Timeout: secretStoreTimeout}
+
+	switch {
+	case secretStore.Vault != nil && secretStore.AWSSecretsManager != nil:
+		return nil, fmt.Errorf("only one of vault and awsSecretsManager may be set")
+
+	case secretStore.Vault != nil:
+		token, err := r.getSecretStoreCredential(ctx, secretStore.Vault.TokenSecret, secretstore.DefaultVaultTokenKey, true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &secretstore.VaultStore{
+			Client:    httpClient,
+			Address:   secretStore.Vault.Address,
+			Mount:     secretStore.Vault.Mount,
+			KVVersion: int(secretStore.Vault.KVVersion),
+			Token:     token,
+		}, nil
+
+	case secretStore.AWSSecretsManager != nil:
+		credentials, err := r.getAWSCredentials(ctx, secretStore.AWSSecretsManager.CredentialsSecret)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &secretstore.AWSSecretsManagerStore{
+			Client:      httpClient,
+			Region:      secretStore.AWSSecretsManager.Region,
+			Endpoint:    secretStore.AWSSecretsManager.Endpoint,
+			Credentials: credentials,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("one of vault and awsSecretsManager must be set")
+}
+
+// getSecretStoreCredential returns a credential of a secret store stored in a Secret. The key of the reference, when set,
+// replaces the default key of single credentials
+func (r *NamespaceIntegrationReconciler) getSecretStoreCredential(ctx context.Context, secretRef *quayv1.SecretRef, key string, required bool) (string, error) {
+
+	if secretRef == nil {
+		return "", fmt.Errorf("credentials of the secret store are not set")
+	}
+
+	secret := &corev1.Secret{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name}, secret); err != nil {
+		return "", err
+	}
+
+	if secretRef.Key != "" && key == secretstore.DefaultVaultTokenKey {
+		key = secretRef.Key
+	}
+
+	value, found := secret.Data[key]
+
+	if !found && required {
+		return "", fmt.Errorf("'%s' not found in Secret %s/%s", key, secret.Namespace, secret.Name)
+	}
+
+	return string(value), nil
+}
+
+// getAWSCredentials returns the AWS credentials stored in a Secret
+func (r *NamespaceIntegrationReconciler) getAWSCredentials(ctx context.Context, secretRef *quayv1.SecretRef) (secretstore.AWSCredentials, error) {
+
+	credentials := secretstore.AWSCredentials{}
+
+	for key, value := range map[string]*string{
+		secretstore.AWSAccessKeyIDKey:     &credentials.AccessKeyID,
+		secretstore.AWSSecretAccessKeyKey: &credentials.SecretAccessKey,
+		secretstore.AWSSessionTokenKey:    &credentials.SessionToken,
+	} {
+		credential, err := r.getSecretStoreCredential(ctx, secretRef, key, key != secretstore.AWSSessionTokenKey)
+
+		if err != nil {
+			return credentials, err
+		}
+
+		*value = credential
+	}
+
+	return credentials, nil
+}
+
+// removeStoredSecrets deletes the credentials of the robot accounts of a deleted namespace from the external secret store
+func (r *NamespaceIntegrationReconciler) removeStoredSecrets(ctx context.Context, namespace *corev1.Namespace, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if quayIntegration.Spec.SecretStore == nil {
+		return reconcile.Result{}, nil
+	}
+
+	secretActuator, err := r.secretActuatorFor(ctx, quayOrganizationName, quayIntegration)
+
+	if err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Unable to set up secret store",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Reason:       "ConfigrurationError",
+			Error:        err,
+		})
+	}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+		for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+			secretName, err := utils.GenerateRobotAccountSecretName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount), secretFormat)
+
+			if err != nil {
+				continue
+			}
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: secretName}}
+
+			if err := secretActuator.DeleteSecret(ctx, r.CoreComponents.ReconcilerBase, secret); err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Unable to delete robot account credentials from secret store",
+					KeyAndValues: []interface{}{"Namespace", namespace.Name, "Secret", secretName},
+					Error:        err,
+				})
+			}
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/provenance"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// serviceAccountRobotRoles contains the Quay roles service accounts can request with the robot-role label
+var serviceAccountRobotRoles = map[string]qclient.QuayRole{
+	string(qclient.QuayRoleRead):  qclient.QuayRoleRead,
+	string(qclient.QuayRoleWrite): qclient.QuayRoleWrite,
+}
+
+// reconcileServiceAccountRobots creates a robot account for each service account of a namespace labeled with a robot role when
+// service account robots are enabled. Robot accounts recorded as created for other service accounts than the builder, default and
+// deployer service accounts are deleted along with their Secrets once their service account is deleted or unlabeled
+func (r *NamespaceIntegrationReconciler) reconcileServiceAccountRobots(ctx context.Context, request reconcile.Request, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	if !quayIntegration.Spec.ServiceAccountRobots {
+		return reconcile.Result{}, nil
+	}
+
+	serviceAccounts := corev1.ServiceAccountList{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().List(ctx, &serviceAccounts, client.InNamespace(namespace.Name)); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving service accounts",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name},
+			Error:        err,
+		})
+	}
+
+	// Robot account names of the builder, default and deployer service accounts were validated before
+	reservedRobotNames := map[string]bool{constants.NamespaceOwnerRobotName: true}
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+		robotName, _ := utils.GenerateRobotAccountName(quayIntegration, namespace.Name, quayOrganizationName, string(serviceAccount))
+		reservedRobotNames[robotName] = true
+	}
+
+	requestedRobotNames := map[string]bool{}
+
+	for i := range serviceAccounts.Items {
+
+		serviceAccount := &serviceAccounts.Items[i]
+
+		if _, builtIn := QuayServiceAccountPermissionMatrix[qotypes.OpenShiftServiceAccount(serviceAccount.Name)]; builtIn || serviceAccount.DeletionTimestamp != nil {
+			continue
+		}
+
+		roleLabel, labeled := serviceAccount.Labels[constants.QuayRobotRoleLabel]
+
+		if !labeled {
+			continue
+		}
+
+		role, found := serviceAccountRobotRoles[roleLabel]
+
+		if !found {
+			logging.Log.Info("Ignoring unsupported robot role of service account", "Namespace", namespace.Name, "Service Account", serviceAccount.Name, "Role", roleLabel)
+			continue
+		}
+
+		robotName, err := utils.GenerateRobotAccountName(quayIntegration, namespace.Name, quayOrganizationName, serviceAccount.Name)
+
+		if err == nil && (reservedRobotNames[robotName] || requestedRobotNames[robotName]) {
+			err = fmt.Errorf("robot account name '%s' generated for service account '%s' is already in use", robotName, serviceAccount.Name)
+		}
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Invalid robot account name for service account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount.Name, "Template", quayIntegration.Spec.RobotNameTemplate},
+				Reason:       "ConfigrurationError",
+				Error:        err,
+			})
+		}
+
+		requestedRobotNames[robotName] = true
+
+		if result, err := r.createRobotAccountAssociateToSA(ctx, request, namespace, quayClient, quayOrganizationName, qotypes.OpenShiftServiceAccount(serviceAccount.Name), role, quayIntegration); err != nil || result.Requeue {
+			return result, err
+		}
+	}
+
+	robotAccounts, robotAccountsResponse, robotAccountsErr := quayClient.GetOrganizationRobotAccounts(quayOrganizationName)
+
+	if robotAccountsErr.Error != nil || robotAccountsResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving robot accounts for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Status Code", statusCode(robotAccountsResponse)},
+			Error:        robotAccountsErr.Error,
+		})
+	}
+
+	expectedProvenance := provenance.New(quayIntegration, namespace)
+
+	for _, robotAccount := range robotAccounts.Robots {
+
+		robotName := strings.TrimPrefix(robotAccount.Name, quayOrganizationName+"+")
+		serviceAccount, _ := robotAccount.UnstructuredMetadata[constants.ServiceAccountRobotMetadataKey].(string)
+
+		if _, builtIn := QuayServiceAccountPermissionMatrix[qotypes.OpenShiftServiceAccount(serviceAccount)]; builtIn || serviceAccount == "" || requestedRobotNames[robotName] {
+			continue
+		}
+
+		// Robot accounts created for another namespace or cluster sharing the organization are left in place
+		if robotProvenance := provenance.FromRobotMetadata(robotAccount.UnstructuredMetadata); robotProvenance.Namespace != namespace.Name || robotProvenance.Conflict(expectedProvenance) != "" {
+			continue
+		}
+
+		if result, err := r.removeServiceAccountRobot(ctx, namespace, quayClient, quayOrganizationName, serviceAccount, robotName, quayIntegration); err != nil || result.Requeue {
+			return result, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// removeServiceAccountRobot unlinks the Secrets of a robot account from its service account, if it still exists, deletes them and
+// deletes the robot account
+func (r *NamespaceIntegrationReconciler) removeServiceAccountRobot(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, serviceAccount string, robotName string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	logging.Log.Info("Deleting robot account of service account", "Namespace", namespace.Name, "Service Account", serviceAccount, "Robot Account", robotName)
+
+	if quayIntegration.ProvidesRobotAccountSecrets() {
+
+		writer, err := r.namespaceWriter(ctx, namespace.Name, quayIntegration)
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to set up impersonation of namespace service account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", r.ImpersonationServiceAccount},
+				Error:        err,
+			})
+		}
+
+		secretActuator, err := r.secretActuatorFor(ctx, quayOrganizationName, quayIntegration)
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Unable to set up secret store",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name},
+				Reason:       "ConfigrurationError",
+				Error:        err,
+			})
+		}
+
+		secretNames := map[string]bool{}
+
+		for _, secretFormat := range quayIntegration.GetSecretFormats() {
+			if secretName, err := utils.GenerateRobotAccountSecretName(quayIntegration, namespace.Name, quayOrganizationName, serviceAccount, secretFormat); err == nil {
+				secretNames[secretName] = true
+			}
+		}
+
+		existingServiceAccount := &corev1.ServiceAccount{}
+		serviceAccountErr := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: serviceAccount}, existingServiceAccount)
+		serviceAccountFound := serviceAccountErr == nil
+
+		if serviceAccountErr != nil && !apierrors.IsNotFound(serviceAccountErr) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Failed to get existing platform service account",
+				KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount},
+				Error:        serviceAccountErr,
+			})
+		}
+
+		if serviceAccountFound && quayIntegration.ManagesClusterSecrets() {
+			if err := writer.UpdateResource(ctx, existingServiceAccount, func() error {
+				unlinkSecrets(existingServiceAccount, secretNames)
+				return nil
+			}); err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Failed to to updated existing platform service account",
+					KeyAndValues: []interface{}{"Namespace", namespace.Name, "Service Account", serviceAccount},
+					Error:        err,
+				})
+			}
+		}
+
+		for secretName := range secretNames {
+			if err := secretActuator.DeleteSecret(ctx, writer, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: secretName}}); err != nil {
+				return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+					Object:       namespace,
+					Message:      "Failed to delete robot account Secret",
+					KeyAndValues: []interface{}{"Namespace", namespace.Name, "Secret", secretName},
+					Error:        err,
+				})
+			}
+		}
+	}
+
+	deleteResponse, deleteError := r.orgActuator(quayClient).DeleteOrganizationRobotAccount(quayOrganizationName, robotName)
+
+	if deleteError.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 400 && deleteResponse.StatusCode != 404) {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred deleting robot account for Quay Organization",
+			KeyAndValues: []interface{}{"Quay Organization", quayOrganizationName, "Robot Account", robotName, "Status Code", statusCode(deleteResponse)},
+			Error:        deleteError.Error,
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// unlinkSecrets removes Secrets from the pull secrets and mountable secrets of a service account
+func unlinkSecrets(serviceAccount *corev1.ServiceAccount, secretNames map[string]bool) {
+
+	imagePullSecrets := []corev1.LocalObjectReference{}
+
+	for _, imagePullSecret := range serviceAccount.ImagePullSecrets {
+		if !secretNames[imagePullSecret.Name] {
+			imagePullSecrets = append(imagePullSecrets, imagePullSecret)
+		}
+	}
+
+	secrets := []corev1.ObjectReference{}
+
+	for _, secret := range serviceAccount.Secrets {
+		if !secretNames[secret.Name] {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	serviceAccount.ImagePullSecrets = imagePullSecrets
+	serviceAccount.Secrets = secrets
+}
+
+// robotRoleLabelChanged filters events of service accounts to the creation, deletion and relabeling of service accounts labeled with
+// a robot role
+func robotRoleLabelChanged() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			_, labeled := e.Object.GetLabels()[constants.QuayRobotRoleLabel]
+			return labeled
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldRole, oldLabeled := e.ObjectOld.GetLabels()[constants.QuayRobotRoleLabel]
+			role, labeled := e.ObjectNew.GetLabels()[constants.QuayRobotRoleLabel]
+			return oldLabeled != labeled || oldRole != role
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, labeled := e.Object.GetLabels()[constants.QuayRobotRoleLabel]
+			return labeled
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/hooks"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// syncHookFailedReason is the reason of the events recorded when a sync hook fails
+	syncHookFailedReason = "SyncHookFailed"
+)
+
+// runSyncHooks invokes the hooks registered by products embedding the operator followed by the sync hooks of the QuayIntegration
+// at a step of the synchronization of a namespace. The synchronization stops when a hook whose failure policy is Fail fails
+func (r *NamespaceIntegrationReconciler) runSyncHooks(ctx context.Context, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration, event hooks.Event) (reconcile.Result, error) {
+
+	registrations := append([]hooks.Registration{}, r.Hooks...)
+
+	for i := range quayIntegration.Spec.SyncHooks {
+
+		syncHook := &quayIntegration.Spec.SyncHooks[i]
+
+		if !syncHook.HasPoint(event.Point) {
+			continue
+		}
+
+		token, err := r.getSyncHookToken(ctx, syncHook)
+
+		if err != nil {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Unable to retrieve token of sync hook",
+				KeyAndValues: []interface{}{"Hook", syncHook.Name},
+				Reason:       "ConfigrurationError",
+				Error:        err,
+			})
+		}
+
+		registrations = append(registrations, hooks.NewHTTPRegistration(syncHook, token))
+	}
+
+	if len(registrations) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	event.QuayIntegration = quayIntegration.Name
+	event.Namespace = namespace.Name
+
+	failures, err := hooks.Run(ctx, registrations, event)
+
+	for _, failure := range failures {
+		logging.Log.Info("Sync hook failed, continuing", "Namespace", namespace.Name, "Hook", failure.Name, "Point", event.Point, "Error", failure.Error.Error())
+		r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", syncHookFailedReason, fmt.Sprintf("Hook %s failed at %s: %s", failure.Name, event.Point, failure.Error))
+	}
+
+	if err != nil {
+		r.CoreComponents.ReconcilerBase.GetRecorder().Event(namespace, "Warning", syncHookFailedReason, err.Error())
+
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Sync hook failed",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Point", event.Point},
+			Error:        err,
+		})
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// getSyncHookToken returns the bearer token of a sync hook, if any
+func (r *NamespaceIntegrationReconciler) getSyncHookToken(ctx context.Context, syncHook *quayv1.SyncHook) (string, error) {
+
+	if syncHook.TokenSecret == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+
+	if err := r.CoreComponents.ReconcilerBase.GetClient().Get(ctx, types.NamespacedName{Namespace: syncHook.TokenSecret.Namespace, Name: syncHook.TokenSecret.Name}, secret); err != nil {
+		return "", err
+	}
+
+	key := syncHook.TokenSecret.Key
+
+	if key == "" {
+		key = hooks.DefaultTokenKey
+	}
+
+	token, found := secret.Data[key]
+
+	if !found {
+		return "", fmt.Errorf("token '%s' not found in Secret %s/%s", key, secret.Namespace, secret.Name)
+	}
+
+	return string(token), nil
+}
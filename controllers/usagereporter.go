@@ -0,0 +1,164 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/usage"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// UsageReporter periodically retrieves the number of repositories and the storage consumed by the organization of each
+// managed namespace, recording them as annotations of the namespace and as metrics for chargeback tooling
+type UsageReporter struct {
+	reconcilerbase.ReconcilerBase
+	Log            logr.Logger
+	HTTPClientPool *qclient.HTTPClientPool
+	Namespaces     []string
+
+	// Interval between reports
+	Interval time.Duration
+}
+
+// Start implements manager.Runnable
+func (u *UsageReporter) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := u.report(ctx); err != nil {
+			u.Log.Error(err, "Failed to report Quay usage")
+		}
+	}, u.Interval)
+
+	return nil
+}
+
+func (u *UsageReporter) report(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := u.GetClient().List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	reported := map[string]bool{}
+
+	for _, quayIntegration := range quayIntegrations.Items {
+
+		namespaces, err := state.ManagedOrganizationNamespaces(ctx, u.GetClient(), &quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		quayClient, err := state.NewQuayClient(ctx, u.GetClient(), quayIntegration.DeepCopy(), u.HTTPClientPool)
+
+		if err != nil {
+			u.Log.Error(err, "Unable to create Quay client", "QuayIntegration", quayIntegration.Name)
+			continue
+		}
+
+		for _, namespace := range namespaces {
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			if !cachescope.InNamespaces(u.Namespaces, namespace) {
+				continue
+			}
+
+			organizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+
+			organizationUsage, err := usage.Get(quayClient, organizationName)
+
+			if err != nil {
+				u.Log.Error(err, "Unable to retrieve usage of organization", "Namespace", namespace, "Organization", organizationName)
+				continue
+			}
+
+			reported[namespace] = true
+			metrics.RecordOrganizationUsage(namespace, organizationName, organizationUsage.Repositories, organizationUsage.StorageBytes, organizationUsage.StorageReported)
+
+			if err := u.annotateNamespace(ctx, namespace, organizationUsage); err != nil {
+				u.Log.Error(err, "Unable to record usage on namespace", "Namespace", namespace)
+			}
+		}
+	}
+
+	// Namespaces which failed to report keep their last known usage until they are no longer managed
+	for _, namespace := range metrics.GetUsageReportedNamespaces() {
+		if !reported[namespace] && !u.isManaged(ctx, namespace, quayIntegrations.Items) {
+			metrics.ForgetOrganizationUsage(namespace)
+		}
+	}
+
+	return nil
+}
+
+// annotateNamespace records the usage on a namespace, only updating it when the usage changed
+func (u *UsageReporter) annotateNamespace(ctx context.Context, name string, organizationUsage usage.Usage) error {
+
+	namespace := &corev1.Namespace{}
+
+	if err := u.GetClient().Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !usage.ApplyAnnotations(namespace.DeepCopy(), organizationUsage) {
+		return nil
+	}
+
+	return u.UpdateResource(ctx, namespace, func() error {
+		usage.ApplyAnnotations(namespace, organizationUsage)
+		return nil
+	})
+}
+
+// isManaged returns whether a namespace is still synchronized with one of the QuayIntegrations
+func (u *UsageReporter) isManaged(ctx context.Context, name string, quayIntegrations []quayv1.QuayIntegration) bool {
+
+	namespace := &corev1.Namespace{}
+
+	if err := u.GetClient().Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		return !apierrors.IsNotFound(err)
+	}
+
+	policies, err := syncpolicy.List(ctx, u.GetClient())
+
+	if err != nil {
+		return true
+	}
+
+	return syncpolicy.NewScope(quayIntegrations, policies).Owner(namespace) != nil && cachescope.InNamespaces(u.Namespaces, name)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// setupUserResources synchronizes a namespace mapped to the repositories of a Quay user account. Robot accounts cannot be created in
+// the account of another user, so the robot accounts of the namespace belong to the account of the operator and are granted their
+// role on each repository of the namespace instead of relying on the default permissions of an organization
+func (r *NamespaceIntegrationReconciler) setupUserResources(ctx context.Context, namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, quayUsername string, quayIntegration *quayv1.QuayIntegration) (reconcile.Result, error) {
+
+	_, userResponse, userErr := quayClient.GetUserByName(quayUsername)
+
+	if userErr.Error != nil || (userResponse.StatusCode != 200 && userResponse.StatusCode != 404) {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving Quay user account",
+			KeyAndValues: []interface{}{"Quay User", quayUsername},
+			Error:        requestError("error retrieving user account", userResponse, userErr.Error),
+		})
+	}
+
+	if userResponse.StatusCode == 404 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Quay user account of the requester of the namespace does not exist",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Quay User", quayUsername},
+			Reason:       "ConfigrurationError",
+		})
+	}
+
+	if err := validateRobotAccountSecretNames(quayIntegration, namespace.Name, quayOrganizationName); err != nil {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Invalid Secret name template",
+			KeyAndValues: []interface{}{"Namespace", namespace.Name, "Template", quayIntegration.Spec.SecretNameTemplate},
+			Reason:       "ConfigrurationError",
+			Error:        err,
+		})
+	}
+
+	imageSigningResult, imageSigningErr := r.reconcileImageSigning(ctx, namespace, quayIntegration)
+
+	if imageSigningErr != nil || imageSigningResult.Requeue {
+		return imageSigningResult, imageSigningErr
+	}
+
+	robotRoles := map[string]qclient.QuayRole{}
+
+	for serviceAccount, role := range QuayServiceAccountPermissionMatrix {
+
+		robotAccount, result, err := r.ensureUserRobotAccount(namespace, quayClient, quayOrganizationName, serviceAccount, quayIntegration)
+
+		if err != nil || result.Requeue {
+			return result, err
+		}
+
+		// Robot accounts of user accounts are not federated, their Secrets contain their permanent token
+		if result, err := r.associateRobotAccountToSA(ctx, namespace, quayOrganizationName, serviceAccount, robotAccount, nil, quayIntegration); err != nil || result.Requeue {
+			return result, err
+		}
+
+		robotRoles[robotAccount.Name] = role
+	}
+
+	if result, err := r.reconcileInjectedPullSecrets(ctx, namespace, quayIntegration); err != nil || result.Requeue {
+		return result, err
+	}
+
+	return r.reconcileRepositories(ctx, namespace, quayClient, quayUsername, quayIntegration, robotRoles)
+}
+
+// ensureUserRobotAccount returns the robot account of the operator account used by a service account of a namespace, creating it if needed
+func (r *NamespaceIntegrationReconciler) ensureUserRobotAccount(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, serviceAccount qotypes.OpenShiftServiceAccount, quayIntegration *quayv1.QuayIntegration) (qclient.RobotAccount, reconcile.Result, error) {
+
+	robotName := utils.GenerateUserRobotName(quayOrganizationName, string(serviceAccount))
+
+	robotAccount, robotAccountResponse, robotAccountErr := quayClient.GetUserRobotAccount(robotName)
+
+	if robotAccountErr.Error != nil || (robotAccountResponse.StatusCode != 200 && robotAccountResponse.StatusCode != 400 && robotAccountResponse.StatusCode != 404) {
+		result, err := r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving robot account of the operator account",
+			KeyAndValues: []interface{}{"Robot Account", robotName, "Service Account", serviceAccount},
+			Error:        requestError("error retrieving robot account", robotAccountResponse, robotAccountErr.Error),
+		})
+		return qclient.RobotAccount{}, result, err
+	}
+
+	if robotAccountResponse.StatusCode == 200 {
+		return robotAccount, reconcile.Result{}, nil
+	}
+
+	robotAccount, robotAccountResponse, robotAccountErr = r.orgActuator(quayClient).CreateUserRobotAccountWithMetadata(robotName, newRobotAccountRequest(namespace, quayIntegration, string(serviceAccount), fmt.Sprintf("Credentials of service account %s", serviceAccount)))
+
+	if robotAccountErr.Error != nil || robotAccountResponse.StatusCode != 201 {
+		result, err := r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred creating robot account of the operator account",
+			KeyAndValues: []interface{}{"Robot Account", robotName, "Service Account", serviceAccount},
+			Error:        requestError("error creating robot account", robotAccountResponse, robotAccountErr.Error),
+		})
+		return qclient.RobotAccount{}, result, err
+	}
+
+	logging.Log.Info("Created robot account of the operator account", "Namespace", namespace.Name, "Robot Account", robotAccount.Name)
+
+	return robotAccount, reconcile.Result{}, nil
+}
+
+// reconcileRepositoryPermissions grants robot accounts their role on a repository unless already granted
+func (r *NamespaceIntegrationReconciler) reconcileRepositoryPermissions(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string, repositoryName string, robotRoles map[string]qclient.QuayRole) (reconcile.Result, error) {
+
+	permissions, permissionsResponse, permissionsErr := quayClient.GetRepositoryUserPermissions(quayOrganizationName, repositoryName)
+
+	if permissionsErr.Error != nil || permissionsResponse.StatusCode != 200 {
+		return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+			Object:       namespace,
+			Message:      "Error occurred retrieving permissions of Quay Repository",
+			KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName)},
+			Error:        requestError("error retrieving repository permissions", permissionsResponse, permissionsErr.Error),
+		})
+	}
+
+	for robotName, role := range robotRoles {
+
+		if permissions.Permissions[robotName].Role == string(role) {
+			continue
+		}
+
+		permissionResponse, permissionErr := r.orgActuator(quayClient).SetRepositoryUserPermission(quayOrganizationName, repositoryName, robotName, string(role))
+
+		if permissionErr.Error != nil || permissionResponse.StatusCode != 200 {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred granting robot account permission on Quay Repository",
+				KeyAndValues: []interface{}{"Quay Repository", fmt.Sprintf("%s/%s", quayOrganizationName, repositoryName), "Robot Account", robotName, "Role", role},
+				Error:        requestError("error granting repository permission", permissionResponse, permissionErr.Error),
+			})
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// cleanupUserResources deletes the robot accounts of the operator account used by a namespace mapped to a Quay user account. The
+// repositories belong to the user and are kept
+func (r *NamespaceIntegrationReconciler) cleanupUserResources(namespace *corev1.Namespace, quayClient *qclient.QuayClient, quayOrganizationName string) (reconcile.Result, error) {
+
+	for serviceAccount := range QuayServiceAccountPermissionMatrix {
+
+		robotName := utils.GenerateUserRobotName(quayOrganizationName, string(serviceAccount))
+
+		deleteResponse, deleteErr := r.orgActuator(quayClient).DeleteUserRobotAccount(robotName)
+
+		if deleteErr.Error != nil || (deleteResponse.StatusCode != 204 && deleteResponse.StatusCode != 400 && deleteResponse.StatusCode != 404) {
+			return r.CoreComponents.ManageError(&core.QuayIntegrationCoreError{
+				Object:       namespace,
+				Message:      "Error occurred deleting robot account of the operator account",
+				KeyAndValues: []interface{}{"Robot Account", robotName},
+				Error:        requestError("error deleting robot account", deleteResponse, deleteErr.Error),
+			})
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
@@ -7,10 +7,11 @@ require (
 	github.com/onsi/ginkgo v1.14.1
 	github.com/onsi/gomega v1.10.2
 	github.com/openshift/api v0.0.0-20210202165416-a9e731090f5e
-	github.com/redhat-cop/operator-utils v1.1.2
+	github.com/prometheus/client_golang v1.7.1
 	gomodules.xyz/jsonpatch/v2 v2.1.0
 	k8s.io/api v0.20.0
 	k8s.io/apimachinery v0.20.0
 	k8s.io/client-go v0.20.0
 	sigs.k8s.io/controller-runtime v0.7.2
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -17,17 +17,27 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/flowcontrol"
 
 	imagev1 "github.com/openshift/api/image/v1"
 
@@ -35,18 +45,50 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/quay/quay-bridge-operator/pkg/admin"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
 	"github.com/quay/quay-bridge-operator/pkg/constants"
 	"github.com/quay/quay-bridge-operator/pkg/core"
+	"github.com/quay/quay-bridge-operator/pkg/debug"
+	"github.com/quay/quay-bridge-operator/pkg/federation"
+	"github.com/quay/quay-bridge-operator/pkg/health"
+	"github.com/quay/quay-bridge-operator/pkg/httpauth"
+	"github.com/quay/quay-bridge-operator/pkg/imagescan"
+	"github.com/quay/quay-bridge-operator/pkg/inventory"
+	"github.com/quay/quay-bridge-operator/pkg/jobs"
+	"github.com/quay/quay-bridge-operator/pkg/manageroptions"
+	"github.com/quay/quay-bridge-operator/pkg/manifests"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/migration"
+	"github.com/quay/quay-bridge-operator/pkg/monitoring"
+	"github.com/quay/quay-bridge-operator/pkg/priority"
+	"github.com/quay/quay-bridge-operator/pkg/rbac"
+	"github.com/quay/quay-bridge-operator/pkg/readiness"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/version"
 
 	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/config"
 	"github.com/quay/quay-bridge-operator/controllers"
 	quaywebhook "github.com/quay/quay-bridge-operator/pkg/webhook"
-	"github.com/redhat-cop/operator-utils/pkg/util"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
+const (
+	// allMode runs the controllers and serves the admission webhook
+	allMode = "all"
+	// controllersMode only runs the controllers, leaving the admission webhook to a separate deployment
+	controllersMode = "controllers"
+	// webhookMode only serves the admission webhook, letting it scale independently from the controllers
+	webhookMode = "webhook"
+)
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -65,72 +107,889 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableMonitoring bool
+	var enableGrafanaDashboard bool
+	var healthBacklogThreshold int
+	var exportStatePath string
+	var importStatePath string
+	var stateQuayIntegration string
+	var orphanCleanup string
+	var decommissionReport bool
+	var internalRegistryHostnames string
+	var imageScanInterval time.Duration
+	var imageScanAllowedRegistries string
+	var scopeCache bool
+	var watchNamespaces string
+	var enableBuildSync bool
+	var printRBAC bool
+	var renderManifests bool
+	var renderValues string
+	var manageWebhookCertificates bool
+	var webhookCertSecret string
+	var webhookServiceName string
+	var impersonationServiceAccount string
+	var impersonationClusterRole string
+	var enableImagePolicies bool
+	var enableBuildRecovery bool
+	var enableGlobalPullSecret bool
+	var enableReaderRobot bool
+	var enablePullGrants bool
+	var enableJobQueue bool
+	var enableMigrations bool
+	var webhookShutdownDelay time.Duration
+	var webhookShutdownTimeout time.Duration
+	var mode string
+	var debugAddr string
+	var debugTokenFile string
+	var debugCertDir string
+	var adminAddr string
+	var adminTokenFile string
+	var adminCertDir string
+	var buildBackfillInterval time.Duration
+	var buildBackfillWindow time.Duration
+	var usageReportInterval time.Duration
+	var repositoryStatsInterval time.Duration
+	var repositoryStatsWindow time.Duration
+	var repositoryStatsQPS float64
+	var auditLogInterval time.Duration
+	var baseImageTriggerInterval time.Duration
+	var credentialCheckInterval time.Duration
+	var shortLivedCredentialsTokenFile string
+	var shortLivedCredentialsRefreshInterval time.Duration
+	var enableSecretProtection bool
+	var enableSecretProtectionWebhook bool
+	var warmupWindow time.Duration
+	var routineSyncQPS float64
+	var enableProjectAnnotation bool
+	var enablePullSecretInjection bool
+	var buildRepositoryCreation string
+	var buildRepositoryCreationTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableMonitoring, "enable-monitoring", true,
+		"Provision a ServiceMonitor and PrometheusRule for the operator when the monitoring.coreos.com API is available.")
+	flag.BoolVar(&enableGrafanaDashboard, "enable-grafana-dashboard", false,
+		"Provision a ConfigMap containing a Grafana dashboard for the operator.")
+	flag.IntVar(&healthBacklogThreshold, "health-backlog-threshold", 50,
+		"Number of pending synchronizations above which the operator reports itself as Degraded.")
+	flag.StringVar(&exportStatePath, "export-state", "",
+		"Export the Quay state managed by the operator to the provided file and exit.")
+	flag.StringVar(&importStatePath, "import-state", "",
+		"Re-create the Quay state described by the provided file and exit.")
+	flag.StringVar(&stateQuayIntegration, "state-quay-integration", "",
+		"Name of the QuayIntegration used by --export-state and --import-state. Optional when a single QuayIntegration exists.")
+	flag.StringVar(&orphanCleanup, "orphan-cleanup", "",
+		"Print the inventory of the resources created by the operator, including the resources orphaned by previous versions or deleted QuayIntegrations, and exit. DryRun only reports orphaned resources while Delete also deletes them.")
+	flag.BoolVar(&decommissionReport, "decommission-report", false,
+		"Print the references to the internal registry remaining in the Pods, BuildConfigs and ImageStreams of the cluster and whether the internal registry can be disabled, and exit.")
+	flag.StringVar(&internalRegistryHostnames, "internal-registry-hostnames", "",
+		"Comma separated list of additional hostnames of the internal registry, such as the hostname of its route, checked by --decommission-report and the image reference scanner.")
+	flag.BoolVar(&scopeCache, "scope-cache", false,
+		"Only cache Builds labeled as managed by the operator and read Secrets directly from the API server instead of caching every Secret and Build in the cluster.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma separated list of namespaces the operator is restricted to. Every namespace is watched when empty.")
+	flag.BoolVar(&enableBuildSync, "enable-build-sync", true,
+		"Import the images of completed Builds pushed to Quay into their destination ImageStreams.")
+	flag.BoolVar(&printRBAC, "print-rbac", false,
+		"Print the ClusterRole, and Roles when --watch-namespaces is set, required by the enabled features and exit.")
+	flag.BoolVar(&renderManifests, "render-manifests", false,
+		"Print the manifests deploying the operator with the other flags provided, including the CRD, the RBAC required by the enabled features and the webhook configurations, and exit.")
+	flag.StringVar(&renderValues, "render-values", "",
+		"Path to a YAML file configuring the namespace, image, resources and webhook certificates of the manifests printed by --render-manifests.")
+	flag.BoolVar(&manageWebhookCertificates, "manage-webhook-certificates", false,
+		"Issue a self-signed CA and the serving certificate of the webhooks, rotate them before they expire and inject the CA bundle into the webhook configurations. For installations where neither OLM nor the OpenShift service CA provide the certificate.")
+	flag.StringVar(&webhookCertSecret, "webhook-cert-secret", "quay-bridge-operator-webhook-server-cert",
+		"Secret in the namespace of the operator storing the certificates issued with --manage-webhook-certificates.")
+	flag.StringVar(&webhookServiceName, "webhook-service", "quay-bridge-operator-webhook-service",
+		"Service routing admission requests to the operator, determining the names of the certificate issued with --manage-webhook-certificates and the webhooks it is injected into.")
+	flag.StringVar(&impersonationServiceAccount, "impersonate-service-account", "",
+		"Name of a service account created in each synchronized namespace and impersonated to write Secrets and update service accounts. Writes use the identity of the operator when empty.")
+	flag.StringVar(&impersonationClusterRole, "impersonation-cluster-role", "quay-bridge-operator-namespace-writer-role",
+		"ClusterRole bound to the impersonated service account within each synchronized namespace.")
+	flag.BoolVar(&enableBuildRecovery, "enable-build-recovery", false,
+		"Replace pending Builds admitted without being redirected to Quay, such as while the webhook was unavailable, with a clone.")
+	flag.DurationVar(&buildBackfillInterval, "build-backfill-interval", 10*time.Minute,
+		"Interval at which recent Builds are scanned for Builds missed by the webhook when --enable-build-recovery is set. Scanning is disabled when 0.")
+	flag.DurationVar(&buildBackfillWindow, "build-backfill-window", time.Hour,
+		"Age of the oldest Build scanned for Builds missed by the webhook.")
+	flag.BoolVar(&enableImagePolicies, "enable-image-policies", false,
+		"Generate sigstore ImagePolicies or ClusterImagePolicies for the Quay organizations of QuayIntegrations defining an imagePolicy.")
+	flag.BoolVar(&enableGlobalPullSecret, "enable-global-pull-secret", false,
+		"Add a robot account of the operator account granted read access to each Quay organization to the global pull secret in openshift-config, letting nodes pull images from Quay. Existing entries not added by the operator are never replaced.")
+	flag.BoolVar(&enableReaderRobot, "enable-reader-robot", false,
+		"Distribute a pull secret granting read access to every managed Quay organization to the namespaces listed by QuayIntegrations defining a readerRobot.")
+	flag.BoolVar(&enablePullGrants, "enable-pull-grants", true,
+		"Grant the namespaces listed by the quay.openshift.io/grant-pull-to annotation of a namespace read access to its Quay organization.")
+	flag.BoolVar(&enableJobQueue, "enable-job-queue", true,
+		"Run long operations against Quay, such as granting read access to every repository of an organization, in the background. Progress is checkpointed to a ConfigMap in the namespace of the operator.")
+	flag.BoolVar(&enableMigrations, "enable-migrations", true,
+		"Apply the one-time migrations of resources created by previous versions of the operator before synchronization starts. Applied migrations are recorded in a ConfigMap in the namespace of the operator.")
+	flag.DurationVar(&webhookShutdownDelay, "webhook-shutdown-delay", quaywebhook.DefaultShutdownDelay,
+		"Time admission requests are still accepted once shutdown begins, letting the endpoint of the operator be removed from the webhook service.")
+	flag.DurationVar(&webhookShutdownTimeout, "webhook-shutdown-timeout", quaywebhook.DefaultShutdownTimeout,
+		"Time given to in-flight admission requests to complete on shutdown.")
+	flag.StringVar(&debugAddr, "debug-bind-address", "",
+		"The address the pprof and expvar debug endpoints bind to. Disabled when empty. Addresses other than loopback require --debug-token-file and --debug-cert-dir.")
+	flag.StringVar(&debugTokenFile, "debug-token-file", "",
+		"File containing the bearer token required to access the debug endpoints.")
+	flag.StringVar(&debugCertDir, "debug-cert-dir", "",
+		"Directory containing the tls.crt and tls.key files used to serve the debug endpoints over TLS.")
+	flag.StringVar(&adminAddr, "admin-bind-address", "",
+		"The address the admin API, used to resynchronize namespaces, rotate robot accounts and verify integrations on demand, binds to. Disabled when empty. Requires --admin-token-file. Addresses other than loopback require --admin-cert-dir.")
+	flag.StringVar(&adminTokenFile, "admin-token-file", "",
+		"File containing the bearer token required to access the admin API.")
+	flag.StringVar(&adminCertDir, "admin-cert-dir", "",
+		"Directory containing the tls.crt and tls.key files used to serve the admin API over TLS.")
+	flag.DurationVar(&usageReportInterval, "usage-report-interval", 0,
+		"Interval at which the repository count and storage consumption of each Quay organization are recorded as annotations of its namespace and as metrics. Disabled when 0.")
+	flag.DurationVar(&warmupWindow, "warmup-window", 0,
+		"Window over which the synchronization of the namespaces existing when the operator starts is spread. Namespaces missing their Secrets are synchronized first. Disabled when 0.")
+	flag.Float64Var(&routineSyncQPS, "routine-sync-qps", 5,
+		"Maximum rate of routine namespace synchronizations, such as periodic resyncs, leaving room for namespaces created or changed by users. Not limited when 0.")
+	flag.BoolVar(&enableProjectAnnotation, "enable-project-annotation", false,
+		"Annotate the namespaces of projects created through project requests with their Quay organization and mark their provisioning as pending until they are synchronized.")
+	flag.BoolVar(&enablePullSecretInjection, "enable-pull-secret-injection", false,
+		"Add the pull secret of the default service account of synchronized namespaces to the Pods pulling images from Quay using a mutating webhook, covering Pods running as service accounts the pull secret is not linked to.")
+	flag.StringVar(&buildRepositoryCreation, "build-repository-creation", string(quaywebhook.AutoRepositoryCreation),
+		"When the Quay repository a Build is pushed to is created as the Build is admitted, so that its first push does not depend on Quay creating repositories on push. One of Never, Auto (when Quay creates public repositories on push) or Always.")
+	flag.DurationVar(&buildRepositoryCreationTimeout, "build-repository-creation-timeout", quaywebhook.DefaultRepositoryCreationTimeout,
+		"How long the admission of a Build waits for its Quay repository to be created, at most 5s. The creation continues in the background afterwards.")
+	flag.DurationVar(&repositoryStatsInterval, "repository-stats-interval", 0,
+		"Interval at which the pulls and pushes of each repository of the Quay organizations are recorded as metrics. Disabled when 0.")
+	flag.DurationVar(&repositoryStatsWindow, "repository-stats-window", 30*24*time.Hour,
+		"Period of Quay logs covered by the repository statistics.")
+	flag.Float64Var(&repositoryStatsQPS, "repository-stats-qps", 1,
+		"Maximum number of requests per second made against Quay when retrieving repository statistics.")
+	flag.DurationVar(&auditLogInterval, "audit-log-interval", 0,
+		"Interval at which the audit logs of the Quay organizations are polled for changes made outside of the operator. Disabled when 0.")
+	flag.DurationVar(&baseImageTriggerInterval, "base-image-trigger-interval", 0,
+		"Interval at which the Quay base images of BuildConfigs annotated with quay.openshift.io/base-image-trigger are checked for changes triggering a rebuild. Disabled when 0.")
+	flag.DurationVar(&credentialCheckInterval, "credential-check-interval", 0,
+		"Interval at which the credentials of the robot account Secrets of managed namespaces are verified against Quay, repairing rejected credentials. Disabled when 0.")
+	flag.StringVar(&shortLivedCredentialsTokenFile, "short-lived-credentials-token-file", federation.DefaultTokenFile,
+		"The OIDC token of the operator exchanged for short lived robot account tokens by QuayIntegrations using short lived credentials, such as a projected service account token with the audience expected by Quay.")
+	flag.DurationVar(&shortLivedCredentialsRefreshInterval, "short-lived-credentials-refresh-interval", time.Minute,
+		"Interval at which the robot account Secrets containing short lived tokens are checked for tokens due for renewal. Must be shorter than the third of the lifetime of the tokens. Disabled when 0.")
+	flag.DurationVar(&imageScanInterval, "image-scan-interval", 0,
+		"Interval at which the image references of the Pods, BuildConfigs and ImageStreams of managed namespaces are scanned for references to the internal registry or to unmanaged registries. Disabled when 0.")
+	flag.StringVar(&imageScanAllowedRegistries, "image-scan-allowed-registries", "",
+		"Comma separated list of registries, optionally followed by a repository path, allowed alongside Quay by the image reference scanner, such as registry.redhat.io.")
+	flag.BoolVar(&enableSecretProtection, "enable-secret-protection", true,
+		"Watch the robot account Secrets of managed namespaces to recreate them as soon as they are deleted and revert edits made outside of the operator.")
+	flag.BoolVar(&enableSecretProtectionWebhook, "enable-secret-protection-webhook", false,
+		"Warn users changing or deleting the robot account Secrets managed by the operator using a validating webhook.")
+	flag.StringVar(&mode, "mode", allMode,
+		"Components run by the operator: all, controllers or webhook. Running the controllers and the webhook as separate deployments lets the webhook scale independently.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
+	transportOpts := qclient.NewTransportOptions()
+	transportOpts.BindFlags(flag.CommandLine)
+	managerOpts := manageroptions.NewOptions()
+	managerOpts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	// Flags not set on the command line may be set from the environment, letting the operator be tuned through its Subscription.
+	// They are applied before the logger is built so that the zap flags can be set from the environment as well
+	envErr := manageroptions.SetFlagsFromEnvironment(flag.CommandLine, os.LookupEnv)
+
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	if envErr != nil {
+		setupLog.Error(envErr, "invalid environment")
+		os.Exit(1)
+	}
+
+	if err := managerOpts.Validate(); err != nil {
+		setupLog.Error(err, "invalid manager options")
+		os.Exit(1)
+	}
+
+	if mode != allMode && mode != controllersMode && mode != webhookMode {
+		setupLog.Error(fmt.Errorf("unknown mode %q", mode), "invalid --mode")
+		os.Exit(1)
+	}
+
+	if repositoryStatsInterval > 0 && repositoryStatsQPS <= 0 {
+		setupLog.Error(fmt.Errorf("requests per second must be positive, got %v", repositoryStatsQPS), "invalid --repository-stats-qps")
+		os.Exit(1)
+	}
+
+	repositoryCreationMode, err := quaywebhook.ParseRepositoryCreationMode(buildRepositoryCreation)
+
+	if err != nil {
+		setupLog.Error(err, "invalid --build-repository-creation")
+		os.Exit(1)
+	}
+
+	if err := quaywebhook.ValidateBuildAdmissionTimeout(buildRepositoryCreationTimeout); err != nil {
+		setupLog.Error(err, "invalid --build-repository-creation-timeout")
+		os.Exit(1)
+	}
+
+	namespaces := getWatchNamespaces(watchNamespaces)
+
+	features := rbac.Features{
+		BuildSync:                   enableBuildSync,
+		Monitoring:                  enableMonitoring,
+		GrafanaDashboard:            enableGrafanaDashboard,
+		SecretCache:                 !scopeCache && len(namespaces) == 0,
+		Impersonation:               impersonationServiceAccount != "",
+		ImpersonationServiceAccount: impersonationServiceAccount,
+		ImagePolicy:                 enableImagePolicies,
+		BuildRecovery:               enableBuildRecovery && !scopeCache,
+		GlobalPullSecret:            enableGlobalPullSecret,
+		ReaderRobot:                 enableReaderRobot,
+		PullGrants:                  enablePullGrants,
+		BaseImageTrigger:            baseImageTriggerInterval > 0,
+		SecretProtection:            enableSecretProtection,
+		WebhookCertificates:         manageWebhookCertificates,
+		Namespaces:                  namespaces,
+	}
+
+	if printRBAC {
+		if err := rbac.WriteManifests(os.Stdout, rbac.Manifests("manager-role", features)); err != nil {
+			setupLog.Error(err, "unable to print RBAC")
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if renderManifests {
+		values, err := manifests.ReadValues(renderValues)
+
+		if err != nil {
+			setupLog.Error(err, "unable to read values")
+			os.Exit(1)
+		}
+
+		objs, err := manifests.Render(manifests.Options{
+			Values:                    values,
+			Args:                      getDeploymentArgs(),
+			Features:                  features,
+			ImpersonationClusterRole:  impersonationClusterRole,
+			ProjectAnnotation:         enableProjectAnnotation,
+			PullSecretInjection:       enablePullSecretInjection,
+			SecretProtectionWebhook:   enableSecretProtectionWebhook,
+			ManageWebhookCertificates: manageWebhookCertificates,
+			CRD:                       config.QuayIntegrationCRD,
+			WebhookConfigurations:     config.WebhookConfigurations,
+			NamespaceWriterRole:       config.NamespaceWriterRole,
+
+			PullSecretInjectionWebhookConfiguration: config.PullSecretInjectionWebhookConfiguration,
+			SecretProtectionWebhookConfiguration:    config.SecretProtectionWebhookConfiguration,
+		})
+
+		if err != nil {
+			setupLog.Error(err, "unable to render manifests")
+			os.Exit(1)
+		}
+
+		if err := rbac.WriteManifests(os.Stdout, objs); err != nil {
+			setupLog.Error(err, "unable to print manifests")
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	managerOptions := ctrl.Options{
 		Scheme:                     scheme,
 		MetricsBindAddress:         metricsAddr,
-		Port:                       9443,
 		HealthProbeBindAddress:     probeAddr,
-		LeaderElection:             enableLeaderElection,
+		LeaderElection:             enableLeaderElection && mode != webhookMode,
 		LeaderElectionResourceLock: "configmaps",
 		LeaderElectionID:           "0111fb36.redhat.com",
-	})
+	}
+
+	// The webhook server drains in-flight requests before the manager returns
+	gracefulShutdownTimeout := webhookShutdownDelay + webhookShutdownTimeout + 5*time.Second
+	managerOptions.GracefulShutdownTimeout = &gracefulShutdownTimeout
+
+	selectors := map[client.Object]cachescope.Selector{}
+
+	// Large clusters contain far more Secrets and Builds than are managed by the operator
+	if scopeCache {
+		selectors[&buildv1.Build{}] = cachescope.Selector{Label: labels.SelectorFromSet(labels.Set{constants.BuildOperatorManagedLabel: "true"})}
+	}
+
+	// Only the robot account Secrets are watched when Secrets are not cached
+	if enableSecretProtection && !features.SecretCache {
+		selectors[&corev1.Secret{}] = cachescope.Selector{Label: labels.SelectorFromSet(labels.Set{constants.ManagedSecretLabel: "true"})}
+	}
+
+	// Secrets may reside outside of the watched namespaces, such as the Quay credentials
+	if !features.SecretCache {
+		managerOptions.NewCache = cachescope.NewCacheFunc(namespaces, selectors)
+		managerOptions.ClientDisableCacheFor = []client.Object{&corev1.Secret{}}
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	managerOpts.Apply(&managerOptions, restConfig)
+
+	mgr, err := ctrl.NewManager(restConfig, managerOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&controllers.QuayIntegrationReconciler{
-		ReconcilerBase: util.NewReconcilerBase(mgr.GetClient(), mgr.GetScheme(), mgr.GetConfig(), mgr.GetEventRecorderFor("QuayIntegration_controller"), mgr.GetAPIReader()),
-		Log:            ctrl.Log.WithName("controllers").WithName("QuayIntegration"),
-		LastSeenSpec:   map[types.NamespacedName]string{},
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "QuayIntegration")
-		os.Exit(1)
+	// Quay clients share pooled connections across controllers
+	httpClientPool := qclient.NewHTTPClientPool(transportOpts)
+
+	// Refresh tokens rotated by Quay are written back to the credentials Secret, so that they survive a restart of the operator
+	httpClientPool.SetRefreshTokenStore(state.NewRefreshTokenStore(mgr.GetAPIReader(), mgr.GetClient()))
+
+	// Features detected on Quay are shared across controllers
+	capabilityStore := capabilities.NewStore()
+
+	// Controllers wait until Quay is ready
+	readinessGate := readiness.NewGate(mgr.GetAPIReader(), httpClientPool, ctrl.Log.WithName("readiness"))
+
+	if exportStatePath != "" || importStatePath != "" {
+		if err := runStateCommand(mgr.GetAPIReader(), httpClientPool, stateQuayIntegration, exportStatePath, importStatePath); err != nil {
+			setupLog.Error(err, "unable to process Quay state")
+			os.Exit(1)
+		}
+
+		os.Exit(0)
 	}
 
-	if err = (&controllers.NamespaceIntegrationReconciler{
-		CoreComponents: core.NewCoreComponents(util.NewReconcilerBase(mgr.GetClient(), mgr.GetScheme(), mgr.GetConfig(), mgr.GetEventRecorderFor("NamespaceIntegration_controller"), mgr.GetAPIReader())),
-		Log:            ctrl.Log.WithName("controllers").WithName("NamespaceIntegration"),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "NamespaceIntegration")
-		os.Exit(1)
+	if orphanCleanup != "" {
+		if err := runOrphanCleanup(reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("OrphanCleanup")), orphanCleanup); err != nil {
+			setupLog.Error(err, "unable to clean up orphaned resources")
+			os.Exit(1)
+		}
+
+		os.Exit(0)
 	}
 
-	if err = (&controllers.BuildIntegrationReconciler{
-		CoreComponents: core.NewCoreComponents(util.NewReconcilerBase(mgr.GetClient(), mgr.GetScheme(), mgr.GetConfig(), mgr.GetEventRecorderFor("BuildIntegration_controller"), mgr.GetAPIReader())),
-		Log:            ctrl.Log.WithName("controllers").WithName("BuildIntegration"),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "BuildIntegration")
-		os.Exit(1)
+	if decommissionReport {
+		if err := runDecommissionReport(mgr.GetAPIReader(), internalRegistryHostnames); err != nil {
+			setupLog.Error(err, "unable to report references to the internal registry")
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	if debugAddr != "" {
+		debugToken := ""
+
+		if debugTokenFile != "" {
+			token, err := ioutil.ReadFile(debugTokenFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read debug token")
+				os.Exit(1)
+			}
+
+			debugToken = strings.TrimSpace(string(token))
+		}
+
+		if err := httpauth.ValidateBindAddress("debug endpoints", debugAddr, debugToken, debugCertDir); err != nil {
+			setupLog.Error(err, "invalid --debug-bind-address")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(&debug.Server{BindAddress: debugAddr, Token: debugToken, CertDir: debugCertDir}); err != nil {
+			setupLog.Error(err, "unable to set up debug endpoints")
+			os.Exit(1)
+		}
+	}
+
+	// Replicas serving the webhook only are not elected and do not reconcile
+	if mode != webhookMode {
+
+		var jobQueue *jobs.Queue
+
+		if enableJobQueue {
+			if operatorNamespace, err := reconcilerbase.OperatorNamespace(); err != nil {
+				setupLog.Info("The namespace of the operator is unknown, running long operations in the foreground", "error", err.Error())
+			} else {
+				jobQueue = jobs.NewQueue(&jobs.ConfigMapStore{
+					Client:    mgr.GetClient(),
+					Reader:    mgr.GetAPIReader(),
+					Namespace: operatorNamespace,
+					Name:      jobs.DefaultConfigMapName,
+				}, ctrl.Log.WithName("jobs"))
+
+				if err := mgr.Add(jobQueue); err != nil {
+					setupLog.Error(err, "unable to set up job queue")
+					os.Exit(1)
+				}
+			}
+		}
+
+		// Controllers wait until resources created by previous versions of the operator have been migrated
+		if enableMigrations {
+			if operatorNamespace, err := reconcilerbase.OperatorNamespace(); err != nil {
+				setupLog.Info("The namespace of the operator is unknown, skipping migrations", "error", err.Error())
+			} else {
+				migrationRunner := migration.NewRunner(&jobs.ConfigMapStore{
+					Client:    mgr.GetClient(),
+					Reader:    mgr.GetAPIReader(),
+					Namespace: operatorNamespace,
+					Name:      migration.DefaultConfigMapName,
+				}, mgr.GetClient(), mgr.GetAPIReader(), version.Version, ctrl.Log.WithName("migration"))
+
+				if err := mgr.Add(migrationRunner); err != nil {
+					setupLog.Error(err, "unable to set up migrations")
+					os.Exit(1)
+				}
+
+				readinessGate.Prerequisites = append(readinessGate.Prerequisites, migrationRunner)
+			}
+		}
+
+		if err = (&controllers.QuayIntegrationReconciler{
+			ReconcilerBase:          reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("QuayIntegration_controller")),
+			Log:                     ctrl.Log.WithName("controllers").WithName("QuayIntegration"),
+			LastSeenSpec:            map[types.NamespacedName]string{},
+			HTTPClientPool:          httpClientPool,
+			Capabilities:            capabilityStore,
+			RequiredQuayFeatures:    requiredQuayFeatures(repositoryStatsInterval),
+			BuildRepositoryCreation: repositoryCreationMode,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "QuayIntegration")
+			os.Exit(1)
+		}
+
+		if err = (&controllers.NamespaceIntegrationReconciler{
+			CoreComponents: core.NewCoreComponents(reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("NamespaceIntegration_controller"))),
+			Log:            ctrl.Log.WithName("controllers").WithName("NamespaceIntegration"),
+			HTTPClientPool: httpClientPool,
+			ReadinessGate:  readinessGate,
+			Warmup:         readiness.NewWarmup(warmupWindow),
+			Namespaces:     namespaces,
+
+			RoutineThrottle:             priority.NewThrottle(routineSyncQPS),
+			ImpersonationServiceAccount: impersonationServiceAccount,
+			ImpersonationClusterRole:    impersonationClusterRole,
+			GlobalPullSecret:            enableGlobalPullSecret,
+			ReaderRobot:                 enableReaderRobot,
+			PullGrants:                  enablePullGrants,
+			SecretProtection:            enableSecretProtection,
+			Jobs:                        jobQueue,
+			ShortLivedCredentials:       federation.NewProvider(shortLivedCredentialsTokenFile),
+			Capabilities:                capabilityStore,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "NamespaceIntegration")
+			os.Exit(1)
+		}
+
+		if enableBuildSync {
+			if err = (&controllers.BuildIntegrationReconciler{
+				CoreComponents: core.NewCoreComponents(reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("BuildIntegration_controller"))),
+				Log:            ctrl.Log.WithName("controllers").WithName("BuildIntegration"),
+				ReadinessGate:  readinessGate,
+				Namespaces:     namespaces,
+				HTTPClientPool: httpClientPool,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "BuildIntegration")
+				os.Exit(1)
+			}
+		}
+
+		// Builds missed by the webhook are not labeled as managed and are not cached when the cache is scoped
+		if enableBuildRecovery && scopeCache {
+			setupLog.Info("Build recovery is not available when the cache is scoped, ignoring --enable-build-recovery")
+		} else if enableBuildRecovery {
+			buildRecovery := &controllers.BuildRecoveryReconciler{
+				CoreComponents: core.NewCoreComponents(reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("BuildRecovery_controller"))),
+				Log:            ctrl.Log.WithName("controllers").WithName("BuildRecovery"),
+				Namespaces:     namespaces,
+			}
+
+			if err = buildRecovery.SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "BuildRecovery")
+				os.Exit(1)
+			}
+
+			if buildBackfillInterval > 0 {
+				if err := mgr.Add(&controllers.BuildBackfill{
+					Recovery:   buildRecovery,
+					Log:        ctrl.Log.WithName("controllers").WithName("BuildBackfill"),
+					Namespaces: namespaces,
+					Interval:   buildBackfillInterval,
+					Window:     buildBackfillWindow,
+				}); err != nil {
+					setupLog.Error(err, "unable to set up Build backfill", "controller", "BuildBackfill")
+					os.Exit(1)
+				}
+			}
+		}
+
+		if enableImagePolicies {
+			if err = (&controllers.ImagePolicyReconciler{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("ImagePolicy_controller")),
+				Log:            ctrl.Log.WithName("controllers").WithName("ImagePolicy"),
+				Namespaces:     namespaces,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "ImagePolicy")
+				os.Exit(1)
+			}
+		}
+
+		if enableReaderRobot {
+			if err = (&controllers.ReaderRobotReconciler{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("ReaderRobot_controller")),
+				Log:            ctrl.Log.WithName("controllers").WithName("ReaderRobot"),
+				HTTPClientPool: httpClientPool,
+				Namespaces:     namespaces,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "ReaderRobot")
+				os.Exit(1)
+			}
+		}
+
+		if err = (&controllers.ClusterIDMigrationReconciler{
+			ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("ClusterIDMigration_controller")),
+			Log:            ctrl.Log.WithName("controllers").WithName("ClusterIDMigration"),
+			HTTPClientPool: httpClientPool,
+			Jobs:           jobQueue,
+			Namespaces:     namespaces,
+			Capabilities:   capabilityStore,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterIDMigration")
+			os.Exit(1)
+		}
+
+		if err = (&controllers.RegistryHostnameMigrationReconciler{
+			ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("RegistryHostnameMigration_controller")),
+			Log:            ctrl.Log.WithName("controllers").WithName("RegistryHostnameMigration"),
+			Namespaces:     namespaces,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RegistryHostnameMigration")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(&controllers.OfflineQueue{
+			ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("OfflineQueue")),
+			Log:            ctrl.Log.WithName("controllers").WithName("OfflineQueue"),
+			HTTPClientPool: httpClientPool,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up offline queue", "controller", "OfflineQueue")
+			os.Exit(1)
+		}
+
+		if usageReportInterval > 0 {
+			if err := mgr.Add(&controllers.UsageReporter{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("UsageReporter")),
+				Log:            ctrl.Log.WithName("controllers").WithName("UsageReporter"),
+				HTTPClientPool: httpClientPool,
+				Namespaces:     namespaces,
+				Interval:       usageReportInterval,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up usage reporting", "controller", "UsageReporter")
+				os.Exit(1)
+			}
+		}
+
+		if repositoryStatsInterval > 0 {
+			if err := mgr.Add(&controllers.RepositoryStatsReporter{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("RepositoryStatsReporter")),
+				Log:            ctrl.Log.WithName("controllers").WithName("RepositoryStatsReporter"),
+				HTTPClientPool: httpClientPool,
+				Namespaces:     namespaces,
+				Interval:       repositoryStatsInterval,
+				Window:         repositoryStatsWindow,
+				RateLimiter:    flowcontrol.NewTokenBucketRateLimiter(float32(repositoryStatsQPS), 1),
+				Capabilities:   capabilityStore,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up repository statistics", "controller", "RepositoryStatsReporter")
+				os.Exit(1)
+			}
+		}
+
+		if auditLogInterval > 0 {
+			if err := mgr.Add(&controllers.AuditLogForwarder{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("AuditLogForwarder")),
+				Log:            ctrl.Log.WithName("controllers").WithName("AuditLogForwarder"),
+				HTTPClientPool: httpClientPool,
+				Namespaces:     namespaces,
+				Interval:       auditLogInterval,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up audit log forwarding", "controller", "AuditLogForwarder")
+				os.Exit(1)
+			}
+		}
+
+		if baseImageTriggerInterval > 0 {
+			if err := mgr.Add(&controllers.BaseImageTrigger{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("BaseImageTrigger")),
+				Log:            ctrl.Log.WithName("controllers").WithName("BaseImageTrigger"),
+				HTTPClientPool: httpClientPool,
+				Namespaces:     namespaces,
+				Interval:       baseImageTriggerInterval,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up base image triggers", "controller", "BaseImageTrigger")
+				os.Exit(1)
+			}
+		}
+
+		if credentialCheckInterval > 0 {
+			if err := mgr.Add(&controllers.CredentialVerifier{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("CredentialVerifier")),
+				Log:            ctrl.Log.WithName("controllers").WithName("CredentialVerifier"),
+				HTTPClientPool: httpClientPool,
+				Namespaces:     namespaces,
+				Interval:       credentialCheckInterval,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up credential verification", "controller", "CredentialVerifier")
+				os.Exit(1)
+			}
+		}
+
+		if shortLivedCredentialsRefreshInterval > 0 {
+			if err := mgr.Add(&controllers.CredentialRefresher{
+				ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("CredentialRefresher")),
+				Log:            ctrl.Log.WithName("controllers").WithName("CredentialRefresher"),
+				Namespaces:     namespaces,
+				Interval:       shortLivedCredentialsRefreshInterval,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up short lived credential renewal", "controller", "CredentialRefresher")
+				os.Exit(1)
+			}
+		}
+
+		if imageScanInterval > 0 {
+			imageScanner := &controllers.ImageScanner{
+				ReconcilerBase:            reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("ImageScanner")),
+				Log:                       ctrl.Log.WithName("controllers").WithName("ImageScanner"),
+				Namespaces:                namespaces,
+				Interval:                  imageScanInterval,
+				InternalRegistryHostnames: splitList(internalRegistryHostnames),
+				AllowedRegistries:         splitList(imageScanAllowedRegistries),
+			}
+
+			if operatorNamespace, err := reconcilerbase.OperatorNamespace(); err != nil {
+				setupLog.Info("The namespace of the operator is unknown, only reporting image references as metrics", "error", err.Error())
+			} else {
+				imageScanner.Store = &jobs.ConfigMapStore{
+					Client:    mgr.GetClient(),
+					Reader:    mgr.GetAPIReader(),
+					Namespace: operatorNamespace,
+					Name:      imagescan.DefaultConfigMapName,
+				}
+			}
+
+			if err := mgr.Add(imageScanner); err != nil {
+				setupLog.Error(err, "unable to set up image reference scanning", "controller", "ImageScanner")
+				os.Exit(1)
+			}
+		}
+
+		if adminAddr != "" {
+			adminToken := ""
+
+			if adminTokenFile != "" {
+				token, err := ioutil.ReadFile(adminTokenFile)
+				if err != nil {
+					setupLog.Error(err, "unable to read admin token")
+					os.Exit(1)
+				}
+
+				adminToken = strings.TrimSpace(string(token))
+			}
+
+			if err := admin.ValidateToken(adminToken); err != nil {
+				setupLog.Error(err, "invalid --admin-token-file")
+				os.Exit(1)
+			}
+
+			if err := httpauth.ValidateBindAddress("admin API", adminAddr, adminToken, adminCertDir); err != nil {
+				setupLog.Error(err, "invalid --admin-bind-address")
+				os.Exit(1)
+			}
+
+			if err := mgr.Add(&admin.Server{
+				BindAddress: adminAddr,
+				Token:       adminToken,
+				CertDir:     adminCertDir,
+				Executor: &controllers.AdminExecutor{
+					ReconcilerBase: reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("AdminExecutor")),
+					HTTPClientPool: httpClientPool,
+					Namespaces:     namespaces,
+				},
+			}); err != nil {
+				setupLog.Error(err, "unable to set up admin API")
+				os.Exit(1)
+			}
+		}
 	}
 
 	// Enable Webhook support
 	_, disableWebhookEnv := os.LookupEnv(constants.DisableWebhookEnvVar)
+	webhookCertPath := ""
+
+	if !disableWebhookEnv && mode != controllersMode {
+
+		// Register Webhook. Webhooks are served outside of the manager webhook server to drain in-flight requests on shutdown
+		webhookSvr := quaywebhook.NewServer(managerOpts.WebhookPort, getWebhookCertDir(managerOpts.WebhookCertDir), constants.WebhookCertName, constants.WebhookKeyName)
+		webhookSvr.ShutdownDelay = webhookShutdownDelay
+		webhookSvr.ShutdownTimeout = webhookShutdownTimeout
+
+		if manageWebhookCertificates {
+
+			operatorNamespace, err := reconcilerbase.OperatorNamespace()
+
+			if err != nil {
+				setupLog.Error(err, "unable to manage webhook certificates")
+				os.Exit(1)
+			}
+
+			// The certificate is issued before the manager starts, which the client of the manager requires
+			certificateClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+
+			if err != nil {
+				setupLog.Error(err, "unable to manage webhook certificates")
+				os.Exit(1)
+			}
+
+			certificateManager := &quaywebhook.CertificateManager{
+				Client:        certificateClient,
+				Namespace:     operatorNamespace,
+				SecretName:    webhookCertSecret,
+				ServiceName:   webhookServiceName,
+				CertDir:       webhookSvr.CertDir,
+				CertName:      webhookSvr.CertName,
+				KeyName:       webhookSvr.KeyName,
+				CheckInterval: quaywebhook.DefaultCertificateCheckInterval,
+			}
+
+			// Replicas starting concurrently conflict writing the Secret
+			if err := wait.ExponentialBackoff(wait.Backoff{Duration: time.Second, Factor: 2, Steps: 5}, func() (bool, error) {
+				if err := certificateManager.Ensure(context.Background()); err != nil {
+					setupLog.Error(err, "unable to issue webhook certificate, retrying")
+					return false, nil
+				}
+
+				return true, nil
+			}); err != nil {
+				setupLog.Error(err, "unable to issue webhook certificate")
+				os.Exit(1)
+			}
+
+			if err := mgr.Add(certificateManager); err != nil {
+				setupLog.Error(err, "unable to set up webhook certificate rotation")
+				os.Exit(1)
+			}
+		}
+
+		mutator := &quaywebhook.QuayIntegrationMutator{Client: mgr.GetClient(), Log: ctrl.Log.WithName("webhook").WithName("QuayIntegration"), Namespaces: namespaces}
+
+		if repositoryCreationMode != quaywebhook.NeverRepositoryCreation {
+			mutator.RepositoryCreator = quaywebhook.NewRepositoryCreator(mgr.GetClient(), httpClientPool, repositoryCreationMode, capabilityStore, buildRepositoryCreationTimeout)
+		}
+
+		admissionWebhook := &webhook.Admission{Handler: mutator}
+
+		if err := mgr.SetFields(admissionWebhook); err != nil {
+			setupLog.Error(err, "unable to set up webhook")
+			os.Exit(1)
+		}
+
+		webhookSvr.Register("/admissionwebhook", admissionWebhook)
+
+		validatingWebhook := &webhook.Admission{Handler: &quaywebhook.QuayIntegrationValidator{}}
 
-	if !disableWebhookEnv {
+		if err := mgr.SetFields(validatingWebhook); err != nil {
+			setupLog.Error(err, "unable to set up webhook")
+			os.Exit(1)
+		}
 
-		// Register Webhook
-		webhookSvr := mgr.GetWebhookServer()
-		webhookSvr.CertDir = getWebhookCertDir()
-		webhookSvr.CertName = constants.WebhookCertName
-		webhookSvr.KeyName = constants.WebhookKeyName
-		webhookSvr.Register("/admissionwebhook", &webhook.Admission{Handler: &quaywebhook.QuayIntegrationMutator{Client: mgr.GetClient(), Log: ctrl.Log.WithName("webhook").WithName("QuayIntegration")}})
+		webhookSvr.Register("/validate-quayintegration", validatingWebhook)
+
+		projectWebhook := &webhook.Admission{Handler: &quaywebhook.ProjectAnnotator{Client: mgr.GetClient(), Log: ctrl.Log.WithName("webhook").WithName("Project"), Enabled: enableProjectAnnotation, Namespaces: namespaces}}
+
+		if err := mgr.SetFields(projectWebhook); err != nil {
+			setupLog.Error(err, "unable to set up webhook")
+			os.Exit(1)
+		}
+
+		webhookSvr.Register("/mutate-namespace", projectWebhook)
+
+		podWebhook := &webhook.Admission{Handler: &quaywebhook.PullSecretInjector{Client: mgr.GetClient(), Log: ctrl.Log.WithName("webhook").WithName("Pod"), Enabled: enablePullSecretInjection, Namespaces: namespaces}}
+
+		if err := mgr.SetFields(podWebhook); err != nil {
+			setupLog.Error(err, "unable to set up webhook")
+			os.Exit(1)
+		}
+
+		webhookSvr.Register("/mutate-pod", podWebhook)
+
+		secretValidator := &quaywebhook.ManagedSecretValidator{Enabled: enableSecretProtectionWebhook, ImpersonationServiceAccount: impersonationServiceAccount}
+
+		if operatorNamespace, err := reconcilerbase.OperatorNamespace(); err == nil {
+			secretValidator.OperatorNamespace = operatorNamespace
+		}
+
+		secretWebhook := &webhook.Admission{Handler: secretValidator}
+
+		if err := mgr.SetFields(secretWebhook); err != nil {
+			setupLog.Error(err, "unable to set up webhook")
+			os.Exit(1)
+		}
+
+		webhookSvr.Register("/validate-secret", secretWebhook)
+
+		if err := mgr.Add(webhookSvr); err != nil {
+			setupLog.Error(err, "unable to set up webhook server")
+			os.Exit(1)
+		}
+
+		if err := mgr.AddReadyzCheck("webhook", webhookSvr.ReadyzCheck); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+
+		webhookCertPath = filepath.Join(webhookSvr.CertDir, webhookSvr.CertName)
+
+		if err := metrics.RegisterWebhookCertificateCollector(webhookCertPath); err != nil {
+			setupLog.Error(err, "unable to register webhook certificate metrics")
+			os.Exit(1)
+		}
 
 	}
 
+	if mode != webhookMode {
+
+		if enableMonitoring || enableGrafanaDashboard {
+			if err := mgr.Add(&monitoring.MonitoringReconciler{
+				ReconcilerBase:     reconcilerbase.NewFromManager(mgr, mgr.GetEventRecorderFor("Monitoring_controller")),
+				Log:                ctrl.Log.WithName("controllers").WithName("Monitoring"),
+				ProvisionAlerting:  enableMonitoring,
+				ProvisionDashboard: enableGrafanaDashboard,
+			}); err != nil {
+				setupLog.Error(err, "unable to set up monitoring", "controller", "Monitoring")
+				os.Exit(1)
+			}
+		}
+
+		if err := mgr.Add(readinessGate); err != nil {
+			setupLog.Error(err, "unable to set up Quay readiness gate")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(&health.HealthReconciler{
+			Client:           mgr.GetClient(),
+			Cache:            mgr.GetCache(),
+			Log:              ctrl.Log.WithName("controllers").WithName("Health"),
+			WebhookCertPath:  webhookCertPath,
+			BacklogThreshold: healthBacklogThreshold,
+			ReadinessGate:    readinessGate,
+			Features:         features.Names(),
+			Permissions:      rbac.Rules(features),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up health reporting", "controller", "Health")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -150,8 +1009,204 @@ func main() {
 
 }
 
-func getWebhookCertDir() string {
-	webhookCertDir := os.Getenv(constants.WebHookCertDirEnv)
+func runStateCommand(reader client.Reader, httpClientPool *qclient.HTTPClientPool, quayIntegrationName string, exportStatePath string, importStatePath string) error {
+
+	ctx := context.Background()
+
+	quayIntegration, err := state.GetQuayIntegration(ctx, reader, quayIntegrationName)
+
+	if err != nil {
+		return err
+	}
+
+	quayClient, err := state.NewQuayClient(ctx, reader, quayIntegration, httpClientPool)
+
+	if err != nil {
+		return err
+	}
+
+	if exportStatePath != "" {
+
+		namespaces, err := state.ManagedOrganizationNamespaces(ctx, reader, quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		manifest, err := state.Export(quayClient, quayIntegration, namespaces)
+
+		if err != nil {
+			return err
+		}
+
+		if err := state.WriteManifest(exportStatePath, manifest); err != nil {
+			return err
+		}
+
+		setupLog.Info("exported Quay state", "file", exportStatePath, "organizations", len(manifest.Organizations))
+	}
+
+	if importStatePath != "" {
+
+		manifest, err := state.ReadManifest(importStatePath)
+
+		if err != nil {
+			return err
+		}
+
+		if err := state.Import(quayClient, quayIntegration, manifest); err != nil {
+			return err
+		}
+
+		setupLog.Info("imported Quay state", "file", importStatePath, "organizations", len(manifest.Organizations))
+	}
+
+	return nil
+}
+
+// runOrphanCleanup prints the inventory of the resources created by the operator and deletes the orphaned resources unless
+// running a dry run
+func runOrphanCleanup(reconcilerBase reconcilerbase.ReconcilerBase, mode string) error {
+
+	if mode != inventory.DryRunCleanup && mode != inventory.DeleteCleanup {
+		return fmt.Errorf("invalid --orphan-cleanup '%s', must be %s or %s", mode, inventory.DryRunCleanup, inventory.DeleteCleanup)
+	}
+
+	ctx := context.Background()
+
+	inventoryState, err := inventory.LoadState(ctx, reconcilerBase.GetAPIReader())
+
+	if err != nil {
+		return err
+	}
+
+	resources, err := inventory.List(ctx, reconcilerBase.GetAPIReader(), inventoryState)
+
+	if err != nil {
+		return err
+	}
+
+	if err := inventory.WriteReport(os.Stdout, resources); err != nil {
+		return err
+	}
+
+	orphans := inventory.Orphans(resources)
+
+	if mode == inventory.DryRunCleanup {
+		setupLog.Info("listed resources created by the operator, rerun with --orphan-cleanup=Delete to delete orphaned resources", "resources", len(resources), "orphans", len(orphans))
+		return nil
+	}
+
+	if _, err := reconcilerBase.DeleteResources(ctx, inventory.Objects(orphans)); err != nil {
+		return err
+	}
+
+	setupLog.Info("deleted orphaned resources", "orphans", len(orphans))
+
+	return nil
+}
+
+// runDecommissionReport prints the references to the internal registry remaining in the cluster
+func runDecommissionReport(reader client.Reader, internalRegistryHostnames string) error {
+
+	ctx := context.Background()
+
+	decommission, blockers, err := imagescan.NewDecommission(ctx, reader, splitList(internalRegistryHostnames), time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	references, err := imagescan.List(ctx, reader, "")
+
+	if err != nil {
+		return err
+	}
+
+	report := decommission.Report(references, blockers)
+
+	if err := imagescan.WriteDecommissionReport(os.Stdout, report); err != nil {
+		return err
+	}
+
+	if report.Ready {
+		setupLog.Info("no references to the internal registry remain, the internal registry can be disabled", "references", len(references))
+	} else {
+		setupLog.Info("references to the internal registry remain, the internal registry cannot be disabled yet", "residuals", len(report.Residuals), "blockers", len(report.Blockers))
+	}
+
+	return nil
+}
+
+// splitList parses a comma separated list, ignoring empty entries
+func splitList(value string) []string {
+
+	values := []string{}
+
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+
+	return values
+}
+
+// getWatchNamespaces parses the namespaces the operator is restricted to. The namespace of the operator is always watched
+func getWatchNamespaces(watchNamespaces string) []string {
+
+	namespaces := []string{}
+
+	for _, namespace := range strings.Split(watchNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return namespaces
+	}
+
+	if operatorNamespace, err := reconcilerbase.OperatorNamespace(); err == nil && !cachescope.InNamespaces(namespaces, operatorNamespace) {
+		namespaces = append(namespaces, operatorNamespace)
+	}
+
+	return namespaces
+}
+
+// requiredQuayFeatures maps the Quay features required by the enabled operator features to the flag enabling them
+func requiredQuayFeatures(repositoryStatsInterval time.Duration) map[string]string {
+
+	required := map[string]string{}
+
+	if repositoryStatsInterval > 0 {
+		required[capabilities.AggregatedLogCount] = "--repository-stats-interval"
+	}
+
+	return required
+}
+
+// getDeploymentArgs returns the flags set on the command line to pass to the rendered Deployment, always electing a leader
+func getDeploymentArgs() []string {
+
+	excluded := map[string]bool{"render-manifests": true, "render-values": true, "print-rbac": true, "leader-elect": true, "metrics-bind-address": true, "health-probe-bind-address": true, "webhook-port": true, "webhook-cert-dir": true, "webhook-cert-secret": true, "webhook-service": true}
+	args := []string{"--leader-elect"}
+
+	flag.Visit(func(f *flag.Flag) {
+		if !excluded[f.Name] {
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+		}
+	})
+
+	return args
+}
+
+func getWebhookCertDir(webhookCertDir string) string {
+	if webhookCertDir != "" {
+		return webhookCertDir
+	}
+
+	webhookCertDir = os.Getenv(constants.WebHookCertDirEnv)
 	if webhookCertDir != "" {
 		return webhookCertDir
 	}
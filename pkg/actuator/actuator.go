@@ -0,0 +1,79 @@
+// Package actuator defines the interfaces through which the operator changes Quay and the cluster. Products embedding the operator
+// can wrap the default implementations to perform additional actions, such as registering repositories in a CMDB, or replace
+// them entirely
+package actuator
+
+import (
+	"context"
+	"net/http"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// OrgActuator changes the organizations of Quay along with their robot accounts, default permissions and repositories.
+// Its methods match those of the Quay client, which is the default implementation
+type OrgActuator interface {
+	CreateOrganization(name string, email string) (qclient.StringValue, *http.Response, qclient.QuayApiError)
+	UpdateOrganizationEmail(name string, email string) (*http.Response, qclient.QuayApiError)
+	DeleteOrganization(orgName string) (*http.Response, qclient.QuayApiError)
+	AddOrganizationTeamMember(orgName string, teamName string, memberName string) (qclient.TeamMember, *http.Response, qclient.QuayApiError)
+
+	CreateOrganizationRobotAccountWithMetadata(organizationName string, robotName string, robotAccount qclient.RobotAccountRequest) (qclient.RobotAccount, *http.Response, qclient.QuayApiError)
+	RegenerateOrganizationRobotAccountToken(organizationName string, robotName string) (qclient.RobotAccount, *http.Response, qclient.QuayApiError)
+	DeleteOrganizationRobotAccount(organizationName string, robotName string) (*http.Response, qclient.QuayApiError)
+	CreateUserRobotAccountWithMetadata(robotName string, robotAccount qclient.RobotAccountRequest) (qclient.RobotAccount, *http.Response, qclient.QuayApiError)
+	DeleteUserRobotAccount(robotName string) (*http.Response, qclient.QuayApiError)
+
+	CreateRobotPermissionForOrganization(organizationName string, robotAccount string, role string) (qclient.Prototype, *http.Response, qclient.QuayApiError)
+	DeletePrototype(organizationName string, prototypeID string) (*http.Response, qclient.QuayApiError)
+
+	CreateRepository(namespace, name string) (qclient.RepositoryRequest, *http.Response, qclient.QuayApiError)
+	CreateRepositoryWithDescription(namespace, name string, description string) (qclient.RepositoryRequest, *http.Response, qclient.QuayApiError)
+	UpdateRepositoryDescription(orgName string, repositoryName string, description string) (*http.Response, qclient.QuayApiError)
+	CreateRepositoryMirror(orgName string, repositoryName string, mirror qclient.RepositoryMirror) (*http.Response, qclient.QuayApiError)
+	CreateRepositoryNotification(orgName string, repositoryName string, notification qclient.NotificationRequest) (qclient.Notification, *http.Response, qclient.QuayApiError)
+	DeleteRepositoryNotification(orgName string, repositoryName string, uuid string) (*http.Response, qclient.QuayApiError)
+	SetRepositoryUserPermission(orgName string, repositoryName string, username string, role string) (*http.Response, qclient.QuayApiError)
+	DeleteRepositoryUserPermission(orgName string, repositoryName string, username string) (*http.Response, qclient.QuayApiError)
+}
+
+var _ OrgActuator = &qclient.QuayClient{}
+
+// OrgActuatorFactory returns the OrgActuator changing Quay through the Quay client of a QuayIntegration
+type OrgActuatorFactory func(quayClient *qclient.QuayClient) OrgActuator
+
+// DefaultOrgActuator changes Quay directly through the Quay client
+func DefaultOrgActuator(quayClient *qclient.QuayClient) OrgActuator {
+	return quayClient
+}
+
+// SecretActuator writes and deletes the Secrets provisioned in namespaces. The writer is the ReconcilerBase writing within the
+// namespace, which impersonates a service account of the namespace when impersonation is enabled
+type SecretActuator interface {
+	ApplySecret(ctx context.Context, writer reconcilerbase.ReconcilerBase, namespace string, secret *corev1.Secret) error
+	DeleteSecret(ctx context.Context, writer reconcilerbase.ReconcilerBase, secret *corev1.Secret) error
+}
+
+// DefaultSecretActuator applies Secrets using server-side apply and deletes them if they exist
+type DefaultSecretActuator struct{}
+
+var _ SecretActuator = DefaultSecretActuator{}
+
+func (DefaultSecretActuator) ApplySecret(ctx context.Context, writer reconcilerbase.ReconcilerBase, namespace string, secret *corev1.Secret) error {
+	return writer.ApplyResource(ctx, nil, namespace, secret)
+}
+
+func (DefaultSecretActuator) DeleteSecret(ctx context.Context, writer reconcilerbase.ReconcilerBase, secret *corev1.Secret) error {
+	return writer.DeleteResourceIfExists(ctx, secret)
+}
+
+// BuildMutator returns the admission response for a Build submitted in a namespace synchronized with Quay, redirecting its
+// output to Quay. The BuildConfig of the Build, if any, and the namespace of its output ImageStream are provided
+type BuildMutator interface {
+	MutateBuild(build *buildv1.Build, buildConfig *buildv1.BuildConfig, namespace *corev1.Namespace, outputNamespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) *admissionv1.AdmissionResponse
+}
@@ -0,0 +1,380 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quay/quay-bridge-operator/pkg/httpauth"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+)
+
+const (
+	// apiPrefix is the path prefix of the endpoints of the admin API
+	apiPrefix = "/api/v1/"
+	// shutdownTimeout is the time given to in-flight requests to complete on shutdown
+	shutdownTimeout = 5 * time.Second
+	// defaultOperationTimeout bounds the time an operation may run
+	defaultOperationTimeout = 5 * time.Minute
+	// defaultRetainedOperations is the number of completed operations whose status remains available
+	defaultRetainedOperations = 100
+)
+
+// OperationType identifies an on-demand operation
+type OperationType string
+
+const (
+	ResyncNamespaceOperation   OperationType = "ResyncNamespace"
+	RotateRobotOperation       OperationType = "RotateRobot"
+	VerifyIntegrationOperation OperationType = "VerifyIntegration"
+)
+
+// OperationStatus is the progress of an operation
+type OperationStatus string
+
+const (
+	OperationPending   OperationStatus = "Pending"
+	OperationRunning   OperationStatus = "Running"
+	OperationSucceeded OperationStatus = "Succeeded"
+	OperationFailed    OperationStatus = "Failed"
+)
+
+// Operation is an on-demand operation requested through the admin API
+type Operation struct {
+	ID        string          `json:"id"`
+	Type      OperationType   `json:"type"`
+	Target    string          `json:"target"`
+	Status    OperationStatus `json:"status"`
+	Message   string          `json:"message,omitempty"`
+	Created   time.Time       `json:"created"`
+	Completed *time.Time      `json:"completed,omitempty"`
+}
+
+// IsCompleted returns whether the operation succeeded or failed
+func (o *Operation) IsCompleted() bool {
+	return o.Status == OperationSucceeded || o.Status == OperationFailed
+}
+
+// Executor performs the operations requested through the admin API. Each method blocks until the operation completes,
+// returning a message describing its outcome
+type Executor interface {
+	ResyncNamespace(ctx context.Context, namespace string) (string, error)
+	RotateRobot(ctx context.Context, namespace string, serviceAccount string) (string, error)
+	VerifyIntegration(ctx context.Context, name string) (string, error)
+}
+
+// Server serves an API used by developer portals and automation to request operations on demand and follow their status.
+// Operations run asynchronously: requests are answered with the pending operation, whose status is then polled. Requests
+// must present the token as a bearer token. The API is served over TLS when a certificate directory is configured, and only
+// on the loopback interface otherwise
+type Server struct {
+	BindAddress string
+	Token       string
+	Executor    Executor
+
+	// CertDir contains the tls.crt and tls.key files of the serving certificate
+	CertDir string
+
+	// OperationTimeout bounds the time an operation may run. Defaults to 5 minutes
+	OperationTimeout time.Duration
+
+	// RetainedOperations is the number of completed operations whose status remains available. Defaults to 100
+	RetainedOperations int
+
+	mu         sync.Mutex
+	ctx        context.Context
+	operations map[string]*Operation
+	completed  []string
+}
+
+// ValidateToken ensures the admin API is protected by a token
+func ValidateToken(token string) error {
+
+	if token == "" {
+		return errors.New("the admin API must be protected by a token")
+	}
+
+	return nil
+}
+
+// NeedLeaderElection runs the operations on the elected replica, which reconciles the namespaces being operated on
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
+
+// Handler returns the handler serving the admin API
+func (s *Server) Handler() http.Handler {
+
+	return httpauth.RequireBearerToken(s.Token, http.HandlerFunc(s.serve))
+}
+
+// serve routes a request to the operation it requests
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrefix), "/"), "/")
+
+	for _, segment := range segments {
+		if segment == "" {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	switch {
+	// GET /api/v1/operations/{id}
+	case len(segments) == 2 && segments[0] == "operations":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		operation, found := s.Get(segments[1])
+
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+
+		writeOperation(w, http.StatusOK, operation)
+
+	// POST /api/v1/namespaces/{namespace}/resync
+	case len(segments) == 3 && segments[0] == "namespaces" && segments[2] == "resync":
+		s.submit(w, r, ResyncNamespaceOperation, segments[1], func(ctx context.Context) (string, error) {
+			return s.Executor.ResyncNamespace(ctx, segments[1])
+		})
+
+	// POST /api/v1/namespaces/{namespace}/robots/{serviceAccount}/rotate
+	case len(segments) == 5 && segments[0] == "namespaces" && segments[2] == "robots" && segments[4] == "rotate":
+		s.submit(w, r, RotateRobotOperation, segments[1]+"/"+segments[3], func(ctx context.Context) (string, error) {
+			return s.Executor.RotateRobot(ctx, segments[1], segments[3])
+		})
+
+	// POST /api/v1/quayintegrations/{name}/verify
+	case len(segments) == 3 && segments[0] == "quayintegrations" && segments[2] == "verify":
+		s.submit(w, r, VerifyIntegrationOperation, segments[1], func(ctx context.Context) (string, error) {
+			return s.Executor.VerifyIntegration(ctx, segments[1])
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// submit starts an operation, answering with the pending operation and its location
+func (s *Server) submit(w http.ResponseWriter, r *http.Request, operationType OperationType, target string, run func(ctx context.Context) (string, error)) {
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	operation, err := s.start(operationType, target, run)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", apiPrefix+"operations/"+operation.ID)
+	writeOperation(w, http.StatusAccepted, operation)
+}
+
+// start records an operation and runs it in the background
+func (s *Server) start(operationType OperationType, target string, run func(ctx context.Context) (string, error)) (Operation, error) {
+
+	id, err := newOperationID()
+
+	if err != nil {
+		return Operation{}, err
+	}
+
+	operation := &Operation{
+		ID:      id,
+		Type:    operationType,
+		Target:  target,
+		Status:  OperationPending,
+		Created: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+
+	if s.operations == nil {
+		s.operations = map[string]*Operation{}
+	}
+
+	s.operations[id] = operation
+	baseCtx := s.ctx
+	snapshot := *operation
+
+	s.mu.Unlock()
+
+	if baseCtx == nil {
+		baseCtx = context.Background()
+	}
+
+	timeout := s.OperationTimeout
+
+	if timeout <= 0 {
+		timeout = defaultOperationTimeout
+	}
+
+	logging.Log.Info("Starting admin operation", "Operation", id, "Type", operationType, "Target", target)
+
+	go func() {
+
+		ctx, cancel := context.WithTimeout(baseCtx, timeout)
+		defer cancel()
+
+		s.update(id, func(operation *Operation) {
+			operation.Status = OperationRunning
+		})
+
+		message, err := run(ctx)
+
+		s.complete(id, message, err)
+	}()
+
+	return snapshot, nil
+}
+
+// update applies a change to a recorded operation
+func (s *Server) update(id string, mutate func(operation *Operation)) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if operation, found := s.operations[id]; found {
+		mutate(operation)
+	}
+}
+
+// complete records the outcome of an operation, forgetting the oldest completed operations beyond the retention
+func (s *Server) complete(id string, message string, err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operation, found := s.operations[id]
+
+	if !found {
+		return
+	}
+
+	completed := time.Now().UTC()
+
+	operation.Status = OperationSucceeded
+	operation.Message = message
+	operation.Completed = &completed
+
+	if err != nil {
+		operation.Status = OperationFailed
+		operation.Message = err.Error()
+		logging.Log.Error(err, "Admin operation failed", "Operation", id, "Type", operation.Type, "Target", operation.Target)
+	} else {
+		logging.Log.Info("Admin operation succeeded", "Operation", id, "Type", operation.Type, "Target", operation.Target)
+	}
+
+	retained := s.RetainedOperations
+
+	if retained <= 0 {
+		retained = defaultRetainedOperations
+	}
+
+	s.completed = append(s.completed, id)
+
+	for len(s.completed) > retained {
+		delete(s.operations, s.completed[0])
+		s.completed = s.completed[1:]
+	}
+}
+
+// Get returns an operation by its identifier
+func (s *Server) Get(id string) (Operation, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operation, found := s.operations[id]
+
+	if !found {
+		return Operation{}, false
+	}
+
+	return *operation, true
+}
+
+// Start serves the admin API until the context is done. Operations in progress are cancelled on shutdown
+func (s *Server) Start(ctx context.Context) error {
+
+	if err := httpauth.ValidateBindAddress("admin API", s.BindAddress, s.Token, s.CertDir); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	listener, err := net.Listen("tcp", s.BindAddress)
+
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler: s.Handler(),
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logging.Log.Error(err, "Admin server did not shut down cleanly")
+		}
+	}()
+
+	logging.Log.Info("Serving admin API", "Address", s.BindAddress, "TLS", s.CertDir != "")
+
+	if err := httpauth.Serve(srv, listener, s.CertDir); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// writeOperation answers a request with an operation
+func writeOperation(w http.ResponseWriter, statusCode int, operation Operation) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(operation); err != nil {
+		logging.Log.Error(err, "Unable to write admin operation", "Operation", operation.ID)
+	}
+}
+
+// newOperationID returns a random identifier for an operation
+func newOperationID() (string, error) {
+
+	id := make([]byte, 8)
+
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,209 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeExecutor struct{}
+
+func (e *fakeExecutor) ResyncNamespace(ctx context.Context, namespace string) (string, error) {
+	return "namespace " + namespace + " synchronized", nil
+}
+
+func (e *fakeExecutor) RotateRobot(ctx context.Context, namespace string, serviceAccount string) (string, error) {
+	return "", errors.New("robot account of " + serviceAccount + " not found")
+}
+
+func (e *fakeExecutor) VerifyIntegration(ctx context.Context, name string) (string, error) {
+	return "integration " + name + " verified", nil
+}
+
+func TestHandler(t *testing.T) {
+
+	cases := []struct {
+		method          string
+		path            string
+		authorization   string
+		expectedStatus  int
+		expectedType    OperationType
+		expectedTarget  string
+		expectedOutcome OperationStatus
+		expectedMessage string
+	}{
+		{
+			method:         http.MethodPost,
+			path:           "/api/v1/namespaces/app/resync",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/api/v1/namespaces/app/resync",
+			authorization:  "Bearer invalid",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/api/v1/namespaces/app/resync",
+			authorization:  "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/api/v1/namespaces/app/resync",
+			authorization:  "Basic secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			method:          http.MethodPost,
+			path:            "/api/v1/namespaces/app/resync",
+			authorization:   "Bearer secret",
+			expectedStatus:  http.StatusAccepted,
+			expectedType:    ResyncNamespaceOperation,
+			expectedTarget:  "app",
+			expectedOutcome: OperationSucceeded,
+			expectedMessage: "namespace app synchronized",
+		},
+		{
+			method:          http.MethodPost,
+			path:            "/api/v1/namespaces/app/robots/builder/rotate",
+			authorization:   "Bearer secret",
+			expectedStatus:  http.StatusAccepted,
+			expectedType:    RotateRobotOperation,
+			expectedTarget:  "app/builder",
+			expectedOutcome: OperationFailed,
+			expectedMessage: "robot account of builder not found",
+		},
+		{
+			method:          http.MethodPost,
+			path:            "/api/v1/quayintegrations/example/verify",
+			authorization:   "Bearer secret",
+			expectedStatus:  http.StatusAccepted,
+			expectedType:    VerifyIntegrationOperation,
+			expectedTarget:  "example",
+			expectedOutcome: OperationSucceeded,
+			expectedMessage: "integration example verified",
+		},
+		{
+			method:         http.MethodGet,
+			path:           "/api/v1/namespaces/app/resync",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			method:         http.MethodGet,
+			path:           "/api/v1/operations/unknown",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/api/v1/namespaces//resync",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for i, c := range cases {
+
+		server := &Server{Token: "secret", Executor: &fakeExecutor{}}
+
+		request := httptest.NewRequest(c.method, c.path, nil)
+
+		if c.authorization != "" {
+			request.Header.Set("Authorization", c.authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		server.Handler().ServeHTTP(recorder, request)
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("Test case %d did not match\nExpected: %d\nActual: %d", i, c.expectedStatus, recorder.Code)
+			continue
+		}
+
+		if c.expectedStatus != http.StatusAccepted {
+			continue
+		}
+
+		operation := Operation{}
+
+		if err := json.NewDecoder(recorder.Body).Decode(&operation); err != nil {
+			t.Errorf("Test case %d did not match\nUnexpected error: %v", i, err)
+			continue
+		}
+
+		if recorder.Header().Get("Location") != "/api/v1/operations/"+operation.ID {
+			t.Errorf("Test case %d did not match\nExpected Location of operation %s\nActual: %s", i, operation.ID, recorder.Header().Get("Location"))
+		}
+
+		completed := waitForOperation(server, operation.ID)
+
+		if completed.Type != c.expectedType || completed.Target != c.expectedTarget || completed.Status != c.expectedOutcome || completed.Message != c.expectedMessage {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %s %s\nActual: %s %s %s %s", i, c.expectedType, c.expectedTarget, c.expectedOutcome, c.expectedMessage, completed.Type, completed.Target, completed.Status, completed.Message)
+		}
+
+		request = httptest.NewRequest(http.MethodGet, "/api/v1/operations/"+operation.ID, nil)
+		request.Header.Set("Authorization", c.authorization)
+		recorder = httptest.NewRecorder()
+
+		server.Handler().ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Test case %d did not match\nExpected: %d\nActual: %d", i, http.StatusOK, recorder.Code)
+		}
+	}
+}
+
+func TestRetainedOperations(t *testing.T) {
+
+	server := &Server{Token: "secret", Executor: &fakeExecutor{}, RetainedOperations: 2}
+
+	ids := []string{}
+
+	for i := 0; i < 3; i++ {
+
+		operation, err := server.start(VerifyIntegrationOperation, "example", func(ctx context.Context) (string, error) {
+			return "", nil
+		})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		waitForOperation(server, operation.ID)
+
+		ids = append(ids, operation.ID)
+	}
+
+	if _, found := server.Get(ids[0]); found {
+		t.Errorf("Expected operation %s to be forgotten", ids[0])
+	}
+
+	for _, id := range ids[1:] {
+		if _, found := server.Get(id); !found {
+			t.Errorf("Expected operation %s to be retained", id)
+		}
+	}
+}
+
+func waitForOperation(server *Server, id string) Operation {
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		operation, _ := server.Get(id)
+
+		if operation.IsCompleted() || time.Now().After(deadline) {
+			return operation
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,138 @@
+package auditlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+const (
+	// PermissionChangedReason is the reason of the events reporting a change of the permissions of a repository
+	PermissionChangedReason = "QuayPermissionChanged"
+	// RepositoryDeletedReason is the reason of the events reporting the deletion of a repository
+	RepositoryDeletedReason = "QuayRepositoryDeleted"
+	// RobotAccountCreatedReason is the reason of the events reporting the creation of a robot account
+	RobotAccountCreatedReason = "QuayRobotAccountCreated"
+	// RobotAccountDeletedReason is the reason of the events reporting the deletion of a robot account
+	RobotAccountDeletedReason = "QuayRobotAccountDeleted"
+)
+
+// notableKinds maps the kinds of the Quay logs reported in the cluster to the reason of their events
+var notableKinds = map[string]string{
+	"change_repo_permission": PermissionChangedReason,
+	"delete_repo_permission": PermissionChangedReason,
+	"change_repo_visibility": PermissionChangedReason,
+	"org_set_team_role":      PermissionChangedReason,
+	"delete_repo":            RepositoryDeletedReason,
+	"create_robot":           RobotAccountCreatedReason,
+	"delete_robot":           RobotAccountDeletedReason,
+}
+
+// Entry is a notable action performed on an organization outside of the operator
+type Entry struct {
+	Reason  string
+	Message string
+	Time    time.Time
+}
+
+// Cursor marks the logs of an organization which have already been processed. Logs are only timestamped to the second,
+// so the logs sharing the time of the cursor are remembered to avoid reporting them twice
+type Cursor struct {
+	Time time.Time
+	Seen map[string]bool
+}
+
+// Notable returns the notable actions logged after the cursor which were not performed by the operator, along with the
+// cursor to use on the next poll. Logs whose time cannot be parsed are ignored
+func Notable(logs []qclient.Log, cursor Cursor, operatorUsername string) ([]Entry, Cursor) {
+
+	next := Cursor{Time: cursor.Time, Seen: map[string]bool{}}
+
+	for key := range cursor.Seen {
+		next.Seen[key] = true
+	}
+
+	entries := []Entry{}
+
+	for _, log := range logs {
+
+		logTime, err := time.Parse(time.RFC1123Z, log.Datetime)
+
+		if err != nil || logTime.Before(cursor.Time) {
+			continue
+		}
+
+		key := logKey(log)
+
+		if logTime.Equal(cursor.Time) && cursor.Seen[key] {
+			continue
+		}
+
+		if logTime.After(next.Time) {
+			next = Cursor{Time: logTime, Seen: map[string]bool{}}
+		}
+
+		if logTime.Equal(next.Time) {
+			next.Seen[key] = true
+		}
+
+		reason, notable := notableKinds[log.Kind]
+
+		if !notable || (log.Performer != nil && log.Performer.Name == operatorUsername) {
+			continue
+		}
+
+		entries = append(entries, Entry{Reason: reason, Message: describe(log), Time: logTime})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	return entries, next
+}
+
+// describe returns a human readable description of a log
+func describe(log qclient.Log) string {
+
+	description := fmt.Sprintf("Quay reported %s", log.Kind)
+
+	for _, key := range []string{"repo", "robot", "username", "team", "role", "visibility"} {
+		if value, found := log.Metadata[key]; found {
+			description += fmt.Sprintf(" %s=%v", key, value)
+		}
+	}
+
+	if log.Performer != nil {
+		description += fmt.Sprintf(" performed by %s", log.Performer.Name)
+	}
+
+	return description
+}
+
+// logKey identifies a log among the logs sharing the same time
+func logKey(log qclient.Log) string {
+
+	keys := []string{}
+
+	for key := range log.Metadata {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	parts := []string{log.Kind, log.Datetime}
+
+	if log.Performer != nil {
+		parts = append(parts, log.Performer.Name)
+	}
+
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, log.Metadata[key]))
+	}
+
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,67 @@
+package auditlog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestNotable(t *testing.T) {
+
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	robotCreated := qclient.Log{Kind: "create_robot", Datetime: "Tue, 01 Jun 2021 10:00:00 -0000", Metadata: map[string]interface{}{"robot": "demo+ci"}, Performer: &qclient.LogPerformer{Name: "admin"}}
+	repositoryDeleted := qclient.Log{Kind: "delete_repo", Datetime: "Tue, 01 Jun 2021 11:00:00 -0000", Metadata: map[string]interface{}{"repo": "app"}, Performer: &qclient.LogPerformer{Name: "admin"}}
+	operatorRobotCreated := qclient.Log{Kind: "create_robot", Datetime: "Tue, 01 Jun 2021 11:00:00 -0000", Metadata: map[string]interface{}{"robot": "demo+builder"}, Performer: &qclient.LogPerformer{Name: "operator"}}
+	pushed := qclient.Log{Kind: "push_repo", Datetime: "Tue, 01 Jun 2021 12:00:00 -0000", Metadata: map[string]interface{}{"repo": "app"}}
+	earlier := qclient.Log{Kind: "delete_repo", Datetime: "Tue, 01 Jun 2021 09:00:00 -0000", Metadata: map[string]interface{}{"repo": "old"}}
+
+	cases := []struct {
+		logs            []qclient.Log
+		cursor          Cursor
+		expectedReasons []string
+		expectedTime    time.Time
+	}{
+		{
+			logs:            []qclient.Log{earlier, robotCreated, repositoryDeleted, operatorRobotCreated, pushed},
+			cursor:          Cursor{Time: start},
+			expectedReasons: []string{RobotAccountCreatedReason, RepositoryDeletedReason},
+			expectedTime:    start.Add(2 * time.Hour),
+		},
+		{
+			logs:            []qclient.Log{robotCreated, repositoryDeleted},
+			cursor:          Cursor{Time: start, Seen: map[string]bool{logKey(robotCreated): true}},
+			expectedReasons: []string{RepositoryDeletedReason},
+			expectedTime:    start.Add(time.Hour),
+		},
+		{
+			logs:            []qclient.Log{repositoryDeleted},
+			cursor:          Cursor{Time: start.Add(time.Hour), Seen: map[string]bool{logKey(repositoryDeleted): true}},
+			expectedReasons: []string{},
+			expectedTime:    start.Add(time.Hour),
+		},
+		{
+			logs:            []qclient.Log{{Kind: "delete_repo", Datetime: "invalid"}},
+			cursor:          Cursor{Time: start},
+			expectedReasons: []string{},
+			expectedTime:    start,
+		},
+	}
+
+	for i, c := range cases {
+
+		entries, next := Notable(c.logs, c.cursor, "operator")
+
+		reasons := []string{}
+
+		for _, entry := range entries {
+			reasons = append(reasons, entry.Reason)
+		}
+
+		if !reflect.DeepEqual(c.expectedReasons, reasons) || !next.Time.Equal(c.expectedTime) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v", i, c.expectedReasons, c.expectedTime, reasons, next.Time)
+		}
+	}
+}
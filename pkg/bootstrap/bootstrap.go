@@ -0,0 +1,171 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/quayregistry"
+)
+
+const (
+	// UsernameKey is the key of the credentials Secret containing the name of the first user
+	UsernameKey = "username"
+	// PasswordKey is the key of the credentials Secret containing the password of the first user
+	PasswordKey = "password"
+
+	passwordLength = 32
+)
+
+// ErrAlreadyInitialized is returned when Quay rejects the initialization of its first user
+var ErrAlreadyInitialized = fmt.Errorf("quay already has users or does not enable FEATURE_USER_INITIALIZE")
+
+// Initialize creates the first user of Quay and returns its OAuth token. Quay rejects the initialization with a 400 once it has
+// users and with a 404 when FEATURE_USER_INITIALIZE is disabled, which are both reported as ErrAlreadyInitialized
+func Initialize(quayClient *qclient.QuayClient, username string, password string, email string) (string, error) {
+
+	userInitializeResponse, resp, apiErr := quayClient.InitializeUser(qclient.UserInitializeRequest{
+		Username:    username,
+		Password:    password,
+		Email:       email,
+		AccessToken: true,
+	})
+
+	if resp != nil && (resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound) {
+		return "", ErrAlreadyInitialized
+	}
+
+	if apiErr.Error != nil {
+		return "", apiErr.Error
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code initializing the first user of Quay: %d", resp.StatusCode)
+	}
+
+	if userInitializeResponse.AccessToken == "" {
+		return "", fmt.Errorf("quay did not return an access token for user %s", username)
+	}
+
+	return userInitializeResponse.AccessToken, nil
+}
+
+// Bootstrap initializes the first user of Quay and stores its OAuth token in the credentials Secret of a QuayIntegration,
+// returning the reason of the Bootstrapped condition along with a message. Nothing is done when the Secret already contains
+// credentials. The username and password of the user are written to the Secret before Quay is initialized so they are not lost
+// when storing the token fails
+func Bootstrap(ctx context.Context, c client.Client, reader client.Reader, httpClientPool *qclient.HTTPClientPool, quayIntegration *quayv1.QuayIntegration) (string, string, error) {
+
+	if quayIntegration.Spec.CredentialsSecret == nil {
+		return quayv1.BootstrapFailedReason, "", fmt.Errorf("required parameter 'CredentialsSecret' not found")
+	}
+
+	tokenKey := constants.QuaySecretCredentialTokenKey
+
+	if quayIntegration.Spec.CredentialsSecret.Key != "" {
+		tokenKey = quayIntegration.Spec.CredentialsSecret.Key
+	}
+
+	secret := &corev1.Secret{}
+
+	err := reader.Get(ctx, types.NamespacedName{Namespace: quayIntegration.Spec.CredentialsSecret.Namespace, Name: quayIntegration.Spec.CredentialsSecret.Name}, secret)
+
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: quayIntegration.Spec.CredentialsSecret.Namespace, Name: quayIntegration.Spec.CredentialsSecret.Name},
+		}
+	} else if err != nil {
+		return quayv1.BootstrapFailedReason, "", err
+	}
+
+	if len(secret.Data[tokenKey]) > 0 || len(secret.Data[qclient.OAuthClientIDKey]) > 0 {
+		return quayv1.CredentialsProvidedReason, fmt.Sprintf("Secret %s/%s contains credentials", secret.Namespace, secret.Name), nil
+	}
+
+	quayHostname, certificateAuthority, err := quayregistry.ResolveEndpoint(ctx, reader, quayIntegration)
+
+	if err != nil {
+		return quayv1.BootstrapFailedReason, "", err
+	}
+
+	httpClient, err := httpClientPool.GetHTTPClientWithCA(quayIntegration.IsTLSVerificationDisabled(), certificateAuthority)
+
+	if err != nil {
+		return quayv1.BootstrapFailedReason, "", err
+	}
+
+	username := quayIntegration.GetBootstrapUsername()
+	password := string(secret.Data[PasswordKey])
+
+	if password == "" || string(secret.Data[UsernameKey]) != username {
+
+		if password, err = generatePassword(); err != nil {
+			return quayv1.BootstrapFailedReason, "", err
+		}
+
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+
+		secret.Data[UsernameKey] = []byte(username)
+		secret.Data[PasswordKey] = []byte(password)
+
+		if err := save(ctx, c, secret); err != nil {
+			return quayv1.BootstrapFailedReason, "", err
+		}
+	}
+
+	email := ""
+
+	if quayIntegration.Spec.Bootstrap != nil {
+		email = quayIntegration.Spec.Bootstrap.Email
+	}
+
+	token, err := Initialize(qclient.NewClient(httpClient, quayHostname, ""), username, password, email)
+
+	if err == ErrAlreadyInitialized {
+		return quayv1.AlreadyInitializedReason, fmt.Sprintf("Quay cannot be initialized as it already has users or does not enable FEATURE_USER_INITIALIZE. Provide a token in key '%s' of Secret %s/%s", tokenKey, secret.Namespace, secret.Name), nil
+	} else if err != nil {
+		return quayv1.BootstrapFailedReason, "", err
+	}
+
+	secret.Data[tokenKey] = []byte(token)
+
+	if err := save(ctx, c, secret); err != nil {
+		return quayv1.BootstrapFailedReason, "", err
+	}
+
+	return quayv1.BootstrappedReason, fmt.Sprintf("Initialized user %s and stored its token in Secret %s/%s", username, secret.Namespace, secret.Name), nil
+}
+
+// save creates the Secret when it has not been created yet, and updates it otherwise
+func save(ctx context.Context, c client.Client, secret *corev1.Secret) error {
+
+	if secret.ResourceVersion == "" {
+		return c.Create(ctx, secret)
+	}
+
+	return c.Update(ctx, secret)
+}
+
+func generatePassword() (string, error) {
+
+	data := make([]byte, passwordLength/2)
+
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
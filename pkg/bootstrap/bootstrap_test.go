@@ -0,0 +1,50 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestInitialize(t *testing.T) {
+
+	cases := []struct {
+		statusCode    int
+		response      string
+		expected      string
+		expectedError error
+	}{
+		{statusCode: http.StatusOK, response: `{"username": "quayadmin", "access_token": "token"}`, expected: "token"},
+		{statusCode: http.StatusBadRequest, response: `{"message": "Cannot initialize user in a non-empty database"}`, expectedError: ErrAlreadyInitialized},
+		{statusCode: http.StatusNotFound, response: `{}`, expectedError: ErrAlreadyInitialized},
+	}
+
+	for i, c := range cases {
+
+		request := qclient.UserInitializeRequest{}
+		authorization := ""
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+
+			if r.Method != "POST" || r.URL.Path != "/api/v1/user/initialize" || json.NewDecoder(r.Body).Decode(&request) != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(c.statusCode)
+			w.Write([]byte(c.response))
+		}))
+
+		token, err := Initialize(qclient.NewClient(server.Client(), server.URL, ""), "quayadmin", "password", "")
+
+		server.Close()
+
+		if token != c.expected || err != c.expectedError || authorization != "" || request.Username != "quayadmin" || request.Password != "password" || !request.AccessToken {
+			t.Errorf("Test case %d did not match\nExpected: %s %v\nActual: %s %v %+v", i, c.expected, c.expectedError, token, err, request)
+		}
+	}
+}
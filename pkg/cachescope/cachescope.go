@@ -0,0 +1,310 @@
+package cachescope
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var log = logf.Log.WithName("cachescope")
+
+// Selector restricts the objects of a kind held in the cache
+type Selector struct {
+	Label labels.Selector
+	Field fields.Selector
+}
+
+// Query returns the list and watch query parameters of the selector
+func (s Selector) Query() map[string]string {
+
+	query := map[string]string{}
+
+	if s.Label != nil && !s.Label.Empty() {
+		query["labelSelector"] = s.Label.String()
+	}
+
+	if s.Field != nil && !s.Field.Empty() {
+		query["fieldSelector"] = s.Field.String()
+	}
+
+	return query
+}
+
+// NewCacheFunc returns a cache builder restricting the objects held in the cache. Namespaced objects are only cached in the provided namespaces,
+// or in every namespace when none are provided. Objects of the kinds with a selector are held in a dedicated cache only containing matching objects
+func NewCacheFunc(namespaces []string, selectors map[client.Object]Selector) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+
+		clusterCache, err := cache.New(config, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newCache := cache.New
+		namespacedCache := clusterCache
+
+		if len(namespaces) > 0 {
+
+			newCache = cache.MultiNamespacedCacheBuilder(namespaces)
+
+			if namespacedCache, err = newCache(config, opts); err != nil {
+				return nil, err
+			}
+		}
+
+		selectorCaches := map[schema.GroupVersionKind]cache.Cache{}
+
+		for obj, selector := range selectors {
+
+			gvk, err := apiutil.GVKForObject(obj, opts.Scheme)
+
+			if err != nil {
+				return nil, err
+			}
+
+			selectorCache, err := newCache(SelectorConfig(config, selector), opts)
+
+			if err != nil {
+				return nil, err
+			}
+
+			selectorCaches[gvk] = selectorCache
+		}
+
+		return &scopedCache{
+			Cache:           clusterCache,
+			scheme:          opts.Scheme,
+			mapper:          opts.Mapper,
+			namespacedCache: namespacedCache,
+			selectorCaches:  selectorCaches,
+		}, nil
+	}
+}
+
+// InNamespaces returns whether a namespace is part of the namespaces the operator is restricted to. Every namespace is included when none are provided
+func InNamespaces(namespaces []string, namespace string) bool {
+
+	if len(namespaces) == 0 {
+		return true
+	}
+
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NamespacePredicate filters events of objects outside the namespaces the operator is restricted to. Namespaces are matched by their name
+func NamespacePredicate(namespaces []string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+
+		if _, ok := obj.(*corev1.Namespace); ok {
+			return InNamespaces(namespaces, obj.GetName())
+		}
+
+		return InNamespaces(namespaces, obj.GetNamespace())
+	})
+}
+
+// SelectorConfig returns a copy of a rest config adding the selector to list and watch requests
+func SelectorConfig(config *rest.Config, selector Selector) *rest.Config {
+
+	selectorConfig := rest.CopyConfig(config)
+
+	query := selector.Query()
+
+	selectorConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &selectorRoundTripper{delegate: rt, query: query}
+	})
+
+	return selectorConfig
+}
+
+type selectorRoundTripper struct {
+	delegate http.RoundTripper
+	query    map[string]string
+}
+
+func (s *selectorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if req.Method != http.MethodGet || len(s.query) == 0 {
+		return s.delegate.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+
+	values := req.URL.Query()
+
+	for key, value := range s.query {
+		if existing := values.Get(key); existing != "" {
+			value = strings.Join([]string{existing, value}, ",")
+		}
+
+		values.Set(key, value)
+	}
+
+	req.URL.RawQuery = values.Encode()
+
+	return s.delegate.RoundTrip(req)
+}
+
+// scopedCache holds cluster scoped objects in the embedded cache and delegates namespaced objects and objects of the kinds with a selector
+type scopedCache struct {
+	cache.Cache
+	scheme          *runtime.Scheme
+	mapper          meta.RESTMapper
+	namespacedCache cache.Cache
+	selectorCaches  map[schema.GroupVersionKind]cache.Cache
+}
+
+var _ cache.Cache = &scopedCache{}
+
+func (c *scopedCache) cacheForObject(obj runtime.Object) (cache.Cache, error) {
+
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.cacheForKind(gvk)
+}
+
+func (c *scopedCache) cacheForKind(gvk schema.GroupVersionKind) (cache.Cache, error) {
+
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	if selectorCache, ok := c.selectorCaches[gvk]; ok {
+		return selectorCache, nil
+	}
+
+	if c.namespacedCache == c.Cache {
+		return c.Cache, nil
+	}
+
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return c.namespacedCache, nil
+	}
+
+	return c.Cache, nil
+}
+
+func (c *scopedCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+
+	delegate, err := c.cacheForObject(obj)
+
+	if err != nil {
+		return err
+	}
+
+	return delegate.Get(ctx, key, obj)
+}
+
+func (c *scopedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+
+	delegate, err := c.cacheForObject(list)
+
+	if err != nil {
+		return err
+	}
+
+	return delegate.List(ctx, list, opts...)
+}
+
+func (c *scopedCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+
+	delegate, err := c.cacheForObject(obj)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return delegate.GetInformer(ctx, obj)
+}
+
+func (c *scopedCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+
+	delegate, err := c.cacheForKind(gvk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return delegate.GetInformerForKind(ctx, gvk)
+}
+
+func (c *scopedCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+
+	delegate, err := c.cacheForObject(obj)
+
+	if err != nil {
+		return err
+	}
+
+	return delegate.IndexField(ctx, obj, field, extractValue)
+}
+
+func (c *scopedCache) Start(ctx context.Context) error {
+
+	for _, delegate := range c.delegates() {
+		go func(delegate cache.Cache) {
+			if err := delegate.Start(ctx); err != nil {
+				log.Error(err, "Failed to start scoped cache")
+			}
+		}(delegate)
+	}
+
+	return c.Cache.Start(ctx)
+}
+
+func (c *scopedCache) WaitForCacheSync(ctx context.Context) bool {
+
+	synced := c.Cache.WaitForCacheSync(ctx)
+
+	for _, delegate := range c.delegates() {
+		if !delegate.WaitForCacheSync(ctx) {
+			synced = false
+		}
+	}
+
+	return synced
+}
+
+// delegates returns the caches started alongside the embedded cache
+func (c *scopedCache) delegates() []cache.Cache {
+
+	delegates := []cache.Cache{}
+
+	if c.namespacedCache != c.Cache {
+		delegates = append(delegates, c.namespacedCache)
+	}
+
+	for _, selectorCache := range c.selectorCaches {
+		delegates = append(delegates, selectorCache)
+	}
+
+	return delegates
+}
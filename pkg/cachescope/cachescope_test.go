@@ -0,0 +1,154 @@
+package cachescope
+
+import (
+	"net/http"
+	"testing"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+type fakeCache struct {
+	cache.Cache
+}
+
+type recordingRoundTripper struct {
+	request *http.Request
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.request = req
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestSelectorRoundTripper(t *testing.T) {
+
+	cases := []struct {
+		method   string
+		url      string
+		selector Selector
+		expected string
+	}{
+		{
+			method:   http.MethodGet,
+			url:      "https://api/apis/build.openshift.io/v1/builds?watch=true",
+			selector: Selector{Label: labels.SelectorFromSet(labels.Set{"managed": "true"})},
+			expected: "labelSelector=managed%3Dtrue&watch=true",
+		},
+		{
+			method:   http.MethodGet,
+			url:      "https://api/api/v1/secrets?labelSelector=app%3Dtest",
+			selector: Selector{Label: labels.SelectorFromSet(labels.Set{"managed": "true"}), Field: fields.OneTermEqualSelector("type", "kubernetes.io/dockerconfigjson")},
+			expected: "fieldSelector=type%3Dkubernetes.io%2Fdockerconfigjson&labelSelector=app%3Dtest%2Cmanaged%3Dtrue",
+		},
+		{
+			method:   http.MethodPost,
+			url:      "https://api/api/v1/secrets",
+			selector: Selector{Label: labels.SelectorFromSet(labels.Set{"managed": "true"})},
+			expected: "",
+		},
+		{
+			method:   http.MethodGet,
+			url:      "https://api/api/v1/secrets",
+			selector: Selector{},
+			expected: "",
+		},
+	}
+
+	for i, c := range cases {
+
+		recorder := &recordingRoundTripper{}
+		req, _ := http.NewRequest(c.method, c.url, nil)
+
+		if _, err := (&selectorRoundTripper{delegate: recorder, query: c.selector.Query()}).RoundTrip(req); err != nil {
+			t.Fatalf("Test case %d failed: %v", i, err)
+		}
+
+		if recorder.request.URL.RawQuery != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, recorder.request.URL.RawQuery)
+		}
+	}
+}
+
+func TestScopedCacheRouting(t *testing.T) {
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = buildv1.AddToScheme(scheme)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	mapper.Add(buildv1.GroupVersion.WithKind("BuildConfig"), meta.RESTScopeNamespace)
+
+	clusterCache := &fakeCache{}
+	namespacedCache := &fakeCache{}
+	buildCache := &fakeCache{}
+
+	cases := []struct {
+		obj             runtime.Object
+		namespacedCache cache.Cache
+		expected        cache.Cache
+	}{
+		{obj: &buildv1.Build{}, namespacedCache: clusterCache, expected: buildCache},
+		{obj: &buildv1.BuildList{}, namespacedCache: clusterCache, expected: buildCache},
+		{obj: &buildv1.BuildConfig{}, namespacedCache: clusterCache, expected: clusterCache},
+		{obj: &corev1.Secret{}, namespacedCache: clusterCache, expected: clusterCache},
+		{obj: &buildv1.Build{}, namespacedCache: namespacedCache, expected: buildCache},
+		{obj: &buildv1.BuildConfig{}, namespacedCache: namespacedCache, expected: namespacedCache},
+		{obj: &corev1.SecretList{}, namespacedCache: namespacedCache, expected: namespacedCache},
+		{obj: &corev1.Namespace{}, namespacedCache: namespacedCache, expected: clusterCache},
+	}
+
+	for i, c := range cases {
+
+		scoped := &scopedCache{
+			Cache:           clusterCache,
+			scheme:          scheme,
+			mapper:          mapper,
+			namespacedCache: c.namespacedCache,
+			selectorCaches:  map[schema.GroupVersionKind]cache.Cache{buildv1.GroupVersion.WithKind("Build"): buildCache},
+		}
+
+		result, err := scoped.cacheForObject(c.obj)
+
+		if err != nil {
+			t.Fatalf("Test case %d failed: %v", i, err)
+		}
+
+		if result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %p\nActual: %p", i, c.expected, result)
+		}
+	}
+}
+
+func TestNamespacePredicate(t *testing.T) {
+
+	cases := []struct {
+		namespaces []string
+		obj        client.Object
+		expected   bool
+	}{
+		{namespaces: nil, obj: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}}, expected: true},
+		{namespaces: []string{"test"}, obj: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}}, expected: true},
+		{namespaces: []string{"test"}, obj: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}, expected: false},
+		{namespaces: []string{"test"}, obj: &buildv1.Build{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"}}, expected: true},
+		{namespaces: []string{"test"}, obj: &buildv1.Build{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "other"}}, expected: false},
+	}
+
+	for i, c := range cases {
+		if result := NamespacePredicate(c.namespaces).Generic(event.GenericEvent{Object: c.obj}); result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
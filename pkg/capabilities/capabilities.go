@@ -0,0 +1,179 @@
+package capabilities
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+const (
+	// QuotaManagement reports the storage consumed by organizations and repositories
+	QuotaManagement = "QUOTA_MANAGEMENT"
+	// RepositoryMirroring populates repositories from another registry
+	RepositoryMirroring = "REPO_MIRROR"
+	// ProxyCache lets organizations cache the images of an upstream registry
+	ProxyCache = "PROXY_CACHE"
+	// AutoPrune removes tags according to the policies of organizations and repositories
+	AutoPrune = "AUTO_PRUNE"
+	// AggregatedLogCount reports the actions performed on repositories aggregated by day
+	AggregatedLogCount = "AGGREGATED_LOG_COUNT_RETRIEVAL"
+	// Signing lets trust (content signing) be enabled on repositories
+	Signing = "SIGNING"
+
+	// CreatePrivateRepoOnPush is the configuration key determining whether repositories created on push are private
+	CreatePrivateRepoOnPush = "CREATE_PRIVATE_REPO_ON_PUSH"
+)
+
+// Capabilities describes the features enabled on a Quay instance
+type Capabilities struct {
+	// Detected is false when Quay does not publish its features, in which case every feature is assumed to be supported
+	Detected bool
+	Features map[string]bool
+	// PublicRepositoriesOnPush is true when Quay creates the repositories pushed to as public repositories
+	PublicRepositoriesOnPush bool
+	// Version is the version of Quay, or nil when unknown
+	Version *Version
+}
+
+// Supports returns whether a feature is enabled on Quay
+func (c Capabilities) Supports(feature string) bool {
+
+	if _, newer := c.RequiresNewerVersion(feature); newer {
+		return false
+	}
+
+	return !c.Detected || c.Features[feature]
+}
+
+// RequiresNewerVersion returns the version of Quay introducing a feature when the version of Quay is known to precede it
+func (c Capabilities) RequiresNewerVersion(feature string) (Version, bool) {
+
+	minimum, ok := FeatureVersions[feature]
+
+	return minimum, ok && c.Version != nil && c.Version.Less(minimum)
+}
+
+// Enabled returns the sorted features enabled on Quay
+func (c Capabilities) Enabled() []string {
+
+	enabled := []string{}
+
+	for feature, supported := range c.Features {
+		if supported {
+			enabled = append(enabled, feature)
+		}
+	}
+
+	sort.Strings(enabled)
+
+	return enabled
+}
+
+// Unsupported returns the sorted requested features which are not enabled on Quay
+func (c Capabilities) Unsupported(requested []string) []string {
+
+	unsupported := []string{}
+	seen := map[string]bool{}
+
+	for _, feature := range requested {
+		if !c.Supports(feature) && !seen[feature] {
+			unsupported = append(unsupported, feature)
+			seen[feature] = true
+		}
+	}
+
+	sort.Strings(unsupported)
+
+	return unsupported
+}
+
+// FromServerConfig returns the capabilities described by the public configuration of Quay
+func FromServerConfig(serverConfig qclient.ServerConfig) Capabilities {
+
+	capabilities := Capabilities{Detected: true, Features: map[string]bool{}}
+
+	for feature, value := range serverConfig.Features {
+		enabled, _ := value.(bool)
+		capabilities.Features[feature] = enabled
+	}
+
+	// Repositories are created private on push unless configured otherwise
+	if private, ok := serverConfig.Config[CreatePrivateRepoOnPush].(bool); ok && !private {
+		capabilities.PublicRepositoriesOnPush = true
+	}
+
+	return capabilities
+}
+
+// Detect retrieves the features enabled on Quay. Instances not publishing their configuration are reported as undetected
+func Detect(quayClient *qclient.QuayClient) (Capabilities, error) {
+
+	serverConfig, serverConfigResponse, serverConfigErr := quayClient.GetServerConfig()
+
+	if serverConfigResponse != nil && serverConfigResponse.StatusCode == http.StatusNotFound {
+		return Capabilities{}, nil
+	}
+
+	if serverConfigErr.Error != nil {
+		return Capabilities{}, fmt.Errorf("error retrieving Quay configuration: %w", serverConfigErr.Error)
+	}
+
+	if serverConfigResponse.StatusCode != http.StatusOK {
+		return Capabilities{}, fmt.Errorf("error retrieving Quay configuration: status code %d", serverConfigResponse.StatusCode)
+	}
+
+	return FromServerConfig(serverConfig), nil
+}
+
+// Store records the capabilities detected for each QuayIntegration so that they are shared by the controllers
+type Store struct {
+	mutex        sync.RWMutex
+	capabilities map[string]Capabilities
+}
+
+// NewStore creates an empty Store
+func NewStore() *Store {
+	return &Store{capabilities: map[string]Capabilities{}}
+}
+
+// Get returns the capabilities detected for a QuayIntegration. Undetected capabilities support every feature
+func (s *Store) Get(quayIntegrationName string) Capabilities {
+
+	if s == nil {
+		return Capabilities{}
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.capabilities[quayIntegrationName]
+}
+
+// Set records the capabilities detected for a QuayIntegration
+func (s *Store) Set(quayIntegrationName string, capabilities Capabilities) {
+
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.capabilities[quayIntegrationName] = capabilities
+}
+
+// Delete forgets the capabilities of a deleted QuayIntegration
+func (s *Store) Delete(quayIntegrationName string) {
+
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.capabilities, quayIntegrationName)
+}
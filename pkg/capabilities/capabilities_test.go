@@ -0,0 +1,112 @@
+package capabilities
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestDetect(t *testing.T) {
+
+	cases := []struct {
+		statusCode          int
+		body                string
+		requested           []string
+		expectedDetected    bool
+		expectedUnsupported []string
+		expectedErr         bool
+	}{
+		{
+			statusCode:          200,
+			body:                `{"features": {"QUOTA_MANAGEMENT": true, "REPO_MIRROR": false, "PROXY_CACHE": true}, "registry_state": "normal"}`,
+			requested:           []string{RepositoryMirroring, QuotaManagement, AutoPrune, RepositoryMirroring},
+			expectedDetected:    true,
+			expectedUnsupported: []string{AutoPrune, RepositoryMirroring},
+		},
+		{
+			statusCode:          404,
+			body:                `<html>Not Found</html>`,
+			requested:           []string{RepositoryMirroring},
+			expectedDetected:    false,
+			expectedUnsupported: []string{},
+		},
+		{
+			statusCode:          500,
+			body:                `{"error": "internal"}`,
+			requested:           []string{RepositoryMirroring},
+			expectedDetected:    false,
+			expectedUnsupported: []string{},
+			expectedErr:         true,
+		},
+	}
+
+	for i, c := range cases {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.statusCode)
+			w.Write([]byte(c.body))
+		}))
+
+		capabilities, err := Detect(qclient.NewClient(server.Client(), server.URL, "token"))
+
+		server.Close()
+
+		unsupported := capabilities.Unsupported(c.requested)
+
+		if (err != nil) != c.expectedErr || capabilities.Detected != c.expectedDetected || !reflect.DeepEqual(c.expectedUnsupported, unsupported) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %v\nActual: %v %v %v", i, c.expectedDetected, c.expectedUnsupported, c.expectedErr, capabilities.Detected, unsupported, err)
+		}
+	}
+}
+
+func TestFromServerConfig(t *testing.T) {
+
+	cases := []struct {
+		config   map[string]interface{}
+		expected bool
+	}{
+		{config: nil, expected: false},
+		{config: map[string]interface{}{CreatePrivateRepoOnPush: true}, expected: false},
+		{config: map[string]interface{}{CreatePrivateRepoOnPush: false}, expected: true},
+		{config: map[string]interface{}{CreatePrivateRepoOnPush: "false"}, expected: false},
+	}
+
+	for i, c := range cases {
+
+		capabilities := FromServerConfig(qclient.ServerConfig{Config: c.config})
+
+		if capabilities.PublicRepositoriesOnPush != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, capabilities.PublicRepositoriesOnPush)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+
+	store := NewStore()
+
+	if !store.Get("quay").Supports(RepositoryMirroring) {
+		t.Errorf("Expected undetected capabilities to support every feature")
+	}
+
+	store.Set("quay", Capabilities{Detected: true, Features: map[string]bool{QuotaManagement: true}})
+
+	if store.Get("quay").Supports(RepositoryMirroring) || !store.Get("quay").Supports(QuotaManagement) {
+		t.Errorf("Expected detected capabilities to be returned: %v", store.Get("quay"))
+	}
+
+	store.Delete("quay")
+
+	if !store.Get("quay").Supports(RepositoryMirroring) {
+		t.Errorf("Expected deleted capabilities to support every feature")
+	}
+
+	var nilStore *Store
+
+	if !nilStore.Get("quay").Supports(RepositoryMirroring) {
+		t.Errorf("Expected a nil Store to support every feature")
+	}
+}
@@ -0,0 +1,79 @@
+package capabilities
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// MinimumVersion is the oldest version of Quay supported by the operator
+	MinimumVersion = Version{Major: 3, Minor: 6}
+	// UnsupportedVersion is the first version of Quay no longer supported by the operator
+	UnsupportedVersion = Version{Major: 4}
+
+	// FeatureVersions are the versions of Quay introducing the features newer than MinimumVersion. Features are unsupported by older
+	// versions of Quay even when their configuration is not published
+	FeatureVersions = map[string]Version{
+		QuotaManagement: {Major: 3, Minor: 7},
+		ProxyCache:      {Major: 3, Minor: 7},
+		AutoPrune:       {Major: 3, Minor: 10},
+	}
+
+	versionPattern = regexp.MustCompile(`^v?([0-9]+)\.([0-9]+)(?:\.([0-9]+))?(?:[-+][-+.A-Za-z0-9]*)?$`)
+)
+
+// Version is the version of a Quay release. Pre-release and build suffixes are ignored
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses versions such as 3.9, 3.9.1, v3.9.1 or 3.10.0-rc.1
+func ParseVersion(version string) (Version, error) {
+
+	matches := versionPattern.FindStringSubmatch(version)
+
+	if matches == nil {
+		return Version{}, fmt.Errorf("invalid Quay version '%s'", version)
+	}
+
+	parsed := Version{}
+	parsed.Major, _ = strconv.Atoi(matches[1])
+	parsed.Minor, _ = strconv.Atoi(matches[2])
+
+	if matches[3] != "" {
+		parsed.Patch, _ = strconv.Atoi(matches[3])
+	}
+
+	return parsed, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less returns whether a version precedes another one
+func (v Version) Less(other Version) bool {
+
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+
+	return v.Patch < other.Patch
+}
+
+// IsSupported returns whether the operator supports a version of Quay
+func (v Version) IsSupported() bool {
+	return !v.Less(MinimumVersion) && v.Less(UnsupportedVersion)
+}
+
+// SupportedRange describes the versions of Quay supported by the operator
+func SupportedRange() string {
+	return fmt.Sprintf("%d.%d or newer, before %d.%d", MinimumVersion.Major, MinimumVersion.Minor, UnsupportedVersion.Major, UnsupportedVersion.Minor)
+}
@@ -0,0 +1,61 @@
+package capabilities
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+
+	cases := []struct {
+		version           string
+		expected          Version
+		expectedErr       bool
+		expectedSupported bool
+	}{
+		{version: "3.9.1", expected: Version{Major: 3, Minor: 9, Patch: 1}, expectedSupported: true},
+		{version: "v3.6", expected: Version{Major: 3, Minor: 6}, expectedSupported: true},
+		{version: "3.10.0-rc.1", expected: Version{Major: 3, Minor: 10}, expectedSupported: true},
+		{version: "3.5.7", expected: Version{Major: 3, Minor: 5, Patch: 7}, expectedSupported: false},
+		{version: "4.0.0", expected: Version{Major: 4}, expectedSupported: false},
+		{version: "latest", expectedErr: true},
+		{version: "3", expectedErr: true},
+	}
+
+	for i, c := range cases {
+
+		version, err := ParseVersion(c.version)
+
+		if (err != nil) != c.expectedErr || version != c.expected || (err == nil && version.IsSupported() != c.expectedSupported) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %v\nActual: %v %v %v", i, c.expected, c.expectedSupported, c.expectedErr, version, version.IsSupported(), err)
+		}
+	}
+}
+
+func TestSupportsVersion(t *testing.T) {
+
+	quay36 := Version{Major: 3, Minor: 6, Patch: 4}
+	quay39 := Version{Major: 3, Minor: 9}
+
+	cases := []struct {
+		capabilities Capabilities
+		feature      string
+		expected     bool
+	}{
+		{capabilities: Capabilities{}, feature: QuotaManagement, expected: true},
+		{capabilities: Capabilities{Version: &quay36}, feature: QuotaManagement, expected: false},
+		{capabilities: Capabilities{Version: &quay39}, feature: QuotaManagement, expected: true},
+		{capabilities: Capabilities{Version: &quay39}, feature: AutoPrune, expected: false},
+		{capabilities: Capabilities{Version: &quay36}, feature: RepositoryMirroring, expected: true},
+		{capabilities: Capabilities{Detected: true, Version: &quay39, Features: map[string]bool{QuotaManagement: false}}, feature: QuotaManagement, expected: false},
+		{capabilities: Capabilities{Detected: true, Version: &quay36, Features: map[string]bool{ProxyCache: true}}, feature: ProxyCache, expected: false},
+	}
+
+	for i, c := range cases {
+
+		actual := c.capabilities.Supports(c.feature)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/console"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap created in synchronized namespaces when ConfigMapName is not set
+	DefaultConfigMapName = "quay-catalog-metadata"
+
+	// CatalogMetadataLabel identifies the ConfigMaps managed by the operator, letting developer portals list them across
+	// namespaces and ensuring ConfigMaps created by users are never removed
+	CatalogMetadataLabel = "quay.redhat.com/catalog-metadata"
+
+	// OrganizationKey holds the name of the Quay organization, or user account, of the namespace
+	OrganizationKey = "organization"
+	// OrganizationURLKey holds the URL of the organization within the Quay web interface
+	OrganizationURLKey = "organizationURL"
+	// RegistryKey holds the hostname images are pulled from and pushed to
+	RegistryKey = "registry"
+	// PullSecretKey holds the name of the pull secret of the default service account
+	PullSecretKey = "pullSecret"
+	// PushSecretKey holds the name of the push secret of the builder service account
+	PushSecretKey = "pushSecret"
+	// RepositoriesKey holds the repositories of the organization as a JSON list
+	RepositoriesKey = "repositories.json"
+)
+
+// Metadata describes the Quay resources of a synchronized namespace
+type Metadata struct {
+	QuayHostname     string
+	RegistryHostname string
+	Organization     string
+	UserAccount      bool
+	PullSecret       string
+	PushSecret       string
+	Repositories     []string
+}
+
+// Repository is an entry of the repositories of the organization
+type Repository struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Image string `json:"image"`
+}
+
+// GetConfigMapName returns the name of the ConfigMap created in synchronized namespaces
+func GetConfigMapName(catalogMetadata *quayv1.CatalogMetadata) string {
+
+	if catalogMetadata == nil || catalogMetadata.ConfigMapName == "" {
+		return DefaultConfigMapName
+	}
+
+	return catalogMetadata.ConfigMapName
+}
+
+// GenerateOrganizationURL returns the URL of the organization or user account within the Quay web interface
+func GenerateOrganizationURL(quayHostname string, organization string, userAccount bool) string {
+
+	if userAccount {
+		return fmt.Sprintf("%s/user/%s", strings.TrimSuffix(quayHostname, "/"), organization)
+	}
+
+	return console.GenerateOrganizationURL(quayHostname, organization)
+}
+
+// NewCatalogConfigMap returns the ConfigMap describing the Quay resources of a namespace, consumable by developer portals
+// such as Backstage. Repositories are sorted so that the ConfigMap only changes with the resources it describes
+func NewCatalogConfigMap(namespace string, catalogMetadata *quayv1.CatalogMetadata, metadata Metadata) (*corev1.ConfigMap, error) {
+
+	names := append([]string{}, metadata.Repositories...)
+	sort.Strings(names)
+
+	repositories := []Repository{}
+
+	for _, name := range names {
+		repositories = append(repositories, Repository{
+			Name:  name,
+			URL:   fmt.Sprintf("%s/repository/%s/%s", strings.TrimSuffix(metadata.QuayHostname, "/"), metadata.Organization, name),
+			Image: fmt.Sprintf("%s/%s/%s", metadata.RegistryHostname, metadata.Organization, name),
+		})
+	}
+
+	repositoriesJSON, err := json.Marshal(repositories)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetConfigMapName(catalogMetadata),
+			Namespace: namespace,
+			Labels: map[string]string{
+				CatalogMetadataLabel: "true",
+			},
+		},
+		Data: map[string]string{
+			OrganizationKey:    metadata.Organization,
+			OrganizationURLKey: GenerateOrganizationURL(metadata.QuayHostname, metadata.Organization, metadata.UserAccount),
+			RegistryKey:        metadata.RegistryHostname,
+			PullSecretKey:      metadata.PullSecret,
+			PushSecretKey:      metadata.PushSecret,
+			RepositoriesKey:    string(repositoriesJSON),
+		},
+	}, nil
+}
+
+// IsManagedConfigMap returns whether a ConfigMap was created by the operator
+func IsManagedConfigMap(configMap *corev1.ConfigMap) bool {
+	return configMap.Labels[CatalogMetadataLabel] == "true"
+}
@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+func TestNewCatalogConfigMap(t *testing.T) {
+
+	cases := []struct {
+		catalogMetadata *quayv1.CatalogMetadata
+		metadata        Metadata
+		expectedName    string
+		expectedData    map[string]string
+	}{
+		{
+			catalogMetadata: &quayv1.CatalogMetadata{},
+			metadata: Metadata{
+				QuayHostname:     "https://quay.example.com",
+				RegistryHostname: "quay.example.com",
+				Organization:     "openshift_app",
+				PullSecret:       "openshift_app-default-dockercfg",
+				PushSecret:       "openshift_app-builder-dockercfg",
+				Repositories:     []string{"web", "api"},
+			},
+			expectedName: DefaultConfigMapName,
+			expectedData: map[string]string{
+				OrganizationKey:    "openshift_app",
+				OrganizationURLKey: "https://quay.example.com/organization/openshift_app",
+				RegistryKey:        "quay.example.com",
+				PullSecretKey:      "openshift_app-default-dockercfg",
+				PushSecretKey:      "openshift_app-builder-dockercfg",
+				RepositoriesKey:    `[{"name":"api","url":"https://quay.example.com/repository/openshift_app/api","image":"quay.example.com/openshift_app/api"},{"name":"web","url":"https://quay.example.com/repository/openshift_app/web","image":"quay.example.com/openshift_app/web"}]`,
+			},
+		},
+		{
+			catalogMetadata: &quayv1.CatalogMetadata{ConfigMapName: "backstage"},
+			metadata: Metadata{
+				QuayHostname:     "https://quay.example.com/",
+				RegistryHostname: "registry.example.com",
+				Organization:     "alice",
+				UserAccount:      true,
+				PullSecret:       "alice-default-dockercfg",
+				PushSecret:       "alice-builder-dockercfg",
+			},
+			expectedName: "backstage",
+			expectedData: map[string]string{
+				OrganizationKey:    "alice",
+				OrganizationURLKey: "https://quay.example.com/user/alice",
+				RegistryKey:        "registry.example.com",
+				PullSecretKey:      "alice-default-dockercfg",
+				PushSecretKey:      "alice-builder-dockercfg",
+				RepositoriesKey:    `[]`,
+			},
+		},
+	}
+
+	for i, c := range cases {
+		configMap, err := NewCatalogConfigMap("test", c.catalogMetadata, c.metadata)
+
+		if err != nil || configMap.Name != c.expectedName || configMap.Namespace != "test" || !IsManagedConfigMap(configMap) || !reflect.DeepEqual(configMap.Data, c.expectedData) {
+			t.Errorf("Test case %d did not match\nExpected: %s %#v\nActual: %s %#v %v", i, c.expectedName, c.expectedData, configMap.Name, configMap.Data, err)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package quay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Cassettes are replayed against this URL unless recording
+const cassetteBaseURL = "https://quay.example.com"
+
+// Setting these variables records the cassettes of a Quay version against a live Quay instead of replaying them, such as
+// QUAY_RECORD_VERSION=quay-3.9 QUAY_RECORD_URL=https://quay.example.com QUAY_RECORD_TOKEN=<token> go test ./pkg/client/quay/
+const (
+	recordVersionEnv = "QUAY_RECORD_VERSION"
+	recordURLEnv     = "QUAY_RECORD_URL"
+	recordTokenEnv   = "QUAY_RECORD_TOKEN"
+)
+
+var redactedTokenPattern = regexp.MustCompile(`"token"\s*:\s*"[^"]*"`)
+
+// cassette is a sequence of Quay API interactions, recorded against a live Quay and replayed in order
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+// recordedRequest identifies a request by its method, path relative to the Quay URL, canonical query and JSON body
+type recordedRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// recordedResponse holds a JSON response body in Body, or any other response body in Text
+type recordedResponse struct {
+	StatusCode int             `json:"status"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Text       string          `json:"text,omitempty"`
+}
+
+// replayTransport answers requests with the interactions of a cassette, failing the test on requests deviating from it
+type replayTransport struct {
+	t            *testing.T
+	name         string
+	interactions []interaction
+	mutex        sync.Mutex
+	next         int
+}
+
+func (r *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	actual, err := newRecordedRequest(req, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.next >= len(r.interactions) {
+		r.t.Errorf("Unexpected request in cassette %s\nActual: %s %s?%s", r.name, actual.Method, actual.Path, actual.Query)
+		return nil, fmt.Errorf("no interaction left in cassette %s", r.name)
+	}
+
+	expected := r.interactions[r.next]
+
+	if !expected.Request.matches(actual) {
+		r.t.Errorf("Request %d of cassette %s did not match\nExpected: %s %s?%s %s\nActual: %s %s?%s %s", r.next, r.name,
+			expected.Request.Method, expected.Request.Path, expected.Request.Query, expected.Request.Body,
+			actual.Method, actual.Path, actual.Query, actual.Body)
+		return nil, fmt.Errorf("request %d of cassette %s did not match", r.next, r.name)
+	}
+
+	r.next++
+
+	body := []byte(expected.Response.Text)
+
+	if len(expected.Response.Body) > 0 {
+		body = expected.Response.Body
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", expected.Response.StatusCode, http.StatusText(expected.Response.StatusCode)),
+		StatusCode:    expected.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// recordTransport forwards requests to a live Quay, recording the interactions without credentials or robot account tokens
+type recordTransport struct {
+	delegate http.RoundTripper
+	baseURL  *url.URL
+	mutex    sync.Mutex
+	cassette cassette
+}
+
+func (r *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	recorded, err := newRecordedRequest(req, r.baseURL.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.delegate.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	sanitized := redactedTokenPattern.ReplaceAllString(strings.ReplaceAll(string(data), r.baseURL.Host, "quay.example.com"), `"token": "<redacted>"`)
+	response := recordedResponse{StatusCode: resp.StatusCode}
+
+	if compacted := new(bytes.Buffer); len(data) > 0 && json.Compact(compacted, []byte(sanitized)) == nil {
+		response.Body = compacted.Bytes()
+	} else {
+		response.Text = sanitized
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction{Request: recorded, Response: response})
+
+	return resp, nil
+}
+
+// newRecordedRequest describes a request relative to the path prefix of Quay
+func newRecordedRequest(req *http.Request, pathPrefix string) (recordedRequest, error) {
+
+	recorded := recordedRequest{
+		Method: req.Method,
+		Path:   "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(pathPrefix, "/")), "/"),
+		Query:  req.URL.Query().Encode(),
+	}
+
+	if req.Body == nil {
+		return recorded, nil
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+
+	if err != nil {
+		return recorded, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	if compacted := new(bytes.Buffer); len(data) > 0 {
+		if err := json.Compact(compacted, data); err != nil {
+			return recorded, err
+		}
+		recorded.Body = compacted.Bytes()
+	}
+
+	return recorded, nil
+}
+
+// matches compares requests, comparing their bodies as JSON values
+func (r recordedRequest) matches(other recordedRequest) bool {
+
+	if r.Method != other.Method || r.Path != other.Path || r.Query != other.Query || (len(r.Body) == 0) != (len(other.Body) == 0) {
+		return false
+	}
+
+	if len(r.Body) == 0 {
+		return true
+	}
+
+	var expected, actual interface{}
+
+	if json.Unmarshal(r.Body, &expected) != nil || json.Unmarshal(other.Body, &actual) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+func cassettePath(version string, name string) string {
+	return filepath.Join("testdata", version, "cassettes", name+".json")
+}
+
+// newCassetteClient returns a client replaying a cassette of a Quay version, or recording it when recording that version.
+// The test fails when requests remain unreplayed once it completes
+func newCassetteClient(t *testing.T, version string, name string) *QuayClient {
+
+	t.Helper()
+
+	if recordVersion := os.Getenv(recordVersionEnv); recordVersion != "" {
+
+		if recordVersion != version {
+			t.Skipf("Recording cassettes of %s", recordVersion)
+		}
+
+		baseURL, err := url.Parse(os.Getenv(recordURLEnv))
+
+		if err != nil || baseURL.Host == "" {
+			t.Fatalf("%s must be the URL of Quay when recording cassettes: %v", recordURLEnv, err)
+		}
+
+		recorder := &recordTransport{delegate: http.DefaultTransport, baseURL: baseURL}
+
+		t.Cleanup(func() {
+
+			data, err := json.MarshalIndent(recorder.cassette, "", "  ")
+
+			if err == nil {
+				err = ioutil.WriteFile(cassettePath(version, name), append(data, '\n'), 0644)
+			}
+
+			if err != nil {
+				t.Errorf("Failed to write cassette %s/%s: %v", version, name, err)
+			}
+		})
+
+		return NewClient(&http.Client{Transport: recorder}, baseURL.String(), os.Getenv(recordTokenEnv))
+	}
+
+	data, err := ioutil.ReadFile(cassettePath(version, name))
+
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+
+	recorded := cassette{}
+
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatalf("Failed to unmarshal cassette %s/%s: %v", version, name, err)
+	}
+
+	replayer := &replayTransport{t: t, name: version + "/" + name, interactions: recorded.Interactions}
+
+	t.Cleanup(func() {
+		if replayer.next != len(replayer.interactions) {
+			t.Errorf("Cassette %s was not fully replayed\nExpected: %d requests\nActual: %d requests", replayer.name, len(replayer.interactions), replayer.next)
+		}
+	})
+
+	return NewClient(&http.Client{Transport: replayer}, cassetteBaseURL, "token")
+}
+
+func TestCassettes(t *testing.T) {
+
+	versions := []string{"quay-3.6", "quay-3.9"}
+
+	cases := []struct {
+		cassette string
+		run      func(client *QuayClient) error
+	}{
+		{
+			cassette: "organization",
+			run: func(client *QuayClient) error {
+
+				_, resp, apiErr := client.GetOrganizationByname("openshift_app")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusNotFound {
+					return fmt.Errorf("expected a missing organization: %v", apiErr.Error)
+				}
+
+				created, resp, apiErr := client.CreateOrganization("openshift_app", "openshift_app@quay.example.com")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusCreated || !strings.Contains(created.Value, "Created") {
+					return fmt.Errorf("failed to create organization: %#v %v", created, apiErr.Error)
+				}
+
+				organization, resp, apiErr := client.GetOrganizationByname("openshift_app")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusOK || organization.Name != "openshift_app" || !organization.IsOrgAdmin || organization.Teams["owners"].Role != TeamRoleAdmin {
+					return fmt.Errorf("unexpected organization: %#v %v", organization, apiErr.Error)
+				}
+
+				return nil
+			},
+		},
+		{
+			cassette: "robot-accounts",
+			run: func(client *QuayClient) error {
+
+				robotAccount, resp, apiErr := client.CreateOrganizationRobotAccountWithMetadata("openshift_app", "builder", RobotAccountRequest{
+					Description:          "Robot account used by OpenShift builds",
+					UnstructuredMetadata: map[string]interface{}{"namespaceUID": "0d2f1a3c-7b5e-4d6f-9a8b-1c2d3e4f5a6b"},
+				})
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusCreated || robotAccount.Name != "openshift_app+builder" || robotAccount.Token == "" || robotAccount.UnstructuredMetadata["namespaceUID"] != "0d2f1a3c-7b5e-4d6f-9a8b-1c2d3e4f5a6b" {
+					return fmt.Errorf("unexpected robot account: %#v %v", robotAccount, apiErr.Error)
+				}
+
+				robotAccounts, resp, apiErr := client.GetOrganizationRobotAccounts("openshift_app")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusOK || len(robotAccounts.Robots) != 1 || robotAccounts.Robots[0].Token != "" {
+					return fmt.Errorf("unexpected robot accounts: %#v %v", robotAccounts, apiErr.Error)
+				}
+
+				prototype, resp, apiErr := client.CreateRobotPermissionForOrganization("openshift_app", "openshift_app+builder", "read")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusOK || prototype.ID == "" {
+					return fmt.Errorf("unexpected prototype: %#v %v", prototype, apiErr.Error)
+				}
+
+				prototypes, resp, apiErr := client.GetPrototypesByOrganization("openshift_app")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusOK || !IsRobotAccountInPrototypeByRole(prototypes.Prototypes, "openshift_app+builder", "read") {
+					return fmt.Errorf("unexpected prototypes: %#v %v", prototypes, apiErr.Error)
+				}
+
+				return nil
+			},
+		},
+		{
+			cassette: "repositories",
+			run: func(client *QuayClient) error {
+
+				repositories, _, apiErr := client.GetRepositoriesByOrganization("openshift_app")
+
+				if apiErr.Error != nil || len(repositories) != 2 || repositories[0].Name != "app" || repositories[1].Name != "base" {
+					return fmt.Errorf("unexpected repositories: %#v %v", repositories, apiErr.Error)
+				}
+
+				repository, resp, apiErr := client.GetRepository("openshift_app", "app")
+
+				if apiErr.Error != nil || resp.StatusCode != http.StatusOK || repository.Kind != RepositoryKindImage {
+					return fmt.Errorf("unexpected repository: %#v %v", repository, apiErr.Error)
+				}
+
+				tag, _, apiErr := client.GetRepositoryTag("openshift_app", "app", "latest")
+
+				if apiErr.Error != nil || tag.ManifestDigest == "" || tag.ManifestDigest != repository.Tags["latest"].ManifestDigest {
+					return fmt.Errorf("unexpected tag: %#v %v", tag, apiErr.Error)
+				}
+
+				manifest, _, apiErr := client.GetManifest("openshift_app", "app", "sha256:3c7e1a5b9d3f7a1c5e9b3d7f1a5c9e3b7d1f5a9c3e7b1d5f9a3c7e1b5d9f3a7c")
+
+				if apiErr.Error != nil {
+					return apiErr.Error
+				}
+
+				if platformManifests, err := manifest.GetPlatformManifests(); err != nil || len(platformManifests) != 2 {
+					return fmt.Errorf("unexpected manifest: %#v %v", platformManifests, err)
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, version := range versions {
+		for i, c := range cases {
+
+			t.Run(version+"/"+c.cassette, func(t *testing.T) {
+
+				if err := c.run(newCassetteClient(t, version, c.cassette)); err != nil {
+					t.Errorf("Test case %d did not match\n%v", i, err)
+				}
+			})
+		}
+	}
+}
+
+func TestRecordTransport(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"name": "openshift_app+builder", "token": "secret", "avatar": "https://%s/avatar"}`, r.Host)
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL + "/quay")
+	recorder := &recordTransport{delegate: server.Client().Transport, baseURL: baseURL}
+
+	robotAccount, _, apiErr := NewClient(&http.Client{Transport: recorder}, baseURL.String(), "token").CreateOrganizationRobotAccount("openshift_app", "builder")
+
+	if apiErr.Error != nil || robotAccount.Token != "secret" {
+		t.Fatalf("Expected the recorded client to receive the response\nActual: %#v %v", robotAccount, apiErr.Error)
+	}
+
+	expected := []interaction{
+		{
+			Request:  recordedRequest{Method: "PUT", Path: "/api/v1/organization/openshift_app/robots/builder"},
+			Response: recordedResponse{StatusCode: http.StatusCreated, Body: json.RawMessage(`{"name":"openshift_app+builder","token":"<redacted>","avatar":"https://quay.example.com/avatar"}`)},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, recorder.cassette.Interactions) {
+		t.Errorf("Recorded interactions did not match\nExpected: %#v\nActual: %#v", expected, recorder.cassette.Interactions)
+	}
+}
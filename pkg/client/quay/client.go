@@ -8,14 +8,18 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
 	"github.com/quay/quay-bridge-operator/pkg/utils"
 )
 
 type QuayClient struct {
-	BaseURL    *url.URL
-	httpClient *http.Client
-	AuthToken  string
+	BaseURL     *url.URL
+	httpClient  *http.Client
+	AuthToken   string
+	tokenSource TokenSource
 }
 
 func (c *QuayClient) GetUser() (User, *http.Response, QuayApiError) {
@@ -29,6 +33,84 @@ func (c *QuayClient) GetUser() (User, *http.Response, QuayApiError) {
 	return user, resp, QuayApiError{Error: err}
 }
 
+// InitializeUser creates the first user of a Quay without users, along with an OAuth token of the user when requested. Requires
+// FEATURE_USER_INITIALIZE and is rejected once Quay has users. The request is not authenticated
+func (c *QuayClient) InitializeUser(request UserInitializeRequest) (UserInitializeResponse, *http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", "/api/v1/user/initialize", request)
+	if err != nil {
+		return UserInitializeResponse{}, nil, QuayApiError{Error: err}
+	}
+	var userInitializeResponse UserInitializeResponse
+	resp, err := c.do(req, &userInitializeResponse)
+
+	return userInitializeResponse, resp, QuayApiError{Error: err}
+}
+
+// GetUserByName returns the public information of a user account
+func (c *QuayClient) GetUserByName(username string) (User, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/users/%s", username), nil)
+	if err != nil {
+		return User{}, nil, QuayApiError{Error: err}
+	}
+	var user User
+	resp, err := c.do(req, &user)
+
+	return user, resp, QuayApiError{Error: err}
+}
+
+// GetHealth checks whether the Quay instance is able to serve requests
+func (c *QuayClient) GetHealth() (*http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", "/health/instance", nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// VerifyRobotCredentials requests a registry token using the credentials of a robot account, returning whether Quay accepted
+// them. Deleted robot accounts and invalidated tokens are rejected by the token endpoint of the registry
+func (c *QuayClient) VerifyRobotCredentials(username string, password string) (bool, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/v2/auth?service=%s&account=%s", url.QueryEscape(c.BaseURL.Host), url.QueryEscape(username)), nil)
+	if err != nil {
+		return false, nil, QuayApiError{Error: err}
+	}
+	req.SetBasicAuth(username, password)
+
+	// The client is not used as it retries unauthorized requests using the token of the operator
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		metrics.RecordQuayAPIRequest(req.Method, 0)
+		return false, nil, QuayApiError{Error: err}
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	metrics.RecordQuayAPIRequest(req.Method, resp.StatusCode)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, resp, QuayApiError{}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, resp, QuayApiError{}
+	default:
+		return false, resp, QuayApiError{Error: fmt.Errorf("unexpected status code %d verifying robot account credentials", resp.StatusCode)}
+	}
+}
+
+// GetServerConfig returns the public configuration of Quay, including the features enabled on the instance
+func (c *QuayClient) GetServerConfig() (ServerConfig, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", "/config", nil)
+	if err != nil {
+		return ServerConfig{}, nil, QuayApiError{Error: err}
+	}
+	var serverConfig ServerConfig
+	resp, err := c.do(req, &serverConfig)
+
+	return serverConfig, resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) GetOrganizationByname(orgName string) (Organization, *http.Response, QuayApiError) {
 	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s", orgName), nil)
 	if err != nil {
@@ -40,11 +122,11 @@ func (c *QuayClient) GetOrganizationByname(orgName string) (Organization, *http.
 	return organization, resp, QuayApiError{Error: err}
 }
 
-func (c *QuayClient) CreateOrganization(name string) (StringValue, *http.Response, QuayApiError) {
+func (c *QuayClient) CreateOrganization(name string, email string) (StringValue, *http.Response, QuayApiError) {
 
 	newOrganization := OrganizationRequest{
 		Name:  name,
-		Email: fmt.Sprintf("%s@redhat.com", name),
+		Email: email,
 	}
 
 	req, err := c.newRequest("POST", "/api/v1/organization/", newOrganization)
@@ -57,6 +139,18 @@ func (c *QuayClient) CreateOrganization(name string) (StringValue, *http.Respons
 	return newOrganizationResponse, resp, QuayApiError{Error: err}
 }
 
+// UpdateOrganizationEmail changes the email address of an organization
+func (c *QuayClient) UpdateOrganizationEmail(name string, email string) (*http.Response, QuayApiError) {
+
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/organization/%s", name), OrganizationUpdateRequest{Email: email})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) GetOrganizationRobotAccount(organizationName string, robotName string) (RobotAccount, *http.Response, QuayApiError) {
 
 	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s/robots/%s", organizationName, robotName), nil)
@@ -81,6 +175,18 @@ func (c *QuayClient) GetPrototypesByOrganization(organizationName string) (Proto
 	return getPrototypeResponse, resp, QuayApiError{Error: err}
 }
 
+func (c *QuayClient) GetOrganizationRobotAccounts(organizationName string) (RobotAccountsResponse, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s/robots?token=false", organizationName), nil)
+	if err != nil {
+		return RobotAccountsResponse{}, nil, QuayApiError{Error: err}
+	}
+	var getOrganizationRobotsResponse RobotAccountsResponse
+	resp, err := c.do(req, &getOrganizationRobotsResponse)
+
+	return getOrganizationRobotsResponse, resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) CreateOrganizationRobotAccount(organizationName string, robotName string) (RobotAccount, *http.Response, QuayApiError) {
 
 	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/organization/%s/robots/%s", organizationName, robotName), nil)
@@ -93,6 +199,138 @@ func (c *QuayClient) CreateOrganizationRobotAccount(organizationName string, rob
 	return createOrganizationRobotResponse, resp, QuayApiError{Error: err}
 }
 
+// CreateOrganizationRobotAccountWithMetadata creates a robot account with a description and metadata
+func (c *QuayClient) CreateOrganizationRobotAccountWithMetadata(organizationName string, robotName string, robotAccount RobotAccountRequest) (RobotAccount, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/organization/%s/robots/%s", organizationName, robotName), robotAccount)
+	if err != nil {
+		return RobotAccount{}, nil, QuayApiError{Error: err}
+	}
+	var createOrganizationRobotResponse RobotAccount
+	resp, err := c.do(req, &createOrganizationRobotResponse)
+
+	return createOrganizationRobotResponse, resp, QuayApiError{Error: err}
+}
+
+// RegenerateOrganizationRobotAccountToken replaces the token of a robot account, keeping the permissions granted to it
+func (c *QuayClient) RegenerateOrganizationRobotAccountToken(organizationName string, robotName string) (RobotAccount, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/organization/%s/robots/%s/regenerate", organizationName, robotName), nil)
+	if err != nil {
+		return RobotAccount{}, nil, QuayApiError{Error: err}
+	}
+	var regenerateOrganizationRobotResponse RobotAccount
+	resp, err := c.do(req, &regenerateOrganizationRobotResponse)
+
+	return regenerateOrganizationRobotResponse, resp, QuayApiError{Error: err}
+}
+
+// GetOrganizationRobotAccountFederation returns the OIDC identities allowed to exchange their tokens for short lived tokens of a robot
+// account
+func (c *QuayClient) GetOrganizationRobotAccountFederation(organizationName string, robotName string) ([]RobotFederation, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s/robots/%s/federation", organizationName, robotName), nil)
+	if err != nil {
+		return nil, nil, QuayApiError{Error: err}
+	}
+	var robotFederation []RobotFederation
+	resp, err := c.do(req, &robotFederation)
+
+	return robotFederation, resp, QuayApiError{Error: err}
+}
+
+// SetOrganizationRobotAccountFederation replaces the OIDC identities allowed to exchange their tokens for short lived tokens of a robot
+// account
+func (c *QuayClient) SetOrganizationRobotAccountFederation(organizationName string, robotName string, robotFederation []RobotFederation) ([]RobotFederation, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/organization/%s/robots/%s/federation", organizationName, robotName), robotFederation)
+	if err != nil {
+		return nil, nil, QuayApiError{Error: err}
+	}
+	var updatedRobotFederation []RobotFederation
+	resp, err := c.do(req, &updatedRobotFederation)
+
+	return updatedRobotFederation, resp, QuayApiError{Error: err}
+}
+
+// ExchangeRobotFederationToken exchanges an OIDC token of an identity federated with a robot account for a short lived token of the
+// robot account, usable as the password of the robot account against the registry
+func (c *QuayClient) ExchangeRobotFederationToken(robotName string, oidcToken string) (RobotFederationToken, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", "/oauth2/federation/robot/token", nil)
+	if err != nil {
+		return RobotFederationToken{}, nil, QuayApiError{Error: err}
+	}
+	req.SetBasicAuth(robotName, oidcToken)
+
+	// The client is not used as it retries unauthorized requests using the token of the operator
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		metrics.RecordQuayAPIRequest(req.Method, 0)
+		return RobotFederationToken{}, nil, QuayApiError{Error: err}
+	}
+	defer resp.Body.Close()
+
+	metrics.RecordQuayAPIRequest(req.Method, resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return RobotFederationToken{}, resp, QuayApiError{Error: fmt.Errorf("unexpected status code %d exchanging token of robot account '%s'", resp.StatusCode, robotName)}
+	}
+
+	var robotFederationToken RobotFederationToken
+	err = json.NewDecoder(resp.Body).Decode(&robotFederationToken)
+
+	return robotFederationToken, resp, QuayApiError{Error: err}
+}
+
+// DeleteOrganizationRobotAccount deletes a robot account of an organization along with the permissions granted to it
+func (c *QuayClient) DeleteOrganizationRobotAccount(organizationName string, robotName string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/organization/%s/robots/%s", organizationName, robotName), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// GetUserRobotAccount returns a robot account of the authenticated user
+func (c *QuayClient) GetUserRobotAccount(robotName string) (RobotAccount, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/user/robots/%s", robotName), nil)
+	if err != nil {
+		return RobotAccount{}, nil, QuayApiError{Error: err}
+	}
+	var getUserRobotResponse RobotAccount
+	resp, err := c.do(req, &getUserRobotResponse)
+
+	return getUserRobotResponse, resp, QuayApiError{Error: err}
+}
+
+// CreateUserRobotAccountWithMetadata creates a robot account of the authenticated user with a description and metadata
+func (c *QuayClient) CreateUserRobotAccountWithMetadata(robotName string, robotAccount RobotAccountRequest) (RobotAccount, *http.Response, QuayApiError) {
+
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/user/robots/%s", robotName), robotAccount)
+	if err != nil {
+		return RobotAccount{}, nil, QuayApiError{Error: err}
+	}
+	var createUserRobotResponse RobotAccount
+	resp, err := c.do(req, &createUserRobotResponse)
+
+	return createUserRobotResponse, resp, QuayApiError{Error: err}
+}
+
+// DeleteUserRobotAccount deletes a robot account of the authenticated user along with the permissions granted to it
+func (c *QuayClient) DeleteUserRobotAccount(robotName string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/user/robots/%s", robotName), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) DeleteOrganization(orgName string) (*http.Response, QuayApiError) {
 	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/organization/%s", orgName), nil)
 	if err != nil {
@@ -103,6 +341,91 @@ func (c *QuayClient) DeleteOrganization(orgName string) (*http.Response, QuayApi
 	return resp, QuayApiError{Error: err}
 }
 
+// GetOrganizationApplications returns the OAuth applications of an organization along with their client secrets
+func (c *QuayClient) GetOrganizationApplications(orgName string) ([]Application, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s/applications", orgName), nil)
+	if err != nil {
+		return nil, nil, QuayApiError{Error: err}
+	}
+	var applicationsResponse ApplicationsResponse
+	resp, err := c.do(req, &applicationsResponse)
+
+	return applicationsResponse.Applications, resp, QuayApiError{Error: err}
+}
+
+// CreateOrganizationApplication creates an OAuth application in an organization
+func (c *QuayClient) CreateOrganizationApplication(orgName string, application ApplicationRequest) (Application, *http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/organization/%s/applications", orgName), application)
+	if err != nil {
+		return Application{}, nil, QuayApiError{Error: err}
+	}
+	var createdApplication Application
+	resp, err := c.do(req, &createdApplication)
+
+	return createdApplication, resp, QuayApiError{Error: err}
+}
+
+// ResetOrganizationApplicationClientSecret replaces the client secret of an OAuth application, revoking the tokens minted with the previous
+// client secret
+func (c *QuayClient) ResetOrganizationApplicationClientSecret(orgName string, clientID string) (Application, *http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/organization/%s/applications/%s/resetclientsecret", orgName, clientID), nil)
+	if err != nil {
+		return Application{}, nil, QuayApiError{Error: err}
+	}
+	var resetApplication Application
+	resp, err := c.do(req, &resetApplication)
+
+	return resetApplication, resp, QuayApiError{Error: err}
+}
+
+// DeleteOrganizationApplication deletes an OAuth application along with the tokens it minted
+func (c *QuayClient) DeleteOrganizationApplication(orgName string, clientID string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/organization/%s/applications/%s", orgName, clientID), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// GetOrganizationTeamMembers returns the members of a team of an organization, including the users invited to join it
+func (c *QuayClient) GetOrganizationTeamMembers(orgName string, teamName string) (TeamMembersResponse, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s/team/%s/members?includePending=true", orgName, teamName), nil)
+	if err != nil {
+		return TeamMembersResponse{}, nil, QuayApiError{Error: err}
+	}
+	var teamMembersResponse TeamMembersResponse
+	resp, err := c.do(req, &teamMembersResponse)
+
+	return teamMembersResponse, resp, QuayApiError{Error: err}
+}
+
+// AddOrganizationTeamMember adds a user to a team of an organization. Users who are not yet members of the organization are invited
+// instead when Quay sends emails
+func (c *QuayClient) AddOrganizationTeamMember(orgName string, teamName string, memberName string) (TeamMember, *http.Response, QuayApiError) {
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/organization/%s/team/%s/members/%s", orgName, teamName, memberName), nil)
+	if err != nil {
+		return TeamMember{}, nil, QuayApiError{Error: err}
+	}
+	var teamMember TeamMember
+	resp, err := c.do(req, &teamMember)
+
+	return teamMember, resp, QuayApiError{Error: err}
+}
+
+// CreateOrUpdateOrganizationTeam creates a team of an organization, or updates the role and description of an existing team
+func (c *QuayClient) CreateOrUpdateOrganizationTeam(orgName string, teamName string, role TeamRole, description string) (Team, *http.Response, QuayApiError) {
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/organization/%s/team/%s", orgName, teamName), map[string]string{"role": string(role), "description": description})
+	if err != nil {
+		return Team{}, nil, QuayApiError{Error: err}
+	}
+	var team Team
+	resp, err := c.do(req, &team)
+
+	return team, resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) CreateRobotPermissionForOrganization(organizationName string, robotAccount string, role string) (Prototype, *http.Response, QuayApiError) {
 
 	robotOrganizationPermission := Prototype{
@@ -125,6 +448,17 @@ func (c *QuayClient) CreateRobotPermissionForOrganization(organizationName strin
 	return newPrototypeResponse, resp, QuayApiError{Error: err}
 }
 
+// DeletePrototype deletes a default permission of an organization
+func (c *QuayClient) DeletePrototype(organizationName string, prototypeID string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/organization/%s/prototypes/%s", organizationName, prototypeID), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) GetRepository(orgName string, repositoryName string) (Repository, *http.Response, QuayApiError) {
 	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s", orgName, repositoryName), nil)
 	if err != nil {
@@ -136,14 +470,79 @@ func (c *QuayClient) GetRepository(orgName string, repositoryName string) (Repos
 	return repository, resp, QuayApiError{Error: err}
 }
 
+// GetRepositoryTag returns the active tag of a repository with the given name. The returned Tag is empty when the tag does not exist
+func (c *QuayClient) GetRepositoryTag(orgName string, repositoryName string, tagName string) (Tag, *http.Response, QuayApiError) {
+	query := url.Values{"specificTag": []string{tagName}, "onlyActiveTags": []string{"true"}}
+
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/tag/?%s", orgName, repositoryName, query.Encode()), nil)
+	if err != nil {
+		return Tag{}, nil, QuayApiError{Error: err}
+	}
+	var tagsResponse TagsResponse
+	resp, err := c.do(req, &tagsResponse)
+
+	for _, tag := range tagsResponse.Tags {
+		if tag.Name == tagName {
+			return tag, resp, QuayApiError{Error: err}
+		}
+	}
+
+	return Tag{}, resp, QuayApiError{Error: err}
+}
+
+// GetManifest returns the manifest of a repository with the given digest
+func (c *QuayClient) GetManifest(orgName string, repositoryName string, digest string) (Manifest, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/manifest/%s", orgName, repositoryName, digest), nil)
+	if err != nil {
+		return Manifest{}, nil, QuayApiError{Error: err}
+	}
+	var manifest Manifest
+	resp, err := c.do(req, &manifest)
+
+	return manifest, resp, QuayApiError{Error: err}
+}
+
+// GetRepositoriesByOrganization returns all repositories of an organization, following pagination
+func (c *QuayClient) GetRepositoriesByOrganization(orgName string) ([]Repository, *http.Response, QuayApiError) {
+
+	repositories := []Repository{}
+	query := url.Values{"namespace": []string{orgName}}
+
+	for {
+		req, err := c.newRequest("GET", "/api/v1/repository?"+query.Encode(), nil)
+		if err != nil {
+			return nil, nil, QuayApiError{Error: err}
+		}
+		var repositoriesResponse RepositoriesResponse
+		resp, err := c.do(req, &repositoriesResponse)
+
+		if err != nil || resp.StatusCode != 200 {
+			return nil, resp, QuayApiError{Error: err}
+		}
+
+		repositories = append(repositories, repositoriesResponse.Repositories...)
+
+		if repositoriesResponse.NextPage == "" {
+			return repositories, resp, QuayApiError{}
+		}
+
+		query.Set("next_page", repositoriesResponse.NextPage)
+	}
+}
+
 func (c *QuayClient) CreateRepository(namespace, name string) (RepositoryRequest, *http.Response, QuayApiError) {
+	return c.CreateRepositoryWithDescription(namespace, name, "")
+}
+
+// CreateRepositoryWithDescription creates a private repository described using Markdown
+func (c *QuayClient) CreateRepositoryWithDescription(namespace, name string, description string) (RepositoryRequest, *http.Response, QuayApiError) {
 
 	newRepository := RepositoryRequest{
 		Repository:  name,
 		Namespace:   namespace,
-		Kind:        "image",
+		Kind:        RepositoryKindImage,
 		Visibility:  "private",
-		Description: "",
+		Description: description,
 	}
 
 	req, err := c.newRequest("POST", "/api/v1/repository", newRepository)
@@ -156,8 +555,221 @@ func (c *QuayClient) CreateRepository(namespace, name string) (RepositoryRequest
 	return newRepositoryResponse, resp, QuayApiError{Error: err}
 }
 
+// UpdateRepositoryDescription replaces the Markdown description of a repository
+func (c *QuayClient) UpdateRepositoryDescription(orgName string, repositoryName string, description string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/repository/%s/%s", orgName, repositoryName), map[string]string{"description": description})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+func (c *QuayClient) GetRepositoryNotifications(orgName string, repositoryName string) (NotificationsResponse, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/notification/", orgName, repositoryName), nil)
+	if err != nil {
+		return NotificationsResponse{}, nil, QuayApiError{Error: err}
+	}
+	var notifications NotificationsResponse
+	resp, err := c.do(req, &notifications)
+
+	return notifications, resp, QuayApiError{Error: err}
+}
+
+func (c *QuayClient) CreateRepositoryNotification(orgName string, repositoryName string, notification NotificationRequest) (Notification, *http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/repository/%s/%s/notification/", orgName, repositoryName), notification)
+	if err != nil {
+		return Notification{}, nil, QuayApiError{Error: err}
+	}
+	var newNotificationResponse Notification
+	resp, err := c.do(req, &newNotificationResponse)
+
+	return newNotificationResponse, resp, QuayApiError{Error: err}
+}
+
+func (c *QuayClient) DeleteRepositoryNotification(orgName string, repositoryName string, uuid string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/repository/%s/%s/notification/%s", orgName, repositoryName, uuid), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+func (c *QuayClient) ChangeRepositoryVisibility(orgName string, repositoryName string, visibility string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/repository/%s/%s/changevisibility", orgName, repositoryName), map[string]string{"visibility": visibility})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// GetRepositoryAggregatedLogs returns the actions performed on a repository between two days, aggregated by kind and day
+func (c *QuayClient) GetRepositoryAggregatedLogs(orgName string, repositoryName string, start time.Time, end time.Time) ([]AggregatedLog, *http.Response, QuayApiError) {
+	query := url.Values{"starttime": []string{start.UTC().Format("01/02/2006")}, "endtime": []string{end.UTC().Format("01/02/2006")}}
+
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/aggregatelogs?%s", orgName, repositoryName, query.Encode()), nil)
+	if err != nil {
+		return nil, nil, QuayApiError{Error: err}
+	}
+	var aggregatedLogsResponse AggregatedLogsResponse
+	resp, err := c.do(req, &aggregatedLogsResponse)
+
+	return aggregatedLogsResponse.Aggregated, resp, QuayApiError{Error: err}
+}
+
+// GetOrganizationLogs returns the actions performed on an organization between two days, following pagination
+func (c *QuayClient) GetOrganizationLogs(orgName string, start time.Time, end time.Time) ([]Log, *http.Response, QuayApiError) {
+
+	logs := []Log{}
+	query := url.Values{"starttime": []string{start.UTC().Format("01/02/2006")}, "endtime": []string{end.UTC().Format("01/02/2006")}}
+
+	for {
+		req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/organization/%s/logs?%s", orgName, query.Encode()), nil)
+		if err != nil {
+			return nil, nil, QuayApiError{Error: err}
+		}
+		var logsResponse LogsResponse
+		resp, err := c.do(req, &logsResponse)
+
+		if err != nil || resp.StatusCode != 200 {
+			return nil, resp, QuayApiError{Error: err}
+		}
+
+		logs = append(logs, logsResponse.Logs...)
+
+		if logsResponse.NextPage == "" {
+			return logs, resp, QuayApiError{}
+		}
+
+		query.Set("next_page", logsResponse.NextPage)
+	}
+}
+
+// ChangeRepositoryState switches a repository between the NORMAL and MIRROR states
+func (c *QuayClient) ChangeRepositoryState(orgName string, repositoryName string, state string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/repository/%s/%s/changestate", orgName, repositoryName), map[string]string{"state": state})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// GetRepositoryMirror retrieves the mirroring configuration and the status of the last synchronization of a repository
+func (c *QuayClient) GetRepositoryMirror(orgName string, repositoryName string) (RepositoryMirror, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/mirror", orgName, repositoryName), nil)
+	if err != nil {
+		return RepositoryMirror{}, nil, QuayApiError{Error: err}
+	}
+	var mirror RepositoryMirror
+	resp, err := c.do(req, &mirror)
+
+	return mirror, resp, QuayApiError{Error: err}
+}
+
+// CreateRepositoryMirror configures a repository in the MIRROR state to mirror an external repository
+func (c *QuayClient) CreateRepositoryMirror(orgName string, repositoryName string, mirror RepositoryMirror) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/repository/%s/%s/mirror", orgName, repositoryName), mirror)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// SyncRepositoryMirror schedules the synchronization of a mirrored repository
+func (c *QuayClient) SyncRepositoryMirror(orgName string, repositoryName string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/repository/%s/%s/mirror/sync-now", orgName, repositoryName), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// GetRepositoryUserPermissions returns the roles granted on a repository to users and robot accounts, keyed by their name
+func (c *QuayClient) GetRepositoryUserPermissions(orgName string, repositoryName string) (RepositoryPermissionsResponse, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/permissions/user/", orgName, repositoryName), nil)
+	if err != nil {
+		return RepositoryPermissionsResponse{}, nil, QuayApiError{Error: err}
+	}
+	var permissionsResponse RepositoryPermissionsResponse
+	resp, err := c.do(req, &permissionsResponse)
+
+	return permissionsResponse, resp, QuayApiError{Error: err}
+}
+
+// DeleteRepositoryUserPermission revokes the role granted on a repository to a user or robot account
+func (c *QuayClient) DeleteRepositoryUserPermission(orgName string, repositoryName string, username string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/api/v1/repository/%s/%s/permissions/user/%s", orgName, repositoryName, username), nil)
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// SetRepositoryUserPermission grants a role on a repository to a user or robot account, replacing any role previously granted
+func (c *QuayClient) SetRepositoryUserPermission(orgName string, repositoryName string, username string, role string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/repository/%s/%s/permissions/user/%s", orgName, repositoryName, username), map[string]string{"role": role})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// GetRepositoryTeamPermissions returns the roles granted on a repository to teams, keyed by their name
+func (c *QuayClient) GetRepositoryTeamPermissions(orgName string, repositoryName string) (RepositoryPermissionsResponse, *http.Response, QuayApiError) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/api/v1/repository/%s/%s/permissions/team/", orgName, repositoryName), nil)
+	if err != nil {
+		return RepositoryPermissionsResponse{}, nil, QuayApiError{Error: err}
+	}
+	var permissionsResponse RepositoryPermissionsResponse
+	resp, err := c.do(req, &permissionsResponse)
+
+	return permissionsResponse, resp, QuayApiError{Error: err}
+}
+
+// SetRepositoryTeamPermission grants a role on a repository to a team, replacing any role previously granted
+func (c *QuayClient) SetRepositoryTeamPermission(orgName string, repositoryName string, teamName string, role string) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("PUT", fmt.Sprintf("/api/v1/repository/%s/%s/permissions/team/%s", orgName, repositoryName, teamName), map[string]string{"role": role})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
+// ChangeRepositoryTrust enables or disables trust (content signing) on a repository
+func (c *QuayClient) ChangeRepositoryTrust(orgName string, repositoryName string, trustEnabled bool) (*http.Response, QuayApiError) {
+	req, err := c.newRequest("POST", fmt.Sprintf("/api/v1/repository/%s/%s/changetrust", orgName, repositoryName), map[string]bool{"trust_enabled": trustEnabled})
+	if err != nil {
+		return nil, QuayApiError{Error: err}
+	}
+	resp, err := c.do(req, nil)
+
+	return resp, QuayApiError{Error: err}
+}
+
 func (c *QuayClient) newRequest(method, path string, body interface{}) (*http.Request, error) {
 	rel := &url.URL{Path: path}
+	if i := strings.Index(path, "?"); i >= 0 {
+		rel = &url.URL{Path: path[:i], RawQuery: path[i+1:]}
+	}
+	// Quay served behind a reverse proxy path keeps its path prefix
+	rel.Path = strings.TrimSuffix(c.BaseURL.Path, "/") + rel.Path
 	u := c.BaseURL.ResolveReference(rel)
 	var buf io.ReadWriter
 	if body != nil {
@@ -169,11 +781,11 @@ func (c *QuayClient) newRequest(method, path string, body interface{}) (*http.Re
 	}
 	req, err := http.NewRequest(method, u.String(), buf)
 
-	if !utils.IsZeroOfUnderlyingType(c.AuthToken) {
-		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
+	if err := c.authorize(req); err != nil {
 		return nil, err
 	}
 	if body != nil {
@@ -182,12 +794,70 @@ func (c *QuayClient) newRequest(method, path string, body interface{}) (*http.Re
 	req.Header.Set("Accept", "application/json")
 	return req, nil
 }
+
+// authorize sets the token of the client on a request
+func (c *QuayClient) authorize(req *http.Request) error {
+
+	authToken := c.AuthToken
+
+	if c.tokenSource != nil {
+
+		token, err := c.tokenSource.Token()
+
+		if err != nil {
+			return err
+		}
+
+		authToken = token
+	}
+
+	if !utils.IsZeroOfUnderlyingType(authToken) {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	return nil
+}
+
 func (c *QuayClient) do(req *http.Request, v interface{}) (*http.Response, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		metrics.RecordQuayAPIRequest(req.Method, 0)
 		return nil, err
 	}
-	defer resp.Body.Close()
+
+	// Access tokens may be revoked before they expire. Retry once with a fresh token
+	if invalidator, ok := c.tokenSource.(interface{ Invalidate() }); ok && resp.StatusCode == http.StatusUnauthorized && (req.Body == nil || req.GetBody != nil) {
+
+		metrics.RecordQuayAPIRequest(req.Method, resp.StatusCode)
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		invalidator.Invalidate()
+
+		retry := req.Clone(req.Context())
+
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.authorize(retry); err != nil {
+			return nil, err
+		}
+
+		if resp, err = c.httpClient.Do(retry); err != nil {
+			metrics.RecordQuayAPIRequest(req.Method, 0)
+			return nil, err
+		}
+	}
+	defer func() {
+		// Drain the body so the underlying connection can be reused by the pool
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	metrics.RecordQuayAPIRequest(req.Method, resp.StatusCode)
 
 	if v != nil {
 
@@ -220,3 +890,16 @@ func NewClient(httpClient *http.Client, baseUrl string, authToken string) *QuayC
 	quayClient.BaseURL, _ = url.Parse(baseUrl)
 	return &quayClient
 }
+
+// HTTPClient returns the http.Client used to reach Quay, which trusts the certificate authority of Quay
+func (c *QuayClient) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// NewClientWithTokenSource creates a client authenticating using the tokens provided by a TokenSource
+func NewClientWithTokenSource(httpClient *http.Client, baseUrl string, tokenSource TokenSource) *QuayClient {
+	quayClient := NewClient(httpClient, baseUrl, "")
+	quayClient.tokenSource = tokenSource
+
+	return quayClient
+}
@@ -0,0 +1,128 @@
+package quay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPathPrefix(t *testing.T) {
+
+	cases := []struct {
+		prefix       string
+		expectedPath string
+	}{
+		{prefix: "", expectedPath: "/api/v1/user"},
+		{prefix: "/", expectedPath: "/api/v1/user"},
+		{prefix: "/quay", expectedPath: "/quay/api/v1/user"},
+		{prefix: "/registry/quay/", expectedPath: "/registry/quay/api/v1/user"},
+	}
+
+	for i, c := range cases {
+
+		path := ""
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Write([]byte(`{"username": "operator"}`))
+		}))
+
+		_, _, apiErr := NewClient(server.Client(), server.URL+c.prefix, "token").GetUser()
+
+		server.Close()
+
+		if apiErr.Error != nil || path != c.expectedPath {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s %v", i, c.expectedPath, path, apiErr.Error)
+		}
+	}
+}
+
+func TestVerifyRobotCredentials(t *testing.T) {
+
+	cases := []struct {
+		username      string
+		password      string
+		statusCode    int
+		expected      bool
+		expectedError bool
+	}{
+		{username: "openshift_app+builder", password: "token", statusCode: http.StatusOK, expected: true},
+		{username: "openshift_app+builder", password: "revoked", statusCode: http.StatusUnauthorized, expected: false},
+		{username: "openshift_app+builder", password: "token", statusCode: http.StatusInternalServerError, expected: false, expectedError: true},
+	}
+
+	for i, c := range cases {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+
+			if !ok || r.URL.Path != "/v2/auth" || r.URL.Query().Get("account") != c.username || username != c.username || password != c.password {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			w.WriteHeader(c.statusCode)
+		}))
+
+		result, _, apiErr := NewClient(server.Client(), server.URL, "operator-token").VerifyRobotCredentials(c.username, c.password)
+
+		server.Close()
+
+		if result != c.expected || c.expectedError != (apiErr.Error != nil) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v", i, c.expected, c.expectedError, result, apiErr.Error)
+		}
+	}
+}
+
+func TestCreateVulnerabilityNotification(t *testing.T) {
+
+	cases := []struct {
+		severity      string
+		expectedLevel float64
+	}{
+		{severity: "Critical", expectedLevel: 1},
+		{severity: "High", expectedLevel: 2},
+		{severity: "Medium", expectedLevel: 3},
+		{severity: "Low", expectedLevel: 4},
+	}
+
+	for i, c := range cases {
+
+		priority, ok := VulnerabilityPriority(c.severity)
+
+		if !ok {
+			t.Errorf("Test case %d did not match\nExpected priority of severity %s", i, c.severity)
+			continue
+		}
+
+		body := map[string]interface{}{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"uuid": "notification"}`))
+		}))
+
+		_, _, apiErr := NewClient(server.Client(), server.URL, "token").CreateRepositoryNotification("org", "repo", NotificationRequest{
+			Title:       "vulnerabilities",
+			Event:       "vulnerability_found",
+			Method:      "webhook",
+			Config:      map[string]interface{}{"url": "https://example.com"},
+			EventConfig: map[string]interface{}{"level": priority},
+		})
+
+		server.Close()
+
+		eventConfig, _ := body["eventConfig"].(map[string]interface{})
+
+		// Quay expects the index of the priority as a number rather than the name of the severity
+		if level, isNumber := eventConfig["level"].(float64); apiErr.Error != nil || !isNumber || level != c.expectedLevel {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %#v %v", i, c.expectedLevel, eventConfig["level"], apiErr.Error)
+		}
+	}
+
+	if _, ok := VulnerabilityPriority("Unknown"); ok {
+		t.Errorf("Expected no priority for unsupported severity")
+	}
+}
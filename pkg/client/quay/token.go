@@ -0,0 +1,230 @@
+package quay
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OAuthClientIDKey is the key of the credentials Secret containing the client ID of the Quay OAuth application
+	OAuthClientIDKey = "oauthClientID"
+	// OAuthClientSecretKey is the key of the credentials Secret containing the client secret of the Quay OAuth application
+	OAuthClientSecretKey = "oauthClientSecret"
+	// OAuthRefreshTokenKey is the key of the credentials Secret containing the refresh token exchanged for access tokens
+	OAuthRefreshTokenKey = "oauthRefreshToken"
+
+	oauthTokenPath = "/oauth/access_token"
+
+	// tokenExpirySkew renews access tokens before they expire to account for clock drift and request latency
+	tokenExpirySkew = time.Minute
+)
+
+// TokenSource provides the token used to authenticate against the Quay API. Application specific tokens are not provided, as Quay only
+// accepts them to log in to the registry
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticTokenSource always provides the same long lived token
+type StaticTokenSource string
+
+// Token implements TokenSource
+func (s StaticTokenSource) Token() (string, error) {
+	return string(s), nil
+}
+
+// RefreshTokenStore writes the refresh token rotated by Quay to the credentials Secret it was read from
+type RefreshTokenStore func(secretNamespace string, secretName string, refreshToken string) error
+
+// OAuthTokenSource exchanges the credentials of a Quay OAuth application for short lived access tokens, which are cached until they expire.
+// A refresh token is exchanged when provided, otherwise the client credentials grant is used. Quay revokes a refresh token once rotated,
+// so rotated refresh tokens are passed to StoreRefreshToken, retried on the next call to Token until stored
+type OAuthTokenSource struct {
+	TokenURL          string
+	ClientID          string
+	ClientSecret      string
+	RefreshToken      string
+	HTTPClient        *http.Client
+	StoreRefreshToken func(refreshToken string) error
+
+	mutex        sync.Mutex
+	token        string
+	expiry       time.Time
+	now          func() time.Time
+	storePending bool
+	rotated      map[string]bool
+}
+
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Token implements TokenSource
+func (s *OAuthTokenSource) Token() (string, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || s.currentTime().Add(tokenExpirySkew).Before(s.expiry)) {
+		return s.token, s.storeRefreshToken()
+	}
+
+	form := url.Values{
+		"client_id":     {s.ClientID},
+		"client_secret": {s.ClientSecret},
+	}
+
+	if s.RefreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", s.RefreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+
+	resp, err := s.HTTPClient.PostForm(s.TokenURL, form)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	tokenResponse := oauthTokenResponse{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to decode token response with status code %d: %v", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || tokenResponse.AccessToken == "" {
+		return "", fmt.Errorf("token exchange failed with status code %d: %s", resp.StatusCode, tokenResponse.Error)
+	}
+
+	s.token = tokenResponse.AccessToken
+	s.expiry = time.Time{}
+
+	if tokenResponse.ExpiresIn > 0 {
+		s.expiry = s.currentTime().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+
+	// Refresh tokens may be rotated on each exchange
+	if tokenResponse.RefreshToken != "" && tokenResponse.RefreshToken != s.RefreshToken {
+
+		if s.rotated == nil {
+			s.rotated = map[string]bool{}
+		}
+
+		s.rotated[s.RefreshToken] = true
+		s.RefreshToken = tokenResponse.RefreshToken
+		s.storePending = s.StoreRefreshToken != nil
+	}
+
+	return s.token, s.storeRefreshToken()
+}
+
+// storeRefreshToken stores the rotated refresh token, if not stored yet. The access token remains cached when storing fails
+func (s *OAuthTokenSource) storeRefreshToken() error {
+
+	if !s.storePending {
+		return nil
+	}
+
+	if err := s.StoreRefreshToken(s.RefreshToken); err != nil {
+		return fmt.Errorf("failed to store rotated refresh token: %w", err)
+	}
+
+	s.storePending = false
+
+	return nil
+}
+
+// usesRefreshToken returns whether the refresh token read from the credentials Secret is the current refresh token, or one rotated by the
+// token source which may still be read from a cache not yet updated with the stored refresh token
+func (s *OAuthTokenSource) usesRefreshToken(refreshToken string) bool {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return refreshToken == s.RefreshToken || s.rotated[refreshToken]
+}
+
+// Invalidate discards the cached access token, such as when it has been rejected by Quay
+func (s *OAuthTokenSource) Invalidate() {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.token = ""
+}
+
+func (s *OAuthTokenSource) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+
+	return time.Now()
+}
+
+// SetRefreshTokenStore sets the RefreshTokenStore writing the refresh tokens rotated by Quay to the credentials Secrets of QuayIntegrations
+func (p *HTTPClientPool) SetRefreshTokenStore(store RefreshTokenStore) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.refreshTokenStore = store
+}
+
+// GetTokenSource returns the TokenSource for the credentials Secret of a QuayIntegration. OAuth applications are preferred over the long lived
+// token stored in tokenKey. Token sources exchanging OAuth credentials are shared so that access tokens are reused until they expire, and
+// replaced when the refresh token of the Secret is changed by another actor
+func (p *HTTPClientPool) GetTokenSource(httpClient *http.Client, baseURL string, secretNamespace string, secretName string, data map[string][]byte, tokenKey string) (TokenSource, error) {
+
+	clientID, ok := data[OAuthClientIDKey]
+
+	if !ok {
+
+		token, ok := data[tokenKey]
+
+		if !ok {
+			return nil, fmt.Errorf("credential Secret does not contain key '%s' or '%s'", tokenKey, OAuthClientIDKey)
+		}
+
+		return StaticTokenSource(token), nil
+	}
+
+	tokenURL := strings.TrimSuffix(baseURL, "/") + oauthTokenPath
+	key := sha256.Sum256([]byte(strings.Join([]string{tokenURL, string(clientID), string(data[OAuthClientSecretKey])}, "\x00")))
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if tokenSource, ok := p.tokenSources[key]; ok && tokenSource.usesRefreshToken(string(data[OAuthRefreshTokenKey])) {
+		return tokenSource, nil
+	}
+
+	tokenSource := &OAuthTokenSource{
+		TokenURL:     tokenURL,
+		ClientID:     string(clientID),
+		ClientSecret: string(data[OAuthClientSecretKey]),
+		RefreshToken: string(data[OAuthRefreshTokenKey]),
+		HTTPClient:   httpClient,
+	}
+
+	if store := p.refreshTokenStore; store != nil {
+		tokenSource.StoreRefreshToken = func(refreshToken string) error {
+			return store(secretNamespace, secretName, refreshToken)
+		}
+	}
+
+	p.tokenSources[key] = tokenSource
+
+	return tokenSource, nil
+}
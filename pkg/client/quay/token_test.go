@@ -0,0 +1,199 @@
+package quay
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOAuthTokenSource(t *testing.T) {
+
+	cases := []struct {
+		refreshToken      string
+		expiresIn         int64
+		elapsed           time.Duration
+		expectedGrantType string
+		expectedExchanges int
+	}{
+		{refreshToken: "refresh", expiresIn: 3600, elapsed: 0, expectedGrantType: "refresh_token", expectedExchanges: 1},
+		{refreshToken: "", expiresIn: 3600, elapsed: 0, expectedGrantType: "client_credentials", expectedExchanges: 1},
+		{refreshToken: "refresh", expiresIn: 3600, elapsed: 59 * time.Minute, expectedGrantType: "refresh_token", expectedExchanges: 2},
+		{refreshToken: "refresh", expiresIn: 0, elapsed: 24 * time.Hour, expectedGrantType: "refresh_token", expectedExchanges: 1},
+	}
+
+	for i, c := range cases {
+
+		exchanges := 0
+		grantType := ""
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			exchanges++
+			grantType = r.FormValue("grant_type")
+			json.NewEncoder(w).Encode(oauthTokenResponse{AccessToken: "access", ExpiresIn: c.expiresIn})
+		}))
+
+		now := time.Now()
+
+		tokenSource := &OAuthTokenSource{
+			TokenURL:     server.URL + oauthTokenPath,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RefreshToken: c.refreshToken,
+			HTTPClient:   server.Client(),
+			now:          func() time.Time { return now },
+		}
+
+		if token, err := tokenSource.Token(); err != nil || token != "access" {
+			t.Fatalf("Test case %d failed to exchange token: %v", i, err)
+		}
+
+		now = now.Add(c.elapsed)
+
+		if _, err := tokenSource.Token(); err != nil {
+			t.Fatalf("Test case %d failed to exchange token: %v", i, err)
+		}
+
+		server.Close()
+
+		if exchanges != c.expectedExchanges || grantType != c.expectedGrantType {
+			t.Errorf("Test case %d did not match\nExpected: %d %s\nActual: %d %s", i, c.expectedExchanges, c.expectedGrantType, exchanges, grantType)
+		}
+	}
+}
+
+func TestClientRetriesWithFreshToken(t *testing.T) {
+
+	exchanges := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path == oauthTokenPath {
+			exchanges++
+			json.NewEncoder(w).Encode(oauthTokenResponse{AccessToken: map[int]string{1: "revoked", 2: "valid"}[exchanges], ExpiresIn: 3600})
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(`{"username": "operator"}`))
+	}))
+	defer server.Close()
+
+	pool := NewHTTPClientPool(NewTransportOptions())
+
+	tokenSource, err := pool.GetTokenSource(server.Client(), server.URL, "quay-bridge-operator", "quay-integration", map[string][]byte{OAuthClientIDKey: []byte("client"), OAuthClientSecretKey: []byte("secret")}, "token")
+
+	if err != nil {
+		t.Fatalf("Failed to create token source: %v", err)
+	}
+
+	user, resp, apiErr := NewClientWithTokenSource(server.Client(), server.URL, tokenSource).GetUser()
+
+	if apiErr.Error != nil || resp.StatusCode != http.StatusOK || user.Username != "operator" || exchanges != 2 {
+		t.Errorf("Expected request to be retried with a fresh token, got status %v after %d exchanges: %v", resp, exchanges, apiErr.Error)
+	}
+}
+
+func TestGetTokenSource(t *testing.T) {
+
+	pool := NewHTTPClientPool(NewTransportOptions())
+
+	cases := []struct {
+		data        map[string][]byte
+		expectOAuth bool
+		expectErr   bool
+	}{
+		{data: map[string][]byte{"token": []byte("static")}, expectOAuth: false},
+		{data: map[string][]byte{"token": []byte("static"), OAuthClientIDKey: []byte("client")}, expectOAuth: true},
+		{data: map[string][]byte{}, expectErr: true},
+	}
+
+	for i, c := range cases {
+
+		tokenSource, err := pool.GetTokenSource(nil, "https://quay.example.com", "quay-bridge-operator", "quay-integration", c.data, "token")
+
+		if (err != nil) != c.expectErr {
+			t.Fatalf("Test case %d did not match\nExpected error: %t\nActual: %v", i, c.expectErr, err)
+		}
+
+		if _, ok := tokenSource.(*OAuthTokenSource); ok != c.expectOAuth {
+			t.Errorf("Test case %d did not match\nExpected OAuth: %t\nActual: %T", i, c.expectOAuth, tokenSource)
+		}
+	}
+
+	first, _ := pool.GetTokenSource(nil, "https://quay.example.com", "quay-bridge-operator", "quay-integration", map[string][]byte{OAuthClientIDKey: []byte("client")}, "token")
+	second, _ := pool.GetTokenSource(nil, "https://quay.example.com/", "quay-bridge-operator", "quay-integration", map[string][]byte{OAuthClientIDKey: []byte("client")}, "token")
+
+	if first != second {
+		t.Errorf("Expected token sources to be shared for identical credentials")
+	}
+}
+
+func TestRotatedRefreshTokenIsStored(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oauthTokenResponse{AccessToken: "access", RefreshToken: r.FormValue("refresh_token") + "-rotated", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	stored := []string{}
+	storeErr := errors.New("conflict")
+
+	pool := NewHTTPClientPool(NewTransportOptions())
+	pool.SetRefreshTokenStore(func(secretNamespace string, secretName string, refreshToken string) error {
+		stored = append(stored, secretNamespace+"/"+secretName+"/"+refreshToken)
+		return storeErr
+	})
+
+	data := map[string][]byte{OAuthClientIDKey: []byte("client"), OAuthClientSecretKey: []byte("secret"), OAuthRefreshTokenKey: []byte("refresh")}
+
+	tokenSource, _ := pool.GetTokenSource(server.Client(), server.URL, "quay-bridge-operator", "quay-integration", data, "token")
+
+	// The access token is retained when the rotated refresh token cannot be stored, and storing is retried on the next call
+	if token, err := tokenSource.Token(); token != "access" || !errors.Is(err, storeErr) {
+		t.Fatalf("Expected access token with store error, got %q: %v", token, err)
+	}
+
+	storeErr = nil
+
+	if _, err := tokenSource.Token(); err != nil {
+		t.Fatalf("Failed to store rotated refresh token: %v", err)
+	}
+
+	if _, err := tokenSource.Token(); err != nil {
+		t.Fatalf("Failed to get cached token: %v", err)
+	}
+
+	expected := []string{"quay-bridge-operator/quay-integration/refresh-rotated", "quay-bridge-operator/quay-integration/refresh-rotated"}
+
+	if !reflect.DeepEqual(expected, stored) {
+		t.Errorf("Stored refresh tokens did not match\nExpected: %#v\nActual: %#v", expected, stored)
+	}
+
+	cases := []struct {
+		refreshToken string
+		expectShared bool
+	}{
+		{refreshToken: "refresh", expectShared: true},
+		{refreshToken: "refresh-rotated", expectShared: true},
+		{refreshToken: "replaced", expectShared: false},
+	}
+
+	for i, c := range cases {
+
+		data[OAuthRefreshTokenKey] = []byte(c.refreshToken)
+
+		current, _ := pool.GetTokenSource(server.Client(), server.URL, "quay-bridge-operator", "quay-integration", data, "token")
+
+		if (current == tokenSource) != c.expectShared {
+			t.Errorf("Test case %d did not match\nExpected shared: %t\nActual: %t", i, c.expectShared, current == tokenSource)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package quay
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportOptions configures the HTTP transport shared by all Quay clients
+type TransportOptions struct {
+	RequestTimeout      time.Duration
+	DialTimeout         time.Duration
+	KeepAlive           time.Duration
+	IdleConnTimeout     time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+}
+
+// NewTransportOptions returns TransportOptions populated with defaults
+func NewTransportOptions() TransportOptions {
+	return TransportOptions{
+		RequestTimeout:      30 * time.Second,
+		DialTimeout:         10 * time.Second,
+		KeepAlive:           30 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+	}
+}
+
+// BindFlags binds the TransportOptions to flags in the provided FlagSet
+func (o *TransportOptions) BindFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&o.RequestTimeout, "quay-request-timeout", o.RequestTimeout, "Timeout of a single request made against the Quay API, including reading the response.")
+	fs.DurationVar(&o.DialTimeout, "quay-dial-timeout", o.DialTimeout, "Timeout for establishing a connection to the Quay API.")
+	fs.DurationVar(&o.KeepAlive, "quay-keep-alive", o.KeepAlive, "Keep-alive period of connections to the Quay API.")
+	fs.DurationVar(&o.IdleConnTimeout, "quay-idle-conn-timeout", o.IdleConnTimeout, "Time an idle connection to the Quay API is kept in the pool before being closed.")
+	fs.IntVar(&o.MaxIdleConns, "quay-max-idle-conns", o.MaxIdleConns, "Maximum number of idle connections to the Quay API kept in the pool.")
+	fs.IntVar(&o.MaxIdleConnsPerHost, "quay-max-idle-conns-per-host", o.MaxIdleConnsPerHost, "Maximum number of idle connections per Quay host kept in the pool.")
+}
+
+// HTTPClientPool provides http.Clients backed by pooled transports which are shared across controllers
+type HTTPClientPool struct {
+	options        TransportOptions
+	client         *http.Client
+	insecureClient *http.Client
+
+	mutex             sync.Mutex
+	caClients         map[[sha256.Size]byte]*http.Client
+	tokenSources      map[[sha256.Size]byte]*OAuthTokenSource
+	refreshTokenStore RefreshTokenStore
+}
+
+// NewHTTPClientPool creates a HTTPClientPool using the provided TransportOptions
+func NewHTTPClientPool(options TransportOptions) *HTTPClientPool {
+	return &HTTPClientPool{
+		options:        options,
+		client:         newHTTPClient(options, &tls.Config{}),
+		insecureClient: newHTTPClient(options, &tls.Config{InsecureSkipVerify: true}),
+		caClients:      map[[sha256.Size]byte]*http.Client{},
+		tokenSources:   map[[sha256.Size]byte]*OAuthTokenSource{},
+	}
+}
+
+// GetHTTPClient returns the shared http.Client, optionally skipping TLS verification
+func (p *HTTPClientPool) GetHTTPClient(insecureSkipVerify bool) *http.Client {
+	if insecureSkipVerify {
+		return p.insecureClient
+	}
+
+	return p.client
+}
+
+// GetHTTPClientWithCA returns a shared http.Client trusting the provided PEM encoded certificate authority in addition to the system roots.
+// An error is returned when the certificate authority does not contain any PEM encoded certificate
+func (p *HTTPClientPool) GetHTTPClientWithCA(insecureSkipVerify bool, certificateAuthority []byte) (*http.Client, error) {
+	if insecureSkipVerify || len(certificateAuthority) == 0 {
+		return p.GetHTTPClient(insecureSkipVerify), nil
+	}
+
+	key := sha256.Sum256(certificateAuthority)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if client, ok := p.caClients[key]; ok {
+		return client, nil
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if !rootCAs.AppendCertsFromPEM(certificateAuthority) {
+		return nil, errors.New("certificate authority does not contain any valid PEM encoded certificate")
+	}
+
+	client := newHTTPClient(p.options, &tls.Config{RootCAs: rootCAs})
+	p.caClients[key] = client
+
+	return client, nil
+}
+
+func newHTTPClient(options TransportOptions, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout: options.RequestTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   options.DialTimeout,
+				KeepAlive: options.KeepAlive,
+			}).DialContext,
+			TLSClientConfig:     tlsConfig,
+			TLSHandshakeTimeout: options.DialTimeout,
+			IdleConnTimeout:     options.IdleConnTimeout,
+			MaxIdleConns:        options.MaxIdleConns,
+			MaxIdleConnsPerHost: options.MaxIdleConnsPerHost,
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package quay
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetHTTPClientWithCA(t *testing.T) {
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	certificateAuthority := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	cases := []struct {
+		insecureSkipVerify   bool
+		certificateAuthority []byte
+		expectedErr          bool
+	}{
+		{certificateAuthority: nil, expectedErr: false},
+		{certificateAuthority: certificateAuthority, expectedErr: false},
+		{certificateAuthority: []byte("invalid"), expectedErr: true},
+		{insecureSkipVerify: true, certificateAuthority: []byte("invalid"), expectedErr: false},
+	}
+
+	pool := NewHTTPClientPool(NewTransportOptions())
+
+	for i, c := range cases {
+
+		client, err := pool.GetHTTPClientWithCA(c.insecureSkipVerify, c.certificateAuthority)
+
+		if (err != nil) != c.expectedErr || (err == nil) != (client != nil) {
+			t.Errorf("Test case %d did not match\nExpected error: %v\nActual: %v", i, c.expectedErr, err)
+		}
+	}
+
+	client, err := pool.GetHTTPClientWithCA(false, certificateAuthority)
+
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+
+	if err != nil {
+		t.Fatalf("Client did not trust the certificate authority: %v", err)
+	}
+
+	resp.Body.Close()
+}
@@ -1,5 +1,12 @@
 package quay
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type QuayRole string
 
 const (
@@ -16,6 +23,63 @@ type User struct {
 
 // Organization
 type Organization struct {
+	Name           string          `json:"name"`
+	Email          string          `json:"email,omitempty"`
+	IsAdmin        bool            `json:"is_admin,omitempty"`
+	IsMember       bool            `json:"is_member,omitempty"`
+	IsOrgAdmin     bool            `json:"is_org_admin,omitempty"`
+	CanCreateRepo  bool            `json:"can_create_repo,omitempty"`
+	InvoiceEmail   bool            `json:"invoice_email,omitempty"`
+	TagExpirationS int             `json:"tag_expiration_s,omitempty"`
+	Teams          map[string]Team `json:"teams,omitempty"`
+	OrderedTeams   []string        `json:"ordered_teams,omitempty"`
+	QuotaReport    *QuotaReport    `json:"quota_report,omitempty"`
+}
+
+// Team describes a team of an organization. Only reported to organization administrators
+type Team struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Role        TeamRole `json:"role"`
+	CanView     bool     `json:"can_view,omitempty"`
+	RepoCount   int      `json:"repo_count,omitempty"`
+	MemberCount int      `json:"member_count,omitempty"`
+	IsSynced    bool     `json:"is_synced,omitempty"`
+}
+
+// OwnersTeamName is the team created along with each organization, whose members administer the organization
+const OwnersTeamName = "owners"
+
+// TeamMembersResponse lists the members of a team of an organization
+type TeamMembersResponse struct {
+	Name    string       `json:"name"`
+	Members []TeamMember `json:"members"`
+	CanEdit bool         `json:"can_edit,omitempty"`
+}
+
+// TeamMember is a user or robot account member of a team, or a user invited to join it
+type TeamMember struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind,omitempty"`
+	IsRobot bool   `json:"is_robot,omitempty"`
+	Invited bool   `json:"invited,omitempty"`
+}
+
+// TeamRole is the role of the members of a team within their organization
+type TeamRole string
+
+const (
+	TeamRoleAdmin   TeamRole = "admin"
+	TeamRoleCreator TeamRole = "creator"
+	TeamRoleMember  TeamRole = "member"
+)
+
+// QuotaReport describes the storage consumed by an organization or repository. Only reported when quota management is enabled in Quay
+type QuotaReport struct {
+	QuotaBytes      int64  `json:"quota_bytes"`
+	ConfiguredQuota int64  `json:"configured_quota,omitempty"`
+	RunningBackfill string `json:"running_backfill,omitempty"`
+	BackfillStatus  string `json:"backfill_status,omitempty"`
 }
 
 type OrganizationRequest struct {
@@ -23,17 +87,86 @@ type OrganizationRequest struct {
 	Email string `json:"email,omitempty"`
 }
 
+type OrganizationUpdateRequest struct {
+	Email string `json:"email"`
+}
+
+// Application is an OAuth application of an organization
+type Application struct {
+	Name           string `json:"name"`
+	ClientID       string `json:"client_id"`
+	ClientSecret   string `json:"client_secret,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ApplicationURI string `json:"application_uri,omitempty"`
+	RedirectURI    string `json:"redirect_uri,omitempty"`
+}
+
+type ApplicationRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	ApplicationURI string `json:"application_uri,omitempty"`
+	RedirectURI    string `json:"redirect_uri,omitempty"`
+}
+
+type ApplicationsResponse struct {
+	Applications []Application `json:"applications"`
+}
+
 type PrototypesResponse struct {
 	Prototypes []Prototype `json:"prototypes"`
 }
 
 type RobotAccount struct {
-	Description string `json:"description"`
-	Created     string `json:"created"`
-	// UnstructuredData []byte  `json:"unstructured_metadata"`
-	LastAccessed string `json:"last_accessed"`
-	Token        string `json:"token"`
-	Name         string `json:"name"`
+	Description          string                 `json:"description"`
+	Created              string                 `json:"created"`
+	UnstructuredMetadata map[string]interface{} `json:"unstructured_metadata,omitempty"`
+	LastAccessed         string                 `json:"last_accessed"`
+	Token                string                 `json:"token"`
+	Name                 string                 `json:"name"`
+	// Teams and Repositories are only reported when listing robot accounts with their permissions
+	Teams        []RobotAccountTeam `json:"teams,omitempty"`
+	Repositories []string           `json:"repositories,omitempty"`
+}
+
+// RobotFederation is an OIDC identity allowed to exchange its tokens for short lived tokens of a robot account
+type RobotFederation struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+// RobotFederationToken is a short lived token of a robot account. ExpiresIn is the lifetime of the token in seconds, when reported
+type RobotFederationToken struct {
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expires_in,omitempty"`
+}
+
+// RobotAccountTeam describes a team a robot account is a member of
+type RobotAccountTeam struct {
+	Name   string `json:"name"`
+	Avatar Avatar `json:"avatar"`
+}
+
+// Avatar describes how Quay displays a user, robot account or team
+type Avatar struct {
+	Name  string `json:"name"`
+	Hash  string `json:"hash"`
+	Color string `json:"color"`
+	Kind  string `json:"kind"`
+}
+
+// RobotAccountRequest describes a robot account to create. The metadata cannot be changed once the robot account exists
+type RobotAccountRequest struct {
+	Description          string                 `json:"description,omitempty"`
+	UnstructuredMetadata map[string]interface{} `json:"unstructured_metadata,omitempty"`
+}
+
+type RobotAccountsResponse struct {
+	Robots []RobotAccount `json:"robots"`
+}
+
+type RepositoriesResponse struct {
+	Repositories []Repository `json:"repositories"`
+	NextPage     string       `json:"next_page,omitempty"`
 }
 
 type Prototype struct {
@@ -50,20 +183,114 @@ type Repository struct {
 	IsOrganization bool           `json:"is_organization"`
 	IsStarred      bool           `json:"is_starred"`
 	IsPublic       bool           `json:"is_public"`
+	IsFreeAccount  bool           `json:"is_free_account,omitempty"`
 	Name           string         `json:"name"`
 	Namespace      string         `json:"namespace"`
+	Kind           string         `json:"kind,omitempty"`
+	State          string         `json:"state,omitempty"`
 	Image          string         `json:"image"`
 	TagExpirationS int            `json:"tag_expiration_s"`
 	Tags           map[string]Tag `json:"tags"`
 	StatusToken    string         `json:"status_token"`
+	// LastModified, Popularity and QuotaReport are only reported when listing repositories
+	LastModified int64        `json:"last_modified,omitempty"`
+	Popularity   float64      `json:"popularity,omitempty"`
+	QuotaReport  *QuotaReport `json:"quota_report,omitempty"`
 }
 
 type Tag struct {
-	ImageId        string `json:"image_id"`
-	TrustEnabled   string `json:"trust_enabled"`
+	ImageId        string `json:"image_id,omitempty"`
+	DockerImageID  string `json:"docker_image_id,omitempty"`
 	Name           string `json:"name"`
 	ManifestDigest string `json:"manifest_digest,omitempty"`
-	Size           int    `json:"int"`
+	Size           int64  `json:"size,omitempty"`
+	IsManifestList bool   `json:"is_manifest_list,omitempty"`
+	Reversion      bool   `json:"reversion,omitempty"`
+	StartTS        int64  `json:"start_ts,omitempty"`
+	EndTS          int64  `json:"end_ts,omitempty"`
+	LastModified   string `json:"last_modified,omitempty"`
+	Expiration     string `json:"expiration,omitempty"`
+}
+
+// TagsResponse lists the tags of a repository
+type TagsResponse struct {
+	Tags []Tag `json:"tags"`
+}
+
+// Manifest is a manifest of a repository. ManifestData holds the raw manifest, which lists the manifests of each platform of manifest lists
+type Manifest struct {
+	Digest          string `json:"digest"`
+	IsManifestList  bool   `json:"is_manifest_list"`
+	ManifestData    string `json:"manifest_data,omitempty"`
+	ConfigMediaType string `json:"config_media_type,omitempty"`
+}
+
+// PlatformManifest is the manifest of a platform in a manifest list, the platform being formatted as os/architecture[/variant]
+type PlatformManifest struct {
+	Platform string `json:"platform"`
+	Digest   string `json:"digest"`
+}
+
+// manifestList is the subset of Docker manifest lists and OCI image indexes describing the manifest of each platform
+type manifestList struct {
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform *struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+			Variant      string `json:"variant,omitempty"`
+		} `json:"platform,omitempty"`
+	} `json:"manifests"`
+}
+
+// GetPlatformManifests returns the manifests of each platform of a manifest list sorted by platform. Manifests without a platform or
+// for an unknown platform, such as the attestations attached by BuildKit, are skipped
+func (m Manifest) GetPlatformManifests() ([]PlatformManifest, error) {
+
+	if !m.IsManifestList {
+		return nil, nil
+	}
+
+	list := manifestList{}
+
+	if err := json.Unmarshal([]byte(m.ManifestData), &list); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest list %s: %w", m.Digest, err)
+	}
+
+	platformManifests := []PlatformManifest{}
+
+	for _, manifest := range list.Manifests {
+
+		if manifest.Platform == nil || manifest.Platform.OS == "unknown" || manifest.Platform.Architecture == "unknown" {
+			continue
+		}
+
+		platform := manifest.Platform.OS + "/" + manifest.Platform.Architecture
+
+		if manifest.Platform.Variant != "" {
+			platform += "/" + manifest.Platform.Variant
+		}
+
+		platformManifests = append(platformManifests, PlatformManifest{Platform: platform, Digest: manifest.Digest})
+	}
+
+	sort.SliceStable(platformManifests, func(i, j int) bool {
+		return platformManifests[i].Platform < platformManifests[j].Platform
+	})
+
+	return platformManifests, nil
+}
+
+// RepositoryPermission is a role granted on a repository to a user or robot account
+type RepositoryPermission struct {
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	IsRobot bool   `json:"is_robot,omitempty"`
+}
+
+// RepositoryPermissionsResponse lists the roles granted on a repository keyed by the name of the user, robot account or team
+type RepositoryPermissionsResponse struct {
+	Permissions map[string]RepositoryPermission `json:"permissions"`
 }
 
 type RepositoryRequest struct {
@@ -81,6 +308,139 @@ type PrototypeDelegate struct {
 	OrgMember bool   `json:"is_org_member"`
 }
 
+// Notification represents a notification configured on a repository
+type Notification struct {
+	UUID             string                 `json:"uuid"`
+	Title            string                 `json:"title"`
+	Event            string                 `json:"event"`
+	Method           string                 `json:"method"`
+	Config           map[string]interface{} `json:"config"`
+	EventConfig      map[string]interface{} `json:"event_config"`
+	NumberOfFailures int                    `json:"number_of_failures"`
+}
+
+// UserInitializeRequest creates the first user of Quay. AccessToken requests an OAuth token of the user
+type UserInitializeRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	Email       string `json:"email,omitempty"`
+	AccessToken bool   `json:"access_token"`
+}
+
+type UserInitializeResponse struct {
+	Username          string `json:"username"`
+	Email             string `json:"email,omitempty"`
+	EncryptedPassword string `json:"encrypted_password,omitempty"`
+	AccessToken       string `json:"access_token,omitempty"`
+}
+
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+}
+
+type NotificationRequest struct {
+	Title       string                 `json:"title"`
+	Event       string                 `json:"event"`
+	Method      string                 `json:"method"`
+	Config      map[string]interface{} `json:"config"`
+	EventConfig map[string]interface{} `json:"eventConfig"`
+}
+
+// vulnerabilityPriorities maps the severities of vulnerabilities to the index of their priority in Quay, the most severe having the
+// lowest index. vulnerability_found notifications are sent for vulnerabilities whose priority index is at most the configured level
+var vulnerabilityPriorities = map[string]int{
+	"Critical": 1,
+	"High":     2,
+	"Medium":   3,
+	"Low":      4,
+}
+
+// VulnerabilityPriority returns the index of the priority of a severity in Quay, used as the level of vulnerability_found notifications
+func VulnerabilityPriority(severity string) (int, bool) {
+	priority, ok := vulnerabilityPriorities[severity]
+	return priority, ok
+}
+
+// RepositoryMirror represents the mirroring configuration of a repository in the MIRROR state
+type RepositoryMirror struct {
+	IsEnabled                bool                   `json:"is_enabled"`
+	ExternalReference        string                 `json:"external_reference"`
+	ExternalRegistryUsername string                 `json:"external_registry_username,omitempty"`
+	ExternalRegistryPassword string                 `json:"external_registry_password,omitempty"`
+	ExternalRegistryConfig   map[string]interface{} `json:"external_registry_config,omitempty"`
+	SyncInterval             int                    `json:"sync_interval"`
+	SyncStartDate            string                 `json:"sync_start_date"`
+	SyncStatus               string                 `json:"sync_status,omitempty"`
+	RobotUsername            string                 `json:"robot_username"`
+	RootRule                 RepositoryMirrorRule   `json:"root_rule"`
+}
+
+// RepositoryMirrorRule selects the tags mirrored
+type RepositoryMirrorRule struct {
+	RuleKind  string   `json:"rule_kind"`
+	RuleValue []string `json:"rule_value"`
+}
+
+const (
+	// RepositoryStateNormal repositories accept pushes
+	RepositoryStateNormal = "NORMAL"
+	// RepositoryStateReadOnly repositories reject pushes
+	RepositoryStateReadOnly = "READ_ONLY"
+	// RepositoryStateMirror repositories are populated by mirroring
+	RepositoryStateMirror = "MIRROR"
+
+	// RepositoryKindImage repositories contain container images
+	RepositoryKindImage = "image"
+	// RepositoryKindApplication repositories contain application bundles
+	RepositoryKindApplication = "application"
+
+	// MirrorSyncSuccess is the status of a mirror whose last synchronization succeeded
+	MirrorSyncSuccess = "SYNC_SUCCESS"
+	// MirrorSyncFailed is the status of a mirror whose last synchronization failed
+	MirrorSyncFailed = "SYNC_FAILED"
+)
+
+// AggregatedLog counts the actions of a kind performed on a day
+type AggregatedLog struct {
+	Kind     string `json:"kind"`
+	Count    int64  `json:"count"`
+	Datetime string `json:"datetime"`
+}
+
+// AggregatedLogsResponse lists the actions performed on a repository aggregated by kind and day
+type AggregatedLogsResponse struct {
+	Aggregated []AggregatedLog `json:"aggregated"`
+}
+
+// LogPerformer describes the user or robot account having performed an action
+type LogPerformer struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	IsRobot bool   `json:"is_robot"`
+}
+
+// Log describes an action performed on an organization or one of its repositories
+type Log struct {
+	Kind      string                 `json:"kind"`
+	Datetime  string                 `json:"datetime"`
+	IP        string                 `json:"ip,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Performer *LogPerformer          `json:"performer,omitempty"`
+}
+
+// LogsResponse lists the actions performed on an organization
+type LogsResponse struct {
+	Logs     []Log  `json:"logs"`
+	NextPage string `json:"next_page,omitempty"`
+}
+
+// ServerConfig describes the public configuration of a Quay instance
+type ServerConfig struct {
+	Config        map[string]interface{} `json:"config,omitempty"`
+	Features      map[string]interface{} `json:"features,omitempty"`
+	RegistryState string                 `json:"registry_state,omitempty"`
+}
+
 // StringValue represents an object containing a single string
 type StringValue struct {
 	Value string
@@ -103,3 +463,86 @@ func IsRobotAccountInPrototypeByRole(prototypes []Prototype, robotAccount string
 	return false
 
 }
+
+// MissingTeamMembers returns the users which are neither members of a team nor invited to join it, in the order they are provided
+func MissingTeamMembers(members []TeamMember, users []string) []string {
+
+	present := map[string]bool{}
+
+	for _, member := range members {
+		present[strings.ToLower(member.Name)] = true
+	}
+
+	missing := []string{}
+
+	for _, user := range users {
+
+		user = strings.ToLower(strings.TrimSpace(user))
+
+		if user == "" || present[user] {
+			continue
+		}
+
+		present[user] = true
+		missing = append(missing, user)
+	}
+
+	return missing
+}
+
+// DiffNotifications compares the notifications configured on a repository with the desired notifications. Only notifications
+// with a title starting with managedTitlePrefix are considered managed and are returned for deletion when they have drifted
+func DiffNotifications(existing []Notification, desired []NotificationRequest, managedTitlePrefix string) ([]NotificationRequest, []string) {
+
+	toCreate := []NotificationRequest{}
+	toDelete := []string{}
+	matched := map[int]bool{}
+
+	for _, notification := range existing {
+
+		if !strings.HasPrefix(notification.Title, managedTitlePrefix) {
+			continue
+		}
+
+		found := false
+
+		for i, request := range desired {
+			if !matched[i] && isNotificationEqual(notification, request) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			toDelete = append(toDelete, notification.UUID)
+		}
+	}
+
+	for i, request := range desired {
+		if !matched[i] {
+			toCreate = append(toCreate, request)
+		}
+	}
+
+	return toCreate, toDelete
+}
+
+func isNotificationEqual(notification Notification, request NotificationRequest) bool {
+	return notification.Title == request.Title &&
+		notification.Event == request.Event &&
+		notification.Method == request.Method &&
+		isConfigEqual(notification.Config, request.Config) &&
+		isConfigEqual(notification.EventConfig, request.EventConfig)
+}
+
+func isConfigEqual(existing map[string]interface{}, desired map[string]interface{}) bool {
+
+	for key, value := range desired {
+		if fmt.Sprint(existing[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,6 +1,10 @@
 package quay
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -75,3 +79,232 @@ func TestIsRobotAccountInPrototypeByRole(t *testing.T) {
 		})
 	}
 }
+
+func TestMissingTeamMembers(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		members  []TeamMember
+		users    []string
+		expected []string
+	}{
+		{
+			name:     "test-missing-team-members",
+			members:  []TeamMember{{Name: "operator", Kind: "user"}, {Name: "alice", Kind: "user"}},
+			users:    []string{"alice", "Bob", "bob"},
+			expected: []string{"bob"},
+		},
+		{
+			name:     "test-missing-team-members-invited",
+			members:  []TeamMember{{Name: "alice", Kind: "invite", Invited: true}},
+			users:    []string{"alice"},
+			expected: []string{},
+		},
+		{
+			name:     "test-missing-team-members-empty",
+			users:    []string{" alice ", ""},
+			expected: []string{"alice"},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			result := MissingTeamMembers(c.members, c.users)
+
+			if !reflect.DeepEqual(c.expected, result) {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestDiffNotifications(t *testing.T) {
+
+	desired := NotificationRequest{
+		Title:       "[managed] push",
+		Event:       "repo_push",
+		Method:      "slack",
+		Config:      map[string]interface{}{"url": "https://hooks.slack.com/services/a"},
+		EventConfig: map[string]interface{}{},
+	}
+
+	cases := []struct {
+		name             string
+		existing         []Notification
+		desired          []NotificationRequest
+		expectedCreate   int
+		expectedDeletion []string
+	}{
+		{
+			name:             "test-create-missing-notification",
+			existing:         []Notification{},
+			desired:          []NotificationRequest{desired},
+			expectedCreate:   1,
+			expectedDeletion: []string{},
+		},
+		{
+			name: "test-matching-notification",
+			existing: []Notification{
+				{UUID: "1", Title: "[managed] push", Event: "repo_push", Method: "slack", Config: map[string]interface{}{"url": "https://hooks.slack.com/services/a"}},
+			},
+			desired:          []NotificationRequest{desired},
+			expectedCreate:   0,
+			expectedDeletion: []string{},
+		},
+		{
+			name: "test-drifted-notification",
+			existing: []Notification{
+				{UUID: "1", Title: "[managed] push", Event: "repo_push", Method: "slack", Config: map[string]interface{}{"url": "https://hooks.slack.com/services/b"}},
+			},
+			desired:          []NotificationRequest{desired},
+			expectedCreate:   1,
+			expectedDeletion: []string{"1"},
+		},
+		{
+			name: "test-unmanaged-notification",
+			existing: []Notification{
+				{UUID: "1", Title: "push", Event: "repo_push", Method: "email", Config: map[string]interface{}{"email": "user@example.com"}},
+				{UUID: "2", Title: "[managed] removed", Event: "repo_push", Method: "email", Config: map[string]interface{}{"email": "user@example.com"}},
+			},
+			desired:          []NotificationRequest{},
+			expectedCreate:   0,
+			expectedDeletion: []string{"2"},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			toCreate, toDelete := DiffNotifications(c.existing, c.desired, "[managed] ")
+
+			if len(toCreate) != c.expectedCreate || !reflect.DeepEqual(c.expectedDeletion, toDelete) {
+				t.Errorf("Test case %d did not match\nExpected: %d %#v\nActual: %d %#v", i, c.expectedCreate, c.expectedDeletion, len(toCreate), toDelete)
+			}
+		})
+	}
+}
+
+func TestGetPlatformManifests(t *testing.T) {
+
+	cases := []struct {
+		manifest      Manifest
+		expected      []PlatformManifest
+		expectedError bool
+	}{
+		{
+			manifest: Manifest{Digest: "sha256:a", IsManifestList: false, ManifestData: `{"schemaVersion":2,"layers":[]}`},
+			expected: nil,
+		},
+		{
+			manifest: Manifest{Digest: "sha256:a", IsManifestList: true, ManifestData: `{"manifests":[{"digest":"sha256:s390x","platform":{"architecture":"s390x","os":"linux"}},{"digest":"sha256:amd64","platform":{"architecture":"amd64","os":"linux"}}]}`},
+			expected: []PlatformManifest{{Platform: "linux/amd64", Digest: "sha256:amd64"}, {Platform: "linux/s390x", Digest: "sha256:s390x"}},
+		},
+		{
+			manifest: Manifest{Digest: "sha256:a", IsManifestList: true, ManifestData: `{"manifests":[{"digest":"sha256:attestation","platform":{"architecture":"unknown","os":"unknown"}},{"digest":"sha256:none"}]}`},
+			expected: []PlatformManifest{},
+		},
+		{
+			manifest:      Manifest{Digest: "sha256:a", IsManifestList: true, ManifestData: "not json"},
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		platformManifests, err := c.manifest.GetPlatformManifests()
+
+		if (err != nil) != c.expectedError || (!c.expectedError && !reflect.DeepEqual(c.expected, platformManifests)) {
+			t.Errorf("Test case %d did not match\nExpected: %#v %v\nActual: %#v %v", i, c.expected, c.expectedError, platformManifests, err)
+		}
+	}
+}
+
+func TestRecordedResponses(t *testing.T) {
+
+	versions := []string{"quay-3.6", "quay-3.9"}
+
+	cases := []struct {
+		file     string
+		response func() interface{}
+		check    func(response interface{}) bool
+	}{
+		{
+			file:     "manifest.json",
+			response: func() interface{} { return &Manifest{} },
+			check: func(response interface{}) bool {
+				platformManifests, err := response.(*Manifest).GetPlatformManifests()
+				return err == nil && len(platformManifests) == 2 && platformManifests[0].Platform == "linux/amd64" && platformManifests[1].Platform == "linux/arm64/v8"
+			},
+		},
+		{
+			file:     "organization.json",
+			response: func() interface{} { return &Organization{} },
+			check: func(response interface{}) bool {
+				organization := response.(*Organization)
+				return organization.IsOrgAdmin && organization.TagExpirationS == 1209600 && organization.Teams["builders"].Role == TeamRoleMember && organization.Teams["owners"].Role == TeamRoleAdmin && len(organization.OrderedTeams) == 2
+			},
+		},
+		{
+			file:     "repository.json",
+			response: func() interface{} { return &Repository{} },
+			check: func(response interface{}) bool {
+				repository := response.(*Repository)
+				return repository.Kind == RepositoryKindImage && (repository.State == RepositoryStateNormal || repository.State == RepositoryStateMirror) && !repository.TrustEnabled && repository.Tags["latest"].Size == 28765432
+			},
+		},
+		{
+			file:     "robots.json",
+			response: func() interface{} { return &RobotAccountsResponse{} },
+			check: func(response interface{}) bool {
+				robots := response.(*RobotAccountsResponse).Robots
+				return len(robots) == 1 && robots[0].Description != "" && len(robots[0].Teams) == 1 && robots[0].Teams[0].Name == "builders" && reflect.DeepEqual([]string{"app", "base"}, robots[0].Repositories)
+			},
+		},
+		{
+			file:     "tags.json",
+			response: func() interface{} { return &TagsResponse{} },
+			check: func(response interface{}) bool {
+				tags := response.(*TagsResponse).Tags
+				return len(tags) == 1 && tags[0].StartTS == 1622541600 && tags[0].Size == 28765432 && tags[0].ManifestDigest != ""
+			},
+		},
+	}
+
+	for _, version := range versions {
+		for i, c := range cases {
+
+			data, err := ioutil.ReadFile(filepath.Join("testdata", version, c.file))
+
+			if err != nil {
+				t.Fatalf("Failed to read recorded response: %v", err)
+			}
+
+			response := c.response()
+
+			if err := json.Unmarshal(data, response); err != nil {
+				t.Errorf("Test case %d did not match\nFailed to unmarshal %s/%s: %v", i, version, c.file, err)
+				continue
+			}
+
+			if !c.check(response) {
+				t.Errorf("Test case %d did not match\nUnexpected fields in %s/%s: %#v", i, version, c.file, response)
+			}
+
+			marshaled, err := json.Marshal(response)
+
+			if err != nil {
+				t.Errorf("Test case %d did not match\nFailed to marshal %s/%s: %v", i, version, c.file, err)
+				continue
+			}
+
+			roundTripped := c.response()
+
+			if err := json.Unmarshal(marshaled, roundTripped); err != nil || !reflect.DeepEqual(response, roundTripped) {
+				t.Errorf("Test case %d did not match\nRound trip of %s/%s\nExpected: %#v\nActual: %#v", i, version, c.file, response, roundTripped)
+			}
+		}
+	}
+}
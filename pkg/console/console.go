@@ -0,0 +1,53 @@
+package console
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// ConsoleLinkGVK is the GroupVersionKind of the OpenShift Console ConsoleLink resource
+	ConsoleLinkGVK = schema.GroupVersionKind{Group: "console.openshift.io", Version: "v1", Kind: "ConsoleLink"}
+)
+
+const (
+	// NamespaceDashboardLocation displays a ConsoleLink on the dashboard of the listed namespaces
+	NamespaceDashboardLocation = "NamespaceDashboard"
+)
+
+//+kubebuilder:rbac:groups=console.openshift.io,resources=consolelinks,verbs=get;list;watch;create;update;patch;delete
+
+// GenerateConsoleLinkName returns the name of the ConsoleLink of a namespace. ConsoleLinks are cluster scoped
+func GenerateConsoleLinkName(namespace string) string {
+	return fmt.Sprintf("quay-organization-%s", namespace)
+}
+
+// GenerateOrganizationURL returns the URL of an organization within the Quay web interface
+func GenerateOrganizationURL(quayHostname string, organizationName string) string {
+	return fmt.Sprintf("%s/organization/%s", strings.TrimSuffix(quayHostname, "/"), organizationName)
+}
+
+// NewConsoleLink returns a ConsoleLink on the dashboard of a namespace pointing to its organization in Quay
+func NewConsoleLink(namespace string, quayHostname string, organizationName string) *unstructured.Unstructured {
+
+	consoleLink := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"href":     GenerateOrganizationURL(quayHostname, organizationName),
+				"text":     fmt.Sprintf("Quay Organization %s", organizationName),
+				"location": NamespaceDashboardLocation,
+				"namespaceDashboard": map[string]interface{}{
+					"namespaces": []interface{}{namespace},
+				},
+			},
+		},
+	}
+
+	consoleLink.SetGroupVersionKind(ConsoleLinkGVK)
+	consoleLink.SetName(GenerateConsoleLinkName(namespace))
+
+	return consoleLink
+}
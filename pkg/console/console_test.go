@@ -0,0 +1,52 @@
+package console
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewConsoleLink(t *testing.T) {
+
+	cases := []struct {
+		name             string
+		namespace        string
+		quayHostname     string
+		organizationName string
+		expectedHref     string
+	}{
+		{
+			name:             "test-console-link",
+			namespace:        "test",
+			quayHostname:     "https://quay.example.com",
+			organizationName: "openshift_test",
+			expectedHref:     "https://quay.example.com/organization/openshift_test",
+		},
+		{
+			name:             "test-console-link-trailing-slash",
+			namespace:        "test",
+			quayHostname:     "https://quay.example.com/",
+			organizationName: "openshift_test",
+			expectedHref:     "https://quay.example.com/organization/openshift_test",
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			consoleLink := NewConsoleLink(c.namespace, c.quayHostname, c.organizationName)
+
+			href, _, _ := unstructured.NestedString(consoleLink.Object, "spec", "href")
+			namespaces, _, _ := unstructured.NestedStringSlice(consoleLink.Object, "spec", "namespaceDashboard", "namespaces")
+
+			if href != c.expectedHref || len(namespaces) != 1 || namespaces[0] != c.namespace {
+				t.Errorf("Test case %d did not match\nExpected: %s %s\nActual: %s %v", i, c.expectedHref, c.namespace, href, namespaces)
+			}
+
+			if consoleLink.GetKind() != ConsoleLinkGVK.Kind || consoleLink.GetName() != GenerateConsoleLinkName(c.namespace) {
+				t.Errorf("Test case %d did not match\nExpected: %s/%s\nActual: %s/%s", i, ConsoleLinkGVK.Kind, GenerateConsoleLinkName(c.namespace), consoleLink.GetKind(), consoleLink.GetName())
+			}
+		})
+	}
+}
@@ -7,16 +7,70 @@ const (
 	OrganizationPrefix                               = "openshift"
 	QuaySecretCredentialTokenKey                     = "token"
 	NamespaceFinalizer                               = "quay.redhat.com/quayintegrations"
+	OAuthApplicationFinalizer                        = "quay.redhat.com/oauth-application"
 	OpenShiftDisplayNameAnnotation                   = "openshift.io/display-name"
 	OpenShiftDescriptionAnnotation                   = "openshift.io/description"
 	OpenShiftSccMcsAnnotation                        = "openshift.io/sa.scc.mcs"
+	OpenShiftRequesterAnnotation                     = "openshift.io/requester"
 	DisableWebhookEnvVar                             = "DISABLE_WEBHOOK"
 	WebHookCertDirEnv                                = "WEBHOOK_CERT_DIR"
 	DefaultWebhookCertDir                            = "/apiserver.local.config/certificates"
 	WebhookCertName                                  = "apiserver.crt"
 	WebhookKeyName                                   = "apiserver.key"
 	BuildOperatorManagedAnnotation                   = AnnotationBase + "/quay-registry-operator-managed"
+	BuildOperatorManagedLabel                        = AnnotationBase + "/managed"
 	BuildDestinationImageStreamAnnotation            = AnnotationBase + "/destination-imagestream"
 	BuildDestinationImageStreamTagImportedAnnotation = AnnotationBase + "/destination-imagestreamtag-imported"
+	BuildRewriteInputImagesAnnotation                = AnnotationBase + "/rewrite-input-images"
+	BuildMutatedAnnotation                           = AnnotationBase + "/mutated"
+	BuildRecoveredAnnotation                         = AnnotationBase + "/recovered"
+	QuayRepositoryAnnotation                         = "quay.openshift.io/repository"
+	QuayTagTemplateAnnotation                        = "quay.openshift.io/tag-template"
+	QuayExpiresAfterAnnotation                       = "quay.openshift.io/expires-after"
+	QuayExpiresAfterLabel                            = "quay.expires-after"
+	QuayNotificationsAnnotation                      = "quay.openshift.io/notifications"
+	QuayOrganizationAnnotation                       = "quay.openshift.io/organization"
+	QuayTriggerURLAnnotation                         = "quay.openshift.io/trigger-url"
+	QuayTriggerRepositoryAnnotation                  = "quay.openshift.io/trigger-repository"
+	QuayBaseImageTriggerAnnotation                   = "quay.openshift.io/base-image-trigger"
+	QuayBaseImageDigestAnnotation                    = "quay.openshift.io/base-image-digest"
+	QuayImageDigestAnnotation                        = "quay.openshift.io/image-digest"
+	QuayArchitecturesAnnotation                      = "quay.openshift.io/architectures"
+	QuayPlatformDigestsAnnotation                    = "quay.openshift.io/platform-digests"
+	QuayGrantPullToAnnotation                        = "quay.openshift.io/grant-pull-to"
+	QuayGrantedPullToAnnotation                      = "quay.openshift.io/granted-pull-to"
+	QuayInjectPullSecretLabel                        = "quay.openshift.io/inject-pull-secret"
+	QuayInjectedPullSecretAnnotation                 = "quay.openshift.io/injected-pull-secret"
+	QuayRobotSecretsAnnotation                       = "quay.openshift.io/robot-secrets"
+	QuayRobotRoleLabel                               = "quay.openshift.io/robot-role"
+	AllowOrganizationRenameAnnotation                = "quay.redhat.com/allow-organization-rename"
+	NamespaceRecreationPolicyAnnotation              = "quay.redhat.com/namespace-recreation-policy"
+	RegistryHostnameAnnotation                       = "quay.redhat.com/registry-hostname"
+	NamespaceProvisioningAnnotation                  = "quay.redhat.com/provisioning"
+	RobotCredentialsRepairAnnotation                 = "quay.redhat.com/robot-credentials-repair"
+	RegistryAccessVerifiedAnnotation                 = "quay.redhat.com/registry-access-verified"
+	RepositoryTrustAnnotation                        = "quay.redhat.com/repository-trust"
+	ResyncRequestedAnnotation                        = "quay.redhat.com/resync-requested"
+	OrphanCleanupAnnotation                          = "quay.redhat.com/orphan-cleanup"
+	ManagedSecretLabel                               = "quay.redhat.com/managed-secret"
+	SynchronizedNamespaceLabel                       = "quay.redhat.com/synchronized"
+	ManagedSecretHashAnnotation                      = "quay.redhat.com/managed-secret-hash"
+	CredentialsExpireAtAnnotation                    = "quay.redhat.com/credentials-expire-at"
+	CredentialsRefreshAtAnnotation                   = "quay.redhat.com/credentials-refresh-at"
+	NamespaceProvisioningPending                     = "Pending"
+	NamespaceOwnerRobotName                          = "namespace_owner"
+	NamespaceUIDRobotMetadataKey                     = "namespaceUID"
+	NamespaceRobotMetadataKey                        = "namespace"
+	ServiceAccountRobotMetadataKey                   = "serviceAccount"
+	ClusterIDRobotMetadataKey                        = "clusterID"
+	OperatorVersionRobotMetadataKey                  = "operatorVersion"
+	CreatedRobotMetadataKey                          = "created"
+	ManagedNotificationTitlePrefix                   = "[quay-bridge-operator] "
 	RequeuePeriod                                    = time.Second * 5
+	BuildAdmissionTimeoutSeconds                     = 15
+	GrafanaDashboardConfigMapName                    = "quay-bridge-operator-dashboard"
+	GrafanaDashboardLabel                            = "grafana_dashboard"
+	GrafanaDashboardKey                              = "quay-bridge-operator.json"
+	GlobalPullSecretNamespace                        = "openshift-config"
+	GlobalPullSecretName                             = "pull-secret"
 )
@@ -8,13 +8,15 @@ import (
 
 	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
 
-	"github.com/redhat-cop/operator-utils/pkg/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/quay/quay-bridge-operator/pkg/constants"
 	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
 )
 
 const (
@@ -22,7 +24,7 @@ const (
 )
 
 type CoreComponents struct {
-	ReconcilerBase util.ReconcilerBase
+	ReconcilerBase reconcilerbase.ReconcilerBase
 }
 
 type QuayIntegrationCoreError struct {
@@ -35,7 +37,7 @@ type QuayIntegrationCoreError struct {
 	Reason        string
 }
 
-func NewCoreComponents(reconcilerBase util.ReconcilerBase) CoreComponents {
+func NewCoreComponents(reconcilerBase reconcilerbase.ReconcilerBase) CoreComponents {
 	return CoreComponents{
 		ReconcilerBase: reconcilerBase,
 	}
@@ -56,6 +58,14 @@ func (c *CoreComponents) ManageError(quayIntegrationCoreError *QuayIntegrationCo
 	eventMessage := quayIntegrationCoreError.Message
 	eventMessage = fmt.Sprintf("%s - %s", eventMessage, buildKeyAndValueMessage(quayIntegrationCoreError.KeyAndValues))
 
+	// Conflicts with writes of other actors are expected and retried promptly instead of backing off
+	if apierrors.IsConflict(quayIntegrationCoreError.Error) {
+		logging.Log.Info(fmt.Sprintf("%s due to a conflicting update, retrying", quayIntegrationCoreError.Message), quayIntegrationCoreError.KeyAndValues...)
+		c.ReconcilerBase.GetRecorder().Event(quayIntegrationCoreError.Object, "Normal", reconcilerbase.ConflictReason, eventMessage)
+
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	logging.Log.Error(quayIntegrationCoreError.Error, quayIntegrationCoreError.Message, quayIntegrationCoreError.KeyAndValues...)
 	c.ReconcilerBase.GetRecorder().Event(quayIntegrationCoreError.Object, "Warning", quayIntegrationCoreError.Reason, eventMessage)
 
@@ -66,31 +76,30 @@ func (c *CoreComponents) ManageError(quayIntegrationCoreError *QuayIntegrationCo
 
 }
 
-func (c *CoreComponents) GetQuayIntegration(object runtime.Object) (quayv1.QuayIntegration, reconcile.Result, error) {
-
-	// Find the Current Registered QuayIntegration objects
-	quayIntegrations := quayv1.QuayIntegrationList{}
+// GetQuayIntegration returns the QuayIntegration synchronizing the namespace of an object. Namespaces selected by several
+// QuayIntegrations are synchronized by the one with precedence
+func (c *CoreComponents) GetQuayIntegration(object client.Object) (quayv1.QuayIntegration, reconcile.Result, error) {
 
-	err := c.ReconcilerBase.GetClient().List(context.TODO(), &quayIntegrations, &client.ListOptions{})
+	_, quayIntegration, err := syncpolicy.GetOwner(context.TODO(), c.ReconcilerBase.GetClient(), object.GetNamespace())
 
 	if err != nil {
 		return quayv1.QuayIntegration{}, reconcile.Result{}, err
 	}
 
-	if len(quayIntegrations.Items) != 1 {
+	if quayIntegration == nil {
 
 		result, err := c.ManageError(&QuayIntegrationCoreError{
 			Object:       object,
-			Message:      "No QuayIntegrations defined or more than 1 integration present",
-			KeyAndValues: []interface{}{"Expected", "1", "Actual", len(quayIntegrations.Items)},
+			Message:      "No QuayIntegration synchronizes the namespace",
+			KeyAndValues: []interface{}{"Namespace", object.GetNamespace()},
 			Reason:       "ConfigrurationError",
-			Error:        fmt.Errorf("No QuayIntegrations defined or more than 1 integration present"),
+			Error:        fmt.Errorf("No QuayIntegration synchronizes namespace %s", object.GetNamespace()),
 		})
 
 		return quayv1.QuayIntegration{}, result, err
 	}
 
-	return *&quayIntegrations.Items[0], reconcile.Result{}, err
+	return *quayIntegration, reconcile.Result{}, nil
 }
 
 func buildKeyAndValueMessage(keyAndValues []interface{}) string {
@@ -1,13 +1,22 @@
 package credentials
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// TektonDockerAnnotation associates a basic-auth Secret with a registry for Tekton
+	TektonDockerAnnotation = "tekton.dev/docker-0"
+)
+
 func GenerateDockerJsonSecret(name string, server string, username string, password string, email string) (*corev1.Secret, error) {
 
 	secret := &corev1.Secret{
@@ -29,6 +38,238 @@ func GenerateDockerJsonSecret(name string, server string, username string, passw
 	return secret, err
 }
 
+// RegistryCredential is the username and password used to authenticate against a registry location
+type RegistryCredential struct {
+	Server   string
+	Username string
+	Password string
+}
+
+// GenerateCombinedDockerJsonSecret generates a dockerconfigjson Secret containing an auth for each registry location
+func GenerateCombinedDockerJsonSecret(name string, registryCredentials []RegistryCredential) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+	}
+
+	secret.Name = name
+	secret.Type = corev1.SecretTypeDockerConfigJson
+	secret.Data = map[string][]byte{}
+
+	dockerCfgJSON := DockerConfigJSON{
+		Auths: DockerConfig{},
+	}
+
+	for _, registryCredential := range registryCredentials {
+		dockerCfgJSON.Auths[registryCredential.Server] = DockerConfigEntry{
+			Auth: encodeDockerConfigFieldAuth(registryCredential.Username, registryCredential.Password),
+		}
+	}
+
+	dockercfgJSONContent, err := json.Marshal(dockerCfgJSON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Data[corev1.DockerConfigJsonKey] = dockercfgJSONContent
+
+	return secret, nil
+}
+
+func GenerateDockerCfgSecret(name string, server string, username string, password string, email string) (*corev1.Secret, error) {
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+	}
+	secret.Name = name
+	secret.Type = corev1.SecretTypeDockercfg
+	secret.Data = map[string][]byte{}
+
+	dockercfgContent, err := handleDockerCfgContent(username, password, email, server)
+	if err != nil {
+		return nil, err
+	}
+	secret.Data[corev1.DockerConfigKey] = dockercfgContent
+
+	return secret, err
+}
+
+func GenerateBasicAuthSecret(name string, server string, username string, password string) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+	}
+	secret.Name = name
+	secret.Type = corev1.SecretTypeBasicAuth
+	secret.Annotations = map[string]string{
+		TektonDockerAnnotation: server,
+	}
+	secret.Data = map[string][]byte{
+		corev1.BasicAuthUsernameKey: []byte(username),
+		corev1.BasicAuthPasswordKey: []byte(password),
+	}
+
+	return secret
+}
+
+// HasDockerConfigJsonAuth returns whether a dockerconfigjson Secret contains credentials for a registry location
+func HasDockerConfigJsonAuth(secret *corev1.Secret, server string) bool {
+
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return false
+	}
+
+	dockerCfgJSON := DockerConfigJSON{}
+
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &dockerCfgJSON); err != nil {
+		return false
+	}
+
+	entry, found := dockerCfgJSON.Auths[server]
+
+	return found && (entry.Auth != "" || entry.Password != "")
+}
+
+// GetDockerConfigJsonAuth returns the username and password of the credentials for a registry location contained in a dockerconfigjson Secret
+func GetDockerConfigJsonAuth(secret *corev1.Secret, server string) (string, string, bool) {
+
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return "", "", false
+	}
+
+	dockerCfgJSON := DockerConfigJSON{}
+
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &dockerCfgJSON); err != nil {
+		return "", "", false
+	}
+
+	return getDockerConfigAuth(dockerCfgJSON.Auths, server)
+}
+
+// GetDockerCfgAuth returns the username and password of the credentials for a registry location contained in a dockercfg Secret
+func GetDockerCfgAuth(secret *corev1.Secret, server string) (string, string, bool) {
+
+	if secret.Type != corev1.SecretTypeDockercfg {
+		return "", "", false
+	}
+
+	dockerCfg := DockerConfig{}
+
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &dockerCfg); err != nil {
+		return "", "", false
+	}
+
+	return getDockerConfigAuth(dockerCfg, server)
+}
+
+// GetBasicAuth returns the username and password of a basic-auth Secret associated with a registry location
+func GetBasicAuth(secret *corev1.Secret, server string) (string, string, bool) {
+
+	if secret.Type != corev1.SecretTypeBasicAuth || secret.Annotations[TektonDockerAnnotation] != server {
+		return "", "", false
+	}
+
+	username := string(secret.Data[corev1.BasicAuthUsernameKey])
+	password := string(secret.Data[corev1.BasicAuthPasswordKey])
+
+	return username, password, password != ""
+}
+
+// GetRegistryAuth returns the username and password of the credentials for a registry location contained in a dockerconfigjson,
+// dockercfg or basic-auth Secret
+func GetRegistryAuth(secret *corev1.Secret, server string) (string, string, bool) {
+
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		return GetDockerConfigJsonAuth(secret, server)
+	case corev1.SecretTypeDockercfg:
+		return GetDockerCfgAuth(secret, server)
+	case corev1.SecretTypeBasicAuth:
+		return GetBasicAuth(secret, server)
+	}
+
+	return "", "", false
+}
+
+// HasRegistryAuth returns whether a dockerconfigjson, dockercfg or basic-auth Secret contains credentials for a registry location
+func HasRegistryAuth(secret *corev1.Secret, server string) bool {
+
+	_, _, found := GetRegistryAuth(secret, server)
+
+	return found
+}
+
+func getDockerConfigAuth(dockerCfg DockerConfig, server string) (string, string, bool) {
+
+	entry, found := dockerCfg[server]
+
+	if !found {
+		return "", "", false
+	}
+
+	if entry.Password != "" {
+		return entry.Username, entry.Password, true
+	}
+
+	auth, err := base64.StdEncoding.DecodeString(entry.Auth)
+
+	if err != nil {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(string(auth), ":", 2)
+
+	if len(parts) != 2 {
+		return parts[0], "", false
+	}
+
+	return parts[0], parts[1], parts[1] != ""
+}
+
+// HashSecretData returns a digest of the type and data of a Secret, recorded on managed Secrets to detect edits made outside of the operator
+func HashSecretData(secret *corev1.Secret) string {
+
+	keys := make([]string, 0, len(secret.Data))
+
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	hash.Write([]byte(secret.Type))
+
+	for _, key := range keys {
+		hash.Write([]byte{0})
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(secret.Data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func handleDockerCfgContent(username, password, email, server string) ([]byte, error) {
+	dockercfgAuth := DockerConfigEntry{
+		Email: email,
+		Auth:  encodeDockerConfigFieldAuth(username, password),
+	}
+
+	dockerCfg := DockerConfig{server: dockercfgAuth}
+
+	return json.Marshal(dockerCfg)
+}
+
 func handleDockerCfgJSONContent(username, password, email, server string) ([]byte, error) {
 	dockercfgAuth := DockerConfigEntry{
 		Email: email,
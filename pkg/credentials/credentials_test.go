@@ -58,3 +58,283 @@ func TestSecretForDockerRegistryGenerate(t *testing.T) {
 	}
 
 }
+
+func TestSecretForDockerCfgGenerate(t *testing.T) {
+
+	username, password, email, server := "testuser", "testPassword", "test@example.com", "quay.io"
+	secretName := "test-secret"
+	dockercfg, _ := handleDockerCfgContent(username, password, email, server)
+	cases := []struct {
+		name       string
+		secretName string
+		userName   string
+		password   string
+		email      string
+		server     string
+		expected   *corev1.Secret
+	}{
+		{
+			name:       "test-generate-dockercfg-secret",
+			userName:   username,
+			password:   password,
+			email:      email,
+			server:     server,
+			secretName: secretName,
+			expected: &corev1.Secret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Secret",
+					APIVersion: corev1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: secretName,
+				},
+				Data: map[string][]byte{
+					corev1.DockerConfigKey: dockercfg,
+				},
+				Type: corev1.SecretTypeDockercfg,
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+			result, _ := GenerateDockerCfgSecret(c.secretName, c.server, c.userName, c.password, c.email)
+
+			if !reflect.DeepEqual(result, c.expected) {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+
+		})
+	}
+
+}
+
+func TestSecretForBasicAuthGenerate(t *testing.T) {
+
+	username, password, server := "testuser", "testPassword", "quay.io"
+	secretName := "test-secret"
+	cases := []struct {
+		name       string
+		secretName string
+		userName   string
+		password   string
+		server     string
+		expected   *corev1.Secret
+	}{
+		{
+			name:       "test-generate-basic-auth-secret",
+			userName:   username,
+			password:   password,
+			server:     server,
+			secretName: secretName,
+			expected: &corev1.Secret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Secret",
+					APIVersion: corev1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: secretName,
+					Annotations: map[string]string{
+						TektonDockerAnnotation: server,
+					},
+				},
+				Data: map[string][]byte{
+					corev1.BasicAuthUsernameKey: []byte(username),
+					corev1.BasicAuthPasswordKey: []byte(password),
+				},
+				Type: corev1.SecretTypeBasicAuth,
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+			result := GenerateBasicAuthSecret(c.secretName, c.server, c.userName, c.password)
+
+			if !reflect.DeepEqual(result, c.expected) {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+
+		})
+	}
+
+}
+
+func TestCombinedDockerJsonSecretGenerate(t *testing.T) {
+
+	cases := []struct {
+		name                string
+		registryCredentials []RegistryCredential
+		expected            string
+	}{
+		{
+			name:                "test-generate-empty-combined-secret",
+			registryCredentials: []RegistryCredential{},
+			expected:            `{"auths":{}}`,
+		},
+		{
+			name: "test-generate-combined-secret",
+			registryCredentials: []RegistryCredential{
+				{Server: "quay.io/openshift_a", Username: "openshift_a+reader", Password: "a"},
+				{Server: "quay.io/openshift_b", Username: "openshift_b+reader", Password: "b"},
+			},
+			expected: `{"auths":{"quay.io/openshift_a":{"auth":"b3BlbnNoaWZ0X2ErcmVhZGVyOmE="},"quay.io/openshift_b":{"auth":"b3BlbnNoaWZ0X2IrcmVhZGVyOmI="}}}`,
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+			result, err := GenerateCombinedDockerJsonSecret("test-secret", c.registryCredentials)
+
+			if err != nil || result.Type != corev1.SecretTypeDockerConfigJson || string(result.Data[corev1.DockerConfigJsonKey]) != c.expected {
+				t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s (%v)", i, c.expected, result.Data[corev1.DockerConfigJsonKey], err)
+			}
+
+		})
+	}
+
+}
+
+func TestHasDockerConfigJsonAuth(t *testing.T) {
+
+	secret, _ := GenerateDockerJsonSecret("builder-secret", "quay.example.com", "openshift_app+builder", "token", "")
+
+	cases := []struct {
+		secret   *corev1.Secret
+		server   string
+		expected bool
+	}{
+		{secret: secret, server: "quay.example.com", expected: true},
+		{secret: secret, server: "quay-old.example.com", expected: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeDockerConfigJson, Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte("invalid")}}, server: "quay.example.com", expected: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: secret.Data}, server: "quay.example.com", expected: false},
+	}
+
+	for i, c := range cases {
+
+		if result := HasDockerConfigJsonAuth(c.secret, c.server); result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, result)
+		}
+	}
+}
+
+func TestGetDockerConfigJsonAuth(t *testing.T) {
+
+	secret, _ := GenerateDockerJsonSecret("builder-secret", "quay.example.com", "openshift_app+builder", "token", "")
+	authOnly := &corev1.Secret{Type: corev1.SecretTypeDockerConfigJson, Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.example.com":{"auth":"b3BlbnNoaWZ0X2FwcCtidWlsZGVyOnRva2Vu"}}}`)}}
+
+	cases := []struct {
+		secret           *corev1.Secret
+		server           string
+		expectedUsername string
+		expectedPassword string
+		expectedFound    bool
+	}{
+		{secret: secret, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: authOnly, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: secret, server: "quay-old.example.com", expectedFound: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: secret.Data}, server: "quay.example.com", expectedFound: false},
+	}
+
+	for i, c := range cases {
+
+		username, password, found := GetDockerConfigJsonAuth(c.secret, c.server)
+
+		if username != c.expectedUsername || password != c.expectedPassword || found != c.expectedFound {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %v\nActual: %s %s %v", i, c.expectedUsername, c.expectedPassword, c.expectedFound, username, password, found)
+		}
+	}
+}
+
+func TestGetDockerCfgAuth(t *testing.T) {
+
+	secret, _ := GenerateDockerCfgSecret("builder-secret-dockercfg", "quay.example.com", "openshift_app+builder", "token", "")
+	dockerConfigJson, _ := GenerateDockerJsonSecret("builder-secret", "quay.example.com", "openshift_app+builder", "token", "")
+	passwordOnly := &corev1.Secret{Type: corev1.SecretTypeDockercfg, Data: map[string][]byte{corev1.DockerConfigKey: []byte(`{"quay.example.com":{"username":"openshift_app+builder","password":"token"}}`)}}
+
+	cases := []struct {
+		secret           *corev1.Secret
+		server           string
+		expectedUsername string
+		expectedPassword string
+		expectedFound    bool
+	}{
+		{secret: secret, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: passwordOnly, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: secret, server: "quay-old.example.com", expectedFound: false},
+		{secret: dockerConfigJson, server: "quay.example.com", expectedFound: false},
+	}
+
+	for i, c := range cases {
+
+		username, password, found := GetDockerCfgAuth(c.secret, c.server)
+
+		if username != c.expectedUsername || password != c.expectedPassword || found != c.expectedFound {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %v\nActual: %s %s %v", i, c.expectedUsername, c.expectedPassword, c.expectedFound, username, password, found)
+		}
+	}
+}
+
+func TestGetRegistryAuth(t *testing.T) {
+
+	dockerConfigJson, _ := GenerateDockerJsonSecret("builder-secret", "quay.example.com", "openshift_app+builder", "token", "")
+	dockerCfg, _ := GenerateDockerCfgSecret("builder-secret-dockercfg", "quay.example.com", "openshift_app+builder", "token", "")
+	basicAuth := GenerateBasicAuthSecret("builder-secret-basic-auth", "quay.example.com", "openshift_app+builder", "token")
+
+	cases := []struct {
+		secret           *corev1.Secret
+		server           string
+		expectedUsername string
+		expectedPassword string
+		expectedFound    bool
+	}{
+		{secret: dockerConfigJson, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: dockerCfg, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: basicAuth, server: "quay.example.com", expectedUsername: "openshift_app+builder", expectedPassword: "token", expectedFound: true},
+		{secret: dockerCfg, server: "quay-old.example.com", expectedFound: false},
+		{secret: basicAuth, server: "quay-old.example.com", expectedFound: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeDockercfg, Data: map[string][]byte{corev1.DockerConfigKey: []byte("invalid")}}, server: "quay.example.com", expectedFound: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeDockercfg, Data: dockerConfigJson.Data}, server: "quay.example.com", expectedFound: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: dockerCfg.Data}, server: "quay.example.com", expectedFound: false},
+	}
+
+	for i, c := range cases {
+
+		username, password, found := GetRegistryAuth(c.secret, c.server)
+
+		if username != c.expectedUsername || password != c.expectedPassword || found != c.expectedFound {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %v\nActual: %s %s %v", i, c.expectedUsername, c.expectedPassword, c.expectedFound, username, password, found)
+		}
+
+		if found := HasRegistryAuth(c.secret, c.server); found != c.expectedFound {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expectedFound, found)
+		}
+	}
+}
+
+func TestHashSecretData(t *testing.T) {
+
+	secret, _ := GenerateDockerJsonSecret("builder-secret", "quay.example.com", "openshift_app+builder", "token", "")
+	rotated, _ := GenerateDockerJsonSecret("builder-secret", "quay.example.com", "openshift_app+builder", "rotated", "")
+	renamed, _ := GenerateDockerJsonSecret("builder-secret-renamed", "quay.example.com", "openshift_app+builder", "token", "")
+
+	cases := []struct {
+		secret   *corev1.Secret
+		expected bool
+	}{
+		{secret: renamed, expected: true},
+		{secret: rotated, expected: false},
+		{secret: &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: secret.Data}, expected: false},
+		{secret: &corev1.Secret{Type: secret.Type, Data: map[string][]byte{corev1.DockerConfigJsonKey: secret.Data[corev1.DockerConfigJsonKey], "extra": {}}}, expected: false},
+	}
+
+	for i, c := range cases {
+
+		if equal := HashSecretData(c.secret) == HashSecretData(secret); equal != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, equal)
+		}
+	}
+}
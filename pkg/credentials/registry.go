@@ -0,0 +1,181 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// registryToken is the response of the token endpoint of a registry
+type registryToken struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// PingRegistry authenticates against the API of a registry using the credentials of a robot account, as a client pulling or
+// pushing an image would. Registries answering with a bearer challenge, such as Quay, are authenticated using a token requested
+// from the realm of the challenge
+func PingRegistry(httpClient *http.Client, registryHostname string, username string, password string) error {
+
+	endpoint := fmt.Sprintf("https://%s/v2/", registryHostname)
+
+	statusCode, challenge, err := getRegistry(httpClient, endpoint, func(req *http.Request) { req.SetBasicAuth(username, password) })
+
+	if err != nil {
+		return err
+	}
+
+	if statusCode == http.StatusOK {
+		return nil
+	}
+
+	if statusCode != http.StatusUnauthorized {
+		return fmt.Errorf("unexpected status code %d from registry %s", statusCode, registryHostname)
+	}
+
+	scheme, params := parseChallenge(challenge)
+
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return fmt.Errorf("registry %s rejected the credentials of '%s'", registryHostname, username)
+	}
+
+	token, err := requestRegistryToken(httpClient, params["realm"], params["service"], username, password)
+
+	if err != nil {
+		return err
+	}
+
+	statusCode, _, err = getRegistry(httpClient, endpoint, func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) })
+
+	if err != nil {
+		return err
+	}
+
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("registry %s rejected the token issued to '%s' with status code %d", registryHostname, username, statusCode)
+	}
+
+	return nil
+}
+
+// requestRegistryToken requests a token from the realm of a bearer challenge using basic authentication
+func requestRegistryToken(httpClient *http.Client, realm string, service string, username string, password string) (string, error) {
+
+	realmURL, err := url.Parse(realm)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid realm '%s': %w", realm, err)
+	}
+
+	query := realmURL.Query()
+	query.Set("account", username)
+
+	if service != "" {
+		query.Set("service", service)
+	}
+
+	realmURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", realmURL.String(), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.SetBasicAuth(username, password)
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint %s rejected the credentials of '%s' with status code %d", realmURL.Host, username, resp.StatusCode)
+	}
+
+	token := registryToken{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid response from token endpoint %s: %w", realmURL.Host, err)
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	if token.Token == "" {
+		return "", fmt.Errorf("token endpoint %s issued no token to '%s'", realmURL.Host, username)
+	}
+
+	return token.Token, nil
+}
+
+// getRegistry requests an endpoint of a registry, returning the status code and the authentication challenge of the response
+func getRegistry(httpClient *http.Client, endpoint string, authorize func(req *http.Request)) (int, string, error) {
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	authorize(req)
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	return resp.StatusCode, resp.Header.Get("WWW-Authenticate"), nil
+}
+
+// parseChallenge parses the scheme and parameters of a WWW-Authenticate header, such as Bearer realm="https://quay.io/v2/auth",service="quay.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+
+	params := map[string]string{}
+
+	scheme, rest := cut(strings.TrimSpace(challenge), " ")
+
+	for rest != "" {
+
+		var key, value string
+
+		key, rest = cut(strings.TrimLeft(rest, " ,"), "=")
+
+		if strings.HasPrefix(rest, `"`) {
+			value, rest = cut(rest[1:], `"`)
+		} else {
+			value, rest = cut(rest, ",")
+		}
+
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			params[key] = value
+		}
+	}
+
+	return scheme, params
+}
+
+// cut slices s around the first instance of sep, returning s and an empty string when sep is not found
+func cut(s string, sep string) (string, string) {
+
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+
+	return s, ""
+}
@@ -0,0 +1,103 @@
+package credentials
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPingRegistry(t *testing.T) {
+
+	cases := []struct {
+		password      string
+		bearer        bool
+		expectedError bool
+	}{
+		{password: "token", bearer: true, expectedError: false},
+		{password: "revoked", bearer: true, expectedError: true},
+		{password: "token", bearer: false, expectedError: false},
+		{password: "revoked", bearer: false, expectedError: true},
+	}
+
+	for i, c := range cases {
+
+		var server *httptest.Server
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			username, password, basic := r.BasicAuth()
+			valid := basic && username == "openshift_app+builder" && password == "token"
+
+			switch {
+			case r.URL.Path == "/v2/auth":
+				if !valid || r.URL.Query().Get("service") != "quay.example.com" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Write([]byte(`{"token": "registry-token"}`))
+			case r.URL.Path == "/v2/" && c.bearer:
+				if r.Header.Get("Authorization") != "Bearer registry-token" {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/v2/auth",service="quay.example.com"`, server.URL))
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			case r.URL.Path == "/v2/":
+				if !valid {
+					w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		err := PingRegistry(server.Client(), strings.TrimPrefix(server.URL, "https://"), "openshift_app+builder", c.password)
+
+		server.Close()
+
+		if c.expectedError != (err != nil) {
+			t.Errorf("Test case %d did not match\nExpected Error: %v\nActual: %v", i, c.expectedError, err)
+		}
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+
+	cases := []struct {
+		challenge      string
+		expectedScheme string
+		expectedParams map[string]string
+	}{
+		{
+			challenge:      `Bearer realm="https://quay.example.com/v2/auth",service="quay.example.com"`,
+			expectedScheme: "Bearer",
+			expectedParams: map[string]string{"realm": "https://quay.example.com/v2/auth", "service": "quay.example.com"},
+		},
+		{
+			challenge:      `Basic realm="registry, with comma"`,
+			expectedScheme: "Basic",
+			expectedParams: map[string]string{"realm": "registry, with comma"},
+		},
+		{
+			challenge:      `Bearer realm=https://registry/token, service=registry`,
+			expectedScheme: "Bearer",
+			expectedParams: map[string]string{"realm": "https://registry/token", "service": "registry"},
+		},
+		{
+			challenge:      "",
+			expectedScheme: "",
+			expectedParams: map[string]string{},
+		},
+	}
+
+	for i, c := range cases {
+
+		scheme, params := parseChallenge(c.challenge)
+
+		if scheme != c.expectedScheme || !reflect.DeepEqual(params, c.expectedParams) {
+			t.Errorf("Test case %d did not match\nExpected: %s %#v\nActual: %s %#v", i, c.expectedScheme, c.expectedParams, scheme, params)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package debug
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/quay/quay-bridge-operator/pkg/httpauth"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+)
+
+const (
+	// shutdownTimeout is the time given to in-flight requests, such as CPU profiles, to complete on shutdown
+	shutdownTimeout = 5 * time.Second
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} {
+		return runtime.NumGoroutine()
+	}))
+}
+
+// Server serves the pprof and expvar endpoints used to diagnose the operator. Requests must present the token as a
+// bearer token when one is configured. The endpoints are served over TLS when a certificate directory is configured, and
+// only on the loopback interface otherwise
+type Server struct {
+	BindAddress string
+	Token       string
+
+	// CertDir contains the tls.crt and tls.key files of the serving certificate
+	CertDir string
+}
+
+// NeedLeaderElection allows every replica to be diagnosed
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler serving the debug endpoints
+func (s *Server) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	if s.Token == "" {
+		return mux
+	}
+
+	return httpauth.RequireBearerToken(s.Token, mux)
+}
+
+// Start serves the debug endpoints until the context is done
+func (s *Server) Start(ctx context.Context) error {
+
+	if err := httpauth.ValidateBindAddress("debug endpoints", s.BindAddress, s.Token, s.CertDir); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.BindAddress)
+
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler: s.Handler(),
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logging.Log.Error(err, "Debug server did not shut down cleanly")
+		}
+	}()
+
+	logging.Log.Info("Serving debug endpoints", "Address", s.BindAddress, "TLS", s.CertDir != "")
+
+	if err := httpauth.Serve(srv, listener, s.CertDir); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
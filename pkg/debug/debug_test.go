@@ -0,0 +1,66 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+
+	cases := []struct {
+		token          string
+		path           string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			path:           "/debug/vars",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			path:           "/debug/pprof/goroutine?debug=1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			token:          "secret",
+			path:           "/debug/vars",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			path:           "/debug/vars",
+			authorization:  "Bearer invalid",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			path:           "/debug/vars",
+			authorization:  "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			path:           "/debug/vars",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for i, c := range cases {
+
+		request := httptest.NewRequest(http.MethodGet, c.path, nil)
+
+		if c.authorization != "" {
+			request.Header.Set("Authorization", c.authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		(&Server{Token: c.token}).Handler().ServeHTTP(recorder, request)
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("Test case %d did not match\nExpected: %d\nActual: %d", i, c.expectedStatus, recorder.Code)
+		}
+	}
+}
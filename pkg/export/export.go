@@ -0,0 +1,174 @@
+// Package export renders the robot account Secrets of synchronized namespaces as encrypted manifests, such as SealedSecrets or
+// SOPS encrypted Secrets, letting teams commit the generated credentials to Git safely
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/credentials"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap created in synchronized namespaces when ConfigMapName is not set
+	DefaultConfigMapName = "quay-credential-export"
+
+	// CredentialExportLabel identifies the ConfigMaps managed by the operator, ensuring ConfigMaps created by users are never removed
+	CredentialExportLabel = "quay.redhat.com/credential-export"
+
+	// ChecksumsAnnotation holds the checksums of the Secrets and settings each manifest was encrypted from, as a JSON object
+	// keyed by Secret name
+	ChecksumsAnnotation = "quay.redhat.com/credential-export-checksums"
+
+	manifestKeySuffix = ".yaml"
+)
+
+// Encrypter renders the encrypted manifest of a Secret
+type Encrypter interface {
+	Encrypt(ctx context.Context, secret *corev1.Secret) ([]byte, error)
+	// Identity identifies the settings manifests are encrypted with, so that manifests are encrypted again once they change
+	Identity() string
+}
+
+// GetConfigMapName returns the name of the ConfigMap created in synchronized namespaces
+func GetConfigMapName(credentialExport *quayv1.CredentialExport) string {
+
+	if credentialExport == nil || credentialExport.ConfigMapName == "" {
+		return DefaultConfigMapName
+	}
+
+	return credentialExport.ConfigMapName
+}
+
+// NewConfigMap returns an empty ConfigMap receiving the encrypted manifests of the Secrets of a namespace
+func NewConfigMap(namespace string, credentialExport *quayv1.CredentialExport) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetConfigMapName(credentialExport),
+			Namespace: namespace,
+			Labels: map[string]string{
+				CredentialExportLabel: "true",
+			},
+		},
+		Data: map[string]string{},
+	}
+}
+
+// IsManagedConfigMap returns whether a ConfigMap was created by the operator
+func IsManagedConfigMap(configMap *corev1.ConfigMap) bool {
+	return configMap.Labels[CredentialExportLabel] == "true"
+}
+
+// ManifestKey returns the key of the manifest of a Secret within the ConfigMap
+func ManifestKey(secretName string) string {
+	return secretName + manifestKeySuffix
+}
+
+// Checksum returns the checksum of a Secret and of the settings its manifest is encrypted with
+func Checksum(encrypter Encrypter, secret *corev1.Secret) string {
+
+	hash := sha256.New()
+
+	for _, value := range []string{encrypter.Identity(), secret.Namespace, secret.Name, credentials.HashSecretData(secret)} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Export writes the encrypted manifest of a Secret to a ConfigMap, returning whether the ConfigMap changed. As encryption is not
+// deterministic, manifests are only encrypted again when the checksum of the Secret changes so that Git only sees actual changes
+func Export(ctx context.Context, configMap *corev1.ConfigMap, encrypter Encrypter, secret *corev1.Secret) (bool, error) {
+
+	checksums := getChecksums(configMap)
+	checksum := Checksum(encrypter, secret)
+
+	if _, found := configMap.Data[ManifestKey(secret.Name)]; found && checksums[secret.Name] == checksum {
+		return false, nil
+	}
+
+	manifest, err := encrypter.Encrypt(ctx, secret)
+
+	if err != nil {
+		return false, err
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+
+	configMap.Data[ManifestKey(secret.Name)] = string(manifest)
+	checksums[secret.Name] = checksum
+
+	return true, setChecksums(configMap, checksums)
+}
+
+// Prune removes the manifests of Secrets which are no longer generated, returning whether the ConfigMap changed
+func Prune(configMap *corev1.ConfigMap, secretNames []string) (bool, error) {
+
+	expected := map[string]bool{}
+
+	for _, secretName := range secretNames {
+		expected[ManifestKey(secretName)] = true
+	}
+
+	checksums := getChecksums(configMap)
+	changed := false
+
+	for key := range configMap.Data {
+		if strings.HasSuffix(key, manifestKeySuffix) && !expected[key] {
+			delete(configMap.Data, key)
+			delete(checksums, strings.TrimSuffix(key, manifestKeySuffix))
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	return true, setChecksums(configMap, checksums)
+}
+
+// getChecksums returns the checksums recorded on a ConfigMap. Unreadable checksums are ignored, encrypting every manifest again
+func getChecksums(configMap *corev1.ConfigMap) map[string]string {
+
+	checksums := map[string]string{}
+
+	if value, found := configMap.Annotations[ChecksumsAnnotation]; found {
+		if err := json.Unmarshal([]byte(value), &checksums); err != nil {
+			return map[string]string{}
+		}
+	}
+
+	return checksums
+}
+
+func setChecksums(configMap *corev1.ConfigMap, checksums map[string]string) error {
+
+	content, err := json.Marshal(checksums)
+
+	if err != nil {
+		return err
+	}
+
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+
+	configMap.Annotations[ChecksumsAnnotation] = string(content)
+
+	return nil
+}
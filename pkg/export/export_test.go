@@ -0,0 +1,426 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/secretstore"
+)
+
+func newTestSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "builder-quay-cluster", Namespace: "app"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+	}
+}
+
+type fakeEncrypter struct {
+	identity  string
+	encrypted int
+}
+
+func (e *fakeEncrypter) Encrypt(ctx context.Context, secret *corev1.Secret) ([]byte, error) {
+	e.encrypted++
+	return []byte(fmt.Sprintf("%s %d", secret.Name, e.encrypted)), nil
+}
+
+func (e *fakeEncrypter) Identity() string {
+	return e.identity
+}
+
+func TestExport(t *testing.T) {
+
+	encrypter := &fakeEncrypter{identity: "fake"}
+	configMap := NewConfigMap("app", nil)
+	secret := newTestSecret()
+
+	cases := []struct {
+		mutate            func()
+		expectedChanged   bool
+		expectedManifest  string
+		expectedEncrypted int
+	}{
+		{
+			mutate:            func() {},
+			expectedChanged:   true,
+			expectedManifest:  "builder-quay-cluster 1",
+			expectedEncrypted: 1,
+		},
+		{
+			mutate:            func() {},
+			expectedChanged:   false,
+			expectedManifest:  "builder-quay-cluster 1",
+			expectedEncrypted: 1,
+		},
+		{
+			mutate:            func() { secret.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{"quay.io":{}}}`) },
+			expectedChanged:   true,
+			expectedManifest:  "builder-quay-cluster 2",
+			expectedEncrypted: 2,
+		},
+		{
+			mutate:            func() { encrypter.identity = "rotated" },
+			expectedChanged:   true,
+			expectedManifest:  "builder-quay-cluster 3",
+			expectedEncrypted: 3,
+		},
+		{
+			mutate:            func() { delete(configMap.Data, ManifestKey(secret.Name)) },
+			expectedChanged:   true,
+			expectedManifest:  "builder-quay-cluster 4",
+			expectedEncrypted: 4,
+		},
+	}
+
+	for i, c := range cases {
+
+		c.mutate()
+
+		changed, err := Export(context.Background(), configMap, encrypter, secret)
+
+		if err != nil || changed != c.expectedChanged || configMap.Data["builder-quay-cluster.yaml"] != c.expectedManifest || encrypter.encrypted != c.expectedEncrypted {
+			t.Errorf("Test case %d did not match\nExpected: %v %s %d\nActual: %v %s %d %v", i, c.expectedChanged, c.expectedManifest, c.expectedEncrypted, changed, configMap.Data["builder-quay-cluster.yaml"], encrypter.encrypted, err)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+
+	configMap := NewConfigMap("app", &quayv1.CredentialExport{ConfigMapName: "exported"})
+	configMap.Data = map[string]string{"default-quay-cluster.yaml": "a", "builder-quay-cluster.yaml": "b", "README": "c"}
+	configMap.Annotations = map[string]string{ChecksumsAnnotation: `{"builder-quay-cluster":"1","default-quay-cluster":"2"}`}
+
+	changed, err := Prune(configMap, []string{"default-quay-cluster"})
+
+	expectedData := map[string]string{"default-quay-cluster.yaml": "a", "README": "c"}
+	expectedChecksums := `{"default-quay-cluster":"2"}`
+
+	if err != nil || !changed || configMap.Name != "exported" || !reflect.DeepEqual(configMap.Data, expectedData) || configMap.Annotations[ChecksumsAnnotation] != expectedChecksums {
+		t.Errorf("ConfigMap was not pruned: %v %v %#v %#v", changed, err, configMap.Data, configMap.Annotations)
+	}
+
+	if changed, err := Prune(configMap, []string{"default-quay-cluster"}); changed || err != nil {
+		t.Errorf("Pruned ConfigMap changed again: %v %v", changed, err)
+	}
+}
+
+// newTestCertificate returns a self-signed certificate standing in for the sealing key of a sealed-secrets controller
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, []byte) {
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %v", err)
+	}
+
+	return privateKey, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+}
+
+// hybridDecrypt reverses HybridEncrypt as the sealed-secrets controller does
+func hybridDecrypt(privateKey *rsa.PrivateKey, ciphertext []byte, label []byte) ([]byte, error) {
+
+	rsaLength := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext[2:2+rsaLength], label)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := aes.NewCipher(sessionKey)
+	aead, _ := cipher.NewGCM(block)
+
+	return aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+rsaLength:], nil)
+}
+
+func TestSealedSecretsEncrypter(t *testing.T) {
+
+	privateKey, certificate := newTestCertificate(t)
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(certificate)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		scope               quayv1.SealedSecretsScope
+		expectedLabel       string
+		expectedAnnotations map[string]string
+	}{
+		{
+			expectedLabel: "app/builder-quay-cluster",
+		},
+		{
+			scope:               quayv1.NamespaceWideSealedSecretsScope,
+			expectedLabel:       "app",
+			expectedAnnotations: map[string]string{"sealedsecrets.bitnami.com/namespace-wide": "true"},
+		},
+		{
+			scope:               quayv1.ClusterWideSealedSecretsScope,
+			expectedLabel:       "",
+			expectedAnnotations: map[string]string{"sealedsecrets.bitnami.com/cluster-wide": "true"},
+		},
+	}
+
+	for i, c := range cases {
+
+		encrypter := &SealedSecretsEncrypter{Client: server.Client(), CertificateURL: server.URL, Scope: c.scope}
+
+		manifest, err := encrypter.Encrypt(context.Background(), newTestSecret())
+
+		if err != nil {
+			t.Fatalf("Test case %d failed: %v", i, err)
+		}
+
+		sealed := sealedSecret{}
+
+		if err := yaml.Unmarshal(manifest, &sealed); err != nil {
+			t.Fatalf("Test case %d rendered an invalid manifest: %v", i, err)
+		}
+
+		ciphertext, _ := base64.StdEncoding.DecodeString(sealed.Spec.EncryptedData[corev1.DockerConfigJsonKey])
+		plaintext, err := hybridDecrypt(privateKey, ciphertext, []byte(c.expectedLabel))
+
+		if err != nil || string(plaintext) != `{"auths":{}}` || sealed.Kind != "SealedSecret" || sealed.Spec.Template.Type != corev1.SecretTypeDockerConfigJson || !reflect.DeepEqual(sealed.Annotations, c.expectedAnnotations) || !reflect.DeepEqual(sealed.Spec.Template.Annotations, c.expectedAnnotations) {
+			t.Errorf("Test case %d did not match\nExpected: %s %v\nActual: %s %v %s", i, c.expectedLabel, c.expectedAnnotations, plaintext, err, manifest)
+		}
+	}
+
+	if requests != len(cases) {
+		t.Errorf("Certificate was requested %d times", requests)
+	}
+}
+
+type fakeKeyService struct{}
+
+func (s *fakeKeyService) EncryptDataKey(ctx context.Context, dataKey []byte, createdAt string) (string, []YAMLField, error) {
+	return "hc_vault", []YAMLField{{Key: "created_at", Value: createdAt}, {Key: "enc", Value: base64.StdEncoding.EncodeToString(dataKey)}}, nil
+}
+
+func (s *fakeKeyService) Identity() string {
+	return "fake"
+}
+
+var sopsValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.*),tag:(.*),type:str\]$`)
+
+// sopsDecrypt reverses sopsEncrypt as SOPS does
+func sopsDecrypt(dataKey []byte, value string, additionalData string) (string, error) {
+
+	matches := sopsValuePattern.FindStringSubmatch(value)
+
+	if matches == nil {
+		return "", fmt.Errorf("value is not encrypted: %s", value)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(matches[1])
+	iv, _ := base64.StdEncoding.DecodeString(matches[2])
+	tag, _ := base64.StdEncoding.DecodeString(matches[3])
+
+	block, _ := aes.NewCipher(dataKey)
+	aead, _ := cipher.NewGCMWithNonceSize(block, len(iv))
+
+	plaintext, err := aead.Open(nil, iv, append(data, tag...), []byte(additionalData))
+
+	return string(plaintext), err
+}
+
+func TestSOPSEncrypter(t *testing.T) {
+
+	encrypter := &SOPSEncrypter{KeyService: &fakeKeyService{}, now: func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }}
+
+	manifest, err := encrypter.Encrypt(context.Background(), newTestSecret())
+
+	if err != nil {
+		t.Fatalf("Encryption failed: %v", err)
+	}
+
+	document := struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Metadata   map[string]string `json:"metadata"`
+		Type       string            `json:"type"`
+		Data       map[string]string `json:"data"`
+		SOPS       struct {
+			HCVault        []map[string]string `json:"hc_vault"`
+			LastModified   string              `json:"lastmodified"`
+			MAC            string              `json:"mac"`
+			EncryptedRegex string              `json:"encrypted_regex"`
+		} `json:"sops"`
+	}{}
+
+	if err := yaml.Unmarshal(manifest, &document); err != nil {
+		t.Fatalf("Invalid manifest: %v\n%s", err, manifest)
+	}
+
+	if !bytes.HasPrefix(manifest, []byte("\"apiVersion\": \"v1\"\n\"kind\": \"Secret\"\n\"metadata\":\n  \"name\": \"builder-quay-cluster\"\n")) || !bytes.Contains(manifest, []byte("\"sops\":\n  \"hc_vault\":\n    - \"created_at\": \"2024-01-02T03:04:05Z\"\n      \"enc\": ")) {
+		t.Errorf("Manifest was not rendered in order:\n%s", manifest)
+	}
+
+	if len(document.SOPS.HCVault) != 1 || document.SOPS.LastModified != "2024-01-02T03:04:05Z" || document.SOPS.EncryptedRegex != "^(data|stringData)$" {
+		t.Fatalf("Unexpected SOPS metadata: %#v", document.SOPS)
+	}
+
+	dataKey, _ := base64.StdEncoding.DecodeString(document.SOPS.HCVault[0]["enc"])
+
+	data, err := sopsDecrypt(dataKey, document.Data[corev1.DockerConfigJsonKey], "data:.dockerconfigjson:")
+
+	if err != nil || data != base64.StdEncoding.EncodeToString([]byte(`{"auths":{}}`)) {
+		t.Errorf("Data was not decrypted: %s %v", data, err)
+	}
+
+	mac, err := sopsDecrypt(dataKey, document.SOPS.MAC, document.SOPS.LastModified)
+
+	expectedMAC := sha512.New()
+
+	for _, value := range []string{"v1", "Secret", "builder-quay-cluster", "app", string(corev1.SecretTypeDockerConfigJson), data} {
+		expectedMAC.Write([]byte(value))
+	}
+
+	if err != nil || mac != fmt.Sprintf("%X", expectedMAC.Sum(nil)) {
+		t.Errorf("MAC did not match: %s %v", mac, err)
+	}
+}
+
+func TestSOPSVaultTransitKeyService(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if r.URL.Path != "/v1/sops/encrypt/quay" || r.Header.Get("X-Vault-Token") != "vault-token" || string(body) != `{"plaintext":"a2V5"}` {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Write([]byte(`{"data":{"ciphertext":"vault:v1:encrypted"}}`))
+	}))
+	defer server.Close()
+
+	keyService := &SOPSVaultTransitKeyService{Client: server.Client(), Address: server.URL + "/", EnginePath: "/sops/", KeyName: "quay", Token: "vault-token"}
+
+	group, entry, err := keyService.EncryptDataKey(context.Background(), []byte("key"), "2024-01-02T03:04:05Z")
+
+	expected := []YAMLField{
+		{Key: "vault_address", Value: server.URL},
+		{Key: "engine_path", Value: "sops"},
+		{Key: "key_name", Value: "quay"},
+		{Key: "created_at", Value: "2024-01-02T03:04:05Z"},
+		{Key: "enc", Value: "vault:v1:encrypted"},
+	}
+
+	if err != nil || group != "hc_vault" || !reflect.DeepEqual(entry, expected) {
+		t.Errorf("Data key entry did not match\nExpected: %#v\nActual: %s %#v %v", expected, group, entry, err)
+	}
+}
+
+func TestNewSOPSAWSKMSKeyService(t *testing.T) {
+
+	cases := []struct {
+		arn            string
+		expectedRegion string
+		expectedError  bool
+	}{
+		{
+			arn:            "arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+			expectedRegion: "eu-west-1",
+		},
+		{
+			arn:            "arn:aws-us-gov:kms:us-gov-west-1:111122223333:alias/quay",
+			expectedRegion: "us-gov-west-1",
+		},
+		{
+			arn:           "arn:aws:s3:::bucket",
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		keyService, err := NewSOPSAWSKMSKeyService(http.DefaultClient, c.arn, "", secretstore.AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"})
+
+		region := ""
+
+		if keyService != nil {
+			region = keyService.Client.Region
+		}
+
+		if c.expectedError != (err != nil) || region != c.expectedRegion {
+			t.Errorf("Test case %d did not match\nExpected: %s %v\nActual: %s %v", i, c.expectedRegion, c.expectedError, region, err)
+		}
+	}
+}
+
+func TestSOPSAWSKMSKeyService(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		input := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&input)
+
+		if r.Header.Get("X-Amz-Target") != "TrentService.Encrypt" || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request") || input["Plaintext"] != "a2V5" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Write([]byte(`{"CiphertextBlob":"ZW5jcnlwdGVk","KeyId":"` + input["KeyId"] + `"}`))
+	}))
+	defer server.Close()
+
+	arn := "arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	keyService, _ := NewSOPSAWSKMSKeyService(server.Client(), arn, server.URL, secretstore.AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"})
+
+	group, entry, err := keyService.EncryptDataKey(context.Background(), []byte("key"), "2024-01-02T03:04:05Z")
+
+	expected := []YAMLField{
+		{Key: "arn", Value: arn},
+		{Key: "created_at", Value: "2024-01-02T03:04:05Z"},
+		{Key: "enc", Value: "ZW5jcnlwdGVk"},
+		{Key: "aws_profile", Value: ""},
+	}
+
+	if err != nil || group != "kms" || !reflect.DeepEqual(entry, expected) {
+		t.Errorf("Data key entry did not match\nExpected: %#v\nActual: %s %#v %v", expected, group, entry, err)
+	}
+}
@@ -0,0 +1,284 @@
+package export
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+const (
+	// DefaultSealedSecretsControllerName is the name of the Service of the sealed-secrets controller when ControllerName is not set
+	DefaultSealedSecretsControllerName = "sealed-secrets-controller"
+	// DefaultSealedSecretsControllerNamespace is the namespace of the sealed-secrets controller when ControllerNamespace is not set
+	DefaultSealedSecretsControllerNamespace = "kube-system"
+
+	sealedSecretsAPIVersion              = "bitnami.com/v1alpha1"
+	sealedSecretsNamespaceWideAnnotation = "sealedsecrets.bitnami.com/namespace-wide"
+	sealedSecretsClusterWideAnnotation   = "sealedsecrets.bitnami.com/cluster-wide"
+	sessionKeyBytes                      = 32
+)
+
+// SealedSecretsEncrypter renders SealedSecrets encrypted with the public key of a sealed-secrets controller, which is retrieved
+// the first time a Secret is sealed
+type SealedSecretsEncrypter struct {
+	Client *http.Client
+	// CertificateURL serves the PEM encoded certificate of the sealing key
+	CertificateURL string
+	Scope          quayv1.SealedSecretsScope
+
+	publicKey *rsa.PublicKey
+	rand      io.Reader
+}
+
+var _ Encrypter = &SealedSecretsEncrypter{}
+
+// sealedSecret is the subset of the SealedSecret resource of sealed-secrets rendered by the operator
+type sealedSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              sealedSecretSpec `json:"spec"`
+}
+
+type sealedSecretSpec struct {
+	EncryptedData map[string]string    `json:"encryptedData"`
+	Template      sealedSecretTemplate `json:"template"`
+}
+
+type sealedSecretTemplate struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Type              corev1.SecretType `json:"type,omitempty"`
+}
+
+// GetSealedSecretsCertificateURL returns the URL the controller of a SealedSecretsExport serves the certificate of its sealing key at
+func GetSealedSecretsCertificateURL(sealedSecrets *quayv1.SealedSecretsExport) string {
+
+	name := sealedSecrets.ControllerName
+
+	if name == "" {
+		name = DefaultSealedSecretsControllerName
+	}
+
+	namespace := sealedSecrets.ControllerNamespace
+
+	if namespace == "" {
+		namespace = DefaultSealedSecretsControllerNamespace
+	}
+
+	return fmt.Sprintf("http://%s.%s.svc:8080/v1/cert.pem", name, namespace)
+}
+
+// Identity identifies the controller and scope SealedSecrets are sealed with
+func (e *SealedSecretsEncrypter) Identity() string {
+	return fmt.Sprintf("SealedSecrets/%s/%s", e.scope(), e.CertificateURL)
+}
+
+// Encrypt returns the SealedSecret of a Secret
+func (e *SealedSecretsEncrypter) Encrypt(ctx context.Context, secret *corev1.Secret) ([]byte, error) {
+
+	if e.publicKey == nil {
+
+		publicKey, err := e.fetchPublicKey(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e.publicKey = publicKey
+	}
+
+	random := e.rand
+
+	if random == nil {
+		random = rand.Reader
+	}
+
+	annotations := map[string]string{}
+	label := ""
+
+	switch e.scope() {
+	case quayv1.StrictSealedSecretsScope:
+		label = fmt.Sprintf("%s/%s", secret.Namespace, secret.Name)
+	case quayv1.NamespaceWideSealedSecretsScope:
+		label = secret.Namespace
+		annotations[sealedSecretsNamespaceWideAnnotation] = "true"
+	case quayv1.ClusterWideSealedSecretsScope:
+		annotations[sealedSecretsClusterWideAnnotation] = "true"
+	default:
+		return nil, fmt.Errorf("unsupported sealed secrets scope '%s'", e.Scope)
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	encryptedData := map[string]string{}
+
+	for key, value := range secretData(secret) {
+
+		ciphertext, err := HybridEncrypt(random, e.publicKey, value, []byte(label))
+
+		if err != nil {
+			return nil, err
+		}
+
+		encryptedData[key] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+
+	objectMeta := metav1.ObjectMeta{Name: secret.Name, Namespace: secret.Namespace, Annotations: annotations}
+
+	return yaml.Marshal(&sealedSecret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: sealedSecretsAPIVersion, Kind: "SealedSecret"},
+		ObjectMeta: objectMeta,
+		Spec: sealedSecretSpec{
+			EncryptedData: encryptedData,
+			Template:      sealedSecretTemplate{ObjectMeta: objectMeta, Type: secret.Type},
+		},
+	})
+}
+
+func (e *SealedSecretsEncrypter) scope() quayv1.SealedSecretsScope {
+
+	if e.Scope == "" {
+		return quayv1.StrictSealedSecretsScope
+	}
+
+	return e.Scope
+}
+
+// fetchPublicKey retrieves the public key of the sealing key from the certificate served by the controller
+func (e *SealedSecretsEncrypter) fetchPublicKey(ctx context.Context) (*rsa.PublicKey, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.CertificateURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.Client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sealed-secrets controller returned status code %d retrieving certificate", resp.StatusCode)
+	}
+
+	return ParsePublicKey(content)
+}
+
+// ParsePublicKey returns the RSA public key of a PEM encoded certificate
+func ParsePublicKey(content []byte) (*rsa.PublicKey, error) {
+
+	block, _ := pem.Decode(content)
+
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+
+	if !ok {
+		return nil, fmt.Errorf("certificate does not contain an RSA public key")
+	}
+
+	return publicKey, nil
+}
+
+// HybridEncrypt encrypts a value as sealed-secrets does: a random AES-256-GCM session key encrypts the value and is itself
+// encrypted with RSA-OAEP using the label scoping the SealedSecret. The session key is prefixed by its length
+func HybridEncrypt(random io.Reader, publicKey *rsa.PublicKey, plaintext []byte, label []byte) ([]byte, error) {
+
+	sessionKey := make([]byte, sessionKeyBytes)
+
+	if _, err := io.ReadFull(random, sessionKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), random, publicKey, sessionKey, label)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+
+	// The session key is only used once, allowing a zero nonce
+	return aead.Seal(ciphertext, make([]byte, aead.NonceSize()), plaintext, nil), nil
+}
+
+// secretData returns the data of a Secret, including its string data
+func secretData(secret *corev1.Secret) map[string][]byte {
+
+	data := map[string][]byte{}
+
+	for key, value := range secret.Data {
+		data[key] = value
+	}
+
+	for key, value := range secret.StringData {
+		data[key] = []byte(value)
+	}
+
+	return data
+}
+
+// sortedKeys returns the keys of the data of a Secret in lexical order
+func sortedKeys(data map[string][]byte) []string {
+
+	keys := make([]string, 0, len(data))
+
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,353 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/quay/quay-bridge-operator/pkg/secretstore"
+)
+
+const (
+	// DefaultVaultTransitEnginePath is the path of the Transit secrets engine when EnginePath is not set
+	DefaultVaultTransitEnginePath = "transit"
+
+	// sopsVersion is the version of SOPS whose file format is rendered
+	sopsVersion = "3.7.3"
+	// sopsEncryptedRegex limits encryption to the data of Secrets, as SOPS recommends for Kubernetes manifests
+	sopsEncryptedRegex = "^(data|stringData)$"
+	sopsNonceSize      = 32
+	sopsDataKeyBytes   = 32
+)
+
+// SOPSKeyService encrypts the data key of SOPS manifests
+type SOPSKeyService interface {
+	// EncryptDataKey returns the entry of the encrypted data key within the SOPS metadata, along with the name of its key group
+	EncryptDataKey(ctx context.Context, dataKey []byte, createdAt string) (string, []YAMLField, error)
+	// Identity identifies the key encrypting data keys
+	Identity() string
+}
+
+// SOPSEncrypter renders Secrets encrypted in the file format of SOPS, decryptable by SOPS and by GitOps tools integrating it
+// such as Flux or the ksops plugin of Kustomize
+type SOPSEncrypter struct {
+	KeyService SOPSKeyService
+
+	rand io.Reader
+	now  func() time.Time
+}
+
+var _ Encrypter = &SOPSEncrypter{}
+
+// Identity identifies the key SOPS data keys are encrypted with
+func (e *SOPSEncrypter) Identity() string {
+	return "SOPS/" + e.KeyService.Identity()
+}
+
+// Encrypt returns the SOPS encrypted manifest of a Secret. Every value is part of the message authentication code of the file
+// while only the data of the Secret is encrypted
+func (e *SOPSEncrypter) Encrypt(ctx context.Context, secret *corev1.Secret) ([]byte, error) {
+
+	random := e.rand
+
+	if random == nil {
+		random = rand.Reader
+	}
+
+	now := time.Now
+
+	if e.now != nil {
+		now = e.now
+	}
+
+	dataKey := make([]byte, sopsDataKeyBytes)
+
+	if _, err := io.ReadFull(random, dataKey); err != nil {
+		return nil, err
+	}
+
+	lastModified := now().UTC().Format(time.RFC3339)
+	mac := sha512.New()
+
+	// Values are written to the message authentication code in plain text, in the order they are rendered
+	plain := func(key string, value string) YAMLField {
+		mac.Write([]byte(value))
+		return YAMLField{Key: key, Value: value}
+	}
+
+	fields := []YAMLField{
+		plain("apiVersion", "v1"),
+		plain("kind", "Secret"),
+		{Key: "metadata", Value: []YAMLField{plain("name", secret.Name), plain("namespace", secret.Namespace)}},
+	}
+
+	if secret.Type != "" {
+		fields = append(fields, plain("type", string(secret.Type)))
+	}
+
+	data := []YAMLField{}
+	secretData := secretData(secret)
+
+	for _, key := range sortedKeys(secretData) {
+
+		value := base64.StdEncoding.EncodeToString(secretData[key])
+		mac.Write([]byte(value))
+
+		// The path of encrypted values is authenticated
+		encrypted, err := sopsEncrypt(random, dataKey, value, "data:"+key+":")
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, YAMLField{Key: key, Value: encrypted})
+	}
+
+	fields = append(fields, YAMLField{Key: "data", Value: data})
+
+	encryptedMAC, err := sopsEncrypt(random, dataKey, fmt.Sprintf("%X", mac.Sum(nil)), lastModified)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group, key, err := e.KeyService.EncryptDataKey(ctx, dataKey, lastModified)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fields = append(fields, YAMLField{Key: "sops", Value: []YAMLField{
+		{Key: group, Value: [][]YAMLField{key}},
+		{Key: "lastmodified", Value: lastModified},
+		{Key: "mac", Value: encryptedMAC},
+		{Key: "encrypted_regex", Value: sopsEncryptedRegex},
+		{Key: "version", Value: sopsVersion},
+	}})
+
+	var manifest bytes.Buffer
+
+	writeYAML(&manifest, fields, "", "")
+
+	return manifest.Bytes(), nil
+}
+
+// sopsEncrypt encrypts a string value with AES-256-GCM as SOPS does, authenticating the path of the value within the file
+func sopsEncrypt(random io.Reader, dataKey []byte, plaintext string, additionalData string) (string, error) {
+
+	block, err := aes.NewCipher(dataKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := cipher.NewGCMWithNonceSize(block, sopsNonceSize)
+
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, sopsNonceSize)
+
+	if _, err := io.ReadFull(random, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nil, nonce, []byte(plaintext), []byte(additionalData))
+	tagOffset := len(sealed) - aead.Overhead()
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:str]",
+		base64.StdEncoding.EncodeToString(sealed[:tagOffset]),
+		base64.StdEncoding.EncodeToString(nonce),
+		base64.StdEncoding.EncodeToString(sealed[tagOffset:])), nil
+}
+
+// YAMLField is a field of a YAML mapping whose value is a string, a mapping or a sequence of mappings. Fields are rendered in
+// order, as the message authentication code of SOPS depends on the order of values
+type YAMLField struct {
+	Key   string
+	Value interface{}
+}
+
+// writeYAML renders a mapping. Strings are rendered as JSON strings, which are valid double quoted YAML scalars. The first field
+// of a mapping within a sequence is prefixed by the dash of the item
+func writeYAML(out *bytes.Buffer, fields []YAMLField, indent string, firstPrefix string) {
+
+	for i, field := range fields {
+
+		prefix := indent
+
+		if i == 0 && firstPrefix != "" {
+			prefix = firstPrefix
+		}
+
+		key, _ := json.Marshal(field.Key)
+
+		switch value := field.Value.(type) {
+		case string:
+			quoted, _ := json.Marshal(value)
+			fmt.Fprintf(out, "%s%s: %s\n", prefix, key, quoted)
+		case []YAMLField:
+			fmt.Fprintf(out, "%s%s:\n", prefix, key)
+			writeYAML(out, value, indent+"  ", "")
+		case [][]YAMLField:
+			fmt.Fprintf(out, "%s%s:\n", prefix, key)
+			for _, item := range value {
+				writeYAML(out, item, indent+"    ", indent+"  - ")
+			}
+		}
+	}
+}
+
+// SOPSVaultTransitKeyService encrypts data keys with a key of the Transit secrets engine of HashiCorp Vault
+type SOPSVaultTransitKeyService struct {
+	Client     *http.Client
+	Address    string
+	EnginePath string
+	KeyName    string
+	Token      string
+}
+
+var _ SOPSKeyService = &SOPSVaultTransitKeyService{}
+
+// Identity identifies the Transit key
+func (s *SOPSVaultTransitKeyService) Identity() string {
+	return fmt.Sprintf("hc_vault/%s/v1/%s/keys/%s", strings.TrimSuffix(s.Address, "/"), s.enginePath(), s.KeyName)
+}
+
+// EncryptDataKey encrypts a data key with the Transit key
+func (s *SOPSVaultTransitKeyService) EncryptDataKey(ctx context.Context, dataKey []byte, createdAt string) (string, []YAMLField, error) {
+
+	body, err := json.Marshal(map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/encrypt/%s", strings.TrimSuffix(s.Address, "/"), s.enginePath(), s.KeyName)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", s.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", nil, fmt.Errorf("vault returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	result := struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", nil, err
+	}
+
+	if result.Data.Ciphertext == "" {
+		return "", nil, fmt.Errorf("vault returned no ciphertext")
+	}
+
+	return "hc_vault", []YAMLField{
+		{Key: "vault_address", Value: strings.TrimSuffix(s.Address, "/")},
+		{Key: "engine_path", Value: s.enginePath()},
+		{Key: "key_name", Value: s.KeyName},
+		{Key: "created_at", Value: createdAt},
+		{Key: "enc", Value: result.Data.Ciphertext},
+	}, nil
+}
+
+func (s *SOPSVaultTransitKeyService) enginePath() string {
+
+	if s.EnginePath == "" {
+		return DefaultVaultTransitEnginePath
+	}
+
+	return strings.Trim(s.EnginePath, "/")
+}
+
+// SOPSAWSKMSKeyService encrypts data keys with an AWS KMS key
+type SOPSAWSKMSKeyService struct {
+	Client *secretstore.AWSJSONClient
+	ARN    string
+}
+
+var _ SOPSKeyService = &SOPSAWSKMSKeyService{}
+
+// NewSOPSAWSKMSKeyService returns the key service of an AWS KMS key, invoking AWS KMS in the region of the key
+func NewSOPSAWSKMSKeyService(client *http.Client, arn string, endpoint string, credentials secretstore.AWSCredentials) (*SOPSAWSKMSKeyService, error) {
+
+	// arn:partition:kms:region:account:key/id
+	parts := strings.SplitN(arn, ":", 6)
+
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "kms" || parts[3] == "" {
+		return nil, fmt.Errorf("invalid AWS KMS key ARN '%s'", arn)
+	}
+
+	return &SOPSAWSKMSKeyService{
+		Client: &secretstore.AWSJSONClient{
+			Client:       client,
+			Region:       parts[3],
+			Credentials:  credentials,
+			Endpoint:     endpoint,
+			Service:      "kms",
+			TargetPrefix: "TrentService",
+		},
+		ARN: arn,
+	}, nil
+}
+
+// Identity identifies the KMS key
+func (s *SOPSAWSKMSKeyService) Identity() string {
+	return "kms/" + s.ARN
+}
+
+// EncryptDataKey encrypts a data key with the KMS key
+func (s *SOPSAWSKMSKeyService) EncryptDataKey(ctx context.Context, dataKey []byte, createdAt string) (string, []YAMLField, error) {
+
+	result := struct {
+		CiphertextBlob string
+	}{}
+
+	if err := s.Client.Call(ctx, "Encrypt", map[string]string{"KeyId": s.ARN, "Plaintext": base64.StdEncoding.EncodeToString(dataKey)}, &result); err != nil {
+		return "", nil, err
+	}
+
+	if result.CiphertextBlob == "" {
+		return "", nil, fmt.Errorf("aws kms returned no ciphertext")
+	}
+
+	return "kms", []YAMLField{
+		{Key: "arn", Value: s.ARN},
+		{Key: "created_at", Value: createdAt},
+		{Key: "enc", Value: result.CiphertextBlob},
+		{Key: "aws_profile", Value: ""},
+	}, nil
+}
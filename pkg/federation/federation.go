@@ -0,0 +1,229 @@
+package federation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+)
+
+const (
+	// DefaultTokenFile is the token of the service account of the operator, exchanged for short lived tokens of robot accounts
+	DefaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// DefaultLifetime is the lifetime assumed for short lived tokens when Quay does not report it
+	DefaultLifetime = time.Hour
+)
+
+// MinimumVersion is the version of Quay introducing the federation of robot accounts with OIDC identities
+var MinimumVersion = capabilities.Version{Major: 3, Minor: 13}
+
+// Identity is the OIDC identity of the operator, as asserted by the issuer and subject claims of its token
+type Identity struct {
+	Issuer  string
+	Subject string
+}
+
+// ParseIdentity returns the identity asserted by a JWT. The signature is not verified, as the token is only read to configure the
+// federation of robot accounts while Quay verifies it against the issuer when exchanging it
+func ParseIdentity(token string) (Identity, error) {
+
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return Identity{}, fmt.Errorf("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid JWT payload: %v", err)
+	}
+
+	claims := struct {
+		Issuer  string `json:"iss"`
+		Subject string `json:"sub"`
+	}{}
+
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Identity{}, fmt.Errorf("invalid JWT claims: %v", err)
+	}
+
+	if claims.Issuer == "" || claims.Subject == "" {
+		return Identity{}, fmt.Errorf("JWT does not assert an issuer and a subject")
+	}
+
+	return Identity{Issuer: claims.Issuer, Subject: claims.Subject}, nil
+}
+
+// Credentials is a short lived token of a robot account
+type Credentials struct {
+	Token     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// RefreshAt returns the time the token is renewed, once two thirds of its lifetime elapsed, leaving time for the renewed token to
+// be written to the Secrets of namespaces before the previous one expires
+func (c Credentials) RefreshAt() time.Time {
+	return c.IssuedAt.Add(c.ExpiresAt.Sub(c.IssuedAt) * 2 / 3)
+}
+
+// Annotate records the expiry and the renewal time of the token on a Secret containing it
+func (c Credentials) Annotate(secret *corev1.Secret) {
+
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+
+	secret.Annotations[constants.CredentialsExpireAtAnnotation] = c.ExpiresAt.UTC().Format(time.RFC3339)
+	secret.Annotations[constants.CredentialsRefreshAtAnnotation] = c.RefreshAt().UTC().Format(time.RFC3339)
+}
+
+// NeedsRefresh returns whether a Secret, or its metadata, contains a short lived token due for renewal. Secrets containing permanent
+// tokens are never due
+func NeedsRefresh(secret metav1.Object, now time.Time) bool {
+
+	value, found := secret.GetAnnotations()[constants.CredentialsRefreshAtAnnotation]
+
+	if !found {
+		return false
+	}
+
+	refreshAt, err := time.Parse(time.RFC3339, value)
+
+	// Unreadable annotations are renewed, rewriting them
+	return err != nil || !now.Before(refreshAt)
+}
+
+// EnsureFederation allows an identity to exchange its tokens for short lived tokens of a robot account of an organization, keeping
+// the identities already federated with the robot account
+func EnsureFederation(quayClient *qclient.QuayClient, organizationName string, robotName string, identity Identity) error {
+
+	robotFederation, robotFederationResponse, robotFederationErr := quayClient.GetOrganizationRobotAccountFederation(organizationName, robotName)
+
+	if robotFederationErr.Error != nil {
+		return fmt.Errorf("failed to retrieve the federation of robot account %s+%s: %v", organizationName, robotName, robotFederationErr.Error)
+	}
+
+	if robotFederationResponse.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("robot account federation is not supported by Quay, which requires Quay %d.%d or newer", MinimumVersion.Major, MinimumVersion.Minor)
+	}
+
+	if robotFederationResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to retrieve the federation of robot account %s+%s: status code %d", organizationName, robotName, robotFederationResponse.StatusCode)
+	}
+
+	for _, federated := range robotFederation {
+		if federated.Issuer == identity.Issuer && federated.Subject == identity.Subject {
+			return nil
+		}
+	}
+
+	robotFederation = append(robotFederation, qclient.RobotFederation{Issuer: identity.Issuer, Subject: identity.Subject})
+
+	_, robotFederationResponse, robotFederationErr = quayClient.SetOrganizationRobotAccountFederation(organizationName, robotName, robotFederation)
+
+	if robotFederationErr.Error != nil {
+		return fmt.Errorf("failed to federate robot account %s+%s: %v", organizationName, robotName, robotFederationErr.Error)
+	}
+
+	if robotFederationResponse.StatusCode != http.StatusOK && robotFederationResponse.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to federate robot account %s+%s: status code %d", organizationName, robotName, robotFederationResponse.StatusCode)
+	}
+
+	return nil
+}
+
+// Exchange exchanges an OIDC token federated with a robot account for a short lived token of the robot account
+func Exchange(quayClient *qclient.QuayClient, robotAccountName string, oidcToken string, now time.Time) (Credentials, error) {
+
+	robotFederationToken, _, robotFederationTokenErr := quayClient.ExchangeRobotFederationToken(robotAccountName, oidcToken)
+
+	if robotFederationTokenErr.Error != nil {
+		return Credentials{}, robotFederationTokenErr.Error
+	}
+
+	if robotFederationToken.Token == "" {
+		return Credentials{}, fmt.Errorf("Quay returned no token for robot account '%s'", robotAccountName)
+	}
+
+	lifetime := DefaultLifetime
+
+	if robotFederationToken.ExpiresIn > 0 {
+		lifetime = time.Duration(robotFederationToken.ExpiresIn) * time.Second
+	}
+
+	return Credentials{Token: robotFederationToken.Token, IssuedAt: now, ExpiresAt: now.Add(lifetime)}, nil
+}
+
+// Provider issues the short lived tokens of robot accounts, exchanging the token of the operator read from TokenFile. Tokens are
+// reused until due for renewal so that synchronizations of namespaces only rewrite Secrets when their token is renewed
+type Provider struct {
+	// TokenFile contains the OIDC token of the operator, such as a projected service account token with the audience expected by Quay.
+	// It is read on every exchange as projected tokens are rotated by the kubelet
+	TokenFile string
+
+	mutex       sync.Mutex
+	credentials map[string]Credentials
+}
+
+// NewProvider creates a Provider exchanging the token read from tokenFile
+func NewProvider(tokenFile string) *Provider {
+	return &Provider{TokenFile: tokenFile, credentials: map[string]Credentials{}}
+}
+
+// Credentials returns a short lived token of a robot account of an organization, federating the robot account with the identity of
+// the operator when needed
+func (p *Provider) Credentials(quayClient *qclient.QuayClient, organizationName string, robotAccountName string, now time.Time) (Credentials, error) {
+
+	key := quayClient.BaseURL.Host + "/" + robotAccountName
+
+	p.mutex.Lock()
+	cached, found := p.credentials[key]
+	p.mutex.Unlock()
+
+	if found && now.Before(cached.RefreshAt()) {
+		return cached, nil
+	}
+
+	token, err := ioutil.ReadFile(p.TokenFile)
+
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read the token of the operator: %v", err)
+	}
+
+	oidcToken := strings.TrimSpace(string(token))
+
+	identity, err := ParseIdentity(oidcToken)
+
+	if err != nil {
+		return Credentials{}, fmt.Errorf("invalid token of the operator in %s: %v", p.TokenFile, err)
+	}
+
+	if err := EnsureFederation(quayClient, organizationName, strings.TrimPrefix(robotAccountName, organizationName+"+"), identity); err != nil {
+		return Credentials{}, err
+	}
+
+	credentials, err := Exchange(quayClient, robotAccountName, oidcToken, now)
+
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	p.mutex.Lock()
+	p.credentials[key] = credentials
+	p.mutex.Unlock()
+
+	return credentials, nil
+}
@@ -0,0 +1,147 @@
+package federation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func newJWT(claims string) string {
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestParseIdentity(t *testing.T) {
+
+	cases := []struct {
+		token         string
+		expected      Identity
+		expectedError bool
+	}{
+		{
+			token:    newJWT(`{"iss":"https://kubernetes.default.svc","sub":"system:serviceaccount:quay-bridge-operator:controller-manager"}`),
+			expected: Identity{Issuer: "https://kubernetes.default.svc", Subject: "system:serviceaccount:quay-bridge-operator:controller-manager"},
+		},
+		{
+			token:         newJWT(`{"iss":"https://kubernetes.default.svc"}`),
+			expectedError: true,
+		},
+		{
+			token:         "token",
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		identity, err := ParseIdentity(c.token)
+
+		if c.expectedError != (err != nil) || identity != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %+v %v\nActual: %+v %v", i, c.expected, c.expectedError, identity, err)
+		}
+	}
+}
+
+func TestProviderCredentials(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	oidcToken := newJWT(`{"iss":"https://issuer","sub":"operator"}`)
+
+	cases := []struct {
+		federation       []qclient.RobotFederation
+		expiresIn        int64
+		times            []time.Time
+		expected         Credentials
+		expectedRequests []string
+	}{
+		{
+			federation: []qclient.RobotFederation{},
+			times:      []time.Time{now},
+			expected:   Credentials{Token: "short-lived", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+			expectedRequests: []string{
+				"GET /api/v1/organization/org/robots/builder/federation",
+				"POST /api/v1/organization/org/robots/builder/federation",
+				"GET /oauth2/federation/robot/token",
+			},
+		},
+		// Tokens are reused until due for renewal
+		{
+			federation: []qclient.RobotFederation{{Issuer: "https://issuer", Subject: "operator"}},
+			expiresIn:  600,
+			times:      []time.Time{now, now.Add(6 * time.Minute)},
+			expected:   Credentials{Token: "short-lived", IssuedAt: now, ExpiresAt: now.Add(10 * time.Minute)},
+			expectedRequests: []string{
+				"GET /api/v1/organization/org/robots/builder/federation",
+				"GET /oauth2/federation/robot/token",
+			},
+		},
+		{
+			federation: []qclient.RobotFederation{{Issuer: "https://issuer", Subject: "operator"}},
+			expiresIn:  600,
+			times:      []time.Time{now, now.Add(7 * time.Minute)},
+			expected:   Credentials{Token: "short-lived", IssuedAt: now.Add(7 * time.Minute), ExpiresAt: now.Add(17 * time.Minute)},
+			expectedRequests: []string{
+				"GET /api/v1/organization/org/robots/builder/federation",
+				"GET /oauth2/federation/robot/token",
+				"GET /api/v1/organization/org/robots/builder/federation",
+				"GET /oauth2/federation/robot/token",
+			},
+		},
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+
+	if err := ioutil.WriteFile(tokenFile, []byte(oidcToken+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, c := range cases {
+
+		requests := []string{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+
+			switch r.Method + " " + r.URL.Path {
+			case "GET /api/v1/organization/org/robots/builder/federation":
+				json.NewEncoder(w).Encode(c.federation)
+			case "POST /api/v1/organization/org/robots/builder/federation":
+				json.NewDecoder(r.Body).Decode(&c.federation)
+				json.NewEncoder(w).Encode(c.federation)
+			case "GET /oauth2/federation/robot/token":
+				if username, password, _ := r.BasicAuth(); username != "org+builder" || password != oidcToken {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				json.NewEncoder(w).Encode(qclient.RobotFederationToken{Token: "short-lived", ExpiresIn: c.expiresIn})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		provider := NewProvider(tokenFile)
+		quayClient := qclient.NewClient(server.Client(), server.URL, "token")
+
+		var credentials Credentials
+		var err error
+
+		for _, current := range c.times {
+			if credentials, err = provider.Credentials(quayClient, "org", "org+builder", current); err != nil {
+				break
+			}
+		}
+
+		server.Close()
+
+		if err != nil || credentials != c.expected || !reflect.DeepEqual(requests, c.expectedRequests) {
+			t.Errorf("Test case %d did not match\nExpected: %+v %v\nActual: %+v %v %v", i, c.expected, c.expectedRequests, credentials, requests, err)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+	"github.com/quay/quay-bridge-operator/pkg/readiness"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+)
+
+const (
+	// ReportPeriod is the interval at which the health of the operator is reported
+	ReportPeriod = 30 * time.Second
+
+	// cacheSyncTimeout bounds how long the informer caches are waited on during a report
+	cacheSyncTimeout = 5 * time.Second
+	// maxConflictExamples is the number of conflicting namespaces listed by the Conflicted condition
+	maxConflictExamples = 5
+
+	AsExpectedReason                = "AsExpected"
+	SynchronizingReason             = "Synchronizing"
+	CachesNotSyncedReason           = "CachesNotSynced"
+	QuayUnreachableReason           = "QuayUnreachable"
+	QuayNotContactedReason          = "QuayNotContacted"
+	WebhookCertificateInvalidReason = "WebhookCertificateInvalid"
+	SyncBacklogHighReason           = "SyncBacklogHigh"
+	WaitingForQuayReason            = "WaitingForQuay"
+)
+
+var (
+	// WorkQueues are the controller work queues contributing to the synchronization backlog
+	WorkQueues = []string{"namespace", "build"}
+)
+
+// Report captures the health of each component of the operator
+type Report struct {
+	CachesSynced            bool
+	QuayReachable           bool
+	QuayObserved            bool
+	WebhookEnabled          bool
+	WebhookCertificateError error
+	Backlog                 int
+	BacklogThreshold        int
+	WaitingForQuay          bool
+	WaitingForQuayMessage   string
+	SyncedNamespaces        int
+}
+
+// HealthReconciler periodically aggregates the health of the operator into the conditions of each QuayIntegration
+type HealthReconciler struct {
+	Client           client.Client
+	Cache            cache.Cache
+	Log              logr.Logger
+	WebhookCertPath  string
+	BacklogThreshold int
+	ReadinessGate    *readiness.Gate
+
+	// Features and Permissions describe the effective configuration of the operator reported on the status
+	Features    []string
+	Permissions []rbacv1.PolicyRule
+}
+
+// Start implements manager.Runnable
+func (h *HealthReconciler) Start(ctx context.Context) error {
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+
+		if err := h.reconcile(ctx); err != nil {
+			h.Log.Error(err, "Failed to report operator health")
+		}
+
+	}, ReportPeriod)
+
+	return nil
+}
+
+func (h *HealthReconciler) reconcile(ctx context.Context) error {
+
+	report := h.collect(ctx)
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := h.Client.List(ctx, &quayIntegrations, &client.ListOptions{}); err != nil {
+		return err
+	}
+
+	conflicts, err := h.collectConflicts(ctx, quayIntegrations.Items)
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range quayIntegrations.Items {
+
+		key := client.ObjectKeyFromObject(&item)
+		itemConflicts := conflicts[item.Name]
+
+		// Status is shared with the QuayIntegration controller, retry when updated concurrently
+		err := reconcilerbase.RetryOnConflict(reconcilerbase.DefaultRetry, func() error {
+			return h.updateConditions(ctx, key, report, itemConflicts)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *HealthReconciler) updateConditions(ctx context.Context, key client.ObjectKey, report *Report, conflicts []syncpolicy.Conflict) error {
+
+	quayIntegration := &quayv1.QuayIntegration{}
+
+	if err := h.Client.Get(ctx, key, quayIntegration); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	changed := false
+
+	conditions := append(report.Conditions(quayIntegration.GetGeneration()), ConflictedCondition(conflicts, quayIntegration.GetGeneration()))
+
+	for _, condition := range conditions {
+		existing := meta.FindStatusCondition(quayIntegration.Status.Conditions, condition.Type)
+
+		if existing == nil || existing.Status != condition.Status || existing.Reason != condition.Reason || existing.Message != condition.Message || existing.ObservedGeneration != condition.ObservedGeneration {
+			meta.SetStatusCondition(&quayIntegration.Status.Conditions, condition)
+			changed = true
+		}
+	}
+
+	if quayIntegration.Status.SyncedNamespaces != int32(report.SyncedNamespaces) {
+		quayIntegration.Status.SyncedNamespaces = int32(report.SyncedNamespaces)
+		changed = true
+	}
+
+	if !equality.Semantic.DeepEqual(quayIntegration.Status.Features, h.Features) || !equality.Semantic.DeepEqual(quayIntegration.Status.Permissions, h.Permissions) {
+		quayIntegration.Status.Features = h.Features
+		quayIntegration.Status.Permissions = h.Permissions
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return h.Client.Status().Update(ctx, quayIntegration)
+}
+
+// collectConflicts returns the namespaces selected by each QuayIntegration while synchronized by another one
+func (h *HealthReconciler) collectConflicts(ctx context.Context, quayIntegrations []quayv1.QuayIntegration) (map[string][]syncpolicy.Conflict, error) {
+
+	if len(quayIntegrations) < 2 {
+		return map[string][]syncpolicy.Conflict{}, nil
+	}
+
+	namespaces := corev1.NamespaceList{}
+
+	if err := h.Client.List(ctx, &namespaces); err != nil {
+		return nil, err
+	}
+
+	policies, err := syncpolicy.List(ctx, h.Client)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return syncpolicy.NewScope(quayIntegrations, policies).Conflicts(namespaces.Items), nil
+}
+
+func (h *HealthReconciler) collect(ctx context.Context) *Report {
+
+	report := &Report{
+		WebhookEnabled:   h.WebhookCertPath != "",
+		BacklogThreshold: h.BacklogThreshold,
+	}
+
+	cacheCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+
+	report.CachesSynced = h.Cache.WaitForCacheSync(cacheCtx)
+	report.QuayReachable, report.QuayObserved = metrics.IsQuayReachable()
+
+	if h.ReadinessGate != nil {
+		ready, message := h.ReadinessGate.Status()
+		report.WaitingForQuay, report.WaitingForQuayMessage = !ready, message
+	}
+
+	if report.WebhookEnabled {
+		report.WebhookCertificateError = checkCertificate(h.WebhookCertPath, time.Now())
+	}
+
+	backlog, err := metrics.GetWorkQueueDepth(WorkQueues...)
+
+	if err != nil {
+		h.Log.Error(err, "Unable to determine synchronization backlog")
+	}
+
+	report.Backlog = backlog
+	report.SyncedNamespaces = metrics.GetSyncedNamespaceCount()
+
+	return report
+}
+
+func checkCertificate(certPath string, now time.Time) error {
+
+	certificate, err := utils.ReadCertificate(certPath)
+
+	if err != nil {
+		return err
+	}
+
+	if now.After(certificate.NotAfter) {
+		return fmt.Errorf("certificate expired at %s", certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Conditions returns the Available, Progressing and Degraded conditions described by the Report
+func (r *Report) Conditions(generation int64) []metav1.Condition {
+
+	available := metav1.Condition{Type: quayv1.AvailableConditionType, Status: metav1.ConditionTrue, Reason: AsExpectedReason, Message: "The operator is synchronizing with Quay", ObservedGeneration: generation}
+	progressing := metav1.Condition{Type: quayv1.ProgressingConditionType, Status: metav1.ConditionFalse, Reason: AsExpectedReason, Message: "No synchronizations are pending", ObservedGeneration: generation}
+	degraded := metav1.Condition{Type: quayv1.DegradedConditionType, Status: metav1.ConditionFalse, Reason: AsExpectedReason, Message: "All components are healthy", ObservedGeneration: generation}
+
+	if !r.CachesSynced {
+		available.Status, available.Reason, available.Message = metav1.ConditionFalse, CachesNotSyncedReason, "Informer caches have not synced"
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, CachesNotSyncedReason, "Informer caches have not synced"
+	} else if r.WaitingForQuay {
+		available.Status, available.Reason, available.Message = metav1.ConditionFalse, WaitingForQuayReason, fmt.Sprintf("Waiting for Quay to become ready: %s", r.WaitingForQuayMessage)
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, WaitingForQuayReason, fmt.Sprintf("Waiting for Quay to become ready: %s", r.WaitingForQuayMessage)
+	} else if r.QuayObserved && !r.QuayReachable {
+		available.Status, available.Reason, available.Message = metav1.ConditionFalse, QuayUnreachableReason, "The Quay API is unreachable"
+	} else if !r.QuayObserved {
+		available.Status, available.Reason, available.Message = metav1.ConditionUnknown, QuayNotContactedReason, "No requests have been made against the Quay API"
+	}
+
+	if r.CachesSynced && !r.WaitingForQuay && r.Backlog > 0 {
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, SynchronizingReason, fmt.Sprintf("%d synchronizations are pending", r.Backlog)
+	}
+
+	reasons := []string{}
+	messages := []string{}
+
+	// Quay being unavailable during installation is expected and reported as Progressing only
+	if r.QuayObserved && !r.QuayReachable && !r.WaitingForQuay {
+		reasons = append(reasons, QuayUnreachableReason)
+		messages = append(messages, "The Quay API is unreachable")
+	}
+
+	if r.WebhookEnabled && r.WebhookCertificateError != nil {
+		reasons = append(reasons, WebhookCertificateInvalidReason)
+		messages = append(messages, fmt.Sprintf("The webhook certificate is invalid: %v", r.WebhookCertificateError))
+	}
+
+	if r.BacklogThreshold > 0 && r.Backlog > r.BacklogThreshold {
+		reasons = append(reasons, SyncBacklogHighReason)
+		messages = append(messages, fmt.Sprintf("%d synchronizations are pending, exceeding the threshold of %d", r.Backlog, r.BacklogThreshold))
+	}
+
+	if len(reasons) > 0 {
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, reasons[0], strings.Join(messages, ". ")
+	}
+
+	return []metav1.Condition{available, progressing, degraded}
+}
+
+// ConflictedCondition returns the Conflicted condition of a QuayIntegration listing the namespaces it selects while they are
+// synchronized by another QuayIntegration with precedence
+func ConflictedCondition(conflicts []syncpolicy.Conflict, generation int64) metav1.Condition {
+
+	if len(conflicts) == 0 {
+		return metav1.Condition{Type: quayv1.ConflictedConditionType, Status: metav1.ConditionFalse, Reason: quayv1.NoConflictsReason, Message: "No namespaces are synchronized by another QuayIntegration", ObservedGeneration: generation}
+	}
+
+	sorted := make([]syncpolicy.Conflict, len(conflicts))
+	copy(sorted, conflicts)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Namespace < sorted[j].Namespace
+	})
+
+	examples := []string{}
+
+	for i := 0; i < len(sorted) && i < maxConflictExamples; i++ {
+		examples = append(examples, fmt.Sprintf("%s (QuayIntegration %s)", sorted[i].Namespace, sorted[i].Owner))
+	}
+
+	if len(sorted) > maxConflictExamples {
+		examples = append(examples, fmt.Sprintf("%d more", len(sorted)-maxConflictExamples))
+	}
+
+	return metav1.Condition{
+		Type:               quayv1.ConflictedConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             quayv1.NamespacesOwnedElsewhereReason,
+		Message:            fmt.Sprintf("%d selected namespaces are synchronized by QuayIntegrations with precedence and are not managed by this QuayIntegration: %s", len(sorted), strings.Join(examples, ", ")),
+		ObservedGeneration: generation,
+	}
+}
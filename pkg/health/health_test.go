@@ -0,0 +1,146 @@
+package health
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+)
+
+func TestReportConditions(t *testing.T) {
+
+	cases := []struct {
+		name        string
+		report      Report
+		available   metav1.ConditionStatus
+		progressing metav1.ConditionStatus
+		degraded    metav1.ConditionStatus
+		reason      string
+	}{
+		{
+			name:        "test-healthy",
+			report:      Report{CachesSynced: true, QuayReachable: true, QuayObserved: true, WebhookEnabled: true, BacklogThreshold: 50},
+			available:   metav1.ConditionTrue,
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionFalse,
+			reason:      AsExpectedReason,
+		},
+		{
+			name:        "test-caches-not-synced",
+			report:      Report{CachesSynced: false, BacklogThreshold: 50},
+			available:   metav1.ConditionFalse,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionFalse,
+			reason:      AsExpectedReason,
+		},
+		{
+			name:        "test-quay-not-contacted",
+			report:      Report{CachesSynced: true, BacklogThreshold: 50},
+			available:   metav1.ConditionUnknown,
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionFalse,
+			reason:      AsExpectedReason,
+		},
+		{
+			name:        "test-quay-unreachable",
+			report:      Report{CachesSynced: true, QuayReachable: false, QuayObserved: true, Backlog: 3, BacklogThreshold: 50},
+			available:   metav1.ConditionFalse,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionTrue,
+			reason:      QuayUnreachableReason,
+		},
+		{
+			name:        "test-webhook-certificate-invalid",
+			report:      Report{CachesSynced: true, QuayReachable: true, QuayObserved: true, WebhookEnabled: true, WebhookCertificateError: errors.New("expired"), BacklogThreshold: 50},
+			available:   metav1.ConditionTrue,
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionTrue,
+			reason:      WebhookCertificateInvalidReason,
+		},
+		{
+			name:        "test-backlog-high",
+			report:      Report{CachesSynced: true, QuayReachable: true, QuayObserved: true, Backlog: 51, BacklogThreshold: 50},
+			available:   metav1.ConditionTrue,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionTrue,
+			reason:      SyncBacklogHighReason,
+		},
+		{
+			name:        "test-waiting-for-quay",
+			report:      Report{CachesSynced: true, QuayReachable: false, QuayObserved: true, WaitingForQuay: true, WaitingForQuayMessage: "Quay is unreachable", Backlog: 80, BacklogThreshold: 50},
+			available:   metav1.ConditionFalse,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionTrue,
+			reason:      SyncBacklogHighReason,
+		},
+		{
+			name:        "test-waiting-for-quay-not-degraded",
+			report:      Report{CachesSynced: true, QuayReachable: false, QuayObserved: true, WaitingForQuay: true, WaitingForQuayMessage: "Quay is unreachable", BacklogThreshold: 50},
+			available:   metav1.ConditionFalse,
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionFalse,
+			reason:      AsExpectedReason,
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			conditions := c.report.Conditions(1)
+
+			available := meta.FindStatusCondition(conditions, quayv1.AvailableConditionType)
+			progressing := meta.FindStatusCondition(conditions, quayv1.ProgressingConditionType)
+			degraded := meta.FindStatusCondition(conditions, quayv1.DegradedConditionType)
+
+			if available.Status != c.available || progressing.Status != c.progressing || degraded.Status != c.degraded || degraded.Reason != c.reason {
+				t.Errorf("Test case %d did not match\nExpected: %s %s %s %s\nActual: %s %s %s %s", i, c.available, c.progressing, c.degraded, c.reason, available.Status, progressing.Status, degraded.Status, degraded.Reason)
+			}
+		})
+	}
+}
+
+func TestConflictedCondition(t *testing.T) {
+
+	cases := []struct {
+		conflicts []syncpolicy.Conflict
+		status    metav1.ConditionStatus
+		reason    string
+		message   string
+	}{
+		{
+			conflicts: nil,
+			status:    metav1.ConditionFalse,
+			reason:    quayv1.NoConflictsReason,
+			message:   "No namespaces are synchronized by another QuayIntegration",
+		},
+		{
+			conflicts: []syncpolicy.Conflict{{Namespace: "team-b", Owner: "quay"}, {Namespace: "team-a", Owner: "quay"}},
+			status:    metav1.ConditionTrue,
+			reason:    quayv1.NamespacesOwnedElsewhereReason,
+			message:   "2 selected namespaces are synchronized by QuayIntegrations with precedence and are not managed by this QuayIntegration: team-a (QuayIntegration quay), team-b (QuayIntegration quay)",
+		},
+		{
+			conflicts: []syncpolicy.Conflict{
+				{Namespace: "app-1", Owner: "quay"}, {Namespace: "app-2", Owner: "quay"}, {Namespace: "app-3", Owner: "quay"},
+				{Namespace: "app-4", Owner: "quay"}, {Namespace: "app-5", Owner: "quay"}, {Namespace: "app-6", Owner: "quay"},
+			},
+			status:  metav1.ConditionTrue,
+			reason:  quayv1.NamespacesOwnedElsewhereReason,
+			message: "6 selected namespaces are synchronized by QuayIntegrations with precedence and are not managed by this QuayIntegration: app-1 (QuayIntegration quay), app-2 (QuayIntegration quay), app-3 (QuayIntegration quay), app-4 (QuayIntegration quay), app-5 (QuayIntegration quay), 1 more",
+		},
+	}
+
+	for i, c := range cases {
+
+		condition := ConflictedCondition(c.conflicts, 1)
+
+		if condition.Type != quayv1.ConflictedConditionType || condition.Status != c.status || condition.Reason != c.reason || condition.Message != c.message {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %s\nActual: %s %s %s", i, c.status, c.reason, c.message, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+}
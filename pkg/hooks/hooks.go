@@ -0,0 +1,147 @@
+// Package hooks invokes custom steps at defined points of the synchronization of namespaces, such as registering organizations
+// in a CMDB or opening tickets, without modifying the operator
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+// DefaultTokenKey is the key of the token within the token Secret of a sync hook unless specified
+const DefaultTokenKey = "token"
+
+// Event describes the step of the synchronization of a namespace a hook is invoked at
+type Event struct {
+	Point           quayv1.SyncHookPoint `json:"point"`
+	QuayIntegration string               `json:"quayIntegration"`
+	Namespace       string               `json:"namespace"`
+	Organization    string               `json:"organization"`
+	ServiceAccount  string               `json:"serviceAccount,omitempty"`
+	Secrets         []string             `json:"secrets,omitempty"`
+}
+
+// Hook performs a custom step of the synchronization of a namespace
+type Hook interface {
+	Run(ctx context.Context, event Event) error
+}
+
+// Registration invokes a Hook at the steps it is registered for
+type Registration struct {
+	Name          string
+	Points        []quayv1.SyncHookPoint
+	FailurePolicy quayv1.SyncHookFailurePolicy
+	Hook          Hook
+}
+
+// Failure is the failure of a hook whose failure policy is Ignore
+type Failure struct {
+	Name  string
+	Error error
+}
+
+// Run invokes the hooks registered for the point of an event in order. The first failure of a hook whose failure policy is Fail
+// stops the invocation and is returned, while the failures of other hooks are collected
+func Run(ctx context.Context, registrations []Registration, event Event) ([]Failure, error) {
+
+	failures := []Failure{}
+
+	for _, registration := range registrations {
+
+		if !hasPoint(registration.Points, event.Point) {
+			continue
+		}
+
+		err := registration.Hook.Run(ctx, event)
+
+		if err == nil {
+			continue
+		}
+
+		if registration.FailurePolicy == quayv1.IgnoreSyncHookFailurePolicy {
+			failures = append(failures, Failure{Name: registration.Name, Error: err})
+			continue
+		}
+
+		return failures, fmt.Errorf("hook '%s' failed at %s: %w", registration.Name, event.Point, err)
+	}
+
+	return failures, nil
+}
+
+func hasPoint(points []quayv1.SyncHookPoint, point quayv1.SyncHookPoint) bool {
+
+	for _, p := range points {
+		if p == point {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPHook posts events as JSON to an endpoint. Any status code other than 2xx is a failure
+type HTTPHook struct {
+	Client *http.Client
+	URL    string
+	// Token, when set, is sent as a bearer token
+	Token string
+}
+
+func (h *HTTPHook) Run(ctx context.Context, event Event) error {
+
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	resp, err := h.Client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// NewHTTPRegistration returns the Registration of the HTTP endpoint defined by a sync hook of a QuayIntegration
+func NewHTTPRegistration(syncHook *quayv1.SyncHook, token string) Registration {
+	return Registration{
+		Name:          syncHook.Name,
+		Points:        syncHook.Points,
+		FailurePolicy: syncHook.GetFailurePolicy(),
+		Hook: &HTTPHook{
+			Client: &http.Client{Timeout: syncHook.GetTimeout()},
+			URL:    syncHook.URL,
+			Token:  token,
+		},
+	}
+}
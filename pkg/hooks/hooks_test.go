@@ -0,0 +1,106 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+type recordingHook struct {
+	err    error
+	events []Event
+}
+
+func (h *recordingHook) Run(ctx context.Context, event Event) error {
+	h.events = append(h.events, event)
+	return h.err
+}
+
+func TestRun(t *testing.T) {
+
+	failing := errors.New("unavailable")
+	allPoints := []quayv1.SyncHookPoint{quayv1.PreOrganizationCreateSyncHookPoint, quayv1.PostSecretCreateSyncHookPoint, quayv1.PreDeleteSyncHookPoint}
+
+	cases := []struct {
+		point            quayv1.SyncHookPoint
+		firstErr         error
+		firstPolicy      quayv1.SyncHookFailurePolicy
+		firstPoints      []quayv1.SyncHookPoint
+		expectedFailures []string
+		expectedError    bool
+		expectedSecond   int
+	}{
+		{point: quayv1.PreOrganizationCreateSyncHookPoint, firstPolicy: quayv1.FailSyncHookFailurePolicy, firstPoints: allPoints, expectedFailures: []string{}, expectedSecond: 1},
+		{point: quayv1.PreOrganizationCreateSyncHookPoint, firstErr: failing, firstPolicy: quayv1.FailSyncHookFailurePolicy, firstPoints: allPoints, expectedFailures: []string{}, expectedError: true, expectedSecond: 0},
+		{point: quayv1.PreOrganizationCreateSyncHookPoint, firstErr: failing, firstPolicy: quayv1.IgnoreSyncHookFailurePolicy, firstPoints: allPoints, expectedFailures: []string{"first"}, expectedSecond: 1},
+		{point: quayv1.PreDeleteSyncHookPoint, firstErr: failing, firstPolicy: quayv1.FailSyncHookFailurePolicy, firstPoints: []quayv1.SyncHookPoint{quayv1.PostSecretCreateSyncHookPoint}, expectedFailures: []string{}, expectedSecond: 1},
+	}
+
+	for i, c := range cases {
+
+		first := &recordingHook{err: c.firstErr}
+		second := &recordingHook{}
+
+		failures, err := Run(context.Background(), []Registration{
+			{Name: "first", Points: c.firstPoints, FailurePolicy: c.firstPolicy, Hook: first},
+			{Name: "second", Points: allPoints, FailurePolicy: quayv1.FailSyncHookFailurePolicy, Hook: second},
+		}, Event{Point: c.point, Namespace: "app"})
+
+		names := []string{}
+
+		for _, failure := range failures {
+			names = append(names, failure.Name)
+		}
+
+		if !reflect.DeepEqual(names, c.expectedFailures) || c.expectedError != (err != nil) || len(second.events) != c.expectedSecond {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %d\nActual: %v %v %d", i, c.expectedFailures, c.expectedError, c.expectedSecond, names, err, len(second.events))
+		}
+	}
+}
+
+func TestHTTPHook(t *testing.T) {
+
+	cases := []struct {
+		token         string
+		statusCode    int
+		expectedError bool
+	}{
+		{token: "", statusCode: http.StatusOK, expectedError: false},
+		{token: "secret", statusCode: http.StatusNoContent, expectedError: false},
+		{token: "", statusCode: http.StatusInternalServerError, expectedError: true},
+	}
+
+	for i, c := range cases {
+
+		var received Event
+		var authorization string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(c.statusCode)
+		}))
+
+		event := Event{Point: quayv1.PostSecretCreateSyncHookPoint, QuayIntegration: "example", Namespace: "app", Organization: "openshift_app", ServiceAccount: "builder", Secrets: []string{"builder-quay"}}
+
+		err := (&HTTPHook{Client: server.Client(), URL: server.URL, Token: c.token}).Run(context.Background(), event)
+
+		server.Close()
+
+		expectedAuthorization := ""
+
+		if c.token != "" {
+			expectedAuthorization = "Bearer " + c.token
+		}
+
+		if c.expectedError != (err != nil) || !reflect.DeepEqual(received, event) || authorization != expectedAuthorization {
+			t.Errorf("Test case %d did not match\nExpected Error: %v\nActual: %v %#v %s", i, c.expectedError, err, received, authorization)
+		}
+	}
+}
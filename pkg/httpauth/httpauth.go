@@ -0,0 +1,77 @@
+package httpauth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// bearerScheme is the authorization scheme of the token
+	bearerScheme = "Bearer "
+	// certName is the name of the serving certificate in the certificate directory
+	certName = "tls.crt"
+	// keyName is the name of the serving key in the certificate directory
+	keyName = "tls.key"
+)
+
+// ValidateBindAddress ensures endpoints are only exposed beyond the loopback interface when protected by a token, which must then be
+// served over TLS so that it is not sent in the clear. The name of the endpoints is used in errors
+func ValidateBindAddress(name string, bindAddress string, token string, certDir string) error {
+
+	host, _, err := net.SplitHostPort(bindAddress)
+
+	if err != nil {
+		return err
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+
+	if token == "" {
+		return fmt.Errorf("%s bound to '%s' must be protected by a token", name, bindAddress)
+	}
+
+	if certDir == "" {
+		return fmt.Errorf("%s bound to '%s' must be served over TLS", name, bindAddress)
+	}
+
+	return nil
+}
+
+// RequireBearerToken returns a handler only serving the requests presenting the token using the Bearer scheme. Every request is
+// rejected when the token is empty
+func RequireBearerToken(token string, handler http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		authorization := r.Header.Get("Authorization")
+
+		if token == "" || !strings.HasPrefix(authorization, bearerScheme) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, bearerScheme)), []byte(token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Serve serves requests over TLS using the tls.crt and tls.key files of the certificate directory when one is configured, and in the
+// clear otherwise
+func Serve(srv *http.Server, listener net.Listener, certDir string) error {
+
+	if certDir != "" {
+		return srv.ServeTLS(listener, filepath.Join(certDir, certName), filepath.Join(certDir, keyName))
+	}
+
+	return srv.Serve(listener)
+}
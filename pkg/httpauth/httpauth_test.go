@@ -0,0 +1,127 @@
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateBindAddress(t *testing.T) {
+
+	cases := []struct {
+		bindAddress string
+		token       string
+		certDir     string
+		expectedErr bool
+	}{
+		{
+			bindAddress: "127.0.0.1:8082",
+			expectedErr: false,
+		},
+		{
+			bindAddress: "localhost:8082",
+			expectedErr: false,
+		},
+		{
+			bindAddress: "[::1]:8082",
+			expectedErr: false,
+		},
+		{
+			bindAddress: ":8082",
+			expectedErr: true,
+		},
+		{
+			bindAddress: "0.0.0.0:8082",
+			expectedErr: true,
+		},
+		{
+			bindAddress: ":8082",
+			token:       "secret",
+			expectedErr: true,
+		},
+		{
+			bindAddress: ":8082",
+			certDir:     "/tmp/k8s-debug-server/serving-certs",
+			expectedErr: true,
+		},
+		{
+			bindAddress: ":8082",
+			token:       "secret",
+			certDir:     "/tmp/k8s-debug-server/serving-certs",
+			expectedErr: false,
+		},
+		{
+			bindAddress: "8082",
+			token:       "secret",
+			certDir:     "/tmp/k8s-debug-server/serving-certs",
+			expectedErr: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		err := ValidateBindAddress("endpoints", c.bindAddress, c.token, c.certDir)
+
+		if (err != nil) != c.expectedErr {
+			t.Errorf("Test case %d did not match\nExpected error: %v\nActual: %v", i, c.expectedErr, err)
+		}
+	}
+}
+
+func TestRequireBearerToken(t *testing.T) {
+
+	cases := []struct {
+		token          string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			authorization:  "Bearer invalid",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			authorization:  "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			authorization:  "Basic secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			token:          "secret",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for i, c := range cases {
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		if c.authorization != "" {
+			request.Header.Set("Authorization", c.authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+
+		RequireBearerToken(c.token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(recorder, request)
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("Test case %d did not match\nExpected: %d\nActual: %d", i, c.expectedStatus, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package imagepolicy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+var (
+	// ClusterImagePolicyGVK is the GroupVersionKind of the OpenShift ClusterImagePolicy resource
+	ClusterImagePolicyGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1alpha1", Kind: "ClusterImagePolicy"}
+
+	// ImagePolicyGVK is the GroupVersionKind of the OpenShift ImagePolicy resource
+	ImagePolicyGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1alpha1", Kind: "ImagePolicy"}
+)
+
+const (
+	// PublicKeyPolicyType verifies signatures using a public key
+	PublicKeyPolicyType = "PublicKey"
+)
+
+// GenerateName returns the name of the policies generated for a QuayIntegration
+func GenerateName(quayIntegrationName string) string {
+	return fmt.Sprintf("quay-bridge-operator-%s", quayIntegrationName)
+}
+
+// GenerateInventory returns the name of the inventory tracking the policies generated for a QuayIntegration
+func GenerateInventory(quayIntegrationName string) string {
+	return fmt.Sprintf("%s-imagepolicies", GenerateName(quayIntegrationName))
+}
+
+// GenerateScope returns the scope covering every repository of an organization
+func GenerateScope(registryHostname string, organizationName string) string {
+	return fmt.Sprintf("%s/%s", registryHostname, organizationName)
+}
+
+// GetScope returns the configured scope of the generated policies, defaulting to Namespace
+func GetScope(imagePolicy *quayv1.ImagePolicy) quayv1.ImagePolicyScope {
+
+	if imagePolicy == nil || imagePolicy.Scope == "" {
+		return quayv1.NamespaceImagePolicyScope
+	}
+
+	return imagePolicy.Scope
+}
+
+// GetMatchPolicy returns the configured signed identity match policy, defaulting to MatchRepoDigestOrExact
+func GetMatchPolicy(imagePolicy *quayv1.ImagePolicy) quayv1.ImagePolicyMatchPolicy {
+
+	if imagePolicy == nil || imagePolicy.MatchPolicy == "" {
+		return quayv1.MatchRepoDigestOrExactMatchPolicy
+	}
+
+	return imagePolicy.MatchPolicy
+}
+
+// NewClusterImagePolicy returns a ClusterImagePolicy requiring images within the scopes to be signed by the public key
+func NewClusterImagePolicy(name string, scopes []string, publicKey []byte, matchPolicy quayv1.ImagePolicyMatchPolicy) *unstructured.Unstructured {
+
+	clusterImagePolicy := newPolicy(scopes, publicKey, matchPolicy)
+	clusterImagePolicy.SetGroupVersionKind(ClusterImagePolicyGVK)
+	clusterImagePolicy.SetName(name)
+
+	return clusterImagePolicy
+}
+
+// NewImagePolicy returns an ImagePolicy requiring images within the scopes and pulled in the namespace to be signed by the public key
+func NewImagePolicy(namespace string, name string, scopes []string, publicKey []byte, matchPolicy quayv1.ImagePolicyMatchPolicy) *unstructured.Unstructured {
+
+	imagePolicy := newPolicy(scopes, publicKey, matchPolicy)
+	imagePolicy.SetGroupVersionKind(ImagePolicyGVK)
+	imagePolicy.SetNamespace(namespace)
+	imagePolicy.SetName(name)
+
+	return imagePolicy
+}
+
+func newPolicy(scopes []string, publicKey []byte, matchPolicy quayv1.ImagePolicyMatchPolicy) *unstructured.Unstructured {
+
+	sortedScopes := append([]string{}, scopes...)
+	sort.Strings(sortedScopes)
+
+	policyScopes := []interface{}{}
+
+	for _, scope := range sortedScopes {
+		policyScopes = append(policyScopes, scope)
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"scopes": policyScopes,
+				"policy": map[string]interface{}{
+					"rootOfTrust": map[string]interface{}{
+						"policyType": PublicKeyPolicyType,
+						"publicKey": map[string]interface{}{
+							"keyData": base64.StdEncoding.EncodeToString(publicKey),
+						},
+					},
+					"signedIdentity": map[string]interface{}{
+						"matchPolicy": string(matchPolicy),
+					},
+				},
+			},
+		},
+	}
+}
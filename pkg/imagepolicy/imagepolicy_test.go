@@ -0,0 +1,74 @@
+package imagepolicy
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+func TestNewClusterImagePolicy(t *testing.T) {
+
+	cases := []struct {
+		scopes              []string
+		matchPolicy         quayv1.ImagePolicyMatchPolicy
+		expectedScopes      []interface{}
+		expectedMatchPolicy string
+	}{
+		{
+			scopes:              []string{"quay.example.com/openshift_b", "quay.example.com/openshift_a"},
+			matchPolicy:         quayv1.MatchRepoDigestOrExactMatchPolicy,
+			expectedScopes:      []interface{}{"quay.example.com/openshift_a", "quay.example.com/openshift_b"},
+			expectedMatchPolicy: "MatchRepoDigestOrExact",
+		},
+		{
+			scopes:              []string{},
+			matchPolicy:         quayv1.MatchRepositoryMatchPolicy,
+			expectedScopes:      []interface{}{},
+			expectedMatchPolicy: "MatchRepository",
+		},
+	}
+
+	for i, c := range cases {
+		clusterImagePolicy := NewClusterImagePolicy("test", c.scopes, []byte("public-key"), c.matchPolicy)
+
+		scopes, _, _ := unstructured.NestedSlice(clusterImagePolicy.Object, "spec", "scopes")
+		keyData, _, _ := unstructured.NestedString(clusterImagePolicy.Object, "spec", "policy", "rootOfTrust", "publicKey", "keyData")
+		matchPolicy, _, _ := unstructured.NestedString(clusterImagePolicy.Object, "spec", "policy", "signedIdentity", "matchPolicy")
+
+		if clusterImagePolicy.GroupVersionKind() != ClusterImagePolicyGVK || !reflect.DeepEqual(scopes, c.expectedScopes) || keyData != "cHVibGljLWtleQ==" || matchPolicy != c.expectedMatchPolicy {
+			t.Errorf("Test case %d did not match\nExpected: %v %s\nActual: %v %s %s", i, c.expectedScopes, c.expectedMatchPolicy, scopes, matchPolicy, keyData)
+		}
+	}
+}
+
+func TestGetScope(t *testing.T) {
+
+	cases := []struct {
+		imagePolicy *quayv1.ImagePolicy
+		expected    quayv1.ImagePolicyScope
+	}{
+		{
+			imagePolicy: nil,
+			expected:    quayv1.NamespaceImagePolicyScope,
+		},
+		{
+			imagePolicy: &quayv1.ImagePolicy{},
+			expected:    quayv1.NamespaceImagePolicyScope,
+		},
+		{
+			imagePolicy: &quayv1.ImagePolicy{Scope: quayv1.ClusterImagePolicyScope},
+			expected:    quayv1.ClusterImagePolicyScope,
+		},
+	}
+
+	for i, c := range cases {
+		actual := GetScope(c.imagePolicy)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s", i, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package imagescan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+)
+
+// DefaultInternalRegistryHostnames are the hostnames of the internal registry within the cluster
+var DefaultInternalRegistryHostnames = []string{
+	"image-registry.openshift-image-registry.svc:5000",
+	"image-registry.openshift-image-registry.svc.cluster.local:5000",
+}
+
+// Residual is a reference to the internal registry along with the reason it prevents disabling the internal registry
+type Residual struct {
+	ImageReference `json:",inline"`
+	Reason         string `json:"reason"`
+}
+
+// DecommissionReport lists the references to the internal registry remaining in the cluster. The internal registry can be
+// disabled once the report is ready
+type DecommissionReport struct {
+	Ready bool `json:"ready"`
+	// Hostnames are the hostnames of the internal registry references were checked against
+	Hostnames []string `json:"hostnames"`
+	// Blockers are the QuayIntegrations still relying on the internal registry
+	Blockers []string `json:"blockers,omitempty"`
+	// Namespaces are the number of residual references in each namespace
+	Namespaces map[string]int `json:"namespaces,omitempty"`
+	Residuals  []Residual     `json:"residuals"`
+}
+
+// Decommission checks image references against the internal registry
+type Decommission struct {
+	// Hostnames are the hostnames of the internal registry
+	Hostnames []string
+	// ManagedNamespaces are the namespaces whose Builds are redirected to Quay
+	ManagedNamespaces map[string]bool
+}
+
+// IsInternal returns whether an image pull spec is hosted by the internal registry
+func (d *Decommission) IsInternal(image string) bool {
+
+	for _, hostname := range d.Hostnames {
+		if hostname != "" && strings.HasPrefix(image, hostname+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check returns why an image reference prevents disabling the internal registry, empty when it does not. The output
+// ImageStreamTag of a BuildConfig is pushed to the internal registry unless its namespace is synchronized, Builds of
+// synchronized namespaces being redirected to Quay
+func (d *Decommission) Check(reference ImageReference) string {
+
+	switch {
+	case reference.Kind == DockerImageKind && d.IsInternal(reference.Image):
+		return "Image is hosted by the internal registry"
+
+	case reference.Kind == ImageStreamTagKind && reference.Field == "spec.output.to" && !d.ManagedNamespaces[reference.Object.Namespace]:
+		return "Builds push to the internal registry, the namespace is not synchronized with Quay"
+	}
+
+	return ""
+}
+
+// NewDecommission returns the Decommission of the cluster, checking references against the default hostnames, the
+// additional hostnames and the hostnames of the internal registry reported by ImageStreams. Returns the QuayIntegrations
+// still relying on the internal registry, whose build cutover window has not ended
+func NewDecommission(ctx context.Context, reader client.Reader, hostnames []string, now time.Time) (*Decommission, []string, error) {
+
+	decommission := &Decommission{
+		Hostnames:         appendHostnames(nil, append(append([]string{}, DefaultInternalRegistryHostnames...), hostnames...)...),
+		ManagedNamespaces: map[string]bool{},
+	}
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := reader.List(ctx, &quayIntegrations); err != nil {
+		return nil, nil, err
+	}
+
+	blockers := []string{}
+
+	for i := range quayIntegrations.Items {
+
+		quayIntegration := &quayIntegrations.Items[i]
+
+		namespaces, err := state.ManagedNamespaces(ctx, reader, quayIntegration)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, namespace := range namespaces {
+			decommission.ManagedNamespaces[namespace] = true
+		}
+
+		if phase := quayIntegration.GetBuildCutoverPhase(now); phase != "" && phase != quayv1.CompleteBuildCutoverPhase {
+			blockers = append(blockers, fmt.Sprintf("QuayIntegration %s serves ImageStreamTags from the internal registry until its build cutover window ends at %s", quayIntegration.Name, quayIntegration.Spec.BuildCutover.End.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	internalHostnames, err := DiscoverInternalHostnames(ctx, reader, "", decommission.Hostnames)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decommission.Hostnames = internalHostnames
+
+	return decommission, blockers, nil
+}
+
+// DiscoverInternalHostnames returns the hostnames of the internal registry reported by the ImageStreams of a namespace, or of
+// every namespace when empty, in addition to the provided hostnames
+func DiscoverInternalHostnames(ctx context.Context, reader client.Reader, namespace string, hostnames []string) ([]string, error) {
+
+	result := appendHostnames(nil, hostnames...)
+
+	imageStreams := imagev1.ImageStreamList{}
+
+	if err := reader.List(ctx, &imageStreams, client.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	for i := range imageStreams.Items {
+		result = appendHostnames(result, hostnameOf(imageStreams.Items[i].Status.DockerImageRepository), hostnameOf(imageStreams.Items[i].Status.PublicDockerImageRepository))
+	}
+
+	return result, nil
+}
+
+// Report checks image references and returns the report of the residual references to the internal registry
+func (d *Decommission) Report(references []ImageReference, blockers []string) *DecommissionReport {
+
+	report := &DecommissionReport{
+		Hostnames:  d.Hostnames,
+		Blockers:   blockers,
+		Namespaces: map[string]int{},
+		Residuals:  []Residual{},
+	}
+
+	for _, reference := range references {
+		if reason := d.Check(reference); reason != "" {
+			report.Residuals = append(report.Residuals, Residual{ImageReference: reference, Reason: reason})
+			report.Namespaces[reference.Object.Namespace]++
+		}
+	}
+
+	report.Ready = len(report.Blockers) == 0 && len(report.Residuals) == 0
+
+	return report
+}
+
+// WriteDecommissionReport writes a report as YAML
+func WriteDecommissionReport(w io.Writer, report *DecommissionReport) error {
+
+	data, err := yaml.Marshal(report)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// hostnameOf returns the hostname of a repository pull spec
+func hostnameOf(repository string) string {
+
+	if i := strings.Index(repository, "/"); i > 0 {
+		return repository[:i]
+	}
+
+	return ""
+}
+
+func appendHostnames(hostnames []string, additional ...string) []string {
+
+	for _, hostname := range additional {
+
+		if hostname == "" {
+			continue
+		}
+
+		found := false
+
+		for _, existing := range hostnames {
+			if existing == hostname {
+				found = true
+			}
+		}
+
+		if !found {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	return hostnames
+}
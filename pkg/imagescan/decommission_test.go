@@ -0,0 +1,94 @@
+package imagescan
+
+import (
+	"reflect"
+	"testing"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const internalRegistry = "image-registry.openshift-image-registry.svc:5000"
+
+func TestDecommissionReport(t *testing.T) {
+
+	decommission := &Decommission{
+		Hostnames:         DefaultInternalRegistryHostnames,
+		ManagedNamespaces: map[string]bool{"app": true},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "quay.example.com/app/init:latest"}},
+			Containers:     []corev1.Container{{Name: "web", Image: internalRegistry + "/app/web@sha256:abc"}},
+		},
+	}
+
+	managedBuildConfig := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+		Spec: buildv1.BuildConfigSpec{CommonSpec: buildv1.CommonSpec{
+			Output:   buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "web:latest"}},
+			Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{From: &corev1.ObjectReference{Kind: "DockerImage", Name: internalRegistry + "/openshift/base:latest"}}},
+		}},
+	}
+
+	unmanagedBuildConfig := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "legacy", Name: "api"},
+		Spec: buildv1.BuildConfigSpec{CommonSpec: buildv1.CommonSpec{
+			Output:   buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "api:latest"}},
+			Strategy: buildv1.BuildStrategy{SourceStrategy: &buildv1.SourceBuildStrategy{From: corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "openshift", Name: "nodejs:16"}}},
+		}},
+	}
+
+	imageStream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "web"},
+		Spec:       imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.example.com/app/web:latest"}}}},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "latest", Items: []imagev1.TagEvent{{DockerImageReference: "quay.example.com/app/web@sha256:def"}}},
+			{Tag: "legacy", Items: []imagev1.TagEvent{{DockerImageReference: internalRegistry + "/app/web@sha256:abc"}}},
+		}},
+	}
+
+	references := append(PodImages(pod), BuildConfigImages(managedBuildConfig)...)
+	references = append(references, BuildConfigImages(unmanagedBuildConfig)...)
+	references = append(references, ImageStreamImages(imageStream)...)
+
+	cases := []struct {
+		blockers           []string
+		expectedReady      bool
+		expectedFields     []string
+		expectedNamespaces map[string]int
+	}{
+		{
+			expectedFields:     []string{"spec.containers[web].image", "spec.strategy.dockerStrategy.from", "spec.output.to", "status.tags[legacy].items[0].dockerImageReference"},
+			expectedNamespaces: map[string]int{"app": 3, "legacy": 1},
+		},
+		{
+			blockers:           []string{"QuayIntegration quay serves ImageStreamTags from the internal registry"},
+			expectedFields:     []string{"spec.containers[web].image", "spec.strategy.dockerStrategy.from", "spec.output.to", "status.tags[legacy].items[0].dockerImageReference"},
+			expectedNamespaces: map[string]int{"app": 3, "legacy": 1},
+		},
+	}
+
+	for i, c := range cases {
+
+		report := decommission.Report(references, c.blockers)
+
+		fields := []string{}
+
+		for _, residual := range report.Residuals {
+			fields = append(fields, residual.Field)
+		}
+
+		if report.Ready != c.expectedReady || !reflect.DeepEqual(fields, c.expectedFields) || !reflect.DeepEqual(report.Namespaces, c.expectedNamespaces) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %v\nActual: %v %v %v", i, c.expectedReady, c.expectedFields, c.expectedNamespaces, report.Ready, fields, report.Namespaces)
+		}
+	}
+
+	if report := decommission.Report(PodImages(&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "quay.example.com/app/web:latest"}}}}), nil); !report.Ready {
+		t.Errorf("Expected the report to be ready without residual references: %v", report.Residuals)
+	}
+}
@@ -0,0 +1,170 @@
+package imagescan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+)
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list
+
+const (
+	// DockerImageKind refers to an image by its pull spec
+	DockerImageKind = "DockerImage"
+	// ImageStreamTagKind refers to a tag of an ImageStream, hosted by the internal registry unless imported
+	ImageStreamTagKind = "ImageStreamTag"
+)
+
+// ImageReference is a reference to an image by a field of a Pod, BuildConfig or ImageStream
+type ImageReference struct {
+	Object reconcilerbase.ObjectReference `json:"object"`
+	Field  string                         `json:"field"`
+	Kind   string                         `json:"kind"`
+	// Image is the pull spec of a DockerImage, or the namespace and name of an ImageStreamTag
+	Image string `json:"image"`
+}
+
+// PodImages returns the images of the containers of a Pod
+func PodImages(pod *corev1.Pod) []ImageReference {
+
+	object := newObjectReference(pod, "v1", "Pod")
+	references := []ImageReference{}
+
+	for _, container := range pod.Spec.InitContainers {
+		references = append(references, ImageReference{Object: object, Field: fmt.Sprintf("spec.initContainers[%s].image", container.Name), Kind: DockerImageKind, Image: container.Image})
+	}
+
+	for _, container := range pod.Spec.Containers {
+		references = append(references, ImageReference{Object: object, Field: fmt.Sprintf("spec.containers[%s].image", container.Name), Kind: DockerImageKind, Image: container.Image})
+	}
+
+	for _, container := range pod.Spec.EphemeralContainers {
+		references = append(references, ImageReference{Object: object, Field: fmt.Sprintf("spec.ephemeralContainers[%s].image", container.Name), Kind: DockerImageKind, Image: container.Image})
+	}
+
+	return references
+}
+
+// BuildConfigImages returns the output and base image of a BuildConfig
+func BuildConfigImages(buildConfig *buildv1.BuildConfig) []ImageReference {
+
+	object := newObjectReference(buildConfig, buildv1.GroupVersion.String(), "BuildConfig")
+	references := []ImageReference{}
+
+	references = appendObjectReference(references, object, "spec.output.to", buildConfig.Spec.Output.To, buildConfig.Namespace)
+
+	strategy := buildConfig.Spec.Strategy
+
+	switch {
+	case strategy.DockerStrategy != nil:
+		references = appendObjectReference(references, object, "spec.strategy.dockerStrategy.from", strategy.DockerStrategy.From, buildConfig.Namespace)
+	case strategy.SourceStrategy != nil:
+		references = appendObjectReference(references, object, "spec.strategy.sourceStrategy.from", &strategy.SourceStrategy.From, buildConfig.Namespace)
+	case strategy.CustomStrategy != nil:
+		references = appendObjectReference(references, object, "spec.strategy.customStrategy.from", &strategy.CustomStrategy.From, buildConfig.Namespace)
+	}
+
+	return references
+}
+
+// ImageStreamImages returns the images an ImageStream is imported from, and the latest image of each of its tags
+func ImageStreamImages(imageStream *imagev1.ImageStream) []ImageReference {
+
+	object := newObjectReference(imageStream, imagev1.GroupVersion.String(), "ImageStream")
+	references := []ImageReference{}
+
+	if imageStream.Spec.DockerImageRepository != "" {
+		references = append(references, ImageReference{Object: object, Field: "spec.dockerImageRepository", Kind: DockerImageKind, Image: imageStream.Spec.DockerImageRepository})
+	}
+
+	for _, tag := range imageStream.Spec.Tags {
+		if tag.From != nil && tag.From.Kind == DockerImageKind {
+			references = append(references, ImageReference{Object: object, Field: fmt.Sprintf("spec.tags[%s].from", tag.Name), Kind: DockerImageKind, Image: tag.From.Name})
+		}
+	}
+
+	for _, tag := range imageStream.Status.Tags {
+		if len(tag.Items) > 0 && tag.Items[0].DockerImageReference != "" {
+			references = append(references, ImageReference{Object: object, Field: fmt.Sprintf("status.tags[%s].items[0].dockerImageReference", tag.Tag), Kind: DockerImageKind, Image: tag.Items[0].DockerImageReference})
+		}
+	}
+
+	return references
+}
+
+// List returns the image references of the Pods, BuildConfigs and ImageStreams of a namespace, or of every namespace when
+// empty, sorted by object. BuildConfigs and ImageStreams are skipped when not served by the cluster
+func List(ctx context.Context, reader client.Reader, namespace string) ([]ImageReference, error) {
+
+	references := []ImageReference{}
+
+	pods := corev1.PodList{}
+
+	if err := reader.List(ctx, &pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range pods.Items {
+		references = append(references, PodImages(&pods.Items[i])...)
+	}
+
+	buildConfigs := buildv1.BuildConfigList{}
+
+	if err := reader.List(ctx, &buildConfigs, client.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	for i := range buildConfigs.Items {
+		references = append(references, BuildConfigImages(&buildConfigs.Items[i])...)
+	}
+
+	imageStreams := imagev1.ImageStreamList{}
+
+	if err := reader.List(ctx, &imageStreams, client.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	for i := range imageStreams.Items {
+		references = append(references, ImageStreamImages(&imageStreams.Items[i])...)
+	}
+
+	sort.SliceStable(references, func(i, j int) bool {
+		return references[i].Object.String() < references[j].Object.String()
+	})
+
+	return references, nil
+}
+
+func newObjectReference(obj client.Object, apiVersion string, kind string) reconcilerbase.ObjectReference {
+	return reconcilerbase.ObjectReference{APIVersion: apiVersion, Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+}
+
+// appendObjectReference appends a DockerImage or ImageStreamTag reference of a BuildConfig. ImageStreamTags default to the
+// namespace of the BuildConfig
+func appendObjectReference(references []ImageReference, object reconcilerbase.ObjectReference, field string, reference *corev1.ObjectReference, namespace string) []ImageReference {
+
+	if reference == nil {
+		return references
+	}
+
+	switch reference.Kind {
+	case DockerImageKind:
+		return append(references, ImageReference{Object: object, Field: field, Kind: DockerImageKind, Image: reference.Name})
+	case ImageStreamTagKind:
+		if reference.Namespace != "" {
+			namespace = reference.Namespace
+		}
+
+		return append(references, ImageReference{Object: object, Field: field, Kind: ImageStreamTagKind, Image: namespace + "/" + reference.Name})
+	}
+
+	return references
+}
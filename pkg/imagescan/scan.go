@@ -0,0 +1,155 @@
+package imagescan
+
+import (
+	"io"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap recording the references to the internal registry or to unmanaged
+	// registries of each managed namespace
+	DefaultConfigMapName = "quay-bridge-operator-image-scan"
+
+	// QuayRegistry is the Quay registry of a QuayIntegration
+	QuayRegistry = "quay"
+	// InternalRegistry is the internal registry of the cluster
+	InternalRegistry = "internal"
+	// AllowedRegistry is a registry allowed alongside Quay, such as the registry serving the images of the platform
+	AllowedRegistry = "allowed"
+	// UnmanagedRegistry is any other registry
+	UnmanagedRegistry = "unmanaged"
+
+	// defaultRegistry is the registry of image references without a registry hostname
+	defaultRegistry = "docker.io"
+)
+
+// Finding is an image reference to the internal registry or to an unmanaged registry
+type Finding struct {
+	ImageReference `json:",inline"`
+	Registry       string `json:"registry"`
+}
+
+// Scanner classifies image references by the registry hosting them
+type Scanner struct {
+	// QuayHostnames are the registry hostnames of the QuayIntegrations
+	QuayHostnames []string
+	// InternalHostnames are the hostnames of the internal registry
+	InternalHostnames []string
+	// AllowedRegistries are the registries, optionally followed by a repository path, allowed alongside Quay
+	AllowedRegistries []string
+}
+
+// RegistryHostname returns the registry hostname of an image pull spec. The first component of the pull spec is a hostname
+// when it contains a dot or a port, or is localhost, and the image is otherwise hosted by Docker Hub
+func RegistryHostname(image string) string {
+
+	i := strings.Index(image, "/")
+
+	if i < 0 {
+		return defaultRegistry
+	}
+
+	if component := image[:i]; strings.ContainsAny(component, ".:") || component == "localhost" {
+		return strings.ToLower(component)
+	}
+
+	return defaultRegistry
+}
+
+// Classify returns the registry hosting an image reference, empty for references to ImageStreamTags which are resolved by
+// their ImageStream
+func (s *Scanner) Classify(reference ImageReference) string {
+
+	if reference.Kind != DockerImageKind || reference.Image == "" {
+		return ""
+	}
+
+	hostname := RegistryHostname(reference.Image)
+
+	switch {
+	case containsHostname(s.QuayHostnames, hostname):
+		return QuayRegistry
+
+	case containsHostname(s.InternalHostnames, hostname):
+		return InternalRegistry
+	}
+
+	image := strings.ToLower(normalizeImage(reference.Image))
+
+	for _, allowed := range s.AllowedRegistries {
+
+		allowed = strings.TrimSuffix(strings.ToLower(allowed), "/")
+
+		if allowed != "" && (allowed == hostname || strings.HasPrefix(image, allowed+"/")) {
+			return AllowedRegistry
+		}
+	}
+
+	return UnmanagedRegistry
+}
+
+// Scan classifies image references, returning the number of references hosted by each registry and the references to the
+// internal registry or to unmanaged registries
+func (s *Scanner) Scan(references []ImageReference) (map[string]int, []Finding) {
+
+	counts := map[string]int{QuayRegistry: 0, InternalRegistry: 0, AllowedRegistry: 0, UnmanagedRegistry: 0}
+	findings := []Finding{}
+
+	for _, reference := range references {
+
+		registry := s.Classify(reference)
+
+		if registry == "" {
+			continue
+		}
+
+		counts[registry]++
+
+		if registry == InternalRegistry || registry == UnmanagedRegistry {
+			findings = append(findings, Finding{ImageReference: reference, Registry: registry})
+		}
+	}
+
+	return counts, findings
+}
+
+// WriteFindings writes findings as YAML
+func WriteFindings(w io.Writer, findings []Finding) error {
+
+	data, err := yaml.Marshal(findings)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// normalizeImage qualifies the pull spec of an image hosted by Docker Hub, such as nginx for docker.io/library/nginx
+func normalizeImage(image string) string {
+
+	if RegistryHostname(image) != defaultRegistry || strings.HasPrefix(image, defaultRegistry+"/") {
+		return image
+	}
+
+	if !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+
+	return defaultRegistry + "/" + image
+}
+
+func containsHostname(hostnames []string, hostname string) bool {
+
+	for _, candidate := range hostnames {
+		if strings.EqualFold(candidate, hostname) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package imagescan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistryHostname(t *testing.T) {
+
+	cases := []struct {
+		image    string
+		expected string
+	}{
+		{image: "nginx", expected: "docker.io"},
+		{image: "library/nginx:latest", expected: "docker.io"},
+		{image: "Quay.Example.com/app/web:latest", expected: "quay.example.com"},
+		{image: "localhost/app/web", expected: "localhost"},
+		{image: "image-registry.openshift-image-registry.svc:5000/app/web@sha256:abc", expected: "image-registry.openshift-image-registry.svc:5000"},
+	}
+
+	for i, c := range cases {
+
+		actual := RegistryHostname(c.image)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s", i, c.expected, actual)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+
+	scanner := &Scanner{
+		QuayHostnames:     []string{"quay.example.com"},
+		InternalHostnames: DefaultInternalRegistryHostnames,
+		AllowedRegistries: []string{"registry.redhat.io", "docker.io/library/"},
+	}
+
+	cases := []struct {
+		reference        ImageReference
+		expectedRegistry string
+	}{
+		{reference: ImageReference{Kind: DockerImageKind, Image: "quay.example.com/app/web:latest"}, expectedRegistry: QuayRegistry},
+		{reference: ImageReference{Kind: DockerImageKind, Image: "image-registry.openshift-image-registry.svc:5000/app/web:latest"}, expectedRegistry: InternalRegistry},
+		{reference: ImageReference{Kind: DockerImageKind, Image: "registry.redhat.io/ubi8/ubi:latest"}, expectedRegistry: AllowedRegistry},
+		{reference: ImageReference{Kind: DockerImageKind, Image: "docker.io/library/nginx:latest"}, expectedRegistry: AllowedRegistry},
+		{reference: ImageReference{Kind: DockerImageKind, Image: "nginx:latest"}, expectedRegistry: AllowedRegistry},
+		{reference: ImageReference{Kind: DockerImageKind, Image: "bitnami/redis:latest"}, expectedRegistry: UnmanagedRegistry},
+		{reference: ImageReference{Kind: DockerImageKind, Image: "ghcr.io/app/web:latest"}, expectedRegistry: UnmanagedRegistry},
+		{reference: ImageReference{Kind: ImageStreamTagKind, Image: "app/web:latest"}, expectedRegistry: ""},
+	}
+
+	references := []ImageReference{}
+	expectedFindings := []Finding{}
+
+	for i, c := range cases {
+
+		actual := scanner.Classify(c.reference)
+
+		if actual != c.expectedRegistry {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s", i, c.expectedRegistry, actual)
+		}
+
+		references = append(references, c.reference)
+
+		if c.expectedRegistry == InternalRegistry || c.expectedRegistry == UnmanagedRegistry {
+			expectedFindings = append(expectedFindings, Finding{ImageReference: c.reference, Registry: c.expectedRegistry})
+		}
+	}
+
+	counts, findings := scanner.Scan(references)
+	expectedCounts := map[string]int{QuayRegistry: 1, InternalRegistry: 1, AllowedRegistry: 3, UnmanagedRegistry: 2}
+
+	if !reflect.DeepEqual(counts, expectedCounts) || !reflect.DeepEqual(findings, expectedFindings) {
+		t.Errorf("Scan did not match\nExpected: %v %v\nActual: %v %v", expectedCounts, expectedFindings, counts, findings)
+	}
+}
@@ -0,0 +1,277 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/console"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/imagepolicy"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+)
+
+const (
+	// DryRunCleanup reports orphaned resources without deleting them
+	DryRunCleanup = "DryRun"
+	// DeleteCleanup deletes the orphaned resources reported by the previous dry run
+	DeleteCleanup = "Delete"
+)
+
+var (
+	// Kinds are the kinds of the resources created by the operator which outlive the namespaces and QuayIntegrations they belong to
+	Kinds = []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Secret"},
+		{Version: "v1", Kind: "ConfigMap"},
+		console.ConsoleLinkGVK,
+		imagepolicy.ImagePolicyGVK,
+		imagepolicy.ClusterImagePolicyGVK,
+	}
+
+	// managedLabels identify the resources created by the operator, including the resources created by previous versions
+	// before resources were labeled with the name of their QuayIntegration
+	managedLabels = []string{quayv1.QuayIntegrationLabel, constants.ManagedSecretLabel, reconcilerbase.InventoryLabel}
+)
+
+// Resource is a resource created by the operator. Reason explains why the resource is orphaned and is empty while the
+// resource is in use
+type Resource struct {
+	Reference       reconcilerbase.ObjectReference `json:"reference"`
+	QuayIntegration string                         `json:"quayIntegration,omitempty"`
+	Reason          string                         `json:"reason,omitempty"`
+}
+
+// IsOrphaned returns whether the resource is no longer used by any QuayIntegration
+func (r Resource) IsOrphaned() bool {
+	return r.Reason != ""
+}
+
+// Report is the inventory of the resources created by the operator along with the number of orphaned resources
+type Report struct {
+	Resources []Resource `json:"resources"`
+	Orphans   int        `json:"orphans"`
+}
+
+// State is the state of the cluster resources are checked against
+type State struct {
+	// QuayIntegrations are the names of the existing QuayIntegrations
+	QuayIntegrations map[string]bool
+	// RobotSecrets are the robot account Secrets expected in the namespaces synchronized by the QuayIntegrations
+	RobotSecrets map[types.NamespacedName]bool
+}
+
+// LoadState retrieves the QuayIntegrations and the robot account Secrets expected in the namespaces they synchronize
+func LoadState(ctx context.Context, reader client.Reader) (*State, error) {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := reader.List(ctx, &quayIntegrations); err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		QuayIntegrations: map[string]bool{},
+		RobotSecrets:     map[types.NamespacedName]bool{},
+	}
+
+	for i := range quayIntegrations.Items {
+
+		quayIntegration := &quayIntegrations.Items[i]
+		state.QuayIntegrations[quayIntegration.Name] = true
+
+		if err := state.addRobotSecrets(ctx, reader, quayIntegration); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+func (s *State) addRobotSecrets(ctx context.Context, reader client.Reader, quayIntegration *quayv1.QuayIntegration) error {
+
+	namespaces, err := state.ManagedNamespaces(ctx, reader, quayIntegration)
+
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		for _, secretName := range utils.GenerateRobotAccountSecretNames(quayIntegration, namespace) {
+			s.RobotSecrets[types.NamespacedName{Namespace: namespace, Name: secretName}] = true
+		}
+	}
+
+	return nil
+}
+
+// GetQuayIntegration returns the name of the QuayIntegration a resource belongs to, read from its label or else from its
+// owner references. Resources created by previous versions of the operator may not be attributed to a QuayIntegration
+func GetQuayIntegration(obj *unstructured.Unstructured) string {
+
+	if quayIntegration := obj.GetLabels()[quayv1.QuayIntegrationLabel]; quayIntegration != "" {
+		return quayIntegration
+	}
+
+	for _, ownerReference := range obj.GetOwnerReferences() {
+		if ownerReference.Kind == "QuayIntegration" && ownerReference.APIVersion == quayv1.GroupVersion.String() {
+			return ownerReference.Name
+		}
+	}
+
+	return ""
+}
+
+// Classify determines the QuayIntegration of a resource and whether the resource is orphaned. Resources are orphaned once
+// their QuayIntegration no longer exists, and robot account Secrets once no QuayIntegration expects them, such as the
+// Secrets of namespaces no longer synchronized or named after a previous SecretNameTemplate
+func (s *State) Classify(obj *unstructured.Unstructured) Resource {
+
+	resource := Resource{
+		Reference:       reconcilerbase.NewObjectReference(obj),
+		QuayIntegration: GetQuayIntegration(obj),
+	}
+
+	switch {
+	case resource.QuayIntegration != "" && !s.QuayIntegrations[resource.QuayIntegration]:
+		resource.Reason = fmt.Sprintf("QuayIntegration %s no longer exists", resource.QuayIntegration)
+
+	case len(s.QuayIntegrations) == 0:
+		resource.Reason = "No QuayIntegration is defined"
+
+	case obj.GetKind() == "Secret" && obj.GetLabels()[constants.ManagedSecretLabel] == "true" && !s.RobotSecrets[types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}]:
+		resource.Reason = "Robot account Secret is not used by any synchronized namespace"
+	}
+
+	return resource
+}
+
+// List returns the inventory of the resources created by the operator, sorted by reference. Kinds not served by the cluster
+// are skipped
+func List(ctx context.Context, reader client.Reader, state *State) ([]Resource, error) {
+
+	resources := []Resource{}
+	found := map[reconcilerbase.ObjectReference]bool{}
+
+	for _, gvk := range Kinds {
+		for _, label := range managedLabels {
+
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+			err := reader.List(ctx, list, client.HasLabels{label})
+
+			if meta.IsNoMatchError(err) {
+				break
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range list.Items {
+
+				resource := state.Classify(&list.Items[i])
+
+				if !found[resource.Reference] {
+					found[resource.Reference] = true
+					resources = append(resources, resource)
+				}
+			}
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Reference.String() < resources[j].Reference.String()
+	})
+
+	return resources, nil
+}
+
+// Orphans returns the orphaned resources of an inventory
+func Orphans(resources []Resource) []Resource {
+
+	orphans := []Resource{}
+
+	for _, resource := range resources {
+		if resource.IsOrphaned() {
+			orphans = append(orphans, resource)
+		}
+	}
+
+	return orphans
+}
+
+// Reported returns the orphaned resources which were reported by a previous dry run, which are the only orphans deleted
+func Reported(orphans []Resource, reported []string) []Resource {
+
+	previous := map[string]bool{}
+
+	for _, reference := range reported {
+		previous[reference] = true
+	}
+
+	result := []Resource{}
+
+	for _, orphan := range orphans {
+		if previous[orphan.Reference.String()] {
+			result = append(result, orphan)
+		}
+	}
+
+	return result
+}
+
+// References returns the references of resources as reported in the status of a QuayIntegration
+func References(resources []Resource) []string {
+
+	references := []string{}
+
+	for _, resource := range resources {
+		references = append(references, resource.Reference.String())
+	}
+
+	return references
+}
+
+// Objects returns the objects of resources suitable for deletion
+func Objects(resources []Resource) []client.Object {
+
+	objs := []client.Object{}
+
+	for _, resource := range resources {
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(resource.Reference.GroupVersionKind())
+		obj.SetNamespace(resource.Reference.Namespace)
+		obj.SetName(resource.Reference.Name)
+
+		objs = append(objs, obj)
+	}
+
+	return objs
+}
+
+// WriteReport writes the inventory of the resources created by the operator as YAML
+func WriteReport(w io.Writer, resources []Resource) error {
+
+	data, err := yaml.Marshal(&Report{Resources: resources, Orphans: len(Orphans(resources))})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
@@ -0,0 +1,123 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+)
+
+func newResource(kind string, namespace string, name string, labels map[string]string, ownerReferences ...metav1.OwnerReference) *unstructured.Unstructured {
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	obj.SetOwnerReferences(ownerReferences)
+
+	return obj
+}
+
+func TestClassify(t *testing.T) {
+
+	state := &State{
+		QuayIntegrations: map[string]bool{"quay": true},
+		RobotSecrets:     map[types.NamespacedName]bool{{Namespace: "app", Name: "default-quay"}: true},
+	}
+
+	cases := []struct {
+		state                   *State
+		obj                     *unstructured.Unstructured
+		expectedQuayIntegration string
+		expectedOrphaned        bool
+	}{
+		{
+			state:                   state,
+			obj:                     newResource("ConfigMap", "app", "catalog", map[string]string{quayv1.QuayIntegrationLabel: "quay"}),
+			expectedQuayIntegration: "quay",
+		},
+		{
+			state:                   state,
+			obj:                     newResource("ConfigMap", "app", "catalog", map[string]string{quayv1.QuayIntegrationLabel: "renamed"}),
+			expectedQuayIntegration: "renamed",
+			expectedOrphaned:        true,
+		},
+		{
+			state:                   state,
+			obj:                     newResource("ConfigMap", "operator", "reader-robot-inventory", map[string]string{reconcilerbase.InventoryLabel: "reader-robot"}, metav1.OwnerReference{APIVersion: "quay.redhat.com/v1", Kind: "QuayIntegration", Name: "deleted"}),
+			expectedQuayIntegration: "deleted",
+			expectedOrphaned:        true,
+		},
+		{
+			state:                   state,
+			obj:                     newResource("Secret", "app", "default-quay", map[string]string{constants.ManagedSecretLabel: "true", quayv1.QuayIntegrationLabel: "quay"}),
+			expectedQuayIntegration: "quay",
+		},
+		{
+			state:            state,
+			obj:              newResource("Secret", "app", "builder-quay-legacy", map[string]string{constants.ManagedSecretLabel: "true"}),
+			expectedOrphaned: true,
+		},
+		{
+			state:            state,
+			obj:              newResource("Secret", "unsynchronized", "default-quay", map[string]string{constants.ManagedSecretLabel: "true"}),
+			expectedOrphaned: true,
+		},
+		{
+			state:                   state,
+			obj:                     newResource("Secret", "granted", "quay-pull-grant-app", map[string]string{quayv1.QuayIntegrationLabel: "quay"}),
+			expectedQuayIntegration: "quay",
+		},
+		{
+			state:            &State{QuayIntegrations: map[string]bool{}, RobotSecrets: map[types.NamespacedName]bool{}},
+			obj:              newResource("ConfigMap", "operator", "image-policy-inventory", map[string]string{reconcilerbase.InventoryLabel: "image-policy"}),
+			expectedOrphaned: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		resource := c.state.Classify(c.obj)
+
+		if resource.QuayIntegration != c.expectedQuayIntegration || resource.IsOrphaned() != c.expectedOrphaned {
+			t.Errorf("Test case %d did not match\nExpected: %s %v\nActual: %s %v (%s)", i, c.expectedQuayIntegration, c.expectedOrphaned, resource.QuayIntegration, resource.IsOrphaned(), resource.Reason)
+		}
+	}
+}
+
+func TestReported(t *testing.T) {
+
+	reported := reconcilerbase.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "app", Name: "default-quay-legacy"}
+	unreported := reconcilerbase.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "app", Name: "catalog"}
+
+	orphans := []Resource{
+		{Reference: reported, Reason: "Robot account Secret is not used by any synchronized namespace"},
+		{Reference: unreported, Reason: "QuayIntegration renamed no longer exists"},
+	}
+
+	cases := []struct {
+		reported []string
+		expected []Resource
+	}{
+		{reported: nil, expected: []Resource{}},
+		{reported: []string{reported.String(), "v1/Secret app/deleted"}, expected: orphans[:1]},
+		{reported: References(orphans), expected: orphans},
+	}
+
+	for i, c := range cases {
+
+		actual := Reported(orphans, c.reported)
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, actual)
+		}
+	}
+}
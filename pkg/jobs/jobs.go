@@ -0,0 +1,473 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap the queue is checkpointed to
+	DefaultConfigMapName = "quay-bridge-operator-jobs"
+	// DefaultMaxAttempts is the number of times a job is run before being marked as failed
+	DefaultMaxAttempts = 5
+	// DefaultRetryInterval is the delay before a job is run again after its first failure. The delay grows with each attempt
+	DefaultRetryInterval = 30 * time.Second
+	// DefaultRetention is the time finished jobs are kept to report their outcome
+	DefaultRetention = time.Hour
+)
+
+// ErrPending is returned by handlers waiting on work performed outside of the operator. The job is run again after the
+// retry interval without consuming an attempt
+var ErrPending = fmt.Errorf("job is waiting on pending work")
+
+// State of a Job
+type State string
+
+const (
+	// PendingState jobs are waiting to be run
+	PendingState State = "Pending"
+	// RunningState jobs are being run
+	RunningState State = "Running"
+	// SucceededState jobs have completed
+	SucceededState State = "Succeeded"
+	// FailedState jobs have exhausted their attempts
+	FailedState State = "Failed"
+)
+
+// Job is a long-running operation run in the background. Progress is recorded as a checkpoint, letting the job resume
+// where it stopped after a failure or a restart of the operator
+type Job struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Params     map[string]string `json:"params,omitempty"`
+	State      State             `json:"state"`
+	Checkpoint string            `json:"checkpoint,omitempty"`
+	Attempts   int               `json:"attempts,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+	NotBefore  time.Time         `json:"notBefore"`
+}
+
+// IsFinished returns whether the job has succeeded or failed
+func (j Job) IsFinished() bool {
+	return j.State == SucceededState || j.State == FailedState
+}
+
+// Handler runs a job. The checkpoint function records the progress of the job, which is provided back through
+// Job.Checkpoint when the job is run again
+type Handler func(ctx context.Context, job Job, checkpoint func(progress string) error) error
+
+// Store persists the checkpoint of a Queue
+type Store interface {
+	Load(ctx context.Context) (map[string]string, error)
+	Save(ctx context.Context, data map[string]string) error
+}
+
+// ConfigMapStore persists the checkpoint of a Queue to a ConfigMap. The reader should not be backed by the cache
+type ConfigMapStore struct {
+	Client    client.Client
+	Reader    client.Reader
+	Namespace string
+	Name      string
+}
+
+// Load implements Store
+func (s *ConfigMapStore) Load(ctx context.Context) (map[string]string, error) {
+
+	configMap := &corev1.ConfigMap{}
+
+	if err := s.Reader.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	return configMap.Data, nil
+}
+
+// Save implements Store
+func (s *ConfigMapStore) Save(ctx context.Context, data map[string]string) error {
+
+	configMap := &corev1.ConfigMap{}
+
+	err := s.Reader.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, configMap)
+
+	if apierrors.IsNotFound(err) {
+		return s.Client.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: s.Namespace},
+			Data:       data,
+		})
+	} else if err != nil {
+		return err
+	}
+
+	configMap.Data = data
+
+	return s.Client.Update(ctx, configMap)
+}
+
+// Queue runs jobs one at a time and checkpoints them to a Store, letting jobs survive restarts of the operator
+type Queue struct {
+	Store Store
+	Log   logr.Logger
+
+	MaxAttempts   int
+	RetryInterval time.Duration
+	Retention     time.Duration
+
+	mutex    sync.Mutex
+	loaded   bool
+	jobs     map[string]*Job
+	handlers map[string]Handler
+	wake     chan struct{}
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// NewQueue creates a Queue checkpointed to a Store
+func NewQueue(store Store, log logr.Logger) *Queue {
+	return &Queue{
+		Store:         store,
+		Log:           log,
+		MaxAttempts:   DefaultMaxAttempts,
+		RetryInterval: DefaultRetryInterval,
+		Retention:     DefaultRetention,
+		jobs:          map[string]*Job{},
+		handlers:      map[string]Handler{},
+		wake:          make(chan struct{}, 1),
+		now:           time.Now,
+	}
+}
+
+// GenerateID returns the ID of a job from its kind and the resources it operates on. IDs are used as ConfigMap keys
+func GenerateID(kind string, parts ...string) string {
+	return strings.ToLower(strings.Join(append([]string{kind}, parts...), "."))
+}
+
+// Register sets the handler running jobs of a kind
+func (q *Queue) Register(kind string, handler Handler) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.handlers[kind] = handler
+}
+
+// Enqueue adds a job to the queue and returns its state. Enqueuing a job already queued or running returns it unchanged.
+// A finished job is started again: a failed job resumes from its checkpoint and is returned as failed to report the failure
+func (q *Queue) Enqueue(ctx context.Context, job Job) (Job, error) {
+
+	if errs := validation.IsConfigMapKey(job.ID); len(errs) > 0 {
+		return Job{}, fmt.Errorf("invalid job ID '%s': %s", job.ID, strings.Join(errs, ", "))
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := q.load(ctx); err != nil {
+		return Job{}, err
+	}
+
+	now := q.now()
+
+	existing, found := q.jobs[job.ID]
+
+	if found && !existing.IsFinished() {
+		return *existing, nil
+	}
+
+	job.State = PendingState
+	job.Checkpoint = ""
+	job.Attempts = 0
+	job.Message = ""
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.NotBefore = now
+
+	reported := job
+
+	if found && existing.State == FailedState {
+		reported = *existing
+		job.Checkpoint = existing.Checkpoint
+	}
+
+	q.jobs[job.ID] = &job
+
+	if err := q.save(ctx); err != nil {
+		return Job{}, err
+	}
+
+	q.notify()
+
+	return reported, nil
+}
+
+// Get returns the state of a job
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	job, found := q.jobs[id]
+
+	if !found {
+		return Job{}, false
+	}
+
+	return *job, true
+}
+
+// Start implements manager.Runnable. Jobs interrupted by a restart are run again from their checkpoint
+func (q *Queue) Start(ctx context.Context) error {
+
+	ticker := time.NewTicker(q.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			job, handler, err := q.next(ctx)
+
+			if err != nil {
+				q.Log.Error(err, "Failed to process jobs")
+				break
+			}
+
+			if job == nil {
+				break
+			}
+
+			q.run(ctx, *job, handler)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// next marks the next job due as running and returns it along with its handler. Finished jobs past their retention are pruned
+func (q *Queue) next(ctx context.Context) (*Job, Handler, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if err := q.load(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	now := q.now()
+	pruned := false
+
+	for id, job := range q.jobs {
+		if job.IsFinished() && now.Sub(job.UpdatedAt) > q.Retention {
+			delete(q.jobs, id)
+			pruned = true
+		}
+	}
+
+	due := []*Job{}
+
+	for _, job := range q.jobs {
+		if job.State == PendingState && !job.NotBefore.After(now) {
+			due = append(due, job)
+		}
+	}
+
+	if len(due) == 0 {
+		if pruned {
+			return nil, nil, q.save(ctx)
+		}
+		return nil, nil, nil
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].CreatedAt.Equal(due[j].CreatedAt) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+
+	job := due[0]
+	job.State = RunningState
+	job.Attempts++
+	job.UpdatedAt = now
+
+	if err := q.save(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return job, q.handlers[job.Kind], nil
+}
+
+// run runs a job and records its outcome. Failed attempts are retried with a growing delay until MaxAttempts is reached
+func (q *Queue) run(ctx context.Context, job Job, handler Handler) {
+
+	log := q.Log.WithValues("Job", job.ID, "Attempt", job.Attempts)
+	log.Info("Running job")
+
+	var err error
+
+	if handler == nil {
+		err = fmt.Errorf("no handler registered for jobs of kind '%s'", job.Kind)
+	} else {
+		err = handler(ctx, job, func(progress string) error {
+			return q.update(ctx, job.ID, func(j *Job) {
+				j.Checkpoint = progress
+			})
+		})
+	}
+
+	// Jobs interrupted by shutdown are resumed on the next start without consuming an attempt
+	if ctx.Err() != nil {
+		return
+	}
+
+	if errors.Is(err, ErrPending) {
+		log.Info("Job is waiting on pending work")
+	} else if err != nil {
+		log.Error(err, "Job failed")
+	} else {
+		log.Info("Job succeeded")
+	}
+
+	if updateErr := q.update(ctx, job.ID, func(j *Job) {
+		switch {
+		case err == nil:
+			j.State = SucceededState
+			j.Message = ""
+		case errors.Is(err, ErrPending):
+			j.State = PendingState
+			j.Attempts--
+			j.NotBefore = q.now().Add(q.RetryInterval)
+		case handler == nil || j.Attempts >= q.MaxAttempts:
+			j.State = FailedState
+			j.Message = err.Error()
+		default:
+			j.State = PendingState
+			j.Message = err.Error()
+			j.NotBefore = q.now().Add(q.RetryInterval * time.Duration(j.Attempts))
+		}
+	}); updateErr != nil {
+		log.Error(updateErr, "Failed to record job outcome")
+	}
+}
+
+// update modifies a job and checkpoints the queue
+func (q *Queue) update(ctx context.Context, id string, mutate func(job *Job)) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	job, found := q.jobs[id]
+
+	if !found {
+		return fmt.Errorf("job '%s' not found", id)
+	}
+
+	mutate(job)
+	job.UpdatedAt = q.now()
+
+	return q.save(ctx)
+}
+
+// notify wakes up the queue without blocking
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// load reads the jobs from the checkpoint once. Jobs found running were interrupted and are queued again. Must be called with the lock held
+func (q *Queue) load(ctx context.Context) error {
+
+	if q.loaded {
+		return nil
+	}
+
+	data, err := q.Store.Load(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	jobs, err := DecodeJobs(data)
+
+	if err != nil {
+		return err
+	}
+
+	for id, job := range jobs {
+		if job.State == RunningState {
+			job.State = PendingState
+		}
+		q.jobs[id] = job
+	}
+
+	q.loaded = true
+
+	return nil
+}
+
+// save writes the jobs to the checkpoint. Must be called with the lock held
+func (q *Queue) save(ctx context.Context) error {
+
+	data, err := EncodeJobs(q.jobs)
+
+	if err != nil {
+		return err
+	}
+
+	return q.Store.Save(ctx, data)
+}
+
+// EncodeJobs returns the ConfigMap data checkpointing jobs
+func EncodeJobs(jobs map[string]*Job) (map[string]string, error) {
+
+	data := map[string]string{}
+
+	for id, job := range jobs {
+
+		encoded, err := json.Marshal(job)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data[id] = string(encoded)
+	}
+
+	return data, nil
+}
+
+// DecodeJobs returns the jobs checkpointed to ConfigMap data
+func DecodeJobs(data map[string]string) (map[string]*Job, error) {
+
+	jobs := map[string]*Job{}
+
+	for id, encoded := range data {
+
+		job := &Job{}
+
+		if err := json.Unmarshal([]byte(encoded), job); err != nil {
+			return nil, fmt.Errorf("invalid job '%s': %w", id, err)
+		}
+
+		jobs[id] = job
+	}
+
+	return jobs, nil
+}
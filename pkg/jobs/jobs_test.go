@@ -0,0 +1,220 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type memoryStore struct {
+	data map[string]string
+}
+
+func (s *memoryStore) Load(ctx context.Context) (map[string]string, error) {
+	return s.data, nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, data map[string]string) error {
+	s.data = data
+	return nil
+}
+
+func newTestQueue(data map[string]string) *Queue {
+	return NewQueue(&memoryStore{data: data}, log.Log)
+}
+
+func TestRun(t *testing.T) {
+
+	cases := []struct {
+		results            []error
+		expectedState      State
+		expectedAttempts   int
+		expectedCheckpoint string
+	}{
+		{
+			results:            []error{nil},
+			expectedState:      SucceededState,
+			expectedAttempts:   1,
+			expectedCheckpoint: "1",
+		},
+		{
+			results:            []error{fmt.Errorf("unreachable")},
+			expectedState:      PendingState,
+			expectedAttempts:   1,
+			expectedCheckpoint: "1",
+		},
+		{
+			results:            []error{fmt.Errorf("unreachable"), nil},
+			expectedState:      SucceededState,
+			expectedAttempts:   2,
+			expectedCheckpoint: "2",
+		},
+		{
+			results:            []error{ErrPending, ErrPending, nil},
+			expectedState:      SucceededState,
+			expectedAttempts:   1,
+			expectedCheckpoint: "3",
+		},
+		{
+			results:            []error{fmt.Errorf("1"), fmt.Errorf("2"), fmt.Errorf("3"), fmt.Errorf("4"), fmt.Errorf("5")},
+			expectedState:      FailedState,
+			expectedAttempts:   DefaultMaxAttempts,
+			expectedCheckpoint: "5",
+		},
+	}
+
+	for i, c := range cases {
+
+		queue := newTestQueue(nil)
+
+		now := time.Now()
+		queue.now = func() time.Time { return now }
+
+		attempt := 0
+
+		queue.Register("Test", func(ctx context.Context, job Job, checkpoint func(progress string) error) error {
+			attempt++
+
+			if err := checkpoint(fmt.Sprint(attempt)); err != nil {
+				return err
+			}
+
+			return c.results[attempt-1]
+		})
+
+		if _, err := queue.Enqueue(context.TODO(), Job{ID: "test.job", Kind: "Test"}); err != nil {
+			t.Fatalf("Test case %d failed to enqueue job: %v", i, err)
+		}
+
+		for range c.results {
+			job, handler, err := queue.next(context.TODO())
+
+			if err != nil || job == nil {
+				t.Fatalf("Test case %d did not find a job due: %v", i, err)
+			}
+
+			queue.run(context.TODO(), *job, handler)
+
+			now = now.Add(time.Hour)
+		}
+
+		job, _ := queue.Get("test.job")
+
+		if job.State != c.expectedState || job.Attempts != c.expectedAttempts || job.Checkpoint != c.expectedCheckpoint {
+			t.Errorf("Test case %d did not match\nExpected: %s %d %s\nActual: %s %d %s", i, c.expectedState, c.expectedAttempts, c.expectedCheckpoint, job.State, job.Attempts, job.Checkpoint)
+		}
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+
+	cases := []struct {
+		existing           *Job
+		expectedReported   State
+		expectedState      State
+		expectedCheckpoint string
+	}{
+		{
+			existing:         nil,
+			expectedReported: PendingState,
+			expectedState:    PendingState,
+		},
+		{
+			existing:           &Job{ID: "test.job", Kind: "Test", State: RunningState, Checkpoint: "repository"},
+			expectedReported:   RunningState,
+			expectedState:      RunningState,
+			expectedCheckpoint: "repository",
+		},
+		{
+			existing:           &Job{ID: "test.job", Kind: "Test", State: FailedState, Checkpoint: "repository", Attempts: DefaultMaxAttempts},
+			expectedReported:   FailedState,
+			expectedState:      PendingState,
+			expectedCheckpoint: "repository",
+		},
+		{
+			existing:         &Job{ID: "test.job", Kind: "Test", State: SucceededState, Checkpoint: "repository"},
+			expectedReported: PendingState,
+			expectedState:    PendingState,
+		},
+	}
+
+	for i, c := range cases {
+
+		queue := newTestQueue(nil)
+		queue.loaded = true
+
+		if c.existing != nil {
+			existing := *c.existing
+			queue.jobs[existing.ID] = &existing
+		}
+
+		reported, err := queue.Enqueue(context.TODO(), Job{ID: "test.job", Kind: "Test"})
+
+		if err != nil {
+			t.Fatalf("Test case %d failed to enqueue job: %v", i, err)
+		}
+
+		job, _ := queue.Get("test.job")
+
+		if reported.State != c.expectedReported || job.State != c.expectedState || job.Checkpoint != c.expectedCheckpoint {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %s\nActual: %s %s %s", i, c.expectedReported, c.expectedState, c.expectedCheckpoint, reported.State, job.State, job.Checkpoint)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+
+	running, _ := EncodeJobs(map[string]*Job{
+		"test.running":   {ID: "test.running", Kind: "Test", State: RunningState, Checkpoint: "repository"},
+		"test.succeeded": {ID: "test.succeeded", Kind: "Test", State: SucceededState},
+	})
+
+	queue := newTestQueue(running)
+
+	if err := queue.load(context.TODO()); err != nil {
+		t.Fatalf("Failed to load jobs: %v", err)
+	}
+
+	expected := map[string]State{"test.running": PendingState, "test.succeeded": SucceededState}
+	actual := map[string]State{}
+
+	for id, job := range queue.jobs {
+		actual[id] = job.State
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Test case did not match\nExpected: %v\nActual: %v", expected, actual)
+	}
+}
+
+func TestGenerateID(t *testing.T) {
+
+	cases := []struct {
+		kind     string
+		parts    []string
+		expected string
+	}{
+		{
+			kind:     "GrantReadAccess",
+			parts:    []string{"openshift_demo", "reader"},
+			expected: "grantreadaccess.openshift_demo.reader",
+		},
+		{
+			kind:     "Test",
+			expected: "test",
+		},
+	}
+
+	for i, c := range cases {
+
+		id := GenerateID(c.kind, c.parts...)
+
+		if id != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s", i, c.expected, id)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package manageroptions
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// EnvPrefix prefixes the environment variables setting flags, such as QUAY_BRIDGE_OPERATOR_SYNC_PERIOD for --sync-period
+	EnvPrefix = "QUAY_BRIDGE_OPERATOR_"
+
+	// DefaultWebhookPort is the port admission webhooks are served on
+	DefaultWebhookPort = 9443
+
+	// DefaultQPS and DefaultBurst raise the rate of requests to the API server above the defaults of controller-runtime, as
+	// resyncs of large clusters are otherwise throttled by the client
+	DefaultQPS   = 50
+	DefaultBurst = 100
+	// DefaultMinQPS is the rate the adaptive rate limiter never goes below
+	DefaultMinQPS = 5
+)
+
+// Options tunes the controller-runtime manager and its client to the API server. Zero values keep the defaults of
+// controller-runtime
+type Options struct {
+	// WebhookPort is the port admission webhooks are served on
+	WebhookPort int
+	// WebhookCertDir is the directory containing the serving certificate of the webhooks, overriding WEBHOOK_CERT_DIR
+	WebhookCertDir string
+	// SyncPeriod is the minimum interval at which watched resources are reconciled
+	SyncPeriod time.Duration
+	// QPS is the maximum sustained rate of requests to the API server
+	QPS float64
+	// Burst is the maximum burst of requests to the API server
+	Burst int
+	// AdaptiveThrottling reduces the rate of requests when the API server throttles them
+	AdaptiveThrottling bool
+	// MinQPS is the rate adaptive throttling never goes below
+	MinQPS float64
+}
+
+// NewOptions returns Options populated with defaults
+func NewOptions() Options {
+	return Options{
+		WebhookPort:        DefaultWebhookPort,
+		QPS:                DefaultQPS,
+		Burst:              DefaultBurst,
+		AdaptiveThrottling: true,
+		MinQPS:             DefaultMinQPS,
+	}
+}
+
+// BindFlags binds the Options to flags in the provided FlagSet
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.IntVar(&o.WebhookPort, "webhook-port", o.WebhookPort, "The port admission webhooks are served on.")
+	fs.StringVar(&o.WebhookCertDir, "webhook-cert-dir", o.WebhookCertDir, "The directory containing the serving certificate of the webhooks. Defaults to the WEBHOOK_CERT_DIR environment variable or /apiserver.local.config/certificates.")
+	fs.DurationVar(&o.SyncPeriod, "sync-period", o.SyncPeriod, "Minimum interval at which watched resources are reconciled, such as 1h. Defaults to 10h.")
+	fs.Float64Var(&o.QPS, "kube-api-qps", o.QPS, "Maximum sustained requests per second to the API server.")
+	fs.IntVar(&o.Burst, "kube-api-burst", o.Burst, "Maximum burst of requests to the API server.")
+	fs.BoolVar(&o.AdaptiveThrottling, "kube-api-adaptive-throttling", o.AdaptiveThrottling, "Halve the rate of requests to the API server when it responds with 429 Too Many Requests, such as when API Priority and Fairness throttles the operator, and increase it back while requests are accepted.")
+	fs.Float64Var(&o.MinQPS, "kube-api-min-qps", o.MinQPS, "Requests per second to the API server adaptive throttling never goes below.")
+}
+
+// Validate checks the Options
+func (o *Options) Validate() error {
+
+	if o.WebhookPort <= 0 || o.WebhookPort > 65535 {
+		return fmt.Errorf("invalid --webhook-port %d", o.WebhookPort)
+	}
+
+	if o.SyncPeriod < 0 {
+		return fmt.Errorf("invalid --sync-period %s, must not be negative", o.SyncPeriod)
+	}
+
+	if o.QPS < 0 || o.Burst < 0 || o.MinQPS < 0 {
+		return fmt.Errorf("invalid --kube-api-qps %v, --kube-api-burst %d or --kube-api-min-qps %v, must not be negative", o.QPS, o.Burst, o.MinQPS)
+	}
+
+	if o.AdaptiveThrottling && o.QPS <= 0 {
+		return fmt.Errorf("--kube-api-adaptive-throttling requires a positive --kube-api-qps")
+	}
+
+	return nil
+}
+
+// Apply sets the Options on the options of the manager and the configuration of its client
+func (o *Options) Apply(options *ctrl.Options, config *rest.Config) {
+
+	options.Port = o.WebhookPort
+
+	if o.SyncPeriod > 0 {
+		syncPeriod := o.SyncPeriod
+		options.SyncPeriod = &syncPeriod
+	}
+
+	if o.QPS > 0 {
+		config.QPS = float32(o.QPS)
+	}
+
+	if o.Burst > 0 {
+		config.Burst = o.Burst
+	}
+
+	if o.AdaptiveThrottling {
+		limiter := NewAdaptiveRateLimiter(config.QPS, config.Burst, float32(o.MinQPS))
+		config.RateLimiter = limiter
+		config.Wrap(limiter.WrapTransport)
+	}
+}
+
+// SetFlagsFromEnvironment sets the flags not set on the command line from the environment variables named after them
+func SetFlagsFromEnvironment(fs *flag.FlagSet, lookupEnv func(string) (string, bool)) error {
+
+	set := map[string]bool{}
+
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+
+	fs.VisitAll(func(f *flag.Flag) {
+
+		if set[f.Name] || err != nil {
+			return
+		}
+
+		if value, ok := lookupEnv(EnvName(f.Name)); ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %s: %v", EnvName(f.Name), setErr)
+			}
+		}
+	})
+
+	return err
+}
+
+// EnvName returns the name of the environment variable setting a flag
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flagName))
+}
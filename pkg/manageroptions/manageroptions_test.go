@@ -0,0 +1,56 @@
+package manageroptions
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetFlagsFromEnvironment(t *testing.T) {
+
+	cases := []struct {
+		args          []string
+		env           map[string]string
+		expected      Options
+		expectedError bool
+	}{
+		{
+			expected: Options{WebhookPort: DefaultWebhookPort, QPS: DefaultQPS, Burst: DefaultBurst, AdaptiveThrottling: true, MinQPS: DefaultMinQPS},
+		},
+		{
+			env:      map[string]string{"QUAY_BRIDGE_OPERATOR_SYNC_PERIOD": "1h", "QUAY_BRIDGE_OPERATOR_KUBE_API_QPS": "200", "QUAY_BRIDGE_OPERATOR_KUBE_API_ADAPTIVE_THROTTLING": "false", "QUAY_BRIDGE_OPERATOR_WEBHOOK_CERT_DIR": "/certs"},
+			expected: Options{WebhookPort: DefaultWebhookPort, WebhookCertDir: "/certs", SyncPeriod: time.Hour, QPS: 200, Burst: DefaultBurst, MinQPS: DefaultMinQPS},
+		},
+		{
+			args:     []string{"--kube-api-burst=500", "--webhook-port=8443"},
+			env:      map[string]string{"QUAY_BRIDGE_OPERATOR_KUBE_API_BURST": "60"},
+			expected: Options{WebhookPort: 8443, QPS: DefaultQPS, Burst: 500, AdaptiveThrottling: true, MinQPS: DefaultMinQPS},
+		},
+		{
+			env:           map[string]string{"QUAY_BRIDGE_OPERATOR_SYNC_PERIOD": "hourly"},
+			expected:      Options{WebhookPort: DefaultWebhookPort, QPS: DefaultQPS, Burst: DefaultBurst, AdaptiveThrottling: true, MinQPS: DefaultMinQPS},
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		options := NewOptions()
+		options.BindFlags(fs)
+
+		err := fs.Parse(c.args)
+
+		if err == nil {
+			err = SetFlagsFromEnvironment(fs, func(name string) (string, bool) {
+				value, ok := c.env[name]
+				return value, ok
+			})
+		}
+
+		if c.expectedError != (err != nil) || !reflect.DeepEqual(options, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %+v %v\nActual: %+v %v", i, c.expected, c.expectedError, options, err)
+		}
+	}
+}
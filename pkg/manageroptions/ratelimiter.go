@@ -0,0 +1,184 @@
+package manageroptions
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/quay/quay-bridge-operator/pkg/metrics"
+)
+
+const (
+	// backoffInterval is the minimum interval between reductions of the rate, so that the requests throttled at the previous
+	// rate reduce it once
+	backoffInterval = time.Second
+	// recoveryInterval is the interval between increases of the rate while requests are accepted
+	recoveryInterval = 10 * time.Second
+	// recoverySteps is the number of increases recovering the maximum rate from the minimum rate
+	recoverySteps = 10
+)
+
+// AdaptiveRateLimiter limits the rate of requests to the API server, halving the rate when the API server rejects requests with
+// 429 Too Many Requests, as API Priority and Fairness does when the priority level of the operator is saturated, and increasing
+// it back additively while requests are accepted. The limiter is shared by every client created from the configuration
+type AdaptiveRateLimiter struct {
+	maxQPS float32
+	minQPS float32
+	burst  int
+
+	mutex   sync.Mutex
+	limiter flowcontrol.RateLimiter
+	qps     float32
+	changed time.Time
+	now     func() time.Time
+}
+
+// NewAdaptiveRateLimiter returns an AdaptiveRateLimiter allowing up to qps requests per second, never going below minQPS
+func NewAdaptiveRateLimiter(qps float32, burst int, minQPS float32) *AdaptiveRateLimiter {
+
+	if minQPS <= 0 || minQPS > qps {
+		minQPS = qps
+	}
+
+	l := &AdaptiveRateLimiter{maxQPS: qps, minQPS: minQPS, burst: burst}
+	l.setQPS(qps, time.Time{})
+
+	return l
+}
+
+// TryAccept implements flowcontrol.RateLimiter
+func (l *AdaptiveRateLimiter) TryAccept() bool {
+	return l.current().TryAccept()
+}
+
+// Accept implements flowcontrol.RateLimiter
+func (l *AdaptiveRateLimiter) Accept() {
+	l.current().Accept()
+}
+
+// Stop implements flowcontrol.RateLimiter
+func (l *AdaptiveRateLimiter) Stop() {
+	l.current().Stop()
+}
+
+// QPS implements flowcontrol.RateLimiter
+func (l *AdaptiveRateLimiter) QPS() float32 {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.qps
+}
+
+// Wait implements flowcontrol.RateLimiter
+func (l *AdaptiveRateLimiter) Wait(ctx context.Context) error {
+	return l.current().Wait(ctx)
+}
+
+// Throttled halves the rate after the API server rejected a request with 429 Too Many Requests
+func (l *AdaptiveRateLimiter) Throttled() {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	metrics.KubeAPIThrottledRequests.Inc()
+
+	now := l.currentTime()
+
+	if l.qps <= l.minQPS || now.Sub(l.changed) < backoffInterval {
+		return
+	}
+
+	qps := l.qps / 2
+
+	if qps < l.minQPS {
+		qps = l.minQPS
+	}
+
+	l.setQPS(qps, now)
+}
+
+// Accepted increases the rate after the API server accepted a request, at most once per recovery interval
+func (l *AdaptiveRateLimiter) Accepted() {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.currentTime()
+
+	if l.qps >= l.maxQPS || now.Sub(l.changed) < recoveryInterval {
+		return
+	}
+
+	qps := l.qps + (l.maxQPS-l.minQPS)/recoverySteps
+
+	if qps > l.maxQPS {
+		qps = l.maxQPS
+	}
+
+	l.setQPS(qps, now)
+}
+
+// WrapTransport observes the responses of the API server to adapt the rate
+func (l *AdaptiveRateLimiter) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &observingRoundTripper{limiter: l, delegate: rt}
+}
+
+func (l *AdaptiveRateLimiter) current() flowcontrol.RateLimiter {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.limiter
+}
+
+// setQPS replaces the token bucket, scaling the burst along with the rate
+func (l *AdaptiveRateLimiter) setQPS(qps float32, now time.Time) {
+
+	burst := int(float32(l.burst) * qps / l.maxQPS)
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.qps = qps
+	l.changed = now
+	l.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+
+	metrics.KubeAPIQPS.Set(float64(qps))
+}
+
+func (l *AdaptiveRateLimiter) currentTime() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+
+	return time.Now()
+}
+
+type observingRoundTripper struct {
+	limiter  *AdaptiveRateLimiter
+	delegate http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *observingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	resp, err := rt.delegate.RoundTrip(req)
+
+	if err != nil {
+		return resp, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		rt.limiter.Throttled()
+	case resp.StatusCode < http.StatusInternalServerError:
+		rt.limiter.Accepted()
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,62 @@
+package manageroptions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveRateLimiter(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		statusCodes []int
+		interval    time.Duration
+		expected    float32
+	}{
+		{statusCodes: []int{http.StatusOK}, interval: time.Second, expected: 50},
+		{statusCodes: []int{http.StatusTooManyRequests}, interval: time.Second, expected: 25},
+		// Requests throttled at the previous rate reduce it once
+		{statusCodes: []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, interval: time.Millisecond, expected: 25},
+		{statusCodes: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests}, interval: time.Second, expected: 5},
+		{statusCodes: []int{http.StatusTooManyRequests, http.StatusOK}, interval: time.Second, expected: 25},
+		{statusCodes: []int{http.StatusTooManyRequests, http.StatusOK, http.StatusOK}, interval: 10 * time.Second, expected: 34},
+		{statusCodes: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusInternalServerError}, interval: 10 * time.Second, expected: 25},
+	}
+
+	for i, c := range cases {
+
+		current := now
+		statusCodes := c.statusCodes
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statusCodes[0])
+			statusCodes = statusCodes[1:]
+		}))
+
+		limiter := NewAdaptiveRateLimiter(50, 100, 5)
+		limiter.now = func() time.Time { return current }
+		client := &http.Client{Transport: limiter.WrapTransport(http.DefaultTransport)}
+
+		for range c.statusCodes {
+
+			current = current.Add(c.interval)
+
+			resp, err := client.Get(server.URL)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp.Body.Close()
+		}
+
+		server.Close()
+
+		if limiter.QPS() != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, limiter.QPS())
+		}
+	}
+}
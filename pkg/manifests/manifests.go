@@ -0,0 +1,528 @@
+package manifests
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/rbac"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// NamePrefix prefixes the names of the rendered resources, matching the kustomize deployment
+	NamePrefix = "quay-bridge-operator-"
+
+	// DefaultNamespace is the namespace the operator is deployed to unless configured otherwise
+	DefaultNamespace = "quay-bridge-operator-system"
+	// DefaultImage is the image of the operator unless configured otherwise
+	DefaultImage = "quay.io/quay/quay-bridge-operator:latest"
+	// DefaultMetricsProxyImage is the image of the proxy protecting the metrics endpoint unless configured otherwise
+	DefaultMetricsProxyImage = "quay.io/coreos/kube-rbac-proxy:v0.5.0"
+
+	// serviceCAServingCertAnnotation requests a serving certificate from the OpenShift service CA operator
+	serviceCAServingCertAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	// serviceCAInjectBundleAnnotation requests the injection of the OpenShift service CA bundle
+	serviceCAInjectBundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+
+	controlPlaneLabel = "control-plane"
+	controlPlaneValue = "controller-manager"
+
+	webhookServerPort = 9443
+	metricsProxyPort  = 8443
+)
+
+// Values configure the deployment of the operator
+type Values struct {
+	// Namespace the operator is deployed to
+	Namespace string `json:"namespace,omitempty"`
+	// Image of the operator
+	Image string `json:"image,omitempty"`
+	// ImagePullPolicy of the operator and metrics proxy containers
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Replicas of the operator Deployment
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources of the operator container
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// NodeSelector of the operator pods
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the operator pods
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// MetricsProxyImage is the image of the proxy authorizing access to the metrics endpoint
+	MetricsProxyImage string `json:"metricsProxyImage,omitempty"`
+	// Webhook configures the admission webhooks
+	Webhook WebhookValues `json:"webhook,omitempty"`
+}
+
+// WebhookValues configure the admission webhooks of the operator
+type WebhookValues struct {
+	// Enabled registers the admission webhooks. Defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+	// CertSecretName is the Secret holding the serving certificate of the webhook
+	CertSecretName string `json:"certSecretName,omitempty"`
+	// ServiceCA requests the serving certificate and CA bundle from the OpenShift service CA operator. Defaults to true
+	ServiceCA *bool `json:"serviceCA,omitempty"`
+	// CABundle is the PEM encoded CA bundle verifying the serving certificate when the service CA is not used
+	CABundle string `json:"caBundle,omitempty"`
+}
+
+// Options describe the operator being deployed
+type Options struct {
+	Values
+
+	// Args are the arguments passed to the operator
+	Args []string
+	// Features are the enabled features determining the permissions of the operator
+	Features rbac.Features
+	// ImpersonationClusterRole is the ClusterRole bound to impersonated service accounts when impersonation is enabled
+	ImpersonationClusterRole string
+	// ProjectAnnotation registers the webhook annotating the namespaces of requested projects
+	ProjectAnnotation bool
+	// PullSecretInjection registers the webhook adding the pull secret of synchronized namespaces to Pods pulling images from Quay
+	PullSecretInjection bool
+	// SecretProtectionWebhook registers the webhook warning users changing or deleting the robot account Secrets managed by the operator
+	SecretProtectionWebhook bool
+	// ManageWebhookCertificates lets the operator issue the serving certificate of the webhooks and inject its CA bundle
+	ManageWebhookCertificates bool
+
+	// CRD, WebhookConfigurations and NamespaceWriterRole are the generated manifests included in the rendered manifests
+	CRD                   []byte
+	WebhookConfigurations []byte
+	NamespaceWriterRole   []byte
+	// PullSecretInjectionWebhookConfiguration is the webhook configuration only included when pull secret injection is enabled
+	PullSecretInjectionWebhookConfiguration []byte
+	// SecretProtectionWebhookConfiguration is the webhook configuration only included when the secret protection webhook is enabled
+	SecretProtectionWebhookConfiguration []byte
+}
+
+// ReadValues reads Values from a YAML file. Defaults are used when no path is provided
+func ReadValues(path string) (Values, error) {
+
+	values := Values{}
+
+	if path == "" {
+		return values, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return values, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, &values); err != nil {
+		return values, fmt.Errorf("invalid values %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// withDefaults returns the Values with defaults applied to unset fields
+func (v Values) withDefaults() Values {
+
+	if v.Namespace == "" {
+		v.Namespace = DefaultNamespace
+	}
+
+	if v.Image == "" {
+		v.Image = DefaultImage
+	}
+
+	if v.MetricsProxyImage == "" {
+		v.MetricsProxyImage = DefaultMetricsProxyImage
+	}
+
+	if v.Replicas == nil {
+		replicas := int32(1)
+		v.Replicas = &replicas
+	}
+
+	if v.Resources == nil {
+		v.Resources = &corev1.ResourceRequirements{
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("400Mi")},
+		}
+	}
+
+	if v.Webhook.Enabled == nil {
+		enabled := true
+		v.Webhook.Enabled = &enabled
+	}
+
+	if v.Webhook.ServiceCA == nil {
+		serviceCA := v.Webhook.CABundle == ""
+		v.Webhook.ServiceCA = &serviceCA
+	}
+
+	if v.Webhook.CertSecretName == "" {
+		v.Webhook.CertSecretName = NamePrefix + "webhook-server-cert"
+	}
+
+	return v
+}
+
+// Render returns the resources deploying the operator: its CustomResourceDefinition, service account, the RBAC required by the
+// enabled features, the Deployment, the metrics Service and, when enabled, the admission webhooks
+func Render(options Options) ([]client.Object, error) {
+
+	values := options.Values.withDefaults()
+	namespace := values.Namespace
+	args := options.Args
+
+	// The operator issues the certificate in place of the service CA
+	if options.ManageWebhookCertificates {
+		serviceCA := false
+		values.Webhook.ServiceCA = &serviceCA
+		values.Webhook.CABundle = ""
+		args = append(args, "--webhook-cert-secret="+values.Webhook.CertSecretName, "--webhook-service="+NamePrefix+"webhook-service")
+	}
+	serviceAccountName := NamePrefix + "controller-manager"
+
+	crd := &unstructured.Unstructured{}
+
+	if err := yaml.Unmarshal(options.CRD, &crd.Object); err != nil {
+		return nil, fmt.Errorf("invalid CustomResourceDefinition: %w", err)
+	}
+
+	objs := []client.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{controlPlaneLabel: controlPlaneValue}},
+		},
+		crd,
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: namespace},
+		},
+	}
+
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serviceAccountName, Namespace: namespace}}
+
+	// Roles are bound under the name of the role they grant
+	for _, obj := range rbac.Manifests(NamePrefix+"manager-role", options.Features) {
+
+		objs = append(objs, obj)
+
+		switch role := obj.(type) {
+		case *rbacv1.ClusterRole:
+			objs = append(objs, clusterRoleBinding(NamePrefix+"manager-rolebinding", role.Name, subjects))
+		case *rbacv1.Role:
+			objs = append(objs, roleBinding(NamePrefix+"manager-rolebinding", role.Namespace, "Role", role.Name, subjects))
+		}
+	}
+
+	objs = append(objs,
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: NamePrefix + "leader-election-role", Namespace: namespace},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"", "coordination.k8s.io"}, Resources: []string{"configmaps", "leases"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+				{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch"}},
+			},
+		},
+		roleBinding(NamePrefix+"leader-election-rolebinding", namespace, "Role", NamePrefix+"leader-election-role", subjects),
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: NamePrefix + "proxy-role"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"tokenreviews"}, Verbs: []string{"create"}},
+				{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"subjectaccessreviews"}, Verbs: []string{"create"}},
+			},
+		},
+		clusterRoleBinding(NamePrefix+"proxy-rolebinding", NamePrefix+"proxy-role", subjects),
+	)
+
+	if options.Features.Impersonation {
+
+		namespaceWriterRole := &rbacv1.ClusterRole{}
+
+		if err := yaml.Unmarshal(options.NamespaceWriterRole, namespaceWriterRole); err != nil {
+			return nil, fmt.Errorf("invalid namespace writer ClusterRole: %w", err)
+		}
+
+		namespaceWriterRole.Name = options.ImpersonationClusterRole
+		objs = append(objs, namespaceWriterRole)
+	}
+
+	objs = append(objs, deployment(values, args, options.ManageWebhookCertificates, serviceAccountName), metricsService(namespace))
+
+	if !*values.Webhook.Enabled {
+		return objs, nil
+	}
+
+	webhookObjs, err := webhooks(values, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(objs, webhookObjs...), nil
+}
+
+// deployment renders the Deployment of the operator, exposing metrics through the proxy. The serving certificate of the webhooks is
+// mounted from its Secret, or written to an emptyDir volume when issued by the operator
+func deployment(values Values, args []string, manageWebhookCertificates bool, serviceAccountName string) *appsv1.Deployment {
+
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	terminationGracePeriodSeconds := int64(40)
+	labels := map[string]string{controlPlaneLabel: controlPlaneValue}
+
+	manager := corev1.Container{
+		Name:            "manager",
+		Image:           values.Image,
+		ImagePullPolicy: values.ImagePullPolicy,
+		Command:         []string{"/manager"},
+		Args:            append([]string{"--health-probe-bind-address=:8081", "--metrics-bind-address=127.0.0.1:8080"}, args...),
+		SecurityContext: &corev1.SecurityContext{AllowPrivilegeEscalation: &allowPrivilegeEscalation},
+		LivenessProbe: &corev1.Probe{
+			Handler:             corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8081)}},
+			InitialDelaySeconds: 15,
+			PeriodSeconds:       20,
+		},
+		ReadinessProbe: &corev1.Probe{
+			Handler:             corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/readyz", Port: intstr.FromInt(8081)}},
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       10,
+		},
+		Resources: *values.Resources,
+	}
+
+	podSpec := corev1.PodSpec{
+		SecurityContext:               &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+		ServiceAccountName:            serviceAccountName,
+		TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+		NodeSelector:                  values.NodeSelector,
+		Tolerations:                   values.Tolerations,
+	}
+
+	if *values.Webhook.Enabled {
+
+		defaultMode := int32(420)
+
+		manager.Ports = []corev1.ContainerPort{{Name: "webhook-server", ContainerPort: webhookServerPort, Protocol: corev1.ProtocolTCP}}
+		manager.VolumeMounts = []corev1.VolumeMount{{Name: "apiservice-cert", MountPath: constants.DefaultWebhookCertDir, ReadOnly: !manageWebhookCertificates}}
+
+		if manageWebhookCertificates {
+			podSpec.Volumes = []corev1.Volume{{Name: "apiservice-cert", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		} else {
+			podSpec.Volumes = []corev1.Volume{{
+				Name: "apiservice-cert",
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+					SecretName:  values.Webhook.CertSecretName,
+					DefaultMode: &defaultMode,
+					Items: []corev1.KeyToPath{
+						{Key: corev1.TLSPrivateKeyKey, Path: constants.WebhookKeyName},
+						{Key: corev1.TLSCertKey, Path: constants.WebhookCertName},
+					},
+				}},
+			}}
+		}
+	} else {
+		manager.Env = []corev1.EnvVar{{Name: constants.DisableWebhookEnvVar, Value: "true"}}
+	}
+
+	podSpec.Containers = []corev1.Container{
+		{
+			Name:            "kube-rbac-proxy",
+			Image:           values.MetricsProxyImage,
+			ImagePullPolicy: values.ImagePullPolicy,
+			Args:            []string{fmt.Sprintf("--secure-listen-address=0.0.0.0:%d", metricsProxyPort), "--upstream=http://127.0.0.1:8080/", "--logtostderr=true", "--v=10"},
+			Ports:           []corev1.ContainerPort{{Name: "https", ContainerPort: metricsProxyPort}},
+		},
+		manager,
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: NamePrefix + "controller-manager", Namespace: values.Namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: values.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+// metricsService renders the Service exposing the metrics proxy, matched by the ServiceMonitor provisioned by the operator
+func metricsService(namespace string) *corev1.Service {
+
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: NamePrefix + "controller-manager-metrics-service", Namespace: namespace, Labels: map[string]string{controlPlaneLabel: controlPlaneValue}},
+		Spec: corev1.ServiceSpec{
+			Ports:    []corev1.ServicePort{{Name: "https", Port: metricsProxyPort, TargetPort: intstr.FromString("https")}},
+			Selector: map[string]string{controlPlaneLabel: controlPlaneValue},
+		},
+	}
+}
+
+// webhooks renders the webhook Service and the webhook configurations routing admission requests to it
+func webhooks(values Values, options Options) ([]client.Object, error) {
+
+	serviceName := NamePrefix + "webhook-service"
+
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: values.Namespace},
+		Spec: corev1.ServiceSpec{
+			Ports:    []corev1.ServicePort{{Port: 443, TargetPort: intstr.FromInt(webhookServerPort)}},
+			Selector: map[string]string{controlPlaneLabel: controlPlaneValue},
+		},
+	}
+
+	annotations := map[string]string{}
+
+	if *values.Webhook.ServiceCA {
+		service.Annotations = map[string]string{serviceCAServingCertAnnotation: values.Webhook.CertSecretName}
+		annotations[serviceCAInjectBundleAnnotation] = "true"
+	}
+
+	caBundle := []byte{}
+
+	if values.Webhook.CABundle != "" {
+
+		decoded, err := base64.StdEncoding.DecodeString(values.Webhook.CABundle)
+
+		if err != nil {
+			// The bundle may be provided as PEM rather than base64 encoded PEM
+			decoded = []byte(values.Webhook.CABundle)
+		}
+
+		caBundle = decoded
+	}
+
+	objs := []client.Object{service}
+
+	documents := strings.Split(string(options.WebhookConfigurations), "\n---\n")
+
+	// Only the Pods of synchronized namespaces are sent to the webhook, which is only registered when requested
+	if options.PullSecretInjection {
+		documents = append(documents, string(options.PullSecretInjectionWebhookConfiguration))
+	}
+
+	// Only managed Secrets are sent to the webhook, which is only registered when requested
+	if options.SecretProtectionWebhook {
+		documents = append(documents, string(options.SecretProtectionWebhookConfiguration))
+	}
+
+	for _, document := range documents {
+
+		if strings.TrimSpace(strings.TrimPrefix(document, "---")) == "" {
+			continue
+		}
+
+		typeMeta := metav1.TypeMeta{}
+
+		if err := yaml.Unmarshal([]byte(document), &typeMeta); err != nil {
+			return nil, fmt.Errorf("invalid webhook configuration: %w", err)
+		}
+
+		switch typeMeta.Kind {
+		case "MutatingWebhookConfiguration":
+
+			configuration := &admissionregistrationv1.MutatingWebhookConfiguration{}
+
+			if err := yaml.Unmarshal([]byte(document), configuration); err != nil {
+				return nil, fmt.Errorf("invalid webhook configuration: %w", err)
+			}
+
+			webhooks := []admissionregistrationv1.MutatingWebhook{}
+
+			for _, webhook := range configuration.Webhooks {
+
+				// Namespaces are only annotated when requested
+				if webhook.ClientConfig.Service != nil && webhook.ClientConfig.Service.Path != nil && *webhook.ClientConfig.Service.Path == "/mutate-namespace" && !options.ProjectAnnotation {
+					continue
+				}
+
+				// The admission of Builds may wait for the provisioning of their namespace and the creation of their repository
+				if webhook.ClientConfig.Service != nil && webhook.ClientConfig.Service.Path != nil && *webhook.ClientConfig.Service.Path == "/admissionwebhook" {
+					timeoutSeconds := int32(constants.BuildAdmissionTimeoutSeconds)
+					webhook.TimeoutSeconds = &timeoutSeconds
+				}
+
+				webhook.ClientConfig = clientConfig(webhook.ClientConfig, serviceName, values.Namespace, caBundle)
+				webhooks = append(webhooks, webhook)
+			}
+
+			configuration.Name = NamePrefix + configuration.Name
+			configuration.Annotations = annotations
+			configuration.CreationTimestamp = metav1.Time{}
+			configuration.Webhooks = webhooks
+			objs = append(objs, configuration)
+
+		case "ValidatingWebhookConfiguration":
+
+			configuration := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+
+			if err := yaml.Unmarshal([]byte(document), configuration); err != nil {
+				return nil, fmt.Errorf("invalid webhook configuration: %w", err)
+			}
+
+			webhooks := []admissionregistrationv1.ValidatingWebhook{}
+
+			for _, webhook := range configuration.Webhooks {
+				webhook.ClientConfig = clientConfig(webhook.ClientConfig, serviceName, values.Namespace, caBundle)
+				webhooks = append(webhooks, webhook)
+			}
+
+			configuration.Name = NamePrefix + configuration.Name
+			configuration.Annotations = annotations
+			configuration.CreationTimestamp = metav1.Time{}
+			configuration.Webhooks = webhooks
+			objs = append(objs, configuration)
+		}
+	}
+
+	return objs, nil
+}
+
+// clientConfig routes a webhook to the webhook Service of the operator
+func clientConfig(config admissionregistrationv1.WebhookClientConfig, serviceName string, namespace string, caBundle []byte) admissionregistrationv1.WebhookClientConfig {
+
+	if config.Service != nil {
+		service := *config.Service
+		service.Name = serviceName
+		service.Namespace = namespace
+		config.Service = &service
+	}
+
+	if len(caBundle) > 0 {
+		config.CABundle = caBundle
+	}
+
+	return config
+}
+
+func clusterRoleBinding(name string, clusterRoleName string, subjects []rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: clusterRoleName},
+		Subjects:   subjects,
+	}
+}
+
+func roleBinding(name string, namespace string, roleKind string, roleName string, subjects []rbacv1.Subject) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: roleKind, Name: roleName},
+		Subjects:   subjects,
+	}
+}
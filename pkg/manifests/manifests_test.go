@@ -0,0 +1,134 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/quay/quay-bridge-operator/config"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/rbac"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestRender(t *testing.T) {
+
+	disabled := false
+
+	cases := []struct {
+		options                   Options
+		expectedWebhooks          int
+		expectedServiceCA         bool
+		expectedNamespaceWriter   bool
+		expectedWebhookDisableEnv bool
+	}{
+		{
+			options:           Options{},
+			expectedWebhooks:  2,
+			expectedServiceCA: true,
+		},
+		{
+			options:           Options{ProjectAnnotation: true},
+			expectedWebhooks:  3,
+			expectedServiceCA: true,
+		},
+		{
+			options:           Options{PullSecretInjection: true},
+			expectedWebhooks:  3,
+			expectedServiceCA: true,
+		},
+		{
+			options:           Options{SecretProtectionWebhook: true},
+			expectedWebhooks:  3,
+			expectedServiceCA: true,
+		},
+		{
+			options:          Options{Values: Values{Webhook: WebhookValues{CABundle: "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t"}}},
+			expectedWebhooks: 2,
+		},
+		{
+			options:                   Options{Values: Values{Webhook: WebhookValues{Enabled: &disabled}}},
+			expectedWebhookDisableEnv: true,
+		},
+		{
+			options:          Options{ManageWebhookCertificates: true, Values: Values{Webhook: WebhookValues{CABundle: "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t"}}},
+			expectedWebhooks: 2,
+		},
+		{
+			options:                 Options{Features: rbac.Features{Impersonation: true}, ImpersonationClusterRole: "namespace-writer"},
+			expectedWebhooks:        2,
+			expectedServiceCA:       true,
+			expectedNamespaceWriter: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		c.options.CRD = config.QuayIntegrationCRD
+		c.options.WebhookConfigurations = config.WebhookConfigurations
+		c.options.PullSecretInjectionWebhookConfiguration = config.PullSecretInjectionWebhookConfiguration
+		c.options.SecretProtectionWebhookConfiguration = config.SecretProtectionWebhookConfiguration
+		c.options.NamespaceWriterRole = config.NamespaceWriterRole
+
+		objs, err := Render(c.options)
+
+		if err != nil {
+			t.Errorf("Test case %d failed to render: %v", i, err)
+			continue
+		}
+
+		webhooks := 0
+		serviceCA := false
+		namespaceWriter := false
+		webhookDisableEnv := false
+
+		for _, obj := range objs {
+
+			switch o := obj.(type) {
+			case *admissionregistrationv1.MutatingWebhookConfiguration:
+				webhooks += len(o.Webhooks)
+				_, serviceCA = o.Annotations[serviceCAInjectBundleAnnotation]
+
+				for _, webhook := range o.Webhooks {
+					if *webhook.ClientConfig.Service.Path == "/mutate-pod" && (webhook.NamespaceSelector == nil || webhook.TimeoutSeconds == nil) {
+						t.Errorf("Test case %d did not restrict webhook %s to synchronized namespaces", i, webhook.Name)
+					}
+					if *webhook.ClientConfig.Service.Path == "/admissionwebhook" && (webhook.TimeoutSeconds == nil || *webhook.TimeoutSeconds != constants.BuildAdmissionTimeoutSeconds) {
+						t.Errorf("Test case %d did not set the timeout of webhook %s", i, webhook.Name)
+					}
+					if webhook.ClientConfig.Service.Namespace != DefaultNamespace {
+						t.Errorf("Test case %d routed webhook %s to namespace %s", i, webhook.Name, webhook.ClientConfig.Service.Namespace)
+					}
+					if (c.options.Webhook.CABundle != "" && !c.options.ManageWebhookCertificates) != (len(webhook.ClientConfig.CABundle) > 0) {
+						t.Errorf("Test case %d did not match the CA bundle of webhook %s", i, webhook.Name)
+					}
+				}
+			case *admissionregistrationv1.ValidatingWebhookConfiguration:
+				webhooks += len(o.Webhooks)
+
+				for _, webhook := range o.Webhooks {
+					if *webhook.ClientConfig.Service.Path == "/validate-secret" && (webhook.ObjectSelector == nil || webhook.TimeoutSeconds == nil) {
+						t.Errorf("Test case %d did not restrict webhook %s to managed Secrets", i, webhook.Name)
+					}
+				}
+			case *rbacv1.ClusterRole:
+				namespaceWriter = namespaceWriter || o.Name == c.options.ImpersonationClusterRole
+			case *appsv1.Deployment:
+				for _, container := range o.Spec.Template.Spec.Containers {
+					for _, env := range container.Env {
+						webhookDisableEnv = webhookDisableEnv || env.Name == "DISABLE_WEBHOOK"
+					}
+				}
+			case *corev1.Namespace:
+				if o.Name != DefaultNamespace {
+					t.Errorf("Test case %d did not match\nExpected namespace: %s\nActual: %s", i, DefaultNamespace, o.Name)
+				}
+			}
+		}
+
+		if webhooks != c.expectedWebhooks || serviceCA != c.expectedServiceCA || namespaceWriter != c.expectedNamespaceWriter || webhookDisableEnv != c.expectedWebhookDisableEnv {
+			t.Errorf("Test case %d did not match\nExpected: %d %v %v %v\nActual: %d %v %v %v", i, c.expectedWebhooks, c.expectedServiceCA, c.expectedNamespaceWriter, c.expectedWebhookDisableEnv, webhooks, serviceCA, namespaceWriter, webhookDisableEnv)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+)
+
+const (
+	metricsNamespace = "quay_bridge_operator"
+)
+
+var (
+	// QuayAPIRequests counts the requests made against the Quay API partitioned by HTTP method and response code
+	QuayAPIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "quay_api_requests_total",
+		Help:      "Number of requests made against the Quay API, partitioned by method and response code. Requests which did not receive a response are reported with code \"error\".",
+	}, []string{"method", "code"})
+
+	// QuayUp reports whether the Quay API was reachable on the most recent request
+	QuayUp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "quay_up",
+		Help:      "Whether the Quay API was reachable on the most recent request (1 for reachable, 0 otherwise).",
+	})
+
+	// NamespacesOutOfSync reports the number of managed namespaces whose most recent synchronization failed
+	NamespacesOutOfSync = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "namespaces_out_of_sync",
+		Help:      "Number of managed namespaces whose most recent synchronization with Quay failed.",
+	})
+
+	// NamespaceLastSyncTimestamp reports the time at which each managed namespace was last synchronized successfully
+	NamespaceLastSyncTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "namespace_last_sync_timestamp_seconds",
+		Help:      "Unix timestamp of the most recent successful synchronization of a managed namespace with Quay.",
+	}, []string{"managed_namespace"})
+
+	// MissedBuildMutations counts the Builds admitted without being redirected to Quay partitioned by whether they were recovered
+	MissedBuildMutations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "missed_build_mutations_total",
+		Help:      "Number of Builds admitted without being redirected to Quay, partitioned by outcome. Builds replaced by a clone are reported with outcome \"recovered\" and Builds which had already started with outcome \"not_redirected\".",
+	}, []string{"outcome"})
+
+	// NamespacesPendingSync reports the number of managed namespaces which have not been synchronized since they were queued or last failed
+	NamespacesPendingSync = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "namespaces_pending_sync",
+		Help:      "Number of managed namespaces which have not been synchronized successfully since they were queued or since their synchronization last failed.",
+	}, func() float64 {
+		count, _ := unsyncedNamespaceBacklog(time.Now())
+		return float64(count)
+	})
+
+	// OldestUnsyncedNamespaceAge reports how long the namespace waiting the longest for a successful synchronization has been waiting
+	OldestUnsyncedNamespaceAge = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "oldest_unsynced_namespace_age_seconds",
+		Help:      "Time in seconds the namespace waiting the longest for a successful synchronization has been waiting, or 0 when every managed namespace is in sync.",
+	}, func() float64 {
+		_, age := unsyncedNamespaceBacklog(time.Now())
+		return age.Seconds()
+	})
+
+	// OrganizationRepositories reports the number of repositories of the organization of each managed namespace
+	OrganizationRepositories = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "organization_repositories",
+		Help:      "Number of repositories of the Quay organization of a managed namespace.",
+	}, []string{"managed_namespace", "organization"})
+
+	// OrganizationStorageBytes reports the storage consumed by the organization of each managed namespace
+	OrganizationStorageBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "organization_storage_bytes",
+		Help:      "Storage in bytes consumed by the Quay organization of a managed namespace. Only reported when quota management is enabled in Quay.",
+	}, []string{"managed_namespace", "organization"})
+
+	// RepositoryPulls reports the number of pulls of each repository of a managed namespace during the statistics window
+	RepositoryPulls = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "repository_pulls",
+		Help:      "Number of pulls of a repository of the Quay organization of a managed namespace during the statistics window.",
+	}, []string{"managed_namespace", "repository"})
+
+	// RepositoryPushes reports the number of pushes to each repository of a managed namespace during the statistics window
+	RepositoryPushes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "repository_pushes",
+		Help:      "Number of pushes to a repository of the Quay organization of a managed namespace during the statistics window.",
+	}, []string{"managed_namespace", "repository"})
+
+	// RepositoryLastPullTimestamp reports the day of the most recent pull of each repository of a managed namespace
+	RepositoryLastPullTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "repository_last_pull_timestamp_seconds",
+		Help:      "Unix timestamp of the day of the most recent pull of a repository of the Quay organization of a managed namespace. Only reported when the repository was pulled during the statistics window.",
+	}, []string{"managed_namespace", "repository"})
+
+	// OutOfBandChanges counts the notable actions performed on managed organizations outside of the operator partitioned by reason
+	OutOfBandChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "out_of_band_changes_total",
+		Help:      "Number of notable actions found in the audit logs of managed Quay organizations which were not performed by the operator, partitioned by the reason of the event reporting them.",
+	}, []string{"reason"})
+
+	// NamespaceProvisioningDuration observes the time from the creation of a namespace to its first successful synchronization
+	NamespaceProvisioningDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "namespace_provisioning_duration_seconds",
+		Help:      "Time in seconds from the creation of a namespace until its Quay organization, robot accounts and pull secrets bound to its service accounts are ready. Only observed for namespaces created while the operator is running.",
+		Buckets:   []float64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600},
+	})
+
+	// ImageReferences reports the number of image references of each managed namespace partitioned by the registry hosting them
+	ImageReferences = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "image_references",
+		Help:      "Number of image references of the Pods, BuildConfigs and ImageStreams of a managed namespace partitioned by the registry hosting them, either quay, internal, allowed or unmanaged.",
+	}, []string{"managed_namespace", "registry"})
+
+	// KubeAPIThrottledRequests counts the requests to the API server rejected with 429 Too Many Requests
+	KubeAPIThrottledRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "kube_api_throttled_requests_total",
+		Help:      "Number of requests to the API server rejected with 429 Too Many Requests, such as by API Priority and Fairness.",
+	})
+
+	// KubeAPIQPS reports the rate of requests to the API server allowed by the adaptive rate limiter
+	KubeAPIQPS = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "kube_api_qps",
+		Help:      "Requests per second to the API server currently allowed by the adaptive rate limiter.",
+	})
+
+	webhookCertificateExpiryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "webhook_certificate_expiry_timestamp_seconds"),
+		"Unix timestamp at which the certificate served by the admission webhook expires.",
+		nil, nil,
+	)
+
+	outOfSyncNamespaces     = map[string]struct{}{}
+	syncedNamespaces        = map[string]struct{}{}
+	unsyncedSince           = map[string]time.Time{}
+	outOfSyncNamespacesLock sync.Mutex
+
+	// provisionedNamespaces holds the namespaces whose provisioning duration has been observed
+	provisionedNamespaces     = map[string]struct{}{}
+	provisionedNamespacesLock sync.Mutex
+
+	// usageOrganizations holds the organization reported for each namespace, letting stale usage series be removed
+	usageOrganizations     = map[string]string{}
+	usageOrganizationsLock sync.Mutex
+
+	// statsRepositories holds the repositories reported for each namespace, letting stale statistics series be removed
+	statsRepositories     = map[string]map[string]struct{}{}
+	statsRepositoriesLock sync.Mutex
+
+	// quayReachability holds the outcome of the most recent request against the Quay API
+	quayReachability int32 = quayReachabilityUnknown
+)
+
+const (
+	quayReachabilityUnknown int32 = iota
+	quayReachabilityUp
+	quayReachabilityDown
+)
+
+func init() {
+	metrics.Registry.MustRegister(QuayAPIRequests, QuayUp, NamespacesOutOfSync, NamespaceLastSyncTimestamp, MissedBuildMutations, NamespacesPendingSync, OldestUnsyncedNamespaceAge,
+		OrganizationRepositories, OrganizationStorageBytes, RepositoryPulls, RepositoryPushes, RepositoryLastPullTimestamp,
+		OutOfBandChanges, NamespaceProvisioningDuration, ImageReferences, KubeAPIThrottledRequests, KubeAPIQPS)
+}
+
+// RecordQuayAPIRequest records the outcome of a request against the Quay API. A statusCode of 0 indicates that no response was received
+func RecordQuayAPIRequest(method string, statusCode int) {
+
+	if statusCode == 0 {
+		QuayAPIRequests.WithLabelValues(method, "error").Inc()
+		setQuayUp(false)
+		return
+	}
+
+	QuayAPIRequests.WithLabelValues(method, strconv.Itoa(statusCode)).Inc()
+
+	switch statusCode {
+	case 502, 503, 504:
+		setQuayUp(false)
+	default:
+		setQuayUp(true)
+	}
+}
+
+func setQuayUp(up bool) {
+
+	if up {
+		QuayUp.Set(1)
+		atomic.StoreInt32(&quayReachability, quayReachabilityUp)
+		return
+	}
+
+	QuayUp.Set(0)
+	atomic.StoreInt32(&quayReachability, quayReachabilityDown)
+}
+
+// IsQuayReachable reports whether the most recent request against the Quay API succeeded. observed is false until a request has been made
+func IsQuayReachable() (reachable bool, observed bool) {
+
+	switch atomic.LoadInt32(&quayReachability) {
+	case quayReachabilityUp:
+		return true, true
+	case quayReachabilityDown:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// GetWorkQueueDepth returns the total number of items waiting in the named controller work queues
+func GetWorkQueueDepth(names ...string) (int, error) {
+
+	metricFamilies, err := metrics.Registry.Gather()
+
+	if err != nil {
+		return 0, err
+	}
+
+	depth := 0
+
+	for _, metricFamily := range metricFamilies {
+
+		if metricFamily.GetName() != "workqueue_depth" {
+			continue
+		}
+
+		for _, metric := range metricFamily.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" && containsString(names, label.GetValue()) {
+					depth += int(metric.GetGauge().GetValue())
+				}
+			}
+		}
+	}
+
+	return depth, nil
+}
+
+func containsString(values []string, value string) bool {
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RecordNamespaceSyncSuccess marks a namespace as in sync with Quay
+func RecordNamespaceSyncSuccess(namespace string) {
+	markNamespaceInSync(namespace, true)
+	NamespaceLastSyncTimestamp.WithLabelValues(namespace).SetToCurrentTime()
+}
+
+// RecordNamespaceSyncFailure marks a namespace as out of sync with Quay
+func RecordNamespaceSyncFailure(namespace string) {
+	outOfSyncNamespacesLock.Lock()
+	defer outOfSyncNamespacesLock.Unlock()
+
+	outOfSyncNamespaces[namespace] = struct{}{}
+	delete(syncedNamespaces, namespace)
+	NamespacesOutOfSync.Set(float64(len(outOfSyncNamespaces)))
+
+	if _, found := unsyncedSince[namespace]; !found {
+		unsyncedSince[namespace] = time.Now()
+	}
+}
+
+// RecordNamespaceQueued marks a namespace as waiting for synchronization unless it is already in sync or waiting
+func RecordNamespaceQueued(namespace string) {
+	outOfSyncNamespacesLock.Lock()
+	defer outOfSyncNamespacesLock.Unlock()
+
+	if _, synced := syncedNamespaces[namespace]; synced {
+		return
+	}
+
+	if _, found := unsyncedSince[namespace]; !found {
+		unsyncedSince[namespace] = time.Now()
+	}
+}
+
+// unsyncedNamespaceBacklog returns the number of namespaces waiting for a successful synchronization and the age of the oldest
+func unsyncedNamespaceBacklog(now time.Time) (int, time.Duration) {
+	outOfSyncNamespacesLock.Lock()
+	defer outOfSyncNamespacesLock.Unlock()
+
+	oldest := time.Duration(0)
+
+	for _, since := range unsyncedSince {
+		if age := now.Sub(since); age > oldest {
+			oldest = age
+		}
+	}
+
+	return len(unsyncedSince), oldest
+}
+
+// RecordNamespaceProvisioned observes the provisioning duration of a namespace. Returns false when the namespace has already
+// been observed
+func RecordNamespaceProvisioned(namespace string, duration time.Duration) bool {
+	provisionedNamespacesLock.Lock()
+	defer provisionedNamespacesLock.Unlock()
+
+	if _, found := provisionedNamespaces[namespace]; found {
+		return false
+	}
+
+	provisionedNamespaces[namespace] = struct{}{}
+	NamespaceProvisioningDuration.Observe(duration.Seconds())
+
+	return true
+}
+
+// ForgetNamespace removes all tracked state for a namespace that is no longer managed
+func ForgetNamespace(namespace string) {
+	markNamespaceInSync(namespace, false)
+	forgetNamespaceProvisioned(namespace)
+	NamespaceLastSyncTimestamp.DeleteLabelValues(namespace)
+	ForgetOrganizationUsage(namespace)
+	ForgetRepositoryStats(namespace, nil)
+}
+
+func forgetNamespaceProvisioned(namespace string) {
+	provisionedNamespacesLock.Lock()
+	defer provisionedNamespacesLock.Unlock()
+
+	delete(provisionedNamespaces, namespace)
+}
+
+// RecordOrganizationUsage reports the usage of the organization of a namespace. Storage is only reported when known
+func RecordOrganizationUsage(namespace string, organization string, repositories int, storageBytes int64, storageReported bool) {
+	usageOrganizationsLock.Lock()
+	defer usageOrganizationsLock.Unlock()
+
+	if previous, found := usageOrganizations[namespace]; found && previous != organization {
+		OrganizationRepositories.DeleteLabelValues(namespace, previous)
+		OrganizationStorageBytes.DeleteLabelValues(namespace, previous)
+	}
+
+	usageOrganizations[namespace] = organization
+
+	OrganizationRepositories.WithLabelValues(namespace, organization).Set(float64(repositories))
+
+	if storageReported {
+		OrganizationStorageBytes.WithLabelValues(namespace, organization).Set(float64(storageBytes))
+	} else {
+		OrganizationStorageBytes.DeleteLabelValues(namespace, organization)
+	}
+}
+
+// ForgetOrganizationUsage removes the usage reported for a namespace
+func ForgetOrganizationUsage(namespace string) {
+	usageOrganizationsLock.Lock()
+	defer usageOrganizationsLock.Unlock()
+
+	if organization, found := usageOrganizations[namespace]; found {
+		OrganizationRepositories.DeleteLabelValues(namespace, organization)
+		OrganizationStorageBytes.DeleteLabelValues(namespace, organization)
+		delete(usageOrganizations, namespace)
+	}
+}
+
+// GetUsageReportedNamespaces returns the namespaces whose usage is reported
+func GetUsageReportedNamespaces() []string {
+	usageOrganizationsLock.Lock()
+	defer usageOrganizationsLock.Unlock()
+
+	namespaces := []string{}
+
+	for namespace := range usageOrganizations {
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces
+}
+
+// RecordImageReferences reports the number of image references of each namespace by registry, replacing the namespaces
+// previously reported
+func RecordImageReferences(counts map[string]map[string]int) {
+
+	ImageReferences.Reset()
+
+	for namespace, registries := range counts {
+		for registry, count := range registries {
+			ImageReferences.WithLabelValues(namespace, registry).Set(float64(count))
+		}
+	}
+}
+
+// RecordRepositoryStats reports the pulls and pushes of a repository of a namespace. The last pull is only reported when known
+func RecordRepositoryStats(namespace string, repository string, pulls int64, pushes int64, lastPull time.Time) {
+	statsRepositoriesLock.Lock()
+	defer statsRepositoriesLock.Unlock()
+
+	if _, found := statsRepositories[namespace]; !found {
+		statsRepositories[namespace] = map[string]struct{}{}
+	}
+
+	statsRepositories[namespace][repository] = struct{}{}
+
+	RepositoryPulls.WithLabelValues(namespace, repository).Set(float64(pulls))
+	RepositoryPushes.WithLabelValues(namespace, repository).Set(float64(pushes))
+
+	if lastPull.IsZero() {
+		RepositoryLastPullTimestamp.DeleteLabelValues(namespace, repository)
+	} else {
+		RepositoryLastPullTimestamp.WithLabelValues(namespace, repository).Set(float64(lastPull.Unix()))
+	}
+}
+
+// ForgetRepositoryStats removes the statistics reported for the repositories of a namespace which are not kept. All
+// repositories are removed when keep is nil
+func ForgetRepositoryStats(namespace string, keep map[string]bool) {
+	statsRepositoriesLock.Lock()
+	defer statsRepositoriesLock.Unlock()
+
+	for repository := range statsRepositories[namespace] {
+		if keep[repository] {
+			continue
+		}
+
+		RepositoryPulls.DeleteLabelValues(namespace, repository)
+		RepositoryPushes.DeleteLabelValues(namespace, repository)
+		RepositoryLastPullTimestamp.DeleteLabelValues(namespace, repository)
+		delete(statsRepositories[namespace], repository)
+	}
+
+	if len(statsRepositories[namespace]) == 0 {
+		delete(statsRepositories, namespace)
+	}
+}
+
+// GetStatsReportedNamespaces returns the namespaces whose repository statistics are reported
+func GetStatsReportedNamespaces() []string {
+	statsRepositoriesLock.Lock()
+	defer statsRepositoriesLock.Unlock()
+
+	namespaces := []string{}
+
+	for namespace := range statsRepositories {
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces
+}
+
+// GetSyncedNamespaceCount returns the number of managed namespaces whose most recent synchronization succeeded
+func GetSyncedNamespaceCount() int {
+	outOfSyncNamespacesLock.Lock()
+	defer outOfSyncNamespacesLock.Unlock()
+
+	return len(syncedNamespaces)
+}
+
+func markNamespaceInSync(namespace string, synced bool) {
+	outOfSyncNamespacesLock.Lock()
+	defer outOfSyncNamespacesLock.Unlock()
+
+	delete(outOfSyncNamespaces, namespace)
+	delete(unsyncedSince, namespace)
+	NamespacesOutOfSync.Set(float64(len(outOfSyncNamespaces)))
+
+	if synced {
+		syncedNamespaces[namespace] = struct{}{}
+	} else {
+		delete(syncedNamespaces, namespace)
+	}
+}
+
+// RegisterWebhookCertificateCollector registers a collector reporting the expiry of the certificate served by the webhook
+func RegisterWebhookCertificateCollector(certPath string) error {
+	return metrics.Registry.Register(&webhookCertificateCollector{certPath: certPath})
+}
+
+// webhookCertificateCollector reads the webhook certificate on every scrape so that rotated certificates are picked up
+type webhookCertificateCollector struct {
+	certPath string
+}
+
+func (c *webhookCertificateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- webhookCertificateExpiryDesc
+}
+
+func (c *webhookCertificateCollector) Collect(ch chan<- prometheus.Metric) {
+
+	certificate, err := utils.ReadCertificate(c.certPath)
+
+	if err != nil {
+		logging.Log.Error(err, "Unable to read webhook certificate", "Path", c.certPath)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(webhookCertificateExpiryDesc, prometheus.GaugeValue, float64(certificate.NotAfter.Unix()))
+}
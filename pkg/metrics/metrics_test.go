@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnsyncedNamespaceBacklog(t *testing.T) {
+
+	queued := RecordNamespaceQueued
+	failed := RecordNamespaceSyncFailure
+	synced := RecordNamespaceSyncSuccess
+	forgotten := ForgetNamespace
+
+	type step struct {
+		record    func(namespace string)
+		namespace string
+	}
+
+	cases := []struct {
+		steps         []step
+		expectedCount int
+	}{
+		{
+			steps:         []step{{queued, "a"}, {queued, "b"}},
+			expectedCount: 2,
+		},
+		{
+			steps:         []step{{queued, "a"}, {queued, "b"}, {synced, "a"}},
+			expectedCount: 1,
+		},
+		{
+			steps:         []step{{failed, "a"}, {queued, "a"}},
+			expectedCount: 1,
+		},
+		{
+			steps:         []step{{synced, "a"}, {queued, "a"}},
+			expectedCount: 0,
+		},
+		{
+			steps:         []step{{synced, "a"}, {failed, "a"}},
+			expectedCount: 1,
+		},
+		{
+			steps:         []step{{queued, "a"}, {queued, "b"}, {forgotten, "a"}, {forgotten, "b"}},
+			expectedCount: 0,
+		},
+	}
+
+	for i, c := range cases {
+
+		ForgetNamespace("a")
+		ForgetNamespace("b")
+
+		for _, s := range c.steps {
+			s.record(s.namespace)
+		}
+
+		count, age := unsyncedNamespaceBacklog(time.Now().Add(time.Minute))
+
+		if count != c.expectedCount || (count > 0) != (age >= time.Minute) {
+			t.Errorf("Test case %d did not match\nExpected: %d\nActual: %d %s", i, c.expectedCount, count, age)
+		}
+	}
+}
+
+func TestRecordNamespaceProvisioned(t *testing.T) {
+
+	ForgetNamespace("a")
+
+	if !RecordNamespaceProvisioned("a", time.Second) {
+		t.Errorf("Expected the provisioning of namespace a to be observed")
+	}
+
+	if RecordNamespaceProvisioned("a", time.Minute) {
+		t.Errorf("Expected the provisioning of namespace a to be observed once")
+	}
+
+	ForgetNamespace("a")
+
+	if !RecordNamespaceProvisioned("a", time.Second) {
+		t.Errorf("Expected the provisioning of a forgotten namespace to be observed again")
+	}
+}
@@ -0,0 +1,167 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/quay/quay-bridge-operator/pkg/jobs"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap recording the migrations applied
+	DefaultConfigMapName = "quay-bridge-operator-migrations"
+	// OperatorVersionKey records the version of the operator which last applied migrations
+	OperatorVersionKey = "operatorVersion"
+	// DefaultRetryInterval is the delay before a failed migration is run again
+	DefaultRetryInterval = time.Minute
+)
+
+// Migration is a one-time change of the resources managed by previous versions of the operator. Migrations are run again when
+// interrupted by a restart of the operator and must be idempotent
+type Migration struct {
+	// ID identifies the migration within the marker and never changes once released. IDs are ConfigMap keys
+	ID          string
+	Description string
+	// Run applies the migration. The reader is not backed by the cache, which may not hold every resource to migrate
+	Run func(ctx context.Context, c client.Client, reader client.Reader) error
+}
+
+// Runner applies the migrations not yet recorded in the marker in order once the operator is elected, recording each migration
+// as it completes. A failed migration is run again after RetryInterval and holds back the migrations following it
+type Runner struct {
+	Store  jobs.Store
+	Client client.Client
+	Reader client.Reader
+	Log    logr.Logger
+
+	Migrations    []Migration
+	Version       string
+	RetryInterval time.Duration
+
+	mutex    sync.RWMutex
+	complete bool
+	message  string
+}
+
+// NewRunner creates a Runner applying the migrations of this version of the operator
+func NewRunner(store jobs.Store, c client.Client, reader client.Reader, version string, log logr.Logger) *Runner {
+	return &Runner{
+		Store:         store,
+		Client:        c,
+		Reader:        reader,
+		Log:           log,
+		Migrations:    Migrations,
+		Version:       version,
+		RetryInterval: DefaultRetryInterval,
+		message:       "Migrations have not been applied yet",
+	}
+}
+
+// Complete returns whether every migration has been applied, along with the reason they are not
+func (r *Runner) Complete() (bool, string) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.complete, r.message
+}
+
+// Start implements manager.Runnable. Migrations are applied until every migration completes
+func (r *Runner) Start(ctx context.Context) error {
+
+	for _, migration := range r.Migrations {
+		if errs := validation.IsConfigMapKey(migration.ID); len(errs) > 0 {
+			return fmt.Errorf("invalid migration ID '%s': %v", migration.ID, errs)
+		}
+	}
+
+	for {
+
+		err := r.apply(ctx)
+
+		r.mutex.Lock()
+
+		if err == nil {
+			r.complete = true
+			r.message = "Migrations have been applied"
+		} else {
+			r.message = fmt.Sprintf("Migrations are pending: %v", err)
+		}
+
+		r.mutex.Unlock()
+
+		if err == nil {
+			return nil
+		}
+
+		r.Log.Error(err, "Failed to apply migrations", "Retry", r.RetryInterval)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.RetryInterval):
+		}
+	}
+}
+
+// apply runs the migrations missing from the marker in order, recording each one once applied
+func (r *Runner) apply(ctx context.Context) error {
+
+	data, err := r.Store.Load(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	marker := map[string]string{}
+
+	for key, value := range data {
+		marker[key] = value
+	}
+
+	for _, migration := range Pending(r.Migrations, marker) {
+
+		r.Log.Info("Applying migration", "Migration", migration.ID, "Description", migration.Description)
+
+		if err := migration.Run(ctx, r.Client, r.Reader); err != nil {
+			return fmt.Errorf("migration %s failed: %w", migration.ID, err)
+		}
+
+		marker[migration.ID] = time.Now().UTC().Format(time.RFC3339)
+		marker[OperatorVersionKey] = r.Version
+
+		if err := r.Store.Save(ctx, marker); err != nil {
+			return err
+		}
+
+		r.Log.Info("Applied migration", "Migration", migration.ID)
+	}
+
+	if marker[OperatorVersionKey] != r.Version {
+
+		marker[OperatorVersionKey] = r.Version
+
+		return r.Store.Save(ctx, marker)
+	}
+
+	return nil
+}
+
+// Pending returns the migrations not recorded in the marker, in the order they are applied
+func Pending(migrations []Migration, marker map[string]string) []Migration {
+
+	pending := []Migration{}
+
+	for _, migration := range migrations {
+		if _, applied := marker[migration.ID]; !applied {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending
+}
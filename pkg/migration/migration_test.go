@@ -0,0 +1,116 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type memoryStore struct {
+	data map[string]string
+}
+
+func (s *memoryStore) Load(ctx context.Context) (map[string]string, error) {
+	return s.data, nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, data map[string]string) error {
+	s.data = data
+	return nil
+}
+
+func TestRunner(t *testing.T) {
+
+	cases := []struct {
+		marker           map[string]string
+		failures         map[string]int
+		expectedApplied  []string
+		expectedRecorded []string
+	}{
+		{
+			marker:           nil,
+			expectedApplied:  []string{"first", "second", "third"},
+			expectedRecorded: []string{"first", "second", "third"},
+		},
+		{
+			marker:           map[string]string{"first": "2026-01-01T00:00:00Z", OperatorVersionKey: "v0.0.1"},
+			expectedApplied:  []string{"second", "third"},
+			expectedRecorded: []string{"first", "second", "third"},
+		},
+		{
+			marker:           nil,
+			failures:         map[string]int{"second": 2},
+			expectedApplied:  []string{"first", "second", "second", "second", "third"},
+			expectedRecorded: []string{"first", "second", "third"},
+		},
+	}
+
+	for i, c := range cases {
+
+		applied := []string{}
+		failures := map[string]int{}
+
+		for id, count := range c.failures {
+			failures[id] = count
+		}
+
+		migrations := []Migration{}
+
+		for _, id := range []string{"first", "second", "third"} {
+			migrationID := id
+			migrations = append(migrations, Migration{ID: migrationID, Run: func(ctx context.Context, c client.Client, reader client.Reader) error {
+				applied = append(applied, migrationID)
+
+				if failures[migrationID] > 0 {
+					failures[migrationID]--
+					return fmt.Errorf("migration failed")
+				}
+
+				return nil
+			}})
+		}
+
+		store := &memoryStore{data: c.marker}
+
+		runner := NewRunner(store, nil, nil, "v1.0.0", log.Log)
+		runner.Migrations = migrations
+		runner.RetryInterval = time.Millisecond
+
+		if err := runner.Start(context.TODO()); err != nil {
+			t.Errorf("Test case %d returned an error: %v", i, err)
+		}
+
+		recorded := []string{}
+
+		for _, migration := range migrations {
+			if _, ok := store.data[migration.ID]; ok {
+				recorded = append(recorded, migration.ID)
+			}
+		}
+
+		complete, _ := runner.Complete()
+
+		if !reflect.DeepEqual(applied, c.expectedApplied) || !reflect.DeepEqual(recorded, c.expectedRecorded) || store.data[OperatorVersionKey] != "v1.0.0" || !complete {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v %s %v", i, c.expectedApplied, c.expectedRecorded, applied, recorded, store.data[OperatorVersionKey], complete)
+		}
+	}
+}
+
+func TestRunnerInvalidID(t *testing.T) {
+
+	runner := NewRunner(&memoryStore{}, nil, nil, "v1.0.0", log.Log)
+	runner.Migrations = []Migration{{ID: "invalid id"}}
+
+	if err := runner.Start(context.TODO()); err == nil {
+		t.Errorf("Expected an error for an invalid migration ID")
+	}
+
+	if complete, _ := runner.Complete(); complete {
+		t.Errorf("Expected migrations to remain incomplete")
+	}
+}
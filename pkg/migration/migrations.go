@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+)
+
+// Migrations are the migrations of this version of the operator, in the order they are applied. Migrations are appended and
+// never reordered or removed once released
+var Migrations = []Migration{
+	{
+		ID:          "label-managed-secrets",
+		Description: "Label the robot account Secrets created before Secrets were labeled as managed",
+		Run:         labelManagedSecrets,
+	},
+}
+
+// labelManagedSecrets labels the robot account Secrets of synchronized namespaces, which are otherwise left out of the label
+// scoped cache and never updated again. The hash of the Secret is recorded by the next synchronization of the namespace
+func labelManagedSecrets(ctx context.Context, c client.Client, reader client.Reader) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := reader.List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	for i := range quayIntegrations.Items {
+
+		quayIntegration := &quayIntegrations.Items[i]
+
+		namespaces, err := state.ManagedNamespaces(ctx, reader, quayIntegration)
+
+		if err != nil {
+			return err
+		}
+
+		for _, namespace := range namespaces {
+			for _, secretName := range utils.GenerateRobotAccountSecretNames(quayIntegration, namespace) {
+				if err := labelManagedSecret(ctx, c, reader, namespace, secretName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func labelManagedSecret(ctx context.Context, c client.Client, reader client.Reader, namespace string, name string) error {
+
+	secret := &corev1.Secret{}
+
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if secret.Labels[constants.ManagedSecretLabel] == "true" {
+		return nil
+	}
+
+	patch := client.MergeFrom(secret.DeepCopy())
+
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+
+	secret.Labels[constants.ManagedSecretLabel] = "true"
+
+	return c.Patch(ctx, secret, patch)
+}
@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"context"
+	_ "embed"
+	"text/template"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+)
+
+var (
+	// ServiceMonitorGVK is the GroupVersionKind of the Prometheus Operator ServiceMonitor resource
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+	// PrometheusRuleGVK is the GroupVersionKind of the Prometheus Operator PrometheusRule resource
+	PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+	// MonitoringInventory is the inventory tracking the monitoring resources, allowing resources no longer rendered to be pruned
+	MonitoringInventory = "quay-bridge-operator-monitoring"
+
+	monitoringTemplate = template.Must(template.New("monitoring").Funcs(reconcilerbase.TemplateFuncMap()).Parse(monitoringResources))
+
+	//go:embed dashboards/quay-bridge-operator.json
+	grafanaDashboard string
+)
+
+// MonitoringData is the data used to render the monitoring resources
+type MonitoringData struct {
+	Namespace string
+}
+
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// MonitoringReconciler provisions the observability resources for the operator.
+// A ServiceMonitor and PrometheusRule are provisioned when the Prometheus Operator APIs are available
+// and a Grafana dashboard ConfigMap is provisioned when requested
+type MonitoringReconciler struct {
+	ReconcilerBase     reconcilerbase.ReconcilerBase
+	Log                logr.Logger
+	ProvisionAlerting  bool
+	ProvisionDashboard bool
+}
+
+// Start implements manager.Runnable. Resources are provisioned once, retrying until successful or the manager stops
+func (m *MonitoringReconciler) Start(ctx context.Context) error {
+
+	err := wait.PollImmediateUntil(constants.RequeuePeriod, func() (bool, error) {
+
+		err := m.reconcile(ctx)
+
+		if err != nil {
+			m.Log.Error(err, "Failed to provision monitoring resources")
+			return false, nil
+		}
+
+		return true, nil
+
+	}, ctx.Done())
+
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+func (m *MonitoringReconciler) reconcile(ctx context.Context) error {
+
+	namespace, err := m.ReconcilerBase.GetOperatorNamespace()
+
+	if err != nil {
+		m.Log.Info("Unable to determine operator namespace, skipping provisioning of monitoring resources")
+		return nil
+	}
+
+	if m.ProvisionDashboard {
+
+		err = m.ReconcilerBase.ApplyResource(ctx, nil, namespace, NewGrafanaDashboardConfigMap())
+
+		if err != nil {
+			return err
+		}
+
+		m.Log.Info("Provisioned Grafana dashboard", "Namespace", namespace)
+	}
+
+	if !m.ProvisionAlerting {
+		return nil
+	}
+
+	for _, gvk := range []schema.GroupVersionKind{ServiceMonitorGVK, PrometheusRuleGVK} {
+
+		available, err := m.ReconcilerBase.IsAPIResourceAvailable(gvk)
+
+		if err != nil {
+			return err
+		}
+
+		if !available {
+			m.Log.Info("Monitoring API not available, skipping provisioning of monitoring resources", "GroupVersionKind", gvk)
+			return nil
+		}
+	}
+
+	results, err := m.ReconcilerBase.ReconcileTemplatedResources(ctx, nil, namespace, MonitoringInventory, &MonitoringData{Namespace: namespace}, monitoringTemplate)
+
+	for _, result := range reconcilerbase.FailedResults(results) {
+		m.Log.Error(result.Error, "Unable to provision monitoring resource", "Resource", result.Reference.String())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.Log.Info("Provisioned monitoring resources", "Namespace", namespace)
+
+	return nil
+}
+
+// NewGrafanaDashboardConfigMap returns a ConfigMap containing the operator dashboard, labeled for discovery by Grafana
+func NewGrafanaDashboardConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: constants.GrafanaDashboardConfigMapName,
+			Labels: map[string]string{
+				constants.GrafanaDashboardLabel: "1",
+			},
+		},
+		Data: map[string]string{
+			constants.GrafanaDashboardKey: grafanaDashboard,
+		},
+	}
+}
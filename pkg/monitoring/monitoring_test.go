@@ -0,0 +1,61 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+)
+
+func TestMonitoringTemplate(t *testing.T) {
+
+	cases := []struct {
+		name      string
+		namespace string
+		expected  []string
+	}{
+		{
+			name:      "test-monitoring-template",
+			namespace: "quay-bridge-operator",
+			expected:  []string{ServiceMonitorGVK.Kind, PrometheusRuleGVK.Kind},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			objs, err := reconcilerbase.ProcessTemplateArray(&MonitoringData{Namespace: c.namespace}, monitoringTemplate)
+
+			if err != nil {
+				t.Fatalf("Test case %d failed to process template: %v", i, err)
+			}
+
+			if len(objs) != len(c.expected) {
+				t.Fatalf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, len(c.expected), len(objs))
+			}
+
+			for j, obj := range objs {
+				if obj.GetKind() != c.expected[j] || obj.GetNamespace() != c.namespace {
+					t.Errorf("Test case %d did not match\nExpected: %s/%s\nActual: %s/%s", i, c.namespace, c.expected[j], obj.GetNamespace(), obj.GetKind())
+				}
+			}
+		})
+	}
+}
+
+func TestGrafanaDashboardConfigMap(t *testing.T) {
+
+	configMap := NewGrafanaDashboardConfigMap()
+
+	if configMap.Labels[constants.GrafanaDashboardLabel] == "" {
+		t.Errorf("Dashboard ConfigMap is missing label %s", constants.GrafanaDashboardLabel)
+	}
+
+	dashboard := map[string]interface{}{}
+
+	if err := json.Unmarshal([]byte(configMap.Data[constants.GrafanaDashboardKey]), &dashboard); err != nil {
+		t.Errorf("Dashboard is not valid JSON: %v", err)
+	}
+}
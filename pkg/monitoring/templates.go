@@ -0,0 +1,75 @@
+package monitoring
+
+const monitoringResources = `
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: quay-bridge-operator-metrics
+  namespace: {{ .Namespace }}
+  labels:
+    control-plane: controller-manager
+spec:
+  endpoints:
+  - path: /metrics
+    port: https
+    scheme: https
+    bearerTokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token
+    tlsConfig:
+      insecureSkipVerify: true
+  selector:
+    matchLabels:
+      control-plane: controller-manager
+---
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  name: quay-bridge-operator-alerts
+  namespace: {{ .Namespace }}
+  labels:
+    control-plane: controller-manager
+spec:
+  groups:
+  - name: quay-bridge-operator
+    rules:
+    - alert: QuayUnreachable
+      expr: quay_bridge_operator_quay_up{namespace="{{ .Namespace }}"} == 0
+      for: 5m
+      labels:
+        severity: critical
+      annotations:
+        summary: The Quay API is unreachable
+        description: The Quay Bridge Operator has been unable to reach the Quay API for at least 5 minutes.
+    - alert: SecretsOutOfSync
+      expr: quay_bridge_operator_namespaces_out_of_sync{namespace="{{ .Namespace }}"} > 0
+      for: 15m
+      labels:
+        severity: warning
+      annotations:
+        summary: Namespaces are out of sync with Quay
+        description: One or more namespaces have failed to synchronize organizations, robot accounts or pull secrets with Quay for at least 15 minutes.
+    - alert: WebhookCertExpiring
+      expr: (quay_bridge_operator_webhook_certificate_expiry_timestamp_seconds{namespace="{{ .Namespace }}"} - time()) < 7 * 24 * 3600
+      for: 1h
+      labels:
+        severity: warning
+      annotations:
+        summary: The Quay Bridge Operator webhook certificate is about to expire
+        description: The certificate served by the Build admission webhook expires in less than 7 days.
+    - alert: SyncBacklogHigh
+      expr: workqueue_depth{namespace="{{ .Namespace }}", name=~"namespace|build"} > 50
+      for: 15m
+      labels:
+        severity: warning
+      annotations:
+        summary: The Quay Bridge Operator synchronization backlog is high
+        description: More than 50 items have been waiting in a Quay Bridge Operator work queue for at least 15 minutes.
+    - alert: NamespaceSyncLagging
+      expr: quay_bridge_operator_oldest_unsynced_namespace_age_seconds{namespace="{{ .Namespace }}"} > 1800
+      for: 5m
+      labels:
+        severity: warning
+      annotations:
+        summary: Namespaces are waiting to be synchronized with Quay
+        description: A managed namespace has been waiting for a successful synchronization with Quay for more than 30 minutes.
+`
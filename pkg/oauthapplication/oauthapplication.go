@@ -0,0 +1,140 @@
+package oauthapplication
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+// Result is the OAuth application managed for a QuayIntegration along with the change made to it
+type Result struct {
+	Application qclient.Application
+	Created     bool
+	Rotated     bool
+}
+
+// Ensure finds the OAuth application of a QuayIntegration, by the client ID recorded in its status or otherwise by name, creating
+// it when missing and resetting its client secret once due for rotation
+func Ensure(quayClient *qclient.QuayClient, quayIntegration *quayv1.QuayIntegration, now time.Time) (Result, error) {
+
+	organization := quayIntegration.Spec.OAuthApplication.Organization
+	name := quayIntegration.GetOAuthApplicationName()
+
+	applications, _, apiErr := quayClient.GetOrganizationApplications(organization)
+
+	if apiErr.Error != nil {
+		return Result{}, fmt.Errorf("failed to list the OAuth applications of organization %s: %v", organization, apiErr.Error)
+	}
+
+	clientID := ""
+
+	if quayIntegration.Status.OAuthApplication != nil {
+		clientID = quayIntegration.Status.OAuthApplication.ClientID
+	}
+
+	application, found := find(applications, clientID, name)
+
+	if !found {
+
+		application, _, apiErr = quayClient.CreateOrganizationApplication(organization, qclient.ApplicationRequest{
+			Name:        name,
+			Description: fmt.Sprintf("Managed by the Quay Bridge Operator of cluster %s", quayIntegration.Spec.ClusterID),
+		})
+
+		if apiErr.Error != nil {
+			return Result{}, fmt.Errorf("failed to create OAuth application %s in organization %s: %v", name, organization, apiErr.Error)
+		}
+
+		return Result{Application: application, Created: true}, nil
+	}
+
+	if rotation := quayIntegration.GetOAuthApplicationRotation(); !rotation.IsZero() && !now.Before(rotation) {
+
+		application, _, apiErr = quayClient.ResetOrganizationApplicationClientSecret(organization, application.ClientID)
+
+		if apiErr.Error != nil {
+			return Result{}, fmt.Errorf("failed to reset the client secret of OAuth application %s: %v", name, apiErr.Error)
+		}
+
+		return Result{Application: application, Rotated: true}, nil
+	}
+
+	if application.ClientSecret == "" {
+		return Result{}, fmt.Errorf("quay did not return the client secret of OAuth application %s, organization %s must be administered by the user of the credentials", name, organization)
+	}
+
+	return Result{Application: application}, nil
+}
+
+// Delete deletes the OAuth application of a QuayIntegration, revoking the tokens it minted. Deleting an application which no longer
+// exists succeeds
+func Delete(quayClient *qclient.QuayClient, quayIntegration *quayv1.QuayIntegration) error {
+
+	if quayIntegration.Spec.OAuthApplication == nil || quayIntegration.Status.OAuthApplication == nil || quayIntegration.Status.OAuthApplication.ClientID == "" {
+		return nil
+	}
+
+	resp, apiErr := quayClient.DeleteOrganizationApplication(quayIntegration.Spec.OAuthApplication.Organization, quayIntegration.Status.OAuthApplication.ClientID)
+
+	if apiErr.Error != nil {
+		return apiErr.Error
+	}
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code deleting OAuth application %s: %d", quayIntegration.Status.OAuthApplication.ClientID, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ApplyCredentials stores the client credentials of an OAuth application in the data of the credentials Secret. A refresh token
+// issued to another client is removed. Returns whether the data changed
+func ApplyCredentials(data map[string][]byte, application qclient.Application) bool {
+
+	if string(data[qclient.OAuthClientIDKey]) == application.ClientID && string(data[qclient.OAuthClientSecretKey]) == application.ClientSecret {
+		return false
+	}
+
+	if string(data[qclient.OAuthClientIDKey]) != application.ClientID {
+		delete(data, qclient.OAuthRefreshTokenKey)
+	}
+
+	data[qclient.OAuthClientIDKey] = []byte(application.ClientID)
+	data[qclient.OAuthClientSecretKey] = []byte(application.ClientSecret)
+
+	return true
+}
+
+// RemoveCredentials removes the client credentials of an OAuth application from the data of the credentials Secret, leaving the
+// credentials of other clients. Returns whether the data changed
+func RemoveCredentials(data map[string][]byte, clientID string) bool {
+
+	if clientID == "" || string(data[qclient.OAuthClientIDKey]) != clientID {
+		return false
+	}
+
+	delete(data, qclient.OAuthClientIDKey)
+	delete(data, qclient.OAuthClientSecretKey)
+
+	return true
+}
+
+func find(applications []qclient.Application, clientID string, name string) (qclient.Application, bool) {
+
+	for _, application := range applications {
+		if clientID != "" && application.ClientID == clientID {
+			return application, true
+		}
+	}
+
+	for _, application := range applications {
+		if application.Name == name {
+			return application, true
+		}
+	}
+
+	return qclient.Application{}, false
+}
@@ -0,0 +1,132 @@
+package oauthapplication
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestEnsure(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	existing := qclient.Application{Name: "quay-bridge-operator-openshift", ClientID: "client", ClientSecret: "secret"}
+
+	cases := []struct {
+		applications     []qclient.Application
+		status           *quayv1.OAuthApplicationStatus
+		rotationInterval int32
+		expected         Result
+		expectedRequests []string
+	}{
+		{
+			applications:     []qclient.Application{},
+			expected:         Result{Application: qclient.Application{Name: "quay-bridge-operator-openshift", ClientID: "created", ClientSecret: "secret"}, Created: true},
+			expectedRequests: []string{"GET /api/v1/organization/admin/applications", "POST /api/v1/organization/admin/applications"},
+		},
+		{
+			applications:     []qclient.Application{existing},
+			expected:         Result{Application: existing},
+			expectedRequests: []string{"GET /api/v1/organization/admin/applications"},
+		},
+		{
+			applications:     []qclient.Application{{Name: "renamed", ClientID: "client", ClientSecret: "secret"}},
+			status:           &quayv1.OAuthApplicationStatus{ClientID: "client", LastRotation: &metav1.Time{Time: now.Add(-time.Hour)}},
+			rotationInterval: 24,
+			expected:         Result{Application: qclient.Application{Name: "renamed", ClientID: "client", ClientSecret: "secret"}},
+			expectedRequests: []string{"GET /api/v1/organization/admin/applications"},
+		},
+		{
+			applications:     []qclient.Application{existing},
+			status:           &quayv1.OAuthApplicationStatus{ClientID: "client", LastRotation: &metav1.Time{Time: now.Add(-24 * time.Hour)}},
+			rotationInterval: 24,
+			expected:         Result{Application: qclient.Application{Name: "quay-bridge-operator-openshift", ClientID: "client", ClientSecret: "rotated"}, Rotated: true},
+			expectedRequests: []string{"GET /api/v1/organization/admin/applications", "POST /api/v1/organization/admin/applications/client/resetclientsecret"},
+		},
+	}
+
+	for i, c := range cases {
+
+		requests := []string{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+
+			switch r.Method + " " + r.URL.Path {
+			case "GET /api/v1/organization/admin/applications":
+				json.NewEncoder(w).Encode(qclient.ApplicationsResponse{Applications: c.applications})
+			case "POST /api/v1/organization/admin/applications":
+				request := qclient.ApplicationRequest{}
+				json.NewDecoder(r.Body).Decode(&request)
+				json.NewEncoder(w).Encode(qclient.Application{Name: request.Name, ClientID: "created", ClientSecret: "secret"})
+			case "POST /api/v1/organization/admin/applications/client/resetclientsecret":
+				json.NewEncoder(w).Encode(qclient.Application{Name: "quay-bridge-operator-openshift", ClientID: "client", ClientSecret: "rotated"})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		quayIntegration := &quayv1.QuayIntegration{
+			Spec: quayv1.QuayIntegrationSpec{
+				ClusterID:        "openshift",
+				OAuthApplication: &quayv1.OAuthApplication{Organization: "admin", RotationIntervalHours: c.rotationInterval},
+			},
+			Status: quayv1.QuayIntegrationStatus{OAuthApplication: c.status},
+		}
+
+		result, err := Ensure(qclient.NewClient(server.Client(), server.URL, "token"), quayIntegration, now)
+
+		server.Close()
+
+		if err != nil || !reflect.DeepEqual(result, c.expected) || !reflect.DeepEqual(requests, c.expectedRequests) {
+			t.Errorf("Test case %d did not match\nExpected: %+v %v\nActual: %+v %v %v", i, c.expected, c.expectedRequests, result, requests, err)
+		}
+	}
+}
+
+func TestApplyCredentials(t *testing.T) {
+
+	application := qclient.Application{ClientID: "client", ClientSecret: "rotated"}
+
+	cases := []struct {
+		data            map[string][]byte
+		expected        map[string][]byte
+		expectedChanged bool
+	}{
+		{
+			data:            map[string][]byte{"token": []byte("token")},
+			expected:        map[string][]byte{"token": []byte("token"), qclient.OAuthClientIDKey: []byte("client"), qclient.OAuthClientSecretKey: []byte("rotated")},
+			expectedChanged: true,
+		},
+		{
+			data:            map[string][]byte{qclient.OAuthClientIDKey: []byte("client"), qclient.OAuthClientSecretKey: []byte("secret"), qclient.OAuthRefreshTokenKey: []byte("refresh")},
+			expected:        map[string][]byte{qclient.OAuthClientIDKey: []byte("client"), qclient.OAuthClientSecretKey: []byte("rotated"), qclient.OAuthRefreshTokenKey: []byte("refresh")},
+			expectedChanged: true,
+		},
+		{
+			data:            map[string][]byte{qclient.OAuthClientIDKey: []byte("other"), qclient.OAuthClientSecretKey: []byte("secret"), qclient.OAuthRefreshTokenKey: []byte("refresh")},
+			expected:        map[string][]byte{qclient.OAuthClientIDKey: []byte("client"), qclient.OAuthClientSecretKey: []byte("rotated")},
+			expectedChanged: true,
+		},
+		{
+			data:     map[string][]byte{qclient.OAuthClientIDKey: []byte("client"), qclient.OAuthClientSecretKey: []byte("rotated")},
+			expected: map[string][]byte{qclient.OAuthClientIDKey: []byte("client"), qclient.OAuthClientSecretKey: []byte("rotated")},
+		},
+	}
+
+	for i, c := range cases {
+
+		changed := ApplyCredentials(c.data, application)
+
+		if changed != c.expectedChanged || !reflect.DeepEqual(c.data, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v", i, c.expectedChanged, c.expected, changed, c.data)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package offline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RetryInterval is how often queued operations are applied while Quay is unreachable
+	RetryInterval = 30 * time.Second
+
+	// statusUpdateAttempts bounds the attempts to update the status when conflicting with concurrent reconciles
+	statusUpdateAttempts = 5
+)
+
+// IsReachable returns whether Quay responds to its health check. Quay answering with a server error is considered unreachable,
+// as its API is usually fronted by a load balancer or route while the instance itself is down
+func IsReachable(quayClient *qclient.QuayClient) bool {
+
+	healthResponse, healthErr := quayClient.GetHealth()
+
+	return healthErr.Error == nil && healthResponse.StatusCode < 500
+}
+
+// DeleteOrganization deletes an organization through an OrgActuator unless it does not exist anymore
+func DeleteOrganization(quayClient *qclient.QuayClient, orgActuator actuator.OrgActuator, organization string) error {
+
+	_, organizationResponse, organizationErr := quayClient.GetOrganizationByname(organization)
+
+	if organizationErr.Error != nil {
+		return organizationErr.Error
+	}
+
+	if organizationResponse.StatusCode == 404 {
+		return nil
+	}
+
+	if organizationResponse.StatusCode != 200 {
+		return fmt.Errorf("unexpected status code %d retrieving organization", organizationResponse.StatusCode)
+	}
+
+	deleteResponse, deleteErr := orgActuator.DeleteOrganization(organization)
+
+	if deleteErr.Error != nil {
+		return deleteErr.Error
+	}
+
+	if deleteResponse.StatusCode != 204 {
+		return fmt.Errorf("unexpected status code %d deleting organization", deleteResponse.StatusCode)
+	}
+
+	return nil
+}
+
+// HasOperation returns whether an operation of the type is queued for the organization
+func HasOperation(quayIntegration *quayv1.QuayIntegration, operationType quayv1.PendingOperationType, organization string) bool {
+	return findOperation(&quayIntegration.Status, operationType, organization) >= 0
+}
+
+// Enqueue queues an operation in the status of the QuayIntegration unless it is already queued
+func Enqueue(ctx context.Context, c client.Client, name string, operation quayv1.PendingOperation) error {
+
+	if operation.QueuedAt.IsZero() {
+		operation.QueuedAt = metav1.Now()
+	}
+
+	return updateStatus(ctx, c, name, func(status *quayv1.QuayIntegrationStatus) bool {
+		return AddOperation(status, operation)
+	})
+}
+
+// Dequeue removes an operation from the status of the QuayIntegration
+func Dequeue(ctx context.Context, c client.Client, name string, operationType quayv1.PendingOperationType, organization string) error {
+
+	return updateStatus(ctx, c, name, func(status *quayv1.QuayIntegrationStatus) bool {
+		return RemoveOperation(status, operationType, organization)
+	})
+}
+
+// RecordError records the error of the last attempt to apply an operation
+func RecordError(ctx context.Context, c client.Client, name string, operationType quayv1.PendingOperationType, organization string, err error) error {
+
+	return updateStatus(ctx, c, name, func(status *quayv1.QuayIntegrationStatus) bool {
+
+		i := findOperation(status, operationType, organization)
+
+		if i < 0 || status.PendingOperations[i].Message == err.Error() {
+			return false
+		}
+
+		status.PendingOperations[i].Message = err.Error()
+
+		return true
+	})
+}
+
+// AddOperation queues an operation unless an operation of the same type is already queued for the organization, returning whether the status changed
+func AddOperation(status *quayv1.QuayIntegrationStatus, operation quayv1.PendingOperation) bool {
+
+	if findOperation(status, operation.Type, operation.Organization) >= 0 {
+		return false
+	}
+
+	status.PendingOperations = append(status.PendingOperations, operation)
+
+	return true
+}
+
+// RemoveOperation removes the operation of the type queued for the organization, returning whether the status changed
+func RemoveOperation(status *quayv1.QuayIntegrationStatus, operationType quayv1.PendingOperationType, organization string) bool {
+
+	i := findOperation(status, operationType, organization)
+
+	if i < 0 {
+		return false
+	}
+
+	status.PendingOperations = append(status.PendingOperations[:i], status.PendingOperations[i+1:]...)
+
+	if len(status.PendingOperations) == 0 {
+		status.PendingOperations = nil
+	}
+
+	return true
+}
+
+func findOperation(status *quayv1.QuayIntegrationStatus, operationType quayv1.PendingOperationType, organization string) int {
+
+	for i, operation := range status.PendingOperations {
+		if operation.Type == operationType && operation.Organization == organization {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// updateStatus applies a change to the status of the QuayIntegration, retrying on conflicts with concurrent reconciles
+func updateStatus(ctx context.Context, c client.Client, name string, mutate func(status *quayv1.QuayIntegrationStatus) bool) error {
+
+	var err error
+
+	for attempt := 0; attempt < statusUpdateAttempts; attempt++ {
+
+		quayIntegration := &quayv1.QuayIntegration{}
+
+		if err = c.Get(ctx, types.NamespacedName{Name: name}, quayIntegration); err != nil {
+			return err
+		}
+
+		if !mutate(&quayIntegration.Status) {
+			return nil
+		}
+
+		if err = c.Status().Update(ctx, quayIntegration); !apierrors.IsConflict(err) {
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,80 @@
+package offline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestOperations(t *testing.T) {
+
+	deleteApp := quayv1.PendingOperation{Type: quayv1.DeleteOrganizationOperation, Namespace: "app", Organization: "openshift_app"}
+	syncApp := quayv1.PendingOperation{Type: quayv1.SyncNamespaceOperation, Namespace: "app", Organization: "openshift_app"}
+	deleteOther := quayv1.PendingOperation{Type: quayv1.DeleteOrganizationOperation, Namespace: "other", Organization: "openshift_other"}
+
+	cases := []struct {
+		pending         []quayv1.PendingOperation
+		add             *quayv1.PendingOperation
+		remove          *quayv1.PendingOperation
+		expected        []quayv1.PendingOperation
+		expectedChanged bool
+	}{
+		{pending: nil, add: &deleteApp, expected: []quayv1.PendingOperation{deleteApp}, expectedChanged: true},
+		{pending: []quayv1.PendingOperation{deleteApp}, add: &deleteApp, expected: []quayv1.PendingOperation{deleteApp}, expectedChanged: false},
+		{pending: []quayv1.PendingOperation{deleteApp}, add: &syncApp, expected: []quayv1.PendingOperation{deleteApp, syncApp}, expectedChanged: true},
+		{pending: []quayv1.PendingOperation{deleteApp, deleteOther}, remove: &deleteApp, expected: []quayv1.PendingOperation{deleteOther}, expectedChanged: true},
+		{pending: []quayv1.PendingOperation{deleteApp}, remove: &syncApp, expected: []quayv1.PendingOperation{deleteApp}, expectedChanged: false},
+		{pending: []quayv1.PendingOperation{deleteApp}, remove: &deleteApp, expected: nil, expectedChanged: true},
+	}
+
+	for i, c := range cases {
+
+		status := &quayv1.QuayIntegrationStatus{PendingOperations: append([]quayv1.PendingOperation{}, c.pending...)}
+
+		var changed bool
+
+		if c.add != nil {
+			changed = AddOperation(status, *c.add)
+		} else {
+			changed = RemoveOperation(status, c.remove.Type, c.remove.Organization)
+		}
+
+		if changed != c.expectedChanged || !reflect.DeepEqual(status.PendingOperations, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %v %#v\nActual: %v %#v", i, c.expectedChanged, c.expected, changed, status.PendingOperations)
+		}
+	}
+}
+
+func TestIsReachable(t *testing.T) {
+
+	cases := []struct {
+		statusCode int
+		closed     bool
+		expected   bool
+	}{
+		{statusCode: http.StatusOK, expected: true},
+		{statusCode: http.StatusServiceUnavailable, expected: false},
+		{closed: true, expected: false},
+	}
+
+	for i, c := range cases {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.statusCode)
+		}))
+
+		if c.closed {
+			server.Close()
+		}
+
+		if result := IsReachable(qclient.NewClient(server.Client(), server.URL, "")); result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, result)
+		}
+
+		server.Close()
+	}
+}
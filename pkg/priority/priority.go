@@ -0,0 +1,137 @@
+package priority
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Throttle spaces routine reconciles so that they never fill the work queue, letting urgent reconciles queued at any time
+// be processed promptly
+type Throttle struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// NewThrottle creates a Throttle admitting qps routine reconciles per second. Routine reconciles are not throttled when qps is 0
+func NewThrottle(qps float64) *Throttle {
+
+	throttle := &Throttle{now: time.Now}
+
+	if qps > 0 {
+		throttle.interval = time.Duration(float64(time.Second) / qps)
+	}
+
+	return throttle
+}
+
+// Reserve reserves the next slot and returns how long to wait for it
+func (t *Throttle) Reserve() time.Duration {
+
+	if t == nil || t.interval == 0 {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+
+	if t.next.Before(now) {
+		t.next = now
+	}
+
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+
+	return delay
+}
+
+// Classifier separates urgent reconciles, such as those of objects created or changed by users, from routine reconciles,
+// such as periodic resyncs and the listing of existing objects when the operator starts
+type Classifier struct {
+	// StartedAt is the time the operator started. Objects created before are listed when the operator starts
+	StartedAt time.Time
+	// IsUrgent, when set, returns whether the routine reconcile of an object must be processed promptly
+	IsUrgent func(obj client.Object) bool
+}
+
+// Urgent returns a predicate selecting urgent events
+func (c *Classifier) Urgent() predicate.Funcs {
+	return c.predicate(false)
+}
+
+// Routine returns a predicate selecting routine events
+func (c *Classifier) Routine() predicate.Funcs {
+	return c.predicate(true)
+}
+
+func (c *Classifier) predicate(routine bool) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return c.isRoutine(e.Object, !e.Object.GetCreationTimestamp().Time.After(c.StartedAt)) == routine
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// Resyncs deliver the cached revision of the object again
+			return c.isRoutine(e.ObjectNew, e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion()) == routine
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return !routine
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return !routine
+		},
+	}
+}
+
+func (c *Classifier) isRoutine(obj client.Object, routine bool) bool {
+	return routine && (c.IsUrgent == nil || !c.IsUrgent(obj))
+}
+
+// EnqueueRoutine enqueues routine reconciles in the slots reserved from a Throttle
+type EnqueueRoutine struct {
+	Throttle *Throttle
+}
+
+var _ handler.EventHandler = &EnqueueRoutine{}
+
+// Create implements handler.EventHandler
+func (e *EnqueueRoutine) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Object, q)
+}
+
+// Update implements handler.EventHandler
+func (e *EnqueueRoutine) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.ObjectNew, q)
+}
+
+// Delete implements handler.EventHandler
+func (e *EnqueueRoutine) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Object, q)
+}
+
+// Generic implements handler.EventHandler
+func (e *EnqueueRoutine) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(evt.Object, q)
+}
+
+func (e *EnqueueRoutine) enqueue(obj client.Object, q workqueue.RateLimitingInterface) {
+
+	if obj == nil {
+		return
+	}
+
+	q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}}, e.Throttle.Reserve())
+}
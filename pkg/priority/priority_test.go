@@ -0,0 +1,85 @@
+package priority
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestThrottleReserve(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	throttle := NewThrottle(2)
+	throttle.now = func() time.Time { return now }
+
+	expected := []time.Duration{0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+
+	for i, e := range expected {
+		if delay := throttle.Reserve(); delay != e {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, e, delay)
+		}
+	}
+
+	// Slots left unused are not accumulated
+	now = now.Add(time.Minute)
+
+	if delay := throttle.Reserve(); delay != 0 {
+		t.Errorf("Expected no delay once the throttle is idle, got %v", delay)
+	}
+
+	if delay := NewThrottle(0).Reserve(); delay != 0 {
+		t.Errorf("Expected no delay when throttling is disabled, got %v", delay)
+	}
+}
+
+func TestClassifier(t *testing.T) {
+
+	startedAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	namespace := func(name string, created time.Time, resourceVersion string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created), ResourceVersion: resourceVersion}}
+	}
+
+	classifier := &Classifier{
+		StartedAt: startedAt,
+		IsUrgent: func(obj client.Object) bool {
+			return obj.GetName() == "missing-secrets"
+		},
+	}
+
+	cases := []struct {
+		event           interface{}
+		expectedUrgent  bool
+		expectedRoutine bool
+	}{
+		{event: event.CreateEvent{Object: namespace("existing", startedAt.Add(-time.Hour), "1")}, expectedUrgent: false, expectedRoutine: true},
+		{event: event.CreateEvent{Object: namespace("created", startedAt.Add(time.Minute), "1")}, expectedUrgent: true, expectedRoutine: false},
+		{event: event.CreateEvent{Object: namespace("missing-secrets", startedAt.Add(-time.Hour), "1")}, expectedUrgent: true, expectedRoutine: false},
+		{event: event.UpdateEvent{ObjectOld: namespace("existing", startedAt.Add(-time.Hour), "1"), ObjectNew: namespace("existing", startedAt.Add(-time.Hour), "1")}, expectedUrgent: false, expectedRoutine: true},
+		{event: event.UpdateEvent{ObjectOld: namespace("existing", startedAt.Add(-time.Hour), "1"), ObjectNew: namespace("existing", startedAt.Add(-time.Hour), "2")}, expectedUrgent: true, expectedRoutine: false},
+		{event: event.DeleteEvent{Object: namespace("existing", startedAt.Add(-time.Hour), "1")}, expectedUrgent: true, expectedRoutine: false},
+	}
+
+	for i, c := range cases {
+
+		urgent, routine := false, false
+
+		switch e := c.event.(type) {
+		case event.CreateEvent:
+			urgent, routine = classifier.Urgent().Create(e), classifier.Routine().Create(e)
+		case event.UpdateEvent:
+			urgent, routine = classifier.Urgent().Update(e), classifier.Routine().Update(e)
+		case event.DeleteEvent:
+			urgent, routine = classifier.Urgent().Delete(e), classifier.Routine().Delete(e)
+		}
+
+		if urgent != c.expectedUrgent || routine != c.expectedRoutine {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v", i, c.expectedUrgent, c.expectedRoutine, urgent, routine)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/version"
+)
+
+const (
+	// blockStart and blockEnd delimit the provenance recorded in the description of a repository. The description is rendered as
+	// Markdown by Quay, in which the HTML comment holding the provenance is not displayed
+	blockStart = "<!-- quay-bridge-operator:provenance "
+	blockEnd   = " -->"
+)
+
+// Provenance identifies the cluster and namespace a Quay organization or repository was created for
+type Provenance struct {
+	ClusterID       string `json:"clusterID"`
+	Namespace       string `json:"namespace"`
+	NamespaceUID    string `json:"namespaceUID"`
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+}
+
+// New returns the provenance of the resources created in Quay for a namespace
+func New(quayIntegration *quayv1.QuayIntegration, namespace *corev1.Namespace) Provenance {
+	return Provenance{
+		ClusterID:       quayIntegration.Spec.ClusterID,
+		Namespace:       namespace.Name,
+		NamespaceUID:    string(namespace.UID),
+		OperatorVersion: version.Version,
+	}
+}
+
+// RobotMetadata returns the provenance as the unstructured metadata of a robot account
+func (p Provenance) RobotMetadata() map[string]interface{} {
+	return map[string]interface{}{
+		constants.ClusterIDRobotMetadataKey:       p.ClusterID,
+		constants.NamespaceRobotMetadataKey:       p.Namespace,
+		constants.NamespaceUIDRobotMetadataKey:    p.NamespaceUID,
+		constants.OperatorVersionRobotMetadataKey: p.OperatorVersion,
+	}
+}
+
+// FromRobotMetadata reads the provenance recorded in the unstructured metadata of a robot account. Robot accounts created by
+// previous versions of the operator may only record the namespace UID
+func FromRobotMetadata(metadata map[string]interface{}) Provenance {
+
+	p := Provenance{}
+
+	p.ClusterID, _ = metadata[constants.ClusterIDRobotMetadataKey].(string)
+	p.Namespace, _ = metadata[constants.NamespaceRobotMetadataKey].(string)
+	p.NamespaceUID, _ = metadata[constants.NamespaceUIDRobotMetadataKey].(string)
+	p.OperatorVersion, _ = metadata[constants.OperatorVersionRobotMetadataKey].(string)
+
+	return p
+}
+
+// Conflict describes why a resource recorded with this provenance does not belong to another cluster, empty when it does or
+// when the cluster is unknown
+func (p Provenance) Conflict(expected Provenance) string {
+
+	if p.ClusterID != "" && p.ClusterID != expected.ClusterID {
+		return fmt.Sprintf("created for namespace %s of cluster %s", p.Namespace, p.ClusterID)
+	}
+
+	return ""
+}
+
+// ParseDescription returns the provenance recorded in the description of a repository, if any
+func ParseDescription(description string) (Provenance, bool) {
+
+	start, end := findBlock(description)
+
+	if start < 0 {
+		return Provenance{}, false
+	}
+
+	p := Provenance{}
+
+	if err := json.Unmarshal([]byte(description[start+len(blockStart):end]), &p); err != nil {
+		return Provenance{}, false
+	}
+
+	return p, true
+}
+
+// ApplyDescription records the provenance in the description of a repository, replacing the provenance previously recorded
+// while preserving the rest of the description
+func ApplyDescription(description string, p Provenance) (string, error) {
+
+	data, err := json.Marshal(p)
+
+	if err != nil {
+		return "", err
+	}
+
+	block := blockStart + string(data) + blockEnd
+
+	if start, end := findBlock(description); start >= 0 {
+		return description[:start] + block + description[end+len(blockEnd):], nil
+	}
+
+	if description = strings.TrimRight(description, "\n"); description == "" {
+		return block, nil
+	}
+
+	return description + "\n\n" + block, nil
+}
+
+// findBlock returns the start of the last provenance block of a description and the start of its end delimiter, or -1 when
+// the description does not record a provenance
+func findBlock(description string) (int, int) {
+
+	start := strings.LastIndex(description, blockStart)
+
+	if start < 0 {
+		return -1, -1
+	}
+
+	end := strings.Index(description[start+len(blockStart):], blockEnd)
+
+	if end < 0 {
+		return -1, -1
+	}
+
+	return start, start + len(blockStart) + end
+}
@@ -0,0 +1,62 @@
+package provenance
+
+import (
+	"testing"
+)
+
+func TestApplyDescription(t *testing.T) {
+
+	p := Provenance{ClusterID: "openshift", Namespace: "app", NamespaceUID: "uid", OperatorVersion: "v1.0.0"}
+	block := `<!-- quay-bridge-operator:provenance {"clusterID":"openshift","namespace":"app","namespaceUID":"uid","operatorVersion":"v1.0.0"} -->`
+
+	cases := []struct {
+		description string
+		expected    string
+	}{
+		{description: "", expected: block},
+		{description: "Frontend of the store\n", expected: "Frontend of the store\n\n" + block},
+		{description: "Frontend\n\n<!-- quay-bridge-operator:provenance {\"clusterID\":\"previous\"} -->\n\nMore", expected: "Frontend\n\n" + block + "\n\nMore"},
+		{description: "Unterminated <!-- quay-bridge-operator:provenance {", expected: "Unterminated <!-- quay-bridge-operator:provenance {\n\n" + block},
+	}
+
+	for i, c := range cases {
+
+		actual, err := ApplyDescription(c.description, p)
+
+		if err != nil || actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s (%v)", i, c.expected, actual, err)
+		}
+
+		if parsed, found := ParseDescription(actual); !found || parsed != p {
+			t.Errorf("Test case %d did not parse\nExpected: %v\nActual: %v %v", i, p, parsed, found)
+		}
+	}
+}
+
+func TestConflict(t *testing.T) {
+
+	expected := Provenance{ClusterID: "openshift", Namespace: "app", NamespaceUID: "uid"}
+
+	cases := []struct {
+		recorded         Provenance
+		expectedConflict bool
+	}{
+		{recorded: expected, expectedConflict: false},
+		{recorded: Provenance{ClusterID: "openshift", Namespace: "app", NamespaceUID: "previous"}, expectedConflict: false},
+		{recorded: Provenance{NamespaceUID: "previous"}, expectedConflict: false},
+		{recorded: Provenance{ClusterID: "other", Namespace: "app", NamespaceUID: "uid"}, expectedConflict: true},
+	}
+
+	for i, c := range cases {
+
+		conflict := c.recorded.Conflict(expected)
+
+		if (conflict != "") != c.expectedConflict {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %s", i, c.expectedConflict, conflict)
+		}
+	}
+
+	if recorded := FromRobotMetadata(expected.RobotMetadata()); recorded != expected {
+		t.Errorf("Robot metadata did not match\nExpected: %v\nActual: %v", expected, recorded)
+	}
+}
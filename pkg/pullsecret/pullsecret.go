@@ -0,0 +1,163 @@
+package pullsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ManagedAuthsAnnotation lists the auths of the global pull secret managed by the operator
+	ManagedAuthsAnnotation = "quay.redhat.com/managed-auths"
+)
+
+var (
+	// ErrUnmanagedAuth is returned when an auth of the global pull secret is not managed by the operator
+	ErrUnmanagedAuth = errors.New("auth is not managed by the operator")
+)
+
+// GenerateAuthKey returns the key of the auth of a Quay organization, scoping the credentials to the organization
+func GenerateAuthKey(registryHostname string, quayOrganizationName string) string {
+	return fmt.Sprintf("%s/%s", registryHostname, quayOrganizationName)
+}
+
+// SetAuth adds or replaces an auth managed by the operator. Auths added by other actors are never replaced, returning
+// ErrUnmanagedAuth instead. Returns whether the Secret changed
+func SetAuth(secret *corev1.Secret, key string, username string, password string) (bool, error) {
+
+	config, auths, err := parse(secret)
+
+	if err != nil {
+		return false, err
+	}
+
+	managed := getManagedAuths(secret)
+
+	if _, ok := auths[key]; ok && !managed[key] {
+		return false, ErrUnmanagedAuth
+	}
+
+	auth, err := json.Marshal(map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	if managed[key] && string(auths[key]) == string(auth) {
+		return false, nil
+	}
+
+	auths[key] = auth
+	managed[key] = true
+
+	return true, write(secret, config, auths, managed)
+}
+
+// RemoveAuth removes an auth managed by the operator, leaving auths added by other actors untouched. Returns whether the Secret changed
+func RemoveAuth(secret *corev1.Secret, key string) (bool, error) {
+
+	managed := getManagedAuths(secret)
+
+	if !managed[key] {
+		return false, nil
+	}
+
+	config, auths, err := parse(secret)
+
+	if err != nil {
+		return false, err
+	}
+
+	delete(auths, key)
+	delete(managed, key)
+
+	return true, write(secret, config, auths, managed)
+}
+
+// parse returns the content of the Secret along with its auths, preserving fields unknown to the operator
+func parse(secret *corev1.Secret) (map[string]json.RawMessage, map[string]json.RawMessage, error) {
+
+	config := map[string]json.RawMessage{}
+	auths := map[string]json.RawMessage{}
+
+	data, ok := secret.Data[corev1.DockerConfigJsonKey]
+
+	if !ok || len(data) == 0 {
+		return config, auths, nil
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %v", corev1.DockerConfigJsonKey, err)
+	}
+
+	if raw, ok := config["auths"]; ok {
+		if err := json.Unmarshal(raw, &auths); err != nil {
+			return nil, nil, fmt.Errorf("invalid auths: %v", err)
+		}
+	}
+
+	return config, auths, nil
+}
+
+func write(secret *corev1.Secret, config map[string]json.RawMessage, auths map[string]json.RawMessage, managed map[string]bool) error {
+
+	rawAuths, err := json.Marshal(auths)
+
+	if err != nil {
+		return err
+	}
+
+	config["auths"] = rawAuths
+
+	data, err := json.Marshal(config)
+
+	if err != nil {
+		return err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	secret.Data[corev1.DockerConfigJsonKey] = data
+
+	keys := []string{}
+
+	for key := range managed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+
+	if len(keys) == 0 {
+		delete(secret.Annotations, ManagedAuthsAnnotation)
+	} else {
+		secret.Annotations[ManagedAuthsAnnotation] = strings.Join(keys, ",")
+	}
+
+	return nil
+}
+
+func getManagedAuths(secret *corev1.Secret) map[string]bool {
+
+	managed := map[string]bool{}
+
+	for _, key := range strings.Split(secret.Annotations[ManagedAuthsAnnotation], ",") {
+		if key != "" {
+			managed[key] = true
+		}
+	}
+
+	return managed
+}
@@ -0,0 +1,139 @@
+package pullsecret
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetAuth(t *testing.T) {
+
+	cases := []struct {
+		data            string
+		managed         string
+		key             string
+		expectedChanged bool
+		expectedErr     error
+		expectedAuths   []string
+		expectedManaged string
+	}{
+		{
+			data:            `{"auths":{"cloud.openshift.com":{"auth":"b3BlbnNoaWZ0"}}}`,
+			key:             "quay.example.com/openshift_test",
+			expectedChanged: true,
+			expectedAuths:   []string{"cloud.openshift.com", "quay.example.com/openshift_test"},
+			expectedManaged: "quay.example.com/openshift_test",
+		},
+		{
+			data:            `{"auths":{"quay.example.com/openshift_test":{"auth":"dXNlcjpwYXNz"}}}`,
+			key:             "quay.example.com/openshift_test",
+			expectedChanged: false,
+			expectedErr:     ErrUnmanagedAuth,
+			expectedAuths:   []string{"quay.example.com/openshift_test"},
+		},
+		{
+			data:            `{"auths":{"quay.example.com/openshift_test":{"auth":"b2xkOm9sZA=="}}}`,
+			managed:         "quay.example.com/openshift_test",
+			key:             "quay.example.com/openshift_test",
+			expectedChanged: true,
+			expectedAuths:   []string{"quay.example.com/openshift_test"},
+			expectedManaged: "quay.example.com/openshift_test",
+		},
+		{
+			data:            `{"auths":{"quay.example.com/openshift_test":{"auth":"dXNlcjpwYXNz"}}}`,
+			managed:         "quay.example.com/openshift_test",
+			key:             "quay.example.com/openshift_test",
+			expectedChanged: false,
+			expectedAuths:   []string{"quay.example.com/openshift_test"},
+			expectedManaged: "quay.example.com/openshift_test",
+		},
+	}
+
+	for i, c := range cases {
+
+		secret := newSecret(c.data, c.managed)
+
+		changed, err := SetAuth(secret, c.key, "user", "pass")
+
+		if changed != c.expectedChanged || err != c.expectedErr || !reflect.DeepEqual(getAuthKeys(t, secret), c.expectedAuths) || secret.Annotations[ManagedAuthsAnnotation] != c.expectedManaged {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %v %s\nActual: %v %v %v %s", i, c.expectedChanged, c.expectedErr, c.expectedAuths, c.expectedManaged, changed, err, getAuthKeys(t, secret), secret.Annotations[ManagedAuthsAnnotation])
+		}
+	}
+}
+
+func TestRemoveAuth(t *testing.T) {
+
+	cases := []struct {
+		data            string
+		managed         string
+		key             string
+		expectedChanged bool
+		expectedAuths   []string
+		expectedManaged string
+	}{
+		{
+			data:            `{"auths":{"cloud.openshift.com":{"auth":"b3BlbnNoaWZ0"},"quay.example.com/a":{"auth":"YTph"},"quay.example.com/b":{"auth":"Yjpi"}}}`,
+			managed:         "quay.example.com/a,quay.example.com/b",
+			key:             "quay.example.com/a",
+			expectedChanged: true,
+			expectedAuths:   []string{"cloud.openshift.com", "quay.example.com/b"},
+			expectedManaged: "quay.example.com/b",
+		},
+		{
+			data:            `{"auths":{"quay.example.com/a":{"auth":"YTph"}}}`,
+			key:             "quay.example.com/a",
+			expectedChanged: false,
+			expectedAuths:   []string{"quay.example.com/a"},
+		},
+	}
+
+	for i, c := range cases {
+
+		secret := newSecret(c.data, c.managed)
+
+		changed, err := RemoveAuth(secret, c.key)
+
+		if err != nil || changed != c.expectedChanged || !reflect.DeepEqual(getAuthKeys(t, secret), c.expectedAuths) || secret.Annotations[ManagedAuthsAnnotation] != c.expectedManaged {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %s\nActual: %v %v %s (%v)", i, c.expectedChanged, c.expectedAuths, c.expectedManaged, changed, getAuthKeys(t, secret), secret.Annotations[ManagedAuthsAnnotation], err)
+		}
+	}
+}
+
+func newSecret(data string, managed string) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(data)},
+	}
+
+	if managed != "" {
+		secret.Annotations[ManagedAuthsAnnotation] = managed
+	}
+
+	return secret
+}
+
+func getAuthKeys(t *testing.T, secret *corev1.Secret) []string {
+
+	config := struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}{}
+
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		t.Fatalf("Failed to parse pull secret: %v", err)
+	}
+
+	keys := []string{}
+
+	for key := range config.Auths {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
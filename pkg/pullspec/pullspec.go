@@ -0,0 +1,94 @@
+package pullspec
+
+import (
+	"strings"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RewriteHostname replaces the registry hostname of an image reference hosted by one of the previous hostnames. Returns
+// whether the reference was rewritten
+func RewriteHostname(image string, previousHostnames []string, hostname string) (string, bool) {
+
+	for _, previousHostname := range previousHostnames {
+		if previousHostname != "" && previousHostname != hostname && strings.HasPrefix(image, previousHostname+"/") {
+			return hostname + strings.TrimPrefix(image, previousHostname), true
+		}
+	}
+
+	return image, false
+}
+
+// RewriteBuildConfig rewrites the output, builder or base image and annotations of a BuildConfig referring to one of the
+// previous hostnames. Returns whether the BuildConfig changed
+func RewriteBuildConfig(buildConfig *buildv1.BuildConfig, previousHostnames []string, hostname string) bool {
+
+	changed := rewriteAnnotations(buildConfig.Annotations, previousHostnames, hostname)
+
+	if rewriteObjectReference(buildConfig.Spec.Output.To, previousHostnames, hostname) {
+		changed = true
+	}
+
+	strategy := buildConfig.Spec.Strategy
+
+	switch {
+	case strategy.DockerStrategy != nil:
+		changed = rewriteObjectReference(strategy.DockerStrategy.From, previousHostnames, hostname) || changed
+	case strategy.SourceStrategy != nil:
+		changed = rewriteObjectReference(&strategy.SourceStrategy.From, previousHostnames, hostname) || changed
+	case strategy.CustomStrategy != nil:
+		changed = rewriteObjectReference(&strategy.CustomStrategy.From, previousHostnames, hostname) || changed
+	}
+
+	return changed
+}
+
+// RewriteImageStream rewrites the repository, tags and annotations of an ImageStream referring to one of the previous
+// hostnames. Returns whether the ImageStream changed
+func RewriteImageStream(imageStream *imagev1.ImageStream, previousHostnames []string, hostname string) bool {
+
+	changed := rewriteAnnotations(imageStream.Annotations, previousHostnames, hostname)
+
+	if repository, rewritten := RewriteHostname(imageStream.Spec.DockerImageRepository, previousHostnames, hostname); rewritten {
+		imageStream.Spec.DockerImageRepository = repository
+		changed = true
+	}
+
+	for i := range imageStream.Spec.Tags {
+		if rewriteObjectReference(imageStream.Spec.Tags[i].From, previousHostnames, hostname) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// rewriteObjectReference rewrites a DockerImage reference
+func rewriteObjectReference(reference *corev1.ObjectReference, previousHostnames []string, hostname string) bool {
+
+	if reference == nil || reference.Kind != "DockerImage" {
+		return false
+	}
+
+	image, rewritten := RewriteHostname(reference.Name, previousHostnames, hostname)
+	reference.Name = image
+
+	return rewritten
+}
+
+// rewriteAnnotations rewrites the annotations whose value is an image reference
+func rewriteAnnotations(annotations map[string]string, previousHostnames []string, hostname string) bool {
+
+	changed := false
+
+	for key, value := range annotations {
+		if image, rewritten := RewriteHostname(value, previousHostnames, hostname); rewritten {
+			annotations[key] = image
+			changed = true
+		}
+	}
+
+	return changed
+}
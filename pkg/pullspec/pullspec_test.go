@@ -0,0 +1,104 @@
+package pullspec
+
+import (
+	"reflect"
+	"testing"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRewriteHostname(t *testing.T) {
+
+	previousHostnames := []string{"quay-old.example.com", "quay-interim.example.com"}
+
+	cases := []struct {
+		image             string
+		expected          string
+		expectedRewritten bool
+	}{
+		{image: "quay-old.example.com/openshift_app/app:latest", expected: "quay.example.com/openshift_app/app:latest", expectedRewritten: true},
+		{image: "quay-interim.example.com/openshift_app/app@sha256:0123", expected: "quay.example.com/openshift_app/app@sha256:0123", expectedRewritten: true},
+		{image: "quay-old.example.com:8443/openshift_app/app:latest", expected: "quay-old.example.com:8443/openshift_app/app:latest", expectedRewritten: false},
+		{image: "quay-old.example.company/openshift_app/app:latest", expected: "quay-old.example.company/openshift_app/app:latest", expectedRewritten: false},
+		{image: "registry.example.com/app:latest", expected: "registry.example.com/app:latest", expectedRewritten: false},
+	}
+
+	for i, c := range cases {
+
+		image, rewritten := RewriteHostname(c.image, previousHostnames, "quay.example.com")
+
+		if image != c.expected || rewritten != c.expectedRewritten {
+			t.Errorf("Test case %d did not match\nExpected: %s %v\nActual: %s %v", i, c.expected, c.expectedRewritten, image, rewritten)
+		}
+	}
+}
+
+func TestRewriteBuildConfig(t *testing.T) {
+
+	buildConfig := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"base": "quay-old.example.com/openshift_base/base:1.0", "owner": "team-a"}},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay-old.example.com/openshift_base/base:1.0"}}},
+				Output:   buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+		},
+	}
+
+	if !RewriteBuildConfig(buildConfig, []string{"quay-old.example.com"}, "quay.example.com") {
+		t.Errorf("Expected BuildConfig to be rewritten")
+	}
+
+	expectedAnnotations := map[string]string{"base": "quay.example.com/openshift_base/base:1.0", "owner": "team-a"}
+
+	if buildConfig.Spec.Strategy.DockerStrategy.From.Name != "quay.example.com/openshift_base/base:1.0" || buildConfig.Spec.Output.To.Name != "app:latest" || !reflect.DeepEqual(expectedAnnotations, buildConfig.Annotations) {
+		t.Errorf("BuildConfig was not rewritten as expected: %v", buildConfig)
+	}
+
+	if RewriteBuildConfig(buildConfig, []string{"quay-old.example.com"}, "quay.example.com") {
+		t.Errorf("Expected rewritten BuildConfig to be left unchanged")
+	}
+}
+
+func TestRewriteImageStream(t *testing.T) {
+
+	cases := []struct {
+		imageStream      imagev1.ImageStream
+		expectedTagNames []string
+		expectedChanged  bool
+	}{
+		{
+			imageStream: imagev1.ImageStream{Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+				{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay-old.example.com/openshift_app/app:latest"}},
+				{Name: "stable", From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			}}},
+			expectedTagNames: []string{"quay.example.com/openshift_app/app:latest", "app:latest"},
+			expectedChanged:  true,
+		},
+		{
+			imageStream: imagev1.ImageStream{Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+				{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.example.com/openshift_app/app:latest"}},
+			}}},
+			expectedTagNames: []string{"quay.example.com/openshift_app/app:latest"},
+			expectedChanged:  false,
+		},
+	}
+
+	for i, c := range cases {
+
+		changed := RewriteImageStream(&c.imageStream, []string{"quay-old.example.com"}, "quay.example.com")
+
+		tagNames := []string{}
+
+		for _, tag := range c.imageStream.Spec.Tags {
+			tagNames = append(tagNames, tag.From.Name)
+		}
+
+		if changed != c.expectedChanged || !reflect.DeepEqual(c.expectedTagNames, tagNames) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v", i, c.expectedChanged, c.expectedTagNames, changed, tagNames)
+		}
+	}
+}
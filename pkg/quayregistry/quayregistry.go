@@ -0,0 +1,149 @@
+package quayregistry
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+var (
+	// QuayRegistryGVK is the GroupVersionKind of the QuayRegistry resource managed by the Quay Operator
+	QuayRegistryGVK = schema.GroupVersionKind{Group: "quay.redhat.com", Version: "v1", Kind: "QuayRegistry"}
+)
+
+const (
+	// availableConditionType is the condition reported by the Quay Operator once all components of a QuayRegistry are ready
+	availableConditionType = "Available"
+	// configBundleCertificateKey is the key of the TLS certificate within the config bundle Secret of a QuayRegistry
+	configBundleCertificateKey = "ssl.cert"
+	// defaultIngressCertificateNamespace contains the ConfigMap with the CA of the default ingress certificate used by managed routes
+	defaultIngressCertificateNamespace = "openshift-config-managed"
+	// defaultIngressCertificateName is the name of the ConfigMap with the CA of the default ingress certificate
+	defaultIngressCertificateName = "default-ingress-cert"
+	// defaultIngressCertificateKey is the key of the CA bundle within the default ingress certificate ConfigMap
+	defaultIngressCertificateKey = "ca-bundle.crt"
+)
+
+//+kubebuilder:rbac:groups=quay.redhat.com,resources=quayregistries,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// RegistryInfo is the information discovered from a QuayRegistry. Version is the version of Quay deployed by the Quay Operator,
+// empty until reported
+type RegistryInfo struct {
+	Endpoint             string
+	Available            bool
+	Version              string
+	Message              string
+	CertificateAuthority []byte
+}
+
+// NewQuayRegistry returns an empty QuayRegistry suitable for retrieval and watches
+func NewQuayRegistry() *unstructured.Unstructured {
+	quayRegistry := &unstructured.Unstructured{}
+	quayRegistry.SetGroupVersionKind(QuayRegistryGVK)
+
+	return quayRegistry
+}
+
+// GetRegistryInfo extracts the endpoint, version and availability from the status of a QuayRegistry
+func GetRegistryInfo(quayRegistry *unstructured.Unstructured) *RegistryInfo {
+
+	registryInfo := &RegistryInfo{
+		Message: "QuayRegistry has not reported its status",
+	}
+
+	registryInfo.Endpoint, _, _ = unstructured.NestedString(quayRegistry.Object, "status", "registryEndpoint")
+	registryInfo.Version, _, _ = unstructured.NestedString(quayRegistry.Object, "status", "currentVersion")
+
+	conditions, _, _ := unstructured.NestedSlice(quayRegistry.Object, "status", "conditions")
+
+	for _, condition := range conditions {
+
+		conditionMap, ok := condition.(map[string]interface{})
+
+		if !ok || conditionMap["type"] != availableConditionType {
+			continue
+		}
+
+		registryInfo.Available = conditionMap["status"] == "True"
+
+		if message, ok := conditionMap["message"].(string); ok {
+			registryInfo.Message = message
+		}
+	}
+
+	if registryInfo.Endpoint == "" {
+		registryInfo.Available = false
+		registryInfo.Message = "QuayRegistry has not reported a registry endpoint"
+	}
+
+	return registryInfo
+}
+
+// Discover retrieves the QuayRegistry referenced by a QuayIntegration along with the certificate authority trusted for its endpoint
+func Discover(ctx context.Context, reader client.Reader, quayRegistryRef *quayv1.QuayRegistryRef) (*RegistryInfo, error) {
+
+	quayRegistry := NewQuayRegistry()
+
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: quayRegistryRef.Namespace, Name: quayRegistryRef.Name}, quayRegistry); err != nil {
+		return nil, fmt.Errorf("error retrieving QuayRegistry '%s/%s': %v", quayRegistryRef.Namespace, quayRegistryRef.Name, err)
+	}
+
+	registryInfo := GetRegistryInfo(quayRegistry)
+
+	// User provided certificates are stored in the config bundle. Otherwise Quay is exposed using the default ingress certificate
+	if configBundleSecret, _, _ := unstructured.NestedString(quayRegistry.Object, "spec", "configBundleSecret"); configBundleSecret != "" {
+
+		secret := &corev1.Secret{}
+
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: quayRegistryRef.Namespace, Name: configBundleSecret}, secret); err == nil {
+			registryInfo.CertificateAuthority = secret.Data[configBundleCertificateKey]
+		}
+	}
+
+	if len(registryInfo.CertificateAuthority) == 0 {
+
+		configMap := &corev1.ConfigMap{}
+
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: defaultIngressCertificateNamespace, Name: defaultIngressCertificateName}, configMap); err == nil {
+			registryInfo.CertificateAuthority = []byte(configMap.Data[defaultIngressCertificateKey])
+		}
+	}
+
+	return registryInfo, nil
+}
+
+// ResolveEndpoint returns the normalized URL of Quay and the certificate authority trusted for it. The hostname configured on the QuayIntegration takes precedence over QuayRegistryRef
+func ResolveEndpoint(ctx context.Context, reader client.Reader, quayIntegration *quayv1.QuayIntegration) (string, []byte, error) {
+
+	if quayIntegration.Spec.QuayHostname != "" {
+		quayHostname, err := quayv1.NormalizeQuayHostname(quayIntegration.Spec.QuayHostname)
+
+		return quayHostname, nil, err
+	}
+
+	if quayIntegration.Spec.QuayRegistryRef == nil {
+		return "", nil, fmt.Errorf("either 'QuayHostname' or 'QuayRegistryRef' must be specified")
+	}
+
+	registryInfo, err := Discover(ctx, reader, quayIntegration.Spec.QuayRegistryRef)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !registryInfo.Available {
+		return "", nil, fmt.Errorf("QuayRegistry '%s/%s' is not available: %s", quayIntegration.Spec.QuayRegistryRef.Namespace, quayIntegration.Spec.QuayRegistryRef.Name, registryInfo.Message)
+	}
+
+	quayHostname, err := quayv1.NormalizeQuayHostname(registryInfo.Endpoint)
+
+	return quayHostname, registryInfo.CertificateAuthority, err
+}
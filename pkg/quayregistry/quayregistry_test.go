@@ -0,0 +1,78 @@
+package quayregistry
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetRegistryInfo(t *testing.T) {
+
+	cases := []struct {
+		status   map[string]interface{}
+		expected *RegistryInfo
+	}{
+		{
+			status: nil,
+			expected: &RegistryInfo{
+				Message: "QuayRegistry has not reported a registry endpoint",
+			},
+		},
+		{
+			status: map[string]interface{}{
+				"registryEndpoint": "https://quay.apps.example.com",
+				"currentVersion":   "3.9.1",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "True", "message": "All components reporting as healthy"},
+				},
+			},
+			expected: &RegistryInfo{
+				Endpoint:  "https://quay.apps.example.com",
+				Available: true,
+				Message:   "All components reporting as healthy",
+				Version:   "3.9.1",
+			},
+		},
+		{
+			status: map[string]interface{}{
+				"registryEndpoint": "https://quay.apps.example.com",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "ComponentsCreated", "status": "True", "message": "All objects created"},
+					map[string]interface{}{"type": "Available", "status": "False", "message": "Awaiting for component quay to become available"},
+				},
+			},
+			expected: &RegistryInfo{
+				Endpoint:  "https://quay.apps.example.com",
+				Available: false,
+				Message:   "Awaiting for component quay to become available",
+			},
+		},
+		{
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "True", "message": "All components reporting as healthy"},
+				},
+			},
+			expected: &RegistryInfo{
+				Available: false,
+				Message:   "QuayRegistry has not reported a registry endpoint",
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayRegistry := NewQuayRegistry()
+
+		if c.status != nil {
+			unstructured.SetNestedField(quayRegistry.Object, c.status, "status")
+		}
+
+		result := GetRegistryInfo(quayRegistry)
+
+		if !reflect.DeepEqual(c.expected, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
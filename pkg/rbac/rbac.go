@@ -0,0 +1,335 @@
+package rbac
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BuildSyncFeature synchronizes completed Builds into ImageStreams
+	BuildSyncFeature = "BuildSync"
+	// MonitoringFeature provisions a ServiceMonitor and PrometheusRule
+	MonitoringFeature = "Monitoring"
+	// GrafanaDashboardFeature provisions the Grafana dashboard ConfigMap
+	GrafanaDashboardFeature = "GrafanaDashboard"
+	// SecretCacheFeature caches Secrets instead of reading them from the API server
+	SecretCacheFeature = "SecretCache"
+	// ImpersonationFeature writes resources within namespaces by impersonating a service account of the namespace
+	ImpersonationFeature = "Impersonation"
+	// ImagePolicyFeature generates sigstore ImagePolicies and ClusterImagePolicies for the managed Quay organizations
+	ImagePolicyFeature = "ImagePolicy"
+	// BuildRecoveryFeature replaces Builds admitted without being redirected to Quay
+	BuildRecoveryFeature = "BuildRecovery"
+	// GlobalPullSecretFeature adds the nodes robot account, granted read access to the managed Quay organizations, to the global pull secret
+	GlobalPullSecretFeature = "GlobalPullSecret"
+	// ReaderRobotFeature distributes the credentials of the reader robot accounts of the managed Quay organizations to designated namespaces
+	ReaderRobotFeature = "ReaderRobot"
+	// PullGrantsFeature grants namespaces read access to the Quay organization of another namespace using an annotation
+	PullGrantsFeature = "PullGrants"
+	// BaseImageTriggerFeature rebuilds BuildConfigs when their base image changes in Quay
+	BaseImageTriggerFeature = "BaseImageTrigger"
+	// SecretProtectionFeature watches the robot account Secrets of managed namespaces to recreate them when deleted and revert edits
+	SecretProtectionFeature = "SecretProtection"
+	// WebhookCertificatesFeature issues the serving certificate of the webhooks and injects its CA bundle into the webhook configurations
+	WebhookCertificatesFeature = "WebhookCertificates"
+
+	// DefaultImpersonationServiceAccount is the impersonated service account the default ClusterRole of the operator grants impersonation of
+	DefaultImpersonationServiceAccount = "quay-bridge-operator-writer"
+
+	// globalPullSecretNamespace contains the global pull secret of the cluster
+	globalPullSecretNamespace = "openshift-config"
+)
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"create", "get", "list", "patch", "update", "watch"}
+	allVerbs   = []string{"create", "delete", "get", "list", "patch", "update", "watch"}
+
+	// clusterResources are the cluster scoped resources accessed by the operator
+	clusterResources = map[string]bool{
+		"mutatingwebhookconfigurations":   true,
+		"namespaces":                      true,
+		"validatingwebhookconfigurations": true,
+		"clusterimagepolicies":            true,
+		"consolelinks":                    true,
+		"quayintegrations":                true,
+		"quayintegrations/finalizers":     true,
+		"quayintegrations/status":         true,
+		"quaysyncpolicies":                true,
+	}
+)
+
+// Features describes the features enabled on the operator determining the permissions it requires
+type Features struct {
+	BuildSync        bool
+	Monitoring       bool
+	GrafanaDashboard bool
+	SecretCache      bool
+	Impersonation    bool
+	ImagePolicy      bool
+	BuildRecovery    bool
+	GlobalPullSecret bool
+	ReaderRobot      bool
+	PullGrants       bool
+	BaseImageTrigger bool
+	SecretProtection bool
+	// ImpersonationServiceAccount is the only service account the operator may impersonate when Impersonation is enabled,
+	// DefaultImpersonationServiceAccount when empty
+	ImpersonationServiceAccount string
+	// WebhookCertificates issues the serving certificate of the webhooks in the namespace of the operator
+	WebhookCertificates bool
+
+	// Namespaces the operator is restricted to. Permissions on namespaced resources are granted cluster wide when empty
+	Namespaces []string
+}
+
+// Names returns the names of the enabled features
+func (f Features) Names() []string {
+
+	names := []string{}
+
+	for name, enabled := range map[string]bool{
+		BuildSyncFeature:           f.BuildSync,
+		MonitoringFeature:          f.Monitoring,
+		GrafanaDashboardFeature:    f.GrafanaDashboard,
+		SecretCacheFeature:         f.SecretCache,
+		ImpersonationFeature:       f.Impersonation,
+		ImagePolicyFeature:         f.ImagePolicy,
+		BuildRecoveryFeature:       f.BuildRecovery,
+		GlobalPullSecretFeature:    f.GlobalPullSecret,
+		ReaderRobotFeature:         f.ReaderRobot,
+		PullGrantsFeature:          f.PullGrants,
+		BaseImageTriggerFeature:    f.BaseImageTrigger,
+		SecretProtectionFeature:    f.SecretProtection,
+		WebhookCertificatesFeature: f.WebhookCertificates,
+	} {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Rules returns the permissions required by the operator for the enabled features
+func Rules(features Features) []rbacv1.PolicyRule {
+
+	// Robot account Secrets of formats no longer configured are deleted
+	secretVerbs := []string{"create", "delete", "get", "patch", "update"}
+
+	if features.SecretCache {
+		secretVerbs = allVerbs
+	}
+
+	rules := []rbacv1.PolicyRule{
+		// Image signing ConfigMaps are distributed to synchronized namespaces, and jobs and applied migrations are checkpointed to ConfigMaps
+		rule("", []string{"configmaps"}, allVerbs...),
+		rule("", []string{"events"}, writeVerbs...),
+		rule("", []string{"namespaces"}, "get", "list", "update", "watch"),
+		// Pods are listed to report the references to the internal registry remaining in the cluster
+		rule("", []string{"pods"}, "list"),
+		rule("", []string{"secrets"}, secretVerbs...),
+		rule("", []string{"serviceaccounts"}, writeVerbs...),
+		// BuildConfigs are rewritten when the registry hostname changes
+		rule("build.openshift.io", []string{"buildconfigs"}, "get", "list", "patch", "update", "watch"),
+		rule("console.openshift.io", []string{"consolelinks"}, allVerbs...),
+		rule("image.openshift.io", []string{"imagestreamimports", "imagestreams"}, writeVerbs...),
+		rule("quay.redhat.com", []string{"quayintegrations"}, allVerbs...),
+		rule("quay.redhat.com", []string{"quayintegrations/finalizers"}, "update"),
+		rule("quay.redhat.com", []string{"quayintegrations/status"}, "get", "patch", "update"),
+		rule("quay.redhat.com", []string{"quayregistries"}, readVerbs...),
+		rule("quay.redhat.com", []string{"quaysyncpolicies"}, readVerbs...),
+	}
+
+	if features.BuildSync {
+		rules = append(rules, rule("build.openshift.io", []string{"builds"}, writeVerbs...))
+	}
+
+	// Builds are replaced by cloning them
+	if features.BuildRecovery {
+		rules = append(rules,
+			rule("build.openshift.io", []string{"builds"}, writeVerbs...),
+			rule("build.openshift.io", []string{"builds/clone"}, "create"),
+		)
+	}
+
+	// BuildConfigs record the digest of their base image and are instantiated when it changes
+	if features.BaseImageTrigger {
+		rules = append(rules,
+			rule("build.openshift.io", []string{"buildconfigs"}, writeVerbs...),
+			rule("build.openshift.io", []string{"buildconfigs/instantiate"}, "create"),
+		)
+	}
+
+	// Managed Secrets are watched using a cache restricted by label when Secrets are not cached
+	if features.SecretProtection && !features.SecretCache {
+		rules = append(rules, rule("", []string{"secrets"}, "list", "watch"))
+	}
+
+	if features.Monitoring {
+		rules = append(rules, rule("monitoring.coreos.com", []string{"prometheusrules", "servicemonitors"}, allVerbs...))
+	}
+
+	if features.ImagePolicy {
+		rules = append(rules,
+			rule("config.openshift.io", []string{"clusterimagepolicies"}, allVerbs...),
+			rule("config.openshift.io", []string{"imagepolicies"}, allVerbs...),
+		)
+	}
+
+	// The CA bundle is injected into the webhook configurations routing requests to the operator
+	if features.WebhookCertificates {
+		rules = append(rules, rule("admissionregistration.k8s.io", []string{"mutatingwebhookconfigurations", "validatingwebhookconfigurations"}, "get", "list", "update"))
+	}
+
+	// The impersonated service accounts are bound to the namespace writer ClusterRole. Impersonation is restricted to their name, so
+	// that other service accounts, including privileged ones, cannot be impersonated
+	if features.Impersonation {
+
+		impersonationServiceAccount := features.ImpersonationServiceAccount
+
+		if impersonationServiceAccount == "" {
+			impersonationServiceAccount = DefaultImpersonationServiceAccount
+		}
+
+		impersonateRule := rule("", []string{"serviceaccounts"}, "impersonate")
+		impersonateRule.ResourceNames = []string{impersonationServiceAccount}
+
+		rules = append(rules,
+			impersonateRule,
+			rule("rbac.authorization.k8s.io", []string{"rolebindings"}, writeVerbs...),
+		)
+	}
+
+	return mergeRules(rules)
+}
+
+// Manifests returns the ClusterRole, and the Roles in each namespace when the operator is restricted to namespaces, granting the permissions
+// required by the operator for the enabled features
+func Manifests(name string, features Features) []client.Object {
+
+	clusterRules := []rbacv1.PolicyRule{}
+	namespacedRules := []rbacv1.PolicyRule{}
+
+	for _, rule := range Rules(features) {
+		if len(features.Namespaces) == 0 || clusterResources[rule.Resources[0]] {
+			clusterRules = append(clusterRules, rule)
+		} else {
+			namespacedRules = append(namespacedRules, rule)
+		}
+	}
+
+	objs := []client.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      clusterRules,
+		},
+	}
+
+	for _, namespace := range features.Namespaces {
+		objs = append(objs, &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Rules:      namespacedRules,
+		})
+	}
+
+	// The global pull secret resides outside of the watched namespaces
+	if features.GlobalPullSecret && len(features.Namespaces) > 0 {
+		objs = append(objs, &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: globalPullSecretNamespace},
+			Rules:      []rbacv1.PolicyRule{rule("", []string{"secrets"}, "get", "update")},
+		})
+	}
+
+	return objs
+}
+
+// WriteManifests writes resources as a multi-document YAML stream
+func WriteManifests(w io.Writer, objs []client.Object) error {
+
+	for _, obj := range objs {
+
+		data, err := yaml.Marshal(obj)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rule(apiGroup string, resources []string, verbs ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		APIGroups: []string{apiGroup},
+		Resources: resources,
+		Verbs:     verbs,
+	}
+}
+
+// mergeRules combines the verbs of rules sharing API groups, resources and resource names, sorted in the order produced by controller-gen
+func mergeRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+
+	merged := map[string]*rbacv1.PolicyRule{}
+	keys := []string{}
+
+	for i := range rules {
+
+		key := strings.Join(rules[i].APIGroups, ",") + "/" + strings.Join(rules[i].Resources, ",") + "/" + strings.Join(rules[i].ResourceNames, ",")
+
+		existing, ok := merged[key]
+
+		if !ok {
+			existing = &rbacv1.PolicyRule{APIGroups: rules[i].APIGroups, Resources: rules[i].Resources, ResourceNames: rules[i].ResourceNames}
+			merged[key] = existing
+			keys = append(keys, key)
+		}
+
+		existing.Verbs = unionVerbs(existing.Verbs, rules[i].Verbs)
+	}
+
+	sort.Strings(keys)
+
+	result := []rbacv1.PolicyRule{}
+
+	for _, key := range keys {
+		result = append(result, *merged[key])
+	}
+
+	return result
+}
+
+func unionVerbs(verbs []string, additional []string) []string {
+
+	set := map[string]bool{}
+
+	for _, verb := range append(append([]string{}, verbs...), additional...) {
+		set[verb] = true
+	}
+
+	result := []string{}
+
+	for verb := range set {
+		result = append(result, verb)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
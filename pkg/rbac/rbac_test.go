@@ -0,0 +1,155 @@
+package rbac
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRulesMatchDefaultRole(t *testing.T) {
+
+	data, err := ioutil.ReadFile("../../config/rbac/role.yaml")
+
+	if err != nil {
+		t.Fatalf("Failed to read role: %v", err)
+	}
+
+	role := rbacv1.ClusterRole{}
+
+	if err := yaml.Unmarshal(data, &role); err != nil {
+		t.Fatalf("Failed to parse role: %v", err)
+	}
+
+	rules := Rules(Features{BuildSync: true, Monitoring: true, GrafanaDashboard: true, SecretCache: true, Impersonation: true, ImagePolicy: true, BuildRecovery: true, GlobalPullSecret: true, ReaderRobot: true, PullGrants: true, BaseImageTrigger: true, SecretProtection: true, WebhookCertificates: true})
+
+	if !reflect.DeepEqual(rules, role.Rules) {
+		t.Errorf("Rules with every feature enabled do not match config/rbac/role.yaml\nExpected: %#v\nActual: %#v", role.Rules, rules)
+	}
+}
+
+func TestRules(t *testing.T) {
+
+	cases := []struct {
+		features Features
+		resource string
+		expected []string
+	}{
+		{features: Features{}, resource: "builds", expected: nil},
+		{features: Features{BuildSync: true}, resource: "builds", expected: []string{"create", "get", "list", "patch", "update", "watch"}},
+		{features: Features{}, resource: "secrets", expected: []string{"create", "delete", "get", "patch", "update"}},
+		{features: Features{SecretCache: true}, resource: "secrets", expected: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		{features: Features{ReaderRobot: true}, resource: "secrets", expected: []string{"create", "delete", "get", "patch", "update"}},
+		{features: Features{PullGrants: true}, resource: "secrets", expected: []string{"create", "delete", "get", "patch", "update"}},
+		{features: Features{SecretProtection: true}, resource: "secrets", expected: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		{features: Features{}, resource: "configmaps", expected: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		{features: Features{}, resource: "pods", expected: []string{"list"}},
+		{features: Features{}, resource: "servicemonitors", expected: nil},
+		{features: Features{Monitoring: true}, resource: "servicemonitors", expected: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		{features: Features{}, resource: "rolebindings", expected: nil},
+		{features: Features{}, resource: "clusterimagepolicies", expected: nil},
+		{features: Features{BuildRecovery: true}, resource: "builds/clone", expected: []string{"create"}},
+		{features: Features{}, resource: "buildconfigs", expected: []string{"get", "list", "patch", "update", "watch"}},
+		{features: Features{BaseImageTrigger: true}, resource: "buildconfigs", expected: []string{"create", "get", "list", "patch", "update", "watch"}},
+		{features: Features{BaseImageTrigger: true}, resource: "buildconfigs/instantiate", expected: []string{"create"}},
+		{features: Features{ImagePolicy: true}, resource: "imagepolicies", expected: []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+		{features: Features{}, resource: "mutatingwebhookconfigurations", expected: nil},
+		{features: Features{WebhookCertificates: true}, resource: "validatingwebhookconfigurations", expected: []string{"get", "list", "update"}},
+		{features: Features{Impersonation: true}, resource: "serviceaccounts", expected: []string{"impersonate"}},
+	}
+
+	for i, c := range cases {
+
+		var result []string
+
+		for _, rule := range Rules(c.features) {
+			for _, resource := range rule.Resources {
+				if resource == c.resource {
+					result = rule.Verbs
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func TestImpersonationRule(t *testing.T) {
+
+	cases := []struct {
+		features Features
+		expected [][]string
+	}{
+		{features: Features{}, expected: [][]string{nil}},
+		{features: Features{Impersonation: true}, expected: [][]string{nil, {DefaultImpersonationServiceAccount}}},
+		{features: Features{Impersonation: true, ImpersonationServiceAccount: "writer"}, expected: [][]string{nil, {"writer"}}},
+	}
+
+	for i, c := range cases {
+
+		result := [][]string{}
+
+		for _, rule := range Rules(c.features) {
+			if rule.Resources[0] == "serviceaccounts" {
+				result = append(result, rule.ResourceNames)
+
+				if rule.ResourceNames == nil && containsVerb(rule.Verbs, "impersonate") {
+					t.Errorf("Test case %d granted impersonation of every service account", i)
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
+
+func containsVerb(verbs []string, verb string) bool {
+
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestManifests(t *testing.T) {
+
+	cases := []struct {
+		features      Features
+		expectedKinds []string
+	}{
+		{features: Features{}, expectedKinds: []string{"ClusterRole"}},
+		{features: Features{Namespaces: []string{"a", "b"}}, expectedKinds: []string{"ClusterRole", "Role", "Role"}},
+		{features: Features{GlobalPullSecret: true}, expectedKinds: []string{"ClusterRole"}},
+		{features: Features{GlobalPullSecret: true, Namespaces: []string{"a"}}, expectedKinds: []string{"ClusterRole", "Role", "Role"}},
+	}
+
+	for i, c := range cases {
+
+		kinds := []string{}
+
+		for _, obj := range Manifests("manager-role", c.features) {
+			kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+
+			if role, ok := obj.(*rbacv1.Role); ok {
+				for _, rule := range role.Rules {
+					if clusterResources[rule.Resources[0]] {
+						t.Errorf("Test case %d granted cluster scoped resource %v in a Role", i, rule.Resources)
+					}
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(kinds, c.expectedKinds) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expectedKinds, kinds)
+		}
+	}
+}
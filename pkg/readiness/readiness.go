@@ -0,0 +1,214 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/quayregistry"
+)
+
+const (
+	// DefaultInitialInterval is the delay before Quay is probed again after the first failed probe
+	DefaultInitialInterval = 5 * time.Second
+	// DefaultMaxInterval caps the delay between probes
+	DefaultMaxInterval = 5 * time.Minute
+)
+
+// Prerequisite is a condition which must be met before Quay is probed, such as the migrations applied on upgrade
+type Prerequisite interface {
+	Complete() (bool, string)
+}
+
+// Gate holds back all controllers until the Quay instance has been found ready once, avoiding a reconcile
+// error for every namespace while Quay is still being installed. Quay is probed with an exponential backoff
+type Gate struct {
+	Reader          client.Reader
+	HTTPClientPool  *qclient.HTTPClientPool
+	Log             logr.Logger
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Prerequisites   []Prerequisite
+
+	mutex     sync.RWMutex
+	ready     bool
+	message   string
+	interval  time.Duration
+	nextProbe time.Time
+
+	// probe is overridden in tests
+	probe func(ctx context.Context) error
+}
+
+// NewGate creates a Gate probing the Quay instance of the QuayIntegration
+func NewGate(reader client.Reader, httpClientPool *qclient.HTTPClientPool, log logr.Logger) *Gate {
+	return &Gate{
+		Reader:          reader,
+		HTTPClientPool:  httpClientPool,
+		Log:             log,
+		InitialInterval: DefaultInitialInterval,
+		MaxInterval:     DefaultMaxInterval,
+		message:         "Quay has not been probed yet",
+	}
+}
+
+// IsReady returns whether Quay has been found ready
+func (g *Gate) IsReady() bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return g.ready
+}
+
+// Status returns whether Quay has been found ready along with the result of the last probe
+func (g *Gate) Status() (bool, string) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return g.ready, g.message
+}
+
+// RetryAfter returns how long controllers should wait before reconciling again while the Gate is closed
+func (g *Gate) RetryAfter() time.Duration {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if retryAfter := time.Until(g.nextProbe); retryAfter > 0 {
+		return retryAfter + time.Second
+	}
+
+	return g.InitialInterval
+}
+
+// Start implements manager.Runnable. Quay is probed until found ready, after which the Gate remains open
+func (g *Gate) Start(ctx context.Context) error {
+
+	for {
+
+		if g.check(ctx) {
+			return nil
+		}
+
+		g.mutex.RLock()
+		interval := g.interval
+		g.mutex.RUnlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check probes Quay once all prerequisites are met, opening the Gate on success and backing off otherwise
+func (g *Gate) check(ctx context.Context) bool {
+
+	probe := g.probe
+
+	if probe == nil {
+		probe = g.probeQuay
+	}
+
+	err := g.checkPrerequisites()
+
+	if err == nil {
+		err = probe(ctx)
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err == nil {
+		g.ready = true
+		g.message = "Quay is ready"
+		g.Log.Info("Quay is ready, starting synchronization")
+
+		return true
+	}
+
+	if g.interval == 0 {
+		g.interval = g.InitialInterval
+	} else if g.interval *= 2; g.interval > g.MaxInterval {
+		g.interval = g.MaxInterval
+	}
+
+	g.message = err.Error()
+	g.nextProbe = time.Now().Add(g.interval)
+	g.Log.Info("Waiting for Quay to become ready", "Reason", g.message, "Retry", g.interval)
+
+	return false
+}
+
+func (g *Gate) checkPrerequisites() error {
+
+	for _, prerequisite := range g.Prerequisites {
+		if complete, message := prerequisite.Complete(); !complete {
+			return fmt.Errorf("%s", message)
+		}
+	}
+
+	return nil
+}
+
+func (g *Gate) probeQuay(ctx context.Context) error {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := g.Reader.List(ctx, &quayIntegrations); err != nil {
+		return err
+	}
+
+	if len(quayIntegrations.Items) == 0 {
+		return fmt.Errorf("no QuayIntegrations defined")
+	}
+
+	// Every QuayIntegration synchronizes namespaces once several are defined
+	for i := range quayIntegrations.Items {
+
+		if err := g.probeQuayIntegration(ctx, &quayIntegrations.Items[i]); err != nil {
+			if len(quayIntegrations.Items) > 1 {
+				return fmt.Errorf("QuayIntegration %s: %v", quayIntegrations.Items[i].Name, err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Gate) probeQuayIntegration(ctx context.Context, quayIntegration *quayv1.QuayIntegration) error {
+
+	quayHostname, certificateAuthority, err := quayregistry.ResolveEndpoint(ctx, g.Reader, quayIntegration)
+
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := g.HTTPClientPool.GetHTTPClientWithCA(quayIntegration.IsTLSVerificationDisabled(), certificateAuthority)
+
+	if err != nil {
+		return err
+	}
+
+	quayClient := qclient.NewClient(httpClient, quayHostname, "")
+
+	healthResponse, healthErr := quayClient.GetHealth()
+
+	if healthErr.Error != nil {
+		return fmt.Errorf("Quay is unreachable: %v", healthErr.Error)
+	}
+
+	if healthResponse.StatusCode != 200 {
+		return fmt.Errorf("Quay is not ready, health check returned status code %d", healthResponse.StatusCode)
+	}
+
+	return nil
+}
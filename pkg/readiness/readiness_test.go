@@ -0,0 +1,97 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestGateBackoff(t *testing.T) {
+
+	cases := []struct {
+		results          []error
+		expectedReady    bool
+		expectedInterval time.Duration
+	}{
+		{
+			results:          []error{nil},
+			expectedReady:    true,
+			expectedInterval: 0,
+		},
+		{
+			results:          []error{fmt.Errorf("unreachable")},
+			expectedReady:    false,
+			expectedInterval: 5 * time.Second,
+		},
+		{
+			results:          []error{fmt.Errorf("unreachable"), fmt.Errorf("unreachable"), fmt.Errorf("unreachable")},
+			expectedReady:    false,
+			expectedInterval: 20 * time.Second,
+		},
+		{
+			results:          []error{fmt.Errorf("unreachable"), fmt.Errorf("unreachable"), nil},
+			expectedReady:    true,
+			expectedInterval: 10 * time.Second,
+		},
+		{
+			results:          []error{fmt.Errorf("1"), fmt.Errorf("2"), fmt.Errorf("3"), fmt.Errorf("4"), fmt.Errorf("5"), fmt.Errorf("6"), fmt.Errorf("7"), fmt.Errorf("8")},
+			expectedReady:    false,
+			expectedInterval: DefaultMaxInterval,
+		},
+	}
+
+	for i, c := range cases {
+
+		gate := NewGate(nil, nil, log.Log)
+
+		for _, result := range c.results {
+			probeResult := result
+			gate.probe = func(ctx context.Context) error { return probeResult }
+			gate.check(context.TODO())
+		}
+
+		if gate.IsReady() != c.expectedReady || gate.interval != c.expectedInterval {
+			t.Errorf("Test case %d did not match\nExpected: %v %s\nActual: %v %s", i, c.expectedReady, c.expectedInterval, gate.IsReady(), gate.interval)
+		}
+	}
+}
+
+type prerequisite struct {
+	complete bool
+}
+
+func (p *prerequisite) Complete() (bool, string) {
+	return p.complete, "Migrations are pending"
+}
+
+func TestGatePrerequisites(t *testing.T) {
+
+	cases := []struct {
+		prerequisites   []Prerequisite
+		expectedReady   bool
+		expectedProbed  bool
+		expectedMessage string
+	}{
+		{prerequisites: []Prerequisite{&prerequisite{complete: true}}, expectedReady: true, expectedProbed: true, expectedMessage: "Quay is ready"},
+		{prerequisites: []Prerequisite{&prerequisite{complete: true}, &prerequisite{complete: false}}, expectedReady: false, expectedProbed: false, expectedMessage: "Migrations are pending"},
+	}
+
+	for i, c := range cases {
+
+		probed := false
+
+		gate := NewGate(nil, nil, log.Log)
+		gate.Prerequisites = c.prerequisites
+		gate.probe = func(ctx context.Context) error { probed = true; return nil }
+		gate.check(context.TODO())
+
+		ready, message := gate.Status()
+
+		if ready != c.expectedReady || probed != c.expectedProbed || message != c.expectedMessage {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %s\nActual: %v %v %s", i, c.expectedReady, c.expectedProbed, c.expectedMessage, ready, probed, message)
+		}
+	}
+}
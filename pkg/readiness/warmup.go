@@ -0,0 +1,75 @@
+package readiness
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Warmup spreads the first synchronization of the namespaces existing when the operator starts over a window, so that
+// restarting or upgrading the operator does not synchronize every namespace against Quay at once. Each namespace is
+// assigned a stable offset within the window derived from its name. Namespaces created after the operator started and
+// namespaces missing their Secrets are synchronized immediately
+type Warmup struct {
+	Window time.Duration
+
+	mutex       sync.Mutex
+	startedAt   time.Time
+	windowStart time.Time
+	admitted    map[string]bool
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// NewWarmup creates a Warmup spreading synchronizations over a window. Synchronizations are not deferred when the window is 0
+func NewWarmup(window time.Duration) *Warmup {
+	return &Warmup{
+		Window:    window,
+		startedAt: time.Now(),
+		admitted:  map[string]bool{},
+		now:       time.Now,
+	}
+}
+
+// Delay returns how long the synchronization of a namespace is deferred. The window starts with the first synchronization
+// so that time spent waiting for Quay to become ready is not counted. priority is only evaluated while the namespace would
+// be deferred
+func (w *Warmup) Delay(namespace string, created time.Time, priority func() bool) time.Duration {
+
+	if w == nil || w.Window <= 0 {
+		return 0
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := w.now()
+
+	if w.windowStart.IsZero() {
+		w.windowStart = now
+	}
+
+	elapsed := now.Sub(w.windowStart)
+
+	if elapsed >= w.Window || w.admitted[namespace] {
+		return 0
+	}
+
+	if offset := w.offset(namespace); offset > elapsed && !created.After(w.startedAt) && (priority == nil || !priority()) {
+		return offset - elapsed
+	}
+
+	w.admitted[namespace] = true
+
+	return 0
+}
+
+// offset returns the stable position of a namespace within the window
+func (w *Warmup) offset(namespace string) time.Duration {
+
+	hash := fnv.New32a()
+	hash.Write([]byte(namespace))
+
+	return time.Duration(float64(w.Window) * float64(hash.Sum32()) / float64(1<<32))
+}
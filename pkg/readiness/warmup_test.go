@@ -0,0 +1,83 @@
+package readiness
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWarmupDelay(t *testing.T) {
+
+	startedAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		window        time.Duration
+		elapsed       time.Duration
+		created       time.Time
+		priority      bool
+		expectedDelay bool
+	}{
+		{window: 0, elapsed: 0, created: startedAt.Add(-time.Hour), expectedDelay: false},
+		{window: time.Hour, elapsed: 0, created: startedAt.Add(-time.Hour), expectedDelay: true},
+		{window: time.Hour, elapsed: 0, created: startedAt.Add(-time.Hour), priority: true, expectedDelay: false},
+		{window: time.Hour, elapsed: 0, created: startedAt.Add(time.Minute), expectedDelay: false},
+		{window: time.Hour, elapsed: time.Hour, created: startedAt.Add(-time.Hour), expectedDelay: false},
+	}
+
+	for i, c := range cases {
+
+		warmup := NewWarmup(c.window)
+		warmup.startedAt = startedAt
+		warmup.windowStart = startedAt
+		warmup.now = func() time.Time { return startedAt.Add(c.elapsed) }
+
+		// The namespace is chosen so that its offset is well within the window
+		delay := warmup.Delay("team-b", c.created, func() bool { return c.priority })
+
+		if (delay > 0) != c.expectedDelay || delay > c.window {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expectedDelay, delay)
+		}
+	}
+}
+
+func TestWarmupSpread(t *testing.T) {
+
+	startedAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	elapsed := time.Duration(0)
+
+	warmup := NewWarmup(time.Hour)
+	warmup.startedAt = startedAt
+	warmup.now = func() time.Time { return startedAt.Add(elapsed) }
+
+	namespaces := []string{"team-a", "team-b", "team-c", "team-d", "team-e", "team-f", "team-g", "team-h"}
+	delays := map[string]time.Duration{}
+
+	for _, namespace := range namespaces {
+		delays[namespace] = warmup.Delay(namespace, startedAt.Add(-time.Hour), nil)
+	}
+
+	distinct := map[time.Duration]bool{}
+
+	for _, delay := range delays {
+		distinct[delay] = true
+	}
+
+	if len(distinct) < len(namespaces)/2 {
+		t.Errorf("Expected synchronizations to be spread over the window: %v", delays)
+	}
+
+	// Namespaces are synchronized once their offset has been reached and are no longer deferred afterwards
+	for _, namespace := range namespaces {
+
+		elapsed = delays[namespace]
+
+		if delay := warmup.Delay(namespace, startedAt.Add(-time.Hour), nil); delay != 0 {
+			t.Errorf("Expected namespace %s to be synchronized after %v, deferred by %v", namespace, delays[namespace], delay)
+		}
+
+		elapsed = 0
+
+		if delay := warmup.Delay(namespace, startedAt.Add(-time.Hour), nil); delay != 0 {
+			t.Errorf("Expected synchronized namespace %s to no longer be deferred, deferred by %v", namespace, delay)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package reconcilerbase
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// GetRESTMapping resolves the resource serving a GroupVersionKind. Mappings are discovered lazily and cached by the RESTMapper of the client,
+// which is refreshed when an unknown kind is requested
+func (r *ReconcilerBase) GetRESTMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	return r.GetClient().RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// GetDynamicClientOnGVK returns a dynamic client on the resource serving a GroupVersionKind, scoped to the namespace for namespaced resources
+func (r *ReconcilerBase) GetDynamicClientOnGVK(gvk schema.GroupVersionKind, namespace string) (dynamic.ResourceInterface, error) {
+
+	mapping, err := r.GetRESTMapping(gvk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(r.GetRestConfig())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scopeResourceInterface(dynamicClient.Resource(mapping.Resource), mapping, namespace), nil
+}
+
+// GetDynamicClientOnObject returns a dynamic client on the resource of an object, such as resources of CRDs which are not part of the scheme
+func (r *ReconcilerBase) GetDynamicClientOnObject(obj client.Object) (dynamic.ResourceInterface, error) {
+
+	gvk, err := objectGVK(obj, r.GetScheme())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetDynamicClientOnGVK(gvk, obj.GetNamespace())
+}
+
+// objectGVK returns the GroupVersionKind set on an object, falling back to the scheme for typed objects
+func objectGVK(obj runtime.Object, scheme *runtime.Scheme) (schema.GroupVersionKind, error) {
+
+	if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk, nil
+	}
+
+	return apiutil.GVKForObject(obj, scheme)
+}
+
+func scopeResourceInterface(resourceInterface dynamic.NamespaceableResourceInterface, mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return resourceInterface.Namespace(namespace)
+	}
+
+	return resourceInterface
+}
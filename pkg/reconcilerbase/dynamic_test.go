@@ -0,0 +1,138 @@
+package reconcilerbase
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	consoleLinkGVK = schema.GroupVersionKind{Group: "console.openshift.io", Version: "v1", Kind: "ConsoleLink"}
+	pipelineRunGVK = schema.GroupVersionKind{Group: "tekton.dev", Version: "v1beta1", Kind: "PipelineRun"}
+	shipwrightGVK  = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1alpha1", Kind: "Build"}
+)
+
+func newTestReconcilerBase(t *testing.T) ReconcilerBase {
+
+	scheme := runtime.NewScheme()
+
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	restMapper.Add(consoleLinkGVK, meta.RESTScopeRoot)
+	restMapper.Add(pipelineRunGVK, meta.RESTScopeNamespace)
+
+	restConfig := &rest.Config{Host: "https://127.0.0.1:6443"}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme, Mapper: restMapper})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewReconcilerBase(c, scheme, restConfig, nil, c)
+}
+
+func TestGetRESTMapping(t *testing.T) {
+
+	reconcilerBase := newTestReconcilerBase(t)
+
+	cases := []struct {
+		gvk       schema.GroupVersionKind
+		resource  string
+		namespace bool
+		available bool
+	}{
+		{
+			gvk:       corev1.SchemeGroupVersion.WithKind("Secret"),
+			resource:  "secrets",
+			namespace: true,
+			available: true,
+		},
+		{
+			gvk:       consoleLinkGVK,
+			resource:  "consolelinks",
+			namespace: false,
+			available: true,
+		},
+		{
+			gvk:       pipelineRunGVK,
+			resource:  "pipelineruns",
+			namespace: true,
+			available: true,
+		},
+		{
+			gvk:       shipwrightGVK,
+			available: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		available, err := reconcilerBase.IsAPIResourceAvailable(c.gvk)
+
+		if err != nil || available != c.available {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v (%v)", i, c.available, available, err)
+			continue
+		}
+
+		if !c.available {
+			continue
+		}
+
+		mapping, err := reconcilerBase.GetRESTMapping(c.gvk)
+
+		if err != nil || mapping.Resource.Resource != c.resource || (mapping.Scope.Name() == meta.RESTScopeNameNamespace) != c.namespace {
+			t.Errorf("Test case %d did not match\nExpected: %s %#v\nActual: %#v (%v)", i, c.resource, c.namespace, mapping, err)
+		}
+	}
+}
+
+func TestGetDynamicClientOnObject(t *testing.T) {
+
+	reconcilerBase := newTestReconcilerBase(t)
+
+	pipelineRun := &unstructured.Unstructured{}
+	pipelineRun.SetGroupVersionKind(pipelineRunGVK)
+	pipelineRun.SetNamespace("test")
+
+	shipwrightBuild := &unstructured.Unstructured{}
+	shipwrightBuild.SetGroupVersionKind(shipwrightGVK)
+
+	cases := []struct {
+		obj         client.Object
+		expectError bool
+	}{
+		{
+			obj:         &corev1.Secret{},
+			expectError: false,
+		},
+		{
+			obj:         pipelineRun,
+			expectError: false,
+		},
+		{
+			obj:         shipwrightBuild,
+			expectError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		_, err := reconcilerBase.GetDynamicClientOnObject(c.obj)
+
+		if (err != nil) != c.expectError {
+			t.Errorf("Test case %d did not match\nExpected error: %#v\nActual: %v", i, c.expectError, err)
+		}
+	}
+}
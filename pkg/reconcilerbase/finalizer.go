@@ -0,0 +1,26 @@
+package reconcilerbase
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// IsBeingDeleted returns whether an object has been marked for deletion
+func IsBeingDeleted(obj client.Object) bool {
+	return !obj.GetDeletionTimestamp().IsZero()
+}
+
+// HasFinalizer returns whether an object contains a finalizer
+func HasFinalizer(obj client.Object, finalizer string) bool {
+	return controllerutil.ContainsFinalizer(obj, finalizer)
+}
+
+// AddFinalizer adds a finalizer to an object
+func AddFinalizer(obj client.Object, finalizer string) {
+	controllerutil.AddFinalizer(obj, finalizer)
+}
+
+// RemoveFinalizer removes a finalizer from an object
+func RemoveFinalizer(obj client.Object, finalizer string) {
+	controllerutil.RemoveFinalizer(obj, finalizer)
+}
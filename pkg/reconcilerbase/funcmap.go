@@ -0,0 +1,257 @@
+package reconcilerbase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateFuncMap returns the functions available to templates rendered by the operator. Names and argument order follow Sprig
+func TemplateFuncMap() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      strings.Title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr string, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix string, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix string, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"quote":      func(s interface{}) string { return fmt.Sprintf("%q", toString(s)) },
+		"squote":     func(s interface{}) string { return fmt.Sprintf("'%s'", toString(s)) },
+		"join":       join,
+		"splitList":  func(sep string, s string) []string { return strings.Split(s, sep) },
+		"indent":     indent,
+		"nindent":    func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+
+		// Defaults
+		"default":  func(defaultValue interface{}, value interface{}) interface{} { return defaultTo(defaultValue, value) },
+		"empty":    isEmpty,
+		"coalesce": coalesce,
+		"required": required,
+
+		// Collections
+		"list": func(values ...interface{}) []interface{} { return values },
+		"dict": dict,
+
+		// Encoding
+		"b64enc":       func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec":       b64dec,
+		"sha256sum":    func(s string) string { sum := sha256.Sum256([]byte(s)); return hex.EncodeToString(sum[:]) },
+		"toJson":       toJSON,
+		"toPrettyJson": toPrettyJSON,
+		"fromJson":     fromJSON,
+		"toYaml":       toYAML,
+		"fromYaml":     fromYAML,
+
+		// Cluster, replaced by TemplateFuncMapWithLookup
+		"lookup": func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+			return nil, errors.New("lookup is not available outside of a reconciliation")
+		},
+	}
+}
+
+// TemplateFuncMapWithLookup extends TemplateFuncMap with the lookup function retrieving objects from the cluster.
+// lookup accepts an apiVersion, kind, namespace and name and returns the object, or an empty map when it does not exist.
+// When the name is empty, a list of objects is returned
+func (r *ReconcilerBase) TemplateFuncMapWithLookup(ctx context.Context) template.FuncMap {
+
+	funcMap := TemplateFuncMap()
+
+	funcMap["lookup"] = func(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+
+		gv, err := schema.ParseGroupVersion(apiVersion)
+
+		if err != nil {
+			return nil, err
+		}
+
+		resourceInterface, err := r.GetDynamicClientOnGVK(gv.WithKind(kind), namespace)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "" {
+
+			list, err := resourceInterface.List(ctx, metav1.ListOptions{})
+
+			if err != nil {
+				return nil, err
+			}
+
+			return list.UnstructuredContent(), nil
+		}
+
+		obj, err := resourceInterface.Get(ctx, name, metav1.GetOptions{})
+
+		if apierrors.IsNotFound(err) {
+			return map[string]interface{}{}, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return obj.UnstructuredContent(), nil
+	}
+
+	return funcMap
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func join(sep string, values interface{}) string {
+
+	v := reflect.ValueOf(values)
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return toString(values)
+	}
+
+	items := make([]string, v.Len())
+
+	for i := 0; i < v.Len(); i++ {
+		items[i] = toString(v.Index(i).Interface())
+	}
+
+	return strings.Join(items, sep)
+}
+
+func indent(spaces int, s string) string {
+	padding := strings.Repeat(" ", spaces)
+
+	return padding + strings.ReplaceAll(s, "\n", "\n"+padding)
+}
+
+func isEmpty(value interface{}) bool {
+
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+func defaultTo(defaultValue interface{}, value interface{}) interface{} {
+	if isEmpty(value) {
+		return defaultValue
+	}
+
+	return value
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func required(message string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, errors.New(message)
+	}
+
+	return value, nil
+}
+
+func dict(values ...interface{}) (map[string]interface{}, error) {
+
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments")
+	}
+
+	result := map[string]interface{}{}
+
+	for i := 0; i < len(values); i += 2 {
+		result[toString(values[i])] = values[i+1]
+	}
+
+	return result, nil
+}
+
+func b64dec(s string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+
+	return string(decoded), err
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+
+	return string(data), err
+}
+
+func toPrettyJSON(value interface{}) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+
+	return string(data), err
+}
+
+func fromJSON(s string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+
+	return result, json.Unmarshal([]byte(s), &result)
+}
+
+func toYAML(value interface{}) (string, error) {
+	data, err := yaml.Marshal(value)
+
+	return strings.TrimSuffix(string(data), "\n"), err
+}
+
+func fromYAML(s string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+
+	return result, yaml.Unmarshal([]byte(s), &result)
+}
@@ -0,0 +1,57 @@
+package reconcilerbase
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncMap(t *testing.T) {
+
+	cases := []struct {
+		template string
+		data     interface{}
+		expected string
+	}{
+		{template: `{{ .Name | default "fallback" }}`, data: map[string]interface{}{"Name": ""}, expected: "fallback"},
+		{template: `{{ .Name | default "fallback" }}`, data: map[string]interface{}{"Name": "quay"}, expected: "quay"},
+		{template: `{{ coalesce "" .Name "last" }}`, data: map[string]interface{}{"Name": ""}, expected: "last"},
+		{template: `{{ "Quay" | upper }}-{{ "Quay" | lower }}`, expected: "QUAY-quay"},
+		{template: `{{ "quay-bridge" | trimPrefix "quay-" }}`, expected: "bridge"},
+		{template: `{{ "a.b.c" | replace "." "-" }}`, expected: "a-b-c"},
+		{template: `{{ list "a" "b" "c" | join "," }}`, expected: "a,b,c"},
+		{template: `{{ "quay" | quote }}`, expected: `"quay"`},
+		{template: `{{ "quay" | b64enc }}`, expected: "cXVheQ=="},
+		{template: `{{ "cXVheQ==" | b64dec }}`, expected: "quay"},
+		{template: `{{ "a\nb" | indent 2 }}`, expected: "  a\n  b"},
+		{template: `key:{{ "a\nb" | nindent 2 }}`, expected: "key:\n  a\n  b"},
+		{template: `{{ dict "name" "quay" | toJson }}`, expected: `{"name":"quay"}`},
+		{template: `{{ dict "name" "quay" | toYaml }}`, expected: "name: quay"},
+		{template: `{{ (fromJson "{\"name\": \"quay\"}").name }}`, expected: "quay"},
+		{template: `{{ empty .Items }}`, data: map[string]interface{}{"Items": []string{}}, expected: "true"},
+	}
+
+	for i, c := range cases {
+
+		var result bytes.Buffer
+
+		if err := template.Must(template.New("test").Funcs(TemplateFuncMap()).Parse(c.template)).Execute(&result, c.data); err != nil {
+			t.Fatalf("Test case %d failed executing template: %v", i, err)
+		}
+
+		if result.String() != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result.String())
+		}
+	}
+}
+
+func TestTemplateFuncMapRequired(t *testing.T) {
+
+	var result bytes.Buffer
+
+	err := template.Must(template.New("test").Funcs(TemplateFuncMap()).Parse(`{{ required "name is required" .Name }}`)).Execute(&result, map[string]interface{}{"Name": ""})
+
+	if err == nil {
+		t.Errorf("Expected error for missing required value")
+	}
+}
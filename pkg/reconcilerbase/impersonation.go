@@ -0,0 +1,85 @@
+package reconcilerbase
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// impersonatingClients caches the client impersonating each user, so that clients are created once rather than on each reconcile.
+// Clients are evicted using ForgetImpersonation once the user is no longer impersonated
+type impersonatingClients struct {
+	mutex   sync.Mutex
+	clients map[string]client.Client
+}
+
+// ServiceAccountUsername returns the username a service account authenticates as
+func ServiceAccountUsername(namespace string, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// Impersonate returns a copy of the ReconcilerBase performing requests as the provided user, so that changes are attributed to
+// that user and limited to its permissions. Requests made by the copy bypass the cache. The client impersonating each user is
+// shared by the copies
+func (r *ReconcilerBase) Impersonate(username string) (ReconcilerBase, error) {
+
+	config := rest.CopyConfig(r.GetRestConfig())
+	config.Impersonate = rest.ImpersonationConfig{UserName: username}
+
+	impersonatingClient, err := r.impersonatingClient(username, config)
+
+	if err != nil {
+		return ReconcilerBase{}, err
+	}
+
+	impersonated := *r
+	impersonated.client = impersonatingClient
+	impersonated.apiReader = impersonatingClient
+	impersonated.restConfig = config
+
+	return impersonated, nil
+}
+
+// impersonatingClient returns the cached client impersonating a user, creating it when needed
+func (r *ReconcilerBase) impersonatingClient(username string, config *rest.Config) (client.Client, error) {
+
+	if r.impersonatingClients == nil {
+		return client.New(config, client.Options{Scheme: r.GetScheme(), Mapper: r.GetClient().RESTMapper()})
+	}
+
+	r.impersonatingClients.mutex.Lock()
+	defer r.impersonatingClients.mutex.Unlock()
+
+	if impersonatingClient, ok := r.impersonatingClients.clients[username]; ok {
+		return impersonatingClient, nil
+	}
+
+	if r.impersonatingClients.clients == nil {
+		r.impersonatingClients.clients = map[string]client.Client{}
+	}
+
+	impersonatingClient, err := client.New(config, client.Options{Scheme: r.GetScheme(), Mapper: r.GetClient().RESTMapper()})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.impersonatingClients.clients[username] = impersonatingClient
+
+	return impersonatingClient, nil
+}
+
+// ForgetImpersonation evicts the cached client impersonating a user, such as a service account of a deleted namespace
+func (r *ReconcilerBase) ForgetImpersonation(username string) {
+
+	if r.impersonatingClients == nil {
+		return
+	}
+
+	r.impersonatingClients.mutex.Lock()
+	defer r.impersonatingClients.mutex.Unlock()
+
+	delete(r.impersonatingClients.clients, username)
+}
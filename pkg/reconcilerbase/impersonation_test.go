@@ -0,0 +1,68 @@
+package reconcilerbase
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestImpersonate(t *testing.T) {
+
+	cases := []struct {
+		namespace string
+		name      string
+		expected  string
+	}{
+		{namespace: "test", name: "quay-bridge-operator", expected: "system:serviceaccount:test:quay-bridge-operator"},
+		{namespace: "other", name: "writer", expected: "system:serviceaccount:other:writer"},
+	}
+
+	config := &rest.Config{Host: "https://localhost:6443"}
+	scheme := runtime.NewScheme()
+
+	cachedClient, err := client.New(config, client.Options{Scheme: scheme, Mapper: meta.NewDefaultRESTMapper(nil)})
+
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	base := NewReconcilerBase(cachedClient, scheme, config, nil, cachedClient)
+
+	for i, c := range cases {
+
+		impersonated, err := base.Impersonate(ServiceAccountUsername(c.namespace, c.name))
+
+		if err != nil {
+			t.Fatalf("Test case %d failed: %v", i, err)
+		}
+
+		if impersonated.GetRestConfig().Impersonate.UserName != c.expected || base.GetRestConfig().Impersonate.UserName != "" {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, impersonated.GetRestConfig().Impersonate.UserName)
+		}
+
+		again, err := base.Impersonate(ServiceAccountUsername(c.namespace, c.name))
+
+		if err != nil {
+			t.Fatalf("Test case %d failed: %v", i, err)
+		}
+
+		if again.GetClient() != impersonated.GetClient() || impersonated.GetClient() == base.GetClient() {
+			t.Errorf("Test case %d did not reuse the client impersonating %s", i, c.expected)
+		}
+
+		base.ForgetImpersonation(c.expected)
+
+		renewed, err := base.Impersonate(ServiceAccountUsername(c.namespace, c.name))
+
+		if err != nil {
+			t.Fatalf("Test case %d failed: %v", i, err)
+		}
+
+		if renewed.GetClient() == impersonated.GetClient() {
+			t.Errorf("Test case %d reused the forgotten client impersonating %s", i, c.expected)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package reconcilerbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InventoryLabel is set on resources produced from a template to the name of the inventory tracking them
+	InventoryLabel = "quay.redhat.com/inventory"
+	// InventoryKey is the key of the inventory ConfigMap holding the resources produced from a template
+	InventoryKey = "inventory"
+
+	inventoryConfigMapSuffix = "-inventory"
+)
+
+// ObjectReference identifies a resource recorded in an inventory
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// ResourceMetadataOwner is implemented by owners stamping their labels and annotations on the resources created on their behalf
+type ResourceMetadataOwner interface {
+	ApplyResourceMetadata(obj metav1.Object)
+}
+
+// NewObjectReference returns the reference of a resource
+func NewObjectReference(obj *unstructured.Unstructured) ObjectReference {
+	return ObjectReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// GroupVersionKind returns the GroupVersionKind of the referenced resource
+func (o ObjectReference) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(o.APIVersion, o.Kind)
+}
+
+// String returns a human readable representation of the reference
+func (o ObjectReference) String() string {
+	return fmt.Sprintf("%s/%s %s", o.APIVersion, o.Kind, types.NamespacedName{Namespace: o.Namespace, Name: o.Name})
+}
+
+// ReconcileTemplatedResources applies the resources rendered from a template and prunes the resources rendered by a previous
+// reconciliation which are no longer produced. Rendered resources are recorded in a ConfigMap named after the inventory in the namespace
+func (r *ReconcilerBase) ReconcileTemplatedResources(ctx context.Context, owner client.Object, namespace string, inventory string, data interface{}, tmpl *template.Template) ([]ResourceResult, error) {
+
+	objs, err := r.renderTemplatedResources(ctx, data, tmpl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace != "" {
+		for i := range objs {
+			objs[i].SetNamespace(namespace)
+		}
+	}
+
+	return r.ReconcileResources(ctx, owner, namespace, inventory, objs)
+}
+
+// ReconcileResources applies resources and prunes the resources applied by a previous reconciliation which are no longer desired.
+// Resources keep their own namespace while the inventory is recorded in a ConfigMap in the provided namespace. Every resource is
+// attempted, returning the outcome of each applied and pruned resource along with an aggregate of the errors
+func (r *ReconcilerBase) ReconcileResources(ctx context.Context, owner client.Object, namespace string, inventory string, objs []unstructured.Unstructured) ([]ResourceResult, error) {
+
+	current := []ObjectReference{}
+
+	for i := range objs {
+
+		labels := objs[i].GetLabels()
+
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		labels[InventoryLabel] = inventory
+		objs[i].SetLabels(labels)
+
+		current = append(current, NewObjectReference(&objs[i]))
+	}
+
+	results, _ := r.ApplyResources(ctx, owner, "", toObjects(objs))
+
+	previous, err := r.readInventory(ctx, namespace, inventory)
+
+	if err != nil {
+		return results, err
+	}
+
+	stale := PruneCandidates(previous, current)
+
+	for _, ref := range stale {
+		results = append(results, ResourceResult{Reference: ref, Error: r.pruneResource(ctx, inventory, ref)})
+	}
+
+	// Resources which could not be applied or pruned remain in the inventory so they are retried on the next reconciliation
+	for _, result := range FailedResults(results[len(objs):]) {
+		current = append(current, result.Reference)
+	}
+
+	if err := r.writeInventory(ctx, owner, namespace, inventory, current); err != nil {
+		return results, err
+	}
+
+	return results, AggregateResults(results)
+}
+
+// PruneCandidates returns the references of a previous inventory which are not part of the current inventory
+func PruneCandidates(previous []ObjectReference, current []ObjectReference) []ObjectReference {
+
+	rendered := map[ObjectReference]bool{}
+
+	for _, ref := range current {
+		rendered[ref] = true
+	}
+
+	candidates := []ObjectReference{}
+
+	for _, ref := range previous {
+		if !rendered[ref] {
+			candidates = append(candidates, ref)
+		}
+	}
+
+	return candidates
+}
+
+// pruneResource deletes a stale resource, provided it is still labeled as part of the inventory
+func (r *ReconcilerBase) pruneResource(ctx context.Context, inventory string, ref ObjectReference) error {
+
+	resourceInterface, err := r.GetDynamicClientOnGVK(ref.GroupVersionKind(), ref.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	obj, err := resourceInterface.Get(ctx, ref.Name, metav1.GetOptions{})
+
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if obj.GetLabels()[InventoryLabel] != inventory {
+		return nil
+	}
+
+	if err := resourceInterface.Delete(ctx, ref.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReconcilerBase) readInventory(ctx context.Context, namespace string, inventory string) ([]ObjectReference, error) {
+
+	configMap := &corev1.ConfigMap{}
+
+	err := r.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace, Name: inventory + inventoryConfigMapSuffix}, configMap)
+
+	if apierrors.IsNotFound(err) {
+		return []ObjectReference{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []ObjectReference{}
+
+	if err := json.Unmarshal([]byte(configMap.Data[InventoryKey]), &refs); err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+func (r *ReconcilerBase) writeInventory(ctx context.Context, owner client.Object, namespace string, inventory string, refs []ObjectReference) error {
+
+	data, err := json.Marshal(refs)
+
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: inventory + inventoryConfigMapSuffix,
+			Labels: map[string]string{
+				InventoryLabel: inventory,
+			},
+		},
+		Data: map[string]string{
+			InventoryKey: string(data),
+		},
+	}
+
+	if metadataOwner, ok := owner.(ResourceMetadataOwner); ok {
+		metadataOwner.ApplyResourceMetadata(configMap)
+	}
+
+	return r.ApplyResource(ctx, owner, namespace, configMap)
+}
@@ -0,0 +1,46 @@
+package reconcilerbase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPruneCandidates(t *testing.T) {
+
+	configMap := ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "test", Name: "test"}
+	secret := ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "test", Name: "test"}
+	otherNamespace := ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "other", Name: "test"}
+
+	cases := []struct {
+		previous []ObjectReference
+		current  []ObjectReference
+		expected []ObjectReference
+	}{
+		{
+			previous: []ObjectReference{},
+			current:  []ObjectReference{configMap},
+			expected: []ObjectReference{},
+		},
+		{
+			previous: []ObjectReference{configMap, secret},
+			current:  []ObjectReference{configMap, secret},
+			expected: []ObjectReference{},
+		},
+		{
+			previous: []ObjectReference{configMap, secret},
+			current:  []ObjectReference{configMap},
+			expected: []ObjectReference{secret},
+		},
+		{
+			previous: []ObjectReference{secret},
+			current:  []ObjectReference{otherNamespace},
+			expected: []ObjectReference{secret},
+		},
+	}
+
+	for i, c := range cases {
+		if result := PruneCandidates(c.previous, c.current); !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
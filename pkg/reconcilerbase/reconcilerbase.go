@@ -0,0 +1,298 @@
+package reconcilerbase
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// FieldOwner is the field manager used when applying resources
+	FieldOwner = "quay-bridge-operator"
+
+	// ReconcileSuccessConditionType is reported on objects implementing ConditionsAware after each reconciliation
+	ReconcileSuccessConditionType = "ReconcileSuccess"
+	// ReconcileSuccessReason is the reason of a successful reconciliation
+	ReconcileSuccessReason = "LastReconcileCycleSucceded"
+	// ReconcileErrorReason is the reason of a failed reconciliation
+	ReconcileErrorReason = "LastReconcileCycleFailed"
+
+	operatorNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// ConditionsAware is implemented by objects reporting metav1.Conditions on their status
+type ConditionsAware interface {
+	GetConditions() []metav1.Condition
+	SetConditions(conditions []metav1.Condition)
+}
+
+// ReconcilerBase provides the clients and helpers shared by the reconcilers of the operator
+type ReconcilerBase struct {
+	apiReader  client.Reader
+	client     client.Client
+	scheme     *runtime.Scheme
+	restConfig *rest.Config
+	recorder   record.EventRecorder
+
+	impersonatingClients *impersonatingClients
+}
+
+// NewReconcilerBase creates a ReconcilerBase
+func NewReconcilerBase(client client.Client, scheme *runtime.Scheme, restConfig *rest.Config, recorder record.EventRecorder, apiReader client.Reader) ReconcilerBase {
+	return ReconcilerBase{
+		apiReader:            apiReader,
+		client:               client,
+		scheme:               scheme,
+		restConfig:           restConfig,
+		recorder:             recorder,
+		impersonatingClients: &impersonatingClients{},
+	}
+}
+
+// NewFromManager creates a ReconcilerBase using the clients of a Manager
+func NewFromManager(mgr manager.Manager, recorder record.EventRecorder) ReconcilerBase {
+	return NewReconcilerBase(mgr.GetClient(), mgr.GetScheme(), mgr.GetConfig(), recorder, mgr.GetAPIReader())
+}
+
+// GetClient returns the cached client
+func (r *ReconcilerBase) GetClient() client.Client {
+	return r.client
+}
+
+// GetAPIReader returns a reader bypassing the cache
+func (r *ReconcilerBase) GetAPIReader() client.Reader {
+	return r.apiReader
+}
+
+// GetScheme returns the scheme
+func (r *ReconcilerBase) GetScheme() *runtime.Scheme {
+	return r.scheme
+}
+
+// GetRestConfig returns the rest config
+func (r *ReconcilerBase) GetRestConfig() *rest.Config {
+	return r.restConfig
+}
+
+// GetRecorder returns the event recorder
+func (r *ReconcilerBase) GetRecorder() record.EventRecorder {
+	return r.recorder
+}
+
+// GetDiscoveryClient returns a discovery client
+func (r *ReconcilerBase) GetDiscoveryClient() (*discovery.DiscoveryClient, error) {
+	return discovery.NewDiscoveryClientForConfig(r.GetRestConfig())
+}
+
+// IsAPIResourceAvailable returns whether a GroupVersionKind is served by the API server
+func (r *ReconcilerBase) IsAPIResourceAvailable(gvk schema.GroupVersionKind) (bool, error) {
+
+	if _, err := r.GetRESTMapping(gvk); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CreateOrUpdateResource creates a resource or overwrites it when it already exists.
+// The owner, when provided, is set as the controller of the resource and a non empty namespace overrides the namespace of the resource
+func (r *ReconcilerBase) CreateOrUpdateResource(ctx context.Context, owner client.Object, namespace string, obj client.Object) error {
+
+	if err := r.prepare(owner, namespace, obj); err != nil {
+		return err
+	}
+
+	// The resource version is read again from the API server when the update conflicts with a write of another actor,
+	// as the cache may not have observed the write yet
+	var reader client.Reader = r.GetClient()
+
+	return RetryOnConflict(DefaultRetry, func() error {
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+		err := reader.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+		reader = r.GetAPIReader()
+
+		if apierrors.IsNotFound(err) {
+			return r.GetClient().Create(ctx, obj)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		obj.SetResourceVersion(existing.GetResourceVersion())
+
+		return r.GetClient().Update(ctx, obj)
+	})
+}
+
+// ApplyResource applies a resource using server-side apply. Only the fields set on the resource are owned by the operator,
+// leaving fields managed by other actors untouched
+func (r *ReconcilerBase) ApplyResource(ctx context.Context, owner client.Object, namespace string, obj client.Object) error {
+
+	if err := r.prepare(owner, namespace, obj); err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	return r.GetClient().Patch(ctx, obj, client.Apply, client.FieldOwner(FieldOwner), client.ForceOwnership)
+}
+
+// ApplyTemplatedResources renders a template producing one or more resources and applies each of them, returning the outcome of each.
+// Templates parsed with TemplateFuncMap can use lookup to retrieve objects from the cluster
+func (r *ReconcilerBase) ApplyTemplatedResources(ctx context.Context, owner client.Object, namespace string, data interface{}, tmpl *template.Template) ([]ResourceResult, error) {
+
+	objs, err := r.renderTemplatedResources(ctx, data, tmpl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ApplyResources(ctx, owner, namespace, toObjects(objs))
+}
+
+// renderTemplatedResources renders a template with the lookup function bound to the cluster
+func (r *ReconcilerBase) renderTemplatedResources(ctx context.Context, data interface{}, tmpl *template.Template) ([]unstructured.Unstructured, error) {
+
+	tmpl, err := tmpl.Clone()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ProcessTemplateArray(data, tmpl.Funcs(r.TemplateFuncMapWithLookup(ctx)))
+}
+
+// DeleteResourceIfExists deletes a resource, ignoring resources which do not exist
+func (r *ReconcilerBase) DeleteResourceIfExists(ctx context.Context, obj client.Object) error {
+
+	if err := r.GetClient().Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// ManageError records a warning event and a failed ReconcileSuccess condition on objects implementing ConditionsAware
+func (r *ReconcilerBase) ManageError(ctx context.Context, obj client.Object, issue error) (reconcile.Result, error) {
+
+	// Conflicts with writes of other actors are expected and retried promptly without failing the condition
+	if apierrors.IsConflict(issue) {
+		r.GetRecorder().Event(obj, "Normal", ConflictReason, issue.Error())
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	r.GetRecorder().Event(obj, "Warning", ReconcileErrorReason, issue.Error())
+
+	if err := r.setReconcileCondition(ctx, obj, metav1.ConditionFalse, ReconcileErrorReason, issue.Error()); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, issue
+}
+
+// ManageSuccess records a successful ReconcileSuccess condition on objects implementing ConditionsAware
+func (r *ReconcilerBase) ManageSuccess(ctx context.Context, obj client.Object) (reconcile.Result, error) {
+
+	if err := r.setReconcileCondition(ctx, obj, metav1.ConditionTrue, ReconcileSuccessReason, ""); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcilerBase) setReconcileCondition(ctx context.Context, obj client.Object, status metav1.ConditionStatus, reason string, message string) error {
+
+	conditionsAware, ok := obj.(ConditionsAware)
+
+	if !ok {
+		return nil
+	}
+
+	return r.UpdateResourceStatus(ctx, obj, func() error {
+
+		conditions := conditionsAware.GetConditions()
+
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               ReconcileSuccessConditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: obj.GetGeneration(),
+		})
+
+		conditionsAware.SetConditions(conditions)
+
+		return nil
+	})
+}
+
+// GetOperatorNamespace returns the namespace the operator is running in, falling back to the NAMESPACE environment variable when running locally
+func (r *ReconcilerBase) GetOperatorNamespace() (string, error) {
+	return OperatorNamespace()
+}
+
+// OperatorNamespace returns the namespace the operator is running in, falling back to the NAMESPACE environment variable when running locally
+func OperatorNamespace() (string, error) {
+
+	namespace, err := ioutil.ReadFile(operatorNamespaceFile)
+
+	if err == nil {
+		return strings.TrimSpace(string(namespace)), nil
+	}
+
+	if namespace, ok := os.LookupEnv("NAMESPACE"); ok {
+		return namespace, nil
+	}
+
+	return "", errors.New("unable to infer namespace in which operator is running")
+}
+
+// prepare sets the owner, namespace and GroupVersionKind of a resource prior to writing it
+func (r *ReconcilerBase) prepare(owner client.Object, namespace string, obj client.Object) error {
+
+	if owner != nil {
+		if err := controllerutil.SetControllerReference(owner, obj, r.GetScheme()); err != nil {
+			return err
+		}
+	}
+
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+
+	gvk, err := objectGVK(obj, r.GetScheme())
+
+	if err != nil {
+		return err
+	}
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package reconcilerbase
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceResult is the outcome of writing or deleting a single resource
+type ResourceResult struct {
+	Reference ObjectReference
+	Error     error
+}
+
+// FailedResults returns the results of the resources which could not be written or deleted
+func FailedResults(results []ResourceResult) []ResourceResult {
+
+	failed := []ResourceResult{}
+
+	for _, result := range results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// AggregateResults returns an aggregate of the errors of the results, naming the resource each error occurred on, or nil when every resource succeeded
+func AggregateResults(results []ResourceResult) error {
+
+	errs := []error{}
+
+	for _, result := range FailedResults(results) {
+		errs = append(errs, fmt.Errorf("%s: %v", result.Reference, result.Error))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// CreateOrUpdateResources creates or overwrites each resource using CreateOrUpdateResource. Every resource is attempted,
+// returning the outcome of each along with an aggregate of the errors
+func (r *ReconcilerBase) CreateOrUpdateResources(ctx context.Context, owner client.Object, namespace string, objs []client.Object) ([]ResourceResult, error) {
+	return r.forEachResource(objs, func(obj client.Object) error {
+		return r.CreateOrUpdateResource(ctx, owner, namespace, obj)
+	})
+}
+
+// ApplyResources applies each resource using ApplyResource. Every resource is attempted, returning the outcome of each
+// along with an aggregate of the errors
+func (r *ReconcilerBase) ApplyResources(ctx context.Context, owner client.Object, namespace string, objs []client.Object) ([]ResourceResult, error) {
+	return r.forEachResource(objs, func(obj client.Object) error {
+		return r.ApplyResource(ctx, owner, namespace, obj)
+	})
+}
+
+// DeleteResources deletes each resource using DeleteResourceIfExists. Every resource is attempted, returning the outcome
+// of each along with an aggregate of the errors
+func (r *ReconcilerBase) DeleteResources(ctx context.Context, objs []client.Object) ([]ResourceResult, error) {
+	return r.forEachResource(objs, func(obj client.Object) error {
+		return r.DeleteResourceIfExists(ctx, obj)
+	})
+}
+
+func (r *ReconcilerBase) forEachResource(objs []client.Object, fn func(obj client.Object) error) ([]ResourceResult, error) {
+
+	results := []ResourceResult{}
+
+	for _, obj := range objs {
+
+		err := fn(obj)
+
+		results = append(results, ResourceResult{Reference: r.objectReference(obj), Error: err})
+	}
+
+	return results, AggregateResults(results)
+}
+
+// objectReference returns the reference of a resource, resolving the GroupVersionKind of typed objects from the scheme
+func (r *ReconcilerBase) objectReference(obj client.Object) ObjectReference {
+
+	ref := ObjectReference{
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+
+	if gvk, err := objectGVK(obj, r.GetScheme()); err == nil {
+		ref.APIVersion, ref.Kind = gvk.ToAPIVersionAndKind()
+	}
+
+	return ref
+}
+
+func toObjects(objs []unstructured.Unstructured) []client.Object {
+
+	result := []client.Object{}
+
+	for i := range objs {
+		result = append(result, &objs[i])
+	}
+
+	return result
+}
@@ -0,0 +1,54 @@
+package reconcilerbase
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAggregateResults(t *testing.T) {
+
+	configMap := ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "test", Name: "test"}
+	secret := ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "test", Name: "test"}
+
+	cases := []struct {
+		results  []ResourceResult
+		failed   int
+		expected string
+	}{
+		{
+			results:  []ResourceResult{},
+			failed:   0,
+			expected: "",
+		},
+		{
+			results:  []ResourceResult{{Reference: configMap}, {Reference: secret}},
+			failed:   0,
+			expected: "",
+		},
+		{
+			results:  []ResourceResult{{Reference: configMap, Error: fmt.Errorf("forbidden")}, {Reference: secret}},
+			failed:   1,
+			expected: "v1/ConfigMap test/test: forbidden",
+		},
+		{
+			results:  []ResourceResult{{Reference: configMap, Error: fmt.Errorf("forbidden")}, {Reference: secret, Error: fmt.Errorf("conflict")}},
+			failed:   2,
+			expected: "[v1/ConfigMap test/test: forbidden, v1/Secret test/test: conflict]",
+		},
+	}
+
+	for i, c := range cases {
+
+		err := AggregateResults(c.results)
+
+		actual := ""
+
+		if err != nil {
+			actual = err.Error()
+		}
+
+		if failed := len(FailedResults(c.results)); failed != c.failed || actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %d %s\nActual: %d %s", i, c.failed, c.expected, failed, actual)
+		}
+	}
+}
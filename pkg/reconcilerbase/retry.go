@@ -0,0 +1,89 @@
+package reconcilerbase
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConflictReason is the reason of events recorded when an update keeps conflicting with writes of other actors
+	ConflictReason = "UpdateConflict"
+)
+
+// DefaultRetry is the backoff applied to updates conflicting with writes of other actors
+var DefaultRetry = wait.Backoff{
+	Steps:    5,
+	Duration: 10 * time.Millisecond,
+	Factor:   1.0,
+	Jitter:   0.1,
+}
+
+// RetryOnConflict runs fn, retrying with backoff while it fails with a conflict. The last conflict is returned once the backoff is exhausted
+func RetryOnConflict(backoff wait.Backoff, fn func() error) error {
+
+	var lastErr error
+
+	err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+
+		lastErr = fn()
+
+		if lastErr == nil {
+			return true, nil
+		}
+
+		if apierrors.IsConflict(lastErr) {
+			return false, nil
+		}
+
+		return false, lastErr
+	})
+
+	if err == wait.ErrWaitTimeout {
+		return lastErr
+	}
+
+	return err
+}
+
+// UpdateResource applies mutate to a resource and updates it. When the update conflicts, the resource is read again
+// from the API server and mutate reapplied before retrying
+func (r *ReconcilerBase) UpdateResource(ctx context.Context, obj client.Object, mutate func() error) error {
+	return r.updateOnConflict(ctx, obj, mutate, func() error {
+		return r.GetClient().Update(ctx, obj)
+	})
+}
+
+// UpdateResourceStatus applies mutate to a resource and updates its status. When the update conflicts, the resource is
+// read again from the API server and mutate reapplied before retrying
+func (r *ReconcilerBase) UpdateResourceStatus(ctx context.Context, obj client.Object, mutate func() error) error {
+	return r.updateOnConflict(ctx, obj, mutate, func() error {
+		return r.GetClient().Status().Update(ctx, obj)
+	})
+}
+
+func (r *ReconcilerBase) updateOnConflict(ctx context.Context, obj client.Object, mutate func() error, update func() error) error {
+
+	attempt := 0
+
+	return RetryOnConflict(DefaultRetry, func() error {
+
+		// The cache may not have observed the conflicting write yet
+		if attempt > 0 {
+			if err := r.GetAPIReader().Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+		}
+
+		attempt++
+
+		if err := mutate(); err != nil {
+			return err
+		}
+
+		return update()
+	})
+}
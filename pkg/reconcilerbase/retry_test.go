@@ -0,0 +1,110 @@
+package reconcilerbase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, "test", fmt.Errorf("the object has been modified"))
+	failure := fmt.Errorf("forbidden")
+
+	backoff := wait.Backoff{Steps: 3, Duration: time.Millisecond, Factor: 1.0}
+
+	cases := []struct {
+		errs             []error
+		expectedErr      error
+		expectedAttempts int
+	}{
+		{
+			errs:             []error{nil},
+			expectedErr:      nil,
+			expectedAttempts: 1,
+		},
+		{
+			errs:             []error{conflict, conflict, nil},
+			expectedErr:      nil,
+			expectedAttempts: 3,
+		},
+		{
+			errs:             []error{conflict, failure},
+			expectedErr:      failure,
+			expectedAttempts: 2,
+		},
+		{
+			errs:             []error{conflict, conflict, conflict},
+			expectedErr:      conflict,
+			expectedAttempts: 3,
+		},
+	}
+
+	for i, c := range cases {
+
+		attempts := 0
+
+		err := RetryOnConflict(backoff, func() error {
+			attempts++
+			return c.errs[attempts-1]
+		})
+
+		if err != c.expectedErr || attempts != c.expectedAttempts {
+			t.Errorf("Test case %d did not match\nExpected: %v %d\nActual: %v %d", i, c.expectedErr, c.expectedAttempts, err, attempts)
+		}
+	}
+}
+
+// versionedClient returns objects at a fixed resource version, and rejects updates of other versions than the latest one
+type versionedClient struct {
+	client.Client
+	resourceVersion string
+	latestVersion   string
+	updates         int
+}
+
+func (c *versionedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	obj.SetResourceVersion(c.resourceVersion)
+	return nil
+}
+
+func (c *versionedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+
+	if obj.GetResourceVersion() != c.latestVersion {
+		return apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, obj.GetName(), fmt.Errorf("the object has been modified"))
+	}
+
+	c.updates++
+
+	return nil
+}
+
+func TestUpdateResourceReadsAPIServerOnConflict(t *testing.T) {
+
+	// The cache has not observed the latest version of the Secret
+	cachedClient := &versionedClient{resourceVersion: "1", latestVersion: "2"}
+	apiReader := &versionedClient{resourceVersion: "2"}
+
+	reconcilerBase := NewReconcilerBase(cachedClient, nil, nil, nil, apiReader)
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", ResourceVersion: "1"}}
+
+	mutations := 0
+
+	err := reconcilerBase.UpdateResource(context.Background(), secret, func() error {
+		mutations++
+		return nil
+	})
+
+	if err != nil || mutations != 2 || cachedClient.updates != 1 {
+		t.Errorf("Expected update to be retried with the resource read from the API server, got %d mutations and %d updates: %v", mutations, cachedClient.updates, err)
+	}
+}
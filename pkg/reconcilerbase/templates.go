@@ -0,0 +1,114 @@
+package reconcilerbase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// ProcessTemplateArray renders a template producing one or more YAML documents. Each document may contain a single resource,
+// a list of resources or a List kind whose items are returned individually
+func ProcessTemplateArray(data interface{}, tmpl *template.Template) ([]unstructured.Unstructured, error) {
+
+	var rendered bytes.Buffer
+
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, err
+	}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(&rendered))
+
+	objs := []unstructured.Unstructured{}
+
+	for {
+
+		document, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		documentObjs, err := processDocument(document)
+
+		if err != nil {
+			return nil, err
+		}
+
+		objs = append(objs, documentObjs...)
+	}
+
+	return objs, nil
+}
+
+func processDocument(document []byte) ([]unstructured.Unstructured, error) {
+
+	manifest, err := yaml.YAMLToJSON(document)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents containing only comments or whitespace
+	if trimmed := bytes.TrimSpace(manifest); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	if !IsJSONArray(manifest) {
+
+		obj := unstructured.Unstructured{}
+
+		if err := obj.UnmarshalJSON(manifest); err != nil {
+			return nil, err
+		}
+
+		if !obj.IsList() {
+			return []unstructured.Unstructured{obj}, nil
+		}
+
+		list, err := obj.ToList()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return list.Items, nil
+	}
+
+	items := []json.RawMessage{}
+
+	if err := json.Unmarshal(manifest, &items); err != nil {
+		return nil, err
+	}
+
+	objs := []unstructured.Unstructured{}
+
+	for _, item := range items {
+
+		obj := unstructured.Unstructured{}
+
+		if err := obj.UnmarshalJSON(item); err != nil {
+			return nil, err
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// IsJSONArray returns whether a JSON document is an array
+func IsJSONArray(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
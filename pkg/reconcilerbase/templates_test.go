@@ -0,0 +1,118 @@
+package reconcilerbase
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestProcessTemplateArray(t *testing.T) {
+
+	cases := []struct {
+		template string
+		expected []string
+	}{
+		{
+			template: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+`,
+			expected: []string{"ConfigMap/test"},
+		},
+		{
+			template: `
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: {{ .Name }}
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: {{ .Name }}
+`,
+			expected: []string{"ConfigMap/test", "Secret/test"},
+		},
+		{
+			template: `[]`,
+			expected: []string{},
+		},
+		{
+			template: `
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+---
+# Empty document
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Name }}
+`,
+			expected: []string{"ConfigMap/test", "Secret/test"},
+		},
+		{
+			template: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: {{ .Name }}
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: {{ .Name | upper | lower }}
+`,
+			expected: []string{"ConfigMap/test", "Secret/test"},
+		},
+	}
+
+	for i, c := range cases {
+
+		objs, err := ProcessTemplateArray(struct{ Name string }{Name: "test"}, template.Must(template.New("test").Funcs(TemplateFuncMap()).Parse(c.template)))
+
+		if err != nil {
+			t.Fatalf("Test case %d failed processing template: %v", i, err)
+		}
+
+		result := []string{}
+
+		for _, obj := range objs {
+			result = append(result, obj.GetKind()+"/"+obj.GetName())
+		}
+
+		if len(result) != len(c.expected) {
+			t.Fatalf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+
+		for j := range result {
+			if result[j] != c.expected[j] {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		}
+	}
+}
+
+func TestIsJSONArray(t *testing.T) {
+
+	cases := []struct {
+		data     string
+		expected bool
+	}{
+		{data: `[{"kind": "ConfigMap"}]`, expected: true},
+		{data: "  \n[]", expected: true},
+		{data: `{"kind": "ConfigMap"}`, expected: false},
+		{data: "", expected: false},
+	}
+
+	for i, c := range cases {
+		if result := IsJSONArray([]byte(c.data)); result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+		}
+	}
+}
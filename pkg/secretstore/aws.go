@@ -0,0 +1,274 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// AWSAccessKeyIDKey is the key of the access key ID within the referenced Secret
+	AWSAccessKeyIDKey = "aws_access_key_id"
+	// AWSSecretAccessKeyKey is the key of the secret access key within the referenced Secret
+	AWSSecretAccessKeyKey = "aws_secret_access_key"
+	// AWSSessionTokenKey is the key of the optional session token within the referenced Secret
+	AWSSessionTokenKey = "aws_session_token"
+
+	secretsManagerService = "secretsmanager"
+	amzDateFormat         = "20060102T150405Z"
+)
+
+// AWSCredentials are the credentials signing requests to AWS
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSSecretsManagerStore writes credentials to AWS Secrets Manager. The credentials stored at a path are the JSON encoded
+// SecretString of the secret named after the path
+type AWSSecretsManagerStore struct {
+	Client      *http.Client
+	Region      string
+	Credentials AWSCredentials
+	// Endpoint overrides the regional endpoint, such as for VPC endpoints
+	Endpoint string
+
+	now func() time.Time
+}
+
+var _ Store = &AWSSecretsManagerStore{}
+
+// awsError is the error returned by AWS JSON APIs
+type awsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Write creates or replaces the credentials stored at a path
+func (s *AWSSecretsManagerStore) Write(ctx context.Context, path string, data map[string]string) error {
+
+	secretString, err := json.Marshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.call(ctx, "PutSecretValue", map[string]interface{}{"SecretId": path, "SecretString": string(secretString)})
+
+	if !isAWSErrorType(err, "ResourceNotFoundException") {
+		return err
+	}
+
+	return s.call(ctx, "CreateSecret", map[string]interface{}{"Name": path, "SecretString": string(secretString), "Description": "Robot account credentials managed by the quay-bridge-operator"})
+}
+
+// Delete deletes the credentials stored at a path without a recovery window
+func (s *AWSSecretsManagerStore) Delete(ctx context.Context, path string) error {
+
+	err := s.call(ctx, "DeleteSecret", map[string]interface{}{"SecretId": path, "ForceDeleteWithoutRecovery": true})
+
+	if isAWSErrorType(err, "ResourceNotFoundException") {
+		return nil
+	}
+
+	return err
+}
+
+// call invokes an action of the JSON API of AWS Secrets Manager
+func (s *AWSSecretsManagerStore) call(ctx context.Context, action string, input interface{}) error {
+
+	client := &AWSJSONClient{
+		Client:       s.Client,
+		Region:       s.Region,
+		Credentials:  s.Credentials,
+		Endpoint:     s.Endpoint,
+		Service:      secretsManagerService,
+		TargetPrefix: secretsManagerService,
+		now:          s.now,
+	}
+
+	return client.Call(ctx, action, input, nil)
+}
+
+// AWSJSONClient invokes the actions of an AWS JSON API, such as AWS Secrets Manager or AWS KMS
+type AWSJSONClient struct {
+	Client      *http.Client
+	Region      string
+	Credentials AWSCredentials
+	// Endpoint overrides the regional endpoint of the service
+	Endpoint string
+	// Service is the name of the service in endpoints and signatures, such as kms
+	Service string
+	// TargetPrefix prefixes the action in the X-Amz-Target header, such as TrentService
+	TargetPrefix string
+
+	now func() time.Time
+}
+
+// Call invokes an action, decoding the response into output when set
+func (c *AWSJSONClient) Call(ctx context.Context, action string, input interface{}, output interface{}) error {
+
+	body, err := json.Marshal(input)
+
+	if err != nil {
+		return err
+	}
+
+	endpoint := c.Endpoint
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", c.Service, c.Region)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", c.TargetPrefix+"."+action)
+
+	now := time.Now
+
+	if c.now != nil {
+		now = c.now
+	}
+
+	signRequest(req, body, c.Credentials, c.Region, c.Service, now().UTC())
+
+	resp, err := c.Client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+
+		if output != nil {
+			return json.NewDecoder(resp.Body).Decode(output)
+		}
+
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	content, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	apiErr := &AWSError{StatusCode: resp.StatusCode}
+	parsed := awsError{}
+
+	if json.Unmarshal(content, &parsed) == nil {
+		// Types may be prefixed with a namespace, such as com.amazonaws.secretsmanager#ResourceNotFoundException
+		apiErr.Type = parsed.Type[strings.LastIndex(parsed.Type, "#")+1:]
+		apiErr.Message = parsed.Message
+	}
+
+	return apiErr
+}
+
+// AWSError is an error returned by AWS
+type AWSError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *AWSError) Error() string {
+	return fmt.Sprintf("aws returned status code %d: %s %s", e.StatusCode, e.Type, e.Message)
+}
+
+func isAWSErrorType(err error, errorType string) bool {
+	apiErr, ok := err.(*AWSError)
+	return ok && apiErr.Type == errorType
+}
+
+// signRequest signs a request using AWS Signature Version 4
+func signRequest(req *http.Request, body []byte, credentials AWSCredentials, region string, service string, t time.Time) {
+
+	amzDate := t.Format(amzDateFormat)
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	canonicalHeaders := strings.Builder{}
+
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+
+	if path == "" {
+		path = "/"
+	}
+
+	bodyHash := sha256.Sum256(body)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
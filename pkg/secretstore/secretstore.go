@@ -0,0 +1,116 @@
+// Package secretstore publishes the credentials of robot accounts to external secret stores, such as HashiCorp Vault or AWS
+// Secrets Manager, for clusters whose security policies forbid long-lived credentials in Secrets. Tools such as ExternalSecrets
+// read the credentials from the store
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+)
+
+const (
+	// DefaultPathTemplate is the template of the path of credentials within the store when PathTemplate is not set
+	DefaultPathTemplate = "quay/{{ .Namespace }}/{{ .SecretName }}"
+)
+
+// Store writes and deletes the credentials stored at a path of an external secret store
+type Store interface {
+	Write(ctx context.Context, path string, data map[string]string) error
+	Delete(ctx context.Context, path string) error
+}
+
+// PathTemplateData is the data available to the template of the path of credentials within the store
+type PathTemplateData struct {
+	OrgName    string
+	Namespace  string
+	SecretName string
+	ClusterID  string
+}
+
+// RenderPath renders the path of credentials within the store from a template
+func RenderPath(pathTemplate string, data PathTemplateData) (string, error) {
+
+	if pathTemplate == "" {
+		pathTemplate = DefaultPathTemplate
+	}
+
+	tmpl, err := template.New("path").Parse(pathTemplate)
+
+	if err != nil {
+		return "", err
+	}
+
+	var path strings.Builder
+
+	if err := tmpl.Execute(&path, data); err != nil {
+		return "", err
+	}
+
+	rendered := strings.Trim(strings.TrimSpace(path.String()), "/")
+
+	if rendered == "" || strings.Contains(rendered, "//") {
+		return "", fmt.Errorf("invalid secret store path '%s'", rendered)
+	}
+
+	return rendered, nil
+}
+
+// Actuator is the SecretActuator writing the credentials of the Secrets of a namespace to a Store instead of the cluster
+type Actuator struct {
+	Store        Store
+	PathTemplate string
+	OrgName      string
+	ClusterID    string
+}
+
+var _ actuator.SecretActuator = &Actuator{}
+
+// ApplySecret writes the data of a Secret to the store
+func (a *Actuator) ApplySecret(ctx context.Context, writer reconcilerbase.ReconcilerBase, namespace string, secret *corev1.Secret) error {
+
+	path, err := a.path(namespace, secret.Name)
+
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{}
+
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+
+	for key, value := range secret.StringData {
+		data[key] = value
+	}
+
+	return a.Store.Write(ctx, path, data)
+}
+
+// DeleteSecret deletes the data of a Secret from the store
+func (a *Actuator) DeleteSecret(ctx context.Context, writer reconcilerbase.ReconcilerBase, secret *corev1.Secret) error {
+
+	path, err := a.path(secret.Namespace, secret.Name)
+
+	if err != nil {
+		return err
+	}
+
+	return a.Store.Delete(ctx, path)
+}
+
+func (a *Actuator) path(namespace string, secretName string) (string, error) {
+	return RenderPath(a.PathTemplate, PathTemplateData{
+		OrgName:    a.OrgName,
+		Namespace:  namespace,
+		SecretName: secretName,
+		ClusterID:  a.ClusterID,
+	})
+}
@@ -0,0 +1,234 @@
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+)
+
+func TestRenderPath(t *testing.T) {
+
+	cases := []struct {
+		pathTemplate  string
+		expected      string
+		expectedError bool
+	}{
+		{
+			pathTemplate: "",
+			expected:     "quay/app/builder-quay-cluster",
+		},
+		{
+			pathTemplate: "/clusters/{{ .ClusterID }}/{{ .OrgName }}/{{ .SecretName }}/",
+			expected:     "clusters/cluster/cluster_app/builder-quay-cluster",
+		},
+		{
+			pathTemplate:  "quay/{{ .Unknown }}",
+			expectedError: true,
+		},
+		{
+			pathTemplate:  "quay//{{ .SecretName }}",
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		path, err := RenderPath(c.pathTemplate, PathTemplateData{OrgName: "cluster_app", Namespace: "app", SecretName: "builder-quay-cluster", ClusterID: "cluster"})
+
+		if c.expectedError != (err != nil) || path != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s %v\nActual: %s %v", i, c.expected, c.expectedError, path, err)
+		}
+	}
+}
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Target string
+	Body   string
+}
+
+func TestVaultStore(t *testing.T) {
+
+	cases := []struct {
+		kvVersion        int
+		mount            string
+		expectedRequests []recordedRequest
+	}{
+		{
+			kvVersion: 2,
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodPost, Path: "/v1/secret/data/quay/app/builder", Body: `{"data":{"password":"token","username":"app+builder"}}`},
+				{Method: http.MethodDelete, Path: "/v1/secret/metadata/quay/app/builder"},
+			},
+		},
+		{
+			kvVersion: 1,
+			mount:     "/kv/",
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodPost, Path: "/v1/kv/quay/app/builder", Body: `{"password":"token","username":"app+builder"}`},
+				{Method: http.MethodDelete, Path: "/v1/kv/quay/app/builder"},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		requests := []recordedRequest{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			if r.Header.Get("X-Vault-Token") != "vault-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Body: strings.TrimSpace(string(body))})
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		store := &VaultStore{Client: server.Client(), Address: server.URL, Mount: c.mount, KVVersion: c.kvVersion, Token: "vault-token"}
+
+		writeErr := store.Write(context.Background(), "quay/app/builder", map[string]string{"username": "app+builder", "password": "token"})
+		deleteErr := store.Delete(context.Background(), "quay/app/builder")
+
+		server.Close()
+
+		if writeErr != nil || deleteErr != nil || !reflect.DeepEqual(requests, c.expectedRequests) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v %v %v", i, c.expectedRequests, requests, writeErr, deleteErr)
+		}
+	}
+}
+
+func TestAWSSecretsManagerStore(t *testing.T) {
+
+	cases := []struct {
+		existing         bool
+		expectedRequests []recordedRequest
+	}{
+		{
+			existing: true,
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodPost, Path: "/", Target: "secretsmanager.PutSecretValue", Body: `{"SecretId":"quay/app/builder","SecretString":"{\"password\":\"token\"}"}`},
+				{Method: http.MethodPost, Path: "/", Target: "secretsmanager.DeleteSecret", Body: `{"ForceDeleteWithoutRecovery":true,"SecretId":"quay/app/builder"}`},
+			},
+		},
+		{
+			existing: false,
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodPost, Path: "/", Target: "secretsmanager.PutSecretValue", Body: `{"SecretId":"quay/app/builder","SecretString":"{\"password\":\"token\"}"}`},
+				{Method: http.MethodPost, Path: "/", Target: "secretsmanager.CreateSecret", Body: `{"Description":"Robot account credentials managed by the quay-bridge-operator","Name":"quay/app/builder","SecretString":"{\"password\":\"token\"}"}`},
+				{Method: http.MethodPost, Path: "/", Target: "secretsmanager.DeleteSecret", Body: `{"ForceDeleteWithoutRecovery":true,"SecretId":"quay/app/builder"}`},
+			},
+		},
+	}
+
+	for i, c := range cases {
+
+		requests := []recordedRequest{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240102/us-east-1/secretsmanager/aws4_request") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			body, _ := ioutil.ReadAll(r.Body)
+			target := r.Header.Get("X-Amz-Target")
+			requests = append(requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Target: target, Body: string(body)})
+
+			if !c.existing && target != "secretsmanager.CreateSecret" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "Secrets Manager can't find the specified secret."})
+				return
+			}
+
+			w.Write([]byte(`{}`))
+		}))
+
+		store := &AWSSecretsManagerStore{
+			Client:      server.Client(),
+			Region:      "us-east-1",
+			Credentials: AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+			Endpoint:    server.URL,
+			now:         func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+		}
+
+		writeErr := store.Write(context.Background(), "quay/app/builder", map[string]string{"password": "token"})
+		deleteErr := store.Delete(context.Background(), "quay/app/builder")
+
+		server.Close()
+
+		if writeErr != nil || deleteErr != nil || !reflect.DeepEqual(requests, c.expectedRequests) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v %v %v", i, c.expectedRequests, requests, writeErr, deleteErr)
+		}
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+
+	// get-vanilla of the AWS Signature Version 4 test suite
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Header = http.Header{}
+
+	signRequest(req, nil, AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+
+	if actual := req.Header.Get("Authorization"); actual != expected {
+		t.Errorf("Signature did not match\nExpected: %s\nActual: %s", expected, actual)
+	}
+}
+
+type fakeStore struct {
+	data map[string]map[string]string
+}
+
+func (s *fakeStore) Write(ctx context.Context, path string, data map[string]string) error {
+	s.data[path] = data
+	return nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, path string) error {
+	delete(s.data, path)
+	return nil
+}
+
+func TestActuator(t *testing.T) {
+
+	store := &fakeStore{data: map[string]map[string]string{}}
+	actuator := &Actuator{Store: store, PathTemplate: "{{ .ClusterID }}/{{ .Namespace }}/{{ .SecretName }}", ClusterID: "cluster"}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "builder-quay-cluster", Namespace: "app"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+	}
+
+	if err := actuator.ApplySecret(context.Background(), reconcilerbase.ReconcilerBase{}, "app", secret); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]map[string]string{"cluster/app/builder-quay-cluster": {corev1.DockerConfigJsonKey: `{"auths":{}}`}}
+
+	if !reflect.DeepEqual(store.data, expected) {
+		t.Errorf("Stored credentials did not match\nExpected: %#v\nActual: %#v", expected, store.data)
+	}
+
+	if err := actuator.DeleteSecret(context.Background(), reconcilerbase.ReconcilerBase{}, secret); err != nil || len(store.data) != 0 {
+		t.Errorf("Stored credentials were not deleted: %#v %v", store.data, err)
+	}
+}
@@ -0,0 +1,108 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultVaultMount is the mount of the KV secrets engine when Mount is not set
+	DefaultVaultMount = "secret"
+	// DefaultVaultTokenKey is the key of the token within the referenced Secret when Key is not set
+	DefaultVaultTokenKey = "token"
+)
+
+// VaultStore writes credentials to a KV secrets engine of HashiCorp Vault
+type VaultStore struct {
+	Client  *http.Client
+	Address string
+	Mount   string
+	// KVVersion is the version of the KV secrets engine, 1 or 2
+	KVVersion int
+	Token     string
+}
+
+var _ Store = &VaultStore{}
+
+// Write creates or replaces the credentials stored at a path
+func (s *VaultStore) Write(ctx context.Context, path string, data map[string]string) error {
+
+	var body interface{} = data
+
+	if s.KVVersion != 1 {
+		body = map[string]interface{}{"data": data}
+	}
+
+	return s.do(ctx, http.MethodPost, s.url("data", path), body)
+}
+
+// Delete deletes the credentials stored at a path along with every version of them
+func (s *VaultStore) Delete(ctx context.Context, path string) error {
+	return s.do(ctx, http.MethodDelete, s.url("metadata", path), nil)
+}
+
+// url returns the URL of a path. Version 2 of the KV secrets engine serves the data and metadata of secrets under distinct prefixes
+func (s *VaultStore) url(prefix string, path string) string {
+
+	mount := s.Mount
+
+	if mount == "" {
+		mount = DefaultVaultMount
+	}
+
+	if s.KVVersion == 1 {
+		return fmt.Sprintf("%s/v1/%s/%s", strings.TrimSuffix(s.Address, "/"), strings.Trim(mount, "/"), path)
+	}
+
+	return fmt.Sprintf("%s/v1/%s/%s/%s", strings.TrimSuffix(s.Address, "/"), strings.Trim(mount, "/"), prefix, path)
+}
+
+func (s *VaultStore) do(ctx context.Context, method string, url string, body interface{}) error {
+
+	var reader io.Reader
+
+	if body != nil {
+		content, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Vault-Token", s.Token)
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.Client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return nil
+}
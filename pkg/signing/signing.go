@@ -0,0 +1,81 @@
+package signing
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap distributed to synchronized namespaces when ConfigMapName is not set
+	DefaultConfigMapName = "quay-image-signing"
+
+	// PublicKeyKey is the key holding the cosign public key, both in the referenced Secret by default and in the distributed ConfigMap
+	PublicKeyKey = "cosign.pub"
+
+	// PolicyKey is the key holding the sigstore policy in the distributed ConfigMap
+	PolicyKey = "policy.json"
+
+	// ImageSigningLabel identifies the ConfigMaps managed by the operator, ensuring ConfigMaps created by users are never removed
+	ImageSigningLabel = "quay.redhat.com/image-signing"
+)
+
+// GetConfigMapName returns the name of the ConfigMap distributed to synchronized namespaces
+func GetConfigMapName(imageSigning *quayv1.ImageSigning) string {
+
+	if imageSigning == nil || imageSigning.ConfigMapName == "" {
+		return DefaultConfigMapName
+	}
+
+	return imageSigning.ConfigMapName
+}
+
+// GetPublicKeySecretKey returns the key of the public key within the referenced Secret
+func GetPublicKeySecretKey(imageSigning *quayv1.ImageSigning) string {
+
+	if imageSigning == nil || imageSigning.PublicKeySecret == nil || imageSigning.PublicKeySecret.Key == "" {
+		return PublicKeyKey
+	}
+
+	return imageSigning.PublicKeySecret.Key
+}
+
+// IsDistributionEnabled returns whether verification material is distributed to synchronized namespaces
+func IsDistributionEnabled(imageSigning *quayv1.ImageSigning) bool {
+	return imageSigning != nil && (imageSigning.PublicKeySecret != nil || imageSigning.Policy != "")
+}
+
+// NewImageSigningConfigMap returns the ConfigMap holding the verification material of a namespace, consumable by policy engines
+func NewImageSigningConfigMap(namespace string, imageSigning *quayv1.ImageSigning, publicKey string) *corev1.ConfigMap {
+
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetConfigMapName(imageSigning),
+			Namespace: namespace,
+			Labels: map[string]string{
+				ImageSigningLabel: "true",
+			},
+		},
+		Data: map[string]string{},
+	}
+
+	if publicKey != "" {
+		configMap.Data[PublicKeyKey] = publicKey
+	}
+
+	if imageSigning != nil && imageSigning.Policy != "" {
+		configMap.Data[PolicyKey] = imageSigning.Policy
+	}
+
+	return configMap
+}
+
+// IsManagedConfigMap returns whether a ConfigMap was distributed by the operator
+func IsManagedConfigMap(configMap *corev1.ConfigMap) bool {
+	return configMap.Labels[ImageSigningLabel] == "true"
+}
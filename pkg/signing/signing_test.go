@@ -0,0 +1,73 @@
+package signing
+
+import (
+	"reflect"
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+func TestNewImageSigningConfigMap(t *testing.T) {
+
+	cases := []struct {
+		imageSigning *quayv1.ImageSigning
+		publicKey    string
+		expectedName string
+		expectedData map[string]string
+	}{
+		{
+			imageSigning: &quayv1.ImageSigning{PublicKeySecret: &quayv1.SecretRef{Name: "cosign", Namespace: "quay"}},
+			publicKey:    "public-key",
+			expectedName: DefaultConfigMapName,
+			expectedData: map[string]string{PublicKeyKey: "public-key"},
+		},
+		{
+			imageSigning: &quayv1.ImageSigning{Policy: "{}", ConfigMapName: "signing"},
+			expectedName: "signing",
+			expectedData: map[string]string{PolicyKey: "{}"},
+		},
+		{
+			imageSigning: &quayv1.ImageSigning{PublicKeySecret: &quayv1.SecretRef{Name: "cosign", Namespace: "quay"}, Policy: "{}"},
+			publicKey:    "public-key",
+			expectedName: DefaultConfigMapName,
+			expectedData: map[string]string{PublicKeyKey: "public-key", PolicyKey: "{}"},
+		},
+	}
+
+	for i, c := range cases {
+		configMap := NewImageSigningConfigMap("test", c.imageSigning, c.publicKey)
+
+		if configMap.Name != c.expectedName || configMap.Namespace != "test" || !IsManagedConfigMap(configMap) || !reflect.DeepEqual(configMap.Data, c.expectedData) {
+			t.Errorf("Test case %d did not match\nExpected: %s %#v\nActual: %s %#v", i, c.expectedName, c.expectedData, configMap.Name, configMap.Data)
+		}
+	}
+}
+
+func TestGetPublicKeySecretKey(t *testing.T) {
+
+	cases := []struct {
+		imageSigning *quayv1.ImageSigning
+		expected     string
+	}{
+		{
+			imageSigning: nil,
+			expected:     PublicKeyKey,
+		},
+		{
+			imageSigning: &quayv1.ImageSigning{PublicKeySecret: &quayv1.SecretRef{Name: "cosign", Namespace: "quay"}},
+			expected:     PublicKeyKey,
+		},
+		{
+			imageSigning: &quayv1.ImageSigning{PublicKeySecret: &quayv1.SecretRef{Name: "cosign", Namespace: "quay", Key: "key.pub"}},
+			expected:     "key.pub",
+		},
+	}
+
+	for i, c := range cases {
+		actual := GetPublicKeySecretKey(c.imageSigning)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s", i, c.expected, actual)
+		}
+	}
+}
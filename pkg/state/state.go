@@ -0,0 +1,765 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/quayregistry"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+)
+
+const (
+	// ManifestVersion is the version of the manifest format
+	ManifestVersion = "v1"
+
+	// UserKind identifies users, and robot accounts of other namespaces, by their name
+	UserKind = "user"
+	// RobotKind identifies robot accounts of the organization by their shortname, so that they can be restored within another organization
+	RobotKind = "robot"
+	// TeamKind identifies teams of the organization
+	TeamKind = "team"
+)
+
+// Manifest describes the Quay objects managed by the operator. Robot account tokens are not included
+type Manifest struct {
+	Version       string              `json:"version"`
+	QuayHostname  string              `json:"quayHostname"`
+	ExportTime    string              `json:"exportTime"`
+	Organizations []OrganizationState `json:"organizations"`
+}
+
+// OrganizationState describes an organization created for a namespace
+type OrganizationState struct {
+	Name          string              `json:"name"`
+	Namespace     string              `json:"namespace"`
+	Email         string              `json:"email,omitempty"`
+	RobotAccounts []RobotAccountState `json:"robotAccounts,omitempty"`
+	Teams         []TeamState         `json:"teams,omitempty"`
+	Repositories  []RepositoryState   `json:"repositories,omitempty"`
+}
+
+// RobotAccountState describes a robot account and the default permission granted to it within its organization
+type RobotAccountState struct {
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+// TeamState describes a team of an organization, its role within the organization and its members
+type TeamState struct {
+	Name        string        `json:"name"`
+	Role        string        `json:"role"`
+	Description string        `json:"description,omitempty"`
+	Members     []MemberState `json:"members,omitempty"`
+}
+
+// MemberState describes a user or robot account member of a team
+type MemberState struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// RepositoryState describes a repository, the roles granted on it and its notifications
+type RepositoryState struct {
+	Name          string                        `json:"name"`
+	Visibility    string                        `json:"visibility"`
+	Description   string                        `json:"description,omitempty"`
+	Permissions   []PermissionState             `json:"permissions,omitempty"`
+	Notifications []qclient.NotificationRequest `json:"notifications,omitempty"`
+}
+
+// PermissionState describes a role granted on a repository to a user, robot account or team
+type PermissionState struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// ManagedNamespaces returns the namespaces synchronized by the operator
+func ManagedNamespaces(ctx context.Context, reader client.Reader, quayIntegration *quayv1.QuayIntegration) ([]string, error) {
+	return managedNamespaces(ctx, reader, quayIntegration, true)
+}
+
+// ManagedOrganizationNamespaces returns the namespaces synchronized by the operator with a Quay organization, leaving out those mapped to a Quay user account
+func ManagedOrganizationNamespaces(ctx context.Context, reader client.Reader, quayIntegration *quayv1.QuayIntegration) ([]string, error) {
+	return managedNamespaces(ctx, reader, quayIntegration, false)
+}
+
+func managedNamespaces(ctx context.Context, reader client.Reader, quayIntegration *quayv1.QuayIntegration, includeUserAccounts bool) ([]string, error) {
+
+	namespaces := corev1.NamespaceList{}
+
+	if err := reader.List(ctx, &namespaces); err != nil {
+		return nil, err
+	}
+
+	syncScope, err := syncpolicy.LoadScope(ctx, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	managedNamespaces := []string{}
+
+	for i := range namespaces.Items {
+
+		namespace := &namespaces.Items[i]
+
+		if !syncScope.IsSynchronized(quayIntegration, namespace) || !utils.HasNamespaceFinalizer(namespace, constants.NamespaceFinalizer) {
+			continue
+		}
+
+		if _, userAccount := utils.GetUserAccount(quayIntegration, namespace); userAccount && !includeUserAccounts {
+			continue
+		}
+
+		managedNamespaces = append(managedNamespaces, namespace.Name)
+	}
+
+	sort.Strings(managedNamespaces)
+
+	return managedNamespaces, nil
+}
+
+// Export collects the state of the organizations of the provided namespaces from Quay
+func Export(quayClient *qclient.QuayClient, quayIntegration *quayv1.QuayIntegration, namespaces []string) (*Manifest, error) {
+
+	manifest := &Manifest{
+		Version:       ManifestVersion,
+		QuayHostname:  quayIntegration.GetQuayHostname(),
+		ExportTime:    time.Now().UTC().Format(time.RFC3339),
+		Organizations: []OrganizationState{},
+	}
+
+	for _, namespace := range namespaces {
+
+		organizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+
+		quayOrganization, organizationResponse, organizationErr := quayClient.GetOrganizationByname(organizationName)
+
+		if organizationErr.Error != nil {
+			return nil, fmt.Errorf("error retrieving organization '%s': %v", organizationName, organizationErr.Error)
+		}
+
+		if organizationResponse.StatusCode == 404 {
+			logging.Log.Info("Skipping export of missing organization", "Organization", organizationName)
+			continue
+		}
+
+		organization, err := exportOrganization(quayClient, quayOrganization, namespace)
+
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Organizations = append(manifest.Organizations, *organization)
+	}
+
+	return manifest, nil
+}
+
+func exportOrganization(quayClient *qclient.QuayClient, quayOrganization qclient.Organization, namespace string) (*OrganizationState, error) {
+
+	organizationName := quayOrganization.Name
+
+	organization := &OrganizationState{
+		Name:      organizationName,
+		Namespace: namespace,
+		Email:     quayOrganization.Email,
+	}
+
+	robotAccounts, robotAccountsResponse, robotAccountsErr := quayClient.GetOrganizationRobotAccounts(organizationName)
+
+	if robotAccountsErr.Error != nil || robotAccountsResponse.StatusCode != 200 {
+		return nil, fmt.Errorf("error retrieving robot accounts of organization '%s': %v", organizationName, robotAccountsErr.Error)
+	}
+
+	prototypes, prototypesResponse, prototypesErr := quayClient.GetPrototypesByOrganization(organizationName)
+
+	if prototypesErr.Error != nil || prototypesResponse.StatusCode != 200 {
+		return nil, fmt.Errorf("error retrieving default permissions of organization '%s': %v", organizationName, prototypesErr.Error)
+	}
+
+	for _, robotAccount := range robotAccounts.Robots {
+
+		robotAccountState := RobotAccountState{
+			Name: strings.TrimPrefix(robotAccount.Name, organizationName+"+"),
+		}
+
+		for _, prototype := range prototypes.Prototypes {
+			if prototype.Delegate.Robot && prototype.Delegate.Name == robotAccount.Name {
+				robotAccountState.Role = prototype.Role
+			}
+		}
+
+		organization.RobotAccounts = append(organization.RobotAccounts, robotAccountState)
+	}
+
+	teamNames := []string{}
+
+	for teamName := range quayOrganization.Teams {
+		teamNames = append(teamNames, teamName)
+	}
+
+	sort.Strings(teamNames)
+
+	for _, teamName := range teamNames {
+
+		team := quayOrganization.Teams[teamName]
+
+		teamMembers, teamMembersResponse, teamMembersErr := quayClient.GetOrganizationTeamMembers(organizationName, teamName)
+
+		if teamMembersErr.Error != nil || teamMembersResponse.StatusCode != 200 {
+			return nil, fmt.Errorf("error retrieving members of team '%s' of organization '%s': %v", teamName, organizationName, teamMembersErr.Error)
+		}
+
+		teamState := TeamState{
+			Name:        teamName,
+			Role:        string(team.Role),
+			Description: team.Description,
+		}
+
+		for _, member := range teamMembers.Members {
+			kind, name := entityState(organizationName, member.Name, member.IsRobot)
+			teamState.Members = append(teamState.Members, MemberState{Kind: kind, Name: name})
+		}
+
+		organization.Teams = append(organization.Teams, teamState)
+	}
+
+	repositories, repositoriesResponse, repositoriesErr := quayClient.GetRepositoriesByOrganization(organizationName)
+
+	if repositoriesErr.Error != nil || repositoriesResponse == nil || repositoriesResponse.StatusCode != 200 {
+		return nil, fmt.Errorf("error retrieving repositories of organization '%s': %v", organizationName, repositoriesErr.Error)
+	}
+
+	for _, repository := range repositories {
+
+		repositoryState := RepositoryState{
+			Name:        repository.Name,
+			Visibility:  "private",
+			Description: repository.Description,
+		}
+
+		if repository.IsPublic {
+			repositoryState.Visibility = "public"
+		}
+
+		permissions, err := exportRepositoryPermissions(quayClient, organizationName, repository.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		repositoryState.Permissions = permissions
+
+		notifications, notificationsResponse, notificationsErr := quayClient.GetRepositoryNotifications(organizationName, repository.Name)
+
+		if notificationsErr.Error != nil || notificationsResponse.StatusCode != 200 {
+			return nil, fmt.Errorf("error retrieving notifications of repository '%s/%s': %v", organizationName, repository.Name, notificationsErr.Error)
+		}
+
+		for _, notification := range notifications.Notifications {
+			repositoryState.Notifications = append(repositoryState.Notifications, qclient.NotificationRequest{
+				Title:       notification.Title,
+				Event:       notification.Event,
+				Method:      notification.Method,
+				Config:      notification.Config,
+				EventConfig: notification.EventConfig,
+			})
+		}
+
+		organization.Repositories = append(organization.Repositories, repositoryState)
+	}
+
+	return organization, nil
+}
+
+// exportRepositoryPermissions returns the roles granted on a repository to users, robot accounts and teams
+func exportRepositoryPermissions(quayClient *qclient.QuayClient, organizationName string, repositoryName string) ([]PermissionState, error) {
+
+	userPermissions, userPermissionsResponse, userPermissionsErr := quayClient.GetRepositoryUserPermissions(organizationName, repositoryName)
+
+	if userPermissionsErr.Error != nil || userPermissionsResponse.StatusCode != 200 {
+		return nil, fmt.Errorf("error retrieving user permissions of repository '%s/%s': %v", organizationName, repositoryName, userPermissionsErr.Error)
+	}
+
+	teamPermissions, teamPermissionsResponse, teamPermissionsErr := quayClient.GetRepositoryTeamPermissions(organizationName, repositoryName)
+
+	if teamPermissionsErr.Error != nil || teamPermissionsResponse.StatusCode != 200 {
+		return nil, fmt.Errorf("error retrieving team permissions of repository '%s/%s': %v", organizationName, repositoryName, teamPermissionsErr.Error)
+	}
+
+	permissions := []PermissionState{}
+
+	for name, permission := range userPermissions.Permissions {
+		kind, recordedName := entityState(organizationName, name, permission.IsRobot)
+		permissions = append(permissions, PermissionState{Kind: kind, Name: recordedName, Role: permission.Role})
+	}
+
+	for name, permission := range teamPermissions.Permissions {
+		permissions = append(permissions, PermissionState{Kind: TeamKind, Name: name, Role: permission.Role})
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].Kind != permissions[j].Kind {
+			return permissions[i].Kind < permissions[j].Kind
+		}
+
+		return permissions[i].Name < permissions[j].Name
+	})
+
+	if len(permissions) == 0 {
+		return nil, nil
+	}
+
+	return permissions, nil
+}
+
+// entityState returns the kind and name recorded for a user or robot account. Robot accounts of the organization are recorded by
+// their shortname, robot accounts of other namespaces by their full name like users
+func entityState(organizationName string, name string, robot bool) (string, string) {
+
+	if robot && strings.HasPrefix(name, organizationName+"+") {
+		return RobotKind, strings.TrimPrefix(name, organizationName+"+")
+	}
+
+	return UserKind, name
+}
+
+// entityName returns the name in Quay of a recorded user or robot account of an organization
+func entityName(organizationName string, kind string, name string) string {
+
+	if kind == RobotKind {
+		return utils.FormatOrganizationRobotAccountName(organizationName, name)
+	}
+
+	return name
+}
+
+// Import re-creates the objects described by a Manifest which are missing from Quay. Existing objects are left untouched.
+// Organizations are created with their recorded email address, falling back to the address derived by the QuayIntegration
+func Import(quayClient *qclient.QuayClient, quayIntegration *quayv1.QuayIntegration, manifest *Manifest) error {
+
+	if manifest.Version != ManifestVersion {
+		return fmt.Errorf("unsupported manifest version '%s'", manifest.Version)
+	}
+
+	for _, organization := range manifest.Organizations {
+		if err := importOrganization(quayClient, quayIntegration, &organization); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importOrganization(quayClient *qclient.QuayClient, quayIntegration *quayv1.QuayIntegration, organization *OrganizationState) error {
+
+	quayOrganization, organizationResponse, organizationErr := quayClient.GetOrganizationByname(organization.Name)
+
+	if organizationErr.Error != nil {
+		return fmt.Errorf("error retrieving organization '%s': %v", organization.Name, organizationErr.Error)
+	}
+
+	if organizationResponse.StatusCode == 404 {
+
+		logging.Log.Info("Creating Organization", "Organization", organization.Name)
+
+		email := organization.Email
+
+		if email == "" {
+
+			generatedEmail, err := utils.GenerateOrganizationEmail(quayIntegration, organization.Namespace, organization.Name)
+
+			if err != nil {
+				return fmt.Errorf("error generating email of organization '%s': %v", organization.Name, err)
+			}
+
+			email = generatedEmail
+		}
+
+		_, createOrganizationResponse, createOrganizationErr := quayClient.CreateOrganization(organization.Name, email)
+
+		if createOrganizationErr.Error != nil || createOrganizationResponse.StatusCode != 201 {
+			return fmt.Errorf("error creating organization '%s': %v", organization.Name, createOrganizationErr.Error)
+		}
+	}
+
+	prototypes, prototypesResponse, prototypesErr := quayClient.GetPrototypesByOrganization(organization.Name)
+
+	if prototypesErr.Error != nil || prototypesResponse.StatusCode != 200 {
+		return fmt.Errorf("error retrieving default permissions of organization '%s': %v", organization.Name, prototypesErr.Error)
+	}
+
+	for _, robotAccount := range organization.RobotAccounts {
+
+		_, robotAccountResponse, robotAccountErr := quayClient.GetOrganizationRobotAccount(organization.Name, robotAccount.Name)
+
+		if robotAccountErr.Error != nil {
+			return fmt.Errorf("error retrieving robot account '%s' of organization '%s': %v", robotAccount.Name, organization.Name, robotAccountErr.Error)
+		}
+
+		if robotAccountResponse.StatusCode == 400 || robotAccountResponse.StatusCode == 404 {
+
+			logging.Log.Info("Creating Robot Account", "Organization", organization.Name, "Robot Account", robotAccount.Name)
+
+			_, createRobotAccountResponse, createRobotAccountErr := quayClient.CreateOrganizationRobotAccount(organization.Name, robotAccount.Name)
+
+			if createRobotAccountErr.Error != nil || createRobotAccountResponse.StatusCode != 201 {
+				return fmt.Errorf("error creating robot account '%s' of organization '%s': %v", robotAccount.Name, organization.Name, createRobotAccountErr.Error)
+			}
+		}
+
+		robotAccountName := utils.FormatOrganizationRobotAccountName(organization.Name, robotAccount.Name)
+
+		if robotAccount.Role != "" && !qclient.IsRobotAccountInPrototypeByRole(prototypes.Prototypes, robotAccountName, robotAccount.Role) {
+
+			_, createPrototypeResponse, createPrototypeErr := quayClient.CreateRobotPermissionForOrganization(organization.Name, robotAccountName, robotAccount.Role)
+
+			if createPrototypeErr.Error != nil || createPrototypeResponse.StatusCode != 200 {
+				return fmt.Errorf("error creating default permission for robot account '%s': %v", robotAccountName, createPrototypeErr.Error)
+			}
+		}
+	}
+
+	// Teams are imported after the robot accounts which may be their members, and before the repositories granting them roles
+	for _, team := range organization.Teams {
+		if err := importTeam(quayClient, organization.Name, quayOrganization.Teams, &team); err != nil {
+			return err
+		}
+	}
+
+	for _, repository := range organization.Repositories {
+		if err := importRepository(quayClient, organization.Name, &repository); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importTeam creates a team missing from an organization and adds its missing members. The role and description of existing
+// teams and their other members are left untouched
+func importTeam(quayClient *qclient.QuayClient, organizationName string, existingTeams map[string]qclient.Team, team *TeamState) error {
+
+	if _, exists := existingTeams[team.Name]; !exists {
+
+		logging.Log.Info("Creating Team", "Organization", organizationName, "Team", team.Name)
+
+		_, createTeamResponse, createTeamErr := quayClient.CreateOrUpdateOrganizationTeam(organizationName, team.Name, qclient.TeamRole(team.Role), team.Description)
+
+		if createTeamErr.Error != nil || createTeamResponse.StatusCode != 200 {
+			return fmt.Errorf("error creating team '%s' of organization '%s': %v", team.Name, organizationName, createTeamErr.Error)
+		}
+	}
+
+	teamMembers, teamMembersResponse, teamMembersErr := quayClient.GetOrganizationTeamMembers(organizationName, team.Name)
+
+	if teamMembersErr.Error != nil || teamMembersResponse.StatusCode != 200 {
+		return fmt.Errorf("error retrieving members of team '%s' of organization '%s': %v", team.Name, organizationName, teamMembersErr.Error)
+	}
+
+	memberNames := []string{}
+
+	for _, member := range team.Members {
+		memberNames = append(memberNames, entityName(organizationName, member.Kind, member.Name))
+	}
+
+	for _, memberName := range qclient.MissingTeamMembers(teamMembers.Members, memberNames) {
+
+		_, addMemberResponse, addMemberErr := quayClient.AddOrganizationTeamMember(organizationName, team.Name, memberName)
+
+		if addMemberErr.Error != nil || addMemberResponse.StatusCode != 200 {
+			return fmt.Errorf("error adding member '%s' to team '%s' of organization '%s': %v", memberName, team.Name, organizationName, addMemberErr.Error)
+		}
+	}
+
+	return nil
+}
+
+// CopyOrganization creates an organization with the robot accounts, default permissions and repositories of an existing
+// organization. Images are not copied. The email address of the new organization is derived by the QuayIntegration
+func CopyOrganization(quayClient *qclient.QuayClient, quayIntegration *quayv1.QuayIntegration, sourceOrganizationName string, targetOrganizationName string, namespace string) (*OrganizationState, error) {
+
+	sourceOrganization, organizationResponse, organizationErr := quayClient.GetOrganizationByname(sourceOrganizationName)
+
+	if organizationErr.Error != nil || organizationResponse.StatusCode != 200 {
+		return nil, fmt.Errorf("error retrieving organization '%s': %v", sourceOrganizationName, organizationErr.Error)
+	}
+
+	organization, err := exportOrganization(quayClient, sourceOrganization, namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	organization.Name = targetOrganizationName
+	organization.Email = ""
+
+	if err := importOrganization(quayClient, quayIntegration, organization); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func importRepository(quayClient *qclient.QuayClient, organizationName string, repository *RepositoryState) error {
+
+	_, repositoryResponse, repositoryErr := quayClient.GetRepository(organizationName, repository.Name)
+
+	if repositoryErr.Error != nil {
+		return fmt.Errorf("error retrieving repository '%s/%s': %v", organizationName, repository.Name, repositoryErr.Error)
+	}
+
+	if repositoryResponse.StatusCode == 403 || repositoryResponse.StatusCode == 404 {
+
+		logging.Log.Info("Creating Repository", "Organization", organizationName, "Name", repository.Name)
+
+		_, createRepositoryResponse, createRepositoryErr := quayClient.CreateRepository(organizationName, repository.Name)
+
+		if createRepositoryErr.Error != nil || createRepositoryResponse.StatusCode != 201 {
+			return fmt.Errorf("error creating repository '%s/%s': %v", organizationName, repository.Name, createRepositoryErr.Error)
+		}
+
+		if repository.Visibility == "public" {
+
+			visibilityResponse, visibilityErr := quayClient.ChangeRepositoryVisibility(organizationName, repository.Name, repository.Visibility)
+
+			if visibilityErr.Error != nil || visibilityResponse.StatusCode != 200 {
+				return fmt.Errorf("error changing visibility of repository '%s/%s': %v", organizationName, repository.Name, visibilityErr.Error)
+			}
+		}
+	}
+
+	if err := importRepositoryPermissions(quayClient, organizationName, repository); err != nil {
+		return err
+	}
+
+	existingNotifications, notificationsResponse, notificationsErr := quayClient.GetRepositoryNotifications(organizationName, repository.Name)
+
+	if notificationsErr.Error != nil || notificationsResponse.StatusCode != 200 {
+		return fmt.Errorf("error retrieving notifications of repository '%s/%s': %v", organizationName, repository.Name, notificationsErr.Error)
+	}
+
+	// Notifications are only added, existing notifications are never removed during an import
+	toCreate, _ := qclient.DiffNotifications(existingNotifications.Notifications, repository.Notifications, "")
+
+	for _, notification := range toCreate {
+
+		_, createNotificationResponse, createNotificationErr := quayClient.CreateRepositoryNotification(organizationName, repository.Name, notification)
+
+		if createNotificationErr.Error != nil || createNotificationResponse.StatusCode != 201 {
+			return fmt.Errorf("error creating notification '%s' on repository '%s/%s': %v", notification.Title, organizationName, repository.Name, createNotificationErr.Error)
+		}
+	}
+
+	return nil
+}
+
+// importRepositoryPermissions grants the recorded roles on a repository to the users, robot accounts and teams not granted any role
+// yet. Roles already granted are left untouched
+func importRepositoryPermissions(quayClient *qclient.QuayClient, organizationName string, repository *RepositoryState) error {
+
+	if len(repository.Permissions) == 0 {
+		return nil
+	}
+
+	userPermissions, userPermissionsResponse, userPermissionsErr := quayClient.GetRepositoryUserPermissions(organizationName, repository.Name)
+
+	if userPermissionsErr.Error != nil || userPermissionsResponse.StatusCode != 200 {
+		return fmt.Errorf("error retrieving user permissions of repository '%s/%s': %v", organizationName, repository.Name, userPermissionsErr.Error)
+	}
+
+	teamPermissions, teamPermissionsResponse, teamPermissionsErr := quayClient.GetRepositoryTeamPermissions(organizationName, repository.Name)
+
+	if teamPermissionsErr.Error != nil || teamPermissionsResponse.StatusCode != 200 {
+		return fmt.Errorf("error retrieving team permissions of repository '%s/%s': %v", organizationName, repository.Name, teamPermissionsErr.Error)
+	}
+
+	for _, permission := range repository.Permissions {
+
+		if permission.Kind == TeamKind {
+
+			if _, granted := teamPermissions.Permissions[permission.Name]; granted {
+				continue
+			}
+
+			permissionResponse, permissionErr := quayClient.SetRepositoryTeamPermission(organizationName, repository.Name, permission.Name, permission.Role)
+
+			if permissionErr.Error != nil || permissionResponse.StatusCode != 200 {
+				return fmt.Errorf("error granting role '%s' on repository '%s/%s' to team '%s': %v", permission.Role, organizationName, repository.Name, permission.Name, permissionErr.Error)
+			}
+
+			continue
+		}
+
+		name := entityName(organizationName, permission.Kind, permission.Name)
+
+		if _, granted := userPermissions.Permissions[name]; granted {
+			continue
+		}
+
+		permissionResponse, permissionErr := quayClient.SetRepositoryUserPermission(organizationName, repository.Name, name, permission.Role)
+
+		if permissionErr.Error != nil || permissionResponse.StatusCode != 200 {
+			return fmt.Errorf("error granting role '%s' on repository '%s/%s' to '%s': %v", permission.Role, organizationName, repository.Name, name, permissionErr.Error)
+		}
+	}
+
+	return nil
+}
+
+// WriteManifest writes a Manifest to a file. Files with a .json extension are written as JSON, otherwise YAML is used
+func WriteManifest(path string, manifest *Manifest) error {
+
+	var data []byte
+	var err error
+
+	if filepath.Ext(path) == ".json" {
+		data, err = json.MarshalIndent(manifest, "", "  ")
+	} else {
+		data, err = yaml.Marshal(manifest)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// ReadManifest reads a Manifest written in either JSON or YAML from a file
+func ReadManifest(path string) (*Manifest, error) {
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// NewQuayClient creates a client for the Quay instance of a QuayIntegration using its credentials Secret
+func NewQuayClient(ctx context.Context, reader client.Reader, quayIntegration *quayv1.QuayIntegration, httpClientPool *qclient.HTTPClientPool) (*qclient.QuayClient, error) {
+
+	if quayIntegration.Spec.CredentialsSecret == nil {
+		return nil, fmt.Errorf("required parameter 'CredentialsSecret' not found")
+	}
+
+	secretCredential := &corev1.Secret{}
+
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: quayIntegration.Spec.CredentialsSecret.Namespace, Name: quayIntegration.Spec.CredentialsSecret.Name}, secretCredential); err != nil {
+		return nil, err
+	}
+
+	quaySecretCredentialTokenKey := constants.QuaySecretCredentialTokenKey
+
+	if quayIntegration.Spec.CredentialsSecret.Key != "" {
+		quaySecretCredentialTokenKey = quayIntegration.Spec.CredentialsSecret.Key
+	}
+
+	quayHostname, certificateAuthority, err := quayregistry.ResolveEndpoint(ctx, reader, quayIntegration)
+
+	if err != nil {
+		return nil, err
+	}
+
+	quayIntegration.Status.QuayHostname = quayHostname
+
+	httpClient, err := httpClientPool.GetHTTPClientWithCA(quayIntegration.IsTLSVerificationDisabled(), certificateAuthority)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource, err := httpClientPool.GetTokenSource(httpClient, quayHostname, secretCredential.Namespace, secretCredential.Name, secretCredential.Data, quaySecretCredentialTokenKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return qclient.NewClientWithTokenSource(httpClient, quayHostname, tokenSource), nil
+}
+
+// NewRefreshTokenStore returns the RefreshTokenStore updating the credentials Secrets of QuayIntegrations with rotated refresh tokens. The
+// Secret is read with reader, which should not be backed by a cache, so that conflicting writes are retried against its latest version
+func NewRefreshTokenStore(reader client.Reader, writer client.Writer) qclient.RefreshTokenStore {
+
+	return func(secretNamespace string, secretName string, refreshToken string) error {
+
+		ctx := context.Background()
+
+		return reconcilerbase.RetryOnConflict(reconcilerbase.DefaultRetry, func() error {
+
+			secret := &corev1.Secret{}
+
+			if err := reader.Get(ctx, types.NamespacedName{Namespace: secretNamespace, Name: secretName}, secret); err != nil {
+				return err
+			}
+
+			if string(secret.Data[qclient.OAuthRefreshTokenKey]) == refreshToken {
+				return nil
+			}
+
+			if secret.Data == nil {
+				secret.Data = map[string][]byte{}
+			}
+
+			secret.Data[qclient.OAuthRefreshTokenKey] = []byte(refreshToken)
+
+			logging.Log.Info("Storing rotated OAuth refresh token", "Namespace", secretNamespace, "Secret", secretName)
+
+			return writer.Update(ctx, secret)
+		})
+	}
+}
+
+// GetQuayIntegration returns the QuayIntegration with the provided name. When no name is provided, the only QuayIntegration in the cluster is returned
+func GetQuayIntegration(ctx context.Context, reader client.Reader, name string) (*quayv1.QuayIntegration, error) {
+
+	if name != "" {
+		quayIntegration := &quayv1.QuayIntegration{}
+
+		if err := reader.Get(ctx, types.NamespacedName{Name: name}, quayIntegration); err != nil {
+			return nil, err
+		}
+
+		return quayIntegration, nil
+	}
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := reader.List(ctx, &quayIntegrations); err != nil {
+		return nil, err
+	}
+
+	if len(quayIntegrations.Items) != 1 {
+		return nil, fmt.Errorf("found %d QuayIntegrations, a QuayIntegration name must be provided", len(quayIntegrations.Items))
+	}
+
+	return &quayIntegrations.Items[0], nil
+}
@@ -0,0 +1,210 @@
+package state
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "quay-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := &Manifest{
+		Version:      ManifestVersion,
+		QuayHostname: "https://quay.example.com",
+		ExportTime:   "2021-01-01T00:00:00Z",
+		Organizations: []OrganizationState{
+			{
+				Name:      "openshift_myproject",
+				Namespace: "myproject",
+				RobotAccounts: []RobotAccountState{
+					{Name: "builder", Role: "write"},
+					{Name: "default", Role: "read"},
+				},
+				Teams: []TeamState{
+					{
+						Name:        "developers",
+						Role:        "member",
+						Description: "Developers of myproject",
+						Members:     []MemberState{{Kind: RobotKind, Name: "builder"}, {Kind: UserKind, Name: "alice"}},
+					},
+				},
+				Repositories: []RepositoryState{
+					{
+						Name:        "app",
+						Visibility:  "private",
+						Permissions: []PermissionState{{Kind: TeamKind, Name: "developers", Role: "write"}, {Kind: UserKind, Name: "bob", Role: "read"}},
+						Notifications: []qclient.NotificationRequest{
+							{
+								Title:       "Push",
+								Event:       "repo_push",
+								Method:      "webhook",
+								Config:      map[string]interface{}{"url": "https://hooks.example.com"},
+								EventConfig: map[string]interface{}{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		file string
+	}{
+		{
+			file: "state.yaml",
+		},
+		{
+			file: "state.json",
+		},
+	}
+
+	for i, c := range cases {
+
+		path := filepath.Join(dir, c.file)
+
+		if err := WriteManifest(path, manifest); err != nil {
+			t.Fatalf("Test case %d failed writing manifest: %v", i, err)
+		}
+
+		result, err := ReadManifest(path)
+
+		if err != nil {
+			t.Fatalf("Test case %d failed reading manifest: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(manifest, result) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, manifest, result)
+		}
+	}
+}
+
+func TestImportManifestVersion(t *testing.T) {
+
+	cases := []struct {
+		version  string
+		expected bool
+	}{
+		{
+			version:  ManifestVersion,
+			expected: false,
+		},
+		{
+			version:  "v2",
+			expected: true,
+		},
+		{
+			version:  "",
+			expected: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		err := Import(nil, nil, &Manifest{Version: c.version})
+
+		if (err != nil) != c.expected {
+			t.Errorf("Test case %d did not match\nExpected error: %#v\nActual: %v", i, c.expected, err)
+		}
+	}
+}
+
+func TestCopyOrganizationTeamsAndPermissions(t *testing.T) {
+
+	responses := map[string]string{
+		"GET /api/v1/organization/source":                                        `{"name": "source", "teams": {"owners": {"name": "owners", "role": "admin"}, "developers": {"name": "developers", "role": "member", "description": "Developers"}}}`,
+		"GET /api/v1/organization/source/robots":                                 `{"robots": [{"name": "source+builder"}]}`,
+		"GET /api/v1/organization/source/prototypes":                             `{"prototypes": []}`,
+		"GET /api/v1/organization/source/team/owners/members":                    `{"members": [{"name": "operator", "kind": "user"}]}`,
+		"GET /api/v1/organization/source/team/developers/members":                `{"members": [{"name": "alice", "kind": "user"}, {"name": "source+builder", "kind": "user", "is_robot": true}]}`,
+		"GET /api/v1/repository":                                                 `{"repositories": [{"name": "app", "namespace": "source"}]}`,
+		"GET /api/v1/repository/source/app/permissions/user/":                    `{"permissions": {"alice": {"name": "alice", "role": "admin"}, "source+builder": {"name": "source+builder", "role": "write", "is_robot": true}}}`,
+		"GET /api/v1/repository/source/app/permissions/team/":                    `{"permissions": {"developers": {"name": "developers", "role": "read"}}}`,
+		"GET /api/v1/repository/source/app/notification/":                        `{"notifications": []}`,
+		"GET /api/v1/organization/target":                                        `{"name": "target", "teams": {"owners": {"name": "owners", "role": "admin"}}}`,
+		"GET /api/v1/organization/target/robots/builder":                         `{"name": "target+builder"}`,
+		"GET /api/v1/organization/target/prototypes":                             `{"prototypes": []}`,
+		"GET /api/v1/organization/target/team/owners/members":                    `{"members": [{"name": "operator", "kind": "user"}]}`,
+		"GET /api/v1/organization/target/team/developers/members":                `{"members": []}`,
+		"GET /api/v1/repository/target/app":                                      `{"name": "app", "namespace": "target"}`,
+		"GET /api/v1/repository/target/app/permissions/user/":                    `{"permissions": {"alice": {"name": "alice", "role": "read"}}}`,
+		"GET /api/v1/repository/target/app/permissions/team/":                    `{"permissions": {}}`,
+		"GET /api/v1/repository/target/app/notification/":                        `{"notifications": []}`,
+		"PUT /api/v1/organization/target/team/developers":                        `{"name": "developers", "role": "member"}`,
+		"PUT /api/v1/organization/target/team/owners/members/operator":           `{"name": "operator"}`,
+		"PUT /api/v1/organization/target/team/developers/members/alice":          `{"name": "alice"}`,
+		"PUT /api/v1/organization/target/team/developers/members/target+builder": `{"name": "target+builder"}`,
+		"PUT /api/v1/repository/target/app/permissions/user/target+builder":      `{}`,
+		"PUT /api/v1/repository/target/app/permissions/team/developers":          `{}`,
+	}
+
+	changes := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		request := r.Method + " " + r.URL.Path
+		response, ok := responses[request]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			changes = append(changes, request)
+		}
+
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	quayIntegration := &quayv1.QuayIntegration{}
+	quayClient := qclient.NewClient(server.Client(), server.URL, "token")
+
+	organization, err := CopyOrganization(quayClient, quayIntegration, "source", "target", "myproject")
+
+	if err != nil {
+		t.Fatalf("Failed copying organization: %v", err)
+	}
+
+	expectedTeams := []TeamState{
+		{Name: "developers", Role: "member", Description: "Developers", Members: []MemberState{{Kind: UserKind, Name: "alice"}, {Kind: RobotKind, Name: "builder"}}},
+		{Name: "owners", Role: "admin", Members: []MemberState{{Kind: UserKind, Name: "operator"}}},
+	}
+
+	expectedPermissions := []PermissionState{
+		{Kind: RobotKind, Name: "builder", Role: "write"},
+		{Kind: TeamKind, Name: "developers", Role: "read"},
+		{Kind: UserKind, Name: "alice", Role: "admin"},
+	}
+
+	if !reflect.DeepEqual(expectedTeams, organization.Teams) || len(organization.Repositories) != 1 || !reflect.DeepEqual(expectedPermissions, organization.Repositories[0].Permissions) {
+		t.Errorf("Exported organization did not match\nExpected: %#v %#v\nActual: %#v %#v", expectedTeams, expectedPermissions, organization.Teams, organization.Repositories)
+	}
+
+	// Existing teams, members and permissions are left untouched, robot accounts of the source organization are mapped to the target organization
+	expectedChanges := []string{
+		"PUT /api/v1/organization/target/team/developers",
+		"PUT /api/v1/organization/target/team/developers/members/alice",
+		"PUT /api/v1/organization/target/team/developers/members/target+builder",
+		"PUT /api/v1/repository/target/app/permissions/user/target+builder",
+		"PUT /api/v1/repository/target/app/permissions/team/developers",
+	}
+
+	if !reflect.DeepEqual(expectedChanges, changes) {
+		t.Errorf("Imported organization did not match\nExpected: %#v\nActual: %#v", expectedChanges, changes)
+	}
+}
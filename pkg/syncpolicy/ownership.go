@@ -0,0 +1,146 @@
+package syncpolicy
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+// Scope determines the QuayIntegration synchronizing each namespace. A namespace selected by several QuayIntegrations is owned
+// by the QuayIntegration with the highest priority, then by the oldest one, then by the first one by name, and is never
+// synchronized by the others
+type Scope struct {
+	quayIntegrations []quayv1.QuayIntegration
+	policies         Policies
+}
+
+// Conflict is a namespace selected by a QuayIntegration while owned by another QuayIntegration with precedence
+type Conflict struct {
+	Namespace string
+	Owner     string
+}
+
+// NewScope returns the Scope of QuayIntegrations under the policies
+func NewScope(quayIntegrations []quayv1.QuayIntegration, policies Policies) *Scope {
+
+	sorted := make([]quayv1.QuayIntegration, len(quayIntegrations))
+	copy(sorted, quayIntegrations)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return HasPrecedence(&sorted[i], &sorted[j])
+	})
+
+	return &Scope{quayIntegrations: sorted, policies: policies}
+}
+
+// LoadScope returns the Scope of the QuayIntegrations and policies of the cluster
+func LoadScope(ctx context.Context, reader client.Reader) (*Scope, error) {
+
+	quayIntegrations := quayv1.QuayIntegrationList{}
+
+	if err := reader.List(ctx, &quayIntegrations); err != nil {
+		return nil, err
+	}
+
+	policies, err := List(ctx, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewScope(quayIntegrations.Items, policies), nil
+}
+
+// HasPrecedence returns whether a QuayIntegration takes precedence over another one
+func HasPrecedence(quayIntegration *quayv1.QuayIntegration, other *quayv1.QuayIntegration) bool {
+
+	if quayIntegration.Spec.Priority != other.Spec.Priority {
+		return quayIntegration.Spec.Priority > other.Spec.Priority
+	}
+
+	if !quayIntegration.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return quayIntegration.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+
+	return quayIntegration.Name < other.Name
+}
+
+// QuayIntegrations returns the QuayIntegrations of the Scope in order of precedence
+func (s *Scope) QuayIntegrations() []quayv1.QuayIntegration {
+	return s.quayIntegrations
+}
+
+// Owner returns the QuayIntegration synchronizing a namespace, or nil when no QuayIntegration selects it
+func (s *Scope) Owner(namespace *corev1.Namespace) *quayv1.QuayIntegration {
+
+	for i := range s.quayIntegrations {
+		if Selects(&s.quayIntegrations[i], s.policies, namespace) {
+			return &s.quayIntegrations[i]
+		}
+	}
+
+	return nil
+}
+
+// IsSynchronized returns whether a namespace is synchronized by a QuayIntegration
+func (s *Scope) IsSynchronized(quayIntegration *quayv1.QuayIntegration, namespace *corev1.Namespace) bool {
+
+	owner := s.Owner(namespace)
+
+	return owner != nil && owner.Name == quayIntegration.Name
+}
+
+// Conflicts returns the namespaces selected by each QuayIntegration while owned by another one, keyed by the name of the
+// QuayIntegration
+func (s *Scope) Conflicts(namespaces []corev1.Namespace) map[string][]Conflict {
+
+	conflicts := map[string][]Conflict{}
+
+	for i := range namespaces {
+
+		namespace := &namespaces[i]
+		owner := ""
+
+		for j := range s.quayIntegrations {
+
+			quayIntegration := &s.quayIntegrations[j]
+
+			if !Selects(quayIntegration, s.policies, namespace) {
+				continue
+			}
+
+			if owner == "" {
+				owner = quayIntegration.Name
+				continue
+			}
+
+			conflicts[quayIntegration.Name] = append(conflicts[quayIntegration.Name], Conflict{Namespace: namespace.Name, Owner: owner})
+		}
+	}
+
+	return conflicts
+}
+
+// GetOwner returns the namespace of a name along with the QuayIntegration synchronizing it, which is nil when no QuayIntegration
+// selects the namespace
+func GetOwner(ctx context.Context, reader client.Reader, name string) (*corev1.Namespace, *quayv1.QuayIntegration, error) {
+
+	namespace := &corev1.Namespace{}
+
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		return nil, nil, err
+	}
+
+	scope, err := LoadScope(ctx, reader)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return namespace, scope.Owner(namespace), nil
+}
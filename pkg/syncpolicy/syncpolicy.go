@@ -0,0 +1,111 @@
+package syncpolicy
+
+import (
+	"context"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+// Policies are the QuaySyncPolicies of the cluster
+type Policies []quayv1.QuaySyncPolicy
+
+// List returns the QuaySyncPolicies of the cluster. No policies apply when the QuaySyncPolicy CRD is not installed
+func List(ctx context.Context, reader client.Reader) (Policies, error) {
+
+	policies := quayv1.QuaySyncPolicyList{}
+
+	if err := reader.List(ctx, &policies); err != nil {
+		if meta.IsNoMatchError(err) {
+			return Policies{}, nil
+		}
+
+		return nil, err
+	}
+
+	return Policies(policies.Items), nil
+}
+
+// Allows returns whether the policies allow a namespace to be synchronized. Namespaces matched by a Deny policy are never synchronized,
+// while namespaces must be matched by an Allow policy when any exists
+func (p Policies) Allows(namespace *corev1.Namespace) bool {
+
+	hasAllowPolicies := false
+	allowed := false
+
+	for i := range p {
+
+		policy := &p[i]
+
+		switch policy.Spec.Action {
+		case quayv1.DenyQuaySyncPolicyAction:
+			// Invalid rules of Deny policies match to fail closed
+			if matches, err := Matches(policy, namespace); matches || err != nil {
+				return false
+			}
+		case quayv1.AllowQuaySyncPolicyAction:
+			hasAllowPolicies = true
+
+			if matches, err := Matches(policy, namespace); matches && err == nil {
+				allowed = true
+			}
+		}
+	}
+
+	return !hasAllowPolicies || allowed
+}
+
+// Matches returns whether a namespace satisfies the name patterns, label selector and opt-in annotation of a policy
+func Matches(policy *quayv1.QuaySyncPolicy, namespace *corev1.Namespace) (bool, error) {
+
+	if len(policy.Spec.NamespacePatterns) > 0 {
+
+		matched := false
+
+		for _, pattern := range policy.Spec.NamespacePatterns {
+
+			match, err := path.Match(pattern, namespace.Name)
+
+			if err != nil {
+				return false, err
+			}
+
+			matched = matched || match
+		}
+
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if policy.Spec.NamespaceSelector != nil {
+
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+
+		if err != nil {
+			return false, err
+		}
+
+		if !selector.Matches(labels.Set(namespace.Labels)) {
+			return false, nil
+		}
+	}
+
+	if policy.Spec.OptInAnnotation != "" && namespace.Annotations[policy.Spec.OptInAnnotation] != "true" {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Selects returns whether a QuayIntegration selects a namespace, satisfying both the namespace lists of the QuayIntegration and
+// the policies. Namespaces selected by several QuayIntegrations are only synchronized by the one with precedence
+func Selects(quayIntegration *quayv1.QuayIntegration, policies Policies, namespace *corev1.Namespace) bool {
+	return quayIntegration.IsAllowedNamespace(namespace.Name) && policies.Allows(namespace)
+}
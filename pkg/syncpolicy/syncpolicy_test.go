@@ -0,0 +1,245 @@
+package syncpolicy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+type noMatchReader struct {
+	client.Reader
+}
+
+func (r *noMatchReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "quay.redhat.com", Kind: "QuaySyncPolicy"}}
+}
+
+func newPolicy(action quayv1.QuaySyncPolicyAction, spec quayv1.QuaySyncPolicySpec) quayv1.QuaySyncPolicy {
+	spec.Action = action
+	return quayv1.QuaySyncPolicy{Spec: spec}
+}
+
+func TestAllows(t *testing.T) {
+
+	team := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "team-a",
+		Labels:      map[string]string{"environment": "production"},
+		Annotations: map[string]string{"quay.redhat.com/sync": "true"},
+	}}
+
+	cases := []struct {
+		policies Policies
+		expected bool
+	}{
+		{
+			policies: Policies{},
+			expected: true,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.DenyQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"openshift-*"}})},
+			expected: true,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.DenyQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"openshift-*", "team-*"}})},
+			expected: false,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"team-?"}})},
+			expected: true,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"app-*"}})},
+			expected: false,
+		},
+		{
+			policies: Policies{
+				newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"app-*"}}),
+				newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "production"}}}),
+			},
+			expected: true,
+		},
+		{
+			policies: Policies{
+				newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"team-*"}}),
+				newPolicy(quayv1.DenyQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "production"}}}),
+			},
+			expected: false,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"team-*"}, NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "staging"}}})},
+			expected: false,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{OptInAnnotation: "quay.redhat.com/sync"})},
+			expected: true,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{OptInAnnotation: "example.com/sync"})},
+			expected: false,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.DenyQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespacePatterns: []string{"["}})},
+			expected: false,
+		},
+		{
+			policies: Policies{newPolicy(quayv1.AllowQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "environment", Operator: "Unknown"}}}})},
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		actual := c.policies.Allows(team)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestSelects(t *testing.T) {
+
+	denyProduction := Policies{newPolicy(quayv1.DenyQuaySyncPolicyAction, quayv1.QuaySyncPolicySpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "production"}}})}
+
+	cases := []struct {
+		quayIntegration *quayv1.QuayIntegration
+		namespace       *corev1.Namespace
+		expected        bool
+	}{
+		{
+			quayIntegration: &quayv1.QuayIntegration{},
+			namespace:       &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+			expected:        true,
+		},
+		{
+			quayIntegration: &quayv1.QuayIntegration{},
+			namespace:       &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-config"}},
+			expected:        false,
+		},
+		{
+			quayIntegration: &quayv1.QuayIntegration{Spec: quayv1.QuayIntegrationSpec{DenylistNamespaces: []string{"team-a"}}},
+			namespace:       &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+			expected:        false,
+		},
+		{
+			quayIntegration: &quayv1.QuayIntegration{Spec: quayv1.QuayIntegrationSpec{AllowlistNamespaces: []string{"team-a"}}},
+			namespace:       &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"environment": "production"}}},
+			expected:        false,
+		},
+	}
+
+	for i, c := range cases {
+
+		actual := Selects(c.quayIntegration, denyProduction, c.namespace)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestListWithoutCRD(t *testing.T) {
+
+	policies, err := List(context.TODO(), &noMatchReader{})
+
+	if err != nil || policies == nil || len(policies) != 0 {
+		t.Errorf("Expected no policies when the CRD is not installed\nActual: %#v %v", policies, err)
+	}
+}
+
+func newQuayIntegration(name string, priority int32, created time.Time, allowlistNamespaces ...string) quayv1.QuayIntegration {
+	return quayv1.QuayIntegration{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       quayv1.QuayIntegrationSpec{Priority: priority, AllowlistNamespaces: allowlistNamespaces},
+	}
+}
+
+func TestScopeOwner(t *testing.T) {
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	team := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+
+	cases := []struct {
+		quayIntegrations []quayv1.QuayIntegration
+		expected         string
+	}{
+		{
+			quayIntegrations: []quayv1.QuayIntegration{},
+			expected:         "",
+		},
+		{
+			quayIntegrations: []quayv1.QuayIntegration{newQuayIntegration("quay", 0, created)},
+			expected:         "quay",
+		},
+		{
+			quayIntegrations: []quayv1.QuayIntegration{newQuayIntegration("newer", 0, created.Add(time.Hour)), newQuayIntegration("older", 0, created)},
+			expected:         "older",
+		},
+		{
+			quayIntegrations: []quayv1.QuayIntegration{newQuayIntegration("older", 0, created), newQuayIntegration("newer", 10, created.Add(time.Hour))},
+			expected:         "newer",
+		},
+		{
+			quayIntegrations: []quayv1.QuayIntegration{newQuayIntegration("quay-b", 0, created), newQuayIntegration("quay-a", 0, created)},
+			expected:         "quay-a",
+		},
+		{
+			quayIntegrations: []quayv1.QuayIntegration{newQuayIntegration("older", 0, created, "team-b"), newQuayIntegration("newer", 0, created.Add(time.Hour))},
+			expected:         "newer",
+		},
+	}
+
+	for i, c := range cases {
+
+		actual := ""
+
+		if owner := NewScope(c.quayIntegrations, Policies{}).Owner(team); owner != nil {
+			actual = owner.Name
+		}
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestScopeConflicts(t *testing.T) {
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	scope := NewScope([]quayv1.QuayIntegration{
+		newQuayIntegration("quay-c", 0, created, "team-a"),
+		newQuayIntegration("quay-b", 0, created.Add(time.Hour)),
+		newQuayIntegration("quay-a", 5, created.Add(2*time.Hour), "team-a", "team-b"),
+	}, Policies{})
+
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+	}
+
+	expected := map[string][]Conflict{
+		"quay-b": {{Namespace: "team-a", Owner: "quay-a"}, {Namespace: "team-b", Owner: "quay-a"}},
+		"quay-c": {{Namespace: "team-a", Owner: "quay-a"}},
+	}
+
+	actual := scope.Conflicts(namespaces)
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Conflicts did not match\nExpected: %#v\nActual: %#v", expected, actual)
+	}
+
+	if !scope.IsSynchronized(&scope.QuayIntegrations()[2], &namespaces[2]) || scope.IsSynchronized(&scope.QuayIntegrations()[2], &namespaces[0]) {
+		t.Errorf("Expected quay-b to only synchronize team-c")
+	}
+}
@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+const (
+	// pullLogKind is the kind of the logs recorded when a repository is pulled
+	pullLogKind = "pull_repo"
+	// pushLogKind is the kind of the logs recorded when a repository is pushed to
+	pushLogKind = "push_repo"
+)
+
+// RepositoryStats describes the pulls and pushes of a repository over a period
+type RepositoryStats struct {
+	Pulls  int64
+	Pushes int64
+	// LastPull is the day of the most recent pull, zero when the repository was not pulled during the period
+	LastPull time.Time
+}
+
+// GetRepositoryStats retrieves the pulls and pushes of a repository since a point in time from the aggregated logs of Quay
+func GetRepositoryStats(quayClient *qclient.QuayClient, organizationName string, repositoryName string, since time.Time, now time.Time) (RepositoryStats, error) {
+
+	logs, logsResponse, logsErr := quayClient.GetRepositoryAggregatedLogs(organizationName, repositoryName, since, now)
+
+	if logsErr.Error != nil {
+		return RepositoryStats{}, fmt.Errorf("error retrieving logs of repository '%s/%s': %w", organizationName, repositoryName, logsErr.Error)
+	}
+
+	if logsResponse.StatusCode != 200 {
+		return RepositoryStats{}, fmt.Errorf("error retrieving logs of repository '%s/%s': status code %d", organizationName, repositoryName, logsResponse.StatusCode)
+	}
+
+	return SummarizeAggregatedLogs(logs), nil
+}
+
+// SummarizeAggregatedLogs counts the pulls and pushes of aggregated logs. Logs with an unparsable day are counted but do not
+// contribute to LastPull
+func SummarizeAggregatedLogs(logs []qclient.AggregatedLog) RepositoryStats {
+
+	stats := RepositoryStats{}
+
+	for _, log := range logs {
+
+		switch log.Kind {
+		case pullLogKind:
+			stats.Pulls += log.Count
+
+			if day, err := time.Parse(time.RFC1123Z, log.Datetime); err == nil && log.Count > 0 && day.After(stats.LastPull) {
+				stats.LastPull = day
+			}
+		case pushLogKind:
+			stats.Pushes += log.Count
+		}
+	}
+
+	return stats
+}
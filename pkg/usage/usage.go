@@ -0,0 +1,127 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+const (
+	// StorageBytesAnnotation records the storage consumed by the organization of a namespace
+	StorageBytesAnnotation = "quay.redhat.com/storage-bytes"
+	// RepositoryCountAnnotation records the number of repositories of the organization of a namespace
+	RepositoryCountAnnotation = "quay.redhat.com/repository-count"
+)
+
+// Usage describes the registry resources consumed by an organization
+type Usage struct {
+	Repositories int
+	StorageBytes int64
+	// StorageReported is false when Quay does not report storage consumption, which requires quota management
+	StorageReported bool
+}
+
+// Get retrieves the usage of an organization from Quay
+func Get(quayClient *qclient.QuayClient, organizationName string) (Usage, error) {
+
+	organization, organizationResponse, organizationErr := quayClient.GetOrganizationByname(organizationName)
+
+	if organizationErr.Error != nil {
+		return Usage{}, fmt.Errorf("error retrieving organization '%s': %w", organizationName, organizationErr.Error)
+	}
+
+	if organizationResponse.StatusCode != 200 {
+		return Usage{}, fmt.Errorf("error retrieving organization '%s': status code %d", organizationName, organizationResponse.StatusCode)
+	}
+
+	repositories, repositoriesResponse, repositoriesErr := quayClient.GetRepositoriesByOrganization(organizationName)
+
+	if repositoriesErr.Error != nil {
+		return Usage{}, fmt.Errorf("error retrieving repositories of organization '%s': %w", organizationName, repositoriesErr.Error)
+	}
+
+	if repositoriesResponse == nil || repositoriesResponse.StatusCode != 200 {
+		return Usage{}, fmt.Errorf("error retrieving repositories of organization '%s'", organizationName)
+	}
+
+	usage := Usage{Repositories: len(repositories)}
+
+	if organization.QuotaReport != nil {
+		usage.StorageBytes = organization.QuotaReport.QuotaBytes
+		usage.StorageReported = true
+	}
+
+	return usage, nil
+}
+
+// Annotations returns the annotations recording the usage on a namespace
+func (u Usage) Annotations() map[string]string {
+
+	annotations := map[string]string{
+		RepositoryCountAnnotation: strconv.Itoa(u.Repositories),
+	}
+
+	if u.StorageReported {
+		annotations[StorageBytesAnnotation] = strconv.FormatInt(u.StorageBytes, 10)
+	}
+
+	return annotations
+}
+
+// ApplyAnnotations records the usage on a namespace and returns whether the annotations changed
+func ApplyAnnotations(namespace *corev1.Namespace, u Usage) bool {
+
+	desired := u.Annotations()
+	changed := false
+
+	if namespace.Annotations == nil {
+		namespace.Annotations = map[string]string{}
+	}
+
+	for _, key := range []string{StorageBytesAnnotation, RepositoryCountAnnotation} {
+
+		value, found := desired[key]
+		existing, exists := namespace.Annotations[key]
+
+		if !found && exists {
+			delete(namespace.Annotations, key)
+			changed = true
+		} else if found && (!exists || existing != value) {
+			namespace.Annotations[key] = value
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// OnlyAnnotationsChanged returns whether two revisions of a namespace differ only by the usage annotations, letting
+// watches ignore updates made while reporting usage
+func OnlyAnnotationsChanged(oldNamespace *corev1.Namespace, namespace *corev1.Namespace) bool {
+
+	strip := func(namespace *corev1.Namespace) *corev1.Namespace {
+
+		namespace = namespace.DeepCopy()
+		namespace.ResourceVersion = ""
+		namespace.ManagedFields = nil
+
+		delete(namespace.Annotations, StorageBytesAnnotation)
+		delete(namespace.Annotations, RepositoryCountAnnotation)
+
+		if len(namespace.Annotations) == 0 {
+			namespace.Annotations = nil
+		}
+
+		return namespace
+	}
+
+	if equality.Semantic.DeepEqual(oldNamespace.Annotations, namespace.Annotations) {
+		return false
+	}
+
+	return equality.Semantic.DeepEqual(strip(oldNamespace), strip(namespace))
+}
@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+)
+
+func TestApplyAnnotations(t *testing.T) {
+
+	cases := []struct {
+		annotations         map[string]string
+		usage               Usage
+		expectedAnnotations map[string]string
+		expectedChanged     bool
+	}{
+		{
+			annotations:         nil,
+			usage:               Usage{Repositories: 3, StorageBytes: 1024, StorageReported: true},
+			expectedAnnotations: map[string]string{RepositoryCountAnnotation: "3", StorageBytesAnnotation: "1024"},
+			expectedChanged:     true,
+		},
+		{
+			annotations:         map[string]string{RepositoryCountAnnotation: "3", StorageBytesAnnotation: "1024"},
+			usage:               Usage{Repositories: 3, StorageBytes: 1024, StorageReported: true},
+			expectedAnnotations: map[string]string{RepositoryCountAnnotation: "3", StorageBytesAnnotation: "1024"},
+			expectedChanged:     false,
+		},
+		{
+			annotations:         map[string]string{RepositoryCountAnnotation: "3", StorageBytesAnnotation: "1024", "owner": "team-a"},
+			usage:               Usage{Repositories: 4},
+			expectedAnnotations: map[string]string{RepositoryCountAnnotation: "4", "owner": "team-a"},
+			expectedChanged:     true,
+		},
+	}
+
+	for i, c := range cases {
+
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", Annotations: c.annotations}}
+
+		changed := ApplyAnnotations(namespace, c.usage)
+
+		if changed != c.expectedChanged || !reflect.DeepEqual(c.expectedAnnotations, namespace.Annotations) {
+			t.Errorf("Test case %d did not match\nExpected: %v %v\nActual: %v %v", i, c.expectedChanged, c.expectedAnnotations, changed, namespace.Annotations)
+		}
+	}
+}
+
+func TestOnlyAnnotationsChanged(t *testing.T) {
+
+	cases := []struct {
+		old      corev1.Namespace
+		new      corev1.Namespace
+		expected bool
+	}{
+		{
+			old:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "1"}},
+			new:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "2", Annotations: map[string]string{RepositoryCountAnnotation: "3"}}},
+			expected: true,
+		},
+		{
+			old:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "1", Annotations: map[string]string{RepositoryCountAnnotation: "3"}}},
+			new:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "2", Annotations: map[string]string{RepositoryCountAnnotation: "3", "owner": "team-a"}}},
+			expected: false,
+		},
+		{
+			old:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "1"}},
+			new:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "2", Labels: map[string]string{"team": "a"}}},
+			expected: false,
+		},
+		{
+			old:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "1"}},
+			new:      corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo", ResourceVersion: "2"}},
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		changed := OnlyAnnotationsChanged(&c.old, &c.new)
+
+		if changed != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, changed)
+		}
+	}
+}
+
+func TestSummarizeAggregatedLogs(t *testing.T) {
+
+	cases := []struct {
+		logs     []qclient.AggregatedLog
+		expected RepositoryStats
+	}{
+		{
+			logs:     nil,
+			expected: RepositoryStats{},
+		},
+		{
+			logs: []qclient.AggregatedLog{
+				{Kind: "pull_repo", Count: 3, Datetime: "Tue, 01 Jun 2021 00:00:00 -0000"},
+				{Kind: "pull_repo", Count: 2, Datetime: "Thu, 03 Jun 2021 00:00:00 -0000"},
+				{Kind: "push_repo", Count: 1, Datetime: "Fri, 04 Jun 2021 00:00:00 -0000"},
+				{Kind: "create_tag", Count: 1, Datetime: "Fri, 04 Jun 2021 00:00:00 -0000"},
+			},
+			expected: RepositoryStats{Pulls: 5, Pushes: 1, LastPull: time.Date(2021, 6, 3, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			logs: []qclient.AggregatedLog{
+				{Kind: "pull_repo", Count: 4, Datetime: "invalid"},
+			},
+			expected: RepositoryStats{Pulls: 4},
+		},
+	}
+
+	for i, c := range cases {
+
+		stats := SummarizeAggregatedLogs(c.logs)
+
+		if stats.Pulls != c.expected.Pulls || stats.Pushes != c.expected.Pushes || !stats.LastPull.Equal(c.expected.LastPull) {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, stats)
+		}
+	}
+}
@@ -1,14 +1,77 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"net/mail"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
 
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
 	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/credentials"
 	"github.com/quay/quay-bridge-operator/pkg/logging"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+const (
+	// defaultOrganizationEmailTemplate derives the email address of organizations when no template is configured
+	defaultOrganizationEmailTemplate = "{{ .OrgName }}@redhat.com"
+	// organizationEmailHashLength is the number of hexadecimal characters of the hash appended to email addresses
+	organizationEmailHashLength = 8
+	// pullGrantRobotPrefix prefixes the robot accounts granted read access to an organization on behalf of another namespace
+	pullGrantRobotPrefix = "grant_"
+	// userRobotPrefix prefixes the robot accounts of the operator account granted access to the repositories of user namespaces
+	userRobotPrefix = "user_"
+	// serviceAccountRobotPrefix prefixes the robot accounts created for service accounts labeled with a robot role
+	serviceAccountRobotPrefix = "sa_"
+	// nodesRobotPrefix prefixes the robot accounts of the operator account added to the global pull secret of a cluster
+	nodesRobotPrefix = "nodes_"
+)
+
+var (
+	// repositoryNameRegex matches the names of repositories accepted by Quay
+	repositoryNameRegex = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+	// usernameRegex matches the names of user accounts accepted by Quay
+	usernameRegex = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+	// invalidRobotNameCharacters matches the characters Quay does not accept in the shortname of robot accounts
+	invalidRobotNameCharacters = regexp.MustCompile(`[^a-z0-9_]`)
+	// robotNameRegex matches the shortnames of robot accounts accepted by Quay
+	robotNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,254}$`)
+)
+
+// SecretNameTemplateData is the data available to templates used to name generated Secrets
+type SecretNameTemplateData struct {
+	OrgName        string
+	Namespace      string
+	ServiceAccount string
+	ClusterID      string
+}
+
+// RobotNameTemplateData is the data available to templates used to name robot accounts
+type RobotNameTemplateData struct {
+	OrgName        string
+	Namespace      string
+	ServiceAccount string
+	ClusterID      string
+}
+
+// OrganizationEmailTemplateData is the data available to templates used to derive the email address of organizations
+type OrganizationEmailTemplateData struct {
+	OrgName   string
+	Namespace string
+	ClusterID string
+}
+
 func IsZeroOfUnderlyingType(x interface{}) bool {
 	return reflect.DeepEqual(x, reflect.Zero(reflect.TypeOf(x)).Interface())
 }
@@ -66,6 +129,407 @@ func GenerateDockerJsonSecretNameForServiceAccount(serviceAccount string, quayNa
 	return fmt.Sprintf("%s-quay-%s", serviceAccount, quayName)
 }
 
+func GenerateDockerCfgSecretNameForServiceAccount(serviceAccount string, quayName string) string {
+	return fmt.Sprintf("%s-quay-%s-dockercfg", serviceAccount, quayName)
+}
+
+func GenerateBasicAuthSecretNameForServiceAccount(serviceAccount string, quayName string) string {
+	return fmt.Sprintf("%s-quay-%s-basic-auth", serviceAccount, quayName)
+}
+
+// GenerateRobotAccountSecretName returns the name of the Secret of the requested format for a robot account.
+// When a SecretNameTemplate is configured, formats other than dockerconfigjson are suffixed with the format
+func GenerateRobotAccountSecretName(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string, serviceAccount string, secretFormat quayv1.SecretFormat) (string, error) {
+
+	if quayIntegration.Spec.SecretNameTemplate == "" {
+		switch secretFormat {
+		case quayv1.DockerCfgSecretFormat:
+			return GenerateDockerCfgSecretNameForServiceAccount(serviceAccount, quayIntegration.Spec.ClusterID), nil
+		case quayv1.BasicAuthSecretFormat:
+			return GenerateBasicAuthSecretNameForServiceAccount(serviceAccount, quayIntegration.Spec.ClusterID), nil
+		default:
+			return GenerateDockerJsonSecretNameForServiceAccount(serviceAccount, quayIntegration.Spec.ClusterID), nil
+		}
+	}
+
+	secretName, err := RenderSecretName(quayIntegration.Spec.SecretNameTemplate, SecretNameTemplateData{
+		OrgName:        quayOrganizationName,
+		Namespace:      namespace,
+		ServiceAccount: serviceAccount,
+		ClusterID:      quayIntegration.Spec.ClusterID,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if secretFormat != quayv1.DockerConfigJsonSecretFormat {
+		secretName = fmt.Sprintf("%s-%s", secretName, secretFormat)
+	}
+
+	return secretName, nil
+}
+
+// GenerateStaleRobotAccountSecretNames returns the names the Secrets of the robot account of a service account have in the formats which
+// are not configured, such as formats removed from the QuayIntegration. Names which cannot be generated from the SecretNameTemplate are skipped
+func GenerateStaleRobotAccountSecretNames(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string, serviceAccount string) []string {
+
+	configured := map[string]bool{}
+
+	for _, secretFormat := range quayIntegration.GetSecretFormats() {
+		if secretName, err := GenerateRobotAccountSecretName(quayIntegration, namespace, quayOrganizationName, serviceAccount, secretFormat); err == nil {
+			configured[secretName] = true
+		}
+	}
+
+	secretNames := []string{}
+
+	for _, secretFormat := range quayv1.AllSecretFormats {
+
+		secretName, err := GenerateRobotAccountSecretName(quayIntegration, namespace, quayOrganizationName, serviceAccount, secretFormat)
+
+		if err != nil || configured[secretName] {
+			continue
+		}
+
+		secretNames = append(secretNames, secretName)
+	}
+
+	return secretNames
+}
+
+// ParseRobotAccountSecretNames returns the sorted robot account Secret names recorded on a service account, ignoring invalid names and duplicates
+func ParseRobotAccountSecretNames(value string) []string {
+
+	secretNames := []string{}
+	found := map[string]bool{}
+
+	for _, secretName := range strings.Split(value, ",") {
+
+		secretName = strings.TrimSpace(secretName)
+
+		if found[secretName] || len(validation.IsDNS1123Subdomain(secretName)) > 0 {
+			continue
+		}
+
+		found[secretName] = true
+		secretNames = append(secretNames, secretName)
+	}
+
+	sort.Strings(secretNames)
+
+	return secretNames
+}
+
+// GenerateRobotAccountSecretNames returns the names of the Secrets of the robot accounts of the default and builder service
+// accounts of a namespace in every configured format. Names which cannot be generated from the SecretNameTemplate are skipped
+func GenerateRobotAccountSecretNames(quayIntegration *quayv1.QuayIntegration, namespace string) []string {
+
+	quayOrganizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+
+	secretNames := []string{}
+
+	for _, serviceAccount := range []qotypes.OpenShiftServiceAccount{qotypes.DefaultOpenShiftServiceAccount, qotypes.BuilderOpenShiftServiceAccount} {
+		for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+			secretName, err := GenerateRobotAccountSecretName(quayIntegration, namespace, quayOrganizationName, string(serviceAccount), secretFormat)
+
+			if err != nil {
+				continue
+			}
+
+			secretNames = append(secretNames, secretName)
+		}
+	}
+
+	return secretNames
+}
+
+// GeneratePullSecretName returns the name of the pull secret of the default service account of a namespace, in the first configured
+// format usable as a pull secret. Returns false when only basic auth Secrets are generated
+func GeneratePullSecretName(quayIntegration *quayv1.QuayIntegration, namespace string) (string, bool, error) {
+	return GenerateRobotAccountPullSecretName(quayIntegration, namespace, quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace), string(qotypes.DefaultOpenShiftServiceAccount))
+}
+
+// GenerateRobotAccountPullSecretName returns the name of the Secret of the robot account of a service account in the first configured
+// format usable as a pull or push secret. Returns false when only basic auth Secrets are generated
+func GenerateRobotAccountPullSecretName(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string, serviceAccount string) (string, bool, error) {
+
+	for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+		if secretFormat == quayv1.BasicAuthSecretFormat {
+			continue
+		}
+
+		secretName, err := GenerateRobotAccountSecretName(quayIntegration, namespace, quayOrganizationName, serviceAccount, secretFormat)
+
+		return secretName, err == nil, err
+	}
+
+	return "", false, nil
+}
+
+// GenerateRobotAccountName returns the shortname of the robot account created in the organization of a namespace for a service account.
+// Defaults to the name of the service account unless a RobotNameTemplate is configured. Service accounts other than the builder,
+// default and deployer service accounts are prefixed, keeping their robot accounts apart from those created by the operator
+func GenerateRobotAccountName(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string, serviceAccount string) (string, error) {
+
+	if quayIntegration.Spec.RobotNameTemplate == "" {
+		switch qotypes.OpenShiftServiceAccount(serviceAccount) {
+		case qotypes.BuilderOpenShiftServiceAccount, qotypes.DefaultOpenShiftServiceAccount, qotypes.DeployerOpenShiftServiceAccount:
+			return serviceAccount, nil
+		}
+
+		return serviceAccountRobotPrefix + invalidRobotNameCharacters.ReplaceAllString(serviceAccount, "_"), nil
+	}
+
+	return RenderRobotName(quayIntegration.Spec.RobotNameTemplate, RobotNameTemplateData{
+		OrgName:        quayOrganizationName,
+		Namespace:      namespace,
+		ServiceAccount: serviceAccount,
+		ClusterID:      quayIntegration.Spec.ClusterID,
+	})
+}
+
+// RenderRobotName renders the shortname of a robot account from a template. The result is lowercased and hyphens and dots
+// are replaced with underscores so that namespace and organization names can be used within the template
+func RenderRobotName(nameTemplate string, data RobotNameTemplateData) (string, error) {
+
+	tmpl, err := template.New("robotName").Parse(nameTemplate)
+
+	if err != nil {
+		return "", err
+	}
+
+	var name strings.Builder
+
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", err
+	}
+
+	robotName := strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToLower(strings.TrimSpace(name.String())))
+
+	if !robotNameRegex.MatchString(robotName) {
+		return "", fmt.Errorf("invalid robot account name '%s': must start with a letter and only contain lowercase letters, digits and underscores", robotName)
+	}
+
+	return robotName, nil
+}
+
+// RenderSecretName renders a Secret name from a template. The result is lowercased and underscores are
+// replaced with hyphens so that Quay organization names can be used within the template
+func RenderSecretName(nameTemplate string, data SecretNameTemplateData) (string, error) {
+
+	tmpl, err := template.New("secretName").Parse(nameTemplate)
+
+	if err != nil {
+		return "", err
+	}
+
+	var name strings.Builder
+
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", err
+	}
+
+	secretName := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name.String())), "_", "-")
+
+	if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+		return "", fmt.Errorf("invalid secret name '%s': %s", secretName, strings.Join(errs, ", "))
+	}
+
+	return secretName, nil
+}
+
+// GenerateOrganizationEmail returns the email address of an organization, applying the configured uniqueness strategy
+func GenerateOrganizationEmail(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string) (string, error) {
+
+	email, err := RenderOrganizationEmail(quayIntegration, namespace, quayOrganizationName)
+
+	if err != nil {
+		return "", err
+	}
+
+	if quayIntegration.GetOrganizationEmailStrategy() == quayv1.HashSuffixOrganizationEmailStrategy {
+		email = AppendOrganizationEmailHash(email, quayIntegration.Spec.ClusterID, quayOrganizationName)
+	}
+
+	return email, nil
+}
+
+// RenderOrganizationEmail renders the email address of an organization from the configured template
+func RenderOrganizationEmail(quayIntegration *quayv1.QuayIntegration, namespace string, quayOrganizationName string) (string, error) {
+
+	emailTemplate := quayIntegration.Spec.OrganizationEmailTemplate
+
+	if emailTemplate == "" {
+		emailTemplate = defaultOrganizationEmailTemplate
+	}
+
+	tmpl, err := template.New("organizationEmail").Parse(emailTemplate)
+
+	if err != nil {
+		return "", err
+	}
+
+	var email strings.Builder
+
+	if err := tmpl.Execute(&email, OrganizationEmailTemplateData{
+		OrgName:   quayOrganizationName,
+		Namespace: namespace,
+		ClusterID: quayIntegration.Spec.ClusterID,
+	}); err != nil {
+		return "", err
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(email.String()))
+
+	if err != nil || address.Name != "" {
+		return "", fmt.Errorf("invalid organization email '%s'", strings.TrimSpace(email.String()))
+	}
+
+	return address.Address, nil
+}
+
+// AppendOrganizationEmailHash appends a hash of the cluster ID and organization to the local part of an email address,
+// keeping the addresses of organizations of different clusters sharing a template unique
+func AppendOrganizationEmailHash(email string, clusterID string, quayOrganizationName string) string {
+
+	at := strings.LastIndex(email, "@")
+
+	if at < 0 {
+		return email
+	}
+
+	hash := sha256.Sum256([]byte(clusterID + "/" + quayOrganizationName))
+
+	return fmt.Sprintf("%s+%s%s", email[:at], hex.EncodeToString(hash[:])[:organizationEmailHashLength], email[at:])
+}
+
+// IsValidRepositoryName determines whether a name can be used as a Quay repository
+func IsValidRepositoryName(name string) bool {
+	return repositoryNameRegex.MatchString(name)
+}
+
+// ParseQuayImageReference returns the organization, repository and tag of an image hosted by a Quay registry. Images of other
+// registries, nested repositories and images referenced by digest are not matched. The tag defaults to latest
+func ParseQuayImageReference(registryHostname string, image string) (string, string, string, bool) {
+
+	if strings.Contains(image, "@") {
+		return "", "", "", false
+	}
+
+	parts := strings.Split(image, "/")
+
+	if len(parts) != 3 || parts[0] != registryHostname {
+		return "", "", "", false
+	}
+
+	repository, tag := parts[2], "latest"
+
+	if i := strings.LastIndex(repository, ":"); i >= 0 {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+
+	if parts[1] == "" || !IsValidRepositoryName(repository) || tag == "" {
+		return "", "", "", false
+	}
+
+	return parts[1], repository, tag, true
+}
+
+// ParsePullGrants returns the sorted namespaces listed by a pull grant annotation, ignoring invalid names, duplicates and the granting namespace
+func ParsePullGrants(namespace string, value string) []string {
+
+	grantees := []string{}
+	found := map[string]bool{}
+
+	for _, grantee := range strings.Split(value, ",") {
+
+		grantee = strings.TrimSpace(grantee)
+
+		if grantee == namespace || found[grantee] || len(validation.IsDNS1123Label(grantee)) > 0 {
+			continue
+		}
+
+		found[grantee] = true
+		grantees = append(grantees, grantee)
+	}
+
+	sort.Strings(grantees)
+
+	return grantees
+}
+
+// GeneratePullGrantRobotName returns the shortname of the robot account granted read access on behalf of a namespace.
+// Namespace names never contain underscores, so distinct namespaces produce distinct robot accounts
+func GeneratePullGrantRobotName(grantee string) string {
+	return pullGrantRobotPrefix + strings.ReplaceAll(grantee, "-", "_")
+}
+
+// GeneratePullGrantSecretName returns the name of the Secret containing the credentials granted by a namespace
+func GeneratePullGrantSecretName(namespace string) string {
+	return fmt.Sprintf("quay-pull-grant-%s", namespace)
+}
+
+// GetUserAccount returns the Quay user account holding the repositories of a namespace when user repositories are enabled for it.
+// Namespaces are mapped to the account of the user who requested them, provided the name of the user is a valid Quay username
+func GetUserAccount(quayIntegration *quayv1.QuayIntegration, namespace *corev1.Namespace) (string, bool) {
+
+	if quayIntegration.Spec.UserRepositories == nil || namespace == nil {
+		return "", false
+	}
+
+	if namespaces := quayIntegration.Spec.UserRepositories.Namespaces; len(namespaces) > 0 && !contains(namespaces, namespace.Name) {
+		return "", false
+	}
+
+	username := strings.ToLower(namespace.Annotations[constants.OpenShiftRequesterAnnotation])
+
+	if !usernameRegex.MatchString(username) {
+		return "", false
+	}
+
+	return username, true
+}
+
+// GetRepositoryNamespace returns the Quay organization or user account holding the repositories of a namespace. The organization
+// is returned when the Namespace is not provided
+func GetRepositoryNamespace(quayIntegration *quayv1.QuayIntegration, name string, namespace *corev1.Namespace) string {
+
+	if namespace != nil && namespace.Name == name {
+		if username, ok := GetUserAccount(quayIntegration, namespace); ok {
+			return username
+		}
+	}
+
+	return quayIntegration.GenerateQuayOrganizationNameFromNamespace(name)
+}
+
+// GenerateUserRobotName returns the shortname of the robot account of the operator account used by a service account of a namespace
+// mapped to a Quay user account. The organization name of the namespace includes the cluster ID, so that clusters sharing the
+// account of the operator use distinct robot accounts
+func GenerateUserRobotName(quayOrganizationName string, serviceAccount string) string {
+	return userRobotPrefix + invalidRobotNameCharacters.ReplaceAllString(quayOrganizationName, "_") + "_" + serviceAccount
+}
+
+// GenerateNodesRobotName returns the shortname of the robot account of the operator account added to the global pull secret of a
+// cluster, granted read access to every organization of the cluster. Clusters sharing the account of the operator use distinct robot accounts
+func GenerateNodesRobotName(clusterID string) string {
+	return nodesRobotPrefix + invalidRobotNameCharacters.ReplaceAllString(strings.ToLower(clusterID), "_")
+}
+
+func contains(values []string, value string) bool {
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func LocalObjectReferenceNameExists(localObjectReferenceNames []corev1.LocalObjectReference, name string) bool {
 
 	for _, l := range localObjectReferenceNames {
@@ -95,3 +559,60 @@ func IsOpenShiftAnnotatedNamespace(namespace *corev1.Namespace) bool {
 
 	return displayNameFound && descriptionFound
 }
+
+// IsProvisioningPending returns whether a namespace annotated when its project was requested has not been synchronized yet
+func IsProvisioningPending(namespace *corev1.Namespace) bool {
+	return namespace.Annotations[constants.NamespaceProvisioningAnnotation] == constants.NamespaceProvisioningPending
+}
+
+// HasRobotAccountSecrets returns whether the Secrets of the robot account of a service account exist in a namespace in every configured
+// format and contain credentials for the registry. Secrets not written to the namespace by the operator are not checked
+func HasRobotAccountSecrets(quayIntegration *quayv1.QuayIntegration, namespace string, serviceAccount string, getSecret func(name string) *corev1.Secret) bool {
+
+	if !quayIntegration.ManagesClusterSecrets() || quayIntegration.Spec.SecretStore != nil {
+		return true
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return false
+	}
+
+	quayOrganizationName := quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace)
+
+	for _, secretFormat := range quayIntegration.GetSecretFormats() {
+
+		secretName, err := GenerateRobotAccountSecretName(quayIntegration, namespace, quayOrganizationName, serviceAccount, secretFormat)
+
+		if err != nil {
+			return false
+		}
+
+		secret := getSecret(secretName)
+
+		if secret == nil || !credentials.HasRegistryAuth(secret, registryHostname) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ReadCertificate reads the first PEM encoded certificate from a file
+func ReadCertificate(certPath string) (*x509.Certificate, error) {
+
+	certBytes, err := ioutil.ReadFile(certPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(certBytes)
+
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", certPath)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"reflect"
 	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestRobotAccountName(t *testing.T) {
@@ -32,3 +38,468 @@ func TestRobotAccountName(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderSecretName(t *testing.T) {
+
+	data := SecretNameTemplateData{
+		OrgName:        "openshift_test",
+		Namespace:      "test",
+		ServiceAccount: "builder",
+		ClusterID:      "openshift",
+	}
+
+	cases := []struct {
+		name          string
+		nameTemplate  string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:         "test-render-secret-name-organization",
+			nameTemplate: "{{ .OrgName }}-{{ .ServiceAccount }}-quay-pull",
+			expected:     "openshift-test-builder-quay-pull",
+		},
+		{
+			name:         "test-render-secret-name-default",
+			nameTemplate: "{{ .ServiceAccount }}-quay-{{ .ClusterID }}",
+			expected:     "builder-quay-openshift",
+		},
+		{
+			name:          "test-render-secret-name-unknown-field",
+			nameTemplate:  "{{ .Unknown }}",
+			expectedError: true,
+		},
+		{
+			name:          "test-render-secret-name-invalid",
+			nameTemplate:  "{{ .Namespace }}/pull",
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			result, err := RenderSecretName(c.nameTemplate, data)
+
+			if c.expectedError != (err != nil) {
+				t.Errorf("Test case %d did not match\nExpected Error: %#v\nActual: %#v", i, c.expectedError, err)
+			}
+
+			if c.expected != result {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateStaleRobotAccountSecretNames(t *testing.T) {
+
+	cases := []struct {
+		name               string
+		secretFormats      []quayv1.SecretFormat
+		secretNameTemplate string
+		expected           []string
+	}{
+		{
+			name:     "test-stale-secret-names-default",
+			expected: []string{GenerateDockerCfgSecretNameForServiceAccount("builder", "openshift"), GenerateBasicAuthSecretNameForServiceAccount("builder", "openshift")},
+		},
+		{
+			name:          "test-stale-secret-names-all-formats",
+			secretFormats: []quayv1.SecretFormat{quayv1.BasicAuthSecretFormat, quayv1.DockerCfgSecretFormat, quayv1.DockerConfigJsonSecretFormat},
+			expected:      []string{},
+		},
+		{
+			name:               "test-stale-secret-names-template",
+			secretFormats:      []quayv1.SecretFormat{quayv1.DockerCfgSecretFormat},
+			secretNameTemplate: "{{ .OrgName }}-{{ .ServiceAccount }}-quay-pull",
+			expected:           []string{"openshift-test-builder-quay-pull", "openshift-test-builder-quay-pull-basic-auth"},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			quayIntegration := &quayv1.QuayIntegration{Spec: quayv1.QuayIntegrationSpec{ClusterID: "openshift", SecretFormats: c.secretFormats, SecretNameTemplate: c.secretNameTemplate}}
+
+			result := GenerateStaleRobotAccountSecretNames(quayIntegration, "test", "openshift_test", "builder")
+
+			if !reflect.DeepEqual(c.expected, result) {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateRobotAccountPullSecretName(t *testing.T) {
+
+	cases := []struct {
+		name          string
+		secretFormats []quayv1.SecretFormat
+		expectedName  string
+		expectedOk    bool
+	}{
+		{
+			name:         "test-pull-secret-name-default",
+			expectedName: "builder-quay-openshift",
+			expectedOk:   true,
+		},
+		{
+			name:          "test-pull-secret-name-dockercfg",
+			secretFormats: []quayv1.SecretFormat{quayv1.BasicAuthSecretFormat, quayv1.DockerCfgSecretFormat},
+			expectedName:  "builder-quay-openshift-dockercfg",
+			expectedOk:    true,
+		},
+		{
+			name:          "test-pull-secret-name-basic-auth",
+			secretFormats: []quayv1.SecretFormat{quayv1.BasicAuthSecretFormat},
+			expectedName:  "",
+			expectedOk:    false,
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			quayIntegration := &quayv1.QuayIntegration{Spec: quayv1.QuayIntegrationSpec{ClusterID: "openshift", SecretFormats: c.secretFormats}}
+
+			name, ok, err := GenerateRobotAccountPullSecretName(quayIntegration, "test", "openshift_test", "builder")
+
+			if err != nil {
+				t.Fatalf("Test case %d returned an error: %v", i, err)
+			}
+
+			if c.expectedName != name || c.expectedOk != ok {
+				t.Errorf("Test case %d did not match\nExpected: %#v, %#v\nActual: %#v, %#v", i, c.expectedName, c.expectedOk, name, ok)
+			}
+		})
+	}
+}
+
+func TestRenderRobotName(t *testing.T) {
+
+	data := RobotNameTemplateData{
+		OrgName:        "openshift_test",
+		Namespace:      "team-a.dev",
+		ServiceAccount: "builder",
+		ClusterID:      "openshift",
+	}
+
+	cases := []struct {
+		name          string
+		nameTemplate  string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:         "test-render-robot-name-cluster",
+			nameTemplate: "{{ .ClusterID }}_{{ .ServiceAccount }}",
+			expected:     "openshift_builder",
+		},
+		{
+			name:         "test-render-robot-name-namespace",
+			nameTemplate: "{{ .Namespace }}-{{ .ServiceAccount }}",
+			expected:     "team_a_dev_builder",
+		},
+		{
+			name:          "test-render-robot-name-leading-digit",
+			nameTemplate:  "1{{ .ServiceAccount }}",
+			expectedError: true,
+		},
+		{
+			name:          "test-render-robot-name-invalid",
+			nameTemplate:  "{{ .ServiceAccount }}+{{ .OrgName }}",
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			result, err := RenderRobotName(c.nameTemplate, data)
+
+			if c.expectedError != (err != nil) {
+				t.Errorf("Test case %d did not match\nExpected Error: %#v\nActual: %#v", i, c.expectedError, err)
+			}
+
+			if c.expected != result {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateRobotAccountName(t *testing.T) {
+
+	cases := []struct {
+		name              string
+		robotNameTemplate string
+		serviceAccount    string
+		expected          string
+	}{
+		{
+			name:           "test-generate-robot-account-name-builder",
+			serviceAccount: "builder",
+			expected:       "builder",
+		},
+		{
+			name:           "test-generate-robot-account-name-custom",
+			serviceAccount: "my-app.pipeline",
+			expected:       "sa_my_app_pipeline",
+		},
+		{
+			name:              "test-generate-robot-account-name-template",
+			robotNameTemplate: "{{ .ClusterID }}_{{ .ServiceAccount }}",
+			serviceAccount:    "my-app",
+			expected:          "openshift_my_app",
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			quayIntegration := &quayv1.QuayIntegration{Spec: quayv1.QuayIntegrationSpec{ClusterID: "openshift", RobotNameTemplate: c.robotNameTemplate}}
+
+			result, err := GenerateRobotAccountName(quayIntegration, "my-project", "openshift_my-project", c.serviceAccount)
+
+			if err != nil {
+				t.Errorf("Test case %d did not match\nUnexpected Error: %#v", i, err)
+			}
+
+			if c.expected != result {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateOrganizationEmail(t *testing.T) {
+
+	cases := []struct {
+		name          string
+		emailTemplate string
+		strategy      quayv1.OrganizationEmailStrategy
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "test-organization-email-default",
+			expected: "openshift_test@redhat.com",
+		},
+		{
+			name:          "test-organization-email-template",
+			emailTemplate: "quay+{{ .ClusterID }}-{{ .Namespace }}@example.com",
+			expected:      "quay+openshift-test@example.com",
+		},
+		{
+			name:     "test-organization-email-hash-suffix",
+			strategy: quayv1.HashSuffixOrganizationEmailStrategy,
+			expected: "openshift_test+46856994@redhat.com",
+		},
+		{
+			name:          "test-organization-email-invalid",
+			emailTemplate: "{{ .OrgName }}",
+			expectedError: true,
+		},
+		{
+			name:          "test-organization-email-unknown-field",
+			emailTemplate: "{{ .Unknown }}@example.com",
+			expectedError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			quayIntegration := &quayv1.QuayIntegration{
+				Spec: quayv1.QuayIntegrationSpec{
+					ClusterID:                 "openshift",
+					OrganizationEmailTemplate: c.emailTemplate,
+					OrganizationEmailStrategy: c.strategy,
+				},
+			}
+
+			result, err := GenerateOrganizationEmail(quayIntegration, "test", "openshift_test")
+
+			if c.expectedError != (err != nil) {
+				t.Errorf("Test case %d did not match\nExpected Error: %#v\nActual: %#v", i, c.expectedError, err)
+			}
+
+			if c.expected != result {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseRobotAccountSecretNames(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{
+			name:     "test-empty-secret-names",
+			value:    "",
+			expected: []string{},
+		},
+		{
+			name:     "test-secret-names",
+			value:    "team-a-quay-pull, team-a-builder-quay-pull,team-a-quay-pull",
+			expected: []string{"team-a-builder-quay-pull", "team-a-quay-pull"},
+		},
+		{
+			name:     "test-invalid-secret-names",
+			value:    "Team_A,team-a-quay-pull",
+			expected: []string{"team-a-quay-pull"},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			result := ParseRobotAccountSecretNames(c.value)
+
+			if !reflect.DeepEqual(c.expected, result) {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestParsePullGrants(t *testing.T) {
+
+	cases := []struct {
+		name      string
+		namespace string
+		value     string
+		expected  []string
+	}{
+		{
+			name:      "test-empty-pull-grants",
+			namespace: "team-a",
+			value:     "",
+			expected:  []string{},
+		},
+		{
+			name:      "test-pull-grants",
+			namespace: "team-a",
+			value:     "team-c, team-b,team-c",
+			expected:  []string{"team-b", "team-c"},
+		},
+		{
+			name:      "test-invalid-pull-grants",
+			namespace: "team-a",
+			value:     "team-a,Team_B,team-d",
+			expected:  []string{"team-d"},
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			result := ParsePullGrants(c.namespace, c.value)
+
+			if !reflect.DeepEqual(c.expected, result) {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetRepositoryNamespace(t *testing.T) {
+
+	cases := []struct {
+		name             string
+		userRepositories *quayv1.UserRepositories
+		requester        string
+		expected         string
+	}{
+		{
+			name:      "test-organization",
+			requester: "developer",
+			expected:  "openshift_dev",
+		},
+		{
+			name:             "test-user-account",
+			userRepositories: &quayv1.UserRepositories{},
+			requester:        "Developer",
+			expected:         "developer",
+		},
+		{
+			name:             "test-no-requester",
+			userRepositories: &quayv1.UserRepositories{},
+			expected:         "openshift_dev",
+		},
+		{
+			name:             "test-invalid-username",
+			userRepositories: &quayv1.UserRepositories{},
+			requester:        "kube:admin",
+			expected:         "openshift_dev",
+		},
+		{
+			name:             "test-not-listed",
+			userRepositories: &quayv1.UserRepositories{Namespaces: []string{"other"}},
+			requester:        "developer",
+			expected:         "openshift_dev",
+		},
+		{
+			name:             "test-listed",
+			userRepositories: &quayv1.UserRepositories{Namespaces: []string{"dev"}},
+			requester:        "developer",
+			expected:         "developer",
+		},
+	}
+
+	for i, c := range cases {
+
+		t.Run(c.name, func(t *testing.T) {
+
+			quayIntegration := &quayv1.QuayIntegration{Spec: quayv1.QuayIntegrationSpec{ClusterID: "openshift", UserRepositories: c.userRepositories}}
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Annotations: map[string]string{constants.OpenShiftRequesterAnnotation: c.requester}}}
+
+			result := GetRepositoryNamespace(quayIntegration, "dev", namespace)
+
+			if result != c.expected {
+				t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseQuayImageReference(t *testing.T) {
+
+	cases := []struct {
+		image              string
+		expectedOrg        string
+		expectedRepository string
+		expectedTag        string
+		expectedOk         bool
+	}{
+		{image: "quay.example.com/openshift_app/base:1.0", expectedOrg: "openshift_app", expectedRepository: "base", expectedTag: "1.0", expectedOk: true},
+		{image: "quay.example.com/openshift_app/base", expectedOrg: "openshift_app", expectedRepository: "base", expectedTag: "latest", expectedOk: true},
+		{image: "quay.example.com:8443/openshift_app/base:1.0", expectedOk: false},
+		{image: "registry.example.com/openshift_app/base:1.0", expectedOk: false},
+		{image: "quay.example.com/openshift_app/base@sha256:0123", expectedOk: false},
+		{image: "quay.example.com/openshift_app/nested/base:1.0", expectedOk: false},
+		{image: "quay.example.com/openshift_app/base:", expectedOk: false},
+	}
+
+	for i, c := range cases {
+
+		org, repository, tag, ok := ParseQuayImageReference("quay.example.com", c.image)
+
+		if org != c.expectedOrg || repository != c.expectedRepository || tag != c.expectedTag || ok != c.expectedOk {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %s %v\nActual: %s %s %s %v", i, c.expectedOrg, c.expectedRepository, c.expectedTag, c.expectedOk, org, repository, tag, ok)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package version
+
+// Version is the version of the operator, set at build time using -ldflags "-X github.com/quay/quay-bridge-operator/pkg/version.Version=<version>"
+var Version = "dev"
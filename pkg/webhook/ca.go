@@ -0,0 +1,507 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultCertificateCheckInterval is the interval at which the serving certificate managed by the operator is checked for rotation
+	DefaultCertificateCheckInterval = time.Hour
+
+	// CACertKey and CAKeyKey hold the CA in the serving certificate Secret. CACertKey may contain the previous CA while it is rotated
+	CACertKey = "ca.crt"
+	CAKeyKey  = "ca.key"
+
+	caValidity   = 5 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+	// caRotationThreshold ensures serving certificates never outlive the CA signing them
+	caRotationThreshold   = certValidity
+	certRotationThreshold = 90 * 24 * time.Hour
+)
+
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;update
+
+// CertificateManager provisions a self-signed CA and the serving certificate of the webhooks for installations without OLM or the
+// OpenShift service CA. The certificates are stored in a Secret shared by the replicas, written to the certificate directory
+// of the webhook server and the CA bundle is injected into the webhook configurations routing requests to the webhook Service
+type CertificateManager struct {
+	Client      client.Client
+	Namespace   string
+	SecretName  string
+	ServiceName string
+	CertDir     string
+	CertName    string
+	KeyName     string
+
+	CheckInterval time.Duration
+}
+
+// NeedLeaderElection lets every replica write the certificate it serves
+func (m *CertificateManager) NeedLeaderElection() bool {
+	return false
+}
+
+// Start checks the certificates for rotation at the check interval until the context is done
+func (m *CertificateManager) Start(ctx context.Context) error {
+
+	ticker := time.NewTicker(m.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := m.Ensure(ctx); err != nil {
+				logging.Log.Error(err, "Unable to ensure webhook certificate", "Secret", m.SecretName)
+			}
+		}
+	}
+}
+
+// Ensure issues or rotates the certificates, publishes the CA bundle to the webhook configurations and then writes the serving
+// certificate, so the webhooks never serve a certificate the API server cannot verify
+func (m *CertificateManager) Ensure(ctx context.Context) error {
+
+	secret := &corev1.Secret{}
+	exists := true
+
+	if err := m.Client.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.SecretName}, secret); err != nil {
+
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+
+		exists = false
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: m.Namespace, Name: m.SecretName},
+			Type:       corev1.SecretTypeTLS,
+		}
+	}
+
+	data, changed, err := IssueCertificates(secret.Data, m.dnsNames(), time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	if changed {
+
+		logging.Log.Info("Issuing webhook certificate", "Secret", m.SecretName, "DNS Names", m.dnsNames())
+
+		secret.Data = data
+
+		// Replicas rotating concurrently conflict, the certificates of the replica which wrote the Secret first are used
+		if exists {
+			err = m.Client.Update(ctx, secret)
+		} else {
+			err = m.Client.Create(ctx, secret)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := m.injectCABundle(ctx, data[CACertKey]); err != nil {
+		return err
+	}
+
+	return m.writeCertificate(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+}
+
+// dnsNames returns the names the webhook Service is reached at
+func (m *CertificateManager) dnsNames() []string {
+	return []string{
+		m.ServiceName,
+		fmt.Sprintf("%s.%s", m.ServiceName, m.Namespace),
+		fmt.Sprintf("%s.%s.svc", m.ServiceName, m.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", m.ServiceName, m.Namespace),
+	}
+}
+
+// injectCABundle sets the CA bundle of the webhooks routed to the webhook Service
+func (m *CertificateManager) injectCABundle(ctx context.Context, caBundle []byte) error {
+
+	mutatingWebhookConfigurations := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+
+	if err := m.Client.List(ctx, mutatingWebhookConfigurations); err != nil {
+		return err
+	}
+
+	for i := range mutatingWebhookConfigurations.Items {
+
+		configuration := &mutatingWebhookConfigurations.Items[i]
+		updated := false
+
+		for j := range configuration.Webhooks {
+			updated = m.setCABundle(&configuration.Webhooks[j].ClientConfig, caBundle) || updated
+		}
+
+		if !updated {
+			continue
+		}
+
+		logging.Log.Info("Injecting CA bundle", "MutatingWebhookConfiguration", configuration.Name)
+
+		if err := m.Client.Update(ctx, configuration); err != nil {
+			return err
+		}
+	}
+
+	validatingWebhookConfigurations := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+
+	if err := m.Client.List(ctx, validatingWebhookConfigurations); err != nil {
+		return err
+	}
+
+	for i := range validatingWebhookConfigurations.Items {
+
+		configuration := &validatingWebhookConfigurations.Items[i]
+		updated := false
+
+		for j := range configuration.Webhooks {
+			updated = m.setCABundle(&configuration.Webhooks[j].ClientConfig, caBundle) || updated
+		}
+
+		if !updated {
+			continue
+		}
+
+		logging.Log.Info("Injecting CA bundle", "ValidatingWebhookConfiguration", configuration.Name)
+
+		if err := m.Client.Update(ctx, configuration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setCABundle sets the CA bundle of a webhook routed to the webhook Service, returning whether it changed
+func (m *CertificateManager) setCABundle(clientConfig *admissionregistrationv1.WebhookClientConfig, caBundle []byte) bool {
+
+	if clientConfig.Service == nil || clientConfig.Service.Name != m.ServiceName || clientConfig.Service.Namespace != m.Namespace {
+		return false
+	}
+
+	if bytes.Equal(clientConfig.CABundle, caBundle) {
+		return false
+	}
+
+	clientConfig.CABundle = caBundle
+
+	return true
+}
+
+// writeCertificate writes the serving certificate to the certificate directory unless it is already up to date.
+// Files are replaced atomically and the key is written first, the reloader serves the previous certificate until both match
+func (m *CertificateManager) writeCertificate(certData []byte, keyData []byte) error {
+
+	if err := os.MkdirAll(m.CertDir, 0700); err != nil {
+		return err
+	}
+
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{name: m.KeyName, data: keyData},
+		{name: m.CertName, data: certData},
+	} {
+
+		path := filepath.Join(m.CertDir, file.name)
+
+		if existing, err := ioutil.ReadFile(path); err == nil && bytes.Equal(existing, file.data) {
+			continue
+		}
+
+		tmp, err := ioutil.TempFile(m.CertDir, file.name)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := tmp.Write(file.data); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IssueCertificates returns the CA and serving certificate data of the Secret, generating a CA when none is valid, rotating the CA
+// when it expires before a new serving certificate would, and issuing a serving certificate when it is missing, expiring or does not cover
+// the DNS names. The previous CA is kept in the bundle until it expires, so certificates it signed remain trusted while replicas reload.
+// Returns whether the data changed
+func IssueCertificates(data map[string][]byte, dnsNames []string, now time.Time) (map[string][]byte, bool, error) {
+
+	result := map[string][]byte{}
+
+	for key, value := range data {
+		result[key] = value
+	}
+
+	caCert, caKey := parseCA(result[CACertKey], result[CAKeyKey])
+	rotateCA := caCert == nil || caCert.NotAfter.Sub(now) < caRotationThreshold
+
+	if rotateCA {
+
+		var err error
+
+		caCert, caKey, err = generateCA(now)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		result[CAKeyKey], err = encodeKey(caKey)
+
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// The bundle keeps the previous CAs which have not expired
+	bundle := [][]byte{caCert.Raw}
+
+	for _, cert := range parseCertificates(data[CACertKey]) {
+		if now.Before(cert.NotAfter) && !containsCertificate(bundle, cert) {
+			bundle = append(bundle, cert.Raw)
+		}
+	}
+
+	caBundle := []byte{}
+
+	for _, cert := range bundle {
+		caBundle = append(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})...)
+	}
+
+	result[CACertKey] = caBundle
+
+	if rotateCA || !isServingCertificateValid(result[corev1.TLSCertKey], result[corev1.TLSPrivateKeyKey], caCert, dnsNames, now) {
+
+		certData, keyData, err := generateServingCertificate(caCert, caKey, dnsNames, now)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		result[corev1.TLSCertKey] = certData
+		result[corev1.TLSPrivateKeyKey] = keyData
+	}
+
+	return result, !reflect.DeepEqual(result, data), nil
+}
+
+// parseCA returns the current CA, the first certificate of the bundle, when it matches the CA key
+func parseCA(certData []byte, keyData []byte) (*x509.Certificate, *ecdsa.PrivateKey) {
+
+	certs := parseCertificates(certData)
+	block, _ := pem.Decode(keyData)
+
+	if len(certs) == 0 || block == nil {
+		return nil, nil
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	if publicKey, ok := certs[0].PublicKey.(*ecdsa.PublicKey); !ok || !publicKey.Equal(&key.PublicKey) {
+		return nil, nil
+	}
+
+	return certs[0], key
+}
+
+// parseCertificates returns the certificates of a PEM bundle, ignoring invalid blocks
+func parseCertificates(data []byte) []*x509.Certificate {
+
+	certs := []*x509.Certificate{}
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+
+		if block == nil {
+			return certs
+		}
+
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
+
+func containsCertificate(bundle [][]byte, cert *x509.Certificate) bool {
+
+	for _, raw := range bundle {
+		if bytes.Equal(raw, cert.Raw) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isServingCertificateValid returns whether the serving certificate matches its key, was signed by the CA, covers the DNS names and is not expiring
+func isServingCertificateValid(certData []byte, keyData []byte, caCert *x509.Certificate, dnsNames []string, now time.Time) bool {
+
+	certs := parseCertificates(certData)
+	block, _ := pem.Decode(keyData)
+
+	if len(certs) == 0 || block == nil {
+		return false
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+
+	if err != nil {
+		return false
+	}
+
+	cert := certs[0]
+
+	if publicKey, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok || !publicKey.Equal(&key.PublicKey) {
+		return false
+	}
+
+	if cert.CheckSignatureFrom(caCert) != nil || cert.NotAfter.Sub(now) < certRotationThreshold {
+		return false
+	}
+
+	return reflect.DeepEqual(cert.DNSNames, dnsNames)
+}
+
+// generateCA generates a self-signed CA
+func generateCA(now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := generateSerialNumber()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("quay-bridge-operator-webhook-ca@%d", now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(raw)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+// generateServingCertificate generates a serving certificate for the DNS names signed by the CA
+func generateServingCertificate(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, dnsNames []string, now time.Time) ([]byte, []byte, error) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := generateSerialNumber()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyData, err := encodeKey(key)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}), keyData, nil
+}
+
+func generateSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+
+	raw, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: raw}), nil
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIssueCertificates(t *testing.T) {
+
+	dnsNames := []string{"webhook-service", "webhook-service.operator", "webhook-service.operator.svc"}
+	now := time.Now()
+
+	issued, changed, err := IssueCertificates(nil, dnsNames, now)
+
+	if err != nil || !changed {
+		t.Fatalf("Failed to issue certificates: %v %v", changed, err)
+	}
+
+	cases := []struct {
+		data            map[string][]byte
+		dnsNames        []string
+		now             time.Time
+		expectedChanged bool
+		expectedNewCA   bool
+		expectedCAs     int
+	}{
+		// Valid certificates
+		{data: issued, dnsNames: dnsNames, now: now, expectedChanged: false, expectedCAs: 1},
+		// Service renamed
+		{data: issued, dnsNames: []string{"renamed"}, now: now, expectedChanged: true, expectedCAs: 1},
+		// Serving certificate expiring
+		{data: issued, dnsNames: dnsNames, now: now.Add(certValidity - certRotationThreshold + time.Hour), expectedChanged: true, expectedCAs: 1},
+		// CA expiring, the previous CA is kept in the bundle
+		{data: issued, dnsNames: dnsNames, now: now.Add(caValidity - caRotationThreshold + time.Hour), expectedChanged: true, expectedNewCA: true, expectedCAs: 2},
+		// CA expired
+		{data: issued, dnsNames: dnsNames, now: now.Add(caValidity + time.Hour), expectedChanged: true, expectedNewCA: true, expectedCAs: 1},
+		// CA key missing
+		{data: map[string][]byte{CACertKey: issued[CACertKey]}, dnsNames: dnsNames, now: now, expectedChanged: true, expectedNewCA: true, expectedCAs: 2},
+	}
+
+	for i, c := range cases {
+
+		result, changed, err := IssueCertificates(c.data, c.dnsNames, c.now)
+
+		if err != nil {
+			t.Errorf("Test case %d failed to issue certificates: %v", i, err)
+			continue
+		}
+
+		cas := parseCertificates(result[CACertKey])
+		newCA := string(result[CAKeyKey]) != string(issued[CAKeyKey])
+
+		if changed != c.expectedChanged || newCA != c.expectedNewCA || len(cas) != c.expectedCAs {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %d\nActual: %v %v %d", i, c.expectedChanged, c.expectedNewCA, c.expectedCAs, changed, newCA, len(cas))
+			continue
+		}
+
+		// The serving certificate is trusted by the bundle and valid for the DNS names
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(result[CACertKey])
+
+		servingCerts := parseCertificates(result[corev1.TLSCertKey])
+
+		if len(servingCerts) != 1 {
+			t.Errorf("Test case %d did not issue a serving certificate", i)
+			continue
+		}
+
+		if _, err := servingCerts[0].Verify(x509.VerifyOptions{Roots: roots, DNSName: c.dnsNames[0], CurrentTime: c.now}); err != nil {
+			t.Errorf("Test case %d issued an untrusted serving certificate: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+)
+
+// CertificateReloader serves the serving certificate read from disk, reloading it when the certificate or key changes.
+// Files mounted from a Secret are replaced atomically, while the certificate and key may briefly mismatch when written separately,
+// so the last valid certificate is served until both files form a valid pair
+type CertificateReloader struct {
+	certPath string
+	keyPath  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	certData    []byte
+	keyData     []byte
+}
+
+// NewCertificateReloader creates a CertificateReloader, failing when the initial certificate cannot be loaded
+func NewCertificateReloader(certPath string, keyPath string) (*CertificateReloader, error) {
+
+	reloader := &CertificateReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+
+	if _, err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Reload reads the certificate and key from disk, returning whether a different certificate is now served
+func (c *CertificateReloader) Reload() (bool, error) {
+
+	certData, err := ioutil.ReadFile(c.certPath)
+
+	if err != nil {
+		return false, err
+	}
+
+	keyData, err := ioutil.ReadFile(c.keyPath)
+
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	unchanged := bytes.Equal(certData, c.certData) && bytes.Equal(keyData, c.keyData)
+	c.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.X509KeyPair(certData, keyData)
+
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	c.certificate = &certificate
+	c.certData = certData
+	c.keyData = keyData
+	c.mu.Unlock()
+
+	return true, nil
+}
+
+// GetCertificate returns the certificate currently served, suitable for tls.Config
+func (c *CertificateReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.certificate, nil
+}
+
+// Start reloads the certificate at the provided interval until the context is done
+func (c *CertificateReloader) Start(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := c.Reload()
+
+			if err != nil {
+				logging.Log.Error(err, "Unable to reload webhook certificate, serving the previous certificate", "Path", c.certPath)
+			} else if reloaded {
+				logging.Log.Info("Reloaded webhook certificate", "Path", c.certPath)
+			}
+		}
+	}
+}
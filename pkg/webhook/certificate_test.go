@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCertificateReloader(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "webhook-certificate")
+
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+
+	first := generateCertificate(t, "first")
+	second := generateCertificate(t, "second")
+
+	writeCertificate(t, certPath, keyPath, first[0], first[1])
+
+	reloader, err := NewCertificateReloader(certPath, keyPath)
+
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+
+	cases := []struct {
+		certData         []byte
+		keyData          []byte
+		expectedReloaded bool
+		expectedErr      bool
+		expectedSubject  string
+	}{
+		// Unchanged files
+		{certData: first[0], keyData: first[1], expectedReloaded: false, expectedSubject: "first"},
+		// Certificate written before its key
+		{certData: second[0], keyData: first[1], expectedReloaded: false, expectedErr: true, expectedSubject: "first"},
+		// Rotated certificate
+		{certData: second[0], keyData: second[1], expectedReloaded: true, expectedSubject: "second"},
+	}
+
+	for i, c := range cases {
+
+		writeCertificate(t, certPath, keyPath, c.certData, c.keyData)
+
+		reloaded, err := reloader.Reload()
+
+		certificate, _ := reloader.GetCertificate(nil)
+		leaf, _ := x509.ParseCertificate(certificate.Certificate[0])
+
+		if reloaded != c.expectedReloaded || (err != nil) != c.expectedErr || leaf.Subject.CommonName != c.expectedSubject {
+			t.Errorf("Test case %d did not match\nExpected: %v %v %s\nActual: %v %v %s", i, c.expectedReloaded, c.expectedErr, c.expectedSubject, reloaded, err, leaf.Subject.CommonName)
+		}
+	}
+}
+
+func generateCertificate(t *testing.T, commonName string) [2][]byte {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return [2][]byte{
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeCertificate(t *testing.T, certPath string, keyPath string, certData []byte, keyData []byte) {
+
+	if err := ioutil.WriteFile(certPath, certData, 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+
+	if err := ioutil.WriteFile(keyPath, keyData, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ProjectAnnotator annotates the namespaces of projects created through project requests with the Quay organization they are
+// synchronized to and marks their provisioning as pending until the namespace controller has synchronized them
+type ProjectAnnotator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+	Log     logr.Logger
+
+	// Enabled annotates projects. Namespaces are admitted unchanged when disabled
+	Enabled bool
+
+	// Namespaces restricts annotation to the provided namespaces. Every namespace is annotated when empty
+	Namespaces []string
+}
+
+// +kubebuilder:webhook:path=/mutate-namespace,mutating=true,failurePolicy=ignore,verbs=create,groups="",resources=namespaces,versions=v1,name=namespace.quay.redhat.com,sideEffects=None,admissionReviewVersions={v1}
+
+func (p *ProjectAnnotator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if !p.Enabled || req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	namespace := &corev1.Namespace{}
+
+	if err := p.decoder.Decode(req, namespace); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// Only namespaces created from the project request template record their requester
+	if _, ok := namespace.Annotations[constants.OpenShiftRequesterAnnotation]; !ok || !cachescope.InNamespaces(p.Namespaces, namespace.Name) {
+		return admission.Allowed("")
+	}
+
+	syncScope, err := syncpolicy.LoadScope(ctx, p.Client)
+
+	if err != nil {
+		p.Log.Error(err, "Unable to retrieve QuayIntegrations to annotate project", "Namespace", namespace.Name)
+		return admission.Allowed("")
+	}
+
+	quayIntegration := syncScope.Owner(namespace)
+
+	if quayIntegration == nil {
+		return admission.Allowed("")
+	}
+
+	patch := GetProjectAnnotationPatch(namespace, quayIntegration.GenerateQuayOrganizationNameFromNamespace(namespace.Name))
+
+	patchBytes, err := json.Marshal(patch)
+
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+		Allowed: true,
+		Patch:   patchBytes,
+		PatchType: func() *admissionv1.PatchType {
+			pt := admissionv1.PatchTypeJSONPatch
+			return &pt
+		}(),
+	}}
+}
+
+// GetProjectAnnotationPatch returns the patch recording the Quay organization of a namespace and marking its provisioning as pending
+func GetProjectAnnotationPatch(namespace *corev1.Namespace, quayOrganizationName string) []jsonpatch.JsonPatchOperation {
+
+	patch := []jsonpatch.JsonPatchOperation{
+		{
+			Operation: "add",
+			Path:      "/metadata/annotations/" + escapeJSONPointer(constants.QuayOrganizationAnnotation),
+			Value:     quayOrganizationName,
+		},
+		{
+			Operation: "add",
+			Path:      "/metadata/annotations/" + escapeJSONPointer(constants.NamespaceProvisioningAnnotation),
+			Value:     constants.NamespaceProvisioningPending,
+		},
+	}
+
+	// Annotations can only be added once the map exists
+	if namespace.Annotations == nil {
+		patch = append([]jsonpatch.JsonPatchOperation{{
+			Operation: "add",
+			Path:      "/metadata/annotations",
+			Value:     map[string]string{},
+		}}, patch...)
+	}
+
+	return patch
+}
+
+// InjectDecoder injects the decoder.
+func (p *ProjectAnnotator) InjectDecoder(d *admission.Decoder) error {
+	p.decoder = d
+	return nil
+}
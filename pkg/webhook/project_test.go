@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProjectAnnotationPatch(t *testing.T) {
+
+	annotationPatch := []jsonpatch.JsonPatchOperation{
+		{Operation: "add", Path: "/metadata/annotations/quay.openshift.io~1organization", Value: "openshift_myproject"},
+		{Operation: "add", Path: "/metadata/annotations/quay.redhat.com~1provisioning", Value: constants.NamespaceProvisioningPending},
+	}
+
+	cases := []struct {
+		annotations map[string]string
+		expected    []jsonpatch.JsonPatchOperation
+	}{
+		{
+			annotations: map[string]string{constants.OpenShiftRequesterAnnotation: "developer"},
+			expected:    annotationPatch,
+		},
+		{
+			annotations: nil,
+			expected:    append([]jsonpatch.JsonPatchOperation{{Operation: "add", Path: "/metadata/annotations", Value: map[string]string{}}}, annotationPatch...),
+		},
+	}
+
+	for i, c := range cases {
+
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myproject", Annotations: c.annotations}}
+
+		patch := GetProjectAnnotationPatch(namespace, "openshift_myproject")
+
+		if !reflect.DeepEqual(c.expected, patch) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, patch)
+		}
+	}
+}
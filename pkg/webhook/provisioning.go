@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/reconcilerbase"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// unprovisionedBuildDelay is how long the admission of a Build is delayed waiting for its namespace to be provisioned.
+	// Together with the repository creation timeout, it must fit within the BuildAdmissionTimeout
+	unprovisionedBuildDelay = 5 * time.Second
+	// unprovisionedBuildPollInterval is how often the provisioning of the namespace is checked while a Build is delayed
+	unprovisionedBuildPollInterval = 500 * time.Millisecond
+	// unprovisionedBuildRetryAfter is the delay suggested to clients before submitting a denied Build again
+	unprovisionedBuildRetryAfter = 10
+)
+
+// getProvisioningResponse denies Builds pushing to Quay from namespaces which have not been provisioned yet according to the
+// UnprovisionedBuildPolicy. Returns nil when the Build may be admitted
+func (q *QuayIntegrationMutator) getProvisioningResponse(ctx context.Context, build *buildv1.Build, quayIntegration *quayv1.QuayIntegration) *admissionv1.AdmissionResponse {
+
+	policy := quayIntegration.GetUnprovisionedBuildPolicy()
+
+	if policy == quayv1.AllowUnprovisionedBuildPolicy || !isPushedToQuay(build) {
+		return nil
+	}
+
+	provisioned := q.isNamespaceProvisioned(ctx, build.Namespace, quayIntegration)
+
+	if !provisioned && policy == quayv1.DelayUnprovisionedBuildPolicy {
+		_ = wait.PollImmediate(unprovisionedBuildPollInterval, unprovisionedBuildDelay, func() (bool, error) {
+			provisioned = q.isNamespaceProvisioned(ctx, build.Namespace, quayIntegration)
+			return provisioned || ctx.Err() != nil, nil
+		})
+	}
+
+	if provisioned {
+		return nil
+	}
+
+	logging.Log.Info("Denying Build in namespace not provisioned yet", "Namespace", build.Namespace, "Build", build.Name)
+
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Code:    http.StatusTooManyRequests,
+			Reason:  metav1.StatusReasonTooManyRequests,
+			Message: fmt.Sprintf("The Quay organization and push secret of namespace %s are still being provisioned. Retry the Build in a few seconds", build.Namespace),
+			Details: &metav1.StatusDetails{RetryAfterSeconds: unprovisionedBuildRetryAfter},
+		},
+	}
+}
+
+// isNamespaceProvisioned returns whether the namespace controller has provisioned the push secrets of the builder service account
+func (q *QuayIntegrationMutator) isNamespaceProvisioned(ctx context.Context, name string, quayIntegration *quayv1.QuayIntegration) bool {
+
+	namespace := q.getNamespace(ctx, name)
+
+	if namespace == nil {
+		return false
+	}
+
+	return IsNamespaceProvisioned(namespace, quayIntegration, func(secretName string) *corev1.Secret {
+
+		secret := &corev1.Secret{}
+
+		if err := q.Client.Get(ctx, types.NamespacedName{Namespace: name, Name: secretName}, secret); err != nil {
+			return nil
+		}
+
+		return secret
+	})
+}
+
+// IsNamespaceProvisioned returns whether a namespace has been synchronized and the Secrets of its builder service account grant
+// access to the registry in every configured format
+func IsNamespaceProvisioned(namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration, getSecret func(name string) *corev1.Secret) bool {
+
+	if !reconcilerbase.HasFinalizer(namespace, constants.NamespaceFinalizer) || utils.IsProvisioningPending(namespace) {
+		return false
+	}
+
+	return utils.HasRobotAccountSecrets(quayIntegration, namespace.Name, string(qotypes.BuilderOpenShiftServiceAccount), getSecret)
+}
+
+// isPushedToQuay returns whether the output of a Build is redirected to Quay by the webhook
+func isPushedToQuay(build *buildv1.Build) bool {
+
+	if build.Spec.Strategy.DockerStrategy == nil && build.Spec.Strategy.SourceStrategy == nil {
+		return false
+	}
+
+	if _, ok := build.Annotations[constants.BuildMutatedAnnotation]; ok {
+		return false
+	}
+
+	return build.Spec.Output.To != nil && build.Spec.Output.To.Kind == "ImageStreamTag"
+}
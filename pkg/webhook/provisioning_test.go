@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	"github.com/quay/quay-bridge-operator/pkg/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsNamespaceProvisioned(t *testing.T) {
+
+	pushSecret, _ := credentials.GenerateDockerJsonSecret("builder-quay-openshift", "quay.example.com", "openshift_app+builder", "token", "")
+	otherRegistrySecret, _ := credentials.GenerateDockerJsonSecret("builder-quay-openshift", "quay-old.example.com", "openshift_app+builder", "token", "")
+	dockerCfgSecret, _ := credentials.GenerateDockerCfgSecret("builder-quay-openshift-dockercfg", "quay.example.com", "openshift_app+builder", "token", "")
+	basicAuthSecret := credentials.GenerateBasicAuthSecret("builder-quay-openshift-basic-auth", "quay.example.com", "openshift_app+builder", "token")
+
+	synchronized := metav1.ObjectMeta{Name: "app", Finalizers: []string{constants.NamespaceFinalizer}}
+	pending := metav1.ObjectMeta{Name: "app", Finalizers: []string{constants.NamespaceFinalizer}, Annotations: map[string]string{constants.NamespaceProvisioningAnnotation: constants.NamespaceProvisioningPending}}
+
+	cases := []struct {
+		namespace     metav1.ObjectMeta
+		secretFormats []quayv1.SecretFormat
+		gitOpsMode    bool
+		secrets       []*corev1.Secret
+		expected      bool
+	}{
+		{namespace: synchronized, secrets: []*corev1.Secret{pushSecret}, expected: true},
+		{namespace: synchronized, secrets: nil, expected: false},
+		{namespace: synchronized, secrets: []*corev1.Secret{otherRegistrySecret}, expected: false},
+		{namespace: metav1.ObjectMeta{Name: "app"}, secrets: []*corev1.Secret{pushSecret}, expected: false},
+		{namespace: pending, secrets: []*corev1.Secret{pushSecret}, expected: false},
+		{namespace: synchronized, secretFormats: []quayv1.SecretFormat{quayv1.DockerCfgSecretFormat}, secrets: []*corev1.Secret{dockerCfgSecret}, expected: true},
+		{namespace: synchronized, secretFormats: []quayv1.SecretFormat{quayv1.DockerCfgSecretFormat}, secrets: []*corev1.Secret{pushSecret}, expected: false},
+		{namespace: synchronized, secretFormats: []quayv1.SecretFormat{quayv1.DockerCfgSecretFormat, quayv1.BasicAuthSecretFormat}, secrets: []*corev1.Secret{dockerCfgSecret}, expected: false},
+		{namespace: synchronized, secretFormats: []quayv1.SecretFormat{quayv1.DockerCfgSecretFormat, quayv1.BasicAuthSecretFormat}, secrets: []*corev1.Secret{dockerCfgSecret, basicAuthSecret}, expected: true},
+		{namespace: synchronized, gitOpsMode: true, secrets: nil, expected: true},
+	}
+
+	for i, c := range cases {
+
+		quayIntegration := &quayv1.QuayIntegration{
+			Spec: quayv1.QuayIntegrationSpec{
+				ClusterID:     "openshift",
+				QuayHostname:  "https://quay.example.com",
+				SecretFormats: c.secretFormats,
+				GitOpsMode:    c.gitOpsMode,
+			},
+		}
+
+		secrets := map[string]*corev1.Secret{}
+
+		for _, secret := range c.secrets {
+			secrets[secret.Name] = secret
+		}
+
+		getSecret := func(name string) *corev1.Secret {
+			return secrets[name]
+		}
+
+		if result := IsNamespaceProvisioned(&corev1.Namespace{ObjectMeta: c.namespace}, quayIntegration, getSecret); result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, result)
+		}
+	}
+}
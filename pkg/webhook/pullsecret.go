@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
+	"github.com/quay/quay-bridge-operator/pkg/imagescan"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PullSecretInjector adds the pull secret of the default service account of a synchronized namespace to the Pods of the namespace
+// pulling images from Quay, covering Pods running as service accounts the pull secret is not linked to
+type PullSecretInjector struct {
+	Client  client.Client
+	decoder *admission.Decoder
+	Log     logr.Logger
+
+	// Enabled injects pull secrets. Pods are admitted unchanged when disabled
+	Enabled bool
+
+	// Namespaces restricts injection to the provided namespaces. Pods of every namespace are considered when empty
+	Namespaces []string
+}
+
+// The webhook configuration is maintained in config/webhook/pull-secret-injection rather than generated, as it is only installed when
+// pull secret injection is enabled and selects the synchronized namespaces
+
+func (p *PullSecretInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if !p.Enabled || req.Operation != admissionv1.Create || !cachescope.InNamespaces(p.Namespaces, req.Namespace) {
+		return admission.Allowed("")
+	}
+
+	pod := &corev1.Pod{}
+
+	if err := p.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	namespace, quayIntegration, err := syncpolicy.GetOwner(ctx, p.Client, req.Namespace)
+
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			p.Log.Error(err, "Unable to retrieve the QuayIntegration of namespace to inject pull secret", "Namespace", req.Namespace)
+		}
+
+		return admission.Allowed("")
+	}
+
+	// Secrets are only known to exist when written by the operator
+	if quayIntegration == nil || !quayIntegration.ManagesClusterSecrets() {
+		return admission.Allowed("")
+	}
+
+	registryHostname, err := quayIntegration.GetRegistryHostname()
+
+	if err != nil {
+		return admission.Allowed("")
+	}
+
+	secretName, found, err := utils.GeneratePullSecretName(quayIntegration, namespace.Name)
+
+	if err != nil || !found {
+		return admission.Allowed("")
+	}
+
+	patch := GetPullSecretInjectionPatch(pod, registryHostname, secretName)
+
+	if len(patch) == 0 {
+		return admission.Allowed("")
+	}
+
+	patchBytes, err := json.Marshal(patch)
+
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+		Allowed: true,
+		Patch:   patchBytes,
+		PatchType: func() *admissionv1.PatchType {
+			pt := admissionv1.PatchTypeJSONPatch
+			return &pt
+		}(),
+	}}
+}
+
+// GetPullSecretInjectionPatch returns the patch adding a pull secret to a Pod pulling images from the registry, or nil when the Pod
+// does not pull images from the registry or already refers to the pull secret
+func GetPullSecretInjectionPatch(pod *corev1.Pod, registryHostname string, secretName string) []jsonpatch.JsonPatchOperation {
+
+	pullsFromRegistry := false
+
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			pullsFromRegistry = pullsFromRegistry || imagescan.RegistryHostname(container.Image) == registryHostname
+		}
+	}
+
+	if !pullsFromRegistry {
+		return nil
+	}
+
+	for _, pullSecret := range pod.Spec.ImagePullSecrets {
+		if pullSecret.Name == secretName {
+			return nil
+		}
+	}
+
+	// Pull secrets can only be appended once the list exists
+	if pod.Spec.ImagePullSecrets == nil {
+		return []jsonpatch.JsonPatchOperation{{
+			Operation: "add",
+			Path:      "/spec/imagePullSecrets",
+			Value:     []corev1.LocalObjectReference{{Name: secretName}},
+		}}
+	}
+
+	return []jsonpatch.JsonPatchOperation{{
+		Operation: "add",
+		Path:      "/spec/imagePullSecrets/-",
+		Value:     corev1.LocalObjectReference{Name: secretName},
+	}}
+}
+
+// InjectDecoder injects the decoder.
+func (p *PullSecretInjector) InjectDecoder(d *admission.Decoder) error {
+	p.decoder = d
+	return nil
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPullSecretInjectionPatch(t *testing.T) {
+
+	cases := []struct {
+		initContainers   []corev1.Container
+		containers       []corev1.Container
+		imagePullSecrets []corev1.LocalObjectReference
+		expected         []jsonpatch.JsonPatchOperation
+	}{
+		{
+			containers: []corev1.Container{{Image: "quay.example.com/openshift_myproject/app:latest"}},
+			expected:   []jsonpatch.JsonPatchOperation{{Operation: "add", Path: "/spec/imagePullSecrets", Value: []corev1.LocalObjectReference{{Name: "myproject-default"}}}},
+		},
+		{
+			initContainers:   []corev1.Container{{Image: "quay.example.com/openshift_myproject/init:latest"}},
+			containers:       []corev1.Container{{Image: "registry.access.redhat.com/ubi8/ubi"}},
+			imagePullSecrets: []corev1.LocalObjectReference{{Name: "other"}},
+			expected:         []jsonpatch.JsonPatchOperation{{Operation: "add", Path: "/spec/imagePullSecrets/-", Value: corev1.LocalObjectReference{Name: "myproject-default"}}},
+		},
+		{
+			containers:       []corev1.Container{{Image: "quay.example.com/openshift_myproject/app:latest"}},
+			imagePullSecrets: []corev1.LocalObjectReference{{Name: "myproject-default"}},
+		},
+		{
+			containers: []corev1.Container{{Image: "quay.io/openshift/app:latest"}, {Image: "nginx"}},
+		},
+	}
+
+	for i, c := range cases {
+
+		pod := &corev1.Pod{Spec: corev1.PodSpec{InitContainers: c.initContainers, Containers: c.containers, ImagePullSecrets: c.imagePullSecrets}}
+
+		patch := GetPullSecretInjectionPatch(pod, "quay.example.com", "myproject-default")
+
+		if !reflect.DeepEqual(c.expected, patch) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, patch)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	qclient "github.com/quay/quay-bridge-operator/pkg/client/quay"
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/state"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultRepositoryCreationTimeout is how long the admission of a Build waits for its repository to be created by default
+	DefaultRepositoryCreationTimeout = 3 * time.Second
+
+	// repositoryCreationDeadline bounds the creation of a repository continuing in the background
+	repositoryCreationDeadline = 30 * time.Second
+	// repositoryExistsTTL is how long a repository is known to exist before it is checked again
+	repositoryExistsTTL = 10 * time.Minute
+)
+
+// RepositoryCreationMode determines when the webhook creates the Quay repositories of admitted Builds
+type RepositoryCreationMode string
+
+const (
+	// NeverRepositoryCreation leaves the creation of repositories to Quay when Builds push to them
+	NeverRepositoryCreation RepositoryCreationMode = "Never"
+	// AutoRepositoryCreation creates repositories when Quay is detected to create public repositories on push
+	AutoRepositoryCreation RepositoryCreationMode = "Auto"
+	// AlwaysRepositoryCreation creates the repositories of every Build
+	AlwaysRepositoryCreation RepositoryCreationMode = "Always"
+)
+
+// ParseRepositoryCreationMode validates a RepositoryCreationMode
+func ParseRepositoryCreationMode(mode string) (RepositoryCreationMode, error) {
+
+	switch RepositoryCreationMode(mode) {
+	case NeverRepositoryCreation, AutoRepositoryCreation, AlwaysRepositoryCreation:
+		return RepositoryCreationMode(mode), nil
+	}
+
+	return "", fmt.Errorf("invalid repository creation mode '%s', expected one of %s, %s or %s", mode, NeverRepositoryCreation, AutoRepositoryCreation, AlwaysRepositoryCreation)
+}
+
+// ValidateBuildAdmissionTimeout ensures the admission of a Build delayed by the provisioning of its namespace and waiting for the
+// creation of its repository completes within the BuildAdmissionTimeout
+func ValidateBuildAdmissionTimeout(repositoryCreationTimeout time.Duration) error {
+
+	if repositoryCreationTimeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", repositoryCreationTimeout)
+	}
+
+	if limit := BuildAdmissionTimeout - buildAdmissionHeadroom - unprovisionedBuildDelay; repositoryCreationTimeout > limit {
+		return fmt.Errorf("timeout %s exceeds %s, the admission of Builds would exceed the webhook timeout of %s", repositoryCreationTimeout, limit, BuildAdmissionTimeout)
+	}
+
+	return nil
+}
+
+// RepositoryCreator creates the Quay repositories Builds are pushed to when they are admitted, so that the first push of a
+// Build does not depend on Quay creating repositories on push
+type RepositoryCreator struct {
+	// Mode determines whether the repositories of Builds are created
+	Mode RepositoryCreationMode
+	// Capabilities are the features detected on Quay, determining whether repositories are created in Auto mode
+	Capabilities *capabilities.Store
+	// Timeout is how long the admission of a Build waits for its repository to be created. The creation continues in the background afterwards
+	Timeout time.Duration
+	// OrgActuator creates the repositories. DefaultOrgActuator is used when not set
+	OrgActuator actuator.OrgActuatorFactory
+
+	create   func(quayIntegration *quayv1.QuayIntegration, organization string, repository string) error
+	mutex    sync.Mutex
+	existing map[string]time.Time
+	inflight map[string]chan struct{}
+}
+
+// NewRepositoryCreator returns a RepositoryCreator using the credentials of the QuayIntegration
+func NewRepositoryCreator(reader client.Reader, httpClientPool *qclient.HTTPClientPool, mode RepositoryCreationMode, capabilityStore *capabilities.Store, timeout time.Duration) *RepositoryCreator {
+
+	creator := &RepositoryCreator{
+		Mode:         mode,
+		Capabilities: capabilityStore,
+		Timeout:      timeout,
+		existing:     map[string]time.Time{},
+		inflight:     map[string]chan struct{}{},
+	}
+
+	creator.create = func(quayIntegration *quayv1.QuayIntegration, organization string, repository string) error {
+
+		ctx, cancel := context.WithTimeout(context.Background(), repositoryCreationDeadline)
+		defer cancel()
+
+		quayClient, err := state.NewQuayClient(ctx, reader, quayIntegration, httpClientPool)
+
+		if err != nil {
+			return err
+		}
+
+		orgActuator := creator.OrgActuator
+
+		if orgActuator == nil {
+			orgActuator = actuator.DefaultOrgActuator
+		}
+
+		return createRepository(quayClient, orgActuator(quayClient), organization, repository)
+	}
+
+	return creator
+}
+
+// IsEnabled returns whether the repositories of Builds are created for a QuayIntegration. Quay instances whose configuration
+// has not been detected are assumed to create private repositories on push
+func (c *RepositoryCreator) IsEnabled(quayIntegration *quayv1.QuayIntegration) bool {
+
+	switch c.Mode {
+	case AlwaysRepositoryCreation:
+		return true
+	case AutoRepositoryCreation:
+		return c.Capabilities.Get(quayIntegration.Name).PublicRepositoriesOnPush
+	}
+
+	return false
+}
+
+// Ensure creates a repository unless it is known to exist, waiting up to the timeout or the deadline of the context for the
+// creation to complete. Concurrent Builds pushing to the same repository share a single creation
+func (c *RepositoryCreator) Ensure(ctx context.Context, quayIntegration *quayv1.QuayIntegration, organization string, repository string) {
+
+	key := fmt.Sprintf("%s/%s", organization, repository)
+
+	c.mutex.Lock()
+
+	if checked, ok := c.existing[key]; ok && time.Since(checked) < repositoryExistsTTL {
+		c.mutex.Unlock()
+		return
+	}
+
+	done, ok := c.inflight[key]
+
+	if !ok {
+		done = make(chan struct{})
+		c.inflight[key] = done
+
+		go func(quayIntegration *quayv1.QuayIntegration) {
+
+			err := c.create(quayIntegration, organization, repository)
+
+			if err != nil {
+				logging.Log.Error(err, "Failed to create Quay repository for Build", "Quay Repository", key)
+			}
+
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+
+			if err == nil {
+				c.existing[key] = time.Now()
+			}
+
+			delete(c.inflight, key)
+			close(done)
+		}(quayIntegration.DeepCopy())
+	}
+
+	c.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(c.Timeout):
+		logging.Log.Info("Quay repository creation continuing in the background", "Quay Repository", key)
+	case <-ctx.Done():
+		logging.Log.Info("Quay repository creation continuing in the background", "Quay Repository", key)
+	}
+}
+
+// createRepository creates a repository in Quay unless it already exists
+func createRepository(quayClient *qclient.QuayClient, orgActuator actuator.OrgActuator, organization string, repository string) error {
+
+	_, repositoryResponse, repositoryErr := quayClient.GetRepository(organization, repository)
+
+	if repositoryErr.Error != nil {
+		return repositoryErr.Error
+	}
+
+	if repositoryResponse.StatusCode == 200 {
+		return nil
+	}
+
+	// Quay reports repositories the robot account cannot see as forbidden
+	if repositoryResponse.StatusCode != 403 && repositoryResponse.StatusCode != 404 {
+		return fmt.Errorf("unexpected status code %d retrieving repository", repositoryResponse.StatusCode)
+	}
+
+	logging.Log.Info("Creating Repository for Build", "Organization", organization, "Name", repository)
+
+	_, createRepositoryResponse, createRepositoryErr := orgActuator.CreateRepository(organization, repository)
+
+	if createRepositoryErr.Error != nil {
+		return createRepositoryErr.Error
+	}
+
+	if createRepositoryResponse.StatusCode != 201 {
+		return fmt.Errorf("unexpected status code %d creating repository", createRepositoryResponse.StatusCode)
+	}
+
+	return nil
+}
+
+// getBuildOutputRepository returns the Quay organization or user account and the repository a Build is redirected to, if any
+func getBuildOutputRepository(build *buildv1.Build, buildConfig *buildv1.BuildConfig, outputNamespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) (string, string, bool) {
+
+	if !isPushedToQuay(build) {
+		return "", "", false
+	}
+
+	namespace := build.Namespace
+
+	if build.Spec.Output.To.Namespace != "" {
+		namespace = build.Spec.Output.To.Namespace
+	}
+
+	imageStreamParts := strings.Split(build.Spec.Output.To.Name, ":")
+
+	if len(imageStreamParts) != 2 {
+		return "", "", false
+	}
+
+	repository, _, err := getBuildOutputRepositoryAndTag(build, buildConfig, quayIntegration, imageStreamParts[0], imageStreamParts[1])
+
+	if err != nil {
+		return "", "", false
+	}
+
+	return utils.GetRepositoryNamespace(quayIntegration, namespace, outputNamespace), repository, true
+}
@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/capabilities"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetBuildOutputRepository(t *testing.T) {
+
+	quayIntegration := &quayv1.QuayIntegration{
+		Spec: quayv1.QuayIntegrationSpec{
+			ClusterID:    "openshift",
+			QuayHostname: "https://quay.example.com",
+		},
+	}
+
+	dockerStrategy := buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{}}
+
+	cases := []struct {
+		annotations          map[string]string
+		strategy             buildv1.BuildStrategy
+		output               *corev1.ObjectReference
+		expectedOrganization string
+		expectedRepository   string
+		expectedOk           bool
+	}{
+		{
+			strategy:             dockerStrategy,
+			output:               &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+			expectedOrganization: "openshift_myproject",
+			expectedRepository:   "app",
+			expectedOk:           true,
+		},
+		{
+			strategy:             dockerStrategy,
+			output:               &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "shared", Name: "app:latest"},
+			expectedOrganization: "openshift_shared",
+			expectedRepository:   "app",
+			expectedOk:           true,
+		},
+		{
+			annotations:          map[string]string{constants.QuayRepositoryAnnotation: "frontend"},
+			strategy:             dockerStrategy,
+			output:               &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+			expectedOrganization: "openshift_myproject",
+			expectedRepository:   "frontend",
+			expectedOk:           true,
+		},
+		{
+			annotations: map[string]string{constants.BuildMutatedAnnotation: "app-1"},
+			strategy:    dockerStrategy,
+			output:      &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.example.com/openshift_myproject/app:latest"},
+		},
+		{
+			strategy: dockerStrategy,
+			output:   &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/app:latest"},
+		},
+		{
+			strategy: buildv1.BuildStrategy{CustomStrategy: &buildv1.CustomBuildStrategy{}},
+			output:   &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+		},
+		{
+			strategy: dockerStrategy,
+			output:   &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app"},
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "myproject", Annotations: c.annotations},
+			Spec: buildv1.BuildSpec{CommonSpec: buildv1.CommonSpec{
+				Strategy: c.strategy,
+				Output:   buildv1.BuildOutput{To: c.output},
+			}},
+		}
+
+		organization, repository, ok := getBuildOutputRepository(build, nil, nil, quayIntegration)
+
+		if organization != c.expectedOrganization || repository != c.expectedRepository || ok != c.expectedOk {
+			t.Errorf("Test case %d did not match\nExpected: %s %s %v\nActual: %s %s %v", i, c.expectedOrganization, c.expectedRepository, c.expectedOk, organization, repository, ok)
+		}
+	}
+}
+
+func TestRepositoryCreatorEnsure(t *testing.T) {
+
+	var mutex sync.Mutex
+	created := map[string]int{}
+	release := make(chan struct{})
+
+	creator := &RepositoryCreator{
+		Timeout: time.Second,
+		create: func(quayIntegration *quayv1.QuayIntegration, organization string, repository string) error {
+			<-release
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			created[repository]++
+
+			if repository == "failing" {
+				return fmt.Errorf("organization does not exist")
+			}
+
+			return nil
+		},
+		existing: map[string]time.Time{},
+		inflight: map[string]chan struct{}{},
+	}
+
+	quayIntegration := &quayv1.QuayIntegration{}
+
+	// Concurrent Builds share a single creation
+	var wg sync.WaitGroup
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			creator.Ensure(context.TODO(), quayIntegration, "openshift_myproject", "app")
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Repositories known to exist are not created again while failed creations are retried
+	creator.Ensure(context.TODO(), quayIntegration, "openshift_myproject", "app")
+	creator.Ensure(context.TODO(), quayIntegration, "openshift_myproject", "failing")
+	creator.Ensure(context.TODO(), quayIntegration, "openshift_myproject", "failing")
+
+	// The admission stops waiting once its deadline is reached
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	blocked := &RepositoryCreator{
+		Timeout: time.Minute,
+		create: func(quayIntegration *quayv1.QuayIntegration, organization string, repository string) error {
+			<-unblock
+			return nil
+		},
+		existing: map[string]time.Time{},
+		inflight: map[string]chan struct{}{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	blocked.Ensure(ctx, quayIntegration, "openshift_myproject", "app")
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Repository creation was awaited for %s past the deadline of the admission", elapsed)
+	}
+
+	expected := map[string]int{"app": 1, "failing": 2}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for repository, count := range expected {
+		if created[repository] != count {
+			t.Errorf("Repository %s was created %d times, expected %d", repository, created[repository], count)
+		}
+	}
+}
+
+func TestValidateBuildAdmissionTimeout(t *testing.T) {
+
+	cases := []struct {
+		timeout     time.Duration
+		expectedErr bool
+	}{
+		{timeout: DefaultRepositoryCreationTimeout, expectedErr: false},
+		{timeout: 0, expectedErr: false},
+		{timeout: 5 * time.Second, expectedErr: false},
+		{timeout: 6 * time.Second, expectedErr: true},
+		{timeout: -time.Second, expectedErr: true},
+	}
+
+	for i, c := range cases {
+
+		if err := ValidateBuildAdmissionTimeout(c.timeout); (err != nil) != c.expectedErr {
+			t.Errorf("Test case %d did not match\nExpected error: %v\nActual: %v", i, c.expectedErr, err)
+		}
+	}
+}
+
+func TestRepositoryCreatorIsEnabled(t *testing.T) {
+
+	store := capabilities.NewStore()
+	store.Set("public", capabilities.Capabilities{Detected: true, PublicRepositoriesOnPush: true})
+	store.Set("private", capabilities.Capabilities{Detected: true})
+
+	cases := []struct {
+		mode            RepositoryCreationMode
+		quayIntegration string
+		expected        bool
+	}{
+		{mode: AutoRepositoryCreation, quayIntegration: "public", expected: true},
+		{mode: AutoRepositoryCreation, quayIntegration: "private", expected: false},
+		{mode: AutoRepositoryCreation, quayIntegration: "undetected", expected: false},
+		{mode: AlwaysRepositoryCreation, quayIntegration: "private", expected: true},
+		{mode: NeverRepositoryCreation, quayIntegration: "public", expected: false},
+	}
+
+	for i, c := range cases {
+
+		creator := &RepositoryCreator{Mode: c.mode, Capabilities: store}
+
+		if result := creator.IsEnabled(&quayv1.QuayIntegration{ObjectMeta: metav1.ObjectMeta{Name: c.quayIntegration}}); result != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, result)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ManagedSecretValidator warns users changing or deleting the robot account Secrets managed by the operator. Changes are always
+// admitted as the operator recreates deleted Secrets and reverts edits
+type ManagedSecretValidator struct {
+	decoder *admission.Decoder
+
+	// Enabled returns warnings. Changes are admitted without warnings when disabled
+	Enabled bool
+
+	// OperatorNamespace is the namespace of the operator. Changes made by its service accounts are not warned about
+	OperatorNamespace string
+
+	// ImpersonationServiceAccount is the service account impersonated by the operator to write Secrets, if any
+	ImpersonationServiceAccount string
+}
+
+// The webhook configuration is maintained in config/webhook/secret-protection rather than generated, as it is only installed when the
+// secret protection webhook is enabled and selects the managed Secrets
+
+func (v *ManagedSecretValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if !v.Enabled || (req.Operation != admissionv1.Update && req.Operation != admissionv1.Delete) {
+		return admission.Allowed("")
+	}
+
+	secret := &corev1.Secret{}
+
+	if err := v.decoder.DecodeRaw(req.OldObject, secret); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	response := admission.Allowed("")
+
+	if warning := GetManagedSecretWarning(secret, req.Operation, req.UserInfo.Username, v.OperatorNamespace, v.ImpersonationServiceAccount); warning != "" {
+		response.Warnings = []string{warning}
+	}
+
+	return response
+}
+
+// GetManagedSecretWarning returns the warning returned to a user changing or deleting a Secret, if it is managed by the operator
+func GetManagedSecretWarning(secret *corev1.Secret, operation admissionv1.Operation, username string, operatorNamespace string, impersonationServiceAccount string) string {
+
+	if secret.Labels[constants.ManagedSecretLabel] != "true" {
+		return ""
+	}
+
+	if operatorNamespace != "" && strings.HasPrefix(username, fmt.Sprintf("system:serviceaccount:%s:", operatorNamespace)) {
+		return ""
+	}
+
+	if impersonationServiceAccount != "" && username == fmt.Sprintf("system:serviceaccount:%s:%s", secret.Namespace, impersonationServiceAccount) {
+		return ""
+	}
+
+	if operation == admissionv1.Delete {
+		return fmt.Sprintf("Secret %s is managed by the quay-bridge-operator and will be recreated", secret.Name)
+	}
+
+	return fmt.Sprintf("Secret %s is managed by the quay-bridge-operator and edits will be reverted", secret.Name)
+}
+
+// InjectDecoder injects the decoder.
+func (v *ManagedSecretValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetManagedSecretWarning(t *testing.T) {
+
+	managed := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "builder-quay", Namespace: "app", Labels: map[string]string{constants.ManagedSecretLabel: "true"}}}
+	unmanaged := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "builder-quay", Namespace: "app"}}
+
+	cases := []struct {
+		secret          *corev1.Secret
+		operation       admissionv1.Operation
+		username        string
+		impersonation   string
+		expectedWarning bool
+	}{
+		{secret: managed, operation: admissionv1.Update, username: "developer", expectedWarning: true},
+		{secret: managed, operation: admissionv1.Delete, username: "developer", expectedWarning: true},
+		{secret: unmanaged, operation: admissionv1.Delete, username: "developer", expectedWarning: false},
+		{secret: managed, operation: admissionv1.Update, username: "system:serviceaccount:quay-bridge-operator:quay-bridge-operator-controller-manager", expectedWarning: false},
+		{secret: managed, operation: admissionv1.Update, username: "system:serviceaccount:app:quay-bridge-operator-writer", impersonation: "quay-bridge-operator-writer", expectedWarning: false},
+		{secret: managed, operation: admissionv1.Update, username: "system:serviceaccount:other:quay-bridge-operator-writer", impersonation: "quay-bridge-operator-writer", expectedWarning: true},
+	}
+
+	for i, c := range cases {
+
+		warning := GetManagedSecretWarning(c.secret, c.operation, c.username, "quay-bridge-operator", c.impersonation)
+
+		if (warning != "") != c.expectedWarning {
+			t.Errorf("Test case %d did not match\nExpected Warning: %v\nActual: %s", i, c.expectedWarning, warning)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/quay/quay-bridge-operator/pkg/logging"
+)
+
+const (
+	// DefaultShutdownDelay is the time requests are still accepted once shutdown begins, letting endpoints of the webhook service be removed
+	DefaultShutdownDelay = 5 * time.Second
+	// DefaultShutdownTimeout is the time given to in-flight requests to complete once the listener is closed
+	DefaultShutdownTimeout = 20 * time.Second
+	// DefaultCertificateReloadInterval is the interval at which the serving certificate is read from disk
+	DefaultCertificateReloadInterval = 10 * time.Second
+)
+
+// Server serves admission webhooks over TLS. Unlike the webhook server of the manager, it reloads rotated certificates
+// without dropping connections and drains in-flight admission requests on shutdown, so Builds are not rejected while the operator is upgraded
+type Server struct {
+	Host     string
+	Port     int
+	CertDir  string
+	CertName string
+	KeyName  string
+
+	ShutdownDelay             time.Duration
+	ShutdownTimeout           time.Duration
+	CertificateReloadInterval time.Duration
+
+	mux          *http.ServeMux
+	shuttingDown int32
+	inFlight     int64
+}
+
+// NewServer creates a Server with the default shutdown and certificate reload settings
+func NewServer(port int, certDir string, certName string, keyName string) *Server {
+	return &Server{
+		Port:                      port,
+		CertDir:                   certDir,
+		CertName:                  certName,
+		KeyName:                   keyName,
+		ShutdownDelay:             DefaultShutdownDelay,
+		ShutdownTimeout:           DefaultShutdownTimeout,
+		CertificateReloadInterval: DefaultCertificateReloadInterval,
+		mux:                       http.NewServeMux(),
+	}
+}
+
+// Register serves a webhook on the provided path
+func (s *Server) Register(path string, hook http.Handler) {
+	s.mux.Handle(path, hook)
+}
+
+// NeedLeaderElection allows every replica to serve admission requests
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ReadyzCheck fails once shutdown begins, removing the replica from the endpoints of the webhook service
+func (s *Server) ReadyzCheck(_ *http.Request) error {
+
+	if atomic.LoadInt32(&s.shuttingDown) == 1 {
+		return errors.New("webhook server is shutting down")
+	}
+
+	return nil
+}
+
+// Start serves webhooks until the context is done, then drains in-flight requests
+func (s *Server) Start(ctx context.Context) error {
+
+	reloader, err := NewCertificateReloader(filepath.Join(s.CertDir, s.CertName), filepath.Join(s.CertDir, s.KeyName))
+
+	if err != nil {
+		return err
+	}
+
+	go reloader.Start(ctx, s.CertificateReloadInterval)
+
+	listener, err := tls.Listen("tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)), &tls.Config{
+		NextProtos:     []string{"h2"},
+		GetCertificate: reloader.GetCertificate,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler: s.track(s.mux),
+	}
+
+	shutdownComplete := make(chan error, 1)
+
+	go func() {
+		<-ctx.Done()
+
+		atomic.StoreInt32(&s.shuttingDown, 1)
+
+		// Requests keep being routed to this replica until its endpoint is removed
+		logging.Log.Info("Shutting down webhook server", "Delay", s.ShutdownDelay, "In Flight", atomic.LoadInt64(&s.inFlight))
+		time.Sleep(s.ShutdownDelay)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+		defer cancel()
+
+		shutdownComplete <- srv.Shutdown(shutdownCtx)
+	}()
+
+	logging.Log.Info("Serving webhook server", "Host", s.Host, "Port", s.Port)
+
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	if err := <-shutdownComplete; err != nil {
+		logging.Log.Error(err, "Webhook server did not drain in-flight requests", "In Flight", atomic.LoadInt64(&s.inFlight))
+		return err
+	}
+
+	logging.Log.Info("Webhook server stopped")
+
+	return nil
+}
+
+// track counts the requests being served
+func (s *Server) track(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&s.inFlight, 1)
+		defer atomic.AddInt64(&s.inFlight, -1)
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+var (
+	// tagRegex matches valid image tags
+	tagRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+	// expiresAfterRegex matches the durations accepted by the Quay expiration label
+	expiresAfterRegex = regexp.MustCompile(`^[1-9][0-9]*[smhdw]$`)
+
+	// gitShaRegex matches full git commit hashes
+	gitShaRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+const (
+	// tagDateFormat is the layout of the Date field available to tag templates
+	tagDateFormat = "20060102"
+
+	// gitShortShaLength is the length of the abbreviated commit hash available to tag templates
+	gitShortShaLength = 7
+)
+
+// TagTemplateData is the data available to templates used to tag the output of Builds
+type TagTemplateData struct {
+	Repository  string
+	Tag         string
+	BuildNumber string
+	GitSha      string
+	GitShortSha string
+	Date        string
+}
+
+// newTagTemplateData collects the data available to tag templates from a Build
+func newTagTemplateData(build *buildv1.Build, repository string, tag string, now time.Time) *TagTemplateData {
+
+	data := &TagTemplateData{
+		Repository:  repository,
+		Tag:         tag,
+		BuildNumber: build.Annotations[buildv1.BuildNumberAnnotation],
+		Date:        now.UTC().Format(tagDateFormat),
+	}
+
+	// Source revision information is only available when the Build was triggered with a specific commit
+	if build.Spec.Revision != nil && build.Spec.Revision.Git != nil {
+		data.GitSha = build.Spec.Revision.Git.Commit
+	} else if build.Spec.Source.Git != nil && gitShaRegex.MatchString(build.Spec.Source.Git.Ref) {
+		data.GitSha = build.Spec.Source.Git.Ref
+	}
+
+	data.GitShortSha = data.GitSha
+
+	if len(data.GitShortSha) > gitShortShaLength {
+		data.GitShortSha = data.GitShortSha[:gitShortShaLength]
+	}
+
+	return data
+}
+
+// renderTag renders an image tag from a template and validates the result. An empty tag is returned as is
+func renderTag(tagTemplate string, data *TagTemplateData) (string, error) {
+
+	tmpl, err := template.New("tag").Option("missingkey=error").Parse(tagTemplate)
+
+	if err != nil {
+		return "", err
+	}
+
+	var tag strings.Builder
+
+	if err := tmpl.Execute(&tag, data); err != nil {
+		return "", err
+	}
+
+	renderedTag := strings.TrimSpace(tag.String())
+
+	if renderedTag == "" {
+		return "", nil
+	}
+
+	if !tagRegex.MatchString(renderedTag) {
+		return "", fmt.Errorf("invalid tag '%s'", renderedTag)
+	}
+
+	return renderedTag, nil
+}
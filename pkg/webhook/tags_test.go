@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRenderTag(t *testing.T) {
+
+	now := time.Date(2021, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-3",
+			Namespace: "test",
+			Annotations: map[string]string{
+				buildv1.BuildNumberAnnotation: "3",
+			},
+		},
+		Spec: buildv1.BuildSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Revision: &buildv1.SourceRevision{
+					Type: buildv1.BuildSourceGit,
+					Git: &buildv1.GitSourceRevision{
+						Commit: "0123456789abcdef0123456789abcdef01234567",
+					},
+				},
+			},
+		},
+	}
+
+	buildWithoutRevision := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-4",
+			Namespace: "test",
+			Annotations: map[string]string{
+				buildv1.BuildNumberAnnotation: "4",
+			},
+		},
+	}
+
+	cases := []struct {
+		build       *buildv1.Build
+		tagTemplate string
+		expected    string
+		expectError bool
+	}{
+		{
+			build:       build,
+			tagTemplate: "{{ .Tag }}",
+			expected:    "latest",
+		},
+		{
+			build:       build,
+			tagTemplate: "{{ .Date }}-{{ .BuildNumber }}-{{ .GitShortSha }}",
+			expected:    "20210304-3-0123456",
+		},
+		{
+			build:       build,
+			tagTemplate: "{{ .GitSha }}",
+			expected:    "0123456789abcdef0123456789abcdef01234567",
+		},
+		{
+			build:       buildWithoutRevision,
+			tagTemplate: "{{ or .GitShortSha .BuildNumber }}",
+			expected:    "4",
+		},
+		{
+			build:       buildWithoutRevision,
+			tagTemplate: "{{ .GitShortSha }}",
+			expected:    "",
+		},
+		{
+			build:       build,
+			tagTemplate: "{{ .Tag }}:{{ .BuildNumber }}",
+			expectError: true,
+		},
+		{
+			build:       build,
+			tagTemplate: "{{ .Tag",
+			expectError: true,
+		},
+	}
+
+	for i, c := range cases {
+
+		actual, err := renderTag(c.tagTemplate, newTagTemplateData(c.build, "app", "latest", now))
+
+		if c.expectError {
+			if err == nil {
+				t.Errorf("Test case %d did not return an error", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Test case %d returned an unexpected error: %v", i, err)
+		}
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %s\nActual: %s", i, c.expected, actual)
+		}
+	}
+}
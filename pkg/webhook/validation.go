@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// QuayIntegrationValidator rejects changes to the fields of a QuayIntegration deriving the names of Quay organizations. Changing them
+// would leave every organization created so far behind while new, empty organizations are created for the same namespaces
+type QuayIntegrationValidator struct {
+	decoder *admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/validate-quayintegration,mutating=false,failurePolicy=fail,verbs=update,groups="quay.redhat.com",resources=quayintegrations,versions=v1,name=vquayintegration.quay.redhat.com,sideEffects=None,admissionReviewVersions={v1}
+
+func (v *QuayIntegrationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	quayIntegration := &quayv1.QuayIntegration{}
+	oldQuayIntegration := &quayv1.QuayIntegration{}
+
+	if err := v.decoder.Decode(req, quayIntegration); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := v.decoder.DecodeRaw(req.OldObject, oldQuayIntegration); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	changed := GetChangedImmutableFields(oldQuayIntegration, quayIntegration)
+
+	if len(changed) == 0 {
+		return admission.Allowed("")
+	}
+
+	if quayIntegration.Annotations[constants.AllowOrganizationRenameAnnotation] == "true" {
+		response := admission.Allowed("")
+		response.Warnings = []string{fmt.Sprintf("Changing %s leaves the existing Quay organizations behind", strings.Join(changed, ", "))}
+		return response
+	}
+
+	return admission.Denied(fmt.Sprintf("%s cannot be changed as the names of existing Quay organizations are derived from them. Set the %s annotation to \"true\" to create new organizations and leave the existing ones behind",
+		strings.Join(changed, ", "), constants.AllowOrganizationRenameAnnotation))
+}
+
+// GetChangedImmutableFields returns the fields deriving the names of Quay organizations which differ between two revisions of a QuayIntegration.
+// The cluster ID may be changed by a cluster ID migration once the organizations have been copied
+func GetChangedImmutableFields(oldQuayIntegration *quayv1.QuayIntegration, quayIntegration *quayv1.QuayIntegration) []string {
+
+	changed := []string{}
+
+	if oldQuayIntegration.Spec.ClusterID != quayIntegration.Spec.ClusterID && !isClusterIDMigrationSwitch(oldQuayIntegration, quayIntegration) {
+		changed = append(changed, "spec.clusterID")
+	}
+
+	if oldQuayIntegration.GetOrganizationNameMaxLength() != quayIntegration.GetOrganizationNameMaxLength() {
+		changed = append(changed, "spec.organizationNameMaxLength")
+	}
+
+	return changed
+}
+
+// isClusterIDMigrationSwitch returns whether the cluster ID is changed to the target of a migration which has copied the organizations
+func isClusterIDMigrationSwitch(oldQuayIntegration *quayv1.QuayIntegration, quayIntegration *quayv1.QuayIntegration) bool {
+
+	migration := quayIntegration.Spec.ClusterIDMigration
+	migrationStatus := oldQuayIntegration.Status.ClusterIDMigration
+
+	return migration != nil && migrationStatus != nil &&
+		migrationStatus.Phase == quayv1.ClusterIDMigrationSwitching &&
+		migrationStatus.SourceClusterID == oldQuayIntegration.Spec.ClusterID &&
+		migrationStatus.TargetClusterID == migration.ClusterID &&
+		quayIntegration.Spec.ClusterID == migration.ClusterID
+}
+
+// InjectDecoder injects the decoder.
+func (v *QuayIntegrationValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+)
+
+func TestGetChangedImmutableFields(t *testing.T) {
+
+	migration := &quayv1.ClusterIDMigration{ClusterID: "production"}
+
+	cases := []struct {
+		old       quayv1.QuayIntegrationSpec
+		oldStatus quayv1.QuayIntegrationStatus
+		new       quayv1.QuayIntegrationSpec
+		expected  []string
+	}{
+		{
+			old:      quayv1.QuayIntegrationSpec{ClusterID: "openshift"},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "openshift", QuayHostname: "https://quay.example.com"},
+			expected: []string{},
+		},
+		{
+			old:      quayv1.QuayIntegrationSpec{ClusterID: "openshift"},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "production"},
+			expected: []string{"spec.clusterID"},
+		},
+		{
+			old:      quayv1.QuayIntegrationSpec{ClusterID: "openshift"},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "openshift", OrganizationNameMaxLength: quayv1.DefaultOrganizationNameMaxLength},
+			expected: []string{},
+		},
+		{
+			old:      quayv1.QuayIntegrationSpec{ClusterID: "openshift"},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "production", OrganizationNameMaxLength: 64},
+			expected: []string{"spec.clusterID", "spec.organizationNameMaxLength"},
+		},
+		{
+			old: quayv1.QuayIntegrationSpec{ClusterID: "openshift", ClusterIDMigration: migration},
+			oldStatus: quayv1.QuayIntegrationStatus{ClusterIDMigration: &quayv1.ClusterIDMigrationStatus{
+				SourceClusterID: "openshift", TargetClusterID: "production", Phase: quayv1.ClusterIDMigrationSwitching,
+			}},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "production", ClusterIDMigration: migration},
+			expected: []string{},
+		},
+		{
+			old: quayv1.QuayIntegrationSpec{ClusterID: "openshift", ClusterIDMigration: migration},
+			oldStatus: quayv1.QuayIntegrationStatus{ClusterIDMigration: &quayv1.ClusterIDMigrationStatus{
+				SourceClusterID: "openshift", TargetClusterID: "production", Phase: quayv1.ClusterIDMigrationCopying,
+			}},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "production", ClusterIDMigration: migration},
+			expected: []string{"spec.clusterID"},
+		},
+		{
+			old:      quayv1.QuayIntegrationSpec{ClusterID: "openshift"},
+			new:      quayv1.QuayIntegrationSpec{ClusterID: "production", ClusterIDMigration: migration},
+			expected: []string{"spec.clusterID"},
+		},
+	}
+
+	for i, c := range cases {
+
+		changed := GetChangedImmutableFields(&quayv1.QuayIntegration{Spec: c.old, Status: c.oldStatus}, &quayv1.QuayIntegration{Spec: c.new})
+
+		if !reflect.DeepEqual(c.expected, changed) {
+			t.Errorf("Test case %d did not match\nExpected: %v\nActual: %v", i, c.expected, changed)
+		}
+	}
+}
@@ -6,37 +6,93 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	buildv1 "github.com/openshift/api/build/v1"
 	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/actuator"
+	"github.com/quay/quay-bridge-operator/pkg/cachescope"
 	"github.com/quay/quay-bridge-operator/pkg/constants"
 	"github.com/quay/quay-bridge-operator/pkg/logging"
+	"github.com/quay/quay-bridge-operator/pkg/syncpolicy"
+	qotypes "github.com/quay/quay-bridge-operator/pkg/types"
+	"github.com/quay/quay-bridge-operator/pkg/utils"
 	jsonpatch "gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+const (
+	// BuildAdmissionTimeout is the timeoutSeconds of the webhook admitting Builds. Builds are rejected when the admission times out
+	// as the webhook fails closed
+	BuildAdmissionTimeout = constants.BuildAdmissionTimeoutSeconds * time.Second
+	// buildAdmissionHeadroom is the part of the BuildAdmissionTimeout left to the API server for calling the webhook and decoding
+	// its response
+	buildAdmissionHeadroom = 5 * time.Second
+)
+
 type QuayIntegrationMutator struct {
 	Client  client.Client
 	decoder *admission.Decoder
 	Log     logr.Logger
+
+	// Namespaces restricts mutation to Builds in the provided namespaces. Builds in every namespace are mutated when empty
+	Namespaces []string
+
+	// RepositoryCreator, when set, creates the Quay repositories of admitted Builds which do not exist yet
+	RepositoryCreator *RepositoryCreator
+
+	// BuildMutator renders the mutation of admitted Builds. DefaultBuildMutator is used when not set
+	BuildMutator actuator.BuildMutator
+}
+
+// DefaultBuildMutator redirects the output of Builds to Quay and rewrites their input images to their mirrors in Quay
+type DefaultBuildMutator struct{}
+
+var _ actuator.BuildMutator = DefaultBuildMutator{}
+
+func (DefaultBuildMutator) MutateBuild(build *buildv1.Build, buildConfig *buildv1.BuildConfig, namespace *corev1.Namespace, outputNamespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) *admissionv1.AdmissionResponse {
+	return getAdmissionResponseForBuild(build, buildConfig, namespace, outputNamespace, quayIntegration)
 }
 
-// +kubebuilder:webhook:path=/admissionwebhook,mutating=true,failurePolicy=fail,verbs="*",groups="build.openshift.io",resources=builds,versions=v1,name=quayintegration.quay.redhat.com,sideEffects=None,admissionReviewVersions={v1}
+//+kubebuilder:rbac:groups=build.openshift.io,resources=buildconfigs,verbs=get;list;watch
+
+// +kubebuilder:webhook:path=/admissionwebhook,mutating=true,failurePolicy=fail,verbs=create,groups="build.openshift.io",resources=builds,versions=v1,name=quayintegration.quay.redhat.com,sideEffects=None,admissionReviewVersions={v1}
 
 func (q *QuayIntegrationMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
 
 	var admissionResponse *admissionv1.AdmissionResponse
 	build := &buildv1.Build{}
 
+	// BuildRequests submitted to the instantiate and clone subresources produce Builds which are admitted separately
+	if req.Kind.Kind != "Build" {
+		return admission.Allowed("")
+	}
+
+	// The spec of a Build is immutable once created
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
 	err := q.decoder.Decode(req, build)
 	if err != nil {
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	// Waiting for the provisioning of the namespace and the creation of the repository share a single deadline, answering before
+	// the API server gives up on the webhook
+	ctx, cancel := context.WithTimeout(ctx, BuildAdmissionTimeout-buildAdmissionHeadroom)
+	defer cancel()
+
+	if !cachescope.InNamespaces(q.Namespaces, req.Namespace) {
+		return admission.Allowed("")
+	}
+
 	// Get QuayIntegration
 	quayIntegration, found, err := q.getQuayIntegration(ctx, &req)
 
@@ -54,10 +110,30 @@ func (q *QuayIntegrationMutator) Handle(ctx context.Context, req admission.Reque
 				Allowed: true,
 			}
 		}
+	} else if provisioningResponse := q.getProvisioningResponse(ctx, build, &quayIntegration); provisioningResponse != nil {
+
+		admissionResponse = provisioningResponse
+
 	} else {
 
-		admissionResponse = getAdmissionResponseForBuild(build, &quayIntegration)
+		buildConfig := q.getBuildConfig(ctx, build)
+
+		namespace := q.getNamespace(ctx, build.Namespace)
+		outputNamespace := q.getOutputNamespace(ctx, build, namespace, &quayIntegration)
+
+		buildMutator := q.BuildMutator
 
+		if buildMutator == nil {
+			buildMutator = DefaultBuildMutator{}
+		}
+
+		admissionResponse = buildMutator.MutateBuild(build, buildConfig, namespace, outputNamespace, &quayIntegration)
+
+		if admissionResponse.Allowed && q.RepositoryCreator != nil && q.RepositoryCreator.IsEnabled(&quayIntegration) {
+			if organization, repository, ok := getBuildOutputRepository(build, buildConfig, outputNamespace, &quayIntegration); ok {
+				q.RepositoryCreator.Ensure(ctx, &quayIntegration, organization, repository)
+			}
+		}
 	}
 
 	return admission.Response{AdmissionResponse: *admissionResponse}
@@ -66,44 +142,297 @@ func (q *QuayIntegrationMutator) Handle(ctx context.Context, req admission.Reque
 
 func (q *QuayIntegrationMutator) getQuayIntegration(ctx context.Context, ar *admission.Request) (quayv1.QuayIntegration, bool, error) {
 
-	// Find the Current Registered QuayIntegration objects
-	quayIntegrations := quayv1.QuayIntegrationList{}
-
-	err := q.Client.List(ctx, &quayIntegrations, &client.ListOptions{})
+	// Check is this is a valid namespace (TODO: Use a predicate to filter out?). Namespaces selected by several QuayIntegrations
+	// are only synchronized by the one with precedence
+	_, quayIntegration, err := syncpolicy.GetOwner(ctx, q.Client, ar.Namespace)
 
 	if err != nil {
 		return quayv1.QuayIntegration{}, false, err
 	}
 
-	if len(quayIntegrations.Items) != 1 {
-		logging.Log.Info("No QuayIntegrations defined or more than 1 integration present")
+	if quayIntegration == nil {
 		return quayv1.QuayIntegration{}, false, nil
 	}
 
-	quayIntegration := *&quayIntegrations.Items[0]
+	return *quayIntegration, true, nil
+}
 
-	// Check is this is a valid namespace (TODO: Use a predicate to filter out?)
-	validNamespace := quayIntegration.IsAllowedNamespace(ar.Namespace)
+// getBuildConfig retrieves the BuildConfig a Build was instantiated from, if any
+func (q *QuayIntegrationMutator) getBuildConfig(ctx context.Context, build *buildv1.Build) *buildv1.BuildConfig {
 
-	if !validNamespace {
-		return quayv1.QuayIntegration{}, false, nil
+	buildConfigName, ok := build.Annotations[buildv1.BuildConfigAnnotation]
+
+	if !ok {
+		return nil
 	}
 
-	return quayIntegration, true, nil
+	buildConfig := &buildv1.BuildConfig{}
+
+	err := q.Client.Get(ctx, types.NamespacedName{Namespace: build.Namespace, Name: buildConfigName}, buildConfig)
+
+	if err != nil {
+		logging.Log.Error(err, "Failed to retrieve BuildConfig", "Namespace", build.Namespace, "Name", buildConfigName)
+		return nil
+	}
+
+	return buildConfig
+}
+
+// getNamespace retrieves the Namespace a Build was created in
+func (q *QuayIntegrationMutator) getNamespace(ctx context.Context, name string) *corev1.Namespace {
+
+	namespace := &corev1.Namespace{}
+
+	err := q.Client.Get(ctx, types.NamespacedName{Name: name}, namespace)
+
+	if err != nil {
+		logging.Log.Error(err, "Failed to retrieve Namespace", "Namespace", name)
+		return nil
+	}
+
+	return namespace
+}
+
+// getOutputNamespace retrieves the Namespace of the ImageStream a Build is pushed to when user repositories are enabled, as it
+// determines whether the repository belongs to an organization or a user account
+func (q *QuayIntegrationMutator) getOutputNamespace(ctx context.Context, build *buildv1.Build, namespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) *corev1.Namespace {
+
+	if quayIntegration.Spec.UserRepositories == nil || build.Spec.Output.To == nil {
+		return nil
+	}
+
+	if build.Spec.Output.To.Namespace == "" || build.Spec.Output.To.Namespace == build.Namespace {
+		return namespace
+	}
+
+	return q.getNamespace(ctx, build.Spec.Output.To.Namespace)
+}
+
+// getBuildAnnotation returns the value of an annotation from the Build, falling back to its BuildConfig
+func getBuildAnnotation(build *buildv1.Build, buildConfig *buildv1.BuildConfig, annotation string) (string, bool) {
+
+	if value, ok := build.Annotations[annotation]; ok {
+		return value, true
+	}
+
+	if buildConfig != nil {
+		if value, ok := buildConfig.Annotations[annotation]; ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// isBuildInputImageRewriteEnabled determines whether input images should be rewritten.
+// An annotation on the Build or its BuildConfig takes precedence over the QuayIntegration
+func isBuildInputImageRewriteEnabled(build *buildv1.Build, buildConfig *buildv1.BuildConfig, quayIntegration *quayv1.QuayIntegration) bool {
+
+	if value, ok := getBuildAnnotation(build, buildConfig, constants.BuildRewriteInputImagesAnnotation); ok {
+		return value == "true"
+	}
+
+	return quayIntegration.Spec.RewriteBuildInputImages
 }
 
-func getAdmissionResponseForBuild(build *buildv1.Build, quayIntegration *quayv1.QuayIntegration) *admissionv1.AdmissionResponse {
+func getAdmissionResponseForBuild(build *buildv1.Build, buildConfig *buildv1.BuildConfig, namespace *corev1.Namespace, outputNamespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) *admissionv1.AdmissionResponse {
 
 	var patch []jsonpatch.JsonPatchOperation
 
-	quayRegistryHostname, err := quayIntegration.GetRegistryHostname()
+	if build.Spec.Strategy.DockerStrategy == nil && build.Spec.Strategy.SourceStrategy == nil {
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	// Clones carry the output and annotations rendered for the original Build, which are rendered again for the clone
+	if IsStaleClone(build) {
+		patch = append(patch, getClonedBuildPatch(build)...)
+		build = resetClonedBuild(build)
+	}
+
+	// Builds which have already been mutated are not rewritten again upon reinvocation or resubmission
+	if _, ok := build.Annotations[constants.BuildMutatedAnnotation]; ok {
+		return &admissionv1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
+	outputPatch, err := getBuildOutputPatch(build, buildConfig, outputNamespace, quayIntegration)
+
+	if err != nil {
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	patch = append(patch, outputPatch...)
+
+	// Expiration only applies to images pushed to Quay
+	if len(outputPatch) > 0 {
+
+		expirationPatch, err := getBuildExpirationPatch(build, buildConfig, namespace)
+
+		if err != nil {
+			return &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Message: err.Error(),
+				},
+			}
+		}
+
+		patch = append(patch, expirationPatch...)
+	}
+
+	if isBuildInputImageRewriteEnabled(build, buildConfig, quayIntegration) {
+		patch = append(patch, getBuildInputImagePatch(build, quayIntegration)...)
+	}
 
-	if (build.Spec.Strategy.DockerStrategy == nil && build.Spec.Strategy.SourceStrategy == nil) || build.Spec.CommonSpec.Output.To.Kind != "ImageStreamTag" {
+	if len(patch) == 0 {
 		return &admissionv1.AdmissionResponse{
 			Allowed: true,
 		}
 	}
 
+	patch = append(patch, jsonpatch.JsonPatchOperation{
+		Operation: "add",
+		Path:      "/metadata/annotations/" + escapeJSONPointer(constants.BuildMutatedAnnotation),
+		Value:     getMutatedAnnotationValue(build),
+	})
+
+	// Annotations can only be added once the map exists
+	if build.Annotations == nil {
+		patch = append([]jsonpatch.JsonPatchOperation{{
+			Operation: "add",
+			Path:      "/metadata/annotations",
+			Value:     map[string]string{},
+		}}, patch...)
+	}
+
+	patchBytes, err := json.Marshal(patch)
+
+	if err != nil {
+		return &admissionv1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return &admissionv1.AdmissionResponse{
+		Allowed: true,
+		Patch:   patchBytes,
+		PatchType: func() *admissionv1.PatchType {
+			pt := admissionv1.PatchTypeJSONPatch
+			return &pt
+		}(),
+	}
+
+}
+
+// IsStaleClone returns whether a Build was cloned from a mutated Build. The mutated annotation of a Build records its name,
+// which differs from the name of a clone
+func IsStaleClone(build *buildv1.Build) bool {
+
+	value, mutated := build.Annotations[constants.BuildMutatedAnnotation]
+
+	if !mutated || build.Name == "" || value == build.Name {
+		return false
+	}
+
+	_, cloned := build.Annotations[buildv1.BuildCloneAnnotation]
+
+	// Builds mutated prior to recording their name are only identified as clones by the clone annotation
+	return cloned || value != "true"
+}
+
+// IsMutationMissed returns whether a Build pushing to an ImageStreamTag was admitted without being redirected to Quay,
+// such as while the webhook was unavailable
+func IsMutationMissed(build *buildv1.Build) bool {
+
+	if build.Spec.Strategy.DockerStrategy == nil && build.Spec.Strategy.SourceStrategy == nil {
+		return false
+	}
+
+	if _, ok := build.Annotations[constants.BuildMutatedAnnotation]; ok {
+		return false
+	}
+
+	return build.Spec.Output.To != nil && build.Spec.Output.To.Kind == "ImageStreamTag"
+}
+
+// getMutatedAnnotationValue returns the value of the mutated annotation of a Build
+func getMutatedAnnotationValue(build *buildv1.Build) string {
+
+	if build.Name == "" {
+		return "true"
+	}
+
+	return build.Name
+}
+
+// resetClonedBuild returns a copy of a cloned Build targeting the ImageStreamTag the original Build was redirected from,
+// without the annotations recording the mutation and synchronization of the original Build
+func resetClonedBuild(build *buildv1.Build) *buildv1.Build {
+
+	reset := build.DeepCopy()
+
+	delete(reset.Annotations, constants.BuildMutatedAnnotation)
+	delete(reset.Annotations, constants.BuildDestinationImageStreamTagImportedAnnotation)
+
+	destination, ok := build.Annotations[constants.BuildDestinationImageStreamAnnotation]
+
+	if !ok || reset.Spec.Output.To == nil {
+		return reset
+	}
+
+	destinationParts := strings.SplitN(destination, "/", 2)
+
+	if len(destinationParts) != 2 {
+		return reset
+	}
+
+	reset.Spec.Output.To = &corev1.ObjectReference{
+		Kind:      "ImageStreamTag",
+		Namespace: destinationParts[0],
+		Name:      destinationParts[1],
+	}
+
+	return reset
+}
+
+// getClonedBuildPatch removes the annotations recording the synchronization of the original Build from a clone
+func getClonedBuildPatch(build *buildv1.Build) []jsonpatch.JsonPatchOperation {
+
+	var patch []jsonpatch.JsonPatchOperation
+
+	if _, ok := build.Annotations[constants.BuildDestinationImageStreamTagImportedAnnotation]; ok {
+		patch = append(patch, jsonpatch.JsonPatchOperation{
+			Operation: "remove",
+			Path:      "/metadata/annotations/" + escapeJSONPointer(constants.BuildDestinationImageStreamTagImportedAnnotation),
+		})
+	}
+
+	return patch
+}
+
+// getBuildOutputPatch redirects the output of a Build from an ImageStreamTag to Quay.
+// The destination repository and tag can be customized using annotations on the Build or its BuildConfig.
+// The Namespace of the ImageStream, when provided, determines whether the repository belongs to a user account
+func getBuildOutputPatch(build *buildv1.Build, buildConfig *buildv1.BuildConfig, outputNamespace *corev1.Namespace, quayIntegration *quayv1.QuayIntegration) ([]jsonpatch.JsonPatchOperation, error) {
+
+	var patch []jsonpatch.JsonPatchOperation
+
+	if build.Spec.CommonSpec.Output.To == nil || build.Spec.CommonSpec.Output.To.Kind != "ImageStreamTag" {
+		return patch, nil
+	}
+
+	quayRegistryHostname, _ := quayIntegration.GetRegistryHostname()
+
 	var imageStreamDestinationNamespace = build.Namespace
 
 	if build.Spec.CommonSpec.Output.To.Namespace != "" {
@@ -113,7 +442,17 @@ func getAdmissionResponseForBuild(build *buildv1.Build, quayIntegration *quayv1.
 	// Get ImageStream Name and Tag
 	imageStremParts := strings.Split(build.Spec.Output.To.Name, ":")
 
-	dockerImage := fmt.Sprintf("%s/%s/%s:%s", quayRegistryHostname, quayIntegration.GenerateQuayOrganizationNameFromNamespace(imageStreamDestinationNamespace), imageStremParts[0], imageStremParts[1])
+	if len(imageStremParts) != 2 {
+		return nil, fmt.Errorf("invalid ImageStreamTag '%s'", build.Spec.Output.To.Name)
+	}
+
+	repository, tag, err := getBuildOutputRepositoryAndTag(build, buildConfig, quayIntegration, imageStremParts[0], imageStremParts[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	dockerImage := fmt.Sprintf("%s/%s/%s:%s", quayRegistryHostname, utils.GetRepositoryNamespace(quayIntegration, imageStreamDestinationNamespace, outputNamespace), repository, tag)
 
 	// Update the Kind
 	patch = append(patch, jsonpatch.JsonPatchOperation{
@@ -142,25 +481,186 @@ func getAdmissionResponseForBuild(build *buildv1.Build, quayIntegration *quayv1.
 		Value:     fmt.Sprintf("%s/%s:%s", imageStreamDestinationNamespace, imageStremParts[0], imageStremParts[1]),
 	})
 
-	patchBytes, err := json.Marshal(patch)
+	// Label Build so that the operator may restrict the Builds it caches
+	if build.Labels == nil {
+		patch = append(patch, jsonpatch.JsonPatchOperation{
+			Operation: "add",
+			Path:      "/metadata/labels",
+			Value:     map[string]string{constants.BuildOperatorManagedLabel: "true"},
+		})
+	} else {
+		patch = append(patch, jsonpatch.JsonPatchOperation{
+			Operation: "add",
+			Path:      "/metadata/labels/" + escapeJSONPointer(constants.BuildOperatorManagedLabel),
+			Value:     "true",
+		})
+	}
+
+	patch = append(patch, getBuildPushSecretPatch(build, quayIntegration)...)
+
+	return patch, nil
+}
+
+// getBuildOutputRepositoryAndTag returns the Quay repository and tag a Build is pushed to. Unless overridden by
+// the repository annotation, the repository matches the name of the output ImageStreamTag. The tag is rendered
+// from the tag-template annotation or the BuildOutputTagTemplate of the QuayIntegration when either is present
+func getBuildOutputRepositoryAndTag(build *buildv1.Build, buildConfig *buildv1.BuildConfig, quayIntegration *quayv1.QuayIntegration, imageStreamName string, imageStreamTag string) (string, string, error) {
+
+	repository := imageStreamName
+	tag := imageStreamTag
+
+	if value, ok := getBuildAnnotation(build, buildConfig, constants.QuayRepositoryAnnotation); ok {
+
+		if !utils.IsValidRepositoryName(value) {
+			return "", "", fmt.Errorf("invalid repository '%s' specified in annotation '%s'", value, constants.QuayRepositoryAnnotation)
+		}
+
+		repository = value
+	}
+
+	tagTemplate, ok := getBuildAnnotation(build, buildConfig, constants.QuayTagTemplateAnnotation)
+
+	if !ok {
+		tagTemplate = quayIntegration.Spec.BuildOutputTagTemplate
+	}
+
+	if tagTemplate != "" {
+
+		renderedTag, err := renderTag(tagTemplate, newTagTemplateData(build, repository, imageStreamTag, time.Now()))
+
+		if err != nil {
+			return "", "", fmt.Errorf("invalid tag template '%s': %v", tagTemplate, err)
+		}
+
+		// Fall back to the ImageStreamTag when the template has nothing to render, such as a missing source revision
+		if renderedTag != "" {
+			tag = renderedTag
+		} else {
+			logging.Log.Info("Tag template rendered an empty tag, using ImageStreamTag", "Namespace", build.Namespace, "Build", build.Name, "Tag", imageStreamTag)
+		}
+	}
+
+	return repository, tag, nil
+}
+
+// getBuildPushSecretPatch sets the push secret of a Build to the operator managed secret of the builder service account, in the
+// first configured format usable as a push secret, unless the user provided one and the BuildPushSecretPolicy preserves it
+func getBuildPushSecretPatch(build *buildv1.Build, quayIntegration *quayv1.QuayIntegration) []jsonpatch.JsonPatchOperation {
+
+	var patch []jsonpatch.JsonPatchOperation
+
+	if build.Spec.Output.PushSecret != nil && quayIntegration.GetBuildPushSecretPolicy() == quayv1.PreservePushSecretPolicy {
+		logging.Log.Info("Preserving user provided push secret", "Namespace", build.Namespace, "Build", build.Name, "Secret", build.Spec.Output.PushSecret.Name)
+		return patch
+	}
+
+	// The Secrets of the builder service account are left to GitOps tooling unless they are published to a secret store
+	if !quayIntegration.ProvidesRobotAccountSecrets() {
+		return patch
+	}
+
+	pushSecretName, ok, err := utils.GenerateRobotAccountPullSecretName(quayIntegration, build.Namespace, quayIntegration.GenerateQuayOrganizationNameFromNamespace(build.Namespace), string(qotypes.BuilderOpenShiftServiceAccount))
 
 	if err != nil {
-		return &admissionv1.AdmissionResponse{
-			Result: &metav1.Status{
-				Message: err.Error(),
-			},
+		logging.Log.Error(err, "Failed to generate push secret name", "Namespace", build.Namespace, "Build", build.Name)
+		return patch
+	}
+
+	// Basic auth Secrets cannot be used as push secrets
+	if !ok {
+		return patch
+	}
+
+	patch = append(patch, jsonpatch.JsonPatchOperation{
+		Operation: "add",
+		Path:      "/spec/output/pushSecret",
+		Value:     corev1.LocalObjectReference{Name: pushSecretName},
+	})
+
+	return patch
+}
+
+// getBuildExpirationPatch adds the Quay expiration label to the output image of a Build based on the annotation of its
+// BuildConfig or Namespace. An expiration label specified by the user on the Build is preserved
+func getBuildExpirationPatch(build *buildv1.Build, buildConfig *buildv1.BuildConfig, namespace *corev1.Namespace) ([]jsonpatch.JsonPatchOperation, error) {
+
+	var patch []jsonpatch.JsonPatchOperation
+
+	expiresAfter, ok := getBuildAnnotation(build, buildConfig, constants.QuayExpiresAfterAnnotation)
+
+	if !ok && namespace != nil {
+		expiresAfter, ok = namespace.Annotations[constants.QuayExpiresAfterAnnotation]
+	}
+
+	if !ok || expiresAfter == "" {
+		return patch, nil
+	}
+
+	if !expiresAfterRegex.MatchString(expiresAfter) {
+		return nil, fmt.Errorf("invalid expiration '%s' specified in annotation '%s'", expiresAfter, constants.QuayExpiresAfterAnnotation)
+	}
+
+	for _, imageLabel := range build.Spec.Output.ImageLabels {
+		if imageLabel.Name == constants.QuayExpiresAfterLabel {
+			return patch, nil
 		}
 	}
 
-	return &admissionv1.AdmissionResponse{
-		Allowed: true,
-		Patch:   patchBytes,
-		PatchType: func() *admissionv1.PatchType {
-			pt := admissionv1.PatchTypeJSONPatch
-			return &pt
-		}(),
+	imageLabel := buildv1.ImageLabel{Name: constants.QuayExpiresAfterLabel, Value: expiresAfter}
+
+	if build.Spec.Output.ImageLabels == nil {
+		patch = append(patch, jsonpatch.JsonPatchOperation{
+			Operation: "add",
+			Path:      "/spec/output/imageLabels",
+			Value:     []buildv1.ImageLabel{imageLabel},
+		})
+	} else {
+		patch = append(patch, jsonpatch.JsonPatchOperation{
+			Operation: "add",
+			Path:      "/spec/output/imageLabels/-",
+			Value:     imageLabel,
+		})
+	}
+
+	return patch, nil
+}
+
+// getBuildInputImagePatch rewrites the builder or base image of a Build to its mirror in Quay
+func getBuildInputImagePatch(build *buildv1.Build, quayIntegration *quayv1.QuayIntegration) []jsonpatch.JsonPatchOperation {
+
+	var patch []jsonpatch.JsonPatchOperation
+
+	if build.Spec.Strategy.SourceStrategy != nil {
+		if mirror, ok := getInputImageMirror(&build.Spec.Strategy.SourceStrategy.From, quayIntegration); ok {
+			patch = append(patch, jsonpatch.JsonPatchOperation{
+				Operation: "replace",
+				Path:      "/spec/strategy/sourceStrategy/from/name",
+				Value:     mirror,
+			})
+		}
+	}
+
+	if build.Spec.Strategy.DockerStrategy != nil && build.Spec.Strategy.DockerStrategy.From != nil {
+		if mirror, ok := getInputImageMirror(build.Spec.Strategy.DockerStrategy.From, quayIntegration); ok {
+			patch = append(patch, jsonpatch.JsonPatchOperation{
+				Operation: "replace",
+				Path:      "/spec/strategy/dockerStrategy/from/name",
+				Value:     mirror,
+			})
+		}
+	}
+
+	return patch
+}
+
+func getInputImageMirror(from *corev1.ObjectReference, quayIntegration *quayv1.QuayIntegration) (string, bool) {
+
+	// Only direct image references can be mirrored
+	if from.Kind != "DockerImage" {
+		return "", false
 	}
 
+	return quayIntegration.GetBuildInputImageMirror(from.Name)
 }
 
 func escapeJSONPointer(s string) string {
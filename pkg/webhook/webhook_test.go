@@ -0,0 +1,696 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	quayv1 "github.com/quay/quay-bridge-operator/api/v1"
+	"github.com/quay/quay-bridge-operator/pkg/constants"
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildInputImagePatch(t *testing.T) {
+
+	quayIntegration := &quayv1.QuayIntegration{
+		Spec: quayv1.QuayIntegrationSpec{
+			QuayHostname: "https://quay.example.com",
+			BuildInputImageMirrors: []quayv1.ImageMirror{
+				{Source: "registry.redhat.io", Mirror: "quay.example.com/mirrors"},
+				{Source: "registry.redhat.io/ubi8", Mirror: "quay.example.com/ubi8"},
+			},
+		},
+	}
+
+	cases := []struct {
+		strategy           buildv1.BuildStrategy
+		rewriteInputImages bool
+		expected           []jsonpatch.JsonPatchOperation
+	}{
+		{
+			strategy: buildv1.BuildStrategy{
+				SourceStrategy: &buildv1.SourceBuildStrategy{
+					From: corev1.ObjectReference{Kind: "DockerImage", Name: "registry.redhat.io/ubi8/python-38:latest"},
+				},
+			},
+			rewriteInputImages: true,
+			expected: []jsonpatch.JsonPatchOperation{
+				{Operation: "replace", Path: "/spec/strategy/sourceStrategy/from/name", Value: "quay.example.com/ubi8/python-38:latest"},
+			},
+		},
+		{
+			strategy: buildv1.BuildStrategy{
+				DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.redhat.io/rhel8/nodejs-14@sha256:abc"},
+				},
+			},
+			rewriteInputImages: true,
+			expected: []jsonpatch.JsonPatchOperation{
+				{Operation: "replace", Path: "/spec/strategy/dockerStrategy/from/name", Value: "quay.example.com/mirrors/rhel8/nodejs-14@sha256:abc"},
+			},
+		},
+		{
+			strategy: buildv1.BuildStrategy{
+				DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.redhat.io/ubi8-minimal:latest"},
+				},
+			},
+			rewriteInputImages: true,
+			expected: []jsonpatch.JsonPatchOperation{
+				{Operation: "replace", Path: "/spec/strategy/dockerStrategy/from/name", Value: "quay.example.com/mirrors/ubi8-minimal:latest"},
+			},
+		},
+		{
+			strategy: buildv1.BuildStrategy{
+				SourceStrategy: &buildv1.SourceBuildStrategy{
+					From: corev1.ObjectReference{Kind: "DockerImage", Name: "registry.redhat.io/ubi8/python-38:latest"},
+				},
+			},
+			rewriteInputImages: false,
+			expected:           nil,
+		},
+		{
+			strategy: buildv1.BuildStrategy{
+				SourceStrategy: &buildv1.SourceBuildStrategy{
+					From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: "python:3.8", Namespace: "openshift"},
+				},
+			},
+			rewriteInputImages: true,
+			expected:           nil,
+		},
+		{
+			strategy: buildv1.BuildStrategy{
+				DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "DockerImage", Name: "docker.io/library/golang:1.16"},
+				},
+			},
+			rewriteInputImages: true,
+			expected:           nil,
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-build",
+				Namespace: "test",
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: c.strategy,
+					Output: buildv1.BuildOutput{
+						To: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.example.com/test/app:latest"},
+					},
+				},
+			},
+		}
+
+		quayIntegration.Spec.RewriteBuildInputImages = c.rewriteInputImages
+
+		response := getAdmissionResponseForBuild(build, nil, nil, nil, quayIntegration)
+
+		var patch, actual []jsonpatch.JsonPatchOperation
+
+		if response.Patch != nil {
+			if err := json.Unmarshal(response.Patch, &patch); err != nil {
+				t.Fatalf("Test case %d returned an invalid patch: %v", i, err)
+			}
+		}
+
+		for _, operation := range patch {
+			if strings.HasPrefix(operation.Path, "/spec/strategy/") {
+				actual = append(actual, operation)
+			}
+		}
+
+		if !response.Allowed || !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestBuildPushSecretPolicy(t *testing.T) {
+
+	cases := []struct {
+		policy     quayv1.PushSecretPolicy
+		pushSecret *corev1.LocalObjectReference
+		expected   *jsonpatch.JsonPatchOperation
+	}{
+		{
+			policy:     "",
+			pushSecret: nil,
+			expected:   &jsonpatch.JsonPatchOperation{Operation: "add", Path: "/spec/output/pushSecret", Value: map[string]interface{}{"name": "builder-quay-openshift"}},
+		},
+		{
+			policy:     quayv1.PreservePushSecretPolicy,
+			pushSecret: &corev1.LocalObjectReference{Name: "user-push-secret"},
+			expected:   nil,
+		},
+		{
+			policy:     quayv1.ReplacePushSecretPolicy,
+			pushSecret: &corev1.LocalObjectReference{Name: "user-push-secret"},
+			expected:   &jsonpatch.JsonPatchOperation{Operation: "add", Path: "/spec/output/pushSecret", Value: map[string]interface{}{"name": "builder-quay-openshift"}},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayIntegration := &quayv1.QuayIntegration{
+			Spec: quayv1.QuayIntegrationSpec{
+				ClusterID:             "openshift",
+				QuayHostname:          "https://quay.example.com",
+				BuildPushSecretPolicy: c.policy,
+			},
+		}
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-build",
+				Namespace: "test",
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{
+						DockerStrategy: &buildv1.DockerBuildStrategy{},
+					},
+					Output: buildv1.BuildOutput{
+						To:         &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+						PushSecret: c.pushSecret,
+					},
+				},
+			},
+		}
+
+		response := getAdmissionResponseForBuild(build, nil, nil, nil, quayIntegration)
+
+		var patch []jsonpatch.JsonPatchOperation
+
+		if err := json.Unmarshal(response.Patch, &patch); err != nil {
+			t.Fatalf("Test case %d returned an invalid patch: %v", i, err)
+		}
+
+		var actual *jsonpatch.JsonPatchOperation
+
+		for j := range patch {
+			if patch[j].Path == "/spec/output/pushSecret" {
+				actual = &patch[j]
+			}
+		}
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestBuildPushSecretFormat(t *testing.T) {
+
+	cases := []struct {
+		secretFormats []quayv1.SecretFormat
+		gitOpsMode    bool
+		secretStore   *quayv1.SecretStore
+		expected      *jsonpatch.JsonPatchOperation
+	}{
+		{
+			secretFormats: []quayv1.SecretFormat{quayv1.DockerCfgSecretFormat},
+			expected:      &jsonpatch.JsonPatchOperation{Operation: "add", Path: "/spec/output/pushSecret", Value: map[string]interface{}{"name": "builder-quay-openshift-dockercfg"}},
+		},
+		{
+			secretFormats: []quayv1.SecretFormat{quayv1.BasicAuthSecretFormat, quayv1.DockerCfgSecretFormat, quayv1.DockerConfigJsonSecretFormat},
+			expected:      &jsonpatch.JsonPatchOperation{Operation: "add", Path: "/spec/output/pushSecret", Value: map[string]interface{}{"name": "builder-quay-openshift-dockercfg"}},
+		},
+		{
+			secretFormats: []quayv1.SecretFormat{quayv1.BasicAuthSecretFormat},
+			expected:      nil,
+		},
+		{
+			gitOpsMode: true,
+			expected:   nil,
+		},
+		{
+			gitOpsMode:  true,
+			secretStore: &quayv1.SecretStore{Vault: &quayv1.VaultSecretStore{Address: "https://vault.example.com:8200"}},
+			expected:    &jsonpatch.JsonPatchOperation{Operation: "add", Path: "/spec/output/pushSecret", Value: map[string]interface{}{"name": "builder-quay-openshift"}},
+		},
+	}
+
+	for i, c := range cases {
+
+		quayIntegration := &quayv1.QuayIntegration{
+			Spec: quayv1.QuayIntegrationSpec{
+				ClusterID:     "openshift",
+				QuayHostname:  "https://quay.example.com",
+				SecretFormats: c.secretFormats,
+				GitOpsMode:    c.gitOpsMode,
+				SecretStore:   c.secretStore,
+			},
+		}
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-build",
+				Namespace: "test",
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{
+						DockerStrategy: &buildv1.DockerBuildStrategy{},
+					},
+					Output: buildv1.BuildOutput{
+						To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+					},
+				},
+			},
+		}
+
+		response := getAdmissionResponseForBuild(build, nil, nil, nil, quayIntegration)
+
+		var patch []jsonpatch.JsonPatchOperation
+
+		if len(response.Patch) > 0 {
+			if err := json.Unmarshal(response.Patch, &patch); err != nil {
+				t.Fatalf("Test case %d returned an invalid patch: %v", i, err)
+			}
+		}
+
+		var actual *jsonpatch.JsonPatchOperation
+
+		for j := range patch {
+			if patch[j].Path == "/spec/output/pushSecret" {
+				actual = &patch[j]
+			}
+		}
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+func TestBuildMutatedAnnotation(t *testing.T) {
+
+	quayIntegration := &quayv1.QuayIntegration{
+		Spec: quayv1.QuayIntegrationSpec{
+			ClusterID:    "openshift",
+			QuayHostname: "https://quay.example.com",
+		},
+	}
+
+	cases := []struct {
+		annotations   map[string]string
+		labels        map[string]string
+		expectPatch   bool
+		expectedPaths []string
+	}{
+		{
+			annotations:   nil,
+			expectPatch:   true,
+			expectedPaths: []string{"/metadata/annotations", "/metadata/annotations/" + escapeJSONPointer(constants.BuildMutatedAnnotation), "/metadata/labels"},
+		},
+		{
+			annotations:   map[string]string{},
+			labels:        map[string]string{"app": "test"},
+			expectPatch:   true,
+			expectedPaths: []string{"/metadata/annotations/" + escapeJSONPointer(constants.BuildMutatedAnnotation), "/metadata/labels/" + escapeJSONPointer(constants.BuildOperatorManagedLabel)},
+		},
+		{
+			annotations: map[string]string{constants.BuildMutatedAnnotation: "true"},
+			expectPatch: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-build",
+				Namespace:   "test",
+				Annotations: c.annotations,
+				Labels:      c.labels,
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{
+						DockerStrategy: &buildv1.DockerBuildStrategy{},
+					},
+					Output: buildv1.BuildOutput{
+						To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+					},
+				},
+			},
+		}
+
+		response := getAdmissionResponseForBuild(build, nil, nil, nil, quayIntegration)
+
+		if (response.Patch != nil) != c.expectPatch {
+			t.Fatalf("Test case %d did not match\nExpected patch: %t\nActual: %s", i, c.expectPatch, string(response.Patch))
+		}
+
+		if !c.expectPatch {
+			continue
+		}
+
+		var patch []jsonpatch.JsonPatchOperation
+
+		if err := json.Unmarshal(response.Patch, &patch); err != nil {
+			t.Fatalf("Test case %d returned an invalid patch: %v", i, err)
+		}
+
+		paths := map[string]bool{}
+
+		for _, operation := range patch {
+			paths[operation.Path] = true
+		}
+
+		for _, path := range c.expectedPaths {
+			if !paths[path] {
+				t.Errorf("Test case %d did not match\nExpected path: %s\nActual: %s", i, path, string(response.Patch))
+			}
+		}
+
+		if patch[0].Path == "/metadata/annotations" && c.annotations != nil {
+			t.Errorf("Test case %d replaced existing annotations", i)
+		}
+	}
+}
+
+func TestBuildOutputRepositoryAndTag(t *testing.T) {
+
+	cases := []struct {
+		buildAnnotations       map[string]string
+		buildConfigAnnotations map[string]string
+		expectedRepository     string
+		expectedTag            string
+		expectError            bool
+	}{
+		{
+			expectedRepository: "app",
+			expectedTag:        "latest",
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayRepositoryAnnotation: "myrepo"},
+			expectedRepository:     "myrepo",
+			expectedTag:            "latest",
+		},
+		{
+			buildAnnotations:       map[string]string{constants.QuayRepositoryAnnotation: "buildrepo"},
+			buildConfigAnnotations: map[string]string{constants.QuayRepositoryAnnotation: "myrepo"},
+			expectedRepository:     "buildrepo",
+			expectedTag:            "latest",
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayRepositoryAnnotation: "myrepo", constants.QuayTagTemplateAnnotation: "{{ .Repository }}-{{ .Tag }}"},
+			expectedRepository:     "myrepo",
+			expectedTag:            "myrepo-latest",
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayRepositoryAnnotation: "My/Repo"},
+			expectError:            true,
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayTagTemplateAnnotation: "{{ .Unknown }}"},
+			expectError:            true,
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayTagTemplateAnnotation: "invalid tag"},
+			expectError:            true,
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-build",
+				Namespace:   "test",
+				Annotations: c.buildAnnotations,
+			},
+		}
+
+		buildConfig := &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Namespace:   "test",
+				Annotations: c.buildConfigAnnotations,
+			},
+		}
+
+		repository, tag, err := getBuildOutputRepositoryAndTag(build, buildConfig, &quayv1.QuayIntegration{}, "app", "latest")
+
+		if c.expectError {
+			if err == nil {
+				t.Errorf("Test case %d did not return an error", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Test case %d returned an unexpected error: %v", i, err)
+		}
+
+		if repository != c.expectedRepository || tag != c.expectedTag {
+			t.Errorf("Test case %d did not match\nExpected: %s:%s\nActual: %s:%s", i, c.expectedRepository, c.expectedTag, repository, tag)
+		}
+	}
+}
+
+func TestBuildExpirationPatch(t *testing.T) {
+
+	cases := []struct {
+		buildConfigAnnotations map[string]string
+		namespaceAnnotations   map[string]string
+		imageLabels            []buildv1.ImageLabel
+		expected               []jsonpatch.JsonPatchOperation
+		expectError            bool
+	}{
+		{
+			expected: nil,
+		},
+		{
+			namespaceAnnotations: map[string]string{constants.QuayExpiresAfterAnnotation: "2w"},
+			expected: []jsonpatch.JsonPatchOperation{
+				{Operation: "add", Path: "/spec/output/imageLabels", Value: []interface{}{map[string]interface{}{"name": constants.QuayExpiresAfterLabel, "value": "2w"}}},
+			},
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayExpiresAfterAnnotation: "12h"},
+			namespaceAnnotations:   map[string]string{constants.QuayExpiresAfterAnnotation: "2w"},
+			imageLabels:            []buildv1.ImageLabel{{Name: "app", Value: "test"}},
+			expected: []jsonpatch.JsonPatchOperation{
+				{Operation: "add", Path: "/spec/output/imageLabels/-", Value: map[string]interface{}{"name": constants.QuayExpiresAfterLabel, "value": "12h"}},
+			},
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayExpiresAfterAnnotation: "12h"},
+			imageLabels:            []buildv1.ImageLabel{{Name: constants.QuayExpiresAfterLabel, Value: "1d"}},
+			expected:               nil,
+		},
+		{
+			buildConfigAnnotations: map[string]string{constants.QuayExpiresAfterAnnotation: "tomorrow"},
+			expectError:            true,
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-build",
+				Namespace: "test",
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Output: buildv1.BuildOutput{
+						ImageLabels: c.imageLabels,
+					},
+				},
+			},
+		}
+
+		buildConfig := &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Annotations: c.buildConfigAnnotations}}
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Annotations: c.namespaceAnnotations}}
+
+		patch, err := getBuildExpirationPatch(build, buildConfig, namespace)
+
+		if c.expectError {
+			if err == nil {
+				t.Errorf("Test case %d did not return an error", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Test case %d returned an unexpected error: %v", i, err)
+		}
+
+		// Round trip through JSON to compare against the patch sent to the API server
+		var actual []jsonpatch.JsonPatchOperation
+
+		if patch != nil {
+			patchBytes, _ := json.Marshal(patch)
+			json.Unmarshal(patchBytes, &actual)
+		}
+
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("Test case %d did not match\nExpected: %#v\nActual: %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestClonedBuild(t *testing.T) {
+
+	quayIntegration := &quayv1.QuayIntegration{
+		Spec: quayv1.QuayIntegrationSpec{
+			ClusterID:              "openshift",
+			QuayHostname:           "https://quay.example.com",
+			BuildOutputTagTemplate: "{{ .Tag }}-{{ .BuildNumber }}",
+		},
+	}
+
+	cases := []struct {
+		annotations       map[string]string
+		expectPatch       bool
+		expectedOperation map[string]interface{}
+	}{
+		{
+			annotations: map[string]string{
+				constants.BuildMutatedAnnotation:                           "app-1",
+				constants.BuildDestinationImageStreamAnnotation:            "test/app:latest",
+				constants.BuildDestinationImageStreamTagImportedAnnotation: "true",
+				buildv1.BuildCloneAnnotation:                               "app-1",
+				buildv1.BuildNumberAnnotation:                              "2",
+			},
+			expectPatch: true,
+			expectedOperation: map[string]interface{}{
+				"/spec/output/to/name": "quay.example.com/openshift_test/app:latest-2",
+				"/metadata/annotations/" + escapeJSONPointer(constants.BuildMutatedAnnotation):                           "app-2",
+				"/metadata/annotations/" + escapeJSONPointer(constants.BuildDestinationImageStreamTagImportedAnnotation): nil,
+			},
+		},
+		{
+			annotations: map[string]string{
+				constants.BuildMutatedAnnotation:                "true",
+				constants.BuildDestinationImageStreamAnnotation: "test/app:latest",
+				buildv1.BuildNumberAnnotation:                   "2",
+			},
+			expectPatch: false,
+		},
+		{
+			annotations: map[string]string{
+				constants.BuildMutatedAnnotation:                "app-2",
+				constants.BuildDestinationImageStreamAnnotation: "test/app:latest",
+				buildv1.BuildCloneAnnotation:                    "app-1",
+				buildv1.BuildNumberAnnotation:                   "2",
+			},
+			expectPatch: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app-2",
+				Namespace:   "test",
+				Annotations: c.annotations,
+				Labels:      map[string]string{constants.BuildOperatorManagedLabel: "true"},
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{
+						DockerStrategy: &buildv1.DockerBuildStrategy{},
+					},
+					Output: buildv1.BuildOutput{
+						To: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.example.com/openshift_test/app:latest-1"},
+					},
+				},
+			},
+		}
+
+		response := getAdmissionResponseForBuild(build, nil, nil, nil, quayIntegration)
+
+		if (response.Patch != nil) != c.expectPatch {
+			t.Fatalf("Test case %d did not match\nExpected patch: %t\nActual: %s", i, c.expectPatch, string(response.Patch))
+		}
+
+		if !c.expectPatch {
+			continue
+		}
+
+		var patch []jsonpatch.JsonPatchOperation
+
+		if err := json.Unmarshal(response.Patch, &patch); err != nil {
+			t.Fatalf("Test case %d returned an invalid patch: %v", i, err)
+		}
+
+		operations := map[string]interface{}{}
+
+		for _, operation := range patch {
+			operations[operation.Path] = operation.Value
+		}
+
+		for path, value := range c.expectedOperation {
+			if actual, ok := operations[path]; !ok || !reflect.DeepEqual(actual, value) {
+				t.Errorf("Test case %d did not match\nExpected: %s=%v\nActual: %s", i, path, value, string(response.Patch))
+			}
+		}
+	}
+}
+
+func TestIsMutationMissed(t *testing.T) {
+
+	cases := []struct {
+		annotations map[string]string
+		output      *corev1.ObjectReference
+		expected    bool
+	}{
+		{
+			output:   &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+			expected: true,
+		},
+		{
+			annotations: map[string]string{constants.BuildMutatedAnnotation: "app-1"},
+			output:      &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.example.com/openshift_test/app:latest"},
+			expected:    false,
+		},
+		{
+			output:   &corev1.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/app:latest"},
+			expected: false,
+		},
+		{
+			output:   nil,
+			expected: false,
+		},
+	}
+
+	for i, c := range cases {
+
+		build := &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app-1",
+				Namespace:   "test",
+				Annotations: c.annotations,
+			},
+			Spec: buildv1.BuildSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{
+						SourceStrategy: &buildv1.SourceBuildStrategy{},
+					},
+					Output: buildv1.BuildOutput{
+						To: c.output,
+					},
+				},
+			},
+		}
+
+		actual := IsMutationMissed(build)
+
+		if actual != c.expected {
+			t.Errorf("Test case %d did not match\nExpected: %t\nActual: %t", i, c.expected, actual)
+		}
+	}
+}